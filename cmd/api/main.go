@@ -4,21 +4,36 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/Lelo88/catalog-api-golang/internal/audit"
+	"github.com/Lelo88/catalog-api-golang/internal/brands"
+	"github.com/Lelo88/catalog-api-golang/internal/categories"
 	"github.com/Lelo88/catalog-api-golang/internal/config"
 	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/docs"
+	"github.com/Lelo88/catalog-api-golang/internal/events"
+	"github.com/Lelo88/catalog-api-golang/internal/export"
 	"github.com/Lelo88/catalog-api-golang/internal/health"
 	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/imports"
 	"github.com/Lelo88/catalog-api-golang/internal/items"
-	"github.com/Lelo88/catalog-api-golang/internal/docs"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+	"github.com/Lelo88/catalog-api-golang/internal/jobs"
+	"github.com/Lelo88/catalog-api-golang/internal/metrics"
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+	"github.com/Lelo88/catalog-api-golang/internal/reports"
+	"github.com/Lelo88/catalog-api-golang/internal/snapshot"
+	"github.com/Lelo88/catalog-api-golang/internal/webhooks"
 )
 
-
 type appPool interface {
 	Ping(ctx context.Context) error
 	Close()
@@ -27,10 +42,10 @@ type appPool interface {
 }
 
 type appDeps struct {
-	loadConfig      func() (config.Config, error)
-	newPool         func(ctx context.Context, url string) (appPool, error)
-	listenAndServe  func(addr string, handler http.Handler) error
-	logf            func(format string, args ...any)
+	loadConfig     func() (config.Config, error)
+	newPool        func(ctx context.Context, url string) (appPool, error)
+	listenAndServe func(addr string, handler http.Handler) error
+	logf           func(format string, args ...any)
 }
 
 var (
@@ -70,7 +85,27 @@ func run(ctx context.Context, deps appDeps) error {
 	}
 	defer pool.Close()
 
-	router := buildRouter(pool)
+	// Los jobs en background se cortan cuando run termina, antes de cerrar el pool.
+	runner := jobs.NewRunner(deps.logf)
+	// El bus se cierra después de que paren los jobs, así el relay no publica en una conexión cerrada.
+	bus, err := newEventBus(configuration)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := bus.Close(); err != nil {
+			deps.logf("event bus close: %v", err)
+		}
+	}()
+	// Cerrar el hub termina los streams de GET /items/events que sigan abiertos.
+	eventHub := items.NewEventHub()
+	defer eventHub.Close()
+	router := buildRouter(pool, configuration, runner, eventHub, bus)
+
+	jobsContext, cancelJobs := context.WithCancel(ctx)
+	runner.Start(jobsContext)
+	defer runner.Wait()
+	defer cancelJobs()
 
 	address := ":" + configuration.Port
 	deps.logf("listening on %s", address)
@@ -82,15 +117,31 @@ func run(ctx context.Context, deps appDeps) error {
 }
 
 // buildRouter construye el router HTTP con middlewares y rutas.
-func buildRouter(pool appPool) http.Handler {
+// Los jobs periódicos que dependen de lo que se arma acá se registran en runner; las mutaciones de
+// items se publican en eventHub (los streams de este proceso) y en bus.
+func buildRouter(pool appPool, configuration config.Config, runner *jobs.Runner, eventHub *items.EventHub, bus events.Publisher) http.Handler {
 	router := chi.NewRouter()
+	metricsRegistry := metrics.NewRegistry()
 
 	// Middlewares base para trazabilidad y estabilidad.
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Timeout(10 * time.Second))
+	// Log de auditoría: el middleware anota las escrituras exitosas y el job las escribe en la DB,
+	// fuera del request. Va antes que el timeout para ver el status final.
+	auditRepository := audit.NewRepository(pool)
+	if configuration.AuditLogFlushInterval > 0 {
+		auditRecorder := audit.NewRecorder(auditRepository, configuration.AuditLogQueueSize,
+			audit.WithDropHook(metrics.NewAuditDrops(metricsRegistry)),
+		)
+		router.Use(auditRecorder.Middleware)
+		runner.Every("audit_log", configuration.AuditLogFlushInterval, auditRecorder.Flush)
+	}
+	// El stream de eventos dura lo que dure la conexión y los exports e imports lo que tarde la
+	// descarga o la subida: no tienen timeout. Tampoco los chunks de las subidas por partes, ni el
+	// complete, que lee el archivo entero para verificar el checksum.
+	router.Use(httpx.Timeout(10*time.Second, items.EventsPath, items.ExportPath, snapshot.ExportPath, snapshot.ImportPath, imports.UploadPathPrefix))
 
 	// Errores de routing se manejan a nivel router.
 	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
@@ -102,24 +153,159 @@ func buildRouter(pool appPool) http.Handler {
 
 	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		httpx.OK(w, r, http.StatusOK, map[string]any{
-			"name":   "catalog-api-golang",
-			"status": "ok",
-			"docs":   "/docs/",
-			"health": "/health",
-			"ready":  "/ready",
+			"name":    "catalog-api-golang",
+			"status":  "ok",
+			"docs":    "/docs/",
+			"health":  "/health",
+			"ready":   "/ready",
 			"openapi": "/openapi.yaml",
+			"metrics": "/metrics",
 		})
 	})
 
-	healthHandler := health.New(pool)
+	router.Method(http.MethodGet, "/metrics", metrics.Handler(metricsRegistry))
+
+	concurrencyLimiter := httpx.NewConcurrencyLimiter(
+		concurrencyLimit(pool, configuration.ConcurrencyLimit),
+		configuration.ConcurrencyQueue,
+		configuration.ConcurrencyWait,
+	)
+	metrics.RegisterConcurrency(metricsRegistry, concurrencyLimiter)
+
+	// Items
+	itemsRepository := items.NewRepository(pool, items.WithQueryBudget(
+		db.NewQueryBudget(configuration.QueryDeadlineMargin, configuration.QueryTimeout),
+	))
+	var itemsValidators []items.Validator
+	if configuration.NameBlacklistPattern != "" {
+		// El patrón ya fue validado por config.Load.
+		itemsValidators = append(itemsValidators, items.NewNameBlacklistValidator(regexp.MustCompile(configuration.NameBlacklistPattern)))
+	}
+	retryingRepository := items.NewRetryingRepository(itemsRepository, items.WithRetryHook(metrics.NewDBRetries(metricsRegistry)))
+	catalogMetrics := metrics.NewCatalog(metricsRegistry)
+	// Webhooks y bus: el service guarda cada evento en el outbox, en la misma transacción del cambio;
+	// el relay los encola para los webhooks suscriptos (el job de entregas los manda) y los publica
+	// en el bus. El hub de SSE sigue recibiendo los eventos directo, porque cada instancia tiene sus
+	// propios clientes.
+	webhooksRepository := webhooks.NewRepository(pool)
+	webhookDispatcher := webhooks.NewDispatcher(webhooksRepository, webhooks.WithMaxAttempts(configuration.WebhookMaxAttempts))
+	outboxRelay := outbox.NewRelay(outbox.NewRepository(pool), webhookDispatcher, events.NewOutboxPublisher(bus))
+	runner.Every("outbox_relay", configuration.OutboxRelayInterval, outboxRelay.Run)
+	runner.Every("webhook_deliveries", configuration.WebhookDeliveryInterval, webhookDispatcher.Deliver)
+	// La versión del catálogo (ETag de GET /items) se cachea mientras el listener escucha los avisos
+	// de cambios de todas las instancias; los cambios de esta instancia la invalidan en el momento.
+	versionCache := items.NewCollectionVersionCache()
+	changesListener := db.NewListener(db.NewDialer(configuration.DatabaseURL), items.ChangesChannel, versionCache)
+	runner.Go("items_changes_listener", changesListener.Run)
+	itemsService := items.NewService(retryingRepository,
+		items.WithValidators(itemsValidators...),
+		items.WithMetrics(catalogMetrics),
+		items.WithFuzzyThreshold(configuration.FuzzyThreshold),
+		items.WithDidYouMeanThreshold(configuration.DidYouMeanThreshold),
+		items.WithMaxOffset(configuration.PaginationMaxOffset),
+		items.WithEstimatedCount(configuration.CountEstimate),
+		items.WithBackorderFloor(configuration.BackorderStockFloor),
+		items.WithDefaultCurrency(configuration.DefaultCurrency),
+		items.WithDefaultTaxRate(configuration.DefaultTaxRateBPS),
+		items.WithLocales(configuration.SupportedLocales...),
+		items.WithEventPublisher(eventHub, versionCache),
+		items.WithCollectionVersionCache(versionCache),
+		items.WithBus(bus),
+		items.WithOutbox(true),
+	)
+	runner.Every("catalog_stats", configuration.CatalogStatsInterval, func(ctx context.Context) error {
+		stats, err := itemsRepository.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		catalogMetrics.SetStats(stats.Total, stats.OutOfStock)
+		return nil
+	})
+	if days := configuration.TrashRetentionDays; days > 0 {
+		runner.Every("trash_purge", trashPurgeInterval, func(ctx context.Context) error {
+			purged, err := itemsRepository.PurgeDeletedBefore(ctx, time.Now().AddDate(0, 0, -days))
+			log.Printf("trash_purge purged=%d retention_days=%d", purged, days)
+			return err
+		})
+	}
+	// Las reservas vencidas ya no cuentan para available; el job las borra para que la tabla no crezca
+	// y avisa el cambio de sus items.
+	runner.Every("reservation_sweep", configuration.ReservationSweepInterval, func(ctx context.Context) error {
+		deleted, err := itemsService.ExpireReservations(ctx)
+		log.Printf("reservation_sweep deleted=%d", deleted)
+		return err
+	})
+	// Cada cambio de precio vencido se aplica en su propia transacción; los que fallan quedan para la próxima corrida.
+	runner.Every("price_schedules", configuration.PriceScheduleInterval, func(ctx context.Context) error {
+		applied, err := itemsService.ApplyDuePriceSchedules(ctx)
+		log.Printf("price_schedules applied=%d", applied)
+		return err
+	})
+	// Jobs asincrónicos: POST /imports y POST /imports/feed encolan el job y el worker procesa los
+	// items fuera del request. Cualquier instancia puede tomar un job encolado por otra.
+	jobsRepository := jobqueue.NewRepository(pool)
+	jobsService := jobqueue.NewService(jobsRepository)
+	// Las subidas por partes guardan los chunks en la base; el job las borra cuando vencen. Con un TTL
+	// más corto que el intervalo corre cada TTL, así una subida no dura mucho más que su TTL.
+	importUploads := imports.NewUploadService(imports.NewRepository(pool), jobsService, configuration.ImportUploadTTL)
+	runner.Every("import_uploads_purge", min(configuration.ImportUploadTTL, importUploadPurgeInterval), func(ctx context.Context) error {
+		purged, err := importUploads.PurgeExpired(ctx)
+		log.Printf("import_uploads_purge purged=%d", purged)
+		return err
+	})
+	jobWorker := jobqueue.NewWorker(jobsRepository,
+		jobqueue.WithProcessor(imports.Kind, imports.NewProcessor(itemsService, imports.WithUploadSource(importUploads))),
+		jobqueue.WithProcessor(imports.FeedKind, imports.NewFeedProcessor(imports.NewFetcher(), itemsService)),
+	)
+	runner.Every("job_worker", configuration.JobWorkerInterval, jobWorker.Run)
+	itemsHandler := items.NewHandler(itemsService,
+		items.WithStrictPagination(configuration.StrictPagination),
+		items.WithAllowUnknownQueryParams(configuration.AllowUnknownQueryParams),
+		items.WithPageSizes(configuration.PaginationDefaultLimit, configuration.PaginationMaxLimit),
+		items.WithRequireIfMatch(configuration.RequireIfMatch),
+		items.WithEventSource(eventHub),
+		items.WithXLSXMaxRows(configuration.XLSXExportMaxRows),
+	)
+	categoriesHandler := categories.NewHandler(categories.NewService(categories.NewRepository(pool)))
+	brandsHandler := brands.NewHandler(brands.NewService(brands.NewRepository(pool)))
+	reportsHandler := reports.NewHandler(reports.NewService(reports.NewRepository(pool)))
+	webhooksHandler := webhooks.NewHandler(webhooks.NewService(webhooksRepository))
+
+	// Export a S3: el job lee del repositorio sin retry ni budget (recorre toda la tabla).
+	healthOptions := []health.Option{health.WithReadyCacheTTL(configuration.ReadyCacheTTL)}
+	var exportJob *export.Job
+	if configuration.ExportS3Endpoint != "" {
+		exportJob = newExportJob(itemsRepository, configuration, metricsRegistry)
+	}
+	if exportJob != nil {
+		// El schedule ya fue validado por config.Load.
+		runner.Cron("catalog_export", jobs.MustParseSchedule(configuration.ExportSchedule), exportJob.Run)
+		healthOptions = append(healthOptions, health.WithDetail("export", func() any { return exportJob.Status() }))
+	}
+
+	healthHandler := health.New(pool, healthOptions...)
 	router.Get("/health", healthHandler.Health)
 	router.Get("/ready", healthHandler.Ready)
 
-	// Items
-	itemsRepository := items.NewRepository(pool)
-	itemsService := items.NewService(itemsRepository)
-	itemsHandler := items.NewHandler(itemsService)
-	items.RegisterRoutes(router, itemsHandler)
+	// Solo las rutas que tocan la DB pasan por el limiter; health, ready, docs y métricas no.
+	router.Group(func(route chi.Router) {
+		route.Use(concurrencyLimiter.Middleware)
+		items.RegisterRoutes(route, itemsHandler)
+		categories.RegisterRoutes(route, categoriesHandler)
+		brands.RegisterRoutes(route, brandsHandler)
+		reports.RegisterRoutes(route, reportsHandler)
+		webhooks.RegisterRoutes(route, webhooksHandler)
+		imports.RegisterRoutes(route, imports.NewHandler(jobsService, imports.WithUploads(importUploads)))
+		jobqueue.RegisterRoutes(route, jobqueue.NewHandler(jobsService))
+	})
+
+	// Streams: fuera del limiter, porque cada conexión abierta ocuparía un lugar mientras dure.
+	items.RegisterStreamRoutes(router, itemsHandler)
+
+	// Admin. Sin autenticación todavía: no exponer fuera de la red interna.
+	export.RegisterRoutes(router, export.NewHandler(exportJob))
+	audit.RegisterRoutes(router, audit.NewHandler(audit.NewService(auditRepository)))
+	snapshot.RegisterRoutes(router, snapshot.NewHandler(snapshot.NewService(snapshot.NewRepository(pool))))
 
 	// Docs
 	docs.RegisterRoutes(router)
@@ -127,3 +313,60 @@ func buildRouter(pool appPool) http.Handler {
 
 	return router
 }
+
+// newEventBus crea el bus de eventos según EVENTS_BACKEND (ya validado por config.Load). Con nats,
+// un servidor que no responde al arrancar frena el arranque.
+func newEventBus(configuration config.Config) (events.Publisher, error) {
+	if configuration.EventsBackend != "nats" {
+		return events.Noop{}, nil
+	}
+	return events.NewNATS(events.NATSConfig{
+		URL:           configuration.NATSURL,
+		SubjectPrefix: configuration.NATSSubjectPrefix,
+		Name:          "catalog-api",
+	})
+}
+
+// trashPurgeInterval es cada cuánto corre el job que vacía la papelera según TRASH_RETENTION_DAYS.
+const trashPurgeInterval = time.Hour
+
+// importUploadPurgeInterval es cada cuánto se borran las subidas de imports vencidas (IMPORT_UPLOAD_TTL).
+const importUploadPurgeInterval = 15 * time.Minute
+
+// newExportJob arma el export a S3 a partir de la config.
+// El cliente S3 no se conecta al crearse, así que solo falla con un endpoint mal formado;
+// en ese caso se loguea y el export queda deshabilitado en vez de impedir el arranque.
+func newExportJob(source export.Source, configuration config.Config, registry *prometheus.Registry) *export.Job {
+	store, err := export.NewS3Store(export.S3Config{
+		Endpoint:  configuration.ExportS3Endpoint,
+		Bucket:    configuration.ExportS3Bucket,
+		Region:    configuration.ExportS3Region,
+		AccessKey: configuration.ExportS3AccessKey,
+		SecretKey: configuration.ExportS3SecretKey,
+		UseSSL:    configuration.ExportS3UseSSL,
+	})
+	if err != nil {
+		log.Printf("export disabled: %v", err)
+		return nil
+	}
+	// El formato ya fue validado por config.Load.
+	format, _ := export.ParseFormat(configuration.ExportFormat)
+	return export.NewJob(source, store, format,
+		export.WithPrefix(configuration.ExportS3Prefix),
+		export.WithMetrics(metrics.NewExports(registry)),
+	)
+}
+
+// defaultConcurrencyLimit se usa cuando no se configuró un límite y el pool no informa su tamaño.
+const defaultConcurrencyLimit = 10
+
+// concurrencyLimit devuelve el límite configurado o, si es 0, el tamaño máximo del pool.
+func concurrencyLimit(pool appPool, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	if statPool, ok := pool.(interface{ Stat() *pgxpool.Stat }); ok {
+		return int(statPool.Stat().MaxConns())
+	}
+	return defaultConcurrencyLimit
+}
@@ -1,16 +1,23 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Lelo88/catalog-api-golang/internal/config"
+	"github.com/Lelo88/catalog-api-golang/internal/events"
 	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobs"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 )
@@ -18,6 +25,7 @@ import (
 type fakePool struct {
 	pingCalled  bool
 	closeCalled bool
+	queryRowErr error
 }
 
 func (pool *fakePool) Ping(ctx context.Context) error {
@@ -30,9 +38,44 @@ func (pool *fakePool) Close() {
 }
 
 func (pool *fakePool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if pool.queryRowErr != nil {
+		return errorRow{err: pool.queryRowErr}
+	}
+	return nil
+}
+
+// Begin abre una transacción falsa que lee del mismo pool: los cambios de items corren en una por el outbox.
+func (pool *fakePool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &fakeTx{pool: pool}, nil
+}
+
+// fakeTx implementa lo que items usa de pgx.Tx; el resto de los métodos no se llaman.
+type fakeTx struct {
+	pgx.Tx
+	pool *fakePool
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.pool.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
 	return nil
 }
 
+// errorRow es una fila cuyo Scan siempre falla con err.
+type errorRow struct {
+	err error
+}
+
+func (row errorRow) Scan(dest ...any) error {
+	return row.err
+}
+
 func (pool *fakePool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 	return nil, nil
 }
@@ -168,7 +211,7 @@ func TestRun_Success(t *testing.T) {
 
 func TestBuildRouter_HealthReady(t *testing.T) {
 	pool := &fakePool{}
-	router := buildRouter(pool)
+	router := buildRouter(pool, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
 
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	rec := httptest.NewRecorder()
@@ -192,7 +235,7 @@ func TestBuildRouter_HealthReady(t *testing.T) {
 
 func TestBuildRouter_NotFound(t *testing.T) {
 	pool := &fakePool{}
-	router := buildRouter(pool)
+	router := buildRouter(pool, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
 
 	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
 	rec := httptest.NewRecorder()
@@ -206,7 +249,7 @@ func TestBuildRouter_NotFound(t *testing.T) {
 
 func TestBuildRouter_MethodNotAllowed(t *testing.T) {
 	pool := &fakePool{}
-	router := buildRouter(pool)
+	router := buildRouter(pool, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
 
 	req := httptest.NewRequest(http.MethodPost, "/health", nil)
 	rec := httptest.NewRecorder()
@@ -218,6 +261,186 @@ func TestBuildRouter_MethodNotAllowed(t *testing.T) {
 	require.Equal(t, "method_not_allowed", resp.Error.Code)
 }
 
+func TestBuildRouter_DeleteItem(t *testing.T) {
+	t.Run("route is registered", func(t *testing.T) {
+		router := buildRouter(&fakePool{}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/items/not-a-uuid", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		router := buildRouter(&fakePool{queryRowErr: pgx.ErrNoRows}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/items/550e8400-e29b-41d4-a716-446655440000?return=representation", nil))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "not_found", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestBuildRouter_Events(t *testing.T) {
+	eventHub := items.NewEventHub()
+	// Con un solo lugar en el limiter, el segundo stream solo abre si los streams no pasan por él.
+	server := httptest.NewServer(buildRouter(&fakePool{}, config.Config{ConcurrencyLimit: 1}, jobs.NewRunner(t.Logf), eventHub, events.Noop{}))
+	defer server.Close()
+
+	open := func() *bufio.Reader {
+		response, err := http.Get(server.URL + "/items/events")
+		require.NoError(t, err)
+		t.Cleanup(func() { response.Body.Close() })
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		return bufio.NewReader(response.Body)
+	}
+	first, second := open(), open()
+
+	eventHub.Publish(items.Event{ID: "id-1", Operation: items.EventDeleted})
+	for _, stream := range []*bufio.Reader{first, second} {
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "event: item.deleted\n", line)
+		line, err = stream.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, `data: {"id":"id-1","operation":"deleted"}`+"\n", line)
+	}
+
+	// Al apagar, el hub cierra los streams y el cliente recibe el fin del body.
+	eventHub.Close()
+	_, err := io.ReadAll(first)
+	require.NoError(t, err)
+}
+
+func TestBuildRouter_Categories(t *testing.T) {
+	router := buildRouter(&fakePool{queryRowErr: pgx.ErrNoRows}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/categories/550e8400-e29b-41d4-a716-446655440000", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "not_found", decodeResponse(t, rec).Error.Code)
+}
+
+func TestBuildRouter_Brands(t *testing.T) {
+	router := buildRouter(&fakePool{queryRowErr: pgx.ErrNoRows}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/brands/550e8400-e29b-41d4-a716-446655440000", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "not_found", decodeResponse(t, rec).Error.Code)
+}
+
+func TestBuildRouter_Reports(t *testing.T) {
+	router := buildRouter(&fakePool{}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reports/inventory-valuation?group_by=supplier", nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+}
+
+func TestBuildRouter_Webhooks(t *testing.T) {
+	router := buildRouter(&fakePool{queryRowErr: pgx.ErrNoRows}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/550e8400-e29b-41d4-a716-446655440000/test", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, "not_found", decodeResponse(t, rec).Error.Code)
+}
+
+func TestBuildRouter_Metrics(t *testing.T) {
+	pool := &fakePool{}
+	router := buildRouter(pool, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "catalog_db_concurrency_in_use")
+}
+
+func TestBuildRouter_Export(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		router := buildRouter(&fakePool{}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/run", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "export_not_configured", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("configured adds export status to ready", func(t *testing.T) {
+		router := buildRouter(&fakePool{}, config.Config{
+			ExportS3Endpoint: "localhost:9000",
+			ExportS3Bucket:   "warehouse",
+			ExportFormat:     "ndjson",
+			ExportSchedule:   "@daily",
+		}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"export":{"running":false,"last_run":null}`)
+	})
+}
+
+func TestBuildRouter_Audit(t *testing.T) {
+	router := buildRouter(&fakePool{}, config.Config{AuditLogFlushInterval: time.Second, AuditLogQueueSize: 10}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/audit?since=yesterday", nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+}
+
+func TestBuildRouter_Imports(t *testing.T) {
+	router := buildRouter(&fakePool{}, config.Config{}, jobs.NewRunner(t.Logf), items.NewEventHub(), events.Noop{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":[]}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/imports/feed", strings.NewReader(`{"url":"http://169.254.169.254/"}`)))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs/nope", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+}
+
+func TestNewEventBus(t *testing.T) {
+	t.Run("none is a no-op", func(t *testing.T) {
+		bus, err := newEventBus(config.Config{EventsBackend: "none"})
+
+		require.NoError(t, err)
+		require.Equal(t, events.Noop{}, bus)
+	})
+
+	t.Run("nats server unreachable", func(t *testing.T) {
+		_, err := newEventBus(config.Config{EventsBackend: "nats", NATSURL: "nats://127.0.0.1:1"})
+
+		require.Error(t, err)
+	})
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	require.Equal(t, 32, concurrencyLimit(&fakePool{}, 32))
+	require.Equal(t, defaultConcurrencyLimit, concurrencyLimit(&fakePool{}, 0))
+}
+
 func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
 	t.Helper()
 
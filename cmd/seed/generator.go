@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+var (
+	adjectives = []string{"Compact", "Wireless", "Ergonomic", "Smart", "Classic", "Portable", "Premium", "Eco", "Ultra", "Vintage", "Foldable", "Heavy-Duty", "Slim", "Modular", "Rugged"}
+	nouns      = []string{"Keyboard", "Mouse", "Monitor", "Headphones", "Desk Lamp", "Office Chair", "Backpack", "Kettle", "Speaker", "Camera", "Watch", "Blender", "Jacket", "Sneakers", "Notebook", "Water Bottle", "Router", "Tent"}
+	materials  = []string{"brushed steel", "bamboo", "aluminum", "recycled plastic", "leather", "tempered glass", "organic cotton", "carbon fiber"}
+	features   = []string{"a two-year warranty", "free replacement parts", "a travel case", "USB-C charging", "a water-resistant finish", "an adjustable strap", "a quick-start guide"}
+)
+
+// generator arma items de prueba a partir de una semilla: la misma semilla da los mismos items.
+type generator struct {
+	random *rand.Rand
+	// run distingue los nombres y SKUs de una semilla de los de otra, así dos cargas sin -truncate
+	// no chocan en los índices únicos.
+	run string
+}
+
+// newGenerator crea un generator determinístico para seed.
+func newGenerator(seed uint64) *generator {
+	random := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	var run strings.Builder
+	for range 4 {
+		run.WriteByte(byte('A' + random.IntN(26)))
+	}
+	return &generator{random: random, run: run.String()}
+}
+
+// item genera el item número index. Los nombres y SKUs llevan index, así que no se repiten dentro
+// de una carga; uno de cada diez items no tiene descripción.
+func (generator *generator) item(index int) items.CreateItemInput {
+	adjective := adjectives[generator.random.IntN(len(adjectives))]
+	noun := nouns[generator.random.IntN(len(nouns))]
+	sku := fmt.Sprintf("SEED-%s-%07d", generator.run, index+1)
+	input := items.CreateItemInput{
+		Name:  fmt.Sprintf("%s %s %s-%d", adjective, noun, generator.run, index+1),
+		SKU:   &sku,
+		Price: generator.price(),
+		Stock: generator.stock(),
+	}
+	if generator.random.IntN(10) > 0 {
+		description := fmt.Sprintf("%s %s made of %s, with %s.", adjective, strings.ToLower(noun),
+			materials[generator.random.IntN(len(materials))], features[generator.random.IntN(len(features))])
+		input.Description = &description
+	}
+	return input
+}
+
+// price reparte los precios como un catálogo real: la mayoría baratos y pocos caros.
+func (generator *generator) price() string {
+	var cents int
+	switch roll := generator.random.IntN(100); {
+	case roll < 60:
+		cents = 100 + generator.random.IntN(5_000)
+	case roll < 90:
+		cents = 5_000 + generator.random.IntN(45_000)
+	default:
+		cents = 50_000 + generator.random.IntN(250_000)
+	}
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// stock deja algunos items agotados para que los filtros de stock tengan algo que mostrar.
+func (generator *generator) stock() int {
+	if generator.random.IntN(20) == 0 {
+		return 0
+	}
+	return 1 + generator.random.IntN(500)
+}
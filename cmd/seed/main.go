@@ -0,0 +1,132 @@
+// Command seed carga items de prueba en la base de DATABASE_URL, para demos y pruebas de carga.
+//
+// Uso:
+//
+//	go run ./cmd/seed -count 100000 -seed 42
+//	go run ./cmd/seed -truncate -yes -count 5000
+//
+// Los items pasan por las mismas validaciones que POST /items y se cargan con COPY de a lotes.
+// Con el mismo -seed se generan los mismos items, así dos benchmarks corren sobre el mismo catálogo.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/config"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// batchSize es cuántos items carga cada COPY.
+const batchSize = 5000
+
+// options son los flags del comando.
+type options struct {
+	count    int
+	seed     uint64
+	truncate bool
+	yes      bool
+}
+
+// parseOptions lee los flags de args. Sin -seed usa uno al azar, que se loguea para poder repetirlo.
+func parseOptions(args []string, output io.Writer) (options, error) {
+	var parsed options
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.IntVar(&parsed.count, "count", 1000, "cantidad de items a generar")
+	flags.Uint64Var(&parsed.seed, "seed", 0, "semilla de la generación; 0 elige una al azar")
+	flags.BoolVar(&parsed.truncate, "truncate", false, "borra todos los items (y lo que cuelga de ellos) antes de cargar")
+	flags.BoolVar(&parsed.yes, "yes", false, "confirma -truncate")
+	if err := flags.Parse(args); err != nil {
+		return options{}, err
+	}
+	if parsed.count < 1 {
+		return options{}, errors.New("-count must be at least 1")
+	}
+	if parsed.truncate && !parsed.yes {
+		return options{}, errors.New("-truncate deletes every item: add -yes to confirm")
+	}
+	if parsed.seed == 0 {
+		parsed.seed = rand.Uint64()
+	}
+	return parsed, nil
+}
+
+// seedStore es lo que el comando necesita de items.
+type seedStore interface {
+	Truncate(ctx context.Context) error
+}
+
+// seedService carga los lotes. items.Service lo implementa.
+type seedService interface {
+	CreateMany(ctx context.Context, inputs []items.CreateItemInput) (int64, error)
+}
+
+func main() {
+	parsed, err := parseOptions(os.Args[1:], os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal(err)
+	}
+	configuration, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	pool, err := db.NewPool(ctx, configuration.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	repository := items.NewRepository(pool)
+	service := items.NewService(repository,
+		items.WithDefaultCurrency(configuration.DefaultCurrency),
+		items.WithDefaultTaxRate(configuration.DefaultTaxRateBPS),
+		items.WithBackorderFloor(configuration.BackorderStockFloor),
+	)
+	if err := seed(ctx, parsed, repository, service, log.Printf); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// seed borra el catálogo si se pidió y carga parsed.count items de a batchSize.
+func seed(ctx context.Context, parsed options, store seedStore, service seedService, logf func(format string, args ...any)) error {
+	if parsed.truncate {
+		if err := store.Truncate(ctx); err != nil {
+			return fmt.Errorf("truncate items: %w", err)
+		}
+		logf("seed: items truncated")
+	}
+
+	start := time.Now()
+	generator := newGenerator(parsed.seed)
+	batch := make([]items.CreateItemInput, 0, batchSize)
+	loaded := int64(0)
+	for index := range parsed.count {
+		batch = append(batch, generator.item(index))
+		if len(batch) < batchSize && index < parsed.count-1 {
+			continue
+		}
+		copied, err := service.CreateMany(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("load items %d-%d: %w", index+1-len(batch), index, err)
+		}
+		loaded += copied
+		batch = batch[:0]
+		logf("seed: %d/%d items", loaded, parsed.count)
+	}
+	logf("seed: loaded %d items in %s (seed=%d)", loaded, time.Since(start).Round(time.Millisecond), parsed.seed)
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+type fakeSeedStore struct {
+	truncated bool
+}
+
+func (store *fakeSeedStore) Truncate(ctx context.Context) error {
+	store.truncated = true
+	return nil
+}
+
+type fakeSeedService struct {
+	batches []int
+	names   map[string]bool
+	err     error
+}
+
+func (service *fakeSeedService) CreateMany(ctx context.Context, inputs []items.CreateItemInput) (int64, error) {
+	if service.err != nil {
+		return 0, service.err
+	}
+	service.batches = append(service.batches, len(inputs))
+	for _, input := range inputs {
+		service.names[input.Name] = true
+	}
+	return int64(len(inputs)), nil
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Run("defaults pick a random seed", func(t *testing.T) {
+		parsed, err := parseOptions(nil, io.Discard)
+
+		require.NoError(t, err)
+		require.Equal(t, 1000, parsed.count)
+		require.NotZero(t, parsed.seed)
+	})
+
+	t.Run("truncate needs confirmation", func(t *testing.T) {
+		_, err := parseOptions([]string{"-truncate"}, io.Discard)
+		require.ErrorContains(t, err, "-yes")
+
+		parsed, err := parseOptions([]string{"-truncate", "-yes", "-count", "10", "-seed", "42"}, io.Discard)
+		require.NoError(t, err)
+		require.Equal(t, options{count: 10, seed: 42, truncate: true, yes: true}, parsed)
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		_, err := parseOptions([]string{"-count", "0"}, io.Discard)
+
+		require.ErrorContains(t, err, "-count")
+	})
+}
+
+func TestGenerator(t *testing.T) {
+	t.Run("same seed, same items", func(t *testing.T) {
+		first, second := newGenerator(42), newGenerator(42)
+		for index := range 100 {
+			require.Equal(t, first.item(index), second.item(index))
+		}
+		require.NotEqual(t, newGenerator(42).item(0), newGenerator(43).item(0))
+	})
+
+	t.Run("items pass the create rules", func(t *testing.T) {
+		skuPattern := regexp.MustCompile(`^[A-Z0-9._-]{3,64}$`)
+		pricePattern := regexp.MustCompile(`^[1-9][0-9]*\.[0-9]{2}$`)
+		generator := newGenerator(7)
+		withoutDescription := 0
+		for index := range 1000 {
+			item := generator.item(index)
+			require.NotEmpty(t, item.Name)
+			require.Regexp(t, skuPattern, *item.SKU)
+			require.Regexp(t, pricePattern, item.Price)
+			require.GreaterOrEqual(t, item.Stock, 0)
+			if item.Description == nil {
+				withoutDescription++
+			}
+		}
+		require.Positive(t, withoutDescription, "some items have no description")
+	})
+}
+
+func TestSeed(t *testing.T) {
+	t.Run("loads in batches", func(t *testing.T) {
+		store := &fakeSeedStore{}
+		service := &fakeSeedService{names: map[string]bool{}}
+
+		err := seed(context.Background(), options{count: 2*batchSize + 10, seed: 1, truncate: true, yes: true}, store, service, t.Logf)
+
+		require.NoError(t, err)
+		require.True(t, store.truncated)
+		require.Equal(t, []int{batchSize, batchSize, 10}, service.batches)
+		require.Len(t, service.names, 2*batchSize+10, "names are unique within a load")
+	})
+
+	t.Run("without truncate the catalog is kept", func(t *testing.T) {
+		store := &fakeSeedStore{}
+
+		err := seed(context.Background(), options{count: 3, seed: 1}, store, &fakeSeedService{names: map[string]bool{}}, t.Logf)
+
+		require.NoError(t, err)
+		require.False(t, store.truncated)
+	})
+
+	t.Run("load errors name the batch", func(t *testing.T) {
+		service := &fakeSeedService{err: errors.New("duplicate key")}
+
+		err := seed(context.Background(), options{count: 3, seed: 1}, &fakeSeedStore{}, service, t.Logf)
+
+		require.ErrorContains(t, err, "load items 0-2: duplicate key")
+	})
+}
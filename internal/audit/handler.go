@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	List(ctx context.Context, filter Filter, page, limit int) (Page, error)
+}
+
+// Handler expone la consulta del log de auditoría.
+type Handler struct {
+	service ServiceAPI
+}
+
+// NewHandler crea un handler del log de auditoría.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service}
+}
+
+// Paginación de GET /admin/audit. Un limit mayor al máximo se recorta.
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// List maneja GET /admin/audit?since=2026-01-02T15:04:05Z&actor=...&page=1&limit=50: los requests
+// de escritura registrados, del más nuevo al más viejo.
+func (handler *Handler) List(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	page, limit, ok := httpx.ParsePagination(query.Get("page"), query.Get("limit"), defaultAuditLimit, maxAuditLimit)
+	if !ok {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		return
+	}
+	filter := Filter{Actor: query.Get("actor")}
+	if value := strings.TrimSpace(query.Get("since")); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_filter", "invalid filter parameters", []httpx.ErrorDetail{
+				{Field: "since", Message: "since must be an RFC 3339 timestamp"},
+			})
+			return
+		}
+		filter.Since = &since
+	}
+
+	result, err := handler.service.List(request.Context(), filter, page, limit)
+	if err != nil {
+		if errors.Is(err, ErrorInvalidInput) {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"entries":    result.Entries,
+		"pagination": httpx.NewPagination(page, limit, result.Total),
+	})
+}
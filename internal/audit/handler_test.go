@@ -0,0 +1,139 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/audit"
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type stubService struct {
+	err    error
+	filter audit.Filter
+	page   int
+	limit  int
+	called bool
+}
+
+func (service *stubService) List(ctx context.Context, filter audit.Filter, page, limit int) (audit.Page, error) {
+	service.called = true
+	service.filter, service.page, service.limit = filter, page, limit
+	if service.err != nil {
+		return audit.Page{}, service.err
+	}
+	return audit.Page{Entries: []audit.Entry{{ID: 1, Method: http.MethodPost, Path: "/items", Status: http.StatusCreated}}, Total: 120}, nil
+}
+
+func TestHandler_List(t *testing.T) {
+	t.Run("filtered and paginated", func(t *testing.T) {
+		service := &stubService{}
+		handler := audit.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?since=2026-10-01T00:00:00Z&actor=key-1&page=2&limit=1000", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *service.filter.Since)
+		require.Equal(t, "key-1", service.filter.Actor)
+		require.Equal(t, 2, service.page)
+		require.Equal(t, 500, service.limit, "limit is capped")
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Len(t, data["entries"], 1)
+		pagination := asMap(t, data["pagination"])
+		require.Equal(t, json.Number("120"), pagination["total"])
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		service := &stubService{}
+		handler := audit.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Nil(t, service.filter.Since)
+		require.Equal(t, 1, service.page)
+		require.Equal(t, 50, service.limit)
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		service := &stubService{}
+		handler := audit.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?since=yesterday", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		response := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", response.Error.Code)
+		require.Equal(t, "since", response.Error.Details[0].Field)
+		require.False(t, service.called)
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		service := &stubService{}
+		handler := audit.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?page=0", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_pagination", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.called)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		handler := audit.NewHandler(&stubService{err: context.DeadlineExceeded})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	router := chi.NewRouter()
+	audit.RegisterRoutes(router, audit.NewHandler(&stubService{}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/audit", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
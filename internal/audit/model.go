@@ -0,0 +1,28 @@
+package audit
+
+import "time"
+
+// Entry es un request de escritura registrado en el log de auditoría. ItemID solo viene en las
+// rutas de un item (/items/{id}/...). Actor queda vacío hasta que la API tenga autenticación.
+type Entry struct {
+	ID        int64     `json:"id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ItemID    string    `json:"item_id,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Filter acota el log: Since es inclusive y nil no acota; Actor vacío no filtra.
+type Filter struct {
+	Since *time.Time
+	Actor string
+}
+
+// Page es una página del log, de la entrada más nueva a la más vieja.
+type Page struct {
+	Entries []Entry
+	Total   int
+}
@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Store es donde el Recorder escribe las entradas. Repository la implementa.
+type Store interface {
+	Insert(ctx context.Context, entries []Entry) error
+}
+
+// Razones de descarte que recibe el hook de WithDropHook.
+const (
+	// DropQueueFull: la cola estaba llena cuando terminó el request.
+	DropQueueFull = "queue_full"
+	// DropWriteFailed: falló el insert de la tanda; las entradas no se reintentan.
+	DropWriteFailed = "write_failed"
+)
+
+// flushBatchSize es cuántas entradas escribe cada insert de Flush.
+const flushBatchSize = 500
+
+// Recorder anota los requests de escritura y los escribe en el log de auditoría fuera del request.
+// El middleware solo encola (sin bloquear); el job llama a Flush. Si la cola se llena las entradas
+// nuevas se descartan, y las que están en la cola se pierden si el proceso se corta antes del flush.
+type Recorder struct {
+	store Store
+	queue chan Entry
+	actor func(*http.Request) string
+	drop  func(reason string, count int)
+	now   func() time.Time
+}
+
+// RecorderOption configura un Recorder.
+type RecorderOption func(*Recorder)
+
+// WithActor define de dónde sale el actor de cada request (la API key o el subject del JWT cuando
+// haya autenticación). Sin esta opción el actor queda vacío.
+func WithActor(actor func(*http.Request) string) RecorderOption {
+	return func(recorder *Recorder) {
+		recorder.actor = actor
+	}
+}
+
+// WithDropHook recibe cada descarte de entradas, con la razón y cuántas fueron.
+func WithDropHook(drop func(reason string, count int)) RecorderOption {
+	return func(recorder *Recorder) {
+		recorder.drop = drop
+	}
+}
+
+// NewRecorder crea un recorder con una cola de queueSize entradas.
+func NewRecorder(store Store, queueSize int, options ...RecorderOption) *Recorder {
+	recorder := &Recorder{
+		store: store,
+		queue: make(chan Entry, queueSize),
+		actor: func(*http.Request) string { return "" },
+		drop:  func(string, int) {},
+		now:   time.Now,
+	}
+	for _, option := range options {
+		option(recorder)
+	}
+	return recorder
+}
+
+// Middleware anota, cuando el handler termina, los requests POST, PUT, PATCH y DELETE que
+// respondieron 2xx. Tiene que registrarse en el router raíz para ver el patrón de la ruta.
+func (recorder *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !isWrite(request.Method) {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		wrapped := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+		next.ServeHTTP(wrapped, request)
+
+		status := wrapped.Status()
+		if status == 0 {
+			// El handler no escribió nada: net/http responde 200.
+			status = http.StatusOK
+		}
+		if status < 200 || status > 299 {
+			return
+		}
+		recorder.enqueue(Entry{
+			Method:    request.Method,
+			Path:      request.URL.Path,
+			ItemID:    itemID(request, wrapped.Header()),
+			Actor:     recorder.actor(request),
+			RequestID: middleware.GetReqID(request.Context()),
+			Status:    status,
+			CreatedAt: recorder.now().UTC(),
+		})
+	})
+}
+
+// enqueue agrega la entrada sin bloquear; con la cola llena la descarta.
+func (recorder *Recorder) enqueue(entry Entry) {
+	select {
+	case recorder.queue <- entry:
+	default:
+		recorder.drop(DropQueueFull, 1)
+	}
+}
+
+// Flush es el job del log: escribe las entradas encoladas, de a tandas, hasta vaciar la cola. Si
+// un insert falla la tanda se descarta y se devuelve el error; las que quedan esperan a la próxima
+// corrida.
+func (recorder *Recorder) Flush(ctx context.Context) error {
+	for {
+		batch := recorder.take(flushBatchSize)
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := recorder.store.Insert(ctx, batch); err != nil {
+			recorder.drop(DropWriteFailed, len(batch))
+			return err
+		}
+		if len(batch) < flushBatchSize {
+			return nil
+		}
+	}
+}
+
+// take saca hasta limit entradas de la cola sin esperar.
+func (recorder *Recorder) take(limit int) []Entry {
+	var batch []Entry
+	for len(batch) < limit {
+		select {
+		case entry := <-recorder.queue:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// isWrite indica si el método modifica datos.
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// itemID devuelve el {id} de las rutas de un item (/items/{id}, /items/{id}/stock, ...) o, en un
+// alta, el del Location de la respuesta. Para el resto de las rutas devuelve vacío.
+func itemID(request *http.Request, header http.Header) string {
+	routeContext := chi.RouteContext(request.Context())
+	if routeContext != nil && strings.HasPrefix(routeContext.RoutePattern(), "/items/{id}") {
+		return routeContext.URLParam("id")
+	}
+	if id, ok := strings.CutPrefix(header.Get("Location"), "/items/"); ok && !strings.Contains(id, "/") {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	batches [][]Entry
+	err     error
+}
+
+func (store *fakeStore) Insert(ctx context.Context, entries []Entry) error {
+	store.batches = append(store.batches, entries)
+	return store.err
+}
+
+// newTestRouter arma un router con el middleware del recorder y rutas parecidas a las de la API.
+func newTestRouter(recorder *Recorder) http.Handler {
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(recorder.Middleware)
+	router.Route("/items", func(route chi.Router) {
+		route.Post("/", func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Location", "/items/item-2")
+			writer.WriteHeader(http.StatusCreated)
+		})
+		route.Get("/{id}", func(writer http.ResponseWriter, request *http.Request) {})
+		route.Patch("/{id}/stock", func(writer http.ResponseWriter, request *http.Request) {})
+		route.Delete("/{id}", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+		})
+	})
+	router.Post("/webhooks", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusCreated)
+	})
+	return router
+}
+
+func TestRecorder_Middleware(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{}
+	recorder := NewRecorder(store, 10, WithActor(func(request *http.Request) string {
+		return request.Header.Get("X-Actor")
+	}))
+	recorder.now = func() time.Time { return now }
+	router := newTestRouter(recorder)
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodPatch, "/items/item-1/stock", nil),
+		httptest.NewRequest(http.MethodPost, "/items", nil),
+		httptest.NewRequest(http.MethodPost, "/webhooks", nil),
+		httptest.NewRequest(http.MethodGet, "/items/item-1", nil),
+		httptest.NewRequest(http.MethodDelete, "/items/item-1", nil),
+	}
+	requests[0].Header.Set("X-Actor", "key-1")
+	for _, request := range requests {
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	require.NoError(t, recorder.Flush(context.Background()))
+
+	require.Len(t, store.batches, 1)
+	entries := store.batches[0]
+	require.Len(t, entries, 3, "reads and failed writes are not recorded")
+	require.NotEmpty(t, entries[0].RequestID)
+	entries[0].RequestID, entries[1].RequestID, entries[2].RequestID = "", "", ""
+	require.Equal(t, []Entry{
+		{Method: http.MethodPatch, Path: "/items/item-1/stock", ItemID: "item-1", Actor: "key-1", Status: http.StatusOK, CreatedAt: now},
+		{Method: http.MethodPost, Path: "/items", ItemID: "item-2", Status: http.StatusCreated, CreatedAt: now},
+		{Method: http.MethodPost, Path: "/webhooks", Status: http.StatusCreated, CreatedAt: now},
+	}, entries)
+}
+
+func TestRecorder_QueueFull(t *testing.T) {
+	store := &fakeStore{}
+	drops := map[string]int{}
+	recorder := NewRecorder(store, 1, WithDropHook(func(reason string, count int) { drops[reason] += count }))
+	router := newTestRouter(recorder)
+
+	for range 3 {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhooks", nil))
+	}
+
+	require.Equal(t, map[string]int{DropQueueFull: 2}, drops)
+	require.NoError(t, recorder.Flush(context.Background()))
+	require.Len(t, store.batches[0], 1)
+}
+
+func TestRecorder_Flush(t *testing.T) {
+	t.Run("in batches until the queue is empty", func(t *testing.T) {
+		store := &fakeStore{}
+		recorder := NewRecorder(store, flushBatchSize+10)
+		for range flushBatchSize + 1 {
+			recorder.enqueue(Entry{Method: http.MethodPost})
+		}
+
+		require.NoError(t, recorder.Flush(context.Background()))
+
+		require.Len(t, store.batches, 2)
+		require.Len(t, store.batches[0], flushBatchSize)
+		require.Len(t, store.batches[1], 1)
+	})
+
+	t.Run("empty queue does not write", func(t *testing.T) {
+		store := &fakeStore{}
+		recorder := NewRecorder(store, 10)
+
+		require.NoError(t, recorder.Flush(context.Background()))
+
+		require.Empty(t, store.batches)
+	})
+
+	t.Run("failed batch is dropped", func(t *testing.T) {
+		store := &fakeStore{err: errors.New("db down")}
+		drops := map[string]int{}
+		recorder := NewRecorder(store, 10, WithDropHook(func(reason string, count int) { drops[reason] += count }))
+		recorder.enqueue(Entry{Method: http.MethodPost})
+		recorder.enqueue(Entry{Method: http.MethodDelete})
+
+		require.EqualError(t, recorder.Flush(context.Background()), "db down")
+
+		require.Equal(t, map[string]int{DropWriteFailed: 2}, drops)
+		store.err = nil
+		require.NoError(t, recorder.Flush(context.Background()))
+		require.Len(t, store.batches, 1, "nothing left to retry")
+	})
+}
@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Repository lee y escribe el log de auditoría.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio del log de auditoría.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// Insert agrega las entradas en una sola sentencia. Un item id, actor o request id vacío queda NULL.
+func (repository *Repository) Insert(ctx context.Context, entries []Entry) error {
+	const query = `
+		WITH inserted AS (
+			INSERT INTO audit_log (method, path, item_id, actor, request_id, status, created_at)
+			SELECT method, path, nullif(item_id, '')::uuid, nullif(actor, ''), nullif(request_id, ''), status, created_at
+			FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::integer[], $7::timestamptz[])
+				AS entries (method, path, item_id, actor, request_id, status, created_at)
+			RETURNING 1
+		)
+		SELECT count(*) FROM inserted;
+	`
+
+	methods := make([]string, len(entries))
+	paths := make([]string, len(entries))
+	itemIDs := make([]string, len(entries))
+	actors := make([]string, len(entries))
+	requestIDs := make([]string, len(entries))
+	statuses := make([]int, len(entries))
+	createdAt := make([]time.Time, len(entries))
+	for index, entry := range entries {
+		methods[index], paths[index], itemIDs[index] = entry.Method, entry.Path, entry.ItemID
+		actors[index], requestIDs[index] = entry.Actor, entry.RequestID
+		statuses[index], createdAt[index] = entry.Status, entry.CreatedAt
+	}
+
+	var inserted int
+	return repository.database.QueryRow(ctx, query, methods, paths, itemIDs, actors, requestIDs, statuses, createdAt).Scan(&inserted)
+}
+
+// List devuelve una página del log dentro de filter, de la entrada más nueva a la más vieja, y el
+// total de entradas que matchean (COUNT(*) OVER ()).
+func (repository *Repository) List(ctx context.Context, filter Filter, limit, offset int) ([]Entry, int, error) {
+	query := `
+		SELECT id, method, path, coalesce(item_id::text, ''), coalesce(actor, ''), coalesce(request_id, ''), status, created_at,
+			COUNT(*) OVER () AS total
+		FROM audit_log
+		WHERE true`
+	args := []any{limit, offset}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		query += fmt.Sprintf(" AND actor = $%d", len(args))
+	}
+	query += `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2;
+	`
+
+	rows, err := repository.database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]Entry, 0, limit)
+	total := 0
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.ID, &entry.Method, &entry.Path, &entry.ItemID, &entry.Actor, &entry.RequestID, &entry.Status, &entry.CreatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package audit_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/audit"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_AuditLog(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	actor := "it-" + uuid.NewString()
+	t.Cleanup(func() { _, _ = pool.Exec(ctx, "DELETE FROM audit_log WHERE actor = $1", actor) })
+
+	repository := audit.NewRepository(pool)
+	start := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repository.Insert(ctx, []audit.Entry{
+		{Method: http.MethodPost, Path: "/webhooks", Actor: actor, RequestID: "req-1", Status: http.StatusCreated, CreatedAt: start.Add(-time.Hour)},
+		{Method: http.MethodDelete, Path: "/items/" + uuid.NewString(), ItemID: uuid.NewString(), Actor: actor, Status: http.StatusNoContent, CreatedAt: start},
+	}))
+
+	service := audit.NewService(repository)
+	page, err := service.List(ctx, audit.Filter{Actor: actor}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, http.MethodDelete, page.Entries[0].Method, "newest first")
+	require.NotEmpty(t, page.Entries[0].ItemID)
+	require.Empty(t, page.Entries[0].RequestID)
+	require.Equal(t, "req-1", page.Entries[1].RequestID)
+
+	page, err = service.List(ctx, audit.Filter{Actor: actor, Since: &start}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.True(t, start.Equal(page.Entries[0].CreatedAt))
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Insert(t *testing.T) {
+	now := time.Now()
+	database := &fakeDB{row: &fakeRow{values: []any{2}}}
+	repository := NewRepository(database)
+
+	err := repository.Insert(context.Background(), []Entry{
+		{Method: "POST", Path: "/items", ItemID: "item-1", RequestID: "req-1", Status: 201, CreatedAt: now},
+		{Method: "DELETE", Path: "/webhooks/webhook-1", Actor: "key-1", Status: 204, CreatedAt: now},
+	})
+
+	require.NoError(t, err)
+	require.Contains(t, normalizeSQL(database.lastQuery), "SELECT method, path, nullif(item_id, '')::uuid, nullif(actor, ''), nullif(request_id, ''), status, created_at FROM unnest(")
+	require.Equal(t, []any{
+		[]string{"POST", "DELETE"},
+		[]string{"/items", "/webhooks/webhook-1"},
+		[]string{"item-1", ""},
+		[]string{"", "key-1"},
+		[]string{"req-1", ""},
+		[]int{201, 204},
+		[]time.Time{now, now},
+	}, database.lastArgs)
+}
+
+func TestRepository_List(t *testing.T) {
+	t.Run("filters and total", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{rows: &fakeRows{rows: [][]any{
+			{int64(7), "PATCH", "/items/item-1", "item-1", "key-1", "req-1", 200, now, 12},
+		}}}
+		repository := NewRepository(database)
+		since := now.Add(-time.Hour)
+
+		entries, total, err := repository.List(context.Background(), Filter{Since: &since, Actor: "key-1"}, 10, 20)
+
+		require.NoError(t, err)
+		require.Equal(t, 12, total)
+		require.Equal(t, []Entry{{ID: 7, Method: "PATCH", Path: "/items/item-1", ItemID: "item-1", Actor: "key-1", RequestID: "req-1", Status: 200, CreatedAt: now}}, entries)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "WHERE true AND created_at >= $3 AND actor = $4")
+		require.Contains(t, query, "ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2")
+		require.Equal(t, []any{10, 20, since, "key-1"}, database.lastArgs)
+		require.True(t, database.rows.closed)
+	})
+
+	t.Run("without filters", func(t *testing.T) {
+		database := &fakeDB{rows: &fakeRows{}}
+		repository := NewRepository(database)
+
+		entries, total, err := repository.List(context.Background(), Filter{}, 50, 0)
+
+		require.NoError(t, err)
+		require.Empty(t, entries)
+		require.Zero(t, total)
+		require.NotContains(t, database.lastQuery, "created_at >=")
+		require.Equal(t, []any{50, 0}, database.lastArgs)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{err: &pgconn.PgError{Code: "57014"}})
+
+		_, _, err := repository.List(context.Background(), Filter{}, 50, 0)
+
+		require.Error(t, err)
+	})
+}
+
+type fakeDB struct {
+	row       *fakeRow
+	rows      *fakeRows
+	err       error
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.row == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.row
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.err != nil {
+		return nil, db.err
+	}
+	if db.rows == nil {
+		return nil, errors.New("unexpected Query call")
+	}
+	return db.rows, nil
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	return assignValues(dest, row.values)
+}
+
+type fakeRows struct {
+	rows   [][]any
+	index  int
+	closed bool
+}
+
+func (rows *fakeRows) Close()                                       { rows.closed = true }
+func (rows *fakeRows) Err() error                                   { return nil }
+func (rows *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (rows *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (rows *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (rows *fakeRows) RawValues() [][]byte                          { return nil }
+func (rows *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (rows *fakeRows) Next() bool {
+	if rows.index >= len(rows.rows) {
+		return false
+	}
+	rows.index++
+	return true
+}
+
+func (rows *fakeRows) Scan(dest ...any) error {
+	return assignValues(dest, rows.rows[rows.index-1])
+}
+
+func assignValues(dest []any, values []any) error {
+	if len(dest) != len(values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -0,0 +1,9 @@
+package audit
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra la consulta del log de auditoría.
+// Todavía no hay autenticación: esta ruta no debería exponerse fuera de la red interna.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Get("/admin/audit", handler.List)
+}
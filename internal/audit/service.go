@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrorInvalidInput es una página o un límite inválidos. El handler lo traduce a 400.
+var ErrorInvalidInput = errors.New("invalid audit query")
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	// List devuelve una página del log dentro de filter, de la más nueva a la más vieja, y el total.
+	List(ctx context.Context, filter Filter, limit, offset int) ([]Entry, int, error)
+}
+
+// Service consulta el log de auditoría.
+type Service struct {
+	repository RepositoryAPI
+}
+
+// NewService crea el service del log de auditoría.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository}
+}
+
+// List devuelve la página page del log dentro de filter.
+func (service *Service) List(ctx context.Context, filter Filter, page, limit int) (Page, error) {
+	if page < 1 || limit < 1 {
+		return Page{}, ErrorInvalidInput
+	}
+	filter.Actor = strings.TrimSpace(filter.Actor)
+
+	entries, total, err := service.repository.List(ctx, filter, limit, (page-1)*limit)
+	if err != nil {
+		return Page{}, err
+	}
+	return Page{Entries: entries, Total: total}, nil
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	entries []Entry
+	total   int
+	err     error
+	filter  Filter
+	limit   int
+	offset  int
+	called  bool
+}
+
+func (repository *fakeRepo) List(ctx context.Context, filter Filter, limit, offset int) ([]Entry, int, error) {
+	repository.called = true
+	repository.filter, repository.limit, repository.offset = filter, limit, offset
+	return repository.entries, repository.total, repository.err
+}
+
+func TestService_List(t *testing.T) {
+	t.Run("page to offset and trimmed actor", func(t *testing.T) {
+		repository := &fakeRepo{entries: []Entry{{ID: 1}}, total: 31}
+		service := NewService(repository)
+
+		page, err := service.List(context.Background(), Filter{Actor: " key-1 "}, 3, 10)
+
+		require.NoError(t, err)
+		require.Equal(t, Page{Entries: []Entry{{ID: 1}}, Total: 31}, page)
+		require.Equal(t, "key-1", repository.filter.Actor)
+		require.Equal(t, 10, repository.limit)
+		require.Equal(t, 20, repository.offset)
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.List(context.Background(), Filter{}, 0, 10)
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.called)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service := NewService(&fakeRepo{err: errors.New("boom")})
+
+		_, err := service.List(context.Background(), Filter{}, 1, 10)
+
+		require.EqualError(t, err, "boom")
+	})
+}
@@ -0,0 +1,143 @@
+package brands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	Create(ctx context.Context, input BrandInput) (Brand, error)
+	List(ctx context.Context) ([]Brand, error)
+	Get(ctx context.Context, id string) (Brand, error)
+	Update(ctx context.Context, id string, input BrandInput) (Brand, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Handler expone el CRUD de marcas.
+type Handler struct {
+	service ServiceAPI
+}
+
+// NewHandler crea un handler de marcas.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service}
+}
+
+// Create maneja POST /brands.
+func (handler *Handler) Create(writer http.ResponseWriter, request *http.Request) {
+	var input BrandInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	brand, err := handler.service.Create(request.Context(), input)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.Created(writer, request, "/brands/"+brand.ID, brand)
+}
+
+// List maneja GET /brands.
+func (handler *Handler) List(writer http.ResponseWriter, request *http.Request) {
+	brands, err := handler.service.List(request.Context())
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, brands)
+}
+
+// Get maneja GET /brands/{id}.
+func (handler *Handler) Get(writer http.ResponseWriter, request *http.Request) {
+	id, ok := brandID(writer, request)
+	if !ok {
+		return
+	}
+
+	brand, err := handler.service.Get(request.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "brand not found")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, brand)
+}
+
+// Update maneja PATCH /brands/{id}.
+func (handler *Handler) Update(writer http.ResponseWriter, request *http.Request) {
+	id, ok := brandID(writer, request)
+	if !ok {
+		return
+	}
+	var input BrandInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	brand, err := handler.service.Update(request.Context(), id, input)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, brand)
+}
+
+// Delete maneja DELETE /brands/{id}. Una marca con items responde 409 brand_in_use.
+func (handler *Handler) Delete(writer http.ResponseWriter, request *http.Request) {
+	id, ok := brandID(writer, request)
+	if !ok {
+		return
+	}
+
+	err := handler.service.Delete(request.Context(), id)
+	switch {
+	case err == nil:
+		writer.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "brand not found")
+	case errors.Is(err, ErrorInUse):
+		httpx.Fail(writer, request, http.StatusConflict, "brand_in_use", "brand has items; change their brand first")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// brandID lee y valida el {id} del path; si no es un UUID responde 400 y devuelve false.
+func brandID(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// failWrite responde los errores de alta y modificación.
+func failWrite(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorInvalidName):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_name", "name must not be empty")
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "brand not found")
+	case errors.Is(err, ErrorDuplicateName):
+		httpx.FailWithDetails(writer, request, http.StatusConflict, "conflict", "brand name already exists", []httpx.ErrorDetail{
+			{Field: "name", Message: "another brand already has this name"},
+		})
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
@@ -0,0 +1,209 @@
+package brands_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lelo88/catalog-api-golang/internal/brands"
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const brandID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+type stubService struct {
+	createFn    func(ctx context.Context, input brands.BrandInput) (brands.Brand, error)
+	getFn       func(ctx context.Context, id string) (brands.Brand, error)
+	updateInput brands.BrandInput
+	deleteErr   error
+	called      bool
+}
+
+func (service *stubService) Create(ctx context.Context, input brands.BrandInput) (brands.Brand, error) {
+	service.called = true
+	if service.createFn != nil {
+		return service.createFn(ctx, input)
+	}
+	return brands.Brand{ID: brandID, Name: input.Name}, nil
+}
+
+func (service *stubService) List(ctx context.Context) ([]brands.Brand, error) {
+	return []brands.Brand{{ID: brandID, Name: "Acme"}}, nil
+}
+
+func (service *stubService) Get(ctx context.Context, id string) (brands.Brand, error) {
+	service.called = true
+	if service.getFn != nil {
+		return service.getFn(ctx, id)
+	}
+	return brands.Brand{ID: id}, nil
+}
+
+func (service *stubService) Update(ctx context.Context, id string, input brands.BrandInput) (brands.Brand, error) {
+	service.called = true
+	service.updateInput = input
+	return brands.Brand{ID: id, Name: input.Name}, nil
+}
+
+func (service *stubService) Delete(ctx context.Context, id string) error {
+	service.called = true
+	return service.deleteErr
+}
+
+func TestHandler_Create(t *testing.T) {
+	t.Run("created with location", func(t *testing.T) {
+		handler := brands.NewHandler(&stubService{})
+
+		req := httptest.NewRequest(http.MethodPost, "/brands", strings.NewReader(`{"name":"Acme"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/brands/"+brandID, rec.Header().Get("Location"))
+		require.Equal(t, "Acme", asMap(t, decodeResponse(t, rec).Data)["name"])
+	})
+
+	t.Run("duplicate name names the field", func(t *testing.T) {
+		handler := brands.NewHandler(&stubService{
+			createFn: func(ctx context.Context, input brands.BrandInput) (brands.Brand, error) {
+				return brands.Brand{}, brands.ErrorDuplicateName
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/brands", strings.NewReader(`{"name":"Acme"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "name", Message: "another brand already has this name"}}, decodeResponse(t, rec).Error.Details)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		handler := brands.NewHandler(&stubService{
+			createFn: func(ctx context.Context, input brands.BrandInput) (brands.Brand, error) {
+				return brands.Brand{}, brands.ErrorInvalidName
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/brands", strings.NewReader(`{"name":""}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_name", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_List(t *testing.T) {
+	handler := brands.NewHandler(&stubService{})
+
+	rec := httptest.NewRecorder()
+	handler.List(rec, httptest.NewRequest(http.MethodGet, "/brands", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	data, ok := decodeResponse(t, rec).Data.([]any)
+	require.True(t, ok)
+	require.Len(t, data, 1)
+}
+
+func TestHandler_Get(t *testing.T) {
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := brands.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/brands/x", nil), "id", "x")
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.called)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := brands.NewHandler(&stubService{
+			getFn: func(ctx context.Context, id string) (brands.Brand, error) {
+				return brands.Brand{}, brands.ErrorNotFound
+			},
+		})
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/brands/"+brandID, nil), "id", brandID)
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_Update(t *testing.T) {
+	service := &stubService{}
+	handler := brands.NewHandler(service)
+
+	req := withURLParam(httptest.NewRequest(http.MethodPatch, "/brands/"+brandID, strings.NewReader(`{"name":"Acme Corp"}`)), "id", brandID)
+	rec := httptest.NewRecorder()
+
+	handler.Update(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "Acme Corp", service.updateInput.Name)
+}
+
+func TestHandler_Delete(t *testing.T) {
+	t.Run("brand with items", func(t *testing.T) {
+		handler := brands.NewHandler(&stubService{deleteErr: brands.ErrorInUse})
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/brands/"+brandID, nil), "id", brandID)
+		rec := httptest.NewRecorder()
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, "brand_in_use", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		handler := brands.NewHandler(&stubService{})
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/brands/"+brandID, nil), "id", brandID)
+		rec := httptest.NewRecorder()
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
@@ -0,0 +1,16 @@
+package brands
+
+import "time"
+
+// Brand es una marca del catálogo. Name es único.
+type Brand struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BrandInput es el payload de POST /brands y PATCH /brands/{id}: el nombre es el único campo.
+type BrandInput struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,142 @@
+package brands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Repository accede a la tabla brands.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de marcas.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// brandColumns son las columnas de Brand en el orden en que las escanea brandDestinations.
+const brandColumns = `id, name, created_at, updated_at`
+
+// brandDestinations devuelve los destinos de Scan para las columnas de brandColumns.
+func brandDestinations(brand *Brand) []any {
+	return []any{&brand.ID, &brand.Name, &brand.CreatedAt, &brand.UpdatedAt}
+}
+
+// Insert crea una marca y devuelve el registro persistido.
+func (repository *Repository) Insert(ctx context.Context, name string) (Brand, error) {
+	const query = `INSERT INTO brands (name) VALUES ($1) RETURNING ` + brandColumns + `;`
+
+	var brand Brand
+	if err := repository.database.QueryRow(ctx, query, name).Scan(brandDestinations(&brand)...); err != nil {
+		return Brand{}, constraintViolation(err)
+	}
+	return brand, nil
+}
+
+// List devuelve todas las marcas ordenadas por nombre.
+func (repository *Repository) List(ctx context.Context) ([]Brand, error) {
+	const query = `SELECT ` + brandColumns + ` FROM brands ORDER BY name, id;`
+
+	rows, err := repository.database.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brands := make([]Brand, 0)
+	for rows.Next() {
+		var brand Brand
+		if err := rows.Scan(brandDestinations(&brand)...); err != nil {
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+	return brands, rows.Err()
+}
+
+// GetByID busca una marca por su ID. Devuelve ErrorNotFound si no existe.
+func (repository *Repository) GetByID(ctx context.Context, id string) (Brand, error) {
+	const query = `SELECT ` + brandColumns + ` FROM brands WHERE id = $1;`
+
+	var brand Brand
+	if err := repository.database.QueryRow(ctx, query, id).Scan(brandDestinations(&brand)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Brand{}, ErrorNotFound
+		}
+		return Brand{}, err
+	}
+	return brand, nil
+}
+
+// Rename cambia el nombre de la marca. Devuelve ErrorNotFound si no existe. Los items muestran el
+// nombre de su marca, así que si cambia les sube la versión y los avisa en items.ChangesChannel en la
+// misma sentencia.
+func (repository *Repository) Rename(ctx context.Context, id, name string) (Brand, error) {
+	// Las lecturas de brands dentro del CTE ven el nombre anterior.
+	const query = `
+		WITH updated AS (
+			UPDATE brands SET name = $1, updated_at = now() WHERE id = $2 RETURNING ` + brandColumns + `
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE brand_id IN (SELECT id FROM updated)
+				AND EXISTS (SELECT 1 FROM brands previous WHERE previous.id = $2 AND previous.name <> $1)
+			RETURNING id
+		)
+		SELECT ` + brandColumns + `, ` + items.TouchedNotification + ` FROM updated;
+	`
+
+	var (
+		brand    Brand
+		notified int
+	)
+	if err := repository.database.QueryRow(ctx, query, name, id).Scan(append(brandDestinations(&brand), &notified)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Brand{}, ErrorNotFound
+		}
+		return Brand{}, constraintViolation(err)
+	}
+	return brand, nil
+}
+
+// Delete borra la marca. Si algún item (incluidos los de la papelera) la usa, la FK lo impide
+// y devuelve ErrorInUse.
+func (repository *Repository) Delete(ctx context.Context, id string) error {
+	const query = `DELETE FROM brands WHERE id = $1 RETURNING id;`
+
+	var deletedID string
+	if err := repository.database.QueryRow(ctx, query, id).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return constraintViolation(err)
+	}
+	return nil
+}
+
+// constraintViolation traduce una violación de constraint al error de dominio: el nombre
+// repetido (23505) es ErrorDuplicateName y la FK de items (23503), ErrorInUse.
+func constraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if !errors.As(err, &postgresError) {
+		return err
+	}
+	switch {
+	case postgresError.Code == "23505" && postgresError.ConstraintName == "ux_brands_name":
+		return ErrorDuplicateName
+	case postgresError.Code == "23503" && postgresError.ConstraintName == "fk_items_brand":
+		return ErrorInUse
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+//go:build integration
+
+package brands_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/brands"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_BrandLifecycle(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	brandService := brands.NewService(brands.NewRepository(pool))
+	itemsRepository := items.NewRepository(pool)
+	itemsService := items.NewService(itemsRepository)
+
+	brand, err := brandService.Create(ctx, brands.BrandInput{Name: "Acme " + uuid.NewString()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = brandService.Delete(ctx, brand.ID) })
+
+	sku := "IT-" + strings.ToUpper(uuid.NewString()[:8])
+	item, err := itemsService.Create(ctx, items.CreateItemInput{Name: "Anvil " + uuid.NewString(), SKU: &sku, BrandID: &brand.ID, Price: "5.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = itemsRepository.Delete(ctx, item.ID, nil)
+		_ = itemsRepository.Purge(ctx, item.ID)
+	})
+	require.Equal(t, &items.ItemBrand{ID: brand.ID, Name: brand.Name}, item.Brand)
+
+	count, err := itemsService.Count(ctx, items.ListFilter{BrandID: brand.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, count.Total)
+
+	// El nombre de la marca es parte del item: renombrarla le sube la versión y lo avisa.
+	conn, err := pgx.Connect(ctx, databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close(ctx) })
+	_, err = conn.Exec(ctx, "LISTEN "+items.ChangesChannel)
+	require.NoError(t, err)
+
+	newName := "Acme Corp " + uuid.NewString()
+	_, err = brandService.Update(ctx, brand.ID, brands.BrandInput{Name: newName})
+	require.NoError(t, err)
+	renamed, err := itemsService.Get(ctx, item.ID)
+	require.NoError(t, err)
+	require.Equal(t, newName, renamed.Brand.Name)
+	require.Equal(t, item.Version+1, renamed.Version)
+
+	// Saltea los avisos de otros tests que corren contra la misma base.
+	waitContext, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	for {
+		notification, err := conn.WaitForNotification(waitContext)
+		require.NoError(t, err)
+		var payload items.ChangeNotification
+		require.NoError(t, json.Unmarshal([]byte(notification.Payload), &payload))
+		if payload.ID == item.ID {
+			require.Equal(t, items.EventUpdated, payload.Operation)
+			break
+		}
+	}
+
+	unknown := uuid.NewString()
+	_, err = itemsService.Update(ctx, item.ID, items.UpdateItemInput{BrandIDPresent: true, BrandID: &unknown})
+	require.ErrorIs(t, err, items.ErrorUnknownBrand)
+
+	require.ErrorIs(t, brandService.Delete(ctx, brand.ID), brands.ErrorInUse)
+
+	_, err = itemsService.Update(ctx, item.ID, items.UpdateItemInput{BrandIDPresent: true})
+	require.NoError(t, err)
+	require.NoError(t, brandService.Delete(ctx, brand.ID))
+}
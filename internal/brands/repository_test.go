@@ -0,0 +1,143 @@
+package brands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"brand-1", "Acme", now, now}}}
+		repository := NewRepository(database)
+
+		brand, err := repository.Insert(context.Background(), "Acme")
+
+		require.NoError(t, err)
+		require.Equal(t, Brand{ID: "brand-1", Name: "Acme", CreatedAt: now, UpdatedAt: now}, brand)
+		require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO brands (name) VALUES ($1)")
+		require.Equal(t, []any{"Acme"}, database.lastArgs)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_brands_name"}}}
+		repository := NewRepository(database)
+
+		_, err := repository.Insert(context.Background(), "Acme")
+
+		require.ErrorIs(t, err, ErrorDuplicateName)
+	})
+}
+
+func TestRepository_Rename(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"brand-1", "Acme Corp", now, now, 3}}}
+		repository := NewRepository(database)
+
+		brand, err := repository.Rename(context.Background(), "brand-1", "Acme Corp")
+
+		require.NoError(t, err)
+		require.Equal(t, "Acme Corp", brand.Name)
+		require.Contains(t, normalizeSQL(database.lastQuery), "SET name = $1, updated_at = now() WHERE id = $2")
+		require.Equal(t, []any{"Acme Corp", "brand-1"}, database.lastArgs)
+	})
+
+	t.Run("touches and notifies the items of the brand", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"brand-1", "Acme Corp", now, now, 3}}}
+		repository := NewRepository(database)
+
+		_, err := repository.Rename(context.Background(), "brand-1", "Acme Corp")
+
+		require.NoError(t, err)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE brand_id IN (SELECT id FROM updated) "+
+			"AND EXISTS (SELECT 1 FROM brands previous WHERE previous.id = $2 AND previous.name <> $1)")
+		require.Contains(t, query, items.TouchedNotification)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: pgx.ErrNoRows}}
+		repository := NewRepository(database)
+
+		_, err := repository.Rename(context.Background(), "brand-1", "Acme")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_Delete(t *testing.T) {
+	t.Run("brand with items", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_items_brand"}}}
+		repository := NewRepository(database)
+
+		err := repository.Delete(context.Background(), "brand-1")
+
+		require.ErrorIs(t, err, ErrorInUse)
+		require.Contains(t, normalizeSQL(database.lastQuery), "DELETE FROM brands WHERE id = $1")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: pgx.ErrNoRows}}
+		repository := NewRepository(database)
+
+		err := repository.Delete(context.Background(), "brand-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+type fakeDB struct {
+	row       *fakeRow
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.row == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.row
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	return nil, errors.New("unexpected Query call")
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	if len(dest) != len(row.values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(row.values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(row.values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
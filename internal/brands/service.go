@@ -0,0 +1,69 @@
+package brands
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Errores de dominio (no HTTP). El handler los traduce a status codes.
+var (
+	ErrorInvalidName = errors.New("brand name must not be empty")
+	// ErrorDuplicateName indica que otra marca ya usa ese nombre.
+	ErrorDuplicateName = errors.New("duplicate brand name")
+	ErrorNotFound      = errors.New("brand not found")
+	// ErrorInUse indica que hay items de la marca: primero hay que cambiarles la marca.
+	ErrorInUse = errors.New("brand has items")
+)
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	Insert(ctx context.Context, name string) (Brand, error)
+	List(ctx context.Context) ([]Brand, error)
+	GetByID(ctx context.Context, id string) (Brand, error)
+	Rename(ctx context.Context, id, name string) (Brand, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Service contiene las reglas de las marcas.
+type Service struct {
+	repository RepositoryAPI
+}
+
+// NewService crea un service de marcas.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository}
+}
+
+// Create valida y crea una marca.
+func (service *Service) Create(ctx context.Context, input BrandInput) (Brand, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return Brand{}, ErrorInvalidName
+	}
+	return service.repository.Insert(ctx, name)
+}
+
+// List devuelve todas las marcas.
+func (service *Service) List(ctx context.Context) ([]Brand, error) {
+	return service.repository.List(ctx)
+}
+
+// Get devuelve una marca por ID.
+func (service *Service) Get(ctx context.Context, id string) (Brand, error) {
+	return service.repository.GetByID(ctx, id)
+}
+
+// Update cambia el nombre de la marca.
+func (service *Service) Update(ctx context.Context, id string, input BrandInput) (Brand, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return Brand{}, ErrorInvalidName
+	}
+	return service.repository.Rename(ctx, id, name)
+}
+
+// Delete borra una marca sin items.
+func (service *Service) Delete(ctx context.Context, id string) error {
+	return service.repository.Delete(ctx, id)
+}
@@ -0,0 +1,84 @@
+package brands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	insertName   string
+	insertCalled bool
+	renameName   string
+	renameCalled bool
+}
+
+func (fakerepo *fakeRepo) Insert(ctx context.Context, name string) (Brand, error) {
+	fakerepo.insertCalled = true
+	fakerepo.insertName = name
+	return Brand{ID: "brand-1", Name: name}, nil
+}
+
+func (fakerepo *fakeRepo) List(ctx context.Context) ([]Brand, error) {
+	return []Brand{}, nil
+}
+
+func (fakerepo *fakeRepo) GetByID(ctx context.Context, id string) (Brand, error) {
+	return Brand{ID: id}, nil
+}
+
+func (fakerepo *fakeRepo) Rename(ctx context.Context, id, name string) (Brand, error) {
+	fakerepo.renameCalled = true
+	fakerepo.renameName = name
+	return Brand{ID: id, Name: name}, nil
+}
+
+func (fakerepo *fakeRepo) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("trims the name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		brand, err := service.Create(context.Background(), BrandInput{Name: "  Acme "})
+
+		require.NoError(t, err)
+		require.Equal(t, "Acme", brand.Name)
+		require.Equal(t, "Acme", repository.insertName)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), BrandInput{Name: "  "})
+
+		require.ErrorIs(t, err, ErrorInvalidName)
+		require.False(t, repository.insertCalled)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	t.Run("renames", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "brand-1", BrandInput{Name: " Acme Corp"})
+
+		require.NoError(t, err)
+		require.Equal(t, "Acme Corp", repository.renameName)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "brand-1", BrandInput{})
+
+		require.ErrorIs(t, err, ErrorInvalidName)
+		require.False(t, repository.renameCalled)
+	})
+}
@@ -0,0 +1,165 @@
+package categories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	Create(ctx context.Context, input CreateCategoryInput) (Category, error)
+	List(ctx context.Context) ([]Category, error)
+	Get(ctx context.Context, id string) (Category, error)
+	Update(ctx context.Context, id string, input UpdateCategoryInput) (Category, error)
+	Delete(ctx context.Context, id string, force bool) error
+}
+
+// Handler expone el CRUD de categorías.
+type Handler struct {
+	service ServiceAPI
+}
+
+// NewHandler crea un handler de categorías.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service}
+}
+
+// Create maneja POST /categories.
+func (handler *Handler) Create(writer http.ResponseWriter, request *http.Request) {
+	var input CreateCategoryInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	category, err := handler.service.Create(request.Context(), input)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.Created(writer, request, "/categories/"+category.ID, category)
+}
+
+// List maneja GET /categories.
+func (handler *Handler) List(writer http.ResponseWriter, request *http.Request) {
+	categories, err := handler.service.List(request.Context())
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, categories)
+}
+
+// Get maneja GET /categories/{id}.
+func (handler *Handler) Get(writer http.ResponseWriter, request *http.Request) {
+	id, ok := categoryID(writer, request)
+	if !ok {
+		return
+	}
+
+	category, err := handler.service.Get(request.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "category not found")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, category)
+}
+
+// Update maneja PATCH /categories/{id}.
+func (handler *Handler) Update(writer http.ResponseWriter, request *http.Request) {
+	id, ok := categoryID(writer, request)
+	if !ok {
+		return
+	}
+	var input UpdateCategoryInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	category, err := handler.service.Update(request.Context(), id, input)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, category)
+}
+
+// Delete maneja DELETE /categories/{id}. Una categoría con items responde 409 category_in_use,
+// salvo con ?force=true, que deja esos items sin categoría.
+func (handler *Handler) Delete(writer http.ResponseWriter, request *http.Request) {
+	id, ok := categoryID(writer, request)
+	if !ok {
+		return
+	}
+	force := false
+	if value := request.URL.Query().Get("force"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_force", "force must be true or false")
+			return
+		}
+		force = parsed
+	}
+
+	err := handler.service.Delete(request.Context(), id, force)
+	switch {
+	case err == nil:
+		writer.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "category not found")
+	case errors.Is(err, ErrorInUse):
+		httpx.Fail(writer, request, http.StatusConflict, "category_in_use", "category has items; use force=true to remove them from the category")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// categoryID lee y valida el {id} del path; si no es un UUID responde 400 y devuelve false.
+func categoryID(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// failWrite responde los errores de alta y modificación.
+func failWrite(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorInvalidName):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_name", "name must not be empty")
+	case errors.Is(err, ErrorInvalidSlug):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_slug", "slug must be lowercase letters, digits and hyphens, up to 120 characters")
+	case errors.Is(err, ErrorInvalidInput):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "category not found")
+	case errors.Is(err, ErrorDuplicateName):
+		failDuplicate(writer, request, "name")
+	case errors.Is(err, ErrorDuplicateSlug):
+		failDuplicate(writer, request, "slug")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// failDuplicate responde 409 conflict con el campo repetido en el detalle, como en items.
+func failDuplicate(writer http.ResponseWriter, request *http.Request, field string) {
+	httpx.FailWithDetails(writer, request, http.StatusConflict, "conflict", "category "+field+" already exists", []httpx.ErrorDetail{
+		{Field: field, Message: "another category already has this " + field},
+	})
+}
@@ -0,0 +1,230 @@
+package categories_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lelo88/catalog-api-golang/internal/categories"
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const categoryID = "550e8400-e29b-41d4-a716-446655440000"
+
+type stubService struct {
+	createFn    func(ctx context.Context, input categories.CreateCategoryInput) (categories.Category, error)
+	getFn       func(ctx context.Context, id string) (categories.Category, error)
+	updateInput categories.UpdateCategoryInput
+	deleteErr   error
+	deleteForce bool
+	called      bool
+}
+
+func (service *stubService) Create(ctx context.Context, input categories.CreateCategoryInput) (categories.Category, error) {
+	service.called = true
+	if service.createFn != nil {
+		return service.createFn(ctx, input)
+	}
+	return categories.Category{ID: categoryID, Name: input.Name}, nil
+}
+
+func (service *stubService) List(ctx context.Context) ([]categories.Category, error) {
+	return []categories.Category{{ID: categoryID, Name: "Audio", Slug: "audio"}}, nil
+}
+
+func (service *stubService) Get(ctx context.Context, id string) (categories.Category, error) {
+	service.called = true
+	if service.getFn != nil {
+		return service.getFn(ctx, id)
+	}
+	return categories.Category{ID: id}, nil
+}
+
+func (service *stubService) Update(ctx context.Context, id string, input categories.UpdateCategoryInput) (categories.Category, error) {
+	service.called = true
+	service.updateInput = input
+	return categories.Category{ID: id}, nil
+}
+
+func (service *stubService) Delete(ctx context.Context, id string, force bool) error {
+	service.called = true
+	service.deleteForce = force
+	return service.deleteErr
+}
+
+func TestHandler_Create(t *testing.T) {
+	t.Run("created with location", func(t *testing.T) {
+		handler := categories.NewHandler(&stubService{})
+
+		req := httptest.NewRequest(http.MethodPost, "/categories", strings.NewReader(`{"name":"Audio"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/categories/"+categoryID, rec.Header().Get("Location"))
+		require.Equal(t, "Audio", asMap(t, decodeResponse(t, rec).Data)["name"])
+	})
+
+	t.Run("duplicate slug names the field", func(t *testing.T) {
+		handler := categories.NewHandler(&stubService{
+			createFn: func(ctx context.Context, input categories.CreateCategoryInput) (categories.Category, error) {
+				return categories.Category{}, categories.ErrorDuplicateSlug
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/categories", strings.NewReader(`{"name":"Audio"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "slug", Message: "another category already has this slug"}}, decodeResponse(t, rec).Error.Details)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		handler := categories.NewHandler(&stubService{
+			createFn: func(ctx context.Context, input categories.CreateCategoryInput) (categories.Category, error) {
+				return categories.Category{}, categories.ErrorInvalidName
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/categories", strings.NewReader(`{"name":""}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_name", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_List(t *testing.T) {
+	handler := categories.NewHandler(&stubService{})
+
+	rec := httptest.NewRecorder()
+	handler.List(rec, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	data, ok := decodeResponse(t, rec).Data.([]any)
+	require.True(t, ok)
+	require.Len(t, data, 1)
+}
+
+func TestHandler_Get(t *testing.T) {
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := categories.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/categories/x", nil), "id", "x")
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.called)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := categories.NewHandler(&stubService{
+			getFn: func(ctx context.Context, id string) (categories.Category, error) {
+				return categories.Category{}, categories.ErrorNotFound
+			},
+		})
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/categories/"+categoryID, nil), "id", categoryID)
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_Update(t *testing.T) {
+	service := &stubService{}
+	handler := categories.NewHandler(service)
+
+	req := withURLParam(httptest.NewRequest(http.MethodPatch, "/categories/"+categoryID, strings.NewReader(`{"slug":"sound"}`)), "id", categoryID)
+	rec := httptest.NewRecorder()
+
+	handler.Update(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "sound", *service.updateInput.Slug)
+	require.Nil(t, service.updateInput.Name)
+}
+
+func TestHandler_Delete(t *testing.T) {
+	t.Run("category with items", func(t *testing.T) {
+		service := &stubService{deleteErr: categories.ErrorInUse}
+		handler := categories.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/categories/"+categoryID, nil), "id", categoryID)
+		rec := httptest.NewRecorder()
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, "category_in_use", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.deleteForce)
+	})
+
+	t.Run("force", func(t *testing.T) {
+		service := &stubService{}
+		handler := categories.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/categories/"+categoryID+"?force=true", nil), "id", categoryID)
+		rec := httptest.NewRecorder()
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.True(t, service.deleteForce)
+	})
+
+	t.Run("invalid force", func(t *testing.T) {
+		service := &stubService{}
+		handler := categories.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/categories/"+categoryID+"?force=yes", nil), "id", categoryID)
+		rec := httptest.NewRecorder()
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_force", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.called)
+	})
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
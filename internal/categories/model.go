@@ -0,0 +1,26 @@
+package categories
+
+import "time"
+
+// Category agrupa items del catálogo. Slug es único y sirve para URLs legibles.
+type Category struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateCategoryInput es el payload de POST /categories. Slug es opcional: si no viene,
+// el service lo genera a partir del nombre.
+type CreateCategoryInput struct {
+	Name string `json:"name"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// UpdateCategoryInput es el payload de PATCH /categories/{id}. Los campos nil no se tocan.
+// Cambiar el nombre no regenera el slug, así las URLs existentes siguen funcionando.
+type UpdateCategoryInput struct {
+	Name *string `json:"name,omitempty"`
+	Slug *string `json:"slug,omitempty"`
+}
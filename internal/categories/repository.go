@@ -0,0 +1,221 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// txBeginner lo implementa el pool; el borrado forzado lo usa para desasociar los items y
+// borrar la categoría en la misma transacción.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Repository accede a la tabla categories.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de categorías.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// categoryColumns son las columnas de Category en el orden en que las escanea categoryDestinations.
+const categoryColumns = `id, name, slug, created_at, updated_at`
+
+// categoryDestinations devuelve los destinos de Scan para las columnas de categoryColumns.
+func categoryDestinations(category *Category) []any {
+	return []any{&category.ID, &category.Name, &category.Slug, &category.CreatedAt, &category.UpdatedAt}
+}
+
+// Insert crea una categoría y devuelve el registro persistido.
+func (repository *Repository) Insert(ctx context.Context, input CreateCategoryInput) (Category, error) {
+	const query = `
+		INSERT INTO categories (name, slug)
+		VALUES ($1, $2)
+		RETURNING ` + categoryColumns + `;
+	`
+
+	var category Category
+	if err := repository.database.QueryRow(ctx, query, input.Name, input.Slug).Scan(categoryDestinations(&category)...); err != nil {
+		return Category{}, constraintViolation(err)
+	}
+	return category, nil
+}
+
+// List devuelve todas las categorías ordenadas por nombre. Son pocas: no se paginan.
+func (repository *Repository) List(ctx context.Context) ([]Category, error) {
+	const query = `SELECT ` + categoryColumns + ` FROM categories ORDER BY name, id;`
+
+	rows, err := repository.database.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]Category, 0)
+	for rows.Next() {
+		var category Category
+		if err := rows.Scan(categoryDestinations(&category)...); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// GetByID busca una categoría por su ID. Devuelve ErrorNotFound si no existe.
+func (repository *Repository) GetByID(ctx context.Context, id string) (Category, error) {
+	const query = `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1;`
+
+	var category Category
+	if err := repository.database.QueryRow(ctx, query, id).Scan(categoryDestinations(&category)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Category{}, ErrorNotFound
+		}
+		return Category{}, err
+	}
+	return category, nil
+}
+
+// Update aplica un PATCH parcial. Devuelve ErrorNotFound si la categoría no existe. Los items
+// muestran el nombre de su categoría, así que si cambia les sube la versión y los avisa en
+// items.ChangesChannel en la misma sentencia.
+func (repository *Repository) Update(ctx context.Context, id string, input UpdateCategoryInput) (Category, error) {
+	setParts := make([]string, 0, 3)
+	args := make([]any, 0, 3)
+	if input.Name != nil {
+		args = append(args, *input.Name)
+		setParts = append(setParts, fmt.Sprintf("name = $%d", len(args)))
+	}
+	if input.Slug != nil {
+		args = append(args, *input.Slug)
+		setParts = append(setParts, fmt.Sprintf("slug = $%d", len(args)))
+	}
+	if len(setParts) == 0 {
+		return Category{}, ErrorInvalidInput
+	}
+	setParts = append(setParts, "updated_at = now()")
+	args = append(args, id)
+
+	// Sin nombre nuevo los items no cambian: touched queda vacío. Las lecturas de categories dentro
+	// del CTE ven el nombre anterior.
+	renamed := "false"
+	if input.Name != nil {
+		renamed = fmt.Sprintf("EXISTS (SELECT 1 FROM categories previous WHERE previous.id = $%d AND previous.name <> $1)", len(args))
+	}
+	query := fmt.Sprintf(`
+		WITH updated AS (
+			UPDATE categories
+			SET %s
+			WHERE id = $%d
+			RETURNING %s
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE category_id IN (SELECT id FROM updated) AND %s
+			RETURNING id
+		)
+		SELECT %s, %s FROM updated;
+	`, strings.Join(setParts, ", "), len(args), categoryColumns, renamed, categoryColumns, items.TouchedNotification)
+
+	var (
+		category Category
+		notified int
+	)
+	if err := repository.database.QueryRow(ctx, query, args...).Scan(append(categoryDestinations(&category), &notified)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Category{}, ErrorNotFound
+		}
+		return Category{}, constraintViolation(err)
+	}
+	return category, nil
+}
+
+// Delete borra la categoría. Si algún item (incluidos los de la papelera) la usa, la FK lo impide
+// y devuelve ErrorInUse. Con force primero deja esos items sin categoría (con nueva versión y aviso
+// en items.ChangesChannel, porque cambia su representación) y borra la categoría en la misma
+// transacción.
+func (repository *Repository) Delete(ctx context.Context, id string, force bool) error {
+	if !force {
+		return deleteCategory(ctx, repository.database, id)
+	}
+
+	beginner, ok := repository.database.(txBeginner)
+	if !ok {
+		return errors.New("categories: database does not support transactions")
+	}
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback después de Commit no hace nada; cubre los caminos de error.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const detach = `
+		WITH touched AS (
+			UPDATE items SET category_id = NULL, updated_at = now(), version = version + 1
+			WHERE category_id = $1
+			RETURNING id
+		)
+		SELECT ` + items.TouchedNotification + `;
+	`
+	var notified int
+	if err := tx.QueryRow(ctx, detach, id).Scan(&notified); err != nil {
+		return err
+	}
+	if err := deleteCategory(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// deleteCategory borra la categoría con database (el pool o una transacción).
+func deleteCategory(ctx context.Context, database dbQuerier, id string) error {
+	const query = `DELETE FROM categories WHERE id = $1 RETURNING id;`
+
+	var deletedID string
+	if err := database.QueryRow(ctx, query, id).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return constraintViolation(err)
+	}
+	return nil
+}
+
+// constraintViolation traduce una violación de constraint al error de dominio: unicidad (23505)
+// según el índice y la FK de items (23503) como ErrorInUse.
+func constraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if !errors.As(err, &postgresError) {
+		return err
+	}
+	switch postgresError.Code {
+	case "23503":
+		if postgresError.ConstraintName == "fk_items_category" {
+			return ErrorInUse
+		}
+	case "23505":
+		switch postgresError.ConstraintName {
+		case "ux_categories_slug":
+			return ErrorDuplicateSlug
+		case "ux_categories_name":
+			return ErrorDuplicateName
+		}
+	}
+	return err
+}
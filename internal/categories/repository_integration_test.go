@@ -0,0 +1,107 @@
+//go:build integration
+
+package categories_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/categories"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_CategoryLifecycle(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	categoryService := categories.NewService(categories.NewRepository(pool))
+	itemsRepository := items.NewRepository(pool)
+	itemsService := items.NewService(itemsRepository)
+
+	category, err := categoryService.Create(ctx, categories.CreateCategoryInput{Name: "Audio " + uuid.NewString()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = categoryService.Delete(ctx, category.ID, true) })
+
+	sku := "IT-" + strings.ToUpper(uuid.NewString()[:8])
+	item, err := itemsService.Create(ctx, items.CreateItemInput{Name: "Headphones " + uuid.NewString(), SKU: &sku, CategoryID: &category.ID, Price: "5.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = itemsRepository.Delete(ctx, item.ID, nil)
+		_ = itemsRepository.Purge(ctx, item.ID)
+	})
+	require.Equal(t, &items.ItemCategory{ID: category.ID, Name: category.Name}, item.Category)
+
+	page, err := itemsService.List(ctx, 1, 10, items.ListFilter{CategoryID: category.ID})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+
+	// El nombre de la categoría es parte del item: renombrarla le sube la versión y lo avisa.
+	conn, err := pgx.Connect(ctx, databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close(ctx) })
+	_, err = conn.Exec(ctx, "LISTEN "+items.ChangesChannel)
+	require.NoError(t, err)
+	// waitPayload espera el aviso del item; saltea los de otros tests que corren contra la misma base.
+	waitPayload := func() items.ChangeNotification {
+		t.Helper()
+		waitContext, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		for {
+			notification, err := conn.WaitForNotification(waitContext)
+			require.NoError(t, err)
+			var payload items.ChangeNotification
+			require.NoError(t, json.Unmarshal([]byte(notification.Payload), &payload))
+			if payload.ID == item.ID {
+				return payload
+			}
+		}
+	}
+
+	slug := "audio-" + strings.ToLower(uuid.NewString()[:8])
+	_, err = categoryService.Update(ctx, category.ID, categories.UpdateCategoryInput{Slug: &slug})
+	require.NoError(t, err)
+	unchanged, err := itemsService.Get(ctx, item.ID)
+	require.NoError(t, err)
+	require.Equal(t, item.Version, unchanged.Version, "the slug is not part of the item")
+
+	newName := "Sound " + uuid.NewString()
+	_, err = categoryService.Update(ctx, category.ID, categories.UpdateCategoryInput{Name: &newName})
+	require.NoError(t, err)
+	renamed, err := itemsService.Get(ctx, item.ID)
+	require.NoError(t, err)
+	require.Equal(t, newName, renamed.Category.Name)
+	require.Equal(t, item.Version+1, renamed.Version)
+	require.Equal(t, items.ChangeNotification{ID: item.ID, Operation: items.EventUpdated}, waitPayload())
+	item = renamed
+
+	unknown := uuid.NewString()
+	_, err = itemsService.Update(ctx, item.ID, items.UpdateItemInput{CategoryIDPresent: true, CategoryID: &unknown})
+	require.ErrorIs(t, err, items.ErrorUnknownCategory)
+
+	require.ErrorIs(t, categoryService.Delete(ctx, category.ID, false), categories.ErrorInUse)
+	require.NoError(t, categoryService.Delete(ctx, category.ID, true))
+
+	detached, err := itemsService.Get(ctx, item.ID)
+	require.NoError(t, err)
+	require.Nil(t, detached.Category)
+	require.Equal(t, item.Version+1, detached.Version)
+	require.Equal(t, items.ChangeNotification{ID: item.ID, Operation: items.EventUpdated}, waitPayload())
+}
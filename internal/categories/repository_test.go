@@ -0,0 +1,179 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"category-1", "Audio", "audio", now, now}}}
+		repository := NewRepository(database)
+
+		category, err := repository.Insert(context.Background(), CreateCategoryInput{Name: "Audio", Slug: "audio"})
+
+		require.NoError(t, err)
+		require.Equal(t, Category{ID: "category-1", Name: "Audio", Slug: "audio", CreatedAt: now, UpdatedAt: now}, category)
+		require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO categories (name, slug) VALUES ($1, $2)")
+		require.Equal(t, []any{"Audio", "audio"}, database.lastArgs)
+	})
+
+	t.Run("duplicates map to domain errors", func(t *testing.T) {
+		for constraint, want := range map[string]error{"ux_categories_name": ErrorDuplicateName, "ux_categories_slug": ErrorDuplicateSlug} {
+			database := &fakeDB{row: &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: constraint}}}
+			repository := NewRepository(database)
+
+			_, err := repository.Insert(context.Background(), CreateCategoryInput{Name: "Audio", Slug: "audio"})
+
+			require.ErrorIs(t, err, want)
+		}
+	})
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	database := &fakeDB{row: &fakeRow{err: pgx.ErrNoRows}}
+	repository := NewRepository(database)
+
+	_, err := repository.GetByID(context.Background(), "category-1")
+
+	require.ErrorIs(t, err, ErrorNotFound)
+}
+
+func TestRepository_Update(t *testing.T) {
+	t.Run("sets only the fields sent", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"category-1", "Sonido", "audio", now, now, 2}}}
+		repository := NewRepository(database)
+		name := "Sonido"
+
+		category, err := repository.Update(context.Background(), "category-1", UpdateCategoryInput{Name: &name})
+
+		require.NoError(t, err)
+		require.Equal(t, "Sonido", category.Name)
+		require.Contains(t, normalizeSQL(database.lastQuery), "SET name = $1, updated_at = now() WHERE id = $2")
+		require.Equal(t, []any{"Sonido", "category-1"}, database.lastArgs)
+	})
+
+	t.Run("a rename touches and notifies the items of the category", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"category-1", "Sonido", "audio", now, now, 2}}}
+		repository := NewRepository(database)
+		name := "Sonido"
+
+		_, err := repository.Update(context.Background(), "category-1", UpdateCategoryInput{Name: &name})
+
+		require.NoError(t, err)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE category_id IN (SELECT id FROM updated) "+
+			"AND EXISTS (SELECT 1 FROM categories previous WHERE previous.id = $2 AND previous.name <> $1)")
+		require.Contains(t, query, items.TouchedNotification)
+	})
+
+	t.Run("a slug change leaves the items alone", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"category-1", "Audio", "sonido", now, now, 0}}}
+		repository := NewRepository(database)
+		slug := "sonido"
+
+		_, err := repository.Update(context.Background(), "category-1", UpdateCategoryInput{Slug: &slug})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE category_id IN (SELECT id FROM updated) AND false")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: pgx.ErrNoRows}}
+		repository := NewRepository(database)
+		slug := "audio"
+
+		_, err := repository.Update(context.Background(), "category-1", UpdateCategoryInput{Slug: &slug})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_Delete(t *testing.T) {
+	t.Run("category with items", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_items_category"}}}
+		repository := NewRepository(database)
+
+		err := repository.Delete(context.Background(), "category-1", false)
+
+		require.ErrorIs(t, err, ErrorInUse)
+		require.Contains(t, normalizeSQL(database.lastQuery), "DELETE FROM categories WHERE id = $1")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: pgx.ErrNoRows}}
+		repository := NewRepository(database)
+
+		err := repository.Delete(context.Background(), "category-1", false)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("force needs transactions", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		err := repository.Delete(context.Background(), "category-1", true)
+
+		require.Error(t, err)
+		require.Empty(t, database.lastQuery)
+	})
+}
+
+type fakeDB struct {
+	row       *fakeRow
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.row == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.row
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	return nil, errors.New("unexpected Query call")
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	if len(dest) != len(row.values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(row.values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(row.values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -0,0 +1,14 @@
+package categories
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra las rutas de categorías en el router.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Route("/categories", func(route chi.Router) {
+		route.Post("/", handler.Create)
+		route.Get("/", handler.List)
+		route.Get("/{id}", handler.Get)
+		route.Patch("/{id}", handler.Update)
+		route.Delete("/{id}", handler.Delete)
+	})
+}
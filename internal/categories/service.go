@@ -0,0 +1,97 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// Errores de dominio (no HTTP). El handler los traduce a status codes.
+var (
+	ErrorInvalidInput = errors.New("invalid category input")
+	ErrorInvalidName  = fmt.Errorf("%w: name must not be empty", ErrorInvalidInput)
+	ErrorInvalidSlug  = fmt.Errorf("%w: slug must be lowercase letters, digits and hyphens, up to 120 characters", ErrorInvalidInput)
+	// ErrorDuplicateName y ErrorDuplicateSlug indican que otra categoría ya usa ese nombre o slug.
+	ErrorDuplicateName = errors.New("duplicate category name")
+	ErrorDuplicateSlug = errors.New("duplicate category slug")
+	ErrorNotFound      = errors.New("category not found")
+	// ErrorInUse indica que hay items en la categoría y el borrado no es forzado.
+	ErrorInUse = errors.New("category has items")
+)
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	Insert(ctx context.Context, input CreateCategoryInput) (Category, error)
+	List(ctx context.Context) ([]Category, error)
+	GetByID(ctx context.Context, id string) (Category, error)
+	Update(ctx context.Context, id string, input UpdateCategoryInput) (Category, error)
+	Delete(ctx context.Context, id string, force bool) error
+}
+
+// Service contiene las reglas de las categorías.
+type Service struct {
+	repository RepositoryAPI
+}
+
+// NewService crea un service de categorías.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository}
+}
+
+// Create valida y crea una categoría. Sin slug lo genera a partir del nombre, con el mismo formato
+// que el de los items; si ese slug ya existe es ErrorDuplicateSlug.
+func (service *Service) Create(ctx context.Context, input CreateCategoryInput) (Category, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Slug = strings.TrimSpace(input.Slug)
+	if input.Name == "" {
+		return Category{}, ErrorInvalidName
+	}
+	if input.Slug == "" {
+		input.Slug = items.Slugify(input.Name)
+	}
+	if !items.IsValidSlug(input.Slug) {
+		return Category{}, ErrorInvalidSlug
+	}
+	return service.repository.Insert(ctx, input)
+}
+
+// List devuelve todas las categorías.
+func (service *Service) List(ctx context.Context) ([]Category, error) {
+	return service.repository.List(ctx)
+}
+
+// Get devuelve una categoría por ID.
+func (service *Service) Get(ctx context.Context, id string) (Category, error) {
+	return service.repository.GetByID(ctx, id)
+}
+
+// Update valida y aplica un PATCH parcial. Tiene que venir al menos un campo.
+func (service *Service) Update(ctx context.Context, id string, input UpdateCategoryInput) (Category, error) {
+	if input.Name == nil && input.Slug == nil {
+		return Category{}, ErrorInvalidInput
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return Category{}, ErrorInvalidName
+		}
+		input.Name = &name
+	}
+	if input.Slug != nil {
+		slug := strings.TrimSpace(*input.Slug)
+		if !items.IsValidSlug(slug) {
+			return Category{}, ErrorInvalidSlug
+		}
+		input.Slug = &slug
+	}
+	return service.repository.Update(ctx, id, input)
+}
+
+// Delete borra una categoría. Sin force, una categoría con items es ErrorInUse; con force los items
+// quedan sin categoría.
+func (service *Service) Delete(ctx context.Context, id string, force bool) error {
+	return service.repository.Delete(ctx, id, force)
+}
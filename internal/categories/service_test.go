@@ -0,0 +1,145 @@
+package categories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	insertInput  CreateCategoryInput
+	insertCalled bool
+	insertErr    error
+	updateInput  UpdateCategoryInput
+	updateCalled bool
+	deleteForce  bool
+	deleteErr    error
+}
+
+func (fakerepo *fakeRepo) Insert(ctx context.Context, input CreateCategoryInput) (Category, error) {
+	fakerepo.insertCalled = true
+	fakerepo.insertInput = input
+	if fakerepo.insertErr != nil {
+		return Category{}, fakerepo.insertErr
+	}
+	return Category{ID: "category-1", Name: input.Name, Slug: input.Slug}, nil
+}
+
+func (fakerepo *fakeRepo) List(ctx context.Context) ([]Category, error) {
+	return []Category{}, nil
+}
+
+func (fakerepo *fakeRepo) GetByID(ctx context.Context, id string) (Category, error) {
+	return Category{ID: id}, nil
+}
+
+func (fakerepo *fakeRepo) Update(ctx context.Context, id string, input UpdateCategoryInput) (Category, error) {
+	fakerepo.updateCalled = true
+	fakerepo.updateInput = input
+	return Category{ID: id}, nil
+}
+
+func (fakerepo *fakeRepo) Delete(ctx context.Context, id string, force bool) error {
+	fakerepo.deleteForce = force
+	return fakerepo.deleteErr
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("generates the slug from the name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		category, err := service.Create(context.Background(), CreateCategoryInput{Name: "  Periféricos de Audio "})
+
+		require.NoError(t, err)
+		require.Equal(t, "Periféricos de Audio", category.Name)
+		require.Equal(t, "perifericos-de-audio", category.Slug)
+	})
+
+	t.Run("keeps an explicit slug", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateCategoryInput{Name: "Audio", Slug: "sound"})
+
+		require.NoError(t, err)
+		require.Equal(t, "sound", repository.insertInput.Slug)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateCategoryInput{Name: "  "})
+
+		require.ErrorIs(t, err, ErrorInvalidName)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateCategoryInput{Name: "Audio", Slug: "Sound Stuff"})
+
+		require.ErrorIs(t, err, ErrorInvalidSlug)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("duplicate is passed through", func(t *testing.T) {
+		repository := &fakeRepo{insertErr: ErrorDuplicateName}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateCategoryInput{Name: "Audio"})
+
+		require.ErrorIs(t, err, ErrorDuplicateName)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	t.Run("requires at least one field", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "category-1", UpdateCategoryInput{})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("trims the name and keeps the slug", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		name := " Sonido "
+
+		_, err := service.Update(context.Background(), "category-1", UpdateCategoryInput{Name: &name})
+
+		require.NoError(t, err)
+		require.Equal(t, "Sonido", *repository.updateInput.Name)
+		require.Nil(t, repository.updateInput.Slug)
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		slug := "-audio"
+
+		_, err := service.Update(context.Background(), "category-1", UpdateCategoryInput{Slug: &slug})
+
+		require.ErrorIs(t, err, ErrorInvalidSlug)
+		require.False(t, repository.updateCalled)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	repository := &fakeRepo{deleteErr: ErrorInUse}
+	service := NewService(repository)
+
+	err := service.Delete(context.Background(), "category-1", false)
+	require.ErrorIs(t, err, ErrorInUse)
+
+	repository.deleteErr = nil
+	require.NoError(t, service.Delete(context.Background(), "category-1", true))
+	require.True(t, repository.deleteForce)
+}
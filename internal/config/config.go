@@ -3,13 +3,116 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/currency"
+	"github.com/Lelo88/catalog-api-golang/internal/jobs"
+	"github.com/Lelo88/catalog-api-golang/internal/locale"
 )
 
 // Config agrupa la configuración necesaria para correr la aplicación.
 type Config struct {
 	Port        string
 	DatabaseURL string
+	// StrictPagination hace que un limit mayor al máximo devuelva 400 en vez de recortarse.
+	StrictPagination bool
+	// AllowUnknownQueryParams hace que los GET de items ignoren los parámetros de query que no conocen
+	// en lugar de responder 400; es para proxies que agregan parámetros de tracking.
+	AllowUnknownQueryParams bool
+	// PaginationDefaultLimit es el limit de GET /items cuando no se pide uno.
+	PaginationDefaultLimit int
+	// PaginationMaxLimit es el limit máximo de GET /items (se recorta o, con StrictPagination, da 400).
+	PaginationMaxLimit int
+	// PaginationMaxOffset es el tope de page*limit en GET /items; más profundo devuelve 400. 0 lo desactiva.
+	PaginationMaxOffset int
+	// XLSXExportMaxRows es cuántos items puede tener GET /items/export?format=xlsx; un filtro que
+	// matchea más responde 400. No puede pasar las filas de una hoja de Excel.
+	XLSXExportMaxRows int
+	// NameBlacklistPattern es una regex opcional; los items cuyo nombre matchea se rechazan.
+	NameBlacklistPattern string
+	// CountEstimate hace que GET /items sin filtros informe el total estimado por la base en vez de un COUNT(*).
+	CountEstimate bool
+	// RequireIfMatch hace que PATCH y DELETE de un item sin If-Match respondan 428.
+	RequireIfMatch bool
+	// FuzzyThreshold es el score mínimo de similitud (0 a 1) para GET /items?fuzzy=true.
+	FuzzyThreshold float64
+	// DidYouMeanThreshold es la similitud mínima (0 a 1) del nombre que GET /items sugiere cuando una
+	// búsqueda no encuentra nada. 0 desactiva la sugerencia.
+	DidYouMeanThreshold float64
+	// ConcurrencyLimit acota los requests concurrentes que tocan la DB. 0 usa el tamaño del pool.
+	ConcurrencyLimit int
+	// ConcurrencyQueue es cuántos requests pueden esperar lugar antes de rechazar con 503.
+	ConcurrencyQueue int
+	// ConcurrencyWait es cuánto espera un request encolado antes de rechazarse.
+	ConcurrencyWait time.Duration
+	// QueryDeadlineMargin es el tiempo del request que se reserva para serializar la respuesta.
+	QueryDeadlineMargin time.Duration
+	// QueryTimeout es el tope de duración de cada query, aunque el request tenga más tiempo.
+	QueryTimeout time.Duration
+	// ReadyCacheTTL es cuánto se reutiliza el resultado de /ready antes de volver a pingear la DB.
+	ReadyCacheTTL time.Duration
+	// CatalogStatsInterval es cada cuánto se refrescan las métricas de tamaño del catálogo. 0 lo desactiva.
+	CatalogStatsInterval time.Duration
+	// TrashRetentionDays es cuántos días queda un item borrado en la papelera antes de que el job
+	// lo borre definitivamente. 0 desactiva el job.
+	TrashRetentionDays int
+	// ReservationSweepInterval es cada cuánto se borran las reservas de stock vencidas. 0 lo desactiva.
+	ReservationSweepInterval time.Duration
+	// PriceScheduleInterval es cada cuánto se aplican los cambios de precio programados vencidos. 0 lo desactiva.
+	PriceScheduleInterval time.Duration
+	// WebhookDeliveryInterval es cada cuánto el job de webhooks manda las entregas pendientes. 0 lo desactiva.
+	WebhookDeliveryInterval time.Duration
+	// WebhookMaxAttempts es cuántas veces se intenta una entrega antes de pasarla a dead.
+	WebhookMaxAttempts int
+	// OutboxRelayInterval es cada cuánto el relay pasa los eventos del outbox a los webhooks y al bus. 0 lo
+	// desactiva: los eventos se siguen guardando pero no salen.
+	OutboxRelayInterval time.Duration
+	// AuditLogFlushInterval es cada cuánto se escriben en la DB los requests de escritura anotados
+	// para el log de auditoría. 0 desactiva el log: el middleware no se registra.
+	AuditLogFlushInterval time.Duration
+	// AuditLogQueueSize es cuántas entradas del log pueden esperar el próximo flush; con la cola
+	// llena las nuevas se descartan (catalog_audit_log_dropped_total).
+	AuditLogQueueSize int
+	// JobWorkerInterval es cada cuánto el worker busca jobs asincrónicos (imports) encolados. 0 lo
+	// desactiva en esta instancia: los jobs quedan queued hasta que otra los tome.
+	JobWorkerInterval time.Duration
+	// ImportUploadTTL es cuánto dura una subida por partes de un import (POST /imports/uploads) desde
+	// que se abre. Las vencidas se borran, salvo las de un import que todavía no terminó.
+	ImportUploadTTL time.Duration
+	// BackorderStockFloor es el stock más negativo que puede tener un item con allow_backorder. 0 no
+	// permite stock negativo aunque el item tenga el flag.
+	BackorderStockFloor int
+	// DefaultCurrency es la moneda (ISO 4217) de los items que se crean sin currency.
+	DefaultCurrency string
+	// DefaultTaxRateBPS es la alícuota (en puntos básicos, 0 a 10000) de los items que se crean sin tax_rate_bps.
+	DefaultTaxRateBPS int
+	// SupportedLocales son los locales del catálogo. El primero es el idioma de los campos base de los
+	// items; los demás admiten traducciones y se pueden pedir con Accept-Language o ?locale=.
+	SupportedLocales []string
+
+	// EventsBackend es el bus al que salen los eventos de items: none (default, no salen) o nats.
+	EventsBackend string
+	// NATSURL es el servidor NATS (o varios separados por coma). Obligatorio con EventsBackend nats.
+	NATSURL string
+	// NATSSubjectPrefix antecede al tipo de evento en el subject: "catalog" publica en "catalog.item.created".
+	NATSSubjectPrefix string
+	// ExportSchedule es la expresión cron del export a S3 (por ejemplo "0 3 * * *"). Vacío no programa nada.
+	ExportSchedule string
+	// ExportFormat es el formato del archivo exportado: ndjson (default) o csv.
+	ExportFormat string
+	// ExportS3Endpoint es el host:puerto del storage compatible con S3 (AWS, MinIO, etc.).
+	// Vacío deshabilita el export, incluso el manual.
+	ExportS3Endpoint  string
+	ExportS3Bucket    string
+	ExportS3Prefix    string
+	ExportS3Region    string
+	ExportS3AccessKey string
+	ExportS3SecretKey string
+	ExportS3UseSSL    bool
 }
 
 // Load lee variables de entorno y valida lo mínimo indispensable.
@@ -26,8 +129,350 @@ func Load() (Config, error) {
 		return Config{}, fmt.Errorf("missing required env var: DATABASE_URL")
 	}
 
+	strictPagination, err := boolFromEnv("STRICT_PAGINATION", false)
+	if err != nil {
+		return Config{}, err
+	}
+	allowUnknownQueryParams, err := boolFromEnv("ALLOW_UNKNOWN_QUERY_PARAMS", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	paginationDefaultLimit, err := positiveIntFromEnv("PAGINATION_DEFAULT_LIMIT", 20)
+	if err != nil {
+		return Config{}, err
+	}
+	paginationMaxLimit, err := positiveIntFromEnv("PAGINATION_MAX_LIMIT", 100)
+	if err != nil {
+		return Config{}, err
+	}
+	if paginationDefaultLimit > paginationMaxLimit {
+		return Config{}, fmt.Errorf("invalid env var PAGINATION_DEFAULT_LIMIT: must not exceed PAGINATION_MAX_LIMIT (%d), got %d",
+			paginationMaxLimit, paginationDefaultLimit)
+	}
+
+	paginationMaxOffset, err := intFromEnv("PAGINATION_MAX_OFFSET", 10000)
+	if err != nil {
+		return Config{}, err
+	}
+
+	xlsxExportMaxRows, err := positiveIntFromEnv("XLSX_EXPORT_MAX_ROWS", 100000)
+	if err != nil {
+		return Config{}, err
+	}
+	// Una hoja tiene 1048576 filas y la primera es el encabezado.
+	if xlsxExportMaxRows > 1048575 {
+		return Config{}, fmt.Errorf("invalid env var XLSX_EXPORT_MAX_ROWS: must be at most 1048575, got %d", xlsxExportMaxRows)
+	}
+
+	nameBlacklistPattern := strings.TrimSpace(os.Getenv("ITEM_NAME_BLACKLIST_PATTERN"))
+	if nameBlacklistPattern != "" {
+		if _, err := regexp.Compile(nameBlacklistPattern); err != nil {
+			return Config{}, fmt.Errorf("invalid env var ITEM_NAME_BLACKLIST_PATTERN: %w", err)
+		}
+	}
+
+	countEstimate, err := boolFromEnv("ITEM_COUNT_ESTIMATE", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	requireIfMatch, err := boolFromEnv("REQUIRE_IF_MATCH", false)
+	if err != nil {
+		return Config{}, err
+	}
+
+	fuzzyThreshold, err := ratioFromEnv("ITEM_FUZZY_THRESHOLD", 0.3)
+	if err != nil {
+		return Config{}, err
+	}
+
+	didYouMeanThreshold, err := ratioFromEnv("ITEM_DID_YOU_MEAN_THRESHOLD", 0.4)
+	if err != nil {
+		return Config{}, err
+	}
+
+	concurrencyLimit, err := intFromEnv("DB_CONCURRENCY_LIMIT", 0)
+	if err != nil {
+		return Config{}, err
+	}
+	concurrencyQueue, err := intFromEnv("DB_CONCURRENCY_QUEUE", 10)
+	if err != nil {
+		return Config{}, err
+	}
+	concurrencyWait, err := durationFromEnv("DB_CONCURRENCY_WAIT", 250*time.Millisecond)
+	if err != nil {
+		return Config{}, err
+	}
+
+	queryDeadlineMargin, err := durationFromEnv("QUERY_DEADLINE_MARGIN", 200*time.Millisecond)
+	if err != nil {
+		return Config{}, err
+	}
+	queryTimeout, err := durationFromEnv("QUERY_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	readyCacheTTL, err := durationFromEnv("READY_CACHE_TTL", 2*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	catalogStatsInterval, err := durationFromEnv("CATALOG_STATS_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	trashRetentionDays, err := intFromEnv("TRASH_RETENTION_DAYS", 30)
+	if err != nil {
+		return Config{}, err
+	}
+	reservationSweepInterval, err := durationFromEnv("RESERVATION_SWEEP_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	priceScheduleInterval, err := durationFromEnv("PRICE_SCHEDULE_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	webhookDeliveryInterval, err := durationFromEnv("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	webhookMaxAttempts, err := positiveIntFromEnv("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return Config{}, err
+	}
+	outboxRelayInterval, err := durationFromEnv("OUTBOX_RELAY_INTERVAL", time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	jobWorkerInterval, err := durationFromEnv("JOB_WORKER_INTERVAL", 2*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	importUploadTTL, err := durationFromEnv("IMPORT_UPLOAD_TTL", 24*time.Hour)
+	if err != nil {
+		return Config{}, err
+	}
+	if importUploadTTL == 0 {
+		return Config{}, fmt.Errorf("invalid env var IMPORT_UPLOAD_TTL: must be greater than 0, got %s", importUploadTTL)
+	}
+	auditLogFlushInterval, err := durationFromEnv("AUDIT_LOG_FLUSH_INTERVAL", time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	auditLogQueueSize, err := positiveIntFromEnv("AUDIT_LOG_QUEUE_SIZE", 1000)
+	if err != nil {
+		return Config{}, err
+	}
+	backorderStockFloor, err := nonPositiveIntFromEnv("BACKORDER_STOCK_FLOOR", -1000)
+	if err != nil {
+		return Config{}, err
+	}
+
+	defaultCurrency := currency.Normalize(os.Getenv("DEFAULT_CURRENCY"))
+	if defaultCurrency == "" {
+		defaultCurrency = currency.Default
+	}
+	if !currency.IsSupported(defaultCurrency) {
+		return Config{}, fmt.Errorf("invalid env var DEFAULT_CURRENCY: unsupported ISO 4217 code %q", defaultCurrency)
+	}
+	defaultTaxRateBPS, err := intFromEnv("DEFAULT_TAX_RATE_BPS", 0)
+	if err != nil {
+		return Config{}, err
+	}
+	if defaultTaxRateBPS > 10000 {
+		return Config{}, fmt.Errorf("invalid env var DEFAULT_TAX_RATE_BPS: must be between 0 and 10000, got %d", defaultTaxRateBPS)
+	}
+
+	supportedLocales, err := localesFromEnv("SUPPORTED_LOCALES", locale.Default)
+	if err != nil {
+		return Config{}, err
+	}
+
+	eventsBackend := strings.ToLower(strings.TrimSpace(os.Getenv("EVENTS_BACKEND")))
+	if eventsBackend == "" {
+		eventsBackend = "none"
+	}
+	if eventsBackend != "none" && eventsBackend != "nats" {
+		return Config{}, fmt.Errorf("invalid env var EVENTS_BACKEND: must be none or nats, got %q", eventsBackend)
+	}
+	natsURL := strings.TrimSpace(os.Getenv("NATS_URL"))
+	if eventsBackend == "nats" && natsURL == "" {
+		return Config{}, fmt.Errorf("missing required env var: NATS_URL (EVENTS_BACKEND is nats)")
+	}
+	natsSubjectPrefix := strings.TrimSpace(os.Getenv("NATS_SUBJECT_PREFIX"))
+	if natsSubjectPrefix == "" {
+		natsSubjectPrefix = "catalog"
+	}
+	exportSchedule := strings.TrimSpace(os.Getenv("EXPORT_SCHEDULE"))
+	if _, err := jobs.ParseSchedule(exportSchedule); err != nil {
+		return Config{}, fmt.Errorf("invalid env var EXPORT_SCHEDULE: %w", err)
+	}
+	exportFormat := strings.TrimSpace(os.Getenv("EXPORT_FORMAT"))
+	if exportFormat == "" {
+		exportFormat = "ndjson"
+	}
+	if exportFormat != "ndjson" && exportFormat != "csv" {
+		return Config{}, fmt.Errorf("invalid env var EXPORT_FORMAT: must be ndjson or csv, got %q", exportFormat)
+	}
+	exportS3Endpoint := strings.TrimSpace(os.Getenv("EXPORT_S3_ENDPOINT"))
+	exportS3Bucket := strings.TrimSpace(os.Getenv("EXPORT_S3_BUCKET"))
+	if exportS3Endpoint != "" && exportS3Bucket == "" {
+		return Config{}, fmt.Errorf("missing required env var: EXPORT_S3_BUCKET (EXPORT_S3_ENDPOINT is set)")
+	}
+	if exportSchedule != "" && exportS3Endpoint == "" {
+		return Config{}, fmt.Errorf("missing required env var: EXPORT_S3_ENDPOINT (EXPORT_SCHEDULE is set)")
+	}
+	exportS3UseSSL, err := boolFromEnv("EXPORT_S3_USE_SSL", true)
+	if err != nil {
+		return Config{}, err
+	}
+
 	return Config{
-		Port:        port,
-		DatabaseURL: databaseURL,
+		Port:                     port,
+		DatabaseURL:              databaseURL,
+		StrictPagination:         strictPagination,
+		AllowUnknownQueryParams:  allowUnknownQueryParams,
+		PaginationDefaultLimit:   paginationDefaultLimit,
+		PaginationMaxLimit:       paginationMaxLimit,
+		PaginationMaxOffset:      paginationMaxOffset,
+		XLSXExportMaxRows:        xlsxExportMaxRows,
+		NameBlacklistPattern:     nameBlacklistPattern,
+		CountEstimate:            countEstimate,
+		RequireIfMatch:           requireIfMatch,
+		FuzzyThreshold:           fuzzyThreshold,
+		DidYouMeanThreshold:      didYouMeanThreshold,
+		ConcurrencyLimit:         concurrencyLimit,
+		ConcurrencyQueue:         concurrencyQueue,
+		ConcurrencyWait:          concurrencyWait,
+		QueryDeadlineMargin:      queryDeadlineMargin,
+		QueryTimeout:             queryTimeout,
+		ReadyCacheTTL:            readyCacheTTL,
+		CatalogStatsInterval:     catalogStatsInterval,
+		TrashRetentionDays:       trashRetentionDays,
+		ReservationSweepInterval: reservationSweepInterval,
+		PriceScheduleInterval:    priceScheduleInterval,
+		WebhookDeliveryInterval:  webhookDeliveryInterval,
+		WebhookMaxAttempts:       webhookMaxAttempts,
+		OutboxRelayInterval:      outboxRelayInterval,
+		AuditLogFlushInterval:    auditLogFlushInterval,
+		AuditLogQueueSize:        auditLogQueueSize,
+		JobWorkerInterval:        jobWorkerInterval,
+		ImportUploadTTL:          importUploadTTL,
+		BackorderStockFloor:      backorderStockFloor,
+		DefaultCurrency:          defaultCurrency,
+		DefaultTaxRateBPS:        defaultTaxRateBPS,
+		SupportedLocales:         supportedLocales,
+		EventsBackend:            eventsBackend,
+		NATSURL:                  natsURL,
+		NATSSubjectPrefix:        natsSubjectPrefix,
+		ExportSchedule:           exportSchedule,
+		ExportFormat:             exportFormat,
+		ExportS3Endpoint:         exportS3Endpoint,
+		ExportS3Bucket:           exportS3Bucket,
+		ExportS3Prefix:           strings.TrimSpace(os.Getenv("EXPORT_S3_PREFIX")),
+		ExportS3Region:           strings.TrimSpace(os.Getenv("EXPORT_S3_REGION")),
+		ExportS3AccessKey:        strings.TrimSpace(os.Getenv("EXPORT_S3_ACCESS_KEY")),
+		ExportS3SecretKey:        strings.TrimSpace(os.Getenv("EXPORT_S3_SECRET_KEY")),
+		ExportS3UseSSL:           exportS3UseSSL,
 	}, nil
 }
+
+// boolFromEnv lee una variable booleana opcional. Si no está seteada, devuelve fallback.
+func boolFromEnv(name string, fallback bool) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid env var %s: must be a boolean, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// intFromEnv lee un entero no negativo opcional. Si no está seteada, devuelve fallback.
+func intFromEnv(name string, fallback int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid env var %s: must be a non-negative integer, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// positiveIntFromEnv lee un entero mayor a cero opcional. Si no está seteada, devuelve fallback.
+func positiveIntFromEnv(name string, fallback int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		return 0, fmt.Errorf("invalid env var %s: must be a positive integer, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// nonPositiveIntFromEnv lee un entero menor o igual a cero opcional (por ejemplo "-500").
+// Si no está seteada, devuelve fallback.
+func nonPositiveIntFromEnv(name string, fallback int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed > 0 {
+		return 0, fmt.Errorf("invalid env var %s: must be zero or a negative integer, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// ratioFromEnv lee un número opcional entre 0 y 1 (por ejemplo "0.3"). Si no está seteada, devuelve fallback.
+func ratioFromEnv(name string, fallback float64) (float64, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 || parsed > 1 {
+		return 0, fmt.Errorf("invalid env var %s: must be a number between 0 and 1, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// durationFromEnv lee una duración opcional (por ejemplo "250ms" o "2s"). Si no está seteada, devuelve fallback.
+func durationFromEnv(name string, fallback time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid env var %s: must be a non-negative duration, got %q", name, value)
+	}
+	return parsed, nil
+}
+
+// localesFromEnv lee una lista de locales separados por coma ("en,es,pt-BR"), normalizados y sin
+// repetidos. Si no está seteada, devuelve fallback.
+func localesFromEnv(name string, fallback []string) ([]string, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return fallback, nil
+	}
+	var locales []string
+	for _, entry := range strings.Split(value, ",") {
+		tag := locale.Normalize(entry)
+		if !locale.IsValid(tag) {
+			return nil, fmt.Errorf("invalid env var %s: %q is not a locale", name, entry)
+		}
+		if !slices.Contains(locales, tag) {
+			locales = append(locales, tag)
+		}
+	}
+	return locales, nil
+}
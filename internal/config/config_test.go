@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -37,3 +38,782 @@ func TestLoad_CustomPort(t *testing.T) {
 	require.Equal(t, "9090", cfg.Port)
 	require.Equal(t, "postgres://example", cfg.DatabaseURL)
 }
+
+func TestLoad_StrictPagination(t *testing.T) {
+	t.Run("defaults to false", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("STRICT_PAGINATION", "")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.False(t, cfg.StrictPagination)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("STRICT_PAGINATION", "true")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.True(t, cfg.StrictPagination)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("STRICT_PAGINATION", "yes please")
+
+		cfg, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "STRICT_PAGINATION")
+		require.Equal(t, Config{}, cfg)
+	})
+}
+
+func TestLoad_AllowUnknownQueryParams(t *testing.T) {
+	t.Run("defaults to false", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.False(t, cfg.AllowUnknownQueryParams)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ALLOW_UNKNOWN_QUERY_PARAMS", "true")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.True(t, cfg.AllowUnknownQueryParams)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ALLOW_UNKNOWN_QUERY_PARAMS", "maybe")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ALLOW_UNKNOWN_QUERY_PARAMS")
+	})
+}
+
+func TestLoad_PaginationLimits(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 20, cfg.PaginationDefaultLimit)
+		require.Equal(t, 100, cfg.PaginationMaxLimit)
+		require.Equal(t, 10000, cfg.PaginationMaxOffset)
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("PAGINATION_DEFAULT_LIMIT", "50")
+		t.Setenv("PAGINATION_MAX_LIMIT", "500")
+		t.Setenv("PAGINATION_MAX_OFFSET", "0")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 50, cfg.PaginationDefaultLimit)
+		require.Equal(t, 500, cfg.PaginationMaxLimit)
+		require.Equal(t, 0, cfg.PaginationMaxOffset)
+	})
+
+	t.Run("default above max", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("PAGINATION_MAX_LIMIT", "10")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "PAGINATION_DEFAULT_LIMIT")
+		require.Contains(t, err.Error(), "PAGINATION_MAX_LIMIT")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for name, value := range map[string]string{
+			"PAGINATION_DEFAULT_LIMIT": "0",
+			"PAGINATION_MAX_LIMIT":     "lots",
+			"PAGINATION_MAX_OFFSET":    "-1",
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Setenv("DATABASE_URL", "postgres://example")
+				t.Setenv(name, value)
+
+				_, err := Load()
+
+				require.Error(t, err)
+				require.Contains(t, err.Error(), name)
+			})
+		}
+	})
+}
+
+func TestLoad_NameBlacklistPattern(t *testing.T) {
+	t.Run("valid pattern", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_NAME_BLACKLIST_PATTERN", "(?i)replica|fake")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, "(?i)replica|fake", cfg.NameBlacklistPattern)
+	})
+
+	t.Run("invalid pattern fails startup", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_NAME_BLACKLIST_PATTERN", "([a-z")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ITEM_NAME_BLACKLIST_PATTERN")
+	})
+}
+
+func TestLoad_Concurrency(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, cfg.ConcurrencyLimit)
+		require.Equal(t, 10, cfg.ConcurrencyQueue)
+		require.Equal(t, 250*time.Millisecond, cfg.ConcurrencyWait)
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("DB_CONCURRENCY_LIMIT", "32")
+		t.Setenv("DB_CONCURRENCY_QUEUE", "0")
+		t.Setenv("DB_CONCURRENCY_WAIT", "1s")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 32, cfg.ConcurrencyLimit)
+		require.Equal(t, 0, cfg.ConcurrencyQueue)
+		require.Equal(t, time.Second, cfg.ConcurrencyWait)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for name, value := range map[string]string{
+			"DB_CONCURRENCY_LIMIT": "-1",
+			"DB_CONCURRENCY_QUEUE": "many",
+			"DB_CONCURRENCY_WAIT":  "soon",
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Setenv("DATABASE_URL", "postgres://example")
+				t.Setenv(name, value)
+
+				_, err := Load()
+
+				require.Error(t, err)
+				require.Contains(t, err.Error(), name)
+			})
+		}
+	})
+}
+
+func TestLoad_QueryBudget(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 200*time.Millisecond, cfg.QueryDeadlineMargin)
+		require.Equal(t, 5*time.Second, cfg.QueryTimeout)
+		require.Equal(t, 2*time.Second, cfg.ReadyCacheTTL)
+		require.Equal(t, time.Minute, cfg.CatalogStatsInterval)
+		require.Equal(t, time.Minute, cfg.ReservationSweepInterval)
+		require.Equal(t, time.Minute, cfg.PriceScheduleInterval)
+	})
+
+	t.Run("invalid margin", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("QUERY_DEADLINE_MARGIN", "200")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "QUERY_DEADLINE_MARGIN")
+	})
+}
+
+func TestLoad_Export(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Empty(t, cfg.ExportSchedule)
+		require.Empty(t, cfg.ExportS3Endpoint)
+		require.Equal(t, "ndjson", cfg.ExportFormat)
+		require.True(t, cfg.ExportS3UseSSL)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("EXPORT_SCHEDULE", "0 3 * * *")
+		t.Setenv("EXPORT_FORMAT", "csv")
+		t.Setenv("EXPORT_S3_ENDPOINT", "minio:9000")
+		t.Setenv("EXPORT_S3_BUCKET", "warehouse")
+		t.Setenv("EXPORT_S3_PREFIX", "catalog/daily")
+		t.Setenv("EXPORT_S3_USE_SSL", "false")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, "0 3 * * *", cfg.ExportSchedule)
+		require.Equal(t, "csv", cfg.ExportFormat)
+		require.Equal(t, "minio:9000", cfg.ExportS3Endpoint)
+		require.Equal(t, "warehouse", cfg.ExportS3Bucket)
+		require.Equal(t, "catalog/daily", cfg.ExportS3Prefix)
+		require.False(t, cfg.ExportS3UseSSL)
+	})
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantVar string
+	}{
+		{"invalid schedule", map[string]string{"EXPORT_SCHEDULE": "nightly", "EXPORT_S3_ENDPOINT": "minio:9000", "EXPORT_S3_BUCKET": "b"}, "EXPORT_SCHEDULE"},
+		{"invalid format", map[string]string{"EXPORT_FORMAT": "xml"}, "EXPORT_FORMAT"},
+		{"schedule without endpoint", map[string]string{"EXPORT_SCHEDULE": "@daily"}, "EXPORT_S3_ENDPOINT"},
+		{"endpoint without bucket", map[string]string{"EXPORT_S3_ENDPOINT": "minio:9000"}, "EXPORT_S3_BUCKET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://example")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load()
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantVar)
+		})
+	}
+}
+
+func TestLoad_XLSXExportMaxRows(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 100000, cfg.XLSXExportMaxRows)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("XLSX_EXPORT_MAX_ROWS", "5000")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 5000, cfg.XLSXExportMaxRows)
+	})
+
+	t.Run("more than a sheet holds", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("XLSX_EXPORT_MAX_ROWS", "1048576")
+
+		_, err := Load()
+
+		require.ErrorContains(t, err, "XLSX_EXPORT_MAX_ROWS")
+	})
+}
+
+func TestLoad_RequireIfMatch(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.False(t, cfg.RequireIfMatch)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("REQUIRE_IF_MATCH", "true")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.True(t, cfg.RequireIfMatch)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("REQUIRE_IF_MATCH", "maybe")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "REQUIRE_IF_MATCH")
+	})
+}
+
+func TestLoad_TrashRetention(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 30, cfg.TrashRetentionDays)
+	})
+
+	t.Run("zero disables the purge", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("TRASH_RETENTION_DAYS", "0")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Zero(t, cfg.TrashRetentionDays)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("TRASH_RETENTION_DAYS", "-1")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "TRASH_RETENTION_DAYS")
+	})
+}
+
+func TestLoad_BackorderStockFloor(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, -1000, cfg.BackorderStockFloor)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("BACKORDER_STOCK_FLOOR", "-50")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, -50, cfg.BackorderStockFloor)
+	})
+
+	t.Run("positive value", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("BACKORDER_STOCK_FLOOR", "10")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "BACKORDER_STOCK_FLOOR")
+	})
+}
+
+func TestLoad_DefaultCurrency(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, "USD", cfg.DefaultCurrency)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("DEFAULT_CURRENCY", " eur ")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, "EUR", cfg.DefaultCurrency)
+	})
+
+	t.Run("unsupported code", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("DEFAULT_CURRENCY", "XXX")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "DEFAULT_CURRENCY")
+	})
+}
+
+func TestLoad_DefaultTaxRate(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, cfg.DefaultTaxRateBPS)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("DEFAULT_TAX_RATE_BPS", "2100")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 2100, cfg.DefaultTaxRateBPS)
+	})
+
+	for _, value := range []string{"-1", "10001", "21%"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			t.Setenv("DATABASE_URL", "postgres://example")
+			t.Setenv("DEFAULT_TAX_RATE_BPS", value)
+
+			_, err := Load()
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "DEFAULT_TAX_RATE_BPS")
+		})
+	}
+}
+
+func TestLoad_SupportedLocales(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"en", "es", "pt"}, cfg.SupportedLocales)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("SUPPORTED_LOCALES", " es, EN ,pt_BR,es")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"es", "en", "pt-br"}, cfg.SupportedLocales)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("SUPPORTED_LOCALES", "en,spanish!")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "SUPPORTED_LOCALES")
+	})
+}
+
+func TestLoad_CountEstimate(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.False(t, cfg.CountEstimate)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_COUNT_ESTIMATE", "true")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.True(t, cfg.CountEstimate)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_COUNT_ESTIMATE", "sometimes")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ITEM_COUNT_ESTIMATE")
+	})
+}
+
+func TestLoad_FuzzyThreshold(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 0.3, cfg.FuzzyThreshold)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_FUZZY_THRESHOLD", "0.45")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 0.45, cfg.FuzzyThreshold)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_FUZZY_THRESHOLD", "1.5")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ITEM_FUZZY_THRESHOLD")
+	})
+}
+
+func TestLoad_DidYouMeanThreshold(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 0.4, cfg.DidYouMeanThreshold)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_DID_YOU_MEAN_THRESHOLD", "0")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Zero(t, cfg.DidYouMeanThreshold)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("ITEM_DID_YOU_MEAN_THRESHOLD", "-0.1")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ITEM_DID_YOU_MEAN_THRESHOLD")
+	})
+}
+
+func TestLoad_Webhooks(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, cfg.WebhookDeliveryInterval)
+		require.Equal(t, 8, cfg.WebhookMaxAttempts)
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("WEBHOOK_DELIVERY_INTERVAL", "0")
+		t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Zero(t, cfg.WebhookDeliveryInterval)
+		require.Equal(t, 3, cfg.WebhookMaxAttempts)
+	})
+
+	t.Run("invalid max attempts", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("WEBHOOK_MAX_ATTEMPTS", "0")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "WEBHOOK_MAX_ATTEMPTS")
+	})
+}
+
+func TestLoad_OutboxRelayInterval(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, time.Second, cfg.OutboxRelayInterval)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("OUTBOX_RELAY_INTERVAL", "0")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Zero(t, cfg.OutboxRelayInterval)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("OUTBOX_RELAY_INTERVAL", "soon")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "OUTBOX_RELAY_INTERVAL")
+	})
+}
+
+func TestLoad_JobWorkerInterval(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 2*time.Second, cfg.JobWorkerInterval)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("JOB_WORKER_INTERVAL", "often")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "JOB_WORKER_INTERVAL")
+	})
+}
+
+func TestLoad_ImportUploadTTL(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 24*time.Hour, cfg.ImportUploadTTL)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("IMPORT_UPLOAD_TTL", "2h")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, 2*time.Hour, cfg.ImportUploadTTL)
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("IMPORT_UPLOAD_TTL", "0")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "IMPORT_UPLOAD_TTL")
+	})
+}
+
+func TestLoad_AuditLog(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, time.Second, cfg.AuditLogFlushInterval)
+		require.Equal(t, 1000, cfg.AuditLogQueueSize)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("AUDIT_LOG_FLUSH_INTERVAL", "0")
+		t.Setenv("AUDIT_LOG_QUEUE_SIZE", "50")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Zero(t, cfg.AuditLogFlushInterval)
+		require.Equal(t, 50, cfg.AuditLogQueueSize)
+	})
+
+	t.Run("invalid queue size", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("AUDIT_LOG_QUEUE_SIZE", "0")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "AUDIT_LOG_QUEUE_SIZE")
+	})
+}
+
+func TestLoad_EventsBackend(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, "none", cfg.EventsBackend)
+		require.Equal(t, "catalog", cfg.NATSSubjectPrefix)
+	})
+
+	t.Run("nats", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("EVENTS_BACKEND", "NATS")
+		t.Setenv("NATS_URL", "nats://localhost:4222")
+		t.Setenv("NATS_SUBJECT_PREFIX", "shop.catalog")
+
+		cfg, err := Load()
+
+		require.NoError(t, err)
+		require.Equal(t, "nats", cfg.EventsBackend)
+		require.Equal(t, "nats://localhost:4222", cfg.NATSURL)
+		require.Equal(t, "shop.catalog", cfg.NATSSubjectPrefix)
+	})
+
+	t.Run("nats without url", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("EVENTS_BACKEND", "nats")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "NATS_URL")
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://example")
+		t.Setenv("EVENTS_BACKEND", "kafka")
+
+		_, err := Load()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "EVENTS_BACKEND")
+	})
+}
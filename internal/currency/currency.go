@@ -0,0 +1,56 @@
+package currency
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// La lista de monedas ISO 4217 que acepta el catálogo y cuántos decimales usa cada una.
+// La comparten el service de items y la validación de DEFAULT_CURRENCY.
+
+// Default es la moneda de los items cuando no se configura DEFAULT_CURRENCY.
+const Default = "USD"
+
+// minorUnits son las monedas soportadas y sus decimales (ISO 4217). Las de tres decimales
+// (BHD, KWD, OMR, etc.) no están: price es numeric(10,2) y no puede guardarlas.
+var minorUnits = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BOB": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0,
+	"CNY": 2, "COP": 2, "CRC": 2, "CZK": 2, "DKK": 2, "DOP": 2, "EGP": 2, "EUR": 2, "GBP": 2,
+	"GTQ": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JPY": 0, "KRW": 0,
+	"MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2, "PEN": 2, "PHP": 2, "PLN": 2, "PYG": 0, "RON": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "UYU": 2, "VND": 0,
+	"XAF": 0, "XOF": 0, "ZAR": 2,
+}
+
+// Normalize recorta y pasa a mayúsculas el código ("eur " queda "EUR").
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsSupported indica si code (ya normalizado) es una moneda de la lista.
+func IsSupported(code string) bool {
+	_, ok := minorUnits[code]
+	return ok
+}
+
+// WholeUnit devuelve las monedas sin centavos (0 decimales), ordenadas. Sirve para redondear
+// en SQL con los decimales de la moneda de cada fila.
+func WholeUnit() []string {
+	var codes []string
+	for _, code := range slices.Sorted(maps.Keys(minorUnits)) {
+		if minorUnits[code] == 0 {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Decimals devuelve cuántos decimales admite code: 0 para monedas sin centavos como JPY.
+// Una moneda desconocida usa 2, el máximo que admite price.
+func Decimals(code string) int {
+	if decimals, ok := minorUnits[code]; ok {
+		return decimals
+	}
+	return 2
+}
@@ -0,0 +1,22 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrency(t *testing.T) {
+	require.Equal(t, "EUR", Normalize(" eur "))
+	require.True(t, IsSupported("EUR"))
+	require.True(t, IsSupported(Default))
+	require.False(t, IsSupported("eur"))
+	require.False(t, IsSupported("XXX"))
+	require.False(t, IsSupported("KWD"))
+
+	require.Equal(t, 2, Decimals("EUR"))
+	require.Equal(t, 0, Decimals("JPY"))
+	require.Equal(t, 2, Decimals("XXX"))
+
+	require.Equal(t, []string{"CLP", "ISK", "JPY", "KRW", "PYG", "VND", "XAF", "XOF"}, WholeUnit())
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrorNoBudget indica que al request no le queda tiempo suficiente para correr una query
+// (el deadline menos el margen reservado ya pasó). Conviene fallar rápido antes de tocar la DB.
+var ErrorNoBudget = errors.New("db: not enough time left before the request deadline")
+
+// QueryBudget deriva el contexto de cada query a partir del deadline del request.
+// El deadline derivado es el del request menos Margin (tiempo reservado para serializar la respuesta),
+// y nunca más lejos que Ceiling desde ahora. El valor cero no modifica el contexto.
+type QueryBudget struct {
+	margin  time.Duration
+	ceiling time.Duration
+	now     func() time.Time
+}
+
+// NewQueryBudget crea un QueryBudget. ceiling en 0 significa sin tope por query.
+func NewQueryBudget(margin, ceiling time.Duration) QueryBudget {
+	return QueryBudget{margin: margin, ceiling: ceiling, now: time.Now}
+}
+
+// Context devuelve el contexto con el que correr una query.
+// Si el margen no deja tiempo, devuelve ErrorNoBudget y no hay que ejecutar la query.
+// El cancel devuelto siempre debe llamarse.
+func (budget QueryBudget) Context(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	now := time.Now
+	if budget.now != nil {
+		now = budget.now
+	}
+	current := now()
+
+	var deadline time.Time
+	if requestDeadline, ok := ctx.Deadline(); ok {
+		deadline = requestDeadline.Add(-budget.margin)
+		if !deadline.After(current) {
+			return ctx, func() {}, ErrorNoBudget
+		}
+	}
+	if budget.ceiling > 0 {
+		ceilingDeadline := current.Add(budget.ceiling)
+		if deadline.IsZero() || ceilingDeadline.Before(deadline) {
+			deadline = ceilingDeadline
+		}
+	}
+
+	if deadline.IsZero() {
+		return ctx, func() {}, nil
+	}
+	derived, cancel := context.WithDeadline(ctx, deadline)
+	return derived, cancel, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBudget_Context(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := func() time.Time { return now }
+
+	tests := []struct {
+		name         string
+		margin       time.Duration
+		ceiling      time.Duration
+		remaining    time.Duration // 0 = el request no tiene deadline
+		wantErr      bool
+		wantDeadline time.Duration // relativo a now; 0 = sin deadline
+	}{
+		{name: "no request deadline and no ceiling", margin: 200 * time.Millisecond},
+		{name: "no request deadline uses ceiling", margin: 200 * time.Millisecond, ceiling: 3 * time.Second, wantDeadline: 3 * time.Second},
+		{name: "plenty of budget is capped by ceiling", margin: 200 * time.Millisecond, ceiling: 3 * time.Second, remaining: 10 * time.Second, wantDeadline: 3 * time.Second},
+		{name: "tight budget subtracts margin", margin: 200 * time.Millisecond, ceiling: 3 * time.Second, remaining: time.Second, wantDeadline: 800 * time.Millisecond},
+		{name: "no ceiling subtracts margin", margin: 200 * time.Millisecond, remaining: 10 * time.Second, wantDeadline: 9800 * time.Millisecond},
+		{name: "margin consumes the whole budget", margin: 200 * time.Millisecond, ceiling: 3 * time.Second, remaining: 200 * time.Millisecond, wantErr: true},
+		{name: "deadline already passed", margin: 200 * time.Millisecond, remaining: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := NewQueryBudget(tt.margin, tt.ceiling)
+			budget.now = fakeClock
+
+			parent := context.Background()
+			if tt.remaining != 0 {
+				var cancel context.CancelFunc
+				parent, cancel = context.WithDeadline(parent, now.Add(tt.remaining))
+				defer cancel()
+			}
+
+			ctx, cancel, err := budget.Context(parent)
+			defer cancel()
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrorNoBudget)
+				return
+			}
+			require.NoError(t, err)
+
+			deadline, ok := ctx.Deadline()
+			if tt.wantDeadline == 0 {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, now.Add(tt.wantDeadline), deadline)
+		})
+	}
+}
+
+func TestQueryBudget_ZeroValue(t *testing.T) {
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelParent()
+	expected, _ := parent.Deadline()
+
+	ctx, cancel, err := QueryBudget{}.Context(parent)
+	defer cancel()
+
+	require.NoError(t, err)
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, expected, deadline)
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ListenConn es lo que el Listener usa de su conexión dedicada. *pgx.Conn la implementa; los tests
+// la reemplazan.
+type ListenConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// Dialer abre la conexión del Listener.
+type Dialer func(ctx context.Context) (ListenConn, error)
+
+// NewDialer devuelve un Dialer que conecta a databaseURL por fuera del pool: LISTEN necesita una
+// conexión propia durante toda la vida del proceso.
+func NewDialer(databaseURL string) Dialer {
+	return func(ctx context.Context) (ListenConn, error) {
+		return pgx.Connect(ctx, databaseURL)
+	}
+}
+
+// Subscriber recibe lo que pasa en el canal que escucha el Listener.
+type Subscriber interface {
+	// Listening se llama cada vez que el Listener (re)conecta y ya escucha el canal. Lo que cambió
+	// mientras estaba desconectado no llega como notificación.
+	Listening()
+	// Lost se llama al perder la conexión; hasta el próximo Listening no llegan notificaciones.
+	Lost()
+	// Notify recibe el payload de cada NOTIFY del canal, incluidos los de este proceso.
+	Notify(payload string)
+}
+
+// Espera entre reconexiones del Listener: arranca en minListenBackoff y se duplica hasta
+// maxListenBackoff mientras no logre escuchar.
+const (
+	minListenBackoff = 500 * time.Millisecond
+	maxListenBackoff = 30 * time.Second
+	// listenCloseTimeout acota cuánto espera el cierre de la conexión al apagar.
+	listenCloseTimeout = time.Second
+)
+
+// Listener escucha un canal de PostgreSQL (LISTEN) con una conexión dedicada y le pasa cada
+// notificación a un Subscriber. Si la conexión se cae, reconecta con backoff.
+type Listener struct {
+	dial       Dialer
+	channel    string
+	subscriber Subscriber
+	logf       func(format string, args ...any)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// ListenerOption configura un Listener.
+type ListenerOption func(*Listener)
+
+// WithListenerLogf cambia dónde se loguean las desconexiones (por defecto log.Printf).
+func WithListenerLogf(logf func(format string, args ...any)) ListenerOption {
+	return func(listener *Listener) {
+		listener.logf = logf
+	}
+}
+
+// NewListener crea un listener del canal channel.
+func NewListener(dial Dialer, channel string, subscriber Subscriber, options ...ListenerOption) *Listener {
+	listener := &Listener{
+		dial:       dial,
+		channel:    channel,
+		subscriber: subscriber,
+		logf:       log.Printf,
+		minBackoff: minListenBackoff,
+		maxBackoff: maxListenBackoff,
+	}
+	for _, option := range options {
+		option(listener)
+	}
+	return listener
+}
+
+// Run escucha hasta que se cancela ctx, reconectando cada vez que la conexión falla. Al cancelarse
+// cierra la conexión y devuelve nil.
+func (listener *Listener) Run(ctx context.Context) error {
+	backoff := listener.minBackoff
+	for {
+		listened, err := listener.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if listened {
+			backoff = listener.minBackoff
+		}
+		listener.logf("warn: listen_failed channel=%s retry_in=%s err=%v", listener.channel, backoff, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, listener.maxBackoff)
+	}
+}
+
+// listen abre una conexión, escucha el canal y reparte notificaciones hasta que algo falla.
+// listened indica si llegó a escuchar, para reiniciar el backoff.
+func (listener *Listener) listen(ctx context.Context) (listened bool, err error) {
+	conn, err := listener.dial(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		closeContext, cancel := context.WithTimeout(context.WithoutCancel(ctx), listenCloseTimeout)
+		defer cancel()
+		_ = conn.Close(closeContext)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{listener.channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	listener.subscriber.Listening()
+	defer listener.subscriber.Lost()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		listener.subscriber.Notify(notification.Payload)
+	}
+}
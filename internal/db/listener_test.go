@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeListenConn entrega las notificaciones que recibe por notifications; un error en failures
+// simula que se cayó la conexión.
+type fakeListenConn struct {
+	notifications chan string
+	failures      chan error
+	mutex         sync.Mutex
+	executed      []string
+	closed        bool
+}
+
+func newFakeListenConn() *fakeListenConn {
+	return &fakeListenConn{notifications: make(chan string), failures: make(chan error)}
+}
+
+func (conn *fakeListenConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.executed = append(conn.executed, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (conn *fakeListenConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-conn.failures:
+		return nil, err
+	case payload := <-conn.notifications:
+		return &pgconn.Notification{Channel: "items_changed", Payload: payload}, nil
+	}
+}
+
+func (conn *fakeListenConn) Close(ctx context.Context) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.closed = true
+	return nil
+}
+
+func (conn *fakeListenConn) isClosed() bool {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	return conn.closed
+}
+
+// recordingSubscriber guarda lo que recibe, en orden.
+type recordingSubscriber struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (subscriber *recordingSubscriber) record(event string) {
+	subscriber.mutex.Lock()
+	defer subscriber.mutex.Unlock()
+	subscriber.events = append(subscriber.events, event)
+}
+
+func (subscriber *recordingSubscriber) Listening()            { subscriber.record("listening") }
+func (subscriber *recordingSubscriber) Lost()                 { subscriber.record("lost") }
+func (subscriber *recordingSubscriber) Notify(payload string) { subscriber.record("notify " + payload) }
+
+func (subscriber *recordingSubscriber) all() []string {
+	subscriber.mutex.Lock()
+	defer subscriber.mutex.Unlock()
+	return append([]string(nil), subscriber.events...)
+}
+
+func TestListener_Run(t *testing.T) {
+	t.Run("delivers notifications and reconnects after a drop", func(t *testing.T) {
+		first, second := newFakeListenConn(), newFakeListenConn()
+		conns := make(chan *fakeListenConn, 2)
+		conns <- first
+		conns <- second
+		subscriber := &recordingSubscriber{}
+		listener := NewListener(func(ctx context.Context) (ListenConn, error) { return <-conns, nil }, "items_changed", subscriber,
+			WithListenerLogf(func(string, ...any) {}))
+		listener.minBackoff = time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- listener.Run(ctx) }()
+
+		first.notifications <- "a"
+		first.failures <- errors.New("connection reset")
+		second.notifications <- "b"
+		cancel()
+
+		require.NoError(t, <-done)
+		require.Equal(t, []string{"listening", "notify a", "lost", "listening", "notify b", "lost"}, subscriber.all())
+		require.Equal(t, []string{`LISTEN "items_changed"`}, first.executed)
+		require.True(t, first.isClosed())
+		require.True(t, second.isClosed(), "the connection closes on shutdown")
+	})
+
+	t.Run("backs off while the database is unreachable", func(t *testing.T) {
+		var (
+			mutex    sync.Mutex
+			messages []string
+			attempts int
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		dial := func(context.Context) (ListenConn, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if attempts++; attempts == 4 {
+				cancel()
+			}
+			return nil, errors.New("connection refused")
+		}
+		logf := func(format string, args ...any) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			messages = append(messages, fmt.Sprintf(format, args...))
+		}
+		listener := NewListener(dial, "items_changed", &recordingSubscriber{}, WithListenerLogf(logf))
+		listener.minBackoff, listener.maxBackoff = time.Millisecond, 2*time.Millisecond
+
+		require.NoError(t, listener.Run(ctx))
+
+		require.Equal(t, []string{
+			"warn: listen_failed channel=items_changed retry_in=1ms err=connection refused",
+			"warn: listen_failed channel=items_changed retry_in=2ms err=connection refused",
+			"warn: listen_failed channel=items_changed retry_in=2ms err=connection refused",
+		}, messages)
+	})
+}
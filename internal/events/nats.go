@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsConn es lo que NATS usa de *nats.Conn; los tests lo reemplazan.
+type natsConn interface {
+	Publish(subject string, data []byte) error
+	FlushTimeout(timeout time.Duration) error
+	Close()
+}
+
+// natsCloseTimeout es cuánto espera Close a que el servidor confirme lo publicado.
+const natsCloseTimeout = 5 * time.Second
+
+// NATSConfig es la conexión al servidor NATS.
+type NATSConfig struct {
+	// URL es la del servidor, o varias separadas por coma ("nats://nats-1:4222,nats://nats-2:4222").
+	URL string
+	// SubjectPrefix antecede al tipo del evento: con "catalog", item.created sale por
+	// "catalog.item.created".
+	SubjectPrefix string
+	// Name identifica la conexión en el monitoreo del servidor.
+	Name string
+}
+
+// NATS publica los eventos en NATS core, en el subject "<prefijo>.<tipo>". Publish no espera al
+// servidor: el cliente bufferea y, si la conexión se cae, reconecta y manda lo pendiente.
+type NATS struct {
+	conn          natsConn
+	subjectPrefix string
+}
+
+// NewNATS conecta al servidor. Falla si no lo encuentra al arrancar; después reconecta sin límite.
+func NewNATS(config NATSConfig) (*NATS, error) {
+	conn, err := nats.Connect(config.URL, nats.Name(config.Name), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NATS{conn: conn, subjectPrefix: config.SubjectPrefix}, nil
+}
+
+// Publish implementa Publisher.
+func (publisher *NATS) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return publisher.conn.Publish(publisher.subject(event.Type), data)
+}
+
+// Close implementa Publisher: espera a que el servidor reciba lo publicado y cierra la conexión.
+func (publisher *NATS) Close() error {
+	defer publisher.conn.Close()
+	return publisher.conn.FlushTimeout(natsCloseTimeout)
+}
+
+// subject devuelve el subject de un tipo de evento.
+func (publisher *NATS) subject(eventType string) string {
+	if publisher.subjectPrefix == "" {
+		return eventType
+	}
+	return publisher.subjectPrefix + "." + eventType
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	subjects []string
+	messages [][]byte
+	flushErr error
+	closed   bool
+}
+
+func (conn *fakeConn) Publish(subject string, data []byte) error {
+	conn.subjects = append(conn.subjects, subject)
+	conn.messages = append(conn.messages, data)
+	return nil
+}
+
+func (conn *fakeConn) FlushTimeout(timeout time.Duration) error {
+	return conn.flushErr
+}
+
+func (conn *fakeConn) Close() {
+	conn.closed = true
+}
+
+func TestNATS_Publish(t *testing.T) {
+	t.Run("subject from the prefix and the type", func(t *testing.T) {
+		conn := &fakeConn{}
+		publisher := &NATS{conn: conn, subjectPrefix: "catalog"}
+		occurredAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+		err := publisher.Publish(context.Background(), Event{SchemaVersion: SchemaVersion, Type: "item.deleted", OccurredAt: occurredAt, Data: json.RawMessage(`{"id":"id-1","operation":"deleted"}`)})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"catalog.item.deleted"}, conn.subjects)
+		require.JSONEq(t, `{"schema_version":1,"type":"item.deleted","occurred_at":"2026-10-15T12:00:00Z","data":{"id":"id-1","operation":"deleted"}}`, string(conn.messages[0]))
+	})
+
+	t.Run("without prefix", func(t *testing.T) {
+		conn := &fakeConn{}
+		publisher := &NATS{conn: conn}
+
+		require.NoError(t, publisher.Publish(context.Background(), Event{Type: "item.created", Data: json.RawMessage(`{}`)}))
+
+		require.Equal(t, []string{"item.created"}, conn.subjects)
+	})
+}
+
+func TestNATS_Close(t *testing.T) {
+	conn := &fakeConn{flushErr: errors.New("timeout")}
+	publisher := &NATS{conn: conn}
+
+	require.EqualError(t, publisher.Close(), "timeout")
+	require.True(t, conn.closed, "the connection closes even if the flush fails")
+}
@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+)
+
+// SchemaVersion es la versión del sobre y del payload de los eventos. Se incrementa solo con
+// cambios incompatibles (un campo que se saca o cambia de tipo); agregar campos no la cambia.
+const SchemaVersion = 1
+
+// Event es un evento de dominio tal como sale al bus.
+type Event struct {
+	SchemaVersion int `json:"schema_version"`
+	// Type es el tipo del evento ("item.created", "item.updated", "item.deleted"), el mismo del
+	// outbox y de los webhooks.
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data es el evento de items en JSON ({"id", "operation", "item"}): item es el snapshot completo
+	// después del cambio; no viene en las operaciones masivas que solo conocen los IDs.
+	Data json.RawMessage `json:"data"`
+}
+
+// Publisher manda eventos a un bus. Publish puede recibir un evento más de una vez (el relay del
+// outbox reintenta la tanda), así que los consumidores tienen que tolerar duplicados. Close espera
+// a que salga lo pendiente; se llama al apagar el servidor.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Noop es el Publisher por defecto (EVENTS_BACKEND=none): descarta los eventos.
+type Noop struct{}
+
+// Publish implementa Publisher.
+func (Noop) Publish(ctx context.Context, event Event) error { return nil }
+
+// Close implementa Publisher.
+func (Noop) Close() error { return nil }
+
+// FromOutbox arma el evento de un mensaje del outbox.
+func FromOutbox(message outbox.Message) Event {
+	return Event{
+		SchemaVersion: SchemaVersion,
+		Type:          message.EventType,
+		OccurredAt:    message.CreatedAt.UTC(),
+		Data:          message.Payload,
+	}
+}
+
+// OutboxPublisher adapta un Publisher para que el relay del outbox le pase los eventos.
+type OutboxPublisher struct {
+	publisher Publisher
+}
+
+// NewOutboxPublisher crea el adaptador para usar publisher como outbox.Publisher.
+func NewOutboxPublisher(publisher Publisher) *OutboxPublisher {
+	return &OutboxPublisher{publisher: publisher}
+}
+
+// Publish implementa outbox.Publisher.
+func (adapter *OutboxPublisher) Publish(ctx context.Context, message outbox.Message) error {
+	return adapter.publisher.Publish(ctx, FromOutbox(message))
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+)
+
+type recordingPublisher struct {
+	published []Event
+	err       error
+}
+
+func (publisher *recordingPublisher) Publish(ctx context.Context, event Event) error {
+	publisher.published = append(publisher.published, event)
+	return publisher.err
+}
+
+func (publisher *recordingPublisher) Close() error { return nil }
+
+func TestOutboxPublisher(t *testing.T) {
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.FixedZone("ART", -3*60*60))
+	message := outbox.Message{ID: 7, EventType: "item.created", Payload: json.RawMessage(`{"id":"id-1","operation":"created","item":{"id":"id-1"}}`), CreatedAt: createdAt}
+
+	t.Run("wraps the message in the envelope", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+
+		require.NoError(t, NewOutboxPublisher(publisher).Publish(context.Background(), message))
+
+		require.Equal(t, []Event{{
+			SchemaVersion: SchemaVersion,
+			Type:          "item.created",
+			OccurredAt:    createdAt.UTC(),
+			Data:          message.Payload,
+		}}, publisher.published)
+	})
+
+	t.Run("returns the publisher error so the relay retries", func(t *testing.T) {
+		publisher := &recordingPublisher{err: errors.New("bus down")}
+
+		require.EqualError(t, NewOutboxPublisher(publisher).Publish(context.Background(), message), "bus down")
+	})
+}
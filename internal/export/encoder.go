@@ -0,0 +1,95 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// Format es el formato de archivo de un export.
+type Format string
+
+const (
+	// FormatNDJSON escribe un item JSON por línea.
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV escribe un CSV con encabezado.
+	FormatCSV Format = "csv"
+)
+
+// Extension devuelve la extensión de archivo del formato.
+func (format Format) Extension() string {
+	return string(format)
+}
+
+// ContentType devuelve el media type del formato.
+func (format Format) ContentType() string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// ParseFormat valida un formato recibido por config o query param.
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatNDJSON, FormatCSV:
+		return Format(value), nil
+	default:
+		return "", fmt.Errorf("unknown export format %q (expected ndjson or csv)", value)
+	}
+}
+
+// Encoder escribe items de a uno, sin acumularlos en memoria.
+type Encoder interface {
+	Encode(item items.Item) error
+	// Close vacía buffers pendientes. No cierra el writer subyacente.
+	Close() error
+}
+
+// NewEncoder crea el encoder del formato pedido sobre writer.
+func NewEncoder(format Format, writer io.Writer) (Encoder, error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonEncoder{encoder: json.NewEncoder(writer)}, nil
+	case FormatCSV:
+		encoder := &csvEncoder{writer: csv.NewWriter(writer)}
+		if err := encoder.writer.Write(items.CSVColumns); err != nil {
+			return nil, err
+		}
+		return encoder, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+// Encode implementa Encoder. json.Encoder ya agrega el salto de línea.
+func (encoder *ndjsonEncoder) Encode(item items.Item) error {
+	return encoder.encoder.Encode(item)
+}
+
+// Close implementa Encoder.
+func (encoder *ndjsonEncoder) Close() error {
+	return nil
+}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+// Encode implementa Encoder.
+func (encoder *csvEncoder) Encode(item items.Item) error {
+	return encoder.writer.Write(items.CSVRecord(item))
+}
+
+// Close implementa Encoder.
+func (encoder *csvEncoder) Close() error {
+	encoder.writer.Flush()
+	return encoder.writer.Error()
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleItems() []items.Item {
+	description := "de acero, 20cm"
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []items.Item{
+		{ID: "id-1", Name: "Sartén", Slug: "sarten", Description: &description, Price: "12.50", EffectivePrice: "12.50", TaxRateBPS: 2100, PriceWithTax: "15.13", Currency: "USD", Stock: 3, MinOrderQty: 1, Status: items.StatusActive, State: items.StatePublished, CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: "id-2", Name: "Olla", Price: "30.00", EffectivePrice: "30.00", PriceWithTax: "30.00", Currency: "USD", Stock: 0, MinOrderQty: 1, Status: items.StatusActive, State: items.StatePublished, CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	t.Run("ndjson", func(t *testing.T) {
+		var buffer bytes.Buffer
+		encoder, err := NewEncoder(FormatNDJSON, &buffer)
+		require.NoError(t, err)
+
+		for _, item := range sampleItems() {
+			require.NoError(t, encoder.Encode(item))
+		}
+		require.NoError(t, encoder.Close())
+
+		lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+		require.Len(t, lines, 2)
+		require.JSONEq(t, `{"id":"id-1","name":"Sartén","slug":"sarten","description":"de acero, 20cm","price":"12.50","effective_price":"12.50","tax_rate_bps":2100,"price_with_tax":"15.13","currency":"USD","stock":3,"min_order_qty":1,"available":0,"status":"active","state":"published","allow_backorder":false,
+			"created_at":"2025-01-02T03:04:05Z","updated_at":"2025-01-02T03:04:05Z","version":0}`, string(lines[0]))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buffer bytes.Buffer
+		encoder, err := NewEncoder(FormatCSV, &buffer)
+		require.NoError(t, err)
+
+		for _, item := range sampleItems() {
+			require.NoError(t, encoder.Encode(item))
+		}
+		require.NoError(t, encoder.Close())
+
+		require.Equal(t, "id,name,description,price,stock,created_at,updated_at\n"+
+			"id-1,Sartén,\"de acero, 20cm\",12.50,3,2025-01-02T03:04:05Z,2025-01-02T03:04:05Z\n"+
+			"id-2,Olla,,30.00,0,2025-01-02T03:04:05Z,2025-01-02T03:04:05Z\n", buffer.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := NewEncoder(Format("xml"), &bytes.Buffer{})
+		require.Error(t, err)
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+	require.Equal(t, "text/csv", format.ContentType())
+
+	_, err = ParseFormat("xlsx")
+	require.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler expone la ejecución manual del export.
+type Handler struct {
+	job *Job
+}
+
+// NewHandler crea el handler. job puede ser nil si el export no está configurado;
+// en ese caso el endpoint responde 503.
+func NewHandler(job *Job) *Handler {
+	return &Handler{job: job}
+}
+
+// RegisterRoutes registra las rutas de administración del export.
+// Todavía no hay autenticación: estas rutas no deberían exponerse fuera de la red interna.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Post("/admin/exports/run", handler.Run)
+}
+
+// Run dispara un export en background y responde 202 sin esperar a que termine.
+// El resultado se consulta en /ready y en las métricas catalog_export_*.
+func (handler *Handler) Run(writer http.ResponseWriter, request *http.Request) {
+	if handler.job == nil {
+		httpx.Fail(writer, request, http.StatusServiceUnavailable, "export_not_configured", "export storage is not configured")
+		return
+	}
+
+	// El export sobrevive al request (y al timeout del router), así que no hereda su cancelación.
+	err := handler.job.Start(context.WithoutCancel(request.Context()))
+	if errors.Is(err, ErrorAlreadyRunning) {
+		httpx.Fail(writer, request, http.StatusConflict, "export_running", "an export is already running")
+		return
+	}
+
+	httpx.OK(writer, request, http.StatusAccepted, map[string]any{
+		"status": "started",
+	})
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Run(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewHandler(nil).Run(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/run", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "export_not_configured", decodeError(t, rec).Code)
+	})
+
+	t.Run("starts in background", func(t *testing.T) {
+		store := newMemoryStore()
+		job, _ := newTestJob(&fakeSource{items: sampleItems()}, store)
+
+		// El request se cancela apenas responde; el export tiene que seguir igual.
+		ctx, cancel := context.WithCancel(context.Background())
+		rec := httptest.NewRecorder()
+		NewHandler(job).Run(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/run", nil).WithContext(ctx))
+		cancel()
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Eventually(t, func() bool {
+			lastRun := job.Status().LastRun
+			return lastRun != nil && lastRun.Result == ResultSuccess
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("already running", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		store := newMemoryStore()
+		store.putFn = func(key string) error {
+			<-release
+			return nil
+		}
+		job, _ := newTestJob(&fakeSource{items: sampleItems()}, store)
+		require.NoError(t, job.Start(context.Background()))
+
+		rec := httptest.NewRecorder()
+		NewHandler(job).Run(rec, httptest.NewRequest(http.MethodPost, "/admin/exports/run", nil))
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, "export_running", decodeError(t, rec).Code)
+	})
+}
+
+func decodeError(t *testing.T, recorder *httptest.ResponseRecorder) *httpx.ErrorBody {
+	t.Helper()
+
+	var response httpx.Response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.NotNil(t, response.Error)
+	return response.Error
+}
@@ -0,0 +1,310 @@
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// ErrorAlreadyRunning indica que ya hay un export en curso (por schedule o manual).
+var ErrorAlreadyRunning = errors.New("export already running")
+
+// Resultados posibles de un export, tal como se reportan en métricas y en /ready.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// defaultRetryDelay es la espera antes del único reintento.
+const defaultRetryDelay = 5 * time.Second
+
+// maxAttempts: el primer intento más un reintento.
+const maxAttempts = 2
+
+// Source recorre todos los items del catálogo. items.Repository.Each la implementa.
+type Source interface {
+	Each(ctx context.Context, fn func(items.Item) error) error
+}
+
+// ObjectStore es el bucket donde se sube el export.
+// body se lee hasta EOF; su tamaño no se conoce de antemano.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body io.Reader, contentType string) error
+}
+
+// Metrics recibe el resultado de cada corrida. metrics.Exports la implementa.
+type Metrics interface {
+	ExportFinished(result string, rows int64, finishedAt time.Time)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ExportFinished(string, int64, time.Time) {}
+
+// Marker es el contenido del objeto _SUCCESS que se escribe al final de un export completo.
+// Quien consume el bucket debe esperar este objeto antes de leer el archivo.
+type Marker struct {
+	Object      string    `json:"object"`
+	Rows        int64     `json:"rows"`
+	SHA256      string    `json:"sha256"`
+	Format      Format    `json:"format"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Run describe la última corrida de un export.
+type Run struct {
+	Result     string    `json:"result"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Attempts   int       `json:"attempts"`
+	Object     string    `json:"object,omitempty"`
+	Rows       int64     `json:"rows"`
+	SHA256     string    `json:"sha256,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Status es el estado del job que se publica en /ready.
+type Status struct {
+	Running bool `json:"running"`
+	LastRun *Run `json:"last_run"`
+}
+
+// Job exporta el catálogo completo a un ObjectStore.
+// El archivo se genera en streaming (nunca se arma entero en memoria) y se sube por partes.
+// Una falla se reintenta una vez; si vuelve a fallar se loguea y se cuenta en métricas,
+// pero nunca se propaga como pánico.
+type Job struct {
+	source     Source
+	store      ObjectStore
+	format     Format
+	prefix     string
+	metrics    Metrics
+	logf       func(format string, args ...any)
+	now        func() time.Time
+	retryDelay time.Duration
+
+	running atomic.Bool
+	mutex   sync.Mutex
+	lastRun *Run
+}
+
+// JobOption configura comportamiento opcional del Job.
+type JobOption func(*Job)
+
+// WithPrefix define el prefijo de las keys dentro del bucket (por ejemplo "catalog/daily").
+func WithPrefix(prefix string) JobOption {
+	return func(job *Job) {
+		job.prefix = strings.Trim(prefix, "/")
+	}
+}
+
+// WithMetrics registra el resultado de cada corrida.
+func WithMetrics(metrics Metrics) JobOption {
+	return func(job *Job) {
+		job.metrics = metrics
+	}
+}
+
+// WithLogger cambia el logger del job (por defecto, log.Printf).
+func WithLogger(logf func(format string, args ...any)) JobOption {
+	return func(job *Job) {
+		job.logf = logf
+	}
+}
+
+// NewJob crea un Job que lee de source y sube a store en el formato indicado.
+func NewJob(source Source, store ObjectStore, format Format, options ...JobOption) *Job {
+	job := &Job{
+		source:     source,
+		store:      store,
+		format:     format,
+		metrics:    noopMetrics{},
+		logf:       log.Printf,
+		now:        time.Now,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, option := range options {
+		option(job)
+	}
+	return job
+}
+
+// Running indica si hay un export en curso.
+func (job *Job) Running() bool {
+	return job.running.Load()
+}
+
+// Status devuelve una copia del estado actual del job.
+func (job *Job) Status() Status {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	status := Status{Running: job.running.Load()}
+	if job.lastRun != nil {
+		lastRun := *job.lastRun
+		status.LastRun = &lastRun
+	}
+	return status
+}
+
+// Run ejecuta un export completo y espera a que termine. Devuelve ErrorAlreadyRunning si hay otro en curso.
+// El error final (después del reintento) también queda registrado en Status, log y métricas.
+func (job *Job) Run(ctx context.Context) error {
+	if !job.running.CompareAndSwap(false, true) {
+		return ErrorAlreadyRunning
+	}
+	return job.run(ctx)
+}
+
+// Start lanza un export en background y vuelve enseguida.
+// Igual que Run, devuelve ErrorAlreadyRunning si hay otro en curso; el resultado se consulta con Status.
+func (job *Job) Start(ctx context.Context) error {
+	if !job.running.CompareAndSwap(false, true) {
+		return ErrorAlreadyRunning
+	}
+	go func() {
+		// El resultado queda en Status, log y métricas.
+		_ = job.run(ctx)
+	}()
+	return nil
+}
+
+// run asume que el caller ya marcó el job como corriendo.
+func (job *Job) run(ctx context.Context) error {
+	defer job.running.Store(false)
+
+	run := Run{StartedAt: job.now().UTC()}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		run.Attempts = attempt
+		if attempt > 1 {
+			job.logf("export attempt %d failed, retrying: %v", attempt-1, err)
+			if waitErr := job.wait(ctx); waitErr != nil {
+				err = waitErr
+				break
+			}
+		}
+		var marker Marker
+		marker, err = job.export(ctx, run.StartedAt)
+		if err == nil {
+			run.Object, run.Rows, run.SHA256 = marker.Object, marker.Rows, marker.SHA256
+			break
+		}
+	}
+
+	run.FinishedAt = job.now().UTC()
+	run.Result = ResultSuccess
+	if err != nil {
+		run.Result = ResultFailure
+		run.Error = err.Error()
+		job.logf("export failed after %d attempts: %v", run.Attempts, err)
+	}
+	job.metrics.ExportFinished(run.Result, run.Rows, run.FinishedAt)
+
+	job.mutex.Lock()
+	job.lastRun = &run
+	job.mutex.Unlock()
+
+	return err
+}
+
+func (job *Job) wait(ctx context.Context) error {
+	timer := time.NewTimer(job.retryDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// export sube el archivo y, si quedó completo, el marker _SUCCESS.
+// Todos los objetos de una corrida comparten el directorio {prefix}/{timestamp}/.
+func (job *Job) export(ctx context.Context, startedAt time.Time) (Marker, error) {
+	directory := startedAt.Format("20060102T150405Z")
+	if job.prefix != "" {
+		directory = job.prefix + "/" + directory
+	}
+	object := directory + "/items." + job.format.Extension()
+
+	rows, checksum, err := job.upload(ctx, object)
+	if err != nil {
+		return Marker{}, err
+	}
+
+	marker := Marker{
+		Object:      object,
+		Rows:        rows,
+		SHA256:      checksum,
+		Format:      job.format,
+		CompletedAt: job.now().UTC(),
+	}
+	body, err := json.Marshal(marker)
+	if err != nil {
+		return Marker{}, err
+	}
+	if err := job.store.PutObject(ctx, directory+"/_SUCCESS", strings.NewReader(string(body)), "application/json"); err != nil {
+		return Marker{}, fmt.Errorf("write success marker: %w", err)
+	}
+	return marker, nil
+}
+
+// upload conecta el encoder con el store a través de un pipe: las filas se suben
+// a medida que se leen de la base. Devuelve cantidad de filas y sha256 del archivo.
+func (job *Job) upload(ctx context.Context, object string) (int64, string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	uploaded := make(chan error, 1)
+	go func() {
+		err := job.store.PutObject(ctx, object, reader, job.format.ContentType())
+		// Si el store terminó antes de leer todo, destrabamos al encoder.
+		reader.CloseWithError(err)
+		uploaded <- err
+	}()
+
+	hash := sha256.New()
+	rows, encodeErr := job.encode(ctx, io.MultiWriter(writer, hash))
+	// Con encodeErr nil el store ve EOF; si no, recibe el error y aborta el multipart.
+	writer.CloseWithError(encodeErr)
+	uploadErr := <-uploaded
+
+	if uploadErr != nil {
+		return 0, "", fmt.Errorf("upload %s: %w", object, uploadErr)
+	}
+	if encodeErr != nil {
+		return 0, "", fmt.Errorf("encode items: %w", encodeErr)
+	}
+	return rows, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (job *Job) encode(ctx context.Context, writer io.Writer) (int64, error) {
+	encoder, err := NewEncoder(job.format, writer)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	err = job.source.Each(ctx, func(item items.Item) error {
+		rows++
+		return encoder.Encode(item)
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, encoder.Close()
+}
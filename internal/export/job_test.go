@@ -0,0 +1,190 @@
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource devuelve items fijos; eachFn permite simular fallas.
+type fakeSource struct {
+	items  []items.Item
+	eachFn func(calls int) error
+	calls  int
+}
+
+func (source *fakeSource) Each(ctx context.Context, fn func(items.Item) error) error {
+	source.calls++
+	if source.eachFn != nil {
+		if err := source.eachFn(source.calls); err != nil {
+			return err
+		}
+	}
+	for _, item := range source.items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryStore guarda los objetos en memoria, leyendo el body completo como haría S3.
+type memoryStore struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	putFn   func(key string) error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (store *memoryStore) PutObject(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if store.putFn != nil {
+		if err := store.putFn(key); err != nil {
+			return err
+		}
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.objects[key] = content
+	return nil
+}
+
+func (store *memoryStore) keys() []string {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	keys := make([]string, 0, len(store.objects))
+	for key := range store.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+type recordingMetrics struct {
+	results []string
+	rows    int64
+}
+
+func (metrics *recordingMetrics) ExportFinished(result string, rows int64, finishedAt time.Time) {
+	metrics.results = append(metrics.results, result)
+	metrics.rows = rows
+}
+
+func newTestJob(source Source, store ObjectStore, options ...JobOption) (*Job, *[]string) {
+	var logs []string
+	options = append([]JobOption{
+		WithPrefix("/catalog/daily/"),
+		WithLogger(func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }),
+	}, options...)
+	job := NewJob(source, store, FormatNDJSON, options...)
+	job.now = func() time.Time { return time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC) }
+	job.retryDelay = 0
+	return job, &logs
+}
+
+func TestJob_Run(t *testing.T) {
+	t.Run("uploads file and success marker", func(t *testing.T) {
+		store := newMemoryStore()
+		metrics := &recordingMetrics{}
+		job, _ := newTestJob(&fakeSource{items: sampleItems()}, store, WithMetrics(metrics))
+
+		require.NoError(t, job.Run(context.Background()))
+
+		file := store.objects["catalog/daily/20250304T050607Z/items.ndjson"]
+		require.NotEmpty(t, file)
+		checksum := sha256.Sum256(file)
+
+		var marker Marker
+		require.NoError(t, json.Unmarshal(store.objects["catalog/daily/20250304T050607Z/_SUCCESS"], &marker))
+		require.Equal(t, "catalog/daily/20250304T050607Z/items.ndjson", marker.Object)
+		require.Equal(t, int64(2), marker.Rows)
+		require.Equal(t, hex.EncodeToString(checksum[:]), marker.SHA256)
+		require.Equal(t, FormatNDJSON, marker.Format)
+
+		status := job.Status()
+		require.False(t, status.Running)
+		require.Equal(t, ResultSuccess, status.LastRun.Result)
+		require.Equal(t, 1, status.LastRun.Attempts)
+		require.Equal(t, int64(2), status.LastRun.Rows)
+		require.Equal(t, []string{ResultSuccess}, metrics.results)
+	})
+
+	t.Run("retries once", func(t *testing.T) {
+		store := newMemoryStore()
+		source := &fakeSource{items: sampleItems(), eachFn: func(calls int) error {
+			if calls == 1 {
+				return errors.New("connection reset")
+			}
+			return nil
+		}}
+		job, logs := newTestJob(source, store)
+
+		require.NoError(t, job.Run(context.Background()))
+
+		require.Equal(t, 2, job.Status().LastRun.Attempts)
+		require.Len(t, *logs, 1)
+		require.Contains(t, (*logs)[0], "retrying")
+	})
+
+	t.Run("failure after retry is logged and recorded", func(t *testing.T) {
+		store := newMemoryStore()
+		store.putFn = func(key string) error { return errors.New("access denied") }
+		metrics := &recordingMetrics{}
+		job, logs := newTestJob(&fakeSource{items: sampleItems()}, store, WithMetrics(metrics))
+
+		err := job.Run(context.Background())
+
+		require.ErrorContains(t, err, "access denied")
+		require.Empty(t, store.keys())
+		status := job.Status()
+		require.Equal(t, ResultFailure, status.LastRun.Result)
+		require.Equal(t, 2, status.LastRun.Attempts)
+		require.Contains(t, status.LastRun.Error, "access denied")
+		require.Equal(t, []string{ResultFailure}, metrics.results)
+		require.Contains(t, (*logs)[len(*logs)-1], "export failed after 2 attempts")
+	})
+
+	t.Run("no marker when the source fails midway", func(t *testing.T) {
+		store := newMemoryStore()
+		source := &fakeSource{items: sampleItems(), eachFn: func(calls int) error { return errors.New("db down") }}
+		job, _ := newTestJob(source, store)
+
+		require.Error(t, job.Run(context.Background()))
+		for _, key := range store.keys() {
+			require.NotContains(t, key, "_SUCCESS")
+		}
+	})
+
+	t.Run("rejects concurrent runs", func(t *testing.T) {
+		release := make(chan struct{})
+		store := newMemoryStore()
+		store.putFn = func(key string) error {
+			<-release
+			return nil
+		}
+		job, _ := newTestJob(&fakeSource{items: sampleItems()}, store)
+
+		require.NoError(t, job.Start(context.Background()))
+		require.True(t, job.Status().Running)
+		require.ErrorIs(t, job.Run(context.Background()), ErrorAlreadyRunning)
+
+		close(release)
+		require.Eventually(t, func() bool { return !job.Running() }, time.Second, time.Millisecond)
+		require.Equal(t, ResultSuccess, job.Status().LastRun.Result)
+	})
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// partSize es el tamaño de cada parte del multipart upload. Con tamaño total desconocido
+// el cliente bufferea una parte a la vez, así que esto acota la memoria que usa el export.
+const partSize = 16 << 20
+
+// S3Config describe un bucket en un storage compatible con S3 (AWS, MinIO, etc.).
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Store implementa ObjectStore sobre un bucket S3.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store crea el cliente. No hace requests: un bucket inexistente o credenciales
+// inválidas recién fallan en el primer export (y quedan reportadas en /ready).
+func NewS3Store(config S3Config) (*S3Store, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{client: client, bucket: config.Bucket}, nil
+}
+
+// PutObject implementa ObjectStore. body se sube en partes a medida que se lee;
+// si body devuelve error, el multipart se aborta y el objeto no queda visible.
+func (store *S3Store) PutObject(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, err := store.client.PutObject(ctx, store.bucket, key, body, -1, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    partSize,
+	})
+	return err
+}
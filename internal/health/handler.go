@@ -3,6 +3,8 @@ package health
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/Lelo88/catalog-api-golang/internal/httpx"
@@ -13,16 +15,67 @@ type dbPinger interface {
 	Ping(ctx context.Context) error
 }
 
+// Valores por defecto del cache de readiness.
+const (
+	defaultReadyCacheTTL = 2 * time.Second
+	// maxFailureCacheTTL acota cuánto se recuerda un ping fallido, para no ocultar una recuperación.
+	maxFailureCacheTTL = time.Second
+)
+
 // Handler expone endpoints de salud.
 // Incluye checks simples para liveness (/health) y readiness (/ready).
 type Handler struct {
-	db dbPinger
+	db            dbPinger
+	readyCacheTTL time.Duration
+	now           func() time.Time
+
+	// mutex protege lastCheck y serializa los pings: requests concurrentes esperan
+	// el resultado del check en curso en vez de disparar uno cada uno.
+	mutex     sync.Mutex
+	lastCheck *readyCheck
+
+	details []detail
+}
+
+// detail es información extra (no bloqueante) que se agrega a la respuesta de /ready.
+type detail struct {
+	name  string
+	value func() any
+}
+
+// readyCheck es el resultado de un ping a la DB que se reutiliza mientras no venza.
+type readyCheck struct {
+	err       error
+	latency   time.Duration
+	checkedAt time.Time
+}
+
+// Option configura comportamiento opcional del Handler.
+type Option func(*Handler)
+
+// WithReadyCacheTTL cambia cuánto se reutiliza el resultado de /ready. 0 desactiva el cache.
+func WithReadyCacheTTL(ttl time.Duration) Option {
+	return func(handler *Handler) {
+		handler.readyCacheTTL = ttl
+	}
+}
+
+// WithDetail agrega a /ready un bloque informativo (por ejemplo, el estado del último export).
+// value se evalúa en cada request y no afecta si el servicio está listo o no.
+func WithDetail(name string, value func() any) Option {
+	return func(handler *Handler) {
+		handler.details = append(handler.details, detail{name: name, value: value})
+	}
 }
 
 // New crea un Handler. db puede ser nil si querés correr sin base en algún entorno,
 // pero para este proyecto la DB es requerida (config.Load la exige).
-func New(db dbPinger) *Handler {
-	return &Handler{db: db}
+func New(db dbPinger, options ...Option) *Handler {
+	handler := &Handler{db: db, readyCacheTTL: defaultReadyCacheTTL, now: time.Now}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
 }
 
 // Health indica si el proceso está vivo.
@@ -35,22 +88,70 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 
 // Ready indica si el servicio está listo para atender tráfico.
 // Acá sí verificamos dependencias críticas (por ahora, la base de datos).
+// El resultado se cachea brevemente para que load balancers y probes no generen una lluvia de pings;
+// ?force=true ignora el cache (útil para debuggear a mano).
 func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
-	// Timeout corto para readiness. Si la DB no responde rápido, consideramos que no está lista.
-	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
-	defer cancel()
-
 	if h.db == nil {
 		httpx.Fail(w, r, http.StatusServiceUnavailable, "not_ready", "database pool not configured")
 		return
 	}
 
-	if err := h.db.Ping(ctx); err != nil {
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	check, cached := h.check(r.Context(), force)
+
+	if check.err != nil {
 		httpx.Fail(w, r, http.StatusServiceUnavailable, "not_ready", "database is not reachable")
 		return
 	}
 
-	httpx.OK(w, r, http.StatusOK, map[string]any{
+	data := map[string]any{
 		"status": "ready",
-	})
+		"cached": cached,
+		"checks": map[string]any{
+			"database": map[string]any{
+				"status":     "up",
+				"latency_ms": check.latency.Milliseconds(),
+				"checked_at": check.checkedAt.UTC(),
+			},
+		},
+	}
+	if len(h.details) > 0 {
+		details := make(map[string]any, len(h.details))
+		for _, detail := range h.details {
+			details[detail.name] = detail.value()
+		}
+		data["details"] = details
+	}
+
+	httpx.OK(w, r, http.StatusOK, data)
+}
+
+// check devuelve el último resultado si sigue vigente o hace un ping nuevo.
+// El segundo valor indica si el resultado vino del cache.
+func (h *Handler) check(ctx context.Context, force bool) (readyCheck, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !force && h.lastCheck != nil && h.now().Sub(h.lastCheck.checkedAt) < h.ttlFor(*h.lastCheck) {
+		return *h.lastCheck, true
+	}
+
+	// Timeout corto para readiness. Si la DB no responde rápido, consideramos que no está lista.
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	started := h.now()
+	err := h.db.Ping(pingCtx)
+	check := readyCheck{err: err, latency: h.now().Sub(started), checkedAt: started}
+	h.lastCheck = &check
+
+	return check, false
+}
+
+// ttlFor devuelve cuánto vale un resultado: los fallos se recuerdan menos para detectar rápido la recuperación.
+func (h *Handler) ttlFor(check readyCheck) time.Duration {
+	if check.err != nil && h.readyCacheTTL > maxFailureCacheTTL {
+		return maxFailureCacheTTL
+	}
+	return h.readyCacheTTL
 }
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -94,6 +96,149 @@ func TestHandler_Ready(t *testing.T) {
 		data := asMap(t, resp.Data)
 		require.Equal(t, "ready", data["status"])
 		require.True(t, db.pingCalled)
+		require.NotContains(t, data, "details")
+	})
+
+	t.Run("details", func(t *testing.T) {
+		handler := New(&fakeDB{}, WithDetail("export", func() any {
+			return map[string]any{"running": true}
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+		rec := httptest.NewRecorder()
+
+		handler.Ready(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		details := asMap(t, data["details"])
+		require.Equal(t, map[string]any{"running": true}, details["export"])
+	})
+}
+
+// fakeClock permite avanzar el tiempo a mano en los tests del cache.
+type fakeClock struct {
+	current time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.current
+}
+
+func (clock *fakeClock) Advance(duration time.Duration) {
+	clock.current = clock.current.Add(duration)
+}
+
+// countingDB cuenta pings de forma segura ante llamadas concurrentes.
+type countingDB struct {
+	pings atomic.Int32
+	down  atomic.Bool
+}
+
+func (db *countingDB) Ping(ctx context.Context) error {
+	db.pings.Add(1)
+	if db.down.Load() {
+		return errors.New("db down")
+	}
+	return nil
+}
+
+func newCachedHandler(db dbPinger, ttl time.Duration) (*Handler, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	handler := New(db, WithReadyCacheTTL(ttl))
+	handler.now = clock.Now
+	return handler, clock
+}
+
+func callReady(t *testing.T, handler *Handler, target string) (int, map[string]any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.Ready(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	resp := decodeResponse(t, rec)
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	return rec.Code, asMap(t, resp.Data)
+}
+
+func TestHandler_ReadyCache(t *testing.T) {
+	t.Run("reuses the result within the ttl", func(t *testing.T) {
+		db := &countingDB{}
+		handler, clock := newCachedHandler(db, 2*time.Second)
+
+		_, first := callReady(t, handler, "/ready")
+		clock.Advance(1500 * time.Millisecond)
+		_, second := callReady(t, handler, "/ready")
+
+		require.Equal(t, int32(1), db.pings.Load())
+		require.Equal(t, false, first["cached"])
+		require.Equal(t, true, second["cached"])
+		require.Equal(t, asMap(t, first["checks"]), asMap(t, second["checks"]))
+
+		clock.Advance(time.Second)
+		_, third := callReady(t, handler, "/ready")
+
+		require.Equal(t, int32(2), db.pings.Load())
+		require.Equal(t, false, third["cached"])
+	})
+
+	t.Run("force bypasses the cache", func(t *testing.T) {
+		db := &countingDB{}
+		handler, _ := newCachedHandler(db, 2*time.Second)
+
+		callReady(t, handler, "/ready")
+		_, forced := callReady(t, handler, "/ready?force=true")
+
+		require.Equal(t, int32(2), db.pings.Load())
+		require.Equal(t, false, forced["cached"])
+	})
+
+	t.Run("failures do not hide a recovery", func(t *testing.T) {
+		db := &countingDB{}
+		db.down.Store(true)
+		handler, clock := newCachedHandler(db, 10*time.Second)
+
+		code, _ := callReady(t, handler, "/ready")
+		require.Equal(t, http.StatusServiceUnavailable, code)
+
+		db.down.Store(false)
+		clock.Advance(500 * time.Millisecond)
+		code, _ = callReady(t, handler, "/ready")
+		require.Equal(t, http.StatusServiceUnavailable, code, "failure is still cached")
+
+		clock.Advance(600 * time.Millisecond)
+		code, _ = callReady(t, handler, "/ready")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, int32(2), db.pings.Load())
+	})
+
+	t.Run("zero ttl disables the cache", func(t *testing.T) {
+		db := &countingDB{}
+		handler, _ := newCachedHandler(db, 0)
+
+		callReady(t, handler, "/ready")
+		callReady(t, handler, "/ready")
+
+		require.Equal(t, int32(2), db.pings.Load())
+	})
+
+	t.Run("concurrent calls share one ping", func(t *testing.T) {
+		db := &countingDB{}
+		handler, _ := newCachedHandler(db, 2*time.Second)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				handler.Ready(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(1), db.pings.Load())
 	})
 }
 
@@ -0,0 +1,117 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Estados posibles de cada elemento dentro de una operación bulk.
+const (
+	BulkStatusSucceeded = "succeeded"
+	BulkStatusFailed    = "failed"
+	BulkStatusSkipped   = "skipped"
+)
+
+// BulkRef identifica un elemento de una operación bulk.
+// Index se usa cuando el elemento todavía no tiene ID (create/import);
+// ID cuando el recurso ya existe (delete/update).
+type BulkRef struct {
+	Index *int   `json:"index,omitempty"`
+	ID    string `json:"id,omitempty"`
+}
+
+// ByIndex referencia un elemento por su posición en el payload.
+func ByIndex(index int) BulkRef {
+	return BulkRef{Index: &index}
+}
+
+// ByID referencia un elemento por su ID.
+func ByID(id string) BulkRef {
+	return BulkRef{ID: id}
+}
+
+// BulkResult describe el resultado de un elemento de la operación. HTTPStatus es el status con el
+// que se respondería el error del elemento solo; en modo atómico es el de la respuesta.
+type BulkResult struct {
+	BulkRef
+	Status     string     `json:"status"`
+	Error      *ErrorBody `json:"error,omitempty"`
+	Data       any        `json:"data,omitempty"`
+	HTTPStatus int        `json:"-"`
+}
+
+// BulkSummary resume cuántos elementos terminaron en cada estado.
+type BulkSummary struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// BulkReport es el shape estándar (estilo 207 Multi-Status) que devuelven todos los endpoints bulk.
+// La respuesta HTTP es 200 y el detalle por elemento viaja en Results.
+type BulkReport struct {
+	Results []BulkResult `json:"results"`
+	Summary BulkSummary  `json:"summary"`
+}
+
+// NewBulkReport crea un reporte vacío con capacidad para size elementos.
+func NewBulkReport(size int) *BulkReport {
+	return &BulkReport{Results: make([]BulkResult, 0, size)}
+}
+
+// Succeed registra un elemento procesado correctamente. data es opcional.
+func (report *BulkReport) Succeed(ref BulkRef, data any) {
+	report.Results = append(report.Results, BulkResult{BulkRef: ref, Status: BulkStatusSucceeded, Data: data})
+	report.Summary.Succeeded++
+}
+
+// Fail registra un elemento que no pudo procesarse; status es el HTTP que le correspondería solo.
+func (report *BulkReport) Fail(ref BulkRef, status int, code, message string) {
+	report.Results = append(report.Results, BulkResult{
+		BulkRef:    ref,
+		Status:     BulkStatusFailed,
+		Error:      &ErrorBody{Code: code, Message: message},
+		HTTPStatus: status,
+	})
+	report.Summary.Failed++
+}
+
+// Skip registra un elemento que se omitió a propósito (por ejemplo, porque otro falló en modo atómico).
+func (report *BulkReport) Skip(ref BulkRef, code, message string) {
+	result := BulkResult{BulkRef: ref, Status: BulkStatusSkipped}
+	if code != "" {
+		result.Error = &ErrorBody{Code: code, Message: message}
+	}
+	report.Results = append(report.Results, result)
+	report.Summary.Skipped++
+}
+
+// FirstFailure devuelve el primer elemento fallido, si existe.
+// En modo atómico es el error que se devuelve al cliente.
+func (report *BulkReport) FirstFailure() (BulkResult, bool) {
+	for _, result := range report.Results {
+		if result.Status == BulkStatusFailed {
+			return result, true
+		}
+	}
+	return BulkResult{}, false
+}
+
+// ParseAtomic lee la opción ?atomic= de un request bulk. Sin el parámetro devuelve fallback, el modo
+// por defecto del endpoint. Un valor no booleano responde 400 invalid_atomic, igual en todos los
+// endpoints, y devuelve ok en false.
+func ParseAtomic(w http.ResponseWriter, r *http.Request, fallback bool) (atomic, ok bool) {
+	value := strings.TrimSpace(r.URL.Query().Get("atomic"))
+	if value == "" {
+		return fallback, true
+	}
+	atomic, err := strconv.ParseBool(value)
+	if err != nil {
+		FailWithDetails(w, r, http.StatusBadRequest, "invalid_atomic", "atomic must be true or false", []ErrorDetail{
+			{Field: "atomic", Message: "must be true or false"},
+		})
+		return false, false
+	}
+	return atomic, true
+}
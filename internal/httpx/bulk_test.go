@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkReport(t *testing.T) {
+	t.Run("summary tracks every status", func(t *testing.T) {
+		report := NewBulkReport(3)
+
+		report.Succeed(ByIndex(0), map[string]any{"id": "a"})
+		report.Fail(ByID("b"), http.StatusNotFound, "not_found", "item not found")
+		report.Skip(ByIndex(2), "", "")
+
+		require.Len(t, report.Results, 3)
+		require.Equal(t, BulkSummary{Succeeded: 1, Failed: 1, Skipped: 1}, report.Summary)
+		require.Equal(t, BulkStatusSucceeded, report.Results[0].Status)
+		require.Equal(t, BulkStatusFailed, report.Results[1].Status)
+		require.Equal(t, "not_found", report.Results[1].Error.Code)
+		require.Equal(t, BulkStatusSkipped, report.Results[2].Status)
+		require.Nil(t, report.Results[2].Error)
+	})
+
+	t.Run("first failure", func(t *testing.T) {
+		report := NewBulkReport(2)
+		_, found := report.FirstFailure()
+		require.False(t, found)
+
+		report.Succeed(ByIndex(0), nil)
+		report.Fail(ByIndex(1), http.StatusBadRequest, "invalid_input", "invalid input data")
+		report.Fail(ByIndex(2), http.StatusConflict, "conflict", "item name already exists")
+
+		failure, found := report.FirstFailure()
+		require.True(t, found)
+		require.Equal(t, 1, *failure.Index)
+		require.Equal(t, "invalid_input", failure.Error.Code)
+		require.Equal(t, http.StatusBadRequest, failure.HTTPStatus)
+	})
+
+	t.Run("json shape", func(t *testing.T) {
+		report := NewBulkReport(2)
+		report.Succeed(ByIndex(0), nil)
+		report.Fail(ByID("id-1"), http.StatusNotFound, "not_found", "item not found")
+
+		raw, err := json.Marshal(report)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"results": [
+				{"index": 0, "status": "succeeded"},
+				{"id": "id-1", "status": "failed", "error": {"code": "not_found", "message": "item not found"}}
+			],
+			"summary": {"succeeded": 1, "failed": 1, "skipped": 0}
+		}`, string(raw))
+	})
+}
+
+func TestParseAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		fallback bool
+		want     bool
+	}{
+		{"default", "/bulk", false, false},
+		{"endpoint default", "/bulk", true, true},
+		{"true", "/bulk?atomic=true", false, true},
+		{"false", "/bulk?atomic=false", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			atomic, ok := ParseAtomic(recorder, httptest.NewRequest(http.MethodPost, tt.target, nil), tt.fallback)
+
+			require.True(t, ok)
+			require.Equal(t, tt.want, atomic)
+			require.Zero(t, recorder.Body.Len())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		_, ok := ParseAtomic(recorder, httptest.NewRequest(http.MethodPost, "/bulk?atomic=maybe", nil), false)
+
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response Response
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, "invalid_atomic", response.Error.Code)
+		require.Equal(t, []ErrorDetail{{Field: "atomic", Message: "must be true or false"}}, response.Error.Details)
+	})
+}
@@ -0,0 +1,119 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyLimiter acota cuántos requests que tocan la DB corren a la vez.
+// Los que no consiguen lugar esperan en una cola chica con timeout corto;
+// si la cola está llena o el timeout vence, se responde 503 overloaded en vez de
+// dejar cientos de goroutines bloqueadas en pool.Acquire.
+type ConcurrencyLimiter struct {
+	slots    chan struct{}
+	maxQueue int64
+	wait     time.Duration
+
+	inUse    atomic.Int64
+	queued   atomic.Int64
+	rejected atomic.Uint64
+}
+
+// NewConcurrencyLimiter crea un limiter con limit lugares concurrentes,
+// hasta maxQueue requests esperando y un tiempo máximo de espera wait.
+func NewConcurrencyLimiter(limit, maxQueue int, wait time.Duration) *ConcurrencyLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &ConcurrencyLimiter{
+		slots:    make(chan struct{}, limit),
+		maxQueue: int64(maxQueue),
+		wait:     wait,
+	}
+}
+
+// Middleware aplica el límite a las rutas envueltas.
+// Health, readiness, docs y métricas no deberían pasar por acá.
+func (limiter *ConcurrencyLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.acquire(r) {
+			// Si el cliente se fue mientras esperaba, no es un rechazo por carga.
+			if errors.Is(r.Context().Err(), context.Canceled) {
+				w.WriteHeader(StatusClientClosedRequest)
+				return
+			}
+			limiter.rejected.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(limiter.retryAfterSeconds()))
+			Fail(w, r, http.StatusServiceUnavailable, "overloaded", "server is overloaded, retry later")
+			return
+		}
+		defer limiter.release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InUse devuelve la cantidad de requests ejecutándose dentro del límite.
+func (limiter *ConcurrencyLimiter) InUse() int {
+	return int(limiter.inUse.Load())
+}
+
+// Queued devuelve la cantidad de requests esperando lugar.
+func (limiter *ConcurrencyLimiter) Queued() int {
+	return int(limiter.queued.Load())
+}
+
+// Rejected devuelve cuántos requests se rechazaron con 503 desde el arranque.
+func (limiter *ConcurrencyLimiter) Rejected() uint64 {
+	return limiter.rejected.Load()
+}
+
+func (limiter *ConcurrencyLimiter) acquire(r *http.Request) bool {
+	// Camino rápido: hay lugar libre, no se encola.
+	select {
+	case limiter.slots <- struct{}{}:
+		limiter.inUse.Add(1)
+		return true
+	default:
+	}
+
+	if limiter.queued.Add(1) > limiter.maxQueue {
+		limiter.queued.Add(-1)
+		return false
+	}
+	defer limiter.queued.Add(-1)
+
+	timer := time.NewTimer(limiter.wait)
+	defer timer.Stop()
+
+	select {
+	case limiter.slots <- struct{}{}:
+		limiter.inUse.Add(1)
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func (limiter *ConcurrencyLimiter) release() {
+	limiter.inUse.Add(-1)
+	<-limiter.slots
+}
+
+// retryAfterSeconds redondea la espera configurada hacia arriba, con mínimo de 1 segundo.
+func (limiter *ConcurrencyLimiter) retryAfterSeconds() int {
+	seconds := int((limiter.wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,139 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler retiene cada request hasta que se cierra release.
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Run("passes through when there is room", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(2, 0, 10*time.Millisecond)
+		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, 1, limiter.InUse())
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, 0, limiter.InUse())
+	})
+
+	t.Run("rejects immediately when the queue is full", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(1, 0, time.Second)
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		handler := limiter.Middleware(blockingHandler(started, release))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+		}()
+		<-started
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "overloaded", resp.Error.Code)
+		require.Equal(t, uint64(1), limiter.Rejected())
+
+		close(release)
+		<-done
+	})
+
+	t.Run("queued request times out", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(1, 1, 20*time.Millisecond)
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		handler := limiter.Middleware(blockingHandler(started, release))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+		}()
+		<-started
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, 0, limiter.Queued())
+
+		close(release)
+		<-done
+	})
+
+	t.Run("client gone while queued is not counted as rejected", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(1, 1, time.Second)
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		handler := limiter.Middleware(blockingHandler(started, release))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+		}()
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+
+		require.Equal(t, StatusClientClosedRequest, rec.Code)
+		require.Equal(t, uint64(0), limiter.Rejected())
+
+		close(release)
+		<-done
+	})
+
+	t.Run("queued request gets a slot when one frees up", func(t *testing.T) {
+		limiter := NewConcurrencyLimiter(1, 1, time.Second)
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+		handler := limiter.Middleware(blockingHandler(started, release))
+
+		var wg sync.WaitGroup
+		codes := make([]int, 2)
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+				codes[i] = rec.Code
+			}(i)
+		}
+
+		<-started
+		require.Eventually(t, func() bool { return limiter.Queued() == 1 }, time.Second, time.Millisecond)
+		close(release)
+		<-started
+		wg.Wait()
+
+		require.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+		require.Equal(t, uint64(0), limiter.Rejected())
+	})
+}
@@ -0,0 +1,75 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetLastModified fija Last-Modified con modified truncado a segundos (formato HTTP, GMT).
+//
+// Si la respuesta sale en el mismo segundo en que cambió el recurso, el header se omite:
+// otro cambio dentro de ese segundo tendría el mismo Last-Modified y un cliente con ese valor
+// recibiría 304 con datos viejos (RFC 9110, 8.8.2.2). El próximo GET ya lo trae.
+// Devuelve si lo fijó: sin Last-Modified tampoco corresponde honrar If-Modified-Since.
+func SetLastModified(w http.ResponseWriter, modified, now time.Time) bool {
+	if modified.IsZero() || !now.Truncate(time.Second).After(modified.Truncate(time.Second)) {
+		return false
+	}
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	return true
+}
+
+// NotModifiedSince indica si el request trae If-Modified-Since y el recurso no cambió después
+// de esa fecha, o sea si corresponde responder 304.
+//
+// Si el request trae If-None-Match, If-Modified-Since se ignora: el ETag es el validador más
+// preciso y decide solo. Una fecha que no se puede parsear también se ignora.
+func NotModifiedSince(r *http.Request, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if r.Header.Get("If-None-Match") != "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// NotModified escribe un 304 sin body. Los headers de validación ya tienen que estar seteados.
+func NotModified(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// NoneMatch indica si el If-None-Match del request incluye etag (o es "*"), o sea si el cliente
+// ya tiene esa representación y corresponde responder 304. Compara en forma débil: W/"x" y "x" son iguales.
+func NoneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch devuelve las entity tags del header If-Match, sin espacios alrededor.
+// nil significa que el request no trae If-Match (sin precondición).
+func IfMatch(r *http.Request) []string {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return nil
+	}
+	tags := strings.Split(header, ",")
+	for index, tag := range tags {
+		tags[index] = strings.TrimSpace(tag)
+	}
+	return tags
+}
@@ -0,0 +1,126 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLastModified(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 10, 0, 0, 300_000_000, time.FixedZone("ART", -3*60*60))
+
+	t.Run("truncated to seconds in GMT", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		require.True(t, SetLastModified(recorder, modified, modified.Add(time.Hour)))
+
+		require.Equal(t, "Wed, 01 May 2024 13:00:00 GMT", recorder.Header().Get("Last-Modified"))
+	})
+
+	t.Run("omitted within the same second", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		require.False(t, SetLastModified(recorder, modified, modified.Add(500*time.Millisecond)))
+
+		require.Empty(t, recorder.Header().Get("Last-Modified"))
+	})
+
+	t.Run("omitted for zero time", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		require.False(t, SetLastModified(recorder, time.Time{}, time.Now()))
+
+		require.Empty(t, recorder.Header().Get("Last-Modified"))
+	})
+}
+
+func TestNotModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 13, 0, 0, 300_000_000, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    bool
+	}{
+		{"no header", http.MethodGet, nil, false},
+		{"same second", http.MethodGet, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT"}, true},
+		{"later", http.MethodGet, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 14:00:00 GMT"}, true},
+		{"earlier", http.MethodGet, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:59:59 GMT"}, false},
+		{"head", http.MethodHead, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT"}, true},
+		{"not a read", http.MethodPatch, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT"}, false},
+		{"invalid date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, false},
+		{
+			"if-none-match takes precedence",
+			http.MethodGet,
+			map[string]string{"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT", "If-None-Match": `"abc"`},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/items/id", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			require.Equal(t, tt.want, NotModifiedSince(req, modified))
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	const etag = `W/"abc"`
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"no header", "", false},
+		{"same weak tag", `W/"abc"`, true},
+		{"strong form matches weakly", `"abc"`, true},
+		{"one of many", `"x", W/"abc" ,"y"`, true},
+		{"any", "*", true},
+		{"different", `W/"abd"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			if tt.header != "" {
+				req.Header.Set("If-None-Match", tt.header)
+			}
+
+			require.Equal(t, tt.want, NoneMatch(req, etag))
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{"no header", "", nil},
+		{"single tag", `"3"`, []string{`"3"`}},
+		{"list", ` "3" , W/"4"`, []string{`"3"`, `W/"4"`}},
+		{"any", "*", []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/items/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+
+			require.Equal(t, tt.want, IfMatch(req))
+		})
+	}
+}
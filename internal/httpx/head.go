@@ -0,0 +1,48 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Head adapta un handler de GET para responder HEAD: corre el handler completo, así el
+// status y los headers son los mismos que en GET, descarta el body y fija Content-Length
+// con el largo que habría tenido.
+func Head(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &headRecorder{header: http.Header{}, status: http.StatusOK}
+		next(recorder, r)
+
+		for key, values := range recorder.header {
+			w.Header()[key] = values
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(recorder.size))
+		w.WriteHeader(recorder.status)
+	}
+}
+
+// headRecorder junta headers y status sin escribir nada, y solo cuenta los bytes del body.
+type headRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	size        int
+}
+
+func (recorder *headRecorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *headRecorder) WriteHeader(status int) {
+	if recorder.wroteHeader {
+		return
+	}
+	recorder.status = status
+	recorder.wroteHeader = true
+}
+
+func (recorder *headRecorder) Write(body []byte) (int, error) {
+	recorder.wroteHeader = true
+	recorder.size += len(body)
+	return len(body), nil
+}
@@ -0,0 +1,45 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHead(t *testing.T) {
+	t.Run("keeps status and headers and drops the body", func(t *testing.T) {
+		handler := Head(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Total-Count", "3")
+			JSON(w, http.StatusOK, Response{Data: map[string]any{"id": "a"}})
+		})
+
+		getRecorder := httptest.NewRecorder()
+		JSON(getRecorder, http.StatusOK, Response{Data: map[string]any{"id": "a"}})
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodHead, "/items", nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "3", recorder.Header().Get("X-Total-Count"))
+		require.Equal(t, getRecorder.Header().Get("Content-Type"), recorder.Header().Get("Content-Type"))
+		require.Equal(t, strconv.Itoa(getRecorder.Body.Len()), recorder.Header().Get("Content-Length"))
+		require.Zero(t, recorder.Body.Len())
+	})
+
+	t.Run("error status without body", func(t *testing.T) {
+		handler := Head(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodHead, "/items/x", nil))
+
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		require.Equal(t, "0", recorder.Header().Get("Content-Length"))
+		require.Zero(t, recorder.Body.Len())
+	})
+}
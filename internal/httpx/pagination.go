@@ -0,0 +1,53 @@
+package httpx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Pagination es el bloque de paginación de un listado por offset, con los mismos campos que GET /items.
+// TotalPages, HasNext y HasPrev se calculan acá para que los clientes no hagan la cuenta.
+type Pagination struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// NewPagination arma el bloque de paginación de una página por offset.
+// Con total 0 no hay páginas; una página más allá de la última no tiene siguiente pero sí anterior.
+func NewPagination(page, limit, total int) Pagination {
+	totalPages := (total + limit - 1) / limit
+	return Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+// ParsePagination parsea los parámetros page y limit. Sin page es la primera y sin limit,
+// defaultLimit; un limit mayor a maxLimit se recorta. Devuelve false si alguno no es un entero
+// positivo.
+func ParsePagination(pageValue, limitValue string, defaultLimit, maxLimit int) (page, limit int, ok bool) {
+	page, limit = 1, defaultLimit
+	if value := strings.TrimSpace(pageValue); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return 0, 0, false
+		}
+		page = number
+	}
+	if value := strings.TrimSpace(limitValue); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return 0, 0, false
+		}
+		limit = min(number, maxLimit)
+	}
+	return page, limit, true
+}
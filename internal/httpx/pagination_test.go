@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		name               string
+		page, limit, total int
+		want               Pagination
+	}{
+		{"first of several", 1, 10, 25, Pagination{Page: 1, Limit: 10, Total: 25, TotalPages: 3, HasNext: true}},
+		{"last page", 3, 10, 25, Pagination{Page: 3, Limit: 10, Total: 25, TotalPages: 3, HasPrev: true}},
+		{"empty", 1, 10, 0, Pagination{Page: 1, Limit: 10}},
+		{"beyond the last page", 5, 10, 25, Pagination{Page: 5, Limit: 10, Total: 25, TotalPages: 3, HasPrev: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewPagination(tt.page, tt.limit, tt.total))
+		})
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      string
+		limit     string
+		wantPage  int
+		wantLimit int
+		wantOK    bool
+	}{
+		{"defaults", "", "", 1, 20, true},
+		{"explicit", "3", "50", 3, 50, true},
+		{"spaces are trimmed", " 2 ", " 5 ", 2, 5, true},
+		{"limit above the maximum is capped", "1", "1000", 1, 100, true},
+		{"zero page", "0", "", 0, 0, false},
+		{"negative limit", "", "-1", 0, 0, false},
+		{"not a number", "x", "", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, limit, ok := ParsePagination(tt.page, tt.limit, 20, 100)
+
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantPage, page)
+			require.Equal(t, tt.wantLimit, limit)
+		})
+	}
+}
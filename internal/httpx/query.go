@@ -0,0 +1,56 @@
+package httpx
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// IgnoredQueryParams son los parámetros que RejectUnknownQueryParams acepta en cualquier endpoint:
+// los cache busters que agregan algunos clientes y CDNs y los de tracking de las campañas. Ningún
+// handler los lee.
+var IgnoredQueryParams = []string{"_", "cb", "cachebuster", "nocache", "utm_*"}
+
+// UnknownQueryParams devuelve, ordenadas, las claves de query que no están en allowed ni en
+// IgnoredQueryParams. Una entrada que termina en "*" admite cualquier clave con ese prefijo ("attr.*").
+func UnknownQueryParams(query url.Values, allowed ...string) []string {
+	var unknown []string
+	for key := range query {
+		if !knownQueryParam(key, allowed) && !knownQueryParam(key, IgnoredQueryParams) {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+// RejectUnknownQueryParams responde 400 unknown_parameter, con un detalle por clave, si el request
+// trae parámetros que no están en allowed (ver UnknownQueryParams). Así un typo (?serach=) no
+// devuelve el listado entero sin filtrar. Devuelve false si respondió.
+func RejectUnknownQueryParams(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
+	unknown := UnknownQueryParams(r.URL.Query(), allowed...)
+	if len(unknown) == 0 {
+		return true
+	}
+	details := make([]ErrorDetail, len(unknown))
+	for index, key := range unknown {
+		details[index] = ErrorDetail{Field: key, Message: "unknown query parameter"}
+	}
+	FailWithDetails(w, r, http.StatusBadRequest, "unknown_parameter", "unknown query parameters: "+strings.Join(unknown, ", "), details)
+	return false
+}
+
+// knownQueryParam informa si key está en params, exacta o por prefijo.
+func knownQueryParam(key string, params []string) bool {
+	for _, param := range params {
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == param {
+			return true
+		}
+	}
+	return false
+}
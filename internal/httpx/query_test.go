@@ -0,0 +1,48 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownQueryParams(t *testing.T) {
+	query := url.Values{
+		"query": {"phone"}, "serach": {"phone"}, "attr.color": {"red"}, "_": {"123"}, "utm_source": {"mail"}, "attr": {"x"},
+	}
+
+	require.Equal(t, []string{"attr", "serach"}, UnknownQueryParams(query, "query", "attr.*"))
+	require.Empty(t, UnknownQueryParams(url.Values{}, "query"))
+}
+
+func TestRejectUnknownQueryParams(t *testing.T) {
+	t.Run("known parameters pass", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		ok := RejectUnknownQueryParams(recorder, httptest.NewRequest(http.MethodGet, "/items?query=phone&_=1", nil), "query")
+
+		require.True(t, ok)
+		require.Zero(t, recorder.Body.Len())
+	})
+
+	t.Run("unknown parameters are listed", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+
+		ok := RejectUnknownQueryParams(recorder, httptest.NewRequest(http.MethodGet, "/items?serach=phone&limt=5", nil), "query", "limit")
+
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response Response
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, "unknown_parameter", response.Error.Code)
+		require.Equal(t, "unknown query parameters: limt, serach", response.Error.Message)
+		require.Equal(t, []ErrorDetail{
+			{Field: "limt", Message: "unknown query parameter"},
+			{Field: "serach", Message: "unknown query parameter"},
+		}, response.Error.Details)
+	})
+}
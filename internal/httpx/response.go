@@ -1,11 +1,18 @@
 package httpx
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 )
 
+// StatusClientClosedRequest es el 499 no estándar (nginx) para requests que el cliente abandonó.
+// Se escribe sin body: no hay nadie del otro lado para leerlo.
+const StatusClientClosedRequest = 499
+
 // Response es el sobre estándar que devuelve la API.
 // Mantener un formato consistente hace que los clientes (frontend/tests) sean más simples.
 type Response struct {
@@ -18,13 +25,22 @@ type Response struct {
 type Meta struct {
 	RequestID string `json:"request_id,omitempty"`
 	TimeUTC   string `json:"time_utc,omitempty"`
+	// Suggestion es el "quizás quisiste decir" de una búsqueda sin resultados.
+	Suggestion string `json:"suggestion,omitempty"`
 }
 
 // ErrorBody describe un error de forma estructurada.
 // No exponer detalles internos (SQL, stacktrace, etc.) en producción.
 type ErrorBody struct {
-	Code    string `json:"code,omitempty"`    // ej: "invalid_input", "not_found"
-	Message string `json:"message,omitempty"` // mensaje para humanos
+	Code    string        `json:"code,omitempty"`    // ej: "invalid_input", "not_found"
+	Message string        `json:"message,omitempty"` // mensaje para humanos
+	Details []ErrorDetail `json:"details,omitempty"` // detalle por campo, cuando aplica
+}
+
+// ErrorDetail apunta a un campo concreto del input que no pasó la validación.
+type ErrorDetail struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 // JSON escribe una respuesta JSON con headers correctos.
@@ -44,21 +60,39 @@ func JSON(w http.ResponseWriter, status int, resp Response) {
 
 // OK devuelve una respuesta exitosa con data.
 func OK(w http.ResponseWriter, r *http.Request, status int, data any) {
+	OKWithMeta(w, r, status, data, Meta{})
+}
+
+// OKWithMeta es OK con campos extra en meta. RequestID y TimeUTC se completan siempre.
+func OKWithMeta(w http.ResponseWriter, r *http.Request, status int, data any, meta Meta) {
+	meta.RequestID = RequestIDFrom(r)
+	meta.TimeUTC = time.Now().UTC().Format(time.RFC3339)
 	JSON(w, status, Response{
 		Data: data,
-		Meta: &Meta{
-			RequestID: RequestIDFrom(r),
-			TimeUTC:   time.Now().UTC().Format(time.RFC3339),
-		},
+		Meta: &meta,
 	})
 }
 
+// Created responde 201 con data y el header Location apuntando al recurso creado.
+// location se escribe tal cual (por ejemplo "/items/{id}"): un path relativo lo resuelve el cliente
+// contra la URL del request, así que sigue siendo válido si la API se monta detrás de un prefijo.
+func Created(w http.ResponseWriter, r *http.Request, location string, data any) {
+	w.Header().Set("Location", location)
+	OK(w, r, http.StatusCreated, data)
+}
+
 // Fail devuelve un error estructurado.
 func Fail(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	FailWithDetails(w, r, status, code, message, nil)
+}
+
+// FailWithDetails devuelve un error estructurado con detalle por campo.
+func FailWithDetails(w http.ResponseWriter, r *http.Request, status int, code, message string, details []ErrorDetail) {
 	JSON(w, status, Response{
 		Error: &ErrorBody{
 			Code:    code,
 			Message: message,
+			Details: details,
 		},
 		Meta: &Meta{
 			RequestID: RequestIDFrom(r),
@@ -66,3 +100,21 @@ func Fail(w http.ResponseWriter, r *http.Request, status int, code, message stri
 		},
 	})
 }
+
+// FailUnexpected responde errores que no son de validación ni de negocio, igual en todos los handlers.
+//   - Si el cliente cortó la conexión no hay nadie que lea la respuesta: se registra como
+//     client_disconnected y se escribe solo un 499 sin body, para no contarlo como error del servidor.
+//   - Si se agotó el tiempo del request (context.DeadlineExceeded, también envuelto) responde 503 timeout.
+//   - El resto es 500 y no filtramos detalles internos.
+func FailUnexpected(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		log.Printf("debug: client_disconnected method=%s path=%s request_id=%s", r.Method, r.URL.Path, RequestIDFrom(r))
+		w.WriteHeader(StatusClientClosedRequest)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		Fail(w, r, http.StatusServiceUnavailable, "timeout", "request deadline exceeded")
+		return
+	}
+	Fail(w, r, http.StatusInternalServerError, "internal_error", "unexpected error")
+}
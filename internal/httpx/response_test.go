@@ -2,7 +2,10 @@ package httpx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -56,6 +59,35 @@ func TestOK(t *testing.T) {
 	require.Equal(t, true, data["ok"])
 }
 
+func TestOKWithMeta(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "req-123")
+
+	OKWithMeta(rec, req, http.StatusOK, []any{}, Meta{Suggestion: "keyboard", RequestID: "ignored"})
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeResponse(t, rec)
+	require.Equal(t, "keyboard", resp.Meta.Suggestion)
+	require.Equal(t, "req-123", resp.Meta.RequestID)
+	require.NotEmpty(t, resp.Meta.TimeUTC)
+}
+
+func TestCreated(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/items", nil)
+	req.Header.Set("X-Request-Id", "req-123")
+
+	Created(rec, req, "/items/550e8400-e29b-41d4-a716-446655440000", map[string]any{"id": "550e8400-e29b-41d4-a716-446655440000"})
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "/items/550e8400-e29b-41d4-a716-446655440000", rec.Header().Get("Location"))
+
+	resp := decodeResponse(t, rec)
+	require.Equal(t, "req-123", resp.Meta.RequestID)
+	require.Equal(t, "550e8400-e29b-41d4-a716-446655440000", asMap(t, resp.Data)["id"])
+}
+
 func TestFail(t *testing.T) {
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -76,6 +108,63 @@ func TestFail(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestFailWithDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	FailWithDetails(rec, req, http.StatusBadRequest, "invalid_input", "invalid input data", []ErrorDetail{
+		{Field: "name", Message: "name contains a blocked word"},
+	})
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeResponse(t, rec)
+	require.NotNil(t, resp.Error)
+	require.Equal(t, "invalid_input", resp.Error.Code)
+	require.Equal(t, []ErrorDetail{{Field: "name", Message: "name contains a blocked word"}}, resp.Error.Details)
+}
+
+func TestFailUnexpected(t *testing.T) {
+	t.Run("client gone writes 499 without body", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+
+		FailUnexpected(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), context.Canceled)
+
+		require.Equal(t, StatusClientClosedRequest, rec.Code)
+		require.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("wrapped deadline is a timeout", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		FailUnexpected(rec, httptest.NewRequest(http.MethodGet, "/", nil), fmt.Errorf("query: %w", context.DeadlineExceeded))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		require.Equal(t, "timeout", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("anything else is an internal error", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		FailUnexpected(rec, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("db down"))
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "internal_error", resp.Error.Code)
+		require.Equal(t, "unexpected error", resp.Error.Message)
+	})
+}
+
+func TestFail_OmitsDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	Fail(rec, req, http.StatusNotFound, "not_found", "item not found")
+
+	require.NotContains(t, rec.Body.String(), "details")
+}
+
 func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) Response {
 	t.Helper()
 
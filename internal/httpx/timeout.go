@@ -0,0 +1,35 @@
+package httpx
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Timeout es middleware.Timeout salvo para los paths de except, que corren sin deadline. Es para los
+// streams (GET /items/events), que duran lo que dure la conexión: con el timeout global, el contexto
+// se cancelaría a los pocos segundos y cortaría el stream. Los paths se comparan exactos, salvo los
+// que terminan en "/", que excluyen todo lo que está debajo (las rutas con parámetros).
+func Timeout(timeout time.Duration, except ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limited := middleware.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.ContainsFunc(except, func(path string) bool { return excepted(path, r.URL.Path) }) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
+
+// excepted informa si requestPath cae en el path excluido path.
+func excepted(path, requestPath string) bool {
+	if strings.HasSuffix(path, "/") {
+		return strings.HasPrefix(requestPath, path)
+	}
+	return requestPath == path
+}
@@ -0,0 +1,33 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	// deadline informa si el request llegó al handler con deadline.
+	deadline := func(path string) bool {
+		var hasDeadline bool
+		handler := Timeout(time.Minute, "/items/events", "/imports/uploads/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, hasDeadline = r.Context().Deadline()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		return hasDeadline
+	}
+
+	t.Run("regular routes get the deadline", func(t *testing.T) {
+		require.True(t, deadline("/items"))
+		require.True(t, deadline("/items/events/extra"))
+		require.True(t, deadline("/imports/uploads"))
+	})
+
+	t.Run("excluded routes run without it", func(t *testing.T) {
+		require.False(t, deadline("/items/events"))
+		require.False(t, deadline("/imports/uploads/upload-1/chunks/2"))
+	})
+}
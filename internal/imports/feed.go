@@ -0,0 +1,264 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// FeedKind es el tipo de job de un import desde un feed remoto.
+const FeedKind = "items.import_feed"
+
+// Campos de item que se pueden leer de un feed.
+const (
+	feedFieldName        = "name"
+	feedFieldSKU         = "sku"
+	feedFieldDescription = "description"
+	feedFieldPrice       = "price"
+	feedFieldStock       = "stock"
+	feedFieldExternalID  = "external_id"
+)
+
+// feedFields son los campos que acepta el mapping, en el orden en que se validan.
+var feedFields = []string{feedFieldName, feedFieldSKU, feedFieldDescription, feedFieldPrice, feedFieldStock, feedFieldExternalID}
+
+// FeedPayload es el body de POST /imports/feed y lo que queda guardado en el job.
+type FeedPayload struct {
+	URL string `json:"url"`
+	// Mapping dice de qué clave de cada objeto del feed sale cada campo del item
+	// ({"name": "title", "price": "cost"}). Un campo sin mapping se lee de la clave con su nombre.
+	Mapping map[string]string `json:"mapping,omitempty"`
+	// RefSystem es el sistema de las refs externas del proveedor. Con RefSystem cada fila se busca
+	// primero por su external_id y los items creados quedan con esa ref.
+	RefSystem string `json:"ref_system,omitempty"`
+}
+
+// FeedSummary es el resultado de un import de feed terminado. Skipped son las filas que no se
+// aplicaron por un error de la fila (quedan en los errores del job). El avance guardado no separa
+// creados de actualizados, así que en un job retomado Created y Updated cuentan solo las filas del
+// último intento.
+type FeedSummary struct {
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// FeedSource descarga el feed. Fetcher la implementa.
+type FeedSource interface {
+	Fetch(ctx context.Context, rawURL string) ([]map[string]any, error)
+}
+
+// ItemUpserter es lo que el import de feed necesita de items. items.Service lo implementa.
+type ItemUpserter interface {
+	ItemCreator
+	Update(ctx context.Context, id string, input items.UpdateItemInput) (items.Item, error)
+	GetBySKU(ctx context.Context, sku string) (items.Item, error)
+	GetByExternalRef(ctx context.Context, system, externalID string) (items.Item, error)
+	AddExternalRef(ctx context.Context, itemID string, input items.ExternalRefInput) (items.ExternalRef, bool, error)
+}
+
+// FeedProcessor procesa los jobs de import de feed: descarga el feed y, por cada fila, actualiza el
+// item que ya existe (por ref externa o por SKU) o crea uno nuevo, con las mismas validaciones que la
+// API. Si el job se retoma después de un reinicio, el feed se vuelve a descargar y sigue desde la fila
+// donde quedó; como cada fila es un upsert, repetir alguna no duplica items.
+type FeedProcessor struct {
+	source FeedSource
+	items  ItemUpserter
+}
+
+// NewFeedProcessor crea el processor de imports de feed.
+func NewFeedProcessor(source FeedSource, upserter ItemUpserter) *FeedProcessor {
+	return &FeedProcessor{source: source, items: upserter}
+}
+
+// feedRow son los campos de una fila del feed que vinieron.
+type feedRow struct {
+	name        *string
+	sku         *string
+	description *string
+	price       *string
+	stock       *int
+	externalID  *string
+}
+
+// Process implementa jobqueue.Processor.
+func (processor *FeedProcessor) Process(ctx context.Context, job jobqueue.Job, reporter *jobqueue.Reporter) (any, error) {
+	var payload FeedPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode feed payload: %w", err)
+	}
+	rows, err := processor.source.Fetch(ctx, payload.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := job.Progress
+	progress.Total = len(rows)
+	var summary FeedSummary
+	var rowErrors []jobqueue.RowError
+	for row := progress.Processed; row < progress.Total; row++ {
+		updated, err := processor.upsert(ctx, payload, rows[row])
+		switch {
+		case err != nil:
+			rowError, ok := importRowError(row, err)
+			if !ok {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+			progress.Failed++
+			rowErrors = append(rowErrors, rowError)
+		case updated:
+			summary.Updated++
+		default:
+			summary.Created++
+		}
+		progress.Processed++
+		if progress.Processed%reportEvery == 0 || progress.Processed == progress.Total {
+			if err := reporter.Report(ctx, progress, rowErrors...); err != nil {
+				return nil, err
+			}
+			rowErrors = nil
+		}
+	}
+	if progress.Total == 0 {
+		if err := reporter.Report(ctx, progress); err != nil {
+			return nil, err
+		}
+	}
+	summary.Total, summary.Skipped = progress.Total, progress.Failed
+	return summary, nil
+}
+
+// upsert aplica una fila: devuelve true si actualizó un item que ya existía.
+func (processor *FeedProcessor) upsert(ctx context.Context, payload FeedPayload, raw map[string]any) (bool, error) {
+	row, err := parseFeedRow(raw, payload.Mapping)
+	if err != nil {
+		return false, err
+	}
+
+	existing, found, err := processor.match(ctx, payload.RefSystem, row)
+	if err != nil {
+		return false, err
+	}
+	if found {
+		_, err := processor.items.Update(ctx, existing.ID, items.UpdateItemInput{
+			Name:        row.name,
+			Description: row.description,
+			Price:       row.price,
+			Stock:       row.stock,
+		})
+		return true, err
+	}
+
+	input := items.CreateItemInput{SKU: row.sku, Description: row.description}
+	if row.name != nil {
+		input.Name = *row.name
+	}
+	if row.price != nil {
+		input.Price = *row.price
+	}
+	if row.stock != nil {
+		input.Stock = *row.stock
+	}
+	created, err := processor.items.Create(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	if payload.RefSystem != "" && row.externalID != nil {
+		_, _, err = processor.items.AddExternalRef(ctx, created.ID, items.ExternalRefInput{System: payload.RefSystem, ExternalID: *row.externalID})
+	}
+	return false, err
+}
+
+// match busca el item de la fila: por ref externa si el import tiene RefSystem y la fila external_id,
+// y si no por SKU.
+func (processor *FeedProcessor) match(ctx context.Context, refSystem string, row feedRow) (items.Item, bool, error) {
+	if refSystem != "" && row.externalID != nil {
+		item, err := processor.items.GetByExternalRef(ctx, refSystem, *row.externalID)
+		if err == nil || !errors.Is(err, items.ErrorNotFound) {
+			return item, err == nil, err
+		}
+	}
+	if row.sku != nil {
+		item, err := processor.items.GetBySKU(ctx, *row.sku)
+		if err == nil || !errors.Is(err, items.ErrorNotFound) {
+			return item, err == nil, err
+		}
+	}
+	return items.Item{}, false, nil
+}
+
+// parseFeedRow lee los campos del item de un objeto del feed según mapping. Una clave ausente o null
+// es un campo que no vino; un valor del tipo equivocado es un *items.ValidationError de la fila.
+func parseFeedRow(raw map[string]any, mapping map[string]string) (feedRow, error) {
+	var row feedRow
+	for _, field := range feedFields {
+		key := field
+		if mapped, ok := mapping[field]; ok {
+			key = mapped
+		}
+		value, ok := raw[key]
+		if !ok || value == nil {
+			continue
+		}
+		if field == feedFieldStock {
+			stock, err := feedInt(field, value)
+			if err != nil {
+				return feedRow{}, err
+			}
+			row.stock = &stock
+			continue
+		}
+		text, err := feedString(field, value)
+		if err != nil {
+			return feedRow{}, err
+		}
+		switch field {
+		case feedFieldName:
+			row.name = &text
+		case feedFieldSKU:
+			row.sku = &text
+		case feedFieldDescription:
+			row.description = &text
+		case feedFieldPrice:
+			row.price = &text
+		case feedFieldExternalID:
+			row.externalID = &text
+		}
+	}
+	return row, nil
+}
+
+// feedString acepta strings y números (los proveedores mandan precios y SKUs de las dos formas).
+func feedString(field string, value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	default:
+		return "", &items.ValidationError{Field: field, Message: field + " must be a string or a number"}
+	}
+}
+
+// feedInt acepta números enteros y strings con un entero.
+func feedInt(field string, value any) (int, error) {
+	var text string
+	switch value := value.(type) {
+	case json.Number:
+		text = value.String()
+	case string:
+		text = strings.TrimSpace(value)
+	}
+	parsed, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, &items.ValidationError{Field: field, Message: field + " must be an integer"}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,142 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+type fakeSource struct {
+	rows []map[string]any
+	err  error
+	url  string
+}
+
+func (source *fakeSource) Fetch(ctx context.Context, rawURL string) ([]map[string]any, error) {
+	source.url = rawURL
+	return source.rows, source.err
+}
+
+type fakeUpserter struct {
+	fakeCreator
+	bySKU   map[string]items.Item
+	byRef   map[string]items.Item
+	updated map[string]items.UpdateItemInput
+	refs    []items.ExternalRefInput
+}
+
+func (upserter *fakeUpserter) Update(ctx context.Context, id string, input items.UpdateItemInput) (items.Item, error) {
+	if upserter.updated == nil {
+		upserter.updated = make(map[string]items.UpdateItemInput)
+	}
+	upserter.updated[id] = input
+	return items.Item{ID: id}, nil
+}
+
+func (upserter *fakeUpserter) GetBySKU(ctx context.Context, sku string) (items.Item, error) {
+	if item, ok := upserter.bySKU[sku]; ok {
+		return item, nil
+	}
+	return items.Item{}, items.ErrorNotFound
+}
+
+func (upserter *fakeUpserter) GetByExternalRef(ctx context.Context, system, externalID string) (items.Item, error) {
+	if item, ok := upserter.byRef[system+"/"+externalID]; ok {
+		return item, nil
+	}
+	return items.Item{}, items.ErrorNotFound
+}
+
+func (upserter *fakeUpserter) AddExternalRef(ctx context.Context, itemID string, input items.ExternalRefInput) (items.ExternalRef, bool, error) {
+	upserter.refs = append(upserter.refs, input)
+	return items.ExternalRef{System: input.System, ExternalID: input.ExternalID}, true, nil
+}
+
+// feedJob arma un job de import de feed con payload.
+func feedJob(t *testing.T, payload FeedPayload) jobqueue.Job {
+	t.Helper()
+
+	encoded, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return jobqueue.Job{ID: "job-1", Kind: FeedKind, Attempts: 1, Payload: encoded}
+}
+
+func TestFeedProcessor_Process(t *testing.T) {
+	t.Run("upserts by ref and sku with the mapping", func(t *testing.T) {
+		source := &fakeSource{rows: []map[string]any{
+			{"code": "SUP-1", "title": "Teclado", "cost": json.Number("10.50"), "qty": json.Number("4"), "sku": "KB-1"},
+			{"code": "SUP-2", "title": "Mouse", "cost": "5.00", "sku": "MS-1"},
+			{"code": "SUP-3", "title": "Monitor", "cost": "100.00", "sku": "MN-1", "qty": json.Number("2")},
+			{"code": "SUP-4", "title": "Cable", "cost": "1.00", "qty": "many"},
+		}}
+		upserter := &fakeUpserter{
+			byRef: map[string]items.Item{"acme/SUP-1": {ID: "item-1"}},
+			bySKU: map[string]items.Item{"MS-1": {ID: "item-2"}},
+		}
+		store := &fakeStore{}
+		job := feedJob(t, FeedPayload{
+			URL:       "https://feeds.example.com/catalog.json",
+			Mapping:   map[string]string{"name": "title", "price": "cost", "stock": "qty", "external_id": "code"},
+			RefSystem: "acme",
+		})
+
+		result, err := NewFeedProcessor(source, upserter).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, "https://feeds.example.com/catalog.json", source.url)
+		require.Equal(t, FeedSummary{Total: 4, Created: 1, Updated: 2, Skipped: 1}, result)
+		require.Equal(t, "10.50", *upserter.updated["item-1"].Price)
+		require.Equal(t, 4, *upserter.updated["item-1"].Stock)
+		require.Nil(t, upserter.updated["item-2"].Stock, "fields missing from the feed are left as they are")
+		require.Equal(t, []string{"Monitor"}, upserter.created)
+		require.Equal(t, []items.ExternalRefInput{{System: "acme", ExternalID: "SUP-3"}}, upserter.refs)
+		require.Equal(t, []jobqueue.RowError{{Row: 3, Field: "stock", Message: "stock must be an integer"}}, store.rowErrors)
+		require.Equal(t, []jobqueue.Progress{{Total: 4, Processed: 4, Failed: 1}}, store.saved)
+	})
+
+	t.Run("invalid rows are skipped", func(t *testing.T) {
+		upserter := &fakeUpserter{fakeCreator: fakeCreator{errs: map[string]error{
+			"Mouse": items.ErrorDuplicateName,
+		}}}
+		source := &fakeSource{rows: []map[string]any{
+			{"name": "Mouse", "price": "5.00"},
+			{"name": true, "price": "5.00"},
+		}}
+		store := &fakeStore{}
+		job := feedJob(t, FeedPayload{URL: "https://feeds.example.com/catalog.json"})
+
+		result, err := NewFeedProcessor(source, upserter).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, FeedSummary{Total: 2, Skipped: 2}, result)
+		require.Equal(t, []jobqueue.RowError{
+			{Row: 0, Field: "name", Message: "item name already exists"},
+			{Row: 1, Field: "name", Message: "name must be a string or a number"},
+		}, store.rowErrors)
+	})
+
+	t.Run("fetch errors fail the job", func(t *testing.T) {
+		source := &fakeSource{err: ErrorBlockedAddress}
+		job := feedJob(t, FeedPayload{URL: "http://10.0.0.1/feed.json"})
+
+		_, err := NewFeedProcessor(source, &fakeUpserter{}).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{}, job))
+
+		require.ErrorIs(t, err, ErrorBlockedAddress)
+	})
+
+	t.Run("unexpected errors fail the job", func(t *testing.T) {
+		upserter := &fakeUpserter{fakeCreator: fakeCreator{errs: map[string]error{"Mouse": errors.New("connection refused")}}}
+		source := &fakeSource{rows: []map[string]any{{"name": "Mouse", "price": "5.00"}}}
+		job := feedJob(t, FeedPayload{URL: "https://feeds.example.com/catalog.json"})
+
+		_, err := NewFeedProcessor(source, upserter).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{}, job))
+
+		require.ErrorContains(t, err, "row 0: connection refused")
+	})
+}
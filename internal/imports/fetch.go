@@ -0,0 +1,153 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Límites de la descarga de un feed remoto.
+const (
+	feedTimeout      = 30 * time.Second
+	maxFeedBytes     = 32 << 20
+	maxFeedRedirects = 3
+)
+
+// ErrorBlockedAddress indica que la URL del feed apunta (o redirige) a una dirección que no es
+// pública: loopback, red privada, link-local y similares.
+var ErrorBlockedAddress = errors.New("feed address is not public")
+
+// deniedPrefixes son rangos que no son públicos y que netip no clasifica: CGNAT, "esta red" y los
+// reservados para pruebas de benchmark.
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// publicAddress dice si addr es una dirección a la que el import puede conectarse.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateFeedURL revisa lo que se puede revisar de la URL sin resolverla: que sea http o https
+// absoluta y que, si el host es una IP, sea pública. Los nombres se revisan al conectar.
+func ValidateFeedURL(raw string) error {
+	return validateFeedURL(raw, publicAddress)
+}
+
+// validateFeedURL es ValidateFeedURL con el criterio de direcciones del Fetcher.
+func validateFeedURL(raw string, allowed func(netip.Addr) bool) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if addr, err := netip.ParseAddr(parsed.Hostname()); err == nil && !allowed(addr) {
+		return ErrorBlockedAddress
+	}
+	return nil
+}
+
+// Fetcher descarga feeds remotos cuidándose de SSRF: la IP se revisa al conectar (después de
+// resolver el nombre, así un DNS que cambia entre la validación y la conexión no sirve para entrar a
+// la red interna), no usa el proxy del entorno y sigue hasta maxFeedRedirects redirects, cada uno
+// revisado de la misma forma.
+type Fetcher struct {
+	client *http.Client
+	// allowed decide a qué IPs se puede conectar; los tests lo cambian para usar httptest.
+	allowed func(netip.Addr) bool
+}
+
+// NewFetcher crea un Fetcher con el timeout y los límites del import.
+func NewFetcher() *Fetcher {
+	fetcher := &Fetcher{allowed: publicAddress}
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: fetcher.control}
+	fetcher.client = &http.Client{
+		Timeout: feedTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) > maxFeedRedirects {
+				return fmt.Errorf("feed: stopped after %d redirects", maxFeedRedirects)
+			}
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return fmt.Errorf("feed: redirect to unsupported scheme %q", request.URL.Scheme)
+			}
+			return nil
+		},
+	}
+	return fetcher
+}
+
+// control corre antes de cada conexión, con la IP ya resuelta.
+func (fetcher *Fetcher) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !fetcher.allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrorBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Fetch descarga el feed de rawURL, que tiene que ser un array JSON de objetos de hasta
+// maxFeedBytes. Los números quedan como json.Number para no perder decimales de los precios.
+func (fetcher *Fetcher) Fetch(ctx context.Context, rawURL string) ([]map[string]any, error) {
+	if err := validateFeedURL(rawURL, fetcher.allowed); err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := fetcher.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch feed: unexpected status %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxFeedBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	if len(body) > maxFeedBytes {
+		return nil, fmt.Errorf("fetch feed: body exceeds %d bytes", maxFeedBytes)
+	}
+
+	var rows []map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("fetch feed: body must be a JSON array of objects: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,130 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// loopbackFetcher es un Fetcher que deja conectarse a httptest (loopback) pero no a nada más privado.
+func loopbackFetcher() *Fetcher {
+	fetcher := NewFetcher()
+	fetcher.allowed = func(addr netip.Addr) bool { return addr.IsLoopback() || publicAddress(addr) }
+	return fetcher
+}
+
+func TestPublicAddress(t *testing.T) {
+	for address, public := range map[string]bool{
+		"8.8.8.8":            true,
+		"2606:4700::1111":    true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"100.64.0.1":         false,
+		"0.0.0.0":            false,
+		"fd00::1":            false,
+		"fe80::1":            false,
+		"::ffff:192.168.1.1": false,
+	} {
+		require.Equal(t, public, publicAddress(netip.MustParseAddr(address)), address)
+	}
+}
+
+func TestValidateFeedURL(t *testing.T) {
+	require.NoError(t, ValidateFeedURL("https://feeds.example.com/catalog.json"))
+	require.ErrorContains(t, ValidateFeedURL("ftp://feeds.example.com/catalog.json"), "http or https")
+	require.ErrorContains(t, ValidateFeedURL("/catalog.json"), "http or https")
+	require.ErrorIs(t, ValidateFeedURL("http://169.254.169.254/latest/meta-data"), ErrorBlockedAddress)
+	require.ErrorIs(t, ValidateFeedURL("http://[::1]:8080/feed"), ErrorBlockedAddress)
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	t.Run("json array with exact numbers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte(`[{"name":"Teclado","price":10.50}]`))
+		}))
+		defer server.Close()
+
+		rows, err := loopbackFetcher().Fetch(context.Background(), server.URL)
+
+		require.NoError(t, err)
+		require.Equal(t, []map[string]any{{"name": "Teclado", "price": json.Number("10.50")}}, rows)
+	})
+
+	t.Run("private addresses are refused when connecting", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+		// localhost pasa la validación de la URL (no es una IP); se rechaza al resolverla.
+		url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+		_, err := NewFetcher().Fetch(context.Background(), url)
+
+		require.ErrorIs(t, err, ErrorBlockedAddress)
+		require.False(t, called)
+	})
+
+	t.Run("redirects to private addresses are refused", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			http.Redirect(writer, request, "http://10.0.0.1/feed.json", http.StatusFound)
+		}))
+		defer server.Close()
+
+		_, err := loopbackFetcher().Fetch(context.Background(), server.URL)
+
+		require.ErrorIs(t, err, ErrorBlockedAddress)
+	})
+
+	t.Run("at most three redirects", func(t *testing.T) {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			hops, _ := strconv.Atoi(request.URL.Query().Get("hops"))
+			if hops > 0 {
+				http.Redirect(writer, request, server.URL+"/?hops="+strconv.Itoa(hops-1), http.StatusFound)
+				return
+			}
+			_, _ = writer.Write([]byte(`[]`))
+		}))
+		defer server.Close()
+
+		_, err := loopbackFetcher().Fetch(context.Background(), server.URL+"/?hops=3")
+		require.NoError(t, err)
+
+		_, err = loopbackFetcher().Fetch(context.Background(), server.URL+"/?hops=4")
+		require.ErrorContains(t, err, "stopped after 3 redirects")
+	})
+
+	t.Run("non-array body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte(`{"items":[]}`))
+		}))
+		defer server.Close()
+
+		_, err := loopbackFetcher().Fetch(context.Background(), server.URL)
+
+		require.ErrorContains(t, err, "JSON array")
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := loopbackFetcher().Fetch(context.Background(), server.URL)
+
+		require.ErrorContains(t, err, "unexpected status 404")
+	})
+}
@@ -0,0 +1,352 @@
+package imports
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// Límites de POST /imports. Lo que no entra se parte en varios imports.
+const (
+	maxPayloadBytes = 32 << 20
+	maxRows         = 100_000
+	// maxFeedRequestBytes es el tope del body de POST /imports/feed, que solo trae la URL y el mapping.
+	maxFeedRequestBytes = 64 << 10
+)
+
+// UploadPathPrefix es el prefijo de las rutas de una subida por partes (/imports/uploads/{id}/...).
+const UploadPathPrefix = "/imports/uploads/"
+
+// ServiceAPI define lo que el handler necesita para encolar el import. jobqueue.Service lo implementa.
+type ServiceAPI interface {
+	Enqueue(ctx context.Context, kind string, payload any, total int) (jobqueue.Job, error)
+}
+
+// UploadServiceAPI define lo que el handler necesita para las subidas por partes. UploadService lo
+// implementa.
+type UploadServiceAPI interface {
+	Create(ctx context.Context, upload Upload) (Upload, error)
+	Get(ctx context.Context, id string) (Upload, error)
+	PutChunk(ctx context.Context, id string, number int, data []byte) (Chunk, error)
+	Complete(ctx context.Context, id, checksum string) (jobqueue.Job, error)
+}
+
+// Handler recibe los imports.
+type Handler struct {
+	service ServiceAPI
+	uploads UploadServiceAPI
+}
+
+// HandlerOption configura comportamiento opcional del Handler.
+type HandlerOption func(*Handler)
+
+// WithUploads habilita las subidas por partes (/imports/uploads). Sin esta opción esas rutas
+// responden 501.
+func WithUploads(uploads UploadServiceAPI) HandlerOption {
+	return func(handler *Handler) {
+		handler.uploads = uploads
+	}
+}
+
+// NewHandler crea un handler de imports.
+func NewHandler(service ServiceAPI, options ...HandlerOption) *Handler {
+	handler := &Handler{service: service}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
+}
+
+// Create maneja POST /imports: guarda las filas en un job y responde 202 con el job y su URL en
+// Location; el worker crea los items fuera del request. El avance se consulta en GET /jobs/{id}.
+// Con ?dry_run=true el job solo valida las filas y reporta los mismos errores, sin crear nada. Con
+// ?mode=upsert las filas con el SKU de un item existente lo actualizan; no se combina con dry_run.
+// Con ?atomic=true el job valida todas las filas antes de crear ninguna (ver Processor).
+func (handler *Handler) Create(writer http.ResponseWriter, request *http.Request) {
+	options, ok := importOptions(writer, request)
+	if !ok {
+		return
+	}
+
+	var payload Payload
+	if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxPayloadBytes)).Decode(&payload); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			httpx.Fail(writer, request, http.StatusRequestEntityTooLarge, "payload_too_large", fmt.Sprintf("import body must be at most %d bytes", maxPayloadBytes))
+			return
+		}
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+	if len(payload.Items) == 0 || len(payload.Items) > maxRows {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data", []httpx.ErrorDetail{
+			{Field: "items", Message: fmt.Sprintf("items must have between 1 and %d rows", maxRows)},
+		})
+		return
+	}
+	payload.DryRun, payload.Mode, payload.Atomic = options.DryRun, options.Mode, options.Atomic
+
+	job, err := handler.service.Enqueue(request.Context(), Kind, payload, len(payload.Items))
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	writer.Header().Set("Location", "/jobs/"+job.ID)
+	httpx.OK(writer, request, http.StatusAccepted, job)
+}
+
+// CreateFeed maneja POST /imports/feed: valida la URL y el mapping y encola un job que descarga el
+// feed y hace upsert de cada fila. Responde 202 con el job; el total se conoce recién al descargar.
+func (handler *Handler) CreateFeed(writer http.ResponseWriter, request *http.Request) {
+	var payload FeedPayload
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxFeedRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+	payload.URL = strings.TrimSpace(payload.URL)
+	payload.RefSystem = strings.TrimSpace(payload.RefSystem)
+	if details := feedPayloadErrors(payload); len(details) > 0 {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data", details)
+		return
+	}
+
+	job, err := handler.service.Enqueue(request.Context(), FeedKind, payload, 0)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	writer.Header().Set("Location", "/jobs/"+job.ID)
+	httpx.OK(writer, request, http.StatusAccepted, job)
+}
+
+// CreateUpload maneja POST /imports/uploads: abre una subida por partes para un archivo que no
+// entra en POST /imports. ?format elige json (el default, el mismo body que POST /imports) o csv;
+// ?dry_run, ?mode y ?atomic son los del import que se encola al completarla. Responde 201 con la sesión.
+func (handler *Handler) CreateUpload(writer http.ResponseWriter, request *http.Request) {
+	if !handler.uploadsAvailable(writer, request) {
+		return
+	}
+	format := request.URL.Query().Get("format")
+	switch {
+	case format == "":
+		format = FormatJSON
+	case format != FormatJSON && format != FormatCSV:
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_format", "format must be json or csv")
+		return
+	}
+	options, ok := importOptions(writer, request)
+	if !ok {
+		return
+	}
+
+	upload, err := handler.uploads.Create(request.Context(), Upload{Format: format, Mode: options.Mode, DryRun: options.DryRun, Atomic: options.Atomic})
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	writer.Header().Set("Location", UploadPathPrefix+upload.ID)
+	httpx.OK(writer, request, http.StatusCreated, upload)
+}
+
+// GetUpload maneja GET /imports/uploads/{id}: devuelve la sesión con los chunks recibidos, para
+// retomar una subida cortada mandando solo los que faltan.
+func (handler *Handler) GetUpload(writer http.ResponseWriter, request *http.Request) {
+	if !handler.uploadsAvailable(writer, request) {
+		return
+	}
+	id, ok := uploadID(writer, request)
+	if !ok {
+		return
+	}
+
+	upload, err := handler.uploads.Get(request.Context(), id)
+	if err != nil {
+		failUpload(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, upload)
+}
+
+// PutChunk maneja PUT /imports/uploads/{id}/chunks/{n}: el body son los bytes del chunk n (de 1 a
+// 1000, hasta 8 MiB). Los chunks pueden llegar en cualquier orden y reenviar uno lo reemplaza.
+func (handler *Handler) PutChunk(writer http.ResponseWriter, request *http.Request) {
+	if !handler.uploadsAvailable(writer, request) {
+		return
+	}
+	id, ok := uploadID(writer, request)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(chi.URLParam(request, "n"))
+	if err != nil || number < 1 || number > maxChunks {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_chunk", fmt.Sprintf("chunk number must be between 1 and %d", maxChunks))
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, maxChunkBytes))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			httpx.Fail(writer, request, http.StatusRequestEntityTooLarge, "payload_too_large", fmt.Sprintf("chunk body must be at most %d bytes", maxChunkBytes))
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	if len(data) == 0 {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_chunk", "chunk body must not be empty")
+		return
+	}
+
+	chunk, err := handler.uploads.PutChunk(request.Context(), id, number, data)
+	if err != nil {
+		failUpload(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, chunk)
+}
+
+// CompleteUpload maneja POST /imports/uploads/{id}/complete: verifica que estén todos los chunks y
+// que el SHA-256 del archivo armado sea el del body, y encola el import. Responde 202 con el job,
+// como POST /imports; repetirlo devuelve el mismo job.
+func (handler *Handler) CompleteUpload(writer http.ResponseWriter, request *http.Request) {
+	if !handler.uploadsAvailable(writer, request) {
+		return
+	}
+	id, ok := uploadID(writer, request)
+	if !ok {
+		return
+	}
+	var body struct {
+		SHA256 string `json:"sha256"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxFeedRequestBytes)).Decode(&body); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+	checksum := strings.ToLower(strings.TrimSpace(body.SHA256))
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data", []httpx.ErrorDetail{
+			{Field: "sha256", Message: "sha256 must be 64 hexadecimal characters"},
+		})
+		return
+	}
+
+	job, err := handler.uploads.Complete(request.Context(), id, checksum)
+	if err != nil {
+		failUpload(writer, request, err)
+		return
+	}
+	writer.Header().Set("Location", "/jobs/"+job.ID)
+	httpx.OK(writer, request, http.StatusAccepted, job)
+}
+
+// uploadsAvailable responde 501 si el servidor no tiene configuradas las subidas por partes.
+func (handler *Handler) uploadsAvailable(writer http.ResponseWriter, request *http.Request) bool {
+	if handler.uploads == nil {
+		httpx.Fail(writer, request, http.StatusNotImplemented, "uploads_unavailable", "chunked uploads are not available on this server")
+		return false
+	}
+	return true
+}
+
+// uploadID lee y valida el {id} del path; si no es un UUID responde 400 y devuelve false.
+func uploadID(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// failUpload responde los errores de las subidas por partes y el resto como inesperados.
+func failUpload(writer http.ResponseWriter, request *http.Request, err error) {
+	var incomplete *IncompleteUploadError
+	switch {
+	case errors.Is(err, ErrorUploadNotFound), errors.Is(err, jobqueue.ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "upload not found")
+	case errors.Is(err, ErrorUploadCompleted):
+		httpx.Fail(writer, request, http.StatusConflict, "upload_completed", "upload already completed")
+	case errors.Is(err, ErrorUploadTooLarge):
+		httpx.Fail(writer, request, http.StatusRequestEntityTooLarge, "upload_too_large", fmt.Sprintf("upload must be at most %d bytes", maxUploadBytes))
+	case errors.Is(err, ErrorInvalidChunk):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_chunk", fmt.Sprintf("chunks must have 1 to %d bytes and a number between 1 and %d", maxChunkBytes, maxChunks))
+	case errors.Is(err, ErrorChecksumMismatch):
+		httpx.Fail(writer, request, http.StatusUnprocessableEntity, "checksum_mismatch", "sha256 does not match the uploaded file")
+	case errors.As(err, &incomplete):
+		httpx.Fail(writer, request, http.StatusConflict, "upload_incomplete", incomplete.Error())
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// importOptions lee ?dry_run, ?mode y ?atomic, compartidos por POST /imports y POST /imports/uploads,
+// en un Payload sin filas. Si son inválidos responde 400 y devuelve false.
+func importOptions(writer http.ResponseWriter, request *http.Request) (Payload, bool) {
+	var options Payload
+	if value := request.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_dry_run", "dry_run must be true or false")
+			return Payload{}, false
+		}
+		options.DryRun = parsed
+	}
+	atomic, ok := httpx.ParseAtomic(writer, request, false)
+	if !ok {
+		return Payload{}, false
+	}
+	options.Atomic = atomic
+	options.Mode = request.URL.Query().Get("mode")
+	switch {
+	case options.Mode == "":
+		options.Mode = ModeCreate
+	case options.Mode != ModeCreate && options.Mode != ModeUpsert:
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_mode", "mode must be create or upsert")
+		return Payload{}, false
+	}
+	if options.DryRun && options.Mode == ModeUpsert {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_mode", "dry_run is only supported with mode=create")
+		return Payload{}, false
+	}
+	if options.Atomic && options.Mode == ModeUpsert {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_mode", "atomic is only supported with mode=create")
+		return Payload{}, false
+	}
+	return options, true
+}
+
+// feedPayloadErrors valida el body de POST /imports/feed.
+func feedPayloadErrors(payload FeedPayload) []httpx.ErrorDetail {
+	var details []httpx.ErrorDetail
+	if err := ValidateFeedURL(payload.URL); err != nil {
+		message := err.Error()
+		if errors.Is(err, ErrorBlockedAddress) {
+			message = "url must point to a public address"
+		}
+		details = append(details, httpx.ErrorDetail{Field: "url", Message: message})
+	}
+	for field, key := range payload.Mapping {
+		if !slices.Contains(feedFields, field) || strings.TrimSpace(key) == "" {
+			details = append(details, httpx.ErrorDetail{Field: "mapping", Message: fmt.Sprintf("mapping keys must be one of %s and values non-empty", strings.Join(feedFields, ", "))})
+			break
+		}
+	}
+	return details
+}
@@ -0,0 +1,463 @@
+package imports_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/imports"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const jobID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+type stubService struct {
+	err     error
+	kind    string
+	payload any
+	total   int
+	called  bool
+}
+
+func (service *stubService) Enqueue(ctx context.Context, kind string, payload any, total int) (jobqueue.Job, error) {
+	service.called = true
+	service.kind, service.payload, service.total = kind, payload, total
+	if service.err != nil {
+		return jobqueue.Job{}, service.err
+	}
+	return jobqueue.Job{ID: jobID, Kind: kind, Status: jobqueue.StatusQueued, Progress: jobqueue.Progress{Total: total}}, nil
+}
+
+func TestHandler_Create(t *testing.T) {
+	t.Run("accepted with the job", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"},{"name":"Mouse","price":"5.00"}]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Equal(t, "/jobs/"+jobID, rec.Header().Get("Location"))
+		require.Equal(t, imports.Kind, service.kind)
+		require.Equal(t, 2, service.total)
+		require.Len(t, service.payload.(imports.Payload).Items, 2)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "queued", data["status"])
+		require.Equal(t, json.Number("2"), asMap(t, data["progress"])["total"])
+	})
+
+	t.Run("dry run comes from the query", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports?dry_run=true", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.True(t, service.payload.(imports.Payload).DryRun)
+
+		service = &stubService{}
+		req = httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}],"dry_run":true}`))
+		imports.NewHandler(service).Create(httptest.NewRecorder(), req)
+
+		require.False(t, service.payload.(imports.Payload).DryRun, "the body cannot ask for a dry run")
+	})
+
+	t.Run("invalid dry_run", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports?dry_run=maybe", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_dry_run", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.called)
+	})
+
+	t.Run("upsert mode", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports?mode=upsert", strings.NewReader(`{"items":[{"name":"Teclado","sku":"KB-1","price":"10.00"}]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Equal(t, imports.ModeUpsert, service.payload.(imports.Payload).Mode)
+	})
+
+	t.Run("atomic comes from the query", func(t *testing.T) {
+		service := &stubService{}
+
+		req := httptest.NewRequest(http.MethodPost, "/imports?atomic=true", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`))
+		rec := httptest.NewRecorder()
+		imports.NewHandler(service).Create(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.True(t, service.payload.(imports.Payload).Atomic)
+
+		req = httptest.NewRequest(http.MethodPost, "/imports?atomic=maybe", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`))
+		rec = httptest.NewRecorder()
+		imports.NewHandler(&stubService{}).Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_atomic", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		for target, message := range map[string]string{
+			"/imports?mode=replace":             "mode must be create or upsert",
+			"/imports?mode=upsert&dry_run=true": "dry_run is only supported with mode=create",
+			"/imports?mode=upsert&atomic=true":  "atomic is only supported with mode=create",
+		} {
+			service := &stubService{}
+			req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`))
+			rec := httptest.NewRecorder()
+
+			imports.NewHandler(service).Create(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, target)
+			body := decodeResponse(t, rec)
+			require.Equal(t, "invalid_mode", body.Error.Code)
+			require.Equal(t, message, body.Error.Message)
+			require.False(t, service.called)
+		}
+	})
+
+	t.Run("empty import", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":[]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "items", decodeResponse(t, rec).Error.Details[0].Field)
+		require.False(t, service.called)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		handler := imports.NewHandler(&stubService{})
+
+		req := httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		handler := imports.NewHandler(&stubService{err: errors.New("db down")})
+
+		req := httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_CreateFeed(t *testing.T) {
+	t.Run("accepted with the job", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports/feed", strings.NewReader(`{"url":" https://feeds.example.com/catalog.json ","mapping":{"name":"title"},"ref_system":"acme"}`))
+		rec := httptest.NewRecorder()
+
+		handler.CreateFeed(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Equal(t, "/jobs/"+jobID, rec.Header().Get("Location"))
+		require.Equal(t, imports.FeedKind, service.kind)
+		require.Equal(t, imports.FeedPayload{URL: "https://feeds.example.com/catalog.json", Mapping: map[string]string{"name": "title"}, RefSystem: "acme"}, service.payload)
+	})
+
+	t.Run("private address", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports/feed", strings.NewReader(`{"url":"http://127.0.0.1:5432/"}`))
+		rec := httptest.NewRecorder()
+
+		handler.CreateFeed(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		details := decodeResponse(t, rec).Error.Details
+		require.Equal(t, "url", details[0].Field)
+		require.Equal(t, "url must point to a public address", details[0].Message)
+		require.False(t, service.called)
+	})
+
+	t.Run("unknown mapping field", func(t *testing.T) {
+		service := &stubService{}
+		handler := imports.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/imports/feed", strings.NewReader(`{"url":"https://feeds.example.com/catalog.json","mapping":{"color":"colour"}}`))
+		rec := httptest.NewRecorder()
+
+		handler.CreateFeed(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "mapping", decodeResponse(t, rec).Error.Details[0].Field)
+		require.False(t, service.called)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		handler := imports.NewHandler(&stubService{})
+
+		req := httptest.NewRequest(http.MethodPost, "/imports/feed", strings.NewReader(`{"url":"file:///etc/passwd"}`))
+		rec := httptest.NewRecorder()
+
+		handler.CreateFeed(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "url", decodeResponse(t, rec).Error.Details[0].Field)
+	})
+}
+
+const uploadID = "0b4e7a0e-5e2f-4d7b-9d5c-3f1b2a6c8e90"
+
+type stubUploads struct {
+	upload   imports.Upload
+	chunk    []byte
+	number   int
+	checksum string
+	err      error
+}
+
+func (uploads *stubUploads) Create(ctx context.Context, upload imports.Upload) (imports.Upload, error) {
+	upload.ID = uploadID
+	uploads.upload = upload
+	return upload, uploads.err
+}
+
+func (uploads *stubUploads) Get(ctx context.Context, id string) (imports.Upload, error) {
+	return imports.Upload{ID: id, Chunks: []imports.Chunk{{Number: 1, Size: 4}}, Size: 4}, uploads.err
+}
+
+func (uploads *stubUploads) PutChunk(ctx context.Context, id string, number int, data []byte) (imports.Chunk, error) {
+	uploads.number, uploads.chunk = number, data
+	return imports.Chunk{Number: number, Size: len(data)}, uploads.err
+}
+
+func (uploads *stubUploads) Complete(ctx context.Context, id, checksum string) (jobqueue.Job, error) {
+	uploads.checksum = checksum
+	return jobqueue.Job{ID: jobID, Kind: imports.Kind, Status: jobqueue.StatusQueued}, uploads.err
+}
+
+// serveUploads manda request al router de imports con las subidas habilitadas.
+func serveUploads(uploads *stubUploads, request *http.Request) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	imports.RegisterRoutes(router, imports.NewHandler(&stubService{}, imports.WithUploads(uploads)))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, request)
+	return rec
+}
+
+func TestHandler_CreateUpload(t *testing.T) {
+	t.Run("created with the options", func(t *testing.T) {
+		uploads := &stubUploads{}
+
+		rec := serveUploads(uploads, httptest.NewRequest(http.MethodPost, "/imports/uploads?format=csv&mode=upsert", nil))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/imports/uploads/"+uploadID, rec.Header().Get("Location"))
+		require.Equal(t, imports.Upload{ID: uploadID, Format: imports.FormatCSV, Mode: imports.ModeUpsert}, uploads.upload)
+	})
+
+	t.Run("defaults to json", func(t *testing.T) {
+		uploads := &stubUploads{}
+
+		serveUploads(uploads, httptest.NewRequest(http.MethodPost, "/imports/uploads?dry_run=true&atomic=true", nil))
+
+		require.Equal(t, imports.Upload{ID: uploadID, Format: imports.FormatJSON, Mode: imports.ModeCreate, DryRun: true, Atomic: true}, uploads.upload)
+	})
+
+	for query, code := range map[string]string{"format=xml": "invalid_format", "mode=merge": "invalid_mode", "dry_run=yes": "invalid_dry_run", "atomic=yes": "invalid_atomic"} {
+		t.Run(code, func(t *testing.T) {
+			rec := serveUploads(&stubUploads{}, httptest.NewRequest(http.MethodPost, "/imports/uploads?"+query, nil))
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Equal(t, code, decodeResponse(t, rec).Error.Code)
+		})
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		imports.NewHandler(&stubService{}).CreateUpload(rec, httptest.NewRequest(http.MethodPost, "/imports/uploads", nil))
+
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+		require.Equal(t, "uploads_unavailable", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_GetUpload(t *testing.T) {
+	t.Run("returns the received chunks", func(t *testing.T) {
+		rec := serveUploads(&stubUploads{}, httptest.NewRequest(http.MethodGet, "/imports/uploads/"+uploadID, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, []any{map[string]any{"number": json.Number("1"), "size": json.Number("4")}}, data["chunks"])
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		rec := serveUploads(&stubUploads{}, httptest.NewRequest(http.MethodGet, "/imports/uploads/abc", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec := serveUploads(&stubUploads{err: imports.ErrorUploadNotFound}, httptest.NewRequest(http.MethodGet, "/imports/uploads/"+uploadID, nil))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "not_found", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_PutChunk(t *testing.T) {
+	t.Run("stores the chunk", func(t *testing.T) {
+		uploads := &stubUploads{}
+
+		rec := serveUploads(uploads, httptest.NewRequest(http.MethodPut, "/imports/uploads/"+uploadID+"/chunks/3", strings.NewReader("name,price")))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 3, uploads.number)
+		require.Equal(t, "name,price", string(uploads.chunk))
+		require.Equal(t, json.Number("10"), asMap(t, decodeResponse(t, rec).Data)["size"])
+	})
+
+	for name, test := range map[string]struct {
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		"number out of range": {"/chunks/0", "x", http.StatusBadRequest, "invalid_chunk"},
+		"number not a number": {"/chunks/one", "x", http.StatusBadRequest, "invalid_chunk"},
+		"empty body":          {"/chunks/1", "", http.StatusBadRequest, "invalid_chunk"},
+		"chunk too large":     {"/chunks/1", strings.Repeat("x", 8<<20+1), http.StatusRequestEntityTooLarge, "payload_too_large"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			uploads := &stubUploads{}
+
+			rec := serveUploads(uploads, httptest.NewRequest(http.MethodPut, "/imports/uploads/"+uploadID+test.path, strings.NewReader(test.body)))
+
+			require.Equal(t, test.status, rec.Code)
+			require.Equal(t, test.code, decodeResponse(t, rec).Error.Code)
+			require.Zero(t, uploads.number, "service must not be called")
+		})
+	}
+
+	for name, test := range map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"completed": {imports.ErrorUploadCompleted, http.StatusConflict, "upload_completed"},
+		"too large": {imports.ErrorUploadTooLarge, http.StatusRequestEntityTooLarge, "upload_too_large"},
+		"expired":   {imports.ErrorUploadNotFound, http.StatusNotFound, "not_found"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serveUploads(&stubUploads{err: test.err}, httptest.NewRequest(http.MethodPut, "/imports/uploads/"+uploadID+"/chunks/1", strings.NewReader("x")))
+
+			require.Equal(t, test.status, rec.Code)
+			require.Equal(t, test.code, decodeResponse(t, rec).Error.Code)
+		})
+	}
+}
+
+func TestHandler_CompleteUpload(t *testing.T) {
+	checksum := strings.Repeat("ab", 32)
+
+	t.Run("accepted with the job", func(t *testing.T) {
+		uploads := &stubUploads{}
+
+		rec := serveUploads(uploads, httptest.NewRequest(http.MethodPost, "/imports/uploads/"+uploadID+"/complete", strings.NewReader(`{"sha256":"`+strings.ToUpper(checksum)+`"}`)))
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Equal(t, "/jobs/"+jobID, rec.Header().Get("Location"))
+		require.Equal(t, checksum, uploads.checksum)
+	})
+
+	t.Run("invalid checksum", func(t *testing.T) {
+		uploads := &stubUploads{}
+
+		rec := serveUploads(uploads, httptest.NewRequest(http.MethodPost, "/imports/uploads/"+uploadID+"/complete", strings.NewReader(`{"sha256":"abc"}`)))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "sha256", decodeResponse(t, rec).Error.Details[0].Field)
+		require.Empty(t, uploads.checksum)
+	})
+
+	for name, test := range map[string]struct {
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		"incomplete": {&imports.IncompleteUploadError{Missing: []int{2, 4}}, http.StatusConflict, "upload_incomplete", "missing chunks: 2, 4"},
+		"mismatch":   {imports.ErrorChecksumMismatch, http.StatusUnprocessableEntity, "checksum_mismatch", "sha256 does not match the uploaded file"},
+		"unexpected": {errors.New("connection refused"), http.StatusInternalServerError, "internal_error", "unexpected error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serveUploads(&stubUploads{err: test.err}, httptest.NewRequest(http.MethodPost, "/imports/uploads/"+uploadID+"/complete", strings.NewReader(`{"sha256":"`+checksum+`"}`)))
+
+			require.Equal(t, test.status, rec.Code)
+			response := decodeResponse(t, rec)
+			require.Equal(t, test.code, response.Error.Code)
+			require.Equal(t, test.message, response.Error.Message)
+		})
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	router := chi.NewRouter()
+	imports.RegisterRoutes(router, imports.NewHandler(&stubService{}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00"}]}`)))
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
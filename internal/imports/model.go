@@ -0,0 +1,39 @@
+package imports
+
+import "github.com/Lelo88/catalog-api-golang/internal/items"
+
+// Kind es el tipo de job de un import de items.
+const Kind = "items.import"
+
+// Modos de un import (?mode=). En ModeUpsert una fila con el SKU de un item existente le actualiza
+// el precio, el stock y la descripción en lugar de fallar por repetida.
+const (
+	ModeCreate = "create"
+	ModeUpsert = "upsert"
+)
+
+// Payload es el body de POST /imports y lo que queda guardado en el job. DryRun, Mode y Atomic salen
+// de la query (?dry_run=true, ?mode=upsert, ?atomic=true), no del body; un Mode vacío es ModeCreate.
+// El job de una subida por partes no trae Items: el worker lee las filas del archivo UploadID, en Format.
+type Payload struct {
+	Items    []items.CreateItemInput `json:"items"`
+	DryRun   bool                    `json:"dry_run,omitempty"`
+	Mode     string                  `json:"mode,omitempty"`
+	Atomic   bool                    `json:"atomic,omitempty"`
+	UploadID string                  `json:"upload_id,omitempty"`
+	Format   string                  `json:"format,omitempty"`
+}
+
+// Summary es el resultado de un import terminado. Con DryRun, Created cuenta las filas que se
+// habrían creado. Updated son las filas que actualizaron un item existente en ModeUpsert; como en
+// FeedSummary, el avance guardado no separa creados de actualizados, así que en un job retomado
+// Updated cuenta solo las filas del último intento. Skipped son las filas válidas que un import
+// atómico no creó porque falló otra.
+type Summary struct {
+	Total   int  `json:"total"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Failed  int  `json:"failed"`
+	Skipped int  `json:"skipped"`
+	DryRun  bool `json:"dry_run"`
+}
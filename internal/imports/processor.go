@@ -0,0 +1,278 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// reportEvery es cada cuántas filas se guarda el avance (y se mira si pidieron cancelar).
+const reportEvery = 100
+
+// ItemCreator es lo que el import necesita de items. items.Service lo implementa.
+type ItemCreator interface {
+	Create(ctx context.Context, input items.CreateItemInput) (items.Item, error)
+	// ValidateCreate devuelve el error que daría Create sin escribir nada, y el input normalizado.
+	ValidateCreate(ctx context.Context, input items.CreateItemInput) (items.CreateItemInput, error)
+	// UpsertBySKU crea el item o actualiza el que tiene su SKU; devuelve true si lo creó.
+	UpsertBySKU(ctx context.Context, input items.CreateItemInput) (items.Item, bool, error)
+	// CreateAll crea todas las filas en una transacción; si una falla devuelve un *items.BatchError.
+	CreateAll(ctx context.Context, inputs []items.CreateItemInput) ([]items.Item, error)
+}
+
+// Processor procesa los jobs de import: crea cada fila con las mismas validaciones que POST /items.
+// Una fila inválida o repetida se cuenta como error de la fila y el import sigue; cualquier otro
+// error (la DB no responde) hace fallar el job. En ModeUpsert las filas con el SKU de un item
+// existente lo actualizan y las filas sin SKU se crean como siempre. Un dry run solo valida cada
+// fila, así que no toca la base y reporta los mismos errores que el import real. Las filas de una
+// subida por partes se leen del archivo en cada intento, así que un job retomado sigue desde la
+// fila en que quedó igual que uno de POST /imports. Un import atómico (?atomic=true) primero valida
+// todas las filas como un dry run y, si alguna falla, termina sin crear ninguna: reporta los errores
+// y cuenta las demás como skipped. Si todas son válidas las crea en una sola transacción, así que un
+// alta concurrente que ocupe un nombre o SKU entre la validación y la escritura tampoco deja el
+// import a medias: esa fila falla y las demás quedan como skipped.
+type Processor struct {
+	creator ItemCreator
+	uploads UploadSource
+}
+
+// ProcessorOption configura comportamiento opcional del Processor.
+type ProcessorOption func(*Processor)
+
+// WithUploadSource habilita los imports de subidas por partes.
+func WithUploadSource(uploads UploadSource) ProcessorOption {
+	return func(processor *Processor) {
+		processor.uploads = uploads
+	}
+}
+
+// NewProcessor crea el processor de imports.
+func NewProcessor(creator ItemCreator, options ...ProcessorOption) *Processor {
+	processor := &Processor{creator: creator}
+	for _, option := range options {
+		option(processor)
+	}
+	return processor
+}
+
+// Process implementa jobqueue.Processor.
+func (processor *Processor) Process(ctx context.Context, job jobqueue.Job, reporter *jobqueue.Reporter) (any, error) {
+	var payload Payload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode import payload: %w", err)
+	}
+	var invalid map[int]error
+	if payload.UploadID != "" {
+		rows, rowsInvalid, err := processor.uploadRows(ctx, payload)
+		if err != nil {
+			return nil, err
+		}
+		payload.Items, invalid = rows, rowsInvalid
+	}
+
+	progress := job.Progress
+	progress.Total = len(payload.Items)
+	if progress.Total == 0 {
+		// Un archivo sin filas: el total se guarda igual, como en los feeds.
+		if err := reporter.Report(ctx, progress); err != nil {
+			return nil, err
+		}
+	}
+	if payload.Atomic && !payload.DryRun && progress.Processed == 0 {
+		rowErrors, err := processor.validateRows(ctx, payload.Items, invalid)
+		if err != nil {
+			return nil, err
+		}
+		if len(rowErrors) > 0 {
+			progress.Processed, progress.Failed = progress.Total, len(rowErrors)
+			if err := reporter.Report(ctx, progress, rowErrors...); err != nil {
+				return nil, err
+			}
+			return Summary{Total: progress.Total, Failed: progress.Failed, Skipped: progress.Total - progress.Failed}, nil
+		}
+		return processor.createAll(ctx, payload.Items, progress, reporter)
+	}
+	var rowErrors []jobqueue.RowError
+	apply := processor.create
+	switch {
+	case payload.DryRun:
+		apply = newDryRun(processor.creator).check
+	case payload.Mode == ModeUpsert:
+		apply = processor.upsert
+	}
+	updated := 0
+	for row := progress.Processed; row < progress.Total; row++ {
+		rowUpdated, err := false, invalid[row]
+		if err == nil {
+			rowUpdated, err = apply(ctx, payload.Items[row])
+		}
+		switch {
+		case err != nil:
+			rowError, ok := importRowError(row, err)
+			if !ok {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+			progress.Failed++
+			rowErrors = append(rowErrors, rowError)
+		case rowUpdated:
+			updated++
+		}
+		progress.Processed++
+		if progress.Processed%reportEvery == 0 || progress.Processed == progress.Total {
+			if err := reporter.Report(ctx, progress, rowErrors...); err != nil {
+				return nil, err
+			}
+			rowErrors = nil
+		}
+	}
+	return Summary{Total: progress.Total, Created: progress.Processed - progress.Failed - updated, Updated: updated, Failed: progress.Failed, DryRun: payload.DryRun}, nil
+}
+
+// createAll crea las filas ya validadas de un import atómico en una sola transacción. Si una fila
+// falla no se crea ninguna: reporta el error de esa fila y cuenta las demás como skipped.
+func (processor *Processor) createAll(ctx context.Context, rows []items.CreateItemInput, progress jobqueue.Progress, reporter *jobqueue.Reporter) (any, error) {
+	progress.Processed = progress.Total
+	_, err := processor.creator.CreateAll(ctx, rows)
+	if err != nil {
+		var batchError *items.BatchError
+		if !errors.As(err, &batchError) {
+			return nil, err
+		}
+		rowError, ok := importRowError(batchError.Index, batchError.Err)
+		if !ok {
+			return nil, fmt.Errorf("row %d: %w", batchError.Index, batchError.Err)
+		}
+		progress.Failed = 1
+		if err := reporter.Report(ctx, progress, rowError); err != nil {
+			return nil, err
+		}
+		return Summary{Total: progress.Total, Failed: 1, Skipped: progress.Total - 1}, nil
+	}
+	if err := reporter.Report(ctx, progress); err != nil {
+		return nil, err
+	}
+	return Summary{Total: progress.Total, Created: progress.Total}, nil
+}
+
+// validateRows valida todas las filas de un import atómico sin escribir nada y devuelve el error de
+// cada fila inválida. invalid son las filas que ya fallaron al leer el archivo.
+func (processor *Processor) validateRows(ctx context.Context, rows []items.CreateItemInput, invalid map[int]error) ([]jobqueue.RowError, error) {
+	check := newDryRun(processor.creator).check
+	var rowErrors []jobqueue.RowError
+	for row, input := range rows {
+		err := invalid[row]
+		if err == nil {
+			_, err = check(ctx, input)
+		}
+		if err == nil {
+			continue
+		}
+		rowError, ok := importRowError(row, err)
+		if !ok {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		rowErrors = append(rowErrors, rowError)
+	}
+	return rowErrors, nil
+}
+
+// uploadRows lee las filas del archivo de una subida por partes.
+func (processor *Processor) uploadRows(ctx context.Context, payload Payload) ([]items.CreateItemInput, map[int]error, error) {
+	if processor.uploads == nil {
+		return nil, nil, errors.New("import uploads are not configured")
+	}
+	reader, err := processor.uploads.Open(ctx, payload.UploadID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open upload %s: %w", payload.UploadID, err)
+	}
+	return decodeUpload(reader, payload.Format)
+}
+
+// create da de alta una fila. Devuelve si actualizó un item existente, que acá es siempre false.
+func (processor *Processor) create(ctx context.Context, input items.CreateItemInput) (bool, error) {
+	_, err := processor.creator.Create(ctx, input)
+	return false, err
+}
+
+// upsert aplica una fila en ModeUpsert: con SKU crea o actualiza el item de ese SKU y sin SKU la
+// crea. Devuelve true si actualizó un item existente.
+func (processor *Processor) upsert(ctx context.Context, input items.CreateItemInput) (bool, error) {
+	if input.SKU == nil {
+		return processor.create(ctx, input)
+	}
+	_, created, err := processor.creator.UpsertBySKU(ctx, input)
+	return err == nil && !created, err
+}
+
+// dryRun valida las filas de un dry run. Además de lo que ya está en la base, recuerda las claves
+// únicas de las filas válidas anteriores: en el import real la segunda fila con el mismo SKU choca
+// con la primera. Si el job se retoma después de un reinicio, solo recuerda las filas que faltaban.
+type dryRun struct {
+	creator ItemCreator
+	seen    map[string]bool
+}
+
+func newDryRun(creator ItemCreator) *dryRun {
+	return &dryRun{creator: creator, seen: map[string]bool{}}
+}
+
+// check devuelve el error que daría la fila en el import real; nunca actualiza nada.
+func (run *dryRun) check(ctx context.Context, input items.CreateItemInput) (bool, error) {
+	input, err := run.creator.ValidateCreate(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	keys := uniqueKeys(input)
+	for _, key := range keys {
+		if run.seen[key.value] {
+			return false, key.duplicate
+		}
+	}
+	for _, key := range keys {
+		run.seen[key.value] = true
+	}
+	return false, nil
+}
+
+// uniqueKey es una clave única de un alta y el error que da si se repite.
+type uniqueKey struct {
+	value     string
+	duplicate error
+}
+
+// uniqueKeys devuelve las claves únicas de un alta en el orden en que las revisa CheckUniqueKeys.
+func uniqueKeys(input items.CreateItemInput) []uniqueKey {
+	keys := []uniqueKey{{"name:" + input.Name, items.ErrorDuplicateName}}
+	if input.Slug != "" {
+		keys = append(keys, uniqueKey{"slug:" + input.Slug, items.ErrorDuplicateSlug})
+	}
+	if input.SKU != nil {
+		keys = append(keys, uniqueKey{"sku:" + *input.SKU, items.ErrorDuplicateSKU})
+	}
+	if input.Barcode != nil {
+		keys = append(keys, uniqueKey{"barcode:" + *input.Barcode, items.ErrorDuplicateBarcode})
+	}
+	return keys
+}
+
+// importRowError traduce el error de una fila; devuelve false si no es culpa de la fila.
+func importRowError(row int, err error) (jobqueue.RowError, bool) {
+	var validationError *items.ValidationError
+	if errors.As(err, &validationError) {
+		return jobqueue.RowError{Row: row, Field: validationError.Field, Message: validationError.Message}, true
+	}
+	if errors.Is(err, items.ErrorInvalidInput) {
+		return jobqueue.RowError{Row: row, Message: err.Error()}, true
+	}
+	if duplicate, ok := items.DuplicateFieldOf(err); ok {
+		return jobqueue.RowError{Row: row, Field: duplicate.Field, Message: duplicate.Message}, true
+	}
+	if errors.Is(err, items.ErrorDuplicateExternalRef) {
+		return jobqueue.RowError{Row: row, Field: "external_id", Message: "external ref already belongs to another item"}, true
+	}
+	return jobqueue.RowError{}, false
+}
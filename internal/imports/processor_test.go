@@ -0,0 +1,318 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+type fakeCreator struct {
+	errs      map[string]error
+	created   []string
+	validated []string
+	// existing son los SKUs que UpsertBySKU actualiza en lugar de crear.
+	existing map[string]bool
+	updated  []string
+	// writeErrs son los errores que aparecen recién al escribir, como un alta concurrente.
+	writeErrs map[string]error
+	// batches son las filas creadas por cada llamada a CreateAll.
+	batches [][]string
+}
+
+func (creator *fakeCreator) Create(ctx context.Context, input items.CreateItemInput) (items.Item, error) {
+	if err := creator.errs[input.Name]; err != nil {
+		return items.Item{}, err
+	}
+	creator.created = append(creator.created, input.Name)
+	return items.Item{Name: input.Name}, nil
+}
+
+func (creator *fakeCreator) ValidateCreate(ctx context.Context, input items.CreateItemInput) (items.CreateItemInput, error) {
+	if err := creator.errs[input.Name]; err != nil {
+		return items.CreateItemInput{}, err
+	}
+	creator.validated = append(creator.validated, input.Name)
+	return input, nil
+}
+
+func (creator *fakeCreator) UpsertBySKU(ctx context.Context, input items.CreateItemInput) (items.Item, bool, error) {
+	if err := creator.errs[input.Name]; err != nil {
+		return items.Item{}, false, err
+	}
+	if creator.existing[*input.SKU] {
+		creator.updated = append(creator.updated, input.Name)
+		return items.Item{Name: input.Name}, false, nil
+	}
+	creator.created = append(creator.created, input.Name)
+	return items.Item{Name: input.Name}, true, nil
+}
+
+func (creator *fakeCreator) CreateAll(ctx context.Context, inputs []items.CreateItemInput) ([]items.Item, error) {
+	var names []string
+	created := make([]items.Item, 0, len(inputs))
+	for index, input := range inputs {
+		if err := creator.writeErrs[input.Name]; err != nil {
+			return nil, &items.BatchError{Index: index, Err: err}
+		}
+		names = append(names, input.Name)
+		created = append(created, items.Item{Name: input.Name})
+	}
+	creator.created = append(creator.created, names...)
+	creator.batches = append(creator.batches, names)
+	return created, nil
+}
+
+type fakeStore struct {
+	saved           []jobqueue.Progress
+	rowErrors       []jobqueue.RowError
+	cancelRequested bool
+}
+
+func (store *fakeStore) Claim(ctx context.Context, lease time.Duration) (jobqueue.Job, bool, error) {
+	return jobqueue.Job{}, false, nil
+}
+
+func (store *fakeStore) SaveProgress(ctx context.Context, id string, attempt int, progress jobqueue.Progress, rowErrors []jobqueue.RowError, lease time.Duration) (bool, error) {
+	store.saved = append(store.saved, progress)
+	store.rowErrors = rowErrors
+	return store.cancelRequested, nil
+}
+
+func (store *fakeStore) Finish(ctx context.Context, id string, attempt int, outcome jobqueue.Outcome) error {
+	return nil
+}
+
+// importJob arma un job de import con las filas names.
+func importJob(t *testing.T, names ...string) jobqueue.Job {
+	t.Helper()
+
+	payload := Payload{}
+	for _, name := range names {
+		payload.Items = append(payload.Items, items.CreateItemInput{Name: name, Price: "1.00"})
+	}
+	encoded, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return jobqueue.Job{ID: "job-1", Kind: Kind, Attempts: 1, Payload: encoded, Progress: jobqueue.Progress{Total: len(names)}}
+}
+
+// dryRunPayload marca como dry run el payload de un job.
+func dryRunPayload(t *testing.T, encoded []byte) []byte {
+	t.Helper()
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(encoded, &payload))
+	payload.DryRun = true
+	encoded, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return encoded
+}
+
+// atomicPayload marca como atómico el payload de un job.
+func atomicPayload(t *testing.T, encoded []byte) []byte {
+	t.Helper()
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(encoded, &payload))
+	payload.Atomic = true
+	encoded, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestProcessor_Process(t *testing.T) {
+	t.Run("bad rows are reported and the rest is created", func(t *testing.T) {
+		creator := &fakeCreator{errs: map[string]error{
+			"":      &items.ValidationError{Field: "name", Message: "name is required"},
+			"Mouse": items.ErrorDuplicateName,
+		}}
+		store := &fakeStore{}
+		job := importJob(t, "Teclado", "", "Mouse", "Monitor")
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, Summary{Total: 4, Created: 2, Failed: 2}, result)
+		require.Equal(t, []string{"Teclado", "Monitor"}, creator.created)
+		require.Equal(t, []jobqueue.RowError{
+			{Row: 1, Field: "name", Message: "name is required"},
+			{Row: 2, Field: "name", Message: "item name already exists"},
+		}, store.rowErrors)
+	})
+
+	t.Run("resumes after the processed rows", func(t *testing.T) {
+		creator := &fakeCreator{}
+		job := importJob(t, "Teclado", "Mouse", "Monitor")
+		job.Progress.Processed = 2
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{}, job))
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"Monitor"}, creator.created)
+		require.Equal(t, 3, result.(Summary).Created)
+	})
+
+	t.Run("progress is saved every few rows", func(t *testing.T) {
+		names := make([]string, reportEvery+1)
+		for i := range names {
+			names[i] = "Item " + strconv.Itoa(i)
+		}
+		store := &fakeStore{}
+		job := importJob(t, names...)
+
+		_, err := NewProcessor(&fakeCreator{}).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, []jobqueue.Progress{
+			{Total: reportEvery + 1, Processed: reportEvery},
+			{Total: reportEvery + 1, Processed: reportEvery + 1},
+		}, store.saved)
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		job := importJob(t, "Teclado", "Mouse")
+
+		_, err := NewProcessor(&fakeCreator{}).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{cancelRequested: true}, job))
+
+		require.ErrorIs(t, err, jobqueue.ErrorCanceled)
+	})
+
+	t.Run("dry run reports the same errors without creating", func(t *testing.T) {
+		creator := &fakeCreator{errs: map[string]error{
+			"":      &items.ValidationError{Field: "name", Message: "name is required"},
+			"Mouse": items.ErrorDuplicateName,
+		}}
+		store := &fakeStore{}
+		job := importJob(t, "Teclado", "", "Mouse", "Monitor")
+		job.Payload = dryRunPayload(t, job.Payload)
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, Summary{Total: 4, Created: 2, Failed: 2, DryRun: true}, result)
+		require.Empty(t, creator.created)
+		require.Equal(t, []string{"Teclado", "Monitor"}, creator.validated)
+		require.Equal(t, []jobqueue.RowError{
+			{Row: 1, Field: "name", Message: "name is required"},
+			{Row: 2, Field: "name", Message: "item name already exists"},
+		}, store.rowErrors)
+	})
+
+	t.Run("dry run catches keys repeated within the file", func(t *testing.T) {
+		sku, otherSKU := "KB-1", "KB-2"
+		encoded, err := json.Marshal(Payload{DryRun: true, Items: []items.CreateItemInput{
+			{Name: "Teclado", SKU: &sku, Price: "1.00"},
+			{Name: "Teclado", SKU: &otherSKU, Price: "1.00"},
+			{Name: "Mouse", SKU: &sku, Price: "1.00"},
+			{Name: "Monitor", SKU: &otherSKU, Price: "1.00"},
+		}})
+		require.NoError(t, err)
+		store := &fakeStore{}
+		job := jobqueue.Job{ID: "job-1", Kind: Kind, Attempts: 1, Payload: encoded}
+
+		result, err := NewProcessor(&fakeCreator{}).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, 2, result.(Summary).Created)
+		require.Equal(t, []jobqueue.RowError{
+			{Row: 1, Field: "name", Message: "item name already exists"},
+			{Row: 2, Field: "sku", Message: "item sku already exists"},
+		}, store.rowErrors, "a rejected row does not take its keys")
+	})
+
+	t.Run("atomic import creates nothing when a row fails", func(t *testing.T) {
+		creator := &fakeCreator{errs: map[string]error{"Mouse": items.ErrorDuplicateName}}
+		store := &fakeStore{}
+		job := importJob(t, "Teclado", "Mouse", "Monitor")
+		job.Payload = atomicPayload(t, job.Payload)
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, Summary{Total: 3, Failed: 1, Skipped: 2}, result)
+		require.Empty(t, creator.created)
+		require.Equal(t, []jobqueue.RowError{{Row: 1, Field: "name", Message: "item name already exists"}}, store.rowErrors)
+		require.Equal(t, []jobqueue.Progress{{Total: 3, Processed: 3, Failed: 1}}, store.saved)
+	})
+
+	t.Run("atomic import validates every row before creating", func(t *testing.T) {
+		creator := &fakeCreator{}
+		job := importJob(t, "Teclado", "Mouse")
+		job.Payload = atomicPayload(t, job.Payload)
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{}, job))
+
+		require.NoError(t, err)
+		require.Equal(t, Summary{Total: 2, Created: 2}, result)
+		require.Equal(t, []string{"Teclado", "Mouse"}, creator.validated)
+		require.Equal(t, [][]string{{"Teclado", "Mouse"}}, creator.batches, "every row is created in one transaction")
+	})
+
+	t.Run("atomic import creates nothing when a row fails on write", func(t *testing.T) {
+		creator := &fakeCreator{writeErrs: map[string]error{"Mouse": items.ErrorDuplicateSKU}}
+		store := &fakeStore{}
+		job := importJob(t, "Teclado", "Mouse", "Monitor")
+		job.Payload = atomicPayload(t, job.Payload)
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, Summary{Total: 3, Failed: 1, Skipped: 2}, result)
+		require.Empty(t, creator.created)
+		require.Equal(t, []jobqueue.RowError{{Row: 1, Field: "sku", Message: "item sku already exists"}}, store.rowErrors)
+		require.Equal(t, []jobqueue.Progress{{Total: 3, Processed: 3, Failed: 1}}, store.saved)
+	})
+
+	t.Run("atomic import fails the job when the transaction fails", func(t *testing.T) {
+		dbErr := errors.New("connection reset")
+		creator := &fakeCreator{writeErrs: map[string]error{"Mouse": dbErr}}
+		job := importJob(t, "Teclado", "Mouse")
+		job.Payload = atomicPayload(t, job.Payload)
+
+		_, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{}, job))
+
+		require.ErrorIs(t, err, dbErr)
+		require.Empty(t, creator.created)
+	})
+
+	t.Run("upsert updates existing skus and creates the rest", func(t *testing.T) {
+		existing, fresh, invalid := "KB-1", "MS-1", "MN-1"
+		encoded, err := json.Marshal(Payload{Mode: ModeUpsert, Items: []items.CreateItemInput{
+			{Name: "Teclado", SKU: &existing, Price: "1.00"},
+			{Name: "Mouse", SKU: &fresh, Price: "1.00"},
+			{Name: "Sin SKU", Price: "1.00"},
+			{Name: "Monitor", SKU: &invalid, Price: "1.00"},
+		}})
+		require.NoError(t, err)
+		creator := &fakeCreator{
+			existing: map[string]bool{existing: true},
+			errs:     map[string]error{"Monitor": &items.ValidationError{Field: "price", Message: "invalid price"}},
+		}
+		store := &fakeStore{}
+		job := jobqueue.Job{ID: "job-1", Kind: Kind, Attempts: 1, Payload: encoded}
+
+		result, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(store, job))
+
+		require.NoError(t, err)
+		require.Equal(t, Summary{Total: 4, Created: 2, Updated: 1, Failed: 1}, result)
+		require.Equal(t, []string{"Teclado"}, creator.updated)
+		require.Equal(t, []string{"Mouse", "Sin SKU"}, creator.created, "rows without a sku are created")
+		require.Equal(t, []jobqueue.RowError{{Row: 3, Field: "price", Message: "invalid price"}}, store.rowErrors)
+	})
+
+	t.Run("unexpected errors fail the job", func(t *testing.T) {
+		creator := &fakeCreator{errs: map[string]error{"Mouse": errors.New("connection refused")}}
+		job := importJob(t, "Teclado", "Mouse")
+
+		_, err := NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(&fakeStore{}, job))
+
+		require.ErrorContains(t, err, "row 1: connection refused")
+	})
+}
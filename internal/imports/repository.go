@@ -0,0 +1,203 @@
+package imports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// txBeginner lo implementa el pool; PutChunk bloquea la sesión para que dos chunks simultáneos no
+// pasen juntos el tope de tamaño.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Repository accede a las tablas import_uploads e import_upload_chunks.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de subidas de imports.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// uploadColumns son las columnas de Upload en el orden en que las escanea uploadDestinations. Los
+// chunks salen ordenados por número, sin los datos.
+const uploadColumns = `id, format, mode, dry_run, atomic, coalesce(sha256, ''), completed_at, coalesce(job_id::text, ''), expires_at, created_at, ` +
+	`(SELECT coalesce(json_agg(json_build_object('number', chunks.number, 'size', chunks.size) ORDER BY chunks.number), '[]') ` +
+	`FROM import_upload_chunks AS chunks WHERE chunks.upload_id = import_uploads.id)`
+
+// uploadDestinations devuelve los destinos de Scan para las columnas de uploadColumns.
+func uploadDestinations(upload *Upload) []any {
+	return []any{
+		&upload.ID, &upload.Format, &upload.Mode, &upload.DryRun, &upload.Atomic, &upload.SHA256, &upload.CompletedAt, &upload.JobID,
+		&upload.ExpiresAt, &upload.CreatedAt, &upload.Chunks,
+	}
+}
+
+// CreateUpload crea una sesión que vence ttl después de ahora y devuelve el registro persistido.
+func (repository *Repository) CreateUpload(ctx context.Context, upload Upload, ttl time.Duration) (Upload, error) {
+	const query = `
+		INSERT INTO import_uploads (format, mode, dry_run, atomic, expires_at)
+		VALUES ($1, $2, $3, $4, now() + make_interval(secs => $5))
+		RETURNING ` + uploadColumns + `;`
+
+	var created Upload
+	if err := repository.database.QueryRow(ctx, query, upload.Format, upload.Mode, upload.DryRun, upload.Atomic, ttl.Seconds()).Scan(uploadDestinations(&created)...); err != nil {
+		return Upload{}, err
+	}
+	return created, nil
+}
+
+// GetUpload busca una sesión con sus chunks. Devuelve ErrorUploadNotFound si no existe.
+func (repository *Repository) GetUpload(ctx context.Context, id string) (Upload, error) {
+	const query = `SELECT ` + uploadColumns + ` FROM import_uploads WHERE id = $1;`
+
+	var upload Upload
+	if err := repository.database.QueryRow(ctx, query, id).Scan(uploadDestinations(&upload)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Upload{}, ErrorUploadNotFound
+		}
+		return Upload{}, err
+	}
+	for _, chunk := range upload.Chunks {
+		upload.Size += int64(chunk.Size)
+	}
+	return upload, nil
+}
+
+// PutChunk guarda el chunk number o reemplaza el que ya estaba con ese número. Bloquea la sesión
+// mientras suma los demás chunks, así el tope maxBytes vale también con chunks simultáneos.
+func (repository *Repository) PutChunk(ctx context.Context, id string, number int, data []byte, maxBytes int64) error {
+	beginner, ok := repository.database.(txBeginner)
+	if !ok {
+		return errors.New("imports: database does not support transactions")
+	}
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rollback después de Commit no hace nada; cubre los caminos de error.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const lock = `
+		SELECT completed_at IS NOT NULL,
+		       (SELECT coalesce(sum(size), 0) FROM import_upload_chunks WHERE upload_id = $1 AND number <> $2)
+		FROM import_uploads
+		WHERE id = $1 AND expires_at > now()
+		FOR UPDATE;`
+	var completed bool
+	var used int64
+	if err := tx.QueryRow(ctx, lock, id, number).Scan(&completed, &used); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorUploadNotFound
+		}
+		return err
+	}
+	switch {
+	case completed:
+		return ErrorUploadCompleted
+	case used+int64(len(data)) > maxBytes:
+		return ErrorUploadTooLarge
+	}
+
+	const save = `
+		INSERT INTO import_upload_chunks (upload_id, number, size, data)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (upload_id, number) DO UPDATE SET size = EXCLUDED.size, data = EXCLUDED.data
+		RETURNING number;`
+	if err := tx.QueryRow(ctx, save, id, number, len(data), data).Scan(&number); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReadChunk devuelve los datos del chunk number. Devuelve ErrorUploadNotFound si no existe.
+func (repository *Repository) ReadChunk(ctx context.Context, id string, number int) ([]byte, error) {
+	const query = `SELECT data FROM import_upload_chunks WHERE upload_id = $1 AND number = $2;`
+
+	var data []byte
+	if err := repository.database.QueryRow(ctx, query, id, number).Scan(&data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrorUploadNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// CompleteUpload marca la sesión como completada con su checksum. Devuelve ErrorUploadCompleted
+// si ya estaba completada (otro complete llegó primero).
+func (repository *Repository) CompleteUpload(ctx context.Context, id, checksum string) error {
+	const query = `
+		UPDATE import_uploads SET completed_at = now(), sha256 = $2
+		WHERE id = $1 AND completed_at IS NULL
+		RETURNING id;`
+
+	if err := repository.database.QueryRow(ctx, query, id, checksum).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorUploadCompleted
+		}
+		return err
+	}
+	return nil
+}
+
+// SetUploadJob guarda el job del import de la sesión.
+func (repository *Repository) SetUploadJob(ctx context.Context, id, jobID string) error {
+	const query = `UPDATE import_uploads SET job_id = $2 WHERE id = $1 RETURNING id;`
+
+	if err := repository.database.QueryRow(ctx, query, id, jobID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorUploadNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ReopenUpload deshace CompleteUpload si la sesión todavía no tiene job.
+func (repository *Repository) ReopenUpload(ctx context.Context, id string) error {
+	const query = `
+		UPDATE import_uploads SET completed_at = NULL, sha256 = NULL
+		WHERE id = $1 AND job_id IS NULL
+		RETURNING id;`
+
+	if err := repository.database.QueryRow(ctx, query, id).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorUploadNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// PurgeExpiredUploads borra las sesiones vencidas y, en cascada, sus chunks. Las de un job que
+// todavía no terminó se conservan: el worker lee el archivo de ellas. Devuelve cuántas borró.
+func (repository *Repository) PurgeExpiredUploads(ctx context.Context) (int64, error) {
+	const query = `
+		WITH purged AS (
+			DELETE FROM import_uploads AS uploads
+			WHERE uploads.expires_at <= now()
+			  AND NOT EXISTS (
+			    SELECT 1 FROM jobs WHERE jobs.id = uploads.job_id AND jobs.status IN ('queued', 'running')
+			  )
+			RETURNING uploads.id
+		)
+		SELECT count(*) FROM purged;`
+
+	var purged int64
+	if err := repository.database.QueryRow(ctx, query).Scan(&purged); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
@@ -0,0 +1,88 @@
+//go:build integration
+
+package imports_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/imports"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_Upload(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	service := imports.NewUploadService(imports.NewRepository(pool), jobqueue.NewService(jobqueue.NewRepository(pool)), time.Hour)
+	upload, err := service.Create(ctx, imports.Upload{Format: imports.FormatCSV, Mode: imports.ModeCreate})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = pool.Exec(ctx, `DELETE FROM import_uploads WHERE id = $1`, upload.ID) })
+	require.Empty(t, upload.Chunks)
+	require.WithinDuration(t, time.Now().Add(time.Hour), upload.ExpiresAt, time.Minute)
+
+	content := "name,price\nTeclado,10.00\n"
+	// El chunk 2 llega primero y el 1 se reenvía: queda el último.
+	_, err = service.PutChunk(ctx, upload.ID, 2, []byte(content[11:]))
+	require.NoError(t, err)
+	_, err = service.PutChunk(ctx, upload.ID, 1, []byte("garbage"))
+	require.NoError(t, err)
+	_, err = service.PutChunk(ctx, upload.ID, 1, []byte(content[:11]))
+	require.NoError(t, err)
+
+	found, err := service.Get(ctx, upload.ID)
+	require.NoError(t, err)
+	require.Equal(t, []imports.Chunk{{Number: 1, Size: 11}, {Number: 2, Size: len(content) - 11}}, found.Chunks)
+	require.Equal(t, int64(len(content)), found.Size)
+
+	sum := sha256.Sum256([]byte(content))
+	job, err := service.Complete(ctx, upload.ID, hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = pool.Exec(ctx, `UPDATE jobs SET status = 'canceled' WHERE id = $1`, job.ID) })
+	require.Equal(t, imports.Kind, job.Kind)
+
+	again, err := service.Complete(ctx, upload.ID, hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	require.Equal(t, job.ID, again.ID)
+	_, err = service.PutChunk(ctx, upload.ID, 3, []byte("x"))
+	require.ErrorIs(t, err, imports.ErrorUploadCompleted)
+
+	reader, err := service.Open(ctx, upload.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, content, string(data))
+
+	// Vencida pero con el job pendiente, la limpieza la conserva; con el job terminado la borra.
+	_, err = pool.Exec(ctx, `UPDATE import_uploads SET expires_at = now() - interval '1 minute' WHERE id = $1`, upload.ID)
+	require.NoError(t, err)
+	_, err = service.PurgeExpired(ctx)
+	require.NoError(t, err)
+	_, err = service.Open(ctx, upload.ID)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `UPDATE jobs SET status = 'canceled' WHERE id = $1`, job.ID)
+	require.NoError(t, err)
+	purged, err := service.PurgeExpired(ctx)
+	require.NoError(t, err)
+	require.Positive(t, purged)
+	_, err = service.Open(ctx, upload.ID)
+	require.ErrorIs(t, err, imports.ErrorUploadNotFound)
+}
@@ -0,0 +1,210 @@
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_CreateUpload(t *testing.T) {
+	createdAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	database := &plainDB{row: &fakeRow{values: uploadValues("upload-1", createdAt, []Chunk{})}}
+
+	upload, err := NewRepository(database).CreateUpload(context.Background(), Upload{Format: FormatCSV, Mode: ModeCreate, Atomic: true}, 2*time.Hour)
+
+	require.NoError(t, err)
+	require.Equal(t, "upload-1", upload.ID)
+	require.Contains(t, normalizeSQL(database.lastQuery), "now() + make_interval(secs => $5)")
+	require.Equal(t, []any{FormatCSV, ModeCreate, false, true, float64(7200)}, database.lastArgs)
+}
+
+func TestRepository_GetUpload(t *testing.T) {
+	t.Run("sums the chunks", func(t *testing.T) {
+		createdAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+		chunks := []Chunk{{Number: 1, Size: 10}, {Number: 3, Size: 5}}
+		database := &plainDB{row: &fakeRow{values: uploadValues("upload-1", createdAt, chunks)}}
+
+		upload, err := NewRepository(database).GetUpload(context.Background(), "upload-1")
+
+		require.NoError(t, err)
+		require.Equal(t, chunks, upload.Chunks)
+		require.Equal(t, int64(15), upload.Size)
+		require.Contains(t, normalizeSQL(database.lastQuery), "ORDER BY chunks.number")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &plainDB{row: &fakeRow{err: pgx.ErrNoRows}}
+
+		_, err := NewRepository(database).GetUpload(context.Background(), "upload-1")
+
+		require.ErrorIs(t, err, ErrorUploadNotFound)
+	})
+}
+
+func TestRepository_PutChunk(t *testing.T) {
+	put := func(tx *fakeTx, size int) error {
+		database := &fakeDB{tx: tx}
+		return NewRepository(database).PutChunk(context.Background(), "upload-1", 2, make([]byte, size), 100)
+	}
+
+	t.Run("saves the chunk under the lock", func(t *testing.T) {
+		tx := &fakeTx{rows: []*fakeRow{{values: []any{false, int64(60)}}, {values: []any{2}}}}
+
+		err := put(tx, 40)
+
+		require.NoError(t, err)
+		require.True(t, tx.committed)
+		require.Contains(t, normalizeSQL(tx.queries[0]), "FOR UPDATE")
+		require.Contains(t, normalizeSQL(tx.queries[1]), "ON CONFLICT (upload_id, number) DO UPDATE SET size = EXCLUDED.size, data = EXCLUDED.data")
+	})
+
+	for name, test := range map[string]struct {
+		lock *fakeRow
+		want error
+	}{
+		"not found or expired": {&fakeRow{err: pgx.ErrNoRows}, ErrorUploadNotFound},
+		"completed":            {&fakeRow{values: []any{true, int64(0)}}, ErrorUploadCompleted},
+		"too large":            {&fakeRow{values: []any{false, int64(61)}}, ErrorUploadTooLarge},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tx := &fakeTx{rows: []*fakeRow{test.lock}}
+
+			err := put(tx, 40)
+
+			require.ErrorIs(t, err, test.want)
+			require.Len(t, tx.queries, 1)
+			require.True(t, tx.rolledBack)
+		})
+	}
+
+	t.Run("database without transactions", func(t *testing.T) {
+		err := NewRepository(&plainDB{}).PutChunk(context.Background(), "upload-1", 1, []byte("x"), 100)
+
+		require.ErrorContains(t, err, "does not support transactions")
+	})
+}
+
+func TestRepository_CompleteUpload(t *testing.T) {
+	t.Run("completes", func(t *testing.T) {
+		database := &plainDB{row: &fakeRow{values: []any{"upload-1"}}}
+
+		err := NewRepository(database).CompleteUpload(context.Background(), "upload-1", "abc")
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id = $1 AND completed_at IS NULL")
+	})
+
+	t.Run("already completed", func(t *testing.T) {
+		database := &plainDB{row: &fakeRow{err: pgx.ErrNoRows}}
+
+		err := NewRepository(database).CompleteUpload(context.Background(), "upload-1", "abc")
+
+		require.ErrorIs(t, err, ErrorUploadCompleted)
+	})
+}
+
+func TestRepository_PurgeExpiredUploads(t *testing.T) {
+	database := &plainDB{row: &fakeRow{values: []any{int64(3)}}}
+
+	purged, err := NewRepository(database).PurgeExpiredUploads(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, int64(3), purged)
+	require.Contains(t, normalizeSQL(database.lastQuery), "jobs.status IN ('queued', 'running')")
+}
+
+// uploadValues son los valores de uploadColumns para una sesión abierta.
+func uploadValues(id string, createdAt time.Time, chunks []Chunk) []any {
+	return []any{id, FormatJSON, ModeCreate, false, false, "", (*time.Time)(nil), "", createdAt.Add(24 * time.Hour), createdAt, chunks}
+}
+
+// plainDB es una base sin transacciones.
+type plainDB struct {
+	row       *fakeRow
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *plainDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.row == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.row
+}
+
+func (db *plainDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected Query call")
+}
+
+type fakeDB struct {
+	plainDB
+	tx *fakeTx
+}
+
+func (db *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return db.tx, nil
+}
+
+// fakeTx devuelve rows en orden, una por QueryRow.
+type fakeTx struct {
+	pgx.Tx
+	rows       []*fakeRow
+	queries    []string
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	tx.queries = append(tx.queries, sql)
+	if len(tx.queries) > len(tx.rows) {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return tx.rows[len(tx.queries)-1]
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	return assignValues(dest, row.values)
+}
+
+func assignValues(dest []any, values []any) error {
+	if len(dest) != len(values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
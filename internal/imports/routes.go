@@ -0,0 +1,13 @@
+package imports
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra las rutas de imports en el router.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Post("/imports", handler.Create)
+	route.Post("/imports/feed", handler.CreateFeed)
+	route.Post("/imports/uploads", handler.CreateUpload)
+	route.Get("/imports/uploads/{id}", handler.GetUpload)
+	route.Put("/imports/uploads/{id}/chunks/{n}", handler.PutChunk)
+	route.Post("/imports/uploads/{id}/complete", handler.CompleteUpload)
+}
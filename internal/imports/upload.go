@@ -0,0 +1,385 @@
+package imports
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// Formatos del archivo de una subida por partes. FormatJSON es el mismo body que POST /imports
+// ({"items": [...]}); FormatCSV tiene una fila de encabezado con los nombres de los campos.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Límites de las subidas por partes. Un chunk tiene que subir holgado dentro del timeout de los
+// requests; el archivo entero tiene el mismo tope de filas que POST /imports.
+const (
+	maxChunkBytes  = 8 << 20
+	maxChunks      = 1000
+	maxUploadBytes = 512 << 20
+)
+
+// Upload es una sesión de subida por partes. Format, Mode y DryRun se eligen al abrirla y son los
+// del import que se encola al completarla. Los chunks se numeran desde 1 y el archivo es su
+// concatenación en ese orden.
+type Upload struct {
+	ID     string  `json:"id"`
+	Format string  `json:"format"`
+	Mode   string  `json:"mode"`
+	DryRun bool    `json:"dry_run"`
+	Atomic bool    `json:"atomic"`
+	Chunks []Chunk `json:"chunks"`
+	// Size es la suma de los chunks recibidos.
+	Size        int64      `json:"size"`
+	SHA256      string     `json:"sha256,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	JobID       string     `json:"job_id,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Chunk es una parte recibida de una subida.
+type Chunk struct {
+	Number int `json:"number"`
+	Size   int `json:"size"`
+}
+
+// Errores de las subidas por partes. El handler los traduce a status codes.
+var (
+	// ErrorUploadNotFound es una sesión que no existe o que ya venció.
+	ErrorUploadNotFound = errors.New("upload not found")
+	// ErrorUploadCompleted es un chunk para una sesión ya completada, o un complete mientras otro
+	// request la está completando.
+	ErrorUploadCompleted = errors.New("upload already completed")
+	// ErrorUploadTooLarge es un chunk que haría pasar al archivo de maxUploadBytes.
+	ErrorUploadTooLarge = errors.New("upload too large")
+	// ErrorInvalidChunk es un chunk vacío o con un número fuera de 1..maxChunks.
+	ErrorInvalidChunk = errors.New("invalid chunk")
+	// ErrorChecksumMismatch es un SHA-256 que no coincide con el del archivo armado.
+	ErrorChecksumMismatch = errors.New("upload checksum does not match")
+)
+
+// IncompleteUploadError es un complete con chunks faltantes: Missing son los números que faltan
+// entre 1 y el último recibido. Una sesión sin chunks tiene Missing vacío.
+type IncompleteUploadError struct {
+	Missing []int
+}
+
+func (err *IncompleteUploadError) Error() string {
+	if len(err.Missing) == 0 {
+		return "upload has no chunks"
+	}
+	missing := make([]string, len(err.Missing))
+	for index, number := range err.Missing {
+		missing[index] = strconv.Itoa(number)
+	}
+	return "missing chunks: " + strings.Join(missing, ", ")
+}
+
+// UploadStore es lo que las subidas necesitan de la base. Repository lo implementa.
+type UploadStore interface {
+	CreateUpload(ctx context.Context, upload Upload, ttl time.Duration) (Upload, error)
+	// GetUpload devuelve la sesión con sus chunks, vencida o no; ErrorUploadNotFound si no existe.
+	GetUpload(ctx context.Context, id string) (Upload, error)
+	// PutChunk guarda o reemplaza el chunk number. Devuelve ErrorUploadNotFound si la sesión no existe
+	// o venció, ErrorUploadCompleted si ya se completó y ErrorUploadTooLarge si pasaría de maxBytes.
+	PutChunk(ctx context.Context, id string, number int, data []byte, maxBytes int64) error
+	ReadChunk(ctx context.Context, id string, number int) ([]byte, error)
+	// CompleteUpload marca la sesión como completada; ErrorUploadCompleted si otro llegó primero.
+	CompleteUpload(ctx context.Context, id, checksum string) error
+	SetUploadJob(ctx context.Context, id, jobID string) error
+	// ReopenUpload deshace CompleteUpload mientras la sesión no tenga job.
+	ReopenUpload(ctx context.Context, id string) error
+	// PurgeExpiredUploads borra las sesiones vencidas cuyo job (si tienen) ya terminó.
+	PurgeExpiredUploads(ctx context.Context) (int64, error)
+}
+
+// JobQueue encola el import de una subida completa. jobqueue.Service lo implementa.
+type JobQueue interface {
+	Enqueue(ctx context.Context, kind string, payload any, total int) (jobqueue.Job, error)
+	Get(ctx context.Context, id string) (jobqueue.Job, error)
+}
+
+// UploadService maneja las subidas por partes: recibe los chunks, verifica el archivo armado y
+// encola el import. Una sesión vence ttl después de abierta; la de un import encolado se conserva
+// hasta que el job termina, porque el worker lee el archivo de los chunks.
+type UploadService struct {
+	store UploadStore
+	jobs  JobQueue
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewUploadService crea el service de subidas por partes.
+func NewUploadService(store UploadStore, jobs JobQueue, ttl time.Duration) *UploadService {
+	return &UploadService{store: store, jobs: jobs, ttl: ttl, now: time.Now}
+}
+
+// Create abre una sesión con el Format, Mode y DryRun de upload.
+func (service *UploadService) Create(ctx context.Context, upload Upload) (Upload, error) {
+	return service.store.CreateUpload(ctx, upload, service.ttl)
+}
+
+// Get devuelve una sesión y los chunks recibidos, para que el cliente sepa qué le falta mandar.
+func (service *UploadService) Get(ctx context.Context, id string) (Upload, error) {
+	upload, err := service.store.GetUpload(ctx, id)
+	if err != nil {
+		return Upload{}, err
+	}
+	if !upload.ExpiresAt.After(service.now()) {
+		return Upload{}, ErrorUploadNotFound
+	}
+	return upload, nil
+}
+
+// PutChunk guarda el chunk number. Los chunks pueden llegar en cualquier orden, y reenviar uno lo
+// reemplaza: reintentar un PUT que no se sabe si llegó es seguro.
+func (service *UploadService) PutChunk(ctx context.Context, id string, number int, data []byte) (Chunk, error) {
+	if number < 1 || number > maxChunks || len(data) == 0 || len(data) > maxChunkBytes {
+		return Chunk{}, ErrorInvalidChunk
+	}
+	if err := service.store.PutChunk(ctx, id, number, data, maxUploadBytes); err != nil {
+		return Chunk{}, err
+	}
+	return Chunk{Number: number, Size: len(data)}, nil
+}
+
+// Complete verifica que no falten chunks y que el SHA-256 del archivo armado sea checksum, y
+// encola el import. Completar de nuevo una sesión ya completada con el mismo checksum devuelve el
+// mismo job.
+func (service *UploadService) Complete(ctx context.Context, id, checksum string) (jobqueue.Job, error) {
+	upload, err := service.Get(ctx, id)
+	if err != nil {
+		return jobqueue.Job{}, err
+	}
+	if upload.CompletedAt != nil {
+		return service.completedJob(ctx, upload, checksum)
+	}
+	if missing := missingChunks(upload.Chunks); len(upload.Chunks) == 0 || len(missing) > 0 {
+		return jobqueue.Job{}, &IncompleteUploadError{Missing: missing}
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, service.reader(ctx, upload)); err != nil {
+		return jobqueue.Job{}, err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return jobqueue.Job{}, ErrorChecksumMismatch
+	}
+
+	if err := service.store.CompleteUpload(ctx, id, checksum); err != nil {
+		if errors.Is(err, ErrorUploadCompleted) {
+			if upload, err = service.store.GetUpload(ctx, id); err == nil {
+				return service.completedJob(ctx, upload, checksum)
+			}
+		}
+		return jobqueue.Job{}, err
+	}
+	job, err := service.jobs.Enqueue(ctx, Kind, Payload{UploadID: id, Format: upload.Format, Mode: upload.Mode, DryRun: upload.DryRun, Atomic: upload.Atomic}, 0)
+	if err != nil {
+		// Sin job la sesión vuelve a quedar abierta, así el cliente puede reintentar el complete.
+		if reopenErr := service.store.ReopenUpload(context.WithoutCancel(ctx), id); reopenErr != nil {
+			log.Printf("warn: import_upload_reopen_failed upload_id=%s err=%v", id, reopenErr)
+		}
+		return jobqueue.Job{}, err
+	}
+	if err := service.store.SetUploadJob(ctx, id, job.ID); err != nil {
+		return jobqueue.Job{}, err
+	}
+	return job, nil
+}
+
+// completedJob responde un complete repetido: el job de la sesión, si el checksum es el mismo.
+func (service *UploadService) completedJob(ctx context.Context, upload Upload, checksum string) (jobqueue.Job, error) {
+	if upload.SHA256 != checksum {
+		return jobqueue.Job{}, ErrorChecksumMismatch
+	}
+	if upload.JobID == "" {
+		return jobqueue.Job{}, ErrorUploadCompleted
+	}
+	return service.jobs.Get(ctx, upload.JobID)
+}
+
+// Open devuelve el archivo de una subida completa, leído de a un chunk. Lo usa el Processor: no
+// mira el vencimiento, porque la sesión de un job pendiente no se borra.
+func (service *UploadService) Open(ctx context.Context, id string) (io.Reader, error) {
+	upload, err := service.store.GetUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return service.reader(ctx, upload), nil
+}
+
+// PurgeExpired borra las sesiones vencidas y sus chunks y devuelve cuántas borró.
+func (service *UploadService) PurgeExpired(ctx context.Context) (int64, error) {
+	return service.store.PurgeExpiredUploads(ctx)
+}
+
+// reader arma el io.Reader del archivo de upload, que pide los chunks a la base a medida que se leen.
+func (service *UploadService) reader(ctx context.Context, upload Upload) io.Reader {
+	return &chunkReader{ctx: ctx, store: service.store, id: upload.ID, next: 1, count: len(upload.Chunks)}
+}
+
+// missingChunks devuelve los números entre 1 y el último chunk recibido que no llegaron. chunks
+// viene ordenado por número.
+func missingChunks(chunks []Chunk) []int {
+	var missing []int
+	expected := 1
+	for _, chunk := range chunks {
+		for ; expected < chunk.Number; expected++ {
+			missing = append(missing, expected)
+		}
+		expected = chunk.Number + 1
+	}
+	return missing
+}
+
+// chunkReader lee los chunks 1..count de una subida en orden, con uno solo en memoria a la vez.
+type chunkReader struct {
+	ctx     context.Context
+	store   UploadStore
+	id      string
+	next    int
+	count   int
+	current []byte
+}
+
+func (reader *chunkReader) Read(buffer []byte) (int, error) {
+	for len(reader.current) == 0 {
+		if reader.next > reader.count {
+			return 0, io.EOF
+		}
+		data, err := reader.store.ReadChunk(reader.ctx, reader.id, reader.next)
+		if err != nil {
+			return 0, err
+		}
+		reader.current, reader.next = data, reader.next+1
+	}
+	read := copy(buffer, reader.current)
+	reader.current = reader.current[read:]
+	return read, nil
+}
+
+// UploadSource abre el archivo de una subida completa. UploadService la implementa.
+type UploadSource interface {
+	Open(ctx context.Context, id string) (io.Reader, error)
+}
+
+// decodeUpload lee las filas del archivo de una subida. Un archivo que no se puede leer hace fallar
+// el job; en un CSV, una celda con el tipo equivocado es un error de esa fila (invalid, por índice).
+func decodeUpload(reader io.Reader, format string) ([]items.CreateItemInput, map[int]error, error) {
+	var rows []items.CreateItemInput
+	var invalid map[int]error
+	var err error
+	switch format {
+	case FormatCSV:
+		rows, invalid, err = decodeCSVUpload(reader)
+	default:
+		var body struct {
+			Items []items.CreateItemInput `json:"items"`
+		}
+		err = json.NewDecoder(reader).Decode(&body)
+		rows = body.Items
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode upload: %w", err)
+	}
+	if len(rows) > maxRows {
+		return nil, nil, fmt.Errorf("upload has %d rows, the limit is %d", len(rows), maxRows)
+	}
+	return rows, invalid, nil
+}
+
+// csvUploadFields son las columnas que se leen de un CSV subido; las demás se ignoran, así que el
+// CSV de GET /items/export se puede volver a importar. name y price son obligatorias.
+var csvUploadFields = []string{"name", "slug", "sku", "barcode", "description", "price", "stock"}
+
+// decodeCSVUpload lee un CSV con encabezado. Una celda vacía es un campo que no vino.
+func decodeCSVUpload(reader io.Reader) ([]items.CreateItemInput, map[int]error, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := map[string]int{}
+	for index, name := range header {
+		// Excel guarda los CSV en UTF-8 con BOM.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, repeated := columns[name]; !repeated {
+			columns[name] = index
+		}
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("csv header has no %s column", required)
+		}
+	}
+
+	var rows []items.CreateItemInput
+	invalid := map[int]error{}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, invalid, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		input, err := csvUploadRow(record, columns)
+		if err != nil {
+			invalid[len(rows)] = err
+		}
+		rows = append(rows, input)
+	}
+}
+
+// csvUploadRow arma el alta de una fila del CSV.
+func csvUploadRow(record []string, columns map[string]int) (items.CreateItemInput, error) {
+	var input items.CreateItemInput
+	for _, field := range csvUploadFields {
+		index, ok := columns[field]
+		if !ok || index >= len(record) || record[index] == "" {
+			continue
+		}
+		value := record[index]
+		switch field {
+		case "name":
+			input.Name = value
+		case "slug":
+			input.Slug = value
+		case "sku":
+			input.SKU = &value
+		case "barcode":
+			input.Barcode = &value
+		case "description":
+			input.Description = &value
+		case "price":
+			input.Price = value
+		case "stock":
+			stock, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return input, &items.ValidationError{Field: "stock", Message: "stock must be an integer"}
+			}
+			input.Stock = stock
+		}
+	}
+	return input, nil
+}
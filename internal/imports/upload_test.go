@@ -0,0 +1,331 @@
+package imports
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// fakeUploadStore guarda las sesiones en memoria, con las mismas reglas que Repository.
+type fakeUploadStore struct {
+	uploads map[string]*Upload
+	chunks  map[string]map[int][]byte
+	now     time.Time
+	err     error
+	reads   int
+	reopens int
+}
+
+func newFakeUploadStore(now time.Time) *fakeUploadStore {
+	return &fakeUploadStore{uploads: map[string]*Upload{}, chunks: map[string]map[int][]byte{}, now: now}
+}
+
+func (store *fakeUploadStore) CreateUpload(ctx context.Context, upload Upload, ttl time.Duration) (Upload, error) {
+	upload.ID = "upload-1"
+	upload.CreatedAt, upload.ExpiresAt = store.now, store.now.Add(ttl)
+	store.uploads[upload.ID] = &upload
+	store.chunks[upload.ID] = map[int][]byte{}
+	return upload, nil
+}
+
+func (store *fakeUploadStore) GetUpload(ctx context.Context, id string) (Upload, error) {
+	upload, ok := store.uploads[id]
+	if !ok {
+		return Upload{}, ErrorUploadNotFound
+	}
+	found := *upload
+	found.Chunks, found.Size = nil, 0
+	for _, number := range slices.Sorted(maps.Keys(store.chunks[id])) {
+		found.Chunks = append(found.Chunks, Chunk{Number: number, Size: len(store.chunks[id][number])})
+		found.Size += int64(len(store.chunks[id][number]))
+	}
+	return found, nil
+}
+
+func (store *fakeUploadStore) PutChunk(ctx context.Context, id string, number int, data []byte, maxBytes int64) error {
+	upload, ok := store.uploads[id]
+	if !ok || !upload.ExpiresAt.After(store.now) {
+		return ErrorUploadNotFound
+	}
+	if upload.CompletedAt != nil {
+		return ErrorUploadCompleted
+	}
+	used := int64(len(data))
+	for other, chunk := range store.chunks[id] {
+		if other != number {
+			used += int64(len(chunk))
+		}
+	}
+	if used > maxBytes {
+		return ErrorUploadTooLarge
+	}
+	store.chunks[id][number] = data
+	return nil
+}
+
+func (store *fakeUploadStore) ReadChunk(ctx context.Context, id string, number int) ([]byte, error) {
+	store.reads++
+	data, ok := store.chunks[id][number]
+	if !ok {
+		return nil, ErrorUploadNotFound
+	}
+	return data, nil
+}
+
+func (store *fakeUploadStore) CompleteUpload(ctx context.Context, id, checksum string) error {
+	upload := store.uploads[id]
+	if upload.CompletedAt != nil {
+		return ErrorUploadCompleted
+	}
+	upload.CompletedAt, upload.SHA256 = &store.now, checksum
+	return nil
+}
+
+func (store *fakeUploadStore) SetUploadJob(ctx context.Context, id, jobID string) error {
+	store.uploads[id].JobID = jobID
+	return nil
+}
+
+func (store *fakeUploadStore) ReopenUpload(ctx context.Context, id string) error {
+	store.reopens++
+	store.uploads[id].CompletedAt, store.uploads[id].SHA256 = nil, ""
+	return nil
+}
+
+func (store *fakeUploadStore) PurgeExpiredUploads(ctx context.Context) (int64, error) {
+	return 0, store.err
+}
+
+// fakeJobQueue encola jobs en memoria.
+type fakeJobQueue struct {
+	enqueued []Payload
+	err      error
+}
+
+func (queue *fakeJobQueue) Enqueue(ctx context.Context, kind string, payload any, total int) (jobqueue.Job, error) {
+	if queue.err != nil {
+		return jobqueue.Job{}, queue.err
+	}
+	queue.enqueued = append(queue.enqueued, payload.(Payload))
+	return jobqueue.Job{ID: "job-1", Kind: kind, Status: jobqueue.StatusQueued}, nil
+}
+
+func (queue *fakeJobQueue) Get(ctx context.Context, id string) (jobqueue.Job, error) {
+	return jobqueue.Job{ID: id, Kind: Kind, Status: jobqueue.StatusRunning}, nil
+}
+
+func checksumOf(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// uploadWith abre una sesión y le manda los chunks parts, en el orden de order.
+func uploadWith(t *testing.T, service *UploadService, parts []string, order ...int) Upload {
+	t.Helper()
+
+	upload, err := service.Create(context.Background(), Upload{Format: FormatJSON, Mode: ModeCreate})
+	require.NoError(t, err)
+	for _, index := range order {
+		_, err := service.PutChunk(context.Background(), upload.ID, index+1, []byte(parts[index]))
+		require.NoError(t, err)
+	}
+	return upload
+}
+
+func TestUploadService(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	content := `{"items":[{"name":"Teclado","price":"10.00"}]}`
+	parts := []string{content[:10], content[10:30], content[30:]}
+
+	newService := func() (*UploadService, *fakeUploadStore, *fakeJobQueue) {
+		store, queue := newFakeUploadStore(now), &fakeJobQueue{}
+		service := NewUploadService(store, queue, time.Hour)
+		service.now = func() time.Time { return now }
+		return service, store, queue
+	}
+
+	t.Run("chunks out of order and re-sent complete the upload", func(t *testing.T) {
+		service, store, queue := newService()
+		upload := uploadWith(t, service, []string{"garbage", parts[1], parts[2]}, 2, 0, 1)
+		_, err := service.PutChunk(context.Background(), upload.ID, 1, []byte(parts[0]))
+		require.NoError(t, err)
+
+		job, err := service.Complete(context.Background(), upload.ID, checksumOf(content))
+
+		require.NoError(t, err)
+		require.Equal(t, "job-1", job.ID)
+		require.Equal(t, []Payload{{UploadID: upload.ID, Format: FormatJSON, Mode: ModeCreate}}, queue.enqueued)
+		require.Equal(t, "job-1", store.uploads[upload.ID].JobID)
+		require.Equal(t, 3, store.reads, "the file is read one chunk at a time")
+	})
+
+	t.Run("completing again returns the same job", func(t *testing.T) {
+		service, _, queue := newService()
+		upload := uploadWith(t, service, parts, 0, 1, 2)
+		_, err := service.Complete(context.Background(), upload.ID, checksumOf(content))
+		require.NoError(t, err)
+
+		job, err := service.Complete(context.Background(), upload.ID, checksumOf(content))
+
+		require.NoError(t, err)
+		require.Equal(t, "job-1", job.ID)
+		require.Len(t, queue.enqueued, 1)
+
+		_, err = service.Complete(context.Background(), upload.ID, checksumOf("other"))
+		require.ErrorIs(t, err, ErrorChecksumMismatch)
+	})
+
+	t.Run("missing chunks", func(t *testing.T) {
+		service, _, queue := newService()
+		upload := uploadWith(t, service, parts, 2)
+
+		_, err := service.Complete(context.Background(), upload.ID, checksumOf(content))
+
+		var incomplete *IncompleteUploadError
+		require.ErrorAs(t, err, &incomplete)
+		require.Equal(t, []int{1, 2}, incomplete.Missing)
+		require.Equal(t, "missing chunks: 1, 2", err.Error())
+		require.Empty(t, queue.enqueued)
+
+		upload = uploadWith(t, service, parts)
+		_, err = service.Complete(context.Background(), upload.ID, checksumOf(content))
+		require.ErrorAs(t, err, &incomplete)
+		require.Equal(t, "upload has no chunks", err.Error())
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		service, store, queue := newService()
+		upload := uploadWith(t, service, parts, 0, 1, 2)
+
+		_, err := service.Complete(context.Background(), upload.ID, checksumOf(content+" "))
+
+		require.ErrorIs(t, err, ErrorChecksumMismatch)
+		require.Empty(t, queue.enqueued)
+		require.Nil(t, store.uploads[upload.ID].CompletedAt, "the client can still fix the chunks")
+	})
+
+	t.Run("a failed enqueue reopens the upload", func(t *testing.T) {
+		service, store, queue := newService()
+		queue.err = errors.New("connection refused")
+		upload := uploadWith(t, service, parts, 0, 1, 2)
+
+		_, err := service.Complete(context.Background(), upload.ID, checksumOf(content))
+
+		require.ErrorContains(t, err, "connection refused")
+		require.Equal(t, 1, store.reopens)
+		require.Nil(t, store.uploads[upload.ID].CompletedAt)
+	})
+
+	t.Run("chunks after completing", func(t *testing.T) {
+		service, _, _ := newService()
+		upload := uploadWith(t, service, parts, 0, 1, 2)
+		_, err := service.Complete(context.Background(), upload.ID, checksumOf(content))
+		require.NoError(t, err)
+
+		_, err = service.PutChunk(context.Background(), upload.ID, 4, []byte("more"))
+
+		require.ErrorIs(t, err, ErrorUploadCompleted)
+	})
+
+	t.Run("invalid chunks", func(t *testing.T) {
+		service, _, _ := newService()
+		upload := uploadWith(t, service, parts)
+
+		for _, number := range []int{0, maxChunks + 1} {
+			_, err := service.PutChunk(context.Background(), upload.ID, number, []byte("x"))
+			require.ErrorIs(t, err, ErrorInvalidChunk)
+		}
+		_, err := service.PutChunk(context.Background(), upload.ID, 1, nil)
+		require.ErrorIs(t, err, ErrorInvalidChunk)
+	})
+
+	t.Run("expired uploads are not found", func(t *testing.T) {
+		service, _, _ := newService()
+		upload := uploadWith(t, service, parts, 0)
+		service.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+		_, err := service.Get(context.Background(), upload.ID)
+		require.ErrorIs(t, err, ErrorUploadNotFound)
+		_, err = service.Complete(context.Background(), upload.ID, checksumOf(content))
+		require.ErrorIs(t, err, ErrorUploadNotFound)
+
+		reader, err := service.Open(context.Background(), upload.ID)
+		require.NoError(t, err, "the worker still reads the file of a pending job")
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, parts[0], string(data))
+	})
+}
+
+func TestDecodeUpload(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		rows, invalid, err := decodeUpload(strings.NewReader(`{"items":[{"name":"Teclado","price":"10.00","stock":3}]}`), FormatJSON)
+
+		require.NoError(t, err)
+		require.Empty(t, invalid)
+		require.Equal(t, []items.CreateItemInput{{Name: "Teclado", Price: "10.00", Stock: 3}}, rows)
+	})
+
+	t.Run("csv with a bom and extra columns", func(t *testing.T) {
+		sku := "KB-1"
+		csv := "\ufeffid,Name,sku,price,stock,created_at\n" +
+			"item-1,Teclado,KB-1,10.00,3,2026-01-01\n" +
+			"item-2,Mouse,,5.00,many,2026-01-01\n"
+
+		rows, invalid, err := decodeUpload(strings.NewReader(csv), FormatCSV)
+
+		require.NoError(t, err)
+		require.Equal(t, []items.CreateItemInput{
+			{Name: "Teclado", SKU: &sku, Price: "10.00", Stock: 3},
+			{Name: "Mouse", Price: "5.00"},
+		}, rows)
+		require.Equal(t, map[int]error{1: &items.ValidationError{Field: "stock", Message: "stock must be an integer"}}, invalid)
+	})
+
+	t.Run("csv without required columns", func(t *testing.T) {
+		_, _, err := decodeUpload(strings.NewReader("name,stock\nTeclado,3\n"), FormatCSV)
+
+		require.ErrorContains(t, err, "csv header has no price column")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, _, err := decodeUpload(strings.NewReader(`{"items":[`), FormatJSON)
+
+		require.ErrorContains(t, err, "decode upload")
+	})
+}
+
+func TestProcessor_ProcessUpload(t *testing.T) {
+	store := newFakeUploadStore(time.Now())
+	service := NewUploadService(store, &fakeJobQueue{}, time.Hour)
+	csv := "name,price,stock\nTeclado,10.00,1\nMouse,5.00,x\n\nMonitor,100.00,2\n"
+	upload := uploadWith(t, service, []string{csv[:20], csv[20:]}, 1, 0)
+	encoded, err := json.Marshal(Payload{UploadID: upload.ID, Format: FormatCSV})
+	require.NoError(t, err)
+	job := jobqueue.Job{ID: "job-1", Kind: Kind, Attempts: 1, Payload: encoded}
+	creator := &fakeCreator{}
+	jobStore := &fakeStore{}
+
+	result, err := NewProcessor(creator, WithUploadSource(service)).Process(context.Background(), job, jobqueue.NewReporter(jobStore, job))
+
+	require.NoError(t, err)
+	require.Equal(t, Summary{Total: 3, Created: 2, Failed: 1}, result)
+	require.Equal(t, []string{"Teclado", "Monitor"}, creator.created)
+	require.Equal(t, []jobqueue.RowError{{Row: 1, Field: "stock", Message: "stock must be an integer"}}, jobStore.rowErrors)
+
+	_, err = NewProcessor(creator).Process(context.Background(), job, jobqueue.NewReporter(jobStore, job))
+	require.ErrorContains(t, err, "import uploads are not configured")
+}
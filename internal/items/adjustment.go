@@ -0,0 +1,49 @@
+package items
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+const (
+	// priceAdjustmentSampleSize es cuántos items, con el precio antes y después, muestra un ajuste masivo.
+	priceAdjustmentSampleSize = 5
+	// maxUnconfirmedPriceAdjustment es el máximo de items que cambia un ajuste masivo sin ?confirm_over=.
+	// Más que eso suele ser un filtro olvidado.
+	maxUnconfirmedPriceAdjustment = 100
+)
+
+// adjustmentValuePattern es un decimal con signo opcional y hasta dos decimales ("5", "-2.50", "+10").
+var adjustmentValuePattern = regexp.MustCompile(`^[+-]?\d{1,8}(\.\d{1,2})?$`)
+
+// normalizeAdjustment valida el ajuste de un POST /items/price-adjustments. code es la moneda del
+// filtro, ya normalizada (vacía si no vino): un monto fijo solo tiene sentido en una moneda.
+func normalizeAdjustment(adjustment PriceAdjustment, code string) (PriceAdjustment, error) {
+	adjustment.Type = PriceAdjustmentType(strings.ToLower(strings.TrimSpace(string(adjustment.Type))))
+	adjustment.Value = strings.TrimSpace(adjustment.Value)
+	if adjustment.Type != AdjustmentPercent && adjustment.Type != AdjustmentFixed {
+		return PriceAdjustment{}, &ValidationError{Field: "adjustment.type", Message: "type must be percent or fixed"}
+	}
+
+	value, ok := new(big.Rat).SetString(adjustment.Value)
+	if !adjustmentValuePattern.MatchString(adjustment.Value) || !ok || value.Sign() == 0 {
+		return PriceAdjustment{}, &ValidationError{Field: "adjustment.value", Message: "value must be a non-zero decimal with up to 2 decimals"}
+	}
+
+	switch adjustment.Type {
+	case AdjustmentPercent:
+		// -100% o menos deja todos los precios en cero o negativos.
+		if value.Cmp(big.NewRat(-100, 1)) <= 0 {
+			return PriceAdjustment{}, &ValidationError{Field: "adjustment.value", Message: "value must be greater than -100"}
+		}
+	case AdjustmentFixed:
+		if code == "" {
+			return PriceAdjustment{}, &ValidationError{Field: "filter.currency", Message: "fixed adjustments require filter.currency"}
+		}
+		if err := amountPrecisionError("adjustment.value", strings.TrimLeft(adjustment.Value, "+-"), code); err != nil {
+			return PriceAdjustment{}, err
+		}
+	}
+	return adjustment, nil
+}
@@ -0,0 +1,49 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAdjustment(t *testing.T) {
+	t.Run("valid adjustments", func(t *testing.T) {
+		for _, test := range []struct {
+			adjustment PriceAdjustment
+			currency   string
+			want       PriceAdjustment
+		}{
+			{PriceAdjustment{Type: " Percent ", Value: "5"}, "", PriceAdjustment{Type: AdjustmentPercent, Value: "5"}},
+			{PriceAdjustment{Type: "percent", Value: "-99.99"}, "", PriceAdjustment{Type: AdjustmentPercent, Value: "-99.99"}},
+			{PriceAdjustment{Type: "fixed", Value: " +1.50 "}, "USD", PriceAdjustment{Type: AdjustmentFixed, Value: "+1.50"}},
+			{PriceAdjustment{Type: "fixed", Value: "-100"}, "JPY", PriceAdjustment{Type: AdjustmentFixed, Value: "-100"}},
+		} {
+			adjustment, err := normalizeAdjustment(test.adjustment, test.currency)
+
+			require.NoError(t, err, test.adjustment)
+			require.Equal(t, test.want, adjustment)
+		}
+	})
+
+	t.Run("invalid adjustments", func(t *testing.T) {
+		for _, test := range []struct {
+			adjustment PriceAdjustment
+			currency   string
+			field      string
+		}{
+			{PriceAdjustment{Type: "multiply", Value: "2"}, "", "adjustment.type"},
+			{PriceAdjustment{Type: "percent", Value: "0.00"}, "", "adjustment.value"},
+			{PriceAdjustment{Type: "percent", Value: "5.125"}, "", "adjustment.value"},
+			{PriceAdjustment{Type: "percent", Value: "abc"}, "", "adjustment.value"},
+			{PriceAdjustment{Type: "percent", Value: "-100"}, "", "adjustment.value"},
+			{PriceAdjustment{Type: "fixed", Value: "1.00"}, "", "filter.currency"},
+			{PriceAdjustment{Type: "fixed", Value: "1.50"}, "JPY", "adjustment.value"},
+		} {
+			_, err := normalizeAdjustment(test.adjustment, test.currency)
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError, test.adjustment)
+			require.Equal(t, test.field, validationError.Field, test.adjustment)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Límites de Item.Attributes: un mapa plano y chico, no un documento arbitrario.
+const (
+	maxAttributes              = 50
+	maxVariantAttributes       = 10
+	maxAttributeKeyLength      = 64
+	maxAttributeValueLength    = 512
+	attributeFilterQueryPrefix = "attr."
+)
+
+// attributesError valida los atributos de un alta o un PATCH y devuelve el error de campo
+// correspondiente, o nil si son válidos. Los valores solo pueden ser string, número o bool.
+func attributesError(attributes map[string]any) error {
+	if len(attributes) > maxAttributes {
+		return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attributes can have at most %d keys", maxAttributes)}
+	}
+	for key, value := range attributes {
+		if !isValidAttributeKey(key) {
+			return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attributes keys must have between 1 and %d characters", maxAttributeKeyLength)}
+		}
+		switch typed := value.(type) {
+		case string:
+			if utf8.RuneCountInString(typed) > maxAttributeValueLength {
+				return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attribute %q is longer than %d characters", key, maxAttributeValueLength)}
+			}
+		case float64, bool, json.Number:
+		default:
+			return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attribute %q must be a string, number or boolean", key)}
+		}
+	}
+	return nil
+}
+
+// variantAttributesError valida los atributos de una variante (talle, color): como los del item,
+// pero hasta maxVariantAttributes claves y solo valores string.
+func variantAttributesError(attributes map[string]string) error {
+	if len(attributes) > maxVariantAttributes {
+		return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attributes can have at most %d keys", maxVariantAttributes)}
+	}
+	for key, value := range attributes {
+		if !isValidAttributeKey(key) {
+			return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attributes keys must have between 1 and %d characters", maxAttributeKeyLength)}
+		}
+		if utf8.RuneCountInString(value) > maxAttributeValueLength {
+			return &ValidationError{Field: "attributes", Message: fmt.Sprintf("attribute %q is longer than %d characters", key, maxAttributeValueLength)}
+		}
+	}
+	return nil
+}
+
+// isValidAttributeKey indica si key sirve como clave de atributo (y de filtro attr.<key>).
+func isValidAttributeKey(key string) bool {
+	length := utf8.RuneCountInString(key)
+	return length >= 1 && length <= maxAttributeKeyLength
+}
+
+// attributesArg es el parámetro de la columna jsonb: nil (NULL) sin atributos y el objeto
+// serializado si no. No se le pasa el map a pgx para no depender de cómo codifica un map nil.
+func attributesArg(attributes map[string]any) any {
+	if attributes == nil {
+		return nil
+	}
+	return string(mustMarshal(attributes))
+}
+
+// attributeFilterDocuments arma los documentos JSON con los que se compara ?attr.<key>=<value>
+// (attributes @> documento). El query string no tiene tipos: "42" matchea tanto el número 42
+// como el string "42", y "true" / "false" también el booleano.
+func attributeFilterDocuments(key, value string) []string {
+	documents := []string{string(mustMarshal(map[string]string{key: value}))}
+	_, numberErr := strconv.ParseFloat(value, 64)
+	if value == "true" || value == "false" || (numberErr == nil && json.Valid([]byte(value))) {
+		documents = append(documents, string(mustMarshal(map[string]json.RawMessage{key: json.RawMessage(value)})))
+	}
+	return documents
+}
+
+// sortedAttributeKeys devuelve las claves del filtro ordenadas, para que el SQL sea estable.
+func sortedAttributeKeys(filter map[string]string) []string {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// parseAttributeFilter junta los query params attr.<key>=<value> del listado.
+// Devuelve nil si no hay ninguno.
+func parseAttributeFilter(query map[string][]string) map[string]string {
+	var filter map[string]string
+	for param, values := range query {
+		key, ok := strings.CutPrefix(param, attributeFilterQueryPrefix)
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if filter == nil {
+			filter = map[string]string{}
+		}
+		filter[key] = strings.TrimSpace(values[0])
+	}
+	return filter
+}
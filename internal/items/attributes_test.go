@@ -0,0 +1,56 @@
+package items
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributesError(t *testing.T) {
+	tooMany := map[string]any{}
+	for index := 0; index <= maxAttributes; index++ {
+		tooMany[strings.Repeat("k", index+1)] = true
+	}
+
+	tests := []struct {
+		name       string
+		attributes map[string]any
+		message    string
+	}{
+		{"nil", nil, ""},
+		{"flat values", map[string]any{"color": "red", "screen_size": 6.1, "waterproof": true, "weight": json.Number("180")}, ""},
+		{"too many keys", tooMany, "attributes can have at most 50 keys"},
+		{"empty key", map[string]any{"": "red"}, "attributes keys must have between 1 and 64 characters"},
+		{"long key", map[string]any{strings.Repeat("k", 65): "red"}, "attributes keys must have between 1 and 64 characters"},
+		{"long value", map[string]any{"color": strings.Repeat("r", 513)}, `attribute "color" is longer than 512 characters`},
+		{"nested object", map[string]any{"size": map[string]any{"w": 1}}, `attribute "size" must be a string, number or boolean`},
+		{"array", map[string]any{"tags": []any{"a"}}, `attribute "tags" must be a string, number or boolean`},
+		{"null value", map[string]any{"color": nil}, `attribute "color" must be a string, number or boolean`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := attributesError(tt.attributes)
+
+			if tt.message == "" {
+				require.NoError(t, err)
+				return
+			}
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, "attributes", validationError.Field)
+			require.Equal(t, tt.message, validationError.Message)
+		})
+	}
+}
+
+func TestAttributeFilterDocuments(t *testing.T) {
+	require.Equal(t, []string{`{"color":"red"}`}, attributeFilterDocuments("color", "red"))
+	require.Equal(t, []string{`{"size":"42"}`, `{"size":42}`}, attributeFilterDocuments("size", "42"))
+	require.Equal(t, []string{`{"size":"6.1"}`, `{"size":6.1}`}, attributeFilterDocuments("size", "6.1"))
+	require.Equal(t, []string{`{"waterproof":"true"}`, `{"waterproof":true}`}, attributeFilterDocuments("waterproof", "true"))
+	require.Equal(t, []string{`{"code":"0x10"}`}, attributeFilterDocuments("code", "0x10"))
+	require.Equal(t, []string{`{"code":"NaN"}`}, attributeFilterDocuments("code", "NaN"))
+}
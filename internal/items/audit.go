@@ -0,0 +1,92 @@
+package items
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// auditedFields son los campos del JSON del item que entran en la auditoría, en el orden en que se
+// listan los cambios: los que se guardan y se pueden editar. Quedan afuera los calculados
+// (effective_price, available, ...), los que cambian en cada escritura (updated_at, version) y las
+// refs, que se cambian por su propio endpoint.
+var auditedFields = []string{
+	"name", "slug", "sku", "barcode", "category", "brand", "description",
+	"price", "sale_price", "currency", "tax_rate_bps", "stock", "allow_backorder",
+	"status", "state", "attributes", "weight_grams", "width_mm", "height_mm", "depth_mm",
+	"min_order_qty", "expires_at", "reorder_point",
+}
+
+// jsonNull es el valor de un campo sin valor en un FieldChange.
+var jsonNull = json.RawMessage("null")
+
+// diffItems devuelve los campos auditados que cambiaron de before a after, comparando su JSON.
+// before nil es un alta: entran todos los campos que tienen valor.
+func diffItems(before *Item, after Item) ([]FieldChange, error) {
+	previous := map[string]json.RawMessage{}
+	if before != nil {
+		if err := fieldsOf(*before, &previous); err != nil {
+			return nil, err
+		}
+	}
+	current := map[string]json.RawMessage{}
+	if err := fieldsOf(after, &current); err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for _, field := range auditedFields {
+		old, updated := previous[field], current[field]
+		if bytes.Equal(old, updated) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Old: orNull(old), New: orNull(updated)})
+	}
+	return changes, nil
+}
+
+// fieldsOf deja en fields el JSON de cada campo de item.
+func fieldsOf(item Item, fields *map[string]json.RawMessage) error {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, fields)
+}
+
+// orNull devuelve value o null si el campo no venía (omitempty).
+func orNull(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return jsonNull
+	}
+	return value
+}
+
+// recordAudit registra la auditoría de un alta (before nil) o un cambio de item, con tx, la
+// transacción del cambio. Un cambio que no tocó ningún campo auditado (un PATCH con los mismos
+// valores) no se registra. El request id es el que dejó el middleware RequestID en el contexto.
+func recordAudit(ctx context.Context, tx RepositoryAPI, action AuditAction, before *Item, item Item) error {
+	changes, err := diffItems(before, item)
+	if err != nil {
+		return err
+	}
+	if action == AuditUpdated && len(changes) == 0 {
+		return nil
+	}
+	return tx.InsertAudit(ctx, []AuditEntry{{ItemID: item.ID, Action: action, Changes: changes, RequestID: middleware.GetReqID(ctx)}})
+}
+
+// recordDeletes registra la auditoría de las bajas de ids, sin campos cambiados.
+func recordDeletes(ctx context.Context, tx RepositoryAPI, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	requestID := middleware.GetReqID(ctx)
+	entries := make([]AuditEntry, len(ids))
+	for index, id := range ids {
+		entries[index] = AuditEntry{ItemID: id, Action: AuditDeleted, Changes: []FieldChange{}, RequestID: requestID}
+	}
+	return tx.InsertAudit(ctx, entries)
+}
@@ -0,0 +1,53 @@
+package items
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffItems(t *testing.T) {
+	description := "Desk lamp"
+	before := Item{ID: "id-1", Name: "Lamp", Slug: "lamp", Description: &description, Price: "10.00", EffectivePrice: "10.00", Currency: "USD", Stock: 4, Available: 4, Version: 1}
+
+	t.Run("update lists the changed fields in order", func(t *testing.T) {
+		after := before
+		after.Description = nil
+		after.Price, after.EffectivePrice = "12.50", "12.50"
+		after.Stock, after.Available = 2, 1
+		after.Version = 2
+
+		changes, err := diffItems(&before, after)
+
+		require.NoError(t, err)
+		require.Equal(t, []FieldChange{
+			{Field: "description", Old: json.RawMessage(`"Desk lamp"`), New: jsonNull},
+			{Field: "price", Old: json.RawMessage(`"10.00"`), New: json.RawMessage(`"12.50"`)},
+			{Field: "stock", Old: json.RawMessage(`4`), New: json.RawMessage(`2`)},
+		}, changes, "computed fields and version are not audited")
+	})
+
+	t.Run("same values are no change", func(t *testing.T) {
+		after := before
+		after.Version = 2
+
+		changes, err := diffItems(&before, after)
+
+		require.NoError(t, err)
+		require.Empty(t, changes)
+		require.NotNil(t, changes, "encodes as an empty array")
+	})
+
+	t.Run("create lists every field with a value", func(t *testing.T) {
+		changes, err := diffItems(nil, before)
+
+		require.NoError(t, err)
+		fields := make([]string, len(changes))
+		for index, change := range changes {
+			fields[index] = change.Field
+			require.Equal(t, jsonNull, change.Old)
+		}
+		require.Equal(t, []string{"name", "slug", "description", "price", "currency", "tax_rate_bps", "stock", "allow_backorder", "status", "state", "min_order_qty"}, fields)
+	})
+}
@@ -0,0 +1,39 @@
+package items
+
+// Largos de los códigos de barras que leen los scanners del depósito.
+const (
+	upcALength  = 12
+	ean13Length = 13
+)
+
+// barcodeError valida un EAN-13 o UPC-A y devuelve el error de campo correspondiente, o nil si es válido.
+func barcodeError(code string) error {
+	if len(code) != upcALength && len(code) != ean13Length {
+		return &ValidationError{Field: "barcode", Message: "barcode must have 12 (UPC-A) or 13 (EAN-13) digits"}
+	}
+	for _, character := range code {
+		if character < '0' || character > '9' {
+			return &ValidationError{Field: "barcode", Message: "barcode must have only digits"}
+		}
+	}
+	if !hasValidCheckDigit(code) {
+		return &ValidationError{Field: "barcode", Message: "barcode check digit is invalid"}
+	}
+	return nil
+}
+
+// hasValidCheckDigit verifica el dígito verificador GTIN, que es el mismo para EAN-13 y UPC-A:
+// de derecha a izquierda (sin contar el verificador) los dígitos pesan 3, 1, 3, 1...
+// y el verificador es lo que le falta a la suma para llegar a la siguiente decena.
+func hasValidCheckDigit(code string) bool {
+	last := len(code) - 1
+	sum := 0
+	for index := last - 1; index >= 0; index-- {
+		digit := int(code[index] - '0')
+		if (last-index)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[last]-'0')
+}
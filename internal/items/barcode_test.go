@@ -0,0 +1,40 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarcodeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		message string
+	}{
+		{"ean-13", "4006381333931", ""},
+		{"another ean-13", "5901234123457", ""},
+		{"upc-a", "036000291452", ""},
+		{"check digit zero", "0000000000000", ""},
+		{"wrong ean-13 check digit", "4006381333932", "barcode check digit is invalid"},
+		{"wrong upc-a check digit", "036000291453", "barcode check digit is invalid"},
+		{"too short", "12345678", "barcode must have 12 (UPC-A) or 13 (EAN-13) digits"},
+		{"too long", "40063813339310", "barcode must have 12 (UPC-A) or 13 (EAN-13) digits"},
+		{"letters", "40063813339X1", "barcode must have only digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := barcodeError(tt.code)
+
+			if tt.message == "" {
+				require.NoError(t, err)
+				return
+			}
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, "barcode", validationError.Field)
+			require.Equal(t, tt.message, validationError.Message)
+		})
+	}
+}
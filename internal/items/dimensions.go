@@ -0,0 +1,30 @@
+package items
+
+import "fmt"
+
+// Máximos de peso y medidas: 1.000 kg y 10 m. Un valor más grande es casi seguro un error de carga
+// (kilos cargados como gramos, centímetros como milímetros).
+const (
+	maxWeightGrams = 1_000_000
+	maxDimensionMM = 10_000
+)
+
+// dimensionsError valida peso y medidas de un alta o un PATCH (nil es "no vino" o "se limpia")
+// y devuelve el error del primer campo fuera de rango, o nil si son válidos.
+func dimensionsError(weightGrams, widthMM, heightMM, depthMM *int) error {
+	for _, field := range []struct {
+		name    string
+		value   *int
+		maximum int
+	}{
+		{"weight_grams", weightGrams, maxWeightGrams},
+		{"width_mm", widthMM, maxDimensionMM},
+		{"height_mm", heightMM, maxDimensionMM},
+		{"depth_mm", depthMM, maxDimensionMM},
+	} {
+		if field.value != nil && (*field.value < 0 || *field.value > field.maximum) {
+			return &ValidationError{Field: field.name, Message: fmt.Sprintf("%s must be between 0 and %d", field.name, field.maximum)}
+		}
+	}
+	return nil
+}
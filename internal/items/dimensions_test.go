@@ -0,0 +1,39 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDimensionsError(t *testing.T) {
+	tests := []struct {
+		name                  string
+		weight, width, height *int
+		depth                 *int
+		field, message        string
+	}{
+		{name: "all empty"},
+		{name: "within bounds", weight: integerPointer(1_000_000), width: integerPointer(0), height: integerPointer(10_000), depth: integerPointer(250)},
+		{name: "negative weight", weight: integerPointer(-1), field: "weight_grams", message: "weight_grams must be between 0 and 1000000"},
+		{name: "too heavy", weight: integerPointer(1_000_001), field: "weight_grams", message: "weight_grams must be between 0 and 1000000"},
+		{name: "too wide", width: integerPointer(10_001), field: "width_mm", message: "width_mm must be between 0 and 10000"},
+		{name: "negative height", height: integerPointer(-5), field: "height_mm", message: "height_mm must be between 0 and 10000"},
+		{name: "too deep", depth: integerPointer(20_000), field: "depth_mm", message: "depth_mm must be between 0 and 10000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dimensionsError(tt.weight, tt.width, tt.height, tt.depth)
+
+			if tt.field == "" {
+				require.NoError(t, err)
+				return
+			}
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, tt.field, validationError.Field)
+			require.Equal(t, tt.message, validationError.Message)
+		})
+	}
+}
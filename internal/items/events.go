@@ -0,0 +1,73 @@
+package items
+
+import "sync"
+
+// eventBuffer es cuántos eventos puede tener pendientes un suscriptor antes de que el hub lo suelte.
+const eventBuffer = 64
+
+// EventHub reparte en memoria las mutaciones del service entre los streams abiertos de GET /items/events.
+// Solo ve los cambios de este proceso: con varias instancias, cada una reparte los suyos.
+type EventHub struct {
+	mutex       sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewEventHub crea un hub sin suscriptores.
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: map[chan Event]struct{}{}}
+}
+
+// Subscribe devuelve un canal con los eventos que se publiquen desde ahora y la función para darse de
+// baja, que se puede llamar más de una vez. El canal se cierra si el suscriptor se atrasa más de
+// eventBuffer eventos o si el hub se cierra; en los dos casos el stream tiene que terminar.
+func (hub *EventHub) Subscribe() (<-chan Event, func()) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	events := make(chan Event, eventBuffer)
+	if hub.closed {
+		close(events)
+		return events, func() {}
+	}
+	hub.subscribers[events] = struct{}{}
+	return events, func() {
+		hub.mutex.Lock()
+		defer hub.mutex.Unlock()
+		hub.drop(events)
+	}
+}
+
+// Publish implementa EventPublisher. Nunca bloquea: un suscriptor con el buffer lleno se suelta
+// (y su cliente se reconecta) en lugar de frenar el request que hizo el cambio.
+func (hub *EventHub) Publish(event Event) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for events := range hub.subscribers {
+		select {
+		case events <- event:
+		default:
+			hub.drop(events)
+		}
+	}
+}
+
+// Close cierra todos los streams abiertos y los que se abran después. Se llama al apagar el servidor.
+func (hub *EventHub) Close() {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	hub.closed = true
+	for events := range hub.subscribers {
+		hub.drop(events)
+	}
+}
+
+// drop da de baja a un suscriptor y cierra su canal. Hay que tener el mutex tomado.
+func (hub *EventHub) drop(events chan Event) {
+	if _, ok := hub.subscribers[events]; ok {
+		delete(hub.subscribers, events)
+		close(events)
+	}
+}
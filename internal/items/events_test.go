@@ -0,0 +1,90 @@
+package items
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHub(t *testing.T) {
+	t.Run("every subscriber gets every event", func(t *testing.T) {
+		hub := NewEventHub()
+		first, unsubscribeFirst := hub.Subscribe()
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := hub.Subscribe()
+		defer unsubscribeSecond()
+
+		hub.Publish(Event{ID: "id-1", Operation: EventCreated})
+
+		require.Equal(t, Event{ID: "id-1", Operation: EventCreated}, <-first)
+		require.Equal(t, Event{ID: "id-1", Operation: EventCreated}, <-second)
+	})
+
+	t.Run("unsubscribe closes the channel and can be repeated", func(t *testing.T) {
+		hub := NewEventHub()
+		events, unsubscribe := hub.Subscribe()
+
+		unsubscribe()
+		unsubscribe()
+		hub.Publish(Event{ID: "id-1", Operation: EventCreated})
+
+		_, open := <-events
+		require.False(t, open)
+	})
+
+	t.Run("a subscriber that falls behind is dropped instead of blocking", func(t *testing.T) {
+		hub := NewEventHub()
+		slow, unsubscribeSlow := hub.Subscribe()
+		defer unsubscribeSlow()
+
+		for range eventBuffer + 1 {
+			hub.Publish(Event{ID: "id-1", Operation: EventUpdated})
+		}
+
+		received := 0
+		for range slow {
+			received++
+		}
+		require.Equal(t, eventBuffer, received)
+		// Los que se suscriben después siguen recibiendo.
+		fresh, unsubscribeFresh := hub.Subscribe()
+		defer unsubscribeFresh()
+		hub.Publish(Event{ID: "id-2", Operation: EventUpdated})
+		require.Equal(t, "id-2", (<-fresh).ID)
+	})
+
+	t.Run("close ends current and future subscriptions", func(t *testing.T) {
+		hub := NewEventHub()
+		current, unsubscribe := hub.Subscribe()
+
+		hub.Close()
+		unsubscribe()
+		late, _ := hub.Subscribe()
+
+		_, open := <-current
+		require.False(t, open)
+		_, open = <-late
+		require.False(t, open)
+	})
+}
+
+func TestHandler_EventsHeartbeat(t *testing.T) {
+	hub := NewEventHub()
+	handler := NewHandler(nil, WithEventSource(hub))
+	handler.heartbeat = 10 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(handler.Events))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": heartbeat\n", line)
+	hub.Close()
+}
@@ -0,0 +1,43 @@
+package items
+
+import (
+	"strings"
+	"time"
+)
+
+// dateLayout es el formato de las fechas sin hora (expires_at y los filtros de vencimiento).
+const dateLayout = "2006-01-02"
+
+// Límites del reporte GET /items/expiring.
+const (
+	defaultExpiringDays = 30
+	maxExpiringDays     = 365
+)
+
+// isValidDate indica si value es una fecha YYYY-MM-DD existente ("2025-02-30" no lo es).
+func isValidDate(value string) bool {
+	_, err := time.Parse(dateLayout, value)
+	return err == nil
+}
+
+// normalizeExpiresAt recorta y valida la fecha de vencimiento. En el alta (future) tiene que ser
+// posterior a today; en un PATCH alcanza con que sea una fecha.
+func normalizeExpiresAt(expiresAt *string, today time.Time, future bool) (*string, error) {
+	if expiresAt == nil {
+		return nil, nil
+	}
+	value := strings.TrimSpace(*expiresAt)
+	if !isValidDate(value) {
+		return nil, &ValidationError{Field: "expires_at", Message: "expires_at must be a date (YYYY-MM-DD)"}
+	}
+	if future && value <= today.Format(dateLayout) {
+		return nil, &ValidationError{Field: "expires_at", Message: "expires_at must be in the future"}
+	}
+	return &value, nil
+}
+
+// expiringBefore devuelve el límite (exclusive) del reporte de vencimientos: los items que vencen
+// entre hoy y dentro de days días, inclusive.
+func expiringBefore(today time.Time, days int) string {
+	return today.AddDate(0, 0, days+1).Format(dateLayout)
+}
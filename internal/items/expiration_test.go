@@ -0,0 +1,60 @@
+package items
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeExpiresAt(t *testing.T) {
+	today := time.Date(2025, 3, 10, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		value     string
+		future    bool
+		expected  string
+		errorText string
+	}{
+		{"future date", " 2025-03-11 ", true, "2025-03-11", ""},
+		{"today is not in the future", "2025-03-10", true, "", "expires_at must be in the future"},
+		{"past date on create", "2024-12-31", true, "", "expires_at must be in the future"},
+		{"past date on patch", "2024-12-31", false, "2024-12-31", ""},
+		{"not a date", "next week", false, "", "expires_at must be a date (YYYY-MM-DD)"},
+		{"nonexistent day", "2025-02-30", false, "", "expires_at must be a date (YYYY-MM-DD)"},
+		{"timestamp", "2025-04-01T00:00:00Z", false, "", "expires_at must be a date (YYYY-MM-DD)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := tt.value
+
+			normalized, err := normalizeExpiresAt(&value, today, tt.future)
+
+			if tt.errorText == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, *normalized)
+				return
+			}
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, "expires_at", validationError.Field)
+			require.Equal(t, tt.errorText, validationError.Message)
+		})
+	}
+
+	t.Run("nil is not a change", func(t *testing.T) {
+		normalized, err := normalizeExpiresAt(nil, today, true)
+
+		require.NoError(t, err)
+		require.Nil(t, normalized)
+	})
+}
+
+func TestExpiringBefore(t *testing.T) {
+	today := time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, "2026-01-15", expiringBefore(today, 30))
+	require.Equal(t, "2025-12-16", expiringBefore(today, 0))
+}
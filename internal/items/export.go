@@ -0,0 +1,293 @@
+package items
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+)
+
+// ExportPath es la ruta del export del catálogo. main la excluye del timeout global: un export
+// grande dura lo que tarde el cliente en leerlo.
+const ExportPath = "/items/export"
+
+// exportFlushRows es cada cuántos items el export vacía lo escrito hacia el cliente.
+const exportFlushRows = 500
+
+// exportFormat es un formato de GET /items/export.
+type exportFormat string
+
+// Formatos de GET /items/export.
+const (
+	exportNDJSON exportFormat = "ndjson"
+	exportCSV    exportFormat = "csv"
+	exportXLSX   exportFormat = "xlsx"
+)
+
+// defaultXLSXMaxRows es cuántos items puede tener un export xlsx si no se configura otro tope.
+const defaultXLSXMaxRows = 100_000
+
+// contentType devuelve el media type del formato.
+func (format exportFormat) contentType() string {
+	switch format {
+	case exportCSV:
+		return "text/csv; charset=utf-8"
+	case exportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// parseExportFormat lee ?format=; sin format el export sale en NDJSON.
+func parseExportFormat(value string) (exportFormat, error) {
+	switch format := exportFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "", exportNDJSON:
+		return exportNDJSON, nil
+	case exportCSV, exportXLSX:
+		return format, nil
+	default:
+		return "", &FilterError{Field: "format", Message: "format must be ndjson, csv or xlsx"}
+	}
+}
+
+// exportEncoder escribe los items del export en un formato.
+type exportEncoder interface {
+	// Begin escribe lo que va antes del primer item, como el encabezado del CSV.
+	Begin() error
+	Encode(item Item) error
+	// Flush pasa al writer lo que el encoder tenga en su propio buffer.
+	Flush() error
+	// End escribe lo que va después del último item, como el cierre del xlsx, y hace Flush.
+	End() error
+}
+
+// newExportEncoder devuelve el encoder del formato sobre writer, recortando cada item a fields.
+func newExportEncoder(format exportFormat, writer io.Writer, fields projection) exportEncoder {
+	switch format {
+	case exportCSV:
+		return &csvExportEncoder{writer: csv.NewWriter(writer), fields: fields}
+	case exportXLSX:
+		return newXLSXExportEncoder(writer, fields)
+	default:
+		return ndjsonExportEncoder{encoder: json.NewEncoder(writer), fields: fields}
+	}
+}
+
+// ndjsonExportEncoder escribe un item por línea, con la misma forma que GET /items/{id}.
+type ndjsonExportEncoder struct {
+	encoder *json.Encoder
+	fields  projection
+}
+
+func (encoder ndjsonExportEncoder) Begin() error { return nil }
+func (encoder ndjsonExportEncoder) Flush() error { return nil }
+func (encoder ndjsonExportEncoder) End() error   { return nil }
+
+func (encoder ndjsonExportEncoder) Encode(item Item) error {
+	projected, err := encoder.fields.apply(item)
+	if err != nil {
+		return err
+	}
+	return encoder.encoder.Encode(projected)
+}
+
+// CSVColumns son las columnas del CSV del catálogo, en el orden de CSVRecord. Las usan GET
+// /items/export y el export programado; cambiarlas rompe a quien lo procesa por posición, así que
+// solo se agregan al final.
+var CSVColumns = []string{"id", "name", "description", "price", "stock", "created_at", "updated_at"}
+
+// CSVRecord es la fila CSV del item. Una descripción null es una celda vacía, no el texto "null".
+func CSVRecord(item Item) []string {
+	description := ""
+	if item.Description != nil {
+		description = *item.Description
+	}
+	return []string{
+		item.ID,
+		item.Name,
+		description,
+		item.Price,
+		strconv.Itoa(item.Stock),
+		item.CreatedAt.UTC().Format(time.RFC3339Nano),
+		item.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// csvExportEncoder escribe el export como CSV: un encabezado y una fila por item. Sin ?fields= las
+// columnas son CSVColumns; con ?fields=, los campos pedidos en el orden de Item. encoding/csv se
+// encarga de las comillas de los nombres con comas, comillas o saltos de línea.
+type csvExportEncoder struct {
+	writer *csv.Writer
+	fields projection
+}
+
+func (encoder *csvExportEncoder) Begin() error {
+	if encoder.fields != nil {
+		return encoder.writer.Write(encoder.fields)
+	}
+	return encoder.writer.Write(CSVColumns)
+}
+
+func (encoder *csvExportEncoder) Encode(item Item) error {
+	if encoder.fields == nil {
+		return encoder.writer.Write(CSVRecord(item))
+	}
+	projected, err := encoder.fields.apply(item)
+	if err != nil {
+		return err
+	}
+	values := projected.(map[string]json.RawMessage)
+	record := make([]string, len(encoder.fields))
+	for index, name := range encoder.fields {
+		if record[index], err = csvCell(values[name]); err != nil {
+			return err
+		}
+	}
+	return encoder.writer.Write(record)
+}
+
+// csvCell pasa a una celda el valor JSON de un campo: un string sin comillas, null como celda vacía
+// (igual que la descripción en CSVRecord) y números, booleanos, objetos y listas como su JSON.
+func csvCell(value json.RawMessage) (string, error) {
+	switch {
+	case len(value) == 0 || string(value) == "null":
+		return "", nil
+	case value[0] == '"':
+		var text string
+		err := json.Unmarshal(value, &text)
+		return text, err
+	default:
+		return string(value), nil
+	}
+}
+
+func (encoder *csvExportEncoder) Flush() error {
+	encoder.writer.Flush()
+	return encoder.writer.Error()
+}
+
+func (encoder *csvExportEncoder) End() error {
+	return encoder.Flush()
+}
+
+// Export maneja GET /items/export?format=ndjson|csv|xlsx: todos los items que cumplen los mismos
+// filtros que GET /items (sin paginación), en su mismo orden, un objeto JSON por línea, una fila de
+// CSV o una fila de planilla por item. Los items se escriben a medida que salen del cursor de la DB y
+// se vacían hacia el cliente cada exportFlushRows, así que la memoria no depende del tamaño del
+// catálogo. Si algo falla después de mandar el primer item, la respuesta se corta sin el cierre del
+// chunked: el cliente lo ve como una descarga incompleta y no como un archivo más corto.
+// El xlsx tiene un tope de filas (WithXLSXMaxRows): un filtro que lo supera responde 400 antes de
+// empezar, y si el catálogo crece durante el export la descarga se corta.
+func (handler *Handler) Export(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, listFilterParams, []string{"format", "fields"}) {
+		return
+	}
+	format, err := parseExportFormat(request.URL.Query().Get("format"))
+	if err != nil {
+		failInvalidFilter(writer, request, err)
+		return
+	}
+	filter, ok := parseListQuery(writer, request)
+	if !ok {
+		return
+	}
+	filter = withDefaultStatus(filter)
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+
+	if format == exportXLSX && !handler.checkXLSXSize(writer, request, filter) {
+		return
+	}
+
+	controller := http.NewResponseController(writer)
+	encoder := newExportEncoder(format, writer, fields)
+	started := false
+	written := 0
+	err = handler.service.Export(request.Context(), filter, func(item Item) error {
+		if format == exportXLSX && written >= handler.xlsxMaxRows {
+			return fmt.Errorf("xlsx export passed %d rows", handler.xlsxMaxRows)
+		}
+		if !started {
+			started = true
+			if err := handler.startExport(writer, format, encoder); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushRows == 0 {
+			return flushExport(encoder, controller)
+		}
+		return nil
+	})
+	if err == nil && !started {
+		// Un filtro sin resultados es un archivo vacío (en CSV, solo el encabezado), no un error.
+		started = true
+		err = handler.startExport(writer, format, encoder)
+	}
+	if err == nil {
+		err = encoder.End()
+	}
+	switch {
+	case err != nil && !started:
+		failList(writer, request, err)
+	case err != nil:
+		log.Printf("warn: export_aborted rows=%d request_id=%s err=%v", written, httpx.RequestIDFrom(request), err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// checkXLSXSize cuenta los items del filtro y, si son más que el tope del xlsx, responde 400
+// export_too_large. Devuelve false si ya respondió.
+func (handler *Handler) checkXLSXSize(writer http.ResponseWriter, request *http.Request, filter ListFilter) bool {
+	count, err := handler.service.Count(request.Context(), filter)
+	if err != nil {
+		failList(writer, request, err)
+		return false
+	}
+	if count.Total > handler.xlsxMaxRows {
+		httpx.Fail(writer, request, http.StatusBadRequest, "export_too_large",
+			fmt.Sprintf("xlsx exports are limited to %d items and this filter matches %d; narrow the filter or use format=csv or format=ndjson", handler.xlsxMaxRows, count.Total))
+		return false
+	}
+	return true
+}
+
+// startExport manda los headers del export (el media type del formato y un nombre de archivo con la
+// fecha) y lo que el formato escribe antes del primer item.
+func (handler *Handler) startExport(writer http.ResponseWriter, format exportFormat, encoder exportEncoder) error {
+	header := writer.Header()
+	header.Set("Content-Type", format.contentType())
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="items-%s.%s"`, handler.now().UTC().Format("2006-01-02"), format))
+	// nginx bufferea las respuestas por defecto; sin esto el export llegaría de a tandas grandes.
+	header.Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+	return encoder.Begin()
+}
+
+// flushExport vacía lo escrito hacia el cliente: primero el buffer del encoder y después el de la
+// respuesta. Un writer que no sabe hacer flush no es un error: la respuesta sale igual, solo que con
+// el buffer del servidor.
+func flushExport(encoder exportEncoder, controller *http.ResponseController) error {
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
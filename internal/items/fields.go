@@ -0,0 +1,103 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// itemFields son los nombres JSON de Item, en el orden del struct. Se derivan de los tags
+// para que un campo nuevo quede disponible en ?fields= sin tocar esta lista.
+var itemFields = jsonFieldNames(reflect.TypeOf(Item{}))
+
+var errorUnknownField = errors.New("unknown field")
+
+// projection es el subconjunto de campos de Item pedido con ?fields=. nil significa el item completo.
+type projection []string
+
+// parseFields lee ?fields=id,name,price. id siempre se incluye aunque no se pida.
+// Un nombre que no es campo de Item es un error que lo menciona.
+func parseFields(request *http.Request) (projection, error) {
+	names := splitList(request.URL.Query().Get("fields"))
+	if names == nil {
+		return nil, nil
+	}
+
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !slices.Contains(itemFields, name) {
+			return nil, fmt.Errorf("%w %q; allowed fields: %s", errorUnknownField, name, strings.Join(itemFields, ", "))
+		}
+		requested[name] = true
+	}
+	requested["id"] = true
+
+	// Recorremos itemFields para descartar repetidos y dejar un orden estable.
+	fields := make(projection, 0, len(requested))
+	for _, name := range itemFields {
+		if requested[name] {
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// apply devuelve el item recortado a los campos de la proyección, listo para serializar.
+// Un campo pedido que el item omite (description sin valor, similarity sin fuzzy) sale como null.
+func (fields projection) apply(item Item) (any, error) {
+	if fields == nil {
+		return item, nil
+	}
+
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var full map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, name := range fields {
+		value, ok := full[name]
+		if !ok {
+			value = json.RawMessage("null")
+		}
+		projected[name] = value
+	}
+	return projected, nil
+}
+
+// applyAll aplica la proyección a cada item del listado.
+func (fields projection) applyAll(items []Item) (any, error) {
+	if fields == nil {
+		return items, nil
+	}
+	projected := make([]any, 0, len(items))
+	for _, item := range items {
+		value, err := fields.apply(item)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, value)
+	}
+	return projected, nil
+}
+
+// jsonFieldNames devuelve los nombres de los tags json de un struct, salteando los "-".
+func jsonFieldNames(structType reflect.Type) []string {
+	names := make([]string, 0, structType.NumField())
+	for index := 0; index < structType.NumField(); index++ {
+		name, _, _ := strings.Cut(structType.Field(index).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,61 @@
+package items
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		want    projection
+		wantErr bool
+	}{
+		{"absent", "/items", nil, false},
+		{"empty", "/items?fields=", nil, false},
+		{"id is always included", "/items?fields=price,name", projection{"id", "name", "price"}, false},
+		{"duplicates collapse", "/items?fields=name,name,id", projection{"id", "name"}, false},
+		{"unknown", "/items?fields=name,colour", nil, true},
+		{"empty entry", "/items?fields=name,,price", nil, true},
+		{"go field name", "/items?fields=Name", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := parseFields(httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, errorUnknownField)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, fields)
+		})
+	}
+}
+
+func TestProjection_Apply(t *testing.T) {
+	item := Item{ID: "id-1", Name: "Phone", Price: "10.00", Stock: 3, CreatedAt: time.Now()}
+
+	t.Run("nil keeps the full item", func(t *testing.T) {
+		value, err := projection(nil).apply(item)
+
+		require.NoError(t, err)
+		require.Equal(t, item, value)
+	})
+
+	t.Run("keeps only requested fields", func(t *testing.T) {
+		value, err := projection{"id", "stock", "similarity"}.apply(item)
+
+		require.NoError(t, err)
+		raw, err := json.Marshal(value)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id": "id-1", "stock": 3, "similarity": null}`, string(raw))
+	})
+}
@@ -0,0 +1,154 @@
+package items
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Los filtros de GET /items. Cada endpoint que filtra el catálogo como el listado los lee con
+// parseListQuery, así un parámetro nuevo queda disponible en todos a la vez.
+
+// listFilterParams son los parámetros que lee parseListFilter. Un filtro nuevo se agrega también
+// acá: los endpoints del listado responden 400 a los parámetros que no conocen.
+var listFilterParams = []string{
+	"query", "match", "search_fields", "min_price", "max_price", "sku", "category_id", "brand_id", "status", "state",
+	"currency", "sort", "name_eq", "case_sensitive", "fuzzy", "search_translations", "highlight", "use_effective_price",
+	"in_stock", "exclude_expired", "expiring_before", "stock_gte", "stock_lte", "max_weight", "min_order_qty_lte",
+	attributeFilterQueryPrefix + "*",
+}
+
+// parseListQuery parsea y valida los filtros del listado. Lo comparten List, Count y Export para
+// que los tres endpoints acepten exactamente los mismos parámetros.
+// Si algo es inválido ya respondió 400 y devuelve false.
+func parseListQuery(writer http.ResponseWriter, request *http.Request) (ListFilter, bool) {
+	filter, err := parseListFilter(request)
+	if err != nil {
+		failInvalidFilter(writer, request, err)
+		return ListFilter{}, false
+	}
+	if err := validateSort(filter.Sort); err != nil {
+		failInvalidSort(writer, request)
+		return ListFilter{}, false
+	}
+	return filter, true
+}
+
+// parseListFilter lee los filtros de búsqueda del query string.
+// Solo parsea; la validación de valores (por ejemplo el modo de match) es del service.
+// Devuelve un *FilterError si un parámetro numérico o booleano no se puede interpretar.
+func parseListFilter(request *http.Request) (ListFilter, error) {
+	query := request.URL.Query()
+
+	filter := ListFilter{
+		Query: strings.TrimSpace(query.Get("query")),
+		Match: MatchMode(strings.ToLower(strings.TrimSpace(query.Get("match")))),
+		// search_fields y no fields: ?fields= queda reservado para proyectar la respuesta.
+		SearchFields: splitList(query.Get("search_fields")),
+		MinPrice:     strings.TrimSpace(query.Get("min_price")),
+		MaxPrice:     strings.TrimSpace(query.Get("max_price")),
+		SKU:          normalizeSKU(query.Get("sku")),
+		CategoryID:   strings.TrimSpace(query.Get("category_id")),
+		BrandID:      strings.TrimSpace(query.Get("brand_id")),
+		Status:       ItemStatus(strings.ToLower(strings.TrimSpace(query.Get("status")))),
+		State:        ItemState(strings.ToLower(strings.TrimSpace(query.Get("state")))),
+		Currency:     strings.ToUpper(strings.TrimSpace(query.Get("currency"))),
+		Attributes:   parseAttributeFilter(query),
+		Sort:         parseSort(query.Get("sort")),
+	}
+	if filter.Query != "" && filter.Match == "" {
+		filter.Match = MatchContains
+	}
+
+	// name_eq no se recorta: los espacios son parte del nombre exacto.
+	filter.NameEq = query.Get("name_eq")
+	if value := strings.TrimSpace(query.Get("case_sensitive")); value != "" {
+		caseSensitive, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "case_sensitive", Message: "case_sensitive must be true or false"}
+		}
+		filter.NameEqIgnoreCase = !caseSensitive
+	}
+	if value := strings.TrimSpace(query.Get("fuzzy")); value != "" {
+		fuzzy, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "fuzzy", Message: "fuzzy must be true or false"}
+		}
+		filter.Fuzzy = fuzzy
+	}
+	if value := strings.TrimSpace(query.Get("search_translations")); value != "" {
+		searchTranslations, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "search_translations", Message: "search_translations must be true or false"}
+		}
+		filter.SearchTranslations = searchTranslations
+	}
+	if value := strings.TrimSpace(query.Get("highlight")); value != "" {
+		highlight, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "highlight", Message: "highlight must be true or false"}
+		}
+		filter.Highlight = highlight
+	}
+	if value := strings.TrimSpace(query.Get("use_effective_price")); value != "" {
+		useEffectivePrice, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "use_effective_price", Message: "use_effective_price must be true or false"}
+		}
+		filter.UseEffectivePrice = useEffectivePrice
+	}
+	if value := strings.TrimSpace(query.Get("in_stock")); value != "" {
+		inStock, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "in_stock", Message: "in_stock must be true or false"}
+		}
+		filter.InStock = &inStock
+	}
+	if value := strings.TrimSpace(query.Get("exclude_expired")); value != "" {
+		excludeExpired, err := strconv.ParseBool(value)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "exclude_expired", Message: "exclude_expired must be true or false"}
+		}
+		filter.ExcludeExpired = &excludeExpired
+	}
+	filter.ExpiringBefore = strings.TrimSpace(query.Get("expiring_before"))
+	for _, param := range []struct {
+		name   string
+		target **int
+	}{
+		{"stock_gte", &filter.StockGTE},
+		{"stock_lte", &filter.StockLTE},
+		{"max_weight", &filter.MaxWeight},
+		{"min_order_qty_lte", &filter.MinOrderQtyLTE},
+	} {
+		value := strings.TrimSpace(query.Get(param.name))
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return ListFilter{}, &FilterError{Field: param.name, Message: param.name + " must be a non-negative integer"}
+		}
+		*param.target = &number
+	}
+	return filter, nil
+}
+
+// withDefaultStatus completa el estado que GET /items, GET /items/count y GET /items/export usan si
+// el cliente no manda ?status=: solo los items a la venta. Un item vencido tampoco está a la venta,
+// así que también se excluyen los vencidos salvo que el cliente mande ?exclude_expired=. Sin
+// ?state= tampoco entran los borradores.
+// La papelera no filtra por estado.
+func withDefaultStatus(filter ListFilter) ListFilter {
+	if filter.State == "" {
+		filter.State = StatePublished
+	}
+	if filter.Status == "" {
+		filter.Status = StatusActive
+		if filter.ExcludeExpired == nil {
+			excludeExpired := true
+			filter.ExcludeExpired = &excludeExpired
+		}
+	}
+	return filter
+}
@@ -0,0 +1,107 @@
+package items
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListFilter(t *testing.T) {
+	t.Run("full filter surface", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/items?query=+phone+&search_fields=name,description&min_price=10&max_price=99.90"+
+			"&use_effective_price=true&in_stock=true&stock_gte=1&stock_lte=50&sku=ab-1&category_id=cat-1&brand_id=brand-1"+
+			"&status=INACTIVE&state=draft&currency=eur&attr.color=red&max_weight=500&min_order_qty_lte=3"+
+			"&expiring_before=2025-01-01&exclude_expired=false&name_eq=Phone+X&case_sensitive=false&sort=-price,name", nil)
+
+		filter, err := parseListFilter(request)
+
+		require.NoError(t, err)
+		inStock, excludeExpired := true, false
+		stockGTE, stockLTE, maxWeight, minOrderQty := 1, 50, 500, 3
+		require.Equal(t, ListFilter{
+			Query:             "phone",
+			Match:             MatchContains,
+			SearchFields:      []string{"name", "description"},
+			MinPrice:          "10",
+			MaxPrice:          "99.90",
+			UseEffectivePrice: true,
+			InStock:           &inStock,
+			StockGTE:          &stockGTE,
+			StockLTE:          &stockLTE,
+			SKU:               "AB-1",
+			CategoryID:        "cat-1",
+			BrandID:           "brand-1",
+			Status:            StatusInactive,
+			State:             StateDraft,
+			Currency:          "EUR",
+			Attributes:        map[string]string{"color": "red"},
+			MaxWeight:         &maxWeight,
+			MinOrderQtyLTE:    &minOrderQty,
+			ExpiringBefore:    "2025-01-01",
+			ExcludeExpired:    &excludeExpired,
+			NameEq:            "Phone X",
+			NameEqIgnoreCase:  true,
+			Sort:              []SortKey{"-price", "name"},
+		}, filter)
+	})
+
+	t.Run("unparseable values name the parameter", func(t *testing.T) {
+		for target, field := range map[string]string{
+			"/items?in_stock=maybe":      "in_stock",
+			"/items?fuzzy=1x":            "fuzzy",
+			"/items?stock_gte=-1":        "stock_gte",
+			"/items?max_weight=heavy":    "max_weight",
+			"/items?case_sensitive=nope": "case_sensitive",
+		} {
+			_, err := parseListFilter(httptest.NewRequest(http.MethodGet, target, nil))
+
+			var filterError *FilterError
+			require.ErrorAs(t, err, &filterError, target)
+			require.Equal(t, field, filterError.Field)
+		}
+	})
+}
+
+func TestParseListQuery(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		filter, ok := parseListQuery(rec, httptest.NewRequest(http.MethodGet, "/items?category_id=cat-1&sort=stock", nil))
+
+		require.True(t, ok)
+		require.Equal(t, "cat-1", filter.CategoryID)
+		require.Equal(t, http.StatusOK, rec.Code, "nothing written")
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		_, ok := parseListQuery(rec, httptest.NewRequest(http.MethodGet, "/items?in_stock=maybe", nil))
+
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"invalid_filter"`)
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		_, ok := parseListQuery(rec, httptest.NewRequest(http.MethodGet, "/items?sort=price,price", nil))
+
+		require.False(t, ok)
+		require.Contains(t, rec.Body.String(), `"invalid_sort"`)
+	})
+}
+
+func TestWithDefaultStatus(t *testing.T) {
+	filter := withDefaultStatus(ListFilter{})
+	require.Equal(t, StatusActive, filter.Status)
+	require.Equal(t, StatePublished, filter.State)
+	require.True(t, *filter.ExcludeExpired)
+
+	filter = withDefaultStatus(ListFilter{Status: StatusInactive})
+	require.Equal(t, StatusInactive, filter.Status)
+	require.Nil(t, filter.ExcludeExpired, "an explicit status keeps expired items")
+}
@@ -1,14 +1,26 @@
 package items
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/locale"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -17,210 +29,2449 @@ import (
 // Permite testear handlers con stubs sin tocar DB.
 type ServiceAPI interface {
 	Create(ctx context.Context, in CreateItemInput) (Item, error)
-	List(ctx context.Context, page, limit int, query string) ([]Item, int, error)
+	List(ctx context.Context, page, limit int, filter ListFilter) (ListPage, error)
+	ListAfter(ctx context.Context, after CreatedAtID, limit int, filter ListFilter) (ListPage, error)
+	Count(ctx context.Context, filter ListFilter) (ItemCount, error)
+	Export(ctx context.Context, filter ListFilter, fn func(Item) error) error
+	CollectionVersion(ctx context.Context) (CollectionVersion, error)
 	Get(ctx context.Context, id string) (Item, error)
+	GetBySlug(ctx context.Context, slug string) (Item, error)
+	GetBySKU(ctx context.Context, sku string) (Item, error)
+	GetByBarcode(ctx context.Context, barcode string) (Item, error)
+	Related(ctx context.Context, id string, limit int) ([]Item, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error)
+	DidYouMean(ctx context.Context, query string) (string, error)
 	Update(ctx context.Context, id string, in UpdateItemInput) (Item, error)
-	Delete(ctx context.Context, id string) error
+	UpdateMany(ctx context.Context, entries []BulkUpdateEntry) ([]BulkUpdateResult, error)
+	UpdateEach(ctx context.Context, entries []BulkUpdateEntry) ([]BulkUpdateResult, error)
+	Replace(ctx context.Context, id string, in ReplaceItemInput) (Item, error)
+	Duplicate(ctx context.Context, id string, input DuplicateItemInput) (Item, error)
+	Delete(ctx context.Context, id string, ifVersion *int) (Item, error)
+	DeleteMany(ctx context.Context, ids []string) (BulkDeleteResult, error)
+	Purge(ctx context.Context, id string) error
+	Reserve(ctx context.Context, id string, in ReserveInput) (Reservation, error)
+	Release(ctx context.Context, id, reservationID string) error
+	AdjustStock(ctx context.Context, id string, in StockAdjustmentInput) (Item, error)
+	StockMovements(ctx context.Context, id string, page, limit int) (StockMovementPage, error)
+	RestockNeeded(ctx context.Context, page, limit int) (ListPage, error)
+	Changes(ctx context.Context, after UpdatedAtID, limit int) (ChangesPage, error)
+	AdjustPrices(ctx context.Context, input PriceAdjustmentInput) (PriceAdjustmentResult, error)
+	PriceHistory(ctx context.Context, id string, filter PriceHistoryFilter, page, limit int) (PriceHistoryPage, error)
+	Audit(ctx context.Context, id string, page, limit int) (AuditPage, error)
+	Variants(ctx context.Context, itemID string) ([]Variant, error)
+	CreateVariant(ctx context.Context, itemID string, in CreateVariantInput) (Variant, error)
+	UpdateVariant(ctx context.Context, itemID, variantID string, in UpdateVariantInput) (Variant, error)
+	DeleteVariant(ctx context.Context, itemID, variantID string) error
+	PriceSchedules(ctx context.Context, itemID string) ([]PriceSchedule, error)
+	CreatePriceSchedule(ctx context.Context, itemID string, in CreatePriceScheduleInput) (PriceSchedule, error)
+	DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error
+	GetByExternalRef(ctx context.Context, system, externalID string) (Item, error)
+	AddExternalRef(ctx context.Context, itemID string, in ExternalRefInput) (ExternalRef, bool, error)
+	RemoveExternalRef(ctx context.Context, itemID, system, externalID string) error
+	Locales() []string
+	Translations(ctx context.Context, itemID string) ([]Translation, error)
+	PutTranslation(ctx context.Context, itemID, tag string, in TranslationInput) (Translation, error)
+	Localize(ctx context.Context, tag string, items []Item) ([]Item, error)
+	Publish(ctx context.Context, id string) (Item, error)
+	Unpublish(ctx context.Context, id string) (Item, error)
+	ApplyJSONPatch(ctx context.Context, id string, operations []PatchOperation, ifVersion *int) (Item, error)
 }
 
 // Handler HTTP para items.
 // Solo traduce HTTP <-> dominio (service).
 type Handler struct {
-	service ServiceAPI
+	service          ServiceAPI
+	strictPagination bool
+	defaultLimit     int
+	maxLimit         int
+	now              func() time.Time
+	requireIfMatch   bool
+	// events es de donde lee GET /items/events; nil si el servidor no tiene stream de eventos.
+	events EventSource
+	// heartbeat es cada cuánto el stream de eventos manda un comentario para que los proxies no corten
+	// la conexión ociosa.
+	heartbeat time.Duration
+	// xlsxMaxRows es cuántos items puede tener un export en xlsx.
+	xlsxMaxRows int
+	// allowUnknownParams desactiva el 400 unknown_parameter de los endpoints de lectura.
+	allowUnknownParams bool
+}
+
+// EventSource es de donde el handler lee las mutaciones para GET /items/events. Lo implementa EventHub.
+type EventSource interface {
+	Subscribe() (<-chan Event, func())
+}
+
+// eventsHeartbeat es cada cuánto manda un heartbeat el stream de eventos.
+const eventsHeartbeat = 15 * time.Second
+
+// HandlerOption configura comportamiento opcional del handler.
+type HandlerOption func(*Handler)
+
+// WithStrictPagination hace que un limit mayor al máximo devuelva 400 en lugar de recortarse.
+func WithStrictPagination(strict bool) HandlerOption {
+	return func(handler *Handler) {
+		handler.strictPagination = strict
+	}
+}
+
+// WithPageSizes cambia el limit por defecto y el máximo del listado.
+// Config ya valida que ambos sean positivos y que defaultLimit no supere a maxLimit.
+func WithPageSizes(defaultLimit, maxLimit int) HandlerOption {
+	return func(handler *Handler) {
+		handler.defaultLimit = defaultLimit
+		handler.maxLimit = maxLimit
+	}
+}
+
+// WithRequireIfMatch hace que PATCH y DELETE de un item sin If-Match respondan 428.
+// Sin esta opción, un request sin If-Match pisa lo que haya (last-write-wins).
+func WithRequireIfMatch(required bool) HandlerOption {
+	return func(handler *Handler) {
+		handler.requireIfMatch = required
+	}
+}
+
+// WithEventSource habilita GET /items/events, que transmite lo que publique source.
+func WithEventSource(source EventSource) HandlerOption {
+	return func(handler *Handler) {
+		handler.events = source
+	}
+}
+
+// WithXLSXMaxRows cambia cuántos items puede tener GET /items/export?format=xlsx. Los exports más
+// grandes se piden en CSV o NDJSON, que no tienen tope.
+func WithXLSXMaxRows(rows int) HandlerOption {
+	return func(handler *Handler) {
+		handler.xlsxMaxRows = rows
+	}
+}
+
+// WithAllowUnknownQueryParams hace que los endpoints de lectura ignoren los parámetros que no
+// conocen en lugar de responder 400 unknown_parameter. Es para los proxies que agregan los suyos.
+func WithAllowUnknownQueryParams(allow bool) HandlerOption {
+	return func(handler *Handler) {
+		handler.allowUnknownParams = allow
+	}
+}
+
+// Parámetros de query que aceptan los endpoints de lectura, además de los de cada uno.
+var (
+	paginationParams = []string{"page", "limit", "cursor"}
+	// listViewParams eligen cómo se muestra cada item: sus campos y su idioma.
+	listViewParams = []string{"fields", "locale"}
+)
+
+// knownParams responde 400 unknown_parameter si el request trae parámetros de query que no están
+// en ninguna de las listas de allowed, salvo con WithAllowUnknownQueryParams. Devuelve false si respondió.
+func (handler *Handler) knownParams(writer http.ResponseWriter, request *http.Request, allowed ...[]string) bool {
+	if handler.allowUnknownParams {
+		return true
+	}
+	return httpx.RejectUnknownQueryParams(writer, request, slices.Concat(allowed...)...)
 }
 
 // NewHandler crea un handler de items.
-func NewHandler(service ServiceAPI) *Handler {
-	return &Handler{service: service}
+func NewHandler(service ServiceAPI, options ...HandlerOption) *Handler {
+	handler := &Handler{service: service, defaultLimit: defaultLimit, maxLimit: maxLimit, now: time.Now, heartbeat: eventsHeartbeat, xlsxMaxRows: defaultXLSXMaxRows}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
+}
+
+// pagination es el bloque de paginación de la respuesta. Page no viene cuando se pagina por cursor.
+// NextCursor solo viene si hay más items y el orden admite cursor.
+// TotalPages, HasNext y HasPrev se calculan acá para que los clientes no hagan la cuenta.
+type pagination struct {
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+	// TotalIsEstimate indica que total (y por lo tanto total_pages) es aproximado.
+	TotalIsEstimate bool   `json:"total_is_estimate,omitempty"`
+	TotalPages      int    `json:"total_pages"`
+	HasNext         bool   `json:"has_next"`
+	HasPrev         bool   `json:"has_prev"`
+	NextCursor      string `json:"next_cursor,omitempty"`
+}
+
+// newPagination arma el bloque de paginación de una página por offset.
+// Con total 0 no hay páginas; una página más allá de la última no tiene siguiente pero sí anterior.
+func newPagination(page, limit, total int) pagination {
+	totalPages := (total + limit - 1) / limit
+	return pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+// appliedFilters refleja los filtros que efectivamente se aplicaron al listado.
+type appliedFilters struct {
+	Query        string    `json:"query,omitempty"`
+	Match        MatchMode `json:"match,omitempty"`
+	SearchFields []string  `json:"search_fields,omitempty"`
+	// SearchTranslations indica que query también buscó en las traducciones.
+	SearchTranslations bool   `json:"search_translations,omitempty"`
+	Highlight          bool   `json:"highlight,omitempty"`
+	Fuzzy              bool   `json:"fuzzy,omitempty"`
+	NameEq             string `json:"name_eq,omitempty"`
+	// CaseSensitive solo se informa junto con name_eq.
+	CaseSensitive *bool  `json:"case_sensitive,omitempty"`
+	MinPrice      string `json:"min_price,omitempty"`
+	MaxPrice      string `json:"max_price,omitempty"`
+	// UseEffectivePrice indica que el rango y el orden por precio usan el precio efectivo.
+	UseEffectivePrice bool   `json:"use_effective_price,omitempty"`
+	InStock           *bool  `json:"in_stock,omitempty"`
+	StockGTE          *int   `json:"stock_gte,omitempty"`
+	StockLTE          *int   `json:"stock_lte,omitempty"`
+	SKU               string `json:"sku,omitempty"`
+	CategoryID        string `json:"category_id,omitempty"`
+	BrandID           string `json:"brand_id,omitempty"`
+	Status            string `json:"status,omitempty"`
+	State             string `json:"state,omitempty"`
+	Currency          string `json:"currency,omitempty"`
+	// Attributes son los filtros attr.<key>, por clave.
+	Attributes     map[string]string `json:"attributes,omitempty"`
+	MaxWeight      *int              `json:"max_weight,omitempty"`
+	MinOrderQtyLTE *int              `json:"min_order_qty_lte,omitempty"`
+	ExpiringBefore string            `json:"expiring_before,omitempty"`
+	ExcludeExpired *bool             `json:"exclude_expired,omitempty"`
+	Sort           string            `json:"sort,omitempty"`
+}
+
+// Create maneja POST /items.
+func (handler *Handler) Create(writer http.ResponseWriter, request *http.Request) {
+	var itemInput CreateItemInput
+	if err := json.NewDecoder(request.Body).Decode(&itemInput); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	item, err := handler.service.Create(request.Context(), itemInput)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case isDuplicate(err):
+			failDuplicate(writer, request, err)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	httpx.Created(writer, request, itemLocation(item.ID), item)
+}
+
+// DuplicateField es un error de unicidad de los items con el campo que chocó y el mensaje para el
+// cliente.
+type DuplicateField struct {
+	Err     error
+	Field   string
+	Message string
+}
+
+// duplicateFields son los errores de unicidad de un alta o un update (name, slug, sku o barcode).
+var duplicateFields = []DuplicateField{
+	{ErrorDuplicateName, "name", "item name already exists"},
+	{ErrorDuplicateSlug, "slug", "item slug already exists"},
+	{ErrorDuplicateSKU, "sku", "item sku already exists"},
+	{ErrorDuplicateBarcode, "barcode", "item barcode already exists"},
+}
+
+// DuplicateFieldOf devuelve el campo repetido de err, si es un error de unicidad de un alta o un
+// update. Los imports lo usan para los errores por fila, con los mismos mensajes que POST /items.
+func DuplicateFieldOf(err error) (DuplicateField, bool) {
+	for _, duplicate := range duplicateFields {
+		if errors.Is(err, duplicate.Err) {
+			return duplicate, true
+		}
+	}
+	return DuplicateField{}, false
+}
+
+// isDuplicate indica si err es un error de unicidad (name, slug, sku o barcode).
+func isDuplicate(err error) bool {
+	_, ok := DuplicateFieldOf(err)
+	return ok
+}
+
+// failDuplicate responde 409 conflict con el campo repetido en el detalle.
+func failDuplicate(writer http.ResponseWriter, request *http.Request, err error) {
+	duplicate, ok := DuplicateFieldOf(err)
+	if !ok {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.FailWithDetails(writer, request, http.StatusConflict, "conflict", duplicate.Message, []httpx.ErrorDetail{
+		{Field: duplicate.Field, Message: "another item already has this " + duplicate.Field},
+	})
+}
+
+// itemLocation es el path del item para el header Location.
+func itemLocation(id string) string {
+	return "/items/" + id
+}
+
+// invalidInputReasons traduce cada motivo de entrada inválida a un código y mensaje para el cliente.
+var invalidInputReasons = []struct {
+	err     error
+	code    string
+	message string
+}{
+	{ErrorInvalidName, "invalid_name", "name must not be empty"},
+	{ErrorInvalidPrice, "invalid_price", `price must be a positive amount with up to 2 decimals (e.g. "10.50")`},
+	{ErrorInvalidSalePrice, "invalid_sale_price", `sale_price must be a positive amount with up to 2 decimals (e.g. "8.99"), lower than price`},
+	{ErrorInvalidStock, "invalid_stock", "stock must be zero or greater"},
+	{ErrorStockBelowFloor, "invalid_stock", "stock is below the backorder floor"},
+	{ErrorStockManagedByVariants, "stock_managed_by_variants", "the stock of an item with variants is the sum of its variants; change the variants instead"},
+	{ErrorInvalidSlug, "invalid_slug", slugRuleMessage},
+	{ErrorInvalidSKU, "invalid_sku", skuRuleMessage},
+	{ErrorInvalidStatus, "invalid_status", "status must be active or inactive"},
+	{ErrorInvalidState, "invalid_state", "state must be draft or published"},
+	{ErrorUnsupportedLocale, "unsupported_locale", "locale must be one of the supported translation locales"},
+	{ErrorInvalidCurrency, "invalid_currency", "currency must be a supported ISO 4217 code"},
+	{ErrorCurrencyChange, "currency_change_not_allowed", "changing the currency requires allow_currency_change=true"},
+	{ErrorInvalidCategory, "invalid_category", "category_id must be a UUID"},
+	{ErrorUnknownCategory, "invalid_category", "category_id does not reference an existing category"},
+}
+
+// failInvalidInput responde 400 con el código más específico disponible.
+// Un *ValidationError agrega el detalle por campo. Errores que solo envuelven ErrorInvalidInput
+// (sin motivo concreto) mantienen el código invalid_input.
+func failInvalidInput(writer http.ResponseWriter, request *http.Request, err error) {
+	var validationError *ValidationError
+	if errors.As(err, &validationError) {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data", []httpx.ErrorDetail{
+			{Field: validationError.Field, Message: validationError.Message},
+		})
+		return
+	}
+	for _, reason := range invalidInputReasons {
+		if errors.Is(err, reason.err) {
+			httpx.Fail(writer, request, http.StatusBadRequest, reason.code, reason.message)
+			return
+		}
+	}
+	httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
+}
+
+// List maneja GET /items con paginación y búsqueda. ?fields= recorta cada item a los campos pedidos.
+func (handler *Handler) List(writer http.ResponseWriter, request *http.Request) {
+	handler.list(writer, request, ScopeActive)
+}
+
+// Expiring maneja GET /items/expiring?days=30: los items que vencen entre hoy y dentro de days días
+// (30 si no viene, hasta 365), del más próximo al más lejano. Es GET /items con expiring_before
+// calculado y ordenado por expires_at si el cliente no pide otro orden; acepta los mismos parámetros.
+func (handler *Handler) Expiring(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, listFilterParams, paginationParams, listViewParams, []string{"days"}) {
+		return
+	}
+	days := defaultExpiringDays
+	if value := strings.TrimSpace(request.URL.Query().Get("days")); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > maxExpiringDays {
+			failInvalidFilter(writer, request, &FilterError{Field: "days", Message: fmt.Sprintf("days must be an integer between 0 and %d", maxExpiringDays)})
+			return
+		}
+		days = parsed
+	}
+
+	query := request.URL.Query()
+	query.Del("days")
+	query.Set("expiring_before", expiringBefore(handler.now().UTC(), days))
+	query.Set("exclude_expired", "true")
+	if query.Get("sort") == "" {
+		query.Set("sort", "expires_at")
+	}
+	request.URL.RawQuery = query.Encode()
+	handler.list(writer, request, ScopeActive)
+}
+
+// RestockNeeded maneja GET /items/restock-needed: los items con stock en o debajo de su punto de
+// reposición, del mayor faltante al menor, con suggested_order_qty. Se pagina con page y limit
+// (sin cursor), como el historial de stock.
+func (handler *Handler) RestockNeeded(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, paginationParams) {
+		return
+	}
+	page, err := handler.parsePagination(request)
+	if err == nil && page.Cursor != nil {
+		err = errorInvalidPagination
+	}
+	if err != nil {
+		if errors.Is(err, errorLimitTooLarge) {
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", handler.maxLimit))
+			return
+		}
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		return
+	}
+
+	result, err := handler.service.RestockNeeded(request.Context(), page.Page, page.Limit)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	if page.Capped {
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(page.Limit))
+	}
+	items := result.Items
+	if items == nil {
+		items = []Item{}
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"items":      items,
+		"pagination": newPagination(page.Page, page.Limit, result.Total),
+	})
+}
+
+// Changes maneja GET /items/changes: el feed de cambios para sincronizar una copia del catálogo.
+// Devuelve los items (borrados lógicamente incluidos, con deleted: true) por updated_at e id
+// ascendentes a partir de ?since=, y next_since para la llamada siguiente. La entrega es al menos
+// una vez: un item puede repetirse entre llamadas, nunca quedar afuera.
+func (handler *Handler) Changes(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, []string{"since", "limit"}) {
+		return
+	}
+	query := request.URL.Query()
+	after, err := parseSince(query.Get("since"))
+	if err != nil {
+		failInvalidFilter(writer, request, err)
+		return
+	}
+
+	limit, capped := defaultChangesLimit, false
+	if value := strings.TrimSpace(query.Get("limit")); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+			return
+		}
+		if limit > maxChangesLimit {
+			if handler.strictPagination {
+				httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", maxChangesLimit))
+				return
+			}
+			limit, capped = maxChangesLimit, true
+		}
+	}
+
+	result, err := handler.service.Changes(request.Context(), after, limit)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	if capped {
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(limit))
+	}
+	items := result.Items
+	if items == nil {
+		items = []Item{}
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"items":      items,
+		"next_since": encodePosition(result.Next.UpdatedAt, result.Next.ID),
+		"has_more":   result.HasMore,
+	})
+}
+
+// Events maneja GET /items/events: un stream text/event-stream con un evento por cada alta, cambio o
+// baja de un item ("item.created", "item.updated", "item.deleted") y un comentario de heartbeat cada
+// eventsHeartbeat. Termina cuando el cliente se desconecta, cuando el hub lo suelta por atrasarse o
+// cuando el servidor se apaga; el cliente se reconecta y se pone al día con GET /items/changes.
+func (handler *Handler) Events(writer http.ResponseWriter, request *http.Request) {
+	if handler.events == nil {
+		httpx.Fail(writer, request, http.StatusNotImplemented, "events_unavailable", "event stream is not available on this server")
+		return
+	}
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		httpx.FailUnexpected(writer, request, errors.New("response writer does not support flushing"))
+		return
+	}
+
+	events, unsubscribe := handler.events.Subscribe()
+	defer unsubscribe()
+
+	header := writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// nginx bufferea las respuestas por defecto; sin esto los eventos llegarían de a tandas.
+	header.Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(handler.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-request.Context().Done():
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			err = writeEvent(writer, event)
+		case <-heartbeat.C:
+			_, err = io.WriteString(writer, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent escribe event en formato SSE: el tipo en "event:" y el Event en JSON en "data:".
+func writeEvent(writer io.Writer, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event.Type(), payload)
+	return err
+}
+
+// parseSince lee ?since= del feed de cambios: un instante RFC 3339, desde el que se incluye, o el
+// next_since de una llamada anterior. Sin since el feed empieza por el cambio más viejo.
+func parseSince(value string) (UpdatedAtID, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return UpdatedAtID{}, nil
+	}
+	if instant, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return UpdatedAtID{UpdatedAt: instant}, nil
+	}
+	at, id, err := decodePosition(value)
+	if err != nil {
+		return UpdatedAtID{}, &FilterError{Field: "since", Message: "since must be an RFC 3339 timestamp or a next_since value"}
+	}
+	return UpdatedAtID{UpdatedAt: at, ID: id}, nil
+}
+
+// Trash maneja GET /items/trash: los items borrados lógicamente, con los mismos parámetros
+// que GET /items. Cada item trae deleted_at.
+func (handler *Handler) Trash(writer http.ResponseWriter, request *http.Request) {
+	handler.list(writer, request, ScopeDeleted)
+}
+
+// list es el listado compartido por List y Trash; scope elige qué items según su borrado lógico.
+func (handler *Handler) list(writer http.ResponseWriter, request *http.Request, scope DeletionScope) {
+	if !handler.knownParams(writer, request, listFilterParams, paginationParams, listViewParams) {
+		return
+	}
+	page, err := handler.parsePagination(request)
+	if err != nil {
+		switch {
+		case errors.Is(err, errorLimitTooLarge):
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", handler.maxLimit))
+		case errors.Is(err, errorCursorWithPage):
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "cursor cannot be combined with page")
+		case errors.Is(err, errorInvalidCursor):
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_cursor", "cursor is malformed")
+		default:
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		}
+		return
+	}
+	filter, ok := parseListQuery(writer, request)
+	if !ok {
+		return
+	}
+	filter.Scope = scope
+	if scope == ScopeActive {
+		filter = withDefaultStatus(filter)
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+	tag, ok := handler.negotiateLocale(writer, request)
+	if !ok {
+		return
+	}
+
+	// El polling casi nunca encuentra cambios: si el cliente ya tiene esta versión del listado,
+	// respondemos 304 sin correr la página ni el total.
+	etag, skipped := handler.collectionETag(request, filter, tag)
+	if skipped != "" {
+		writer.Header().Set("X-ETag-Skipped", skipped)
+	} else {
+		writer.Header().Set("ETag", etag)
+		if httpx.NoneMatch(request, etag) {
+			httpx.NotModified(writer)
+			return
+		}
+	}
+
+	var (
+		result ListPage
+		block  pagination
+	)
+	if page.Cursor != nil {
+		result, err = handler.service.ListAfter(request.Context(), *page.Cursor, page.Limit, filter)
+		// Por cursor no hay número de página: siempre hay una anterior (la que dio el cursor).
+		block = newPagination(0, page.Limit, result.Total)
+		block.HasNext = result.HasMore
+		block.HasPrev = true
+	} else {
+		result, err = handler.service.List(request.Context(), page.Page, page.Limit, filter)
+		block = newPagination(page.Page, page.Limit, result.Total)
+	}
+	block.TotalIsEstimate = result.TotalIsEstimate
+	if err != nil {
+		failList(writer, request, err)
+		return
+	}
+
+	// Si recortamos el limit, lo avisamos para que el cliente no asuma que recibió todo lo que pidió.
+	if page.Capped {
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(page.Limit))
+	}
+
+	// Pedir una página más allá de la última no es un error: vuelve vacía, nunca null.
+	items := result.Items
+	if items == nil {
+		items = []Item{}
+	}
+	items, err = handler.service.Localize(request.Context(), tag, items)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	setContentLanguage(writer, tag)
+	for index := range items {
+		items[index] = highlightItem(items[index], filter)
+	}
+	// El cursor apunta al último item devuelto; el service no lo arma si el orden no lo admite.
+	if block.HasNext && result.Cursor != nil {
+		block.NextCursor = encodeCursor(*result.Cursor)
+	}
+
+	projected, err := fields.applyAll(items)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	// Solo una búsqueda sin ningún resultado paga la query de la sugerencia; una página vacía más
+	// allá de la última no cuenta.
+	var meta httpx.Meta
+	if scope == ScopeActive && filter.Query != "" && page.Cursor == nil && result.Total == 0 && len(items) == 0 {
+		meta.Suggestion = handler.didYouMean(request, filter.Query)
+	}
+
+	httpx.OKWithMeta(writer, request, http.StatusOK, map[string]any{
+		"items":      projected,
+		"pagination": block,
+		"filters":    newAppliedFilters(filter),
+	}, meta)
+}
+
+// didYouMean busca el nombre a sugerir para una búsqueda sin resultados. La sugerencia es un extra:
+// si falla, se loguea y el listado vacío se responde igual.
+func (handler *Handler) didYouMean(request *http.Request, query string) string {
+	suggestion, err := handler.service.DidYouMean(request.Context(), query)
+	if err != nil && !errors.Is(err, ErrorFuzzyUnavailable) {
+		log.Printf("warn: did_you_mean_failed request_id=%s err=%v", httpx.RequestIDFrom(request), err)
+	}
+	return suggestion
+}
+
+// collectionETag deriva el ETag débil del listado a partir de la versión del catálogo y la query
+// normalizada (parámetros ordenados), así dos URLs equivalentes comparten ETag. El scope entra en el hash
+// para que el listado y la papelera con la misma query no compartan ETag, y el locale negociado para
+// que dos Accept-Language distintos tampoco. Si el listado deja afuera los vencidos entra también la
+// fecha de la base: al cambiar el día un item puede vencer sin que cambie ninguna fila. Es débil porque
+// el meta de la respuesta (request_id, time_utc) cambia en cada request.
+//
+// Si no se puede calcular devuelve el motivo:
+//   - "fuzzy": el resultado depende del umbral de similitud configurado, no solo de los datos.
+//   - "version_unavailable": falló la query de versión; el listado se sirve igual, sin ETag.
+func (handler *Handler) collectionETag(request *http.Request, filter ListFilter, tag string) (etag, skipped string) {
+	if filter.Fuzzy {
+		return "", "fuzzy"
+	}
+	version, err := handler.service.CollectionVersion(request.Context())
+	if err != nil {
+		log.Printf("warn: collection_version_failed request_id=%s err=%v", httpx.RequestIDFrom(request), err)
+		return "", "version_unavailable"
+	}
+	var today string
+	if filter.ExcludeExpired != nil && *filter.ExcludeExpired {
+		today = version.Today
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d:%d:%s:%s:%s:%s", version.LastUpdatedAt.UnixNano(), version.Count, today, filter.Scope, tag, request.URL.Query().Encode()))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, ""
+}
+
+// Count maneja GET /items/count: el total de items que matchean los mismos filtros que GET /items,
+// sin traer ninguna página.
+func (handler *Handler) Count(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, listFilterParams) {
+		return
+	}
+	filter, ok := parseListQuery(writer, request)
+	if !ok {
+		return
+	}
+
+	count, err := handler.service.Count(request.Context(), withDefaultStatus(filter))
+	if err != nil {
+		failList(writer, request, err)
+		return
+	}
+
+	httpx.OK(writer, request, http.StatusOK, countResponse{Total: count.Total, TotalIsEstimate: count.TotalIsEstimate})
+}
+
+// countResponse es el cuerpo de GET /items/count.
+type countResponse struct {
+	Total           int  `json:"total"`
+	TotalIsEstimate bool `json:"total_is_estimate,omitempty"`
+}
+
+// failList traduce los errores del service al listar o contar.
+func failList(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorInvalidMatch):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_match", "match must be one of: contains, prefix, exact")
+	case errors.Is(err, ErrorInvalidSort):
+		failInvalidSort(writer, request)
+	case errors.Is(err, ErrorInvalidFilter):
+		failInvalidFilter(writer, request, err)
+	case errors.Is(err, ErrorPaginationTooDeep):
+		httpx.Fail(writer, request, http.StatusBadRequest, "pagination_too_deep",
+			"page is too deep for offset pagination; use cursor pagination or narrow the search with filters")
+	case errors.Is(err, ErrorCursorUnsupported):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_cursor", "cursor pagination only supports the default sort, without fuzzy")
+	case errors.Is(err, ErrorFuzzyUnavailable):
+		httpx.Fail(writer, request, http.StatusNotImplemented, "fuzzy_unavailable", "fuzzy search is not available on this server")
+	case errors.Is(err, ErrorInvalidInput):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// newAppliedFilters arma el bloque filters de la respuesta a partir del filtro pedido.
+func newAppliedFilters(filter ListFilter) appliedFilters {
+	applied := appliedFilters{
+		Query:              filter.Query,
+		Match:              filter.Match,
+		SearchFields:       filter.SearchFields,
+		SearchTranslations: filter.SearchTranslations,
+		Highlight:          filter.Highlight,
+		Fuzzy:              filter.Fuzzy,
+		NameEq:             filter.NameEq,
+		MinPrice:           filter.MinPrice,
+		MaxPrice:           filter.MaxPrice,
+		UseEffectivePrice:  filter.UseEffectivePrice,
+		InStock:            filter.InStock,
+		StockGTE:           filter.StockGTE,
+		StockLTE:           filter.StockLTE,
+		SKU:                filter.SKU,
+		CategoryID:         filter.CategoryID,
+		BrandID:            filter.BrandID,
+		Status:             string(filter.Status),
+		State:              string(filter.State),
+		Currency:           filter.Currency,
+		Attributes:         filter.Attributes,
+		MaxWeight:          filter.MaxWeight,
+		MinOrderQtyLTE:     filter.MinOrderQtyLTE,
+		ExpiringBefore:     filter.ExpiringBefore,
+		ExcludeExpired:     filter.ExcludeExpired,
+		Sort:               joinSort(filter.Sort),
+	}
+	if filter.NameEq != "" {
+		caseSensitive := !filter.NameEqIgnoreCase
+		applied.CaseSensitive = &caseSensitive
+	}
+	return applied
+}
+
+// failInvalidFilter responde 400 invalid_filter, con el query param culpable si se conoce.
+func failInvalidFilter(writer http.ResponseWriter, request *http.Request, err error) {
+	var filterError *FilterError
+	if errors.As(err, &filterError) {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_filter", "invalid filter parameters", []httpx.ErrorDetail{
+			{Field: filterError.Field, Message: filterError.Message},
+		})
+		return
+	}
+	httpx.Fail(writer, request, http.StatusBadRequest, "invalid_filter", "invalid filter parameters")
+}
+
+// failInvalidFields responde 400 invalid_fields; el mensaje nombra el campo desconocido.
+func failInvalidFields(writer http.ResponseWriter, request *http.Request, err error) {
+	httpx.Fail(writer, request, http.StatusBadRequest, "invalid_fields", err.Error())
+}
+
+func failInvalidSort(writer http.ResponseWriter, request *http.Request) {
+	httpx.Fail(writer, request, http.StatusBadRequest, "invalid_sort",
+		"sort must be a comma-separated list of distinct fields among created_at, name, price, stock, expires_at (prefix - for descending)")
+}
+
+// splitList separa un query param con valores separados por coma ("name,description").
+// Las entradas vacías ("price,,stock") se conservan para que la validación las rechace
+// en vez de ignorarlas en silencio.
+func splitList(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	parts := strings.Split(value, ",")
+	for index, part := range parts {
+		parts[index] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// parseSort separa ?sort=stock,-price en claves.
+func parseSort(value string) []SortKey {
+	parts := splitList(value)
+	if parts == nil {
+		return nil
+	}
+	keys := make([]SortKey, 0, len(parts))
+	for _, part := range parts {
+		keys = append(keys, SortKey(part))
+	}
+	return keys
+}
+
+// joinSort es la inversa de parseSort, para informar el orden aplicado.
+func joinSort(keys []SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, string(key))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Valores por defecto de la paginación; se pueden cambiar con WithPageSizes.
+const (
+	defaultPage  = 1
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Tamaños de tanda del feed de cambios. Un sincronizador lee de a mucho más que una pantalla del
+// listado, así que no dependen de WithPageSizes.
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+var (
+	errorInvalidPagination = errors.New("invalid pagination")
+	errorLimitTooLarge     = errors.New("limit too large")
+	errorCursorWithPage    = errors.New("cursor combined with page")
+	errorInvalidCursor     = errors.New("invalid cursor")
+)
+
+// pageRequest es la paginación ya parseada. Capped indica que el limit pedido superaba el máximo.
+// Con Cursor distinto de nil se pagina por keyset y Page queda en 0.
+type pageRequest struct {
+	Page   int
+	Limit  int
+	Capped bool
+	Cursor *CreatedAtID
+}
+
+// parsePagination parsea page y limit con defaults y límites razonables.
+// En modo estricto un limit mayor al máximo es un error; si no, se recorta al máximo.
+// ?cursor= es la alternativa a page: no se pueden combinar.
+func (handler *Handler) parsePagination(request *http.Request) (pageRequest, error) {
+	query := request.URL.Query()
+
+	page := pageRequest{Page: defaultPage, Limit: handler.defaultLimit}
+
+	pageValue := strings.TrimSpace(query.Get("page"))
+	if value := strings.TrimSpace(query.Get("cursor")); value != "" {
+		if pageValue != "" {
+			return pageRequest{}, errorCursorWithPage
+		}
+		cursor, err := decodeCursor(value)
+		if err != nil {
+			return pageRequest{}, err
+		}
+		page.Page = 0
+		page.Cursor = &cursor
+	}
+
+	if pageValue != "" {
+		pageNumber, err := strconv.Atoi(pageValue)
+		if err != nil || pageNumber < 1 {
+			return pageRequest{}, errorInvalidPagination
+		}
+		page.Page = pageNumber
+	}
+
+	if value := strings.TrimSpace(query.Get("limit")); value != "" {
+		limitNumber, err := strconv.Atoi(value)
+		if err != nil || limitNumber < 1 {
+			return pageRequest{}, errorInvalidPagination
+		}
+		if limitNumber > handler.maxLimit {
+			if handler.strictPagination {
+				return pageRequest{}, errorLimitTooLarge
+			}
+			limitNumber = handler.maxLimit
+			page.Capped = true
+		}
+		page.Limit = limitNumber
+	}
+
+	return page, nil
+}
+
+// rankedCursorSuffix marca, al final del cursor, que el último item coincidió con un identificador.
+const rankedCursorSuffix = "|ranked"
+
+// encodeCursor arma el cursor opaco de la página siguiente del listado: la posición de
+// encodePosition más rankedCursorSuffix si el item quedó del lado de las coincidencias exactas.
+func encodeCursor(position CreatedAtID) string {
+	raw := formatPosition(position.CreatedAt, position.ID)
+	if position.Ranked {
+		raw += rankedCursorSuffix
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor es la inversa de encodeCursor. Cualquier valor que no haya salido de ahí es errorInvalidCursor.
+func decodeCursor(value string) (CreatedAtID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return CreatedAtID{}, errorInvalidCursor
+	}
+	position, ranked := strings.CutSuffix(string(raw), rankedCursorSuffix)
+	at, id, err := parsePosition(position)
+	if err != nil {
+		return CreatedAtID{}, err
+	}
+	return CreatedAtID{CreatedAt: at, ID: id, Ranked: ranked}, nil
+}
+
+// encodePosition arma un valor opaco con un par (instante, id): base64 (URL-safe) de "instante|id".
+// El instante va con nanosegundos para no perder la precisión de microsegundos de Postgres.
+// Lo usan el cursor del listado y el next_since del feed de cambios.
+func encodePosition(at time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(formatPosition(at, id)))
+}
+
+// formatPosition es el texto de encodePosition antes de pasarlo a base64.
+func formatPosition(at time.Time, id string) string {
+	return at.UTC().Format(time.RFC3339Nano) + "|" + id
+}
+
+// decodePosition es la inversa de encodePosition. Cualquier valor que no haya salido de ahí es errorInvalidCursor.
+func decodePosition(value string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return time.Time{}, "", errorInvalidCursor
+	}
+	return parsePosition(string(raw))
+}
+
+// parsePosition lee el texto de formatPosition.
+func parsePosition(raw string) (time.Time, string, error) {
+	instant, id, found := strings.Cut(raw, "|")
+	if !found {
+		return time.Time{}, "", errorInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, instant)
+	if err != nil {
+		return time.Time{}, "", errorInvalidCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return time.Time{}, "", errorInvalidCursor
+	}
+	return at, id, nil
+}
+
+// GetByID maneja GET /items/{id}.
+// Valida que el id sea UUID porque en DB es uuid; esto evita errores innecesarios.
+// ?fields= recorta la respuesta igual que en el listado.
+func (handler *Handler) GetByID(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, listViewParams) {
+		return
+	}
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+	tag, ok := handler.negotiateLocale(writer, request)
+	if !ok {
+		return
+	}
+
+	item, err := handler.service.Get(request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+	localized, err := handler.service.Localize(request.Context(), tag, []Item{item})
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	item = localized[0]
+	setContentLanguage(writer, item.Locale)
+
+	// Guardar una traducción incrementa la versión del item, así que el ETag sigue siendo la versión;
+	// Vary separa en los caches las respuestas de cada idioma.
+	etag := itemETag(item)
+	writer.Header().Set("ETag", etag)
+	// Validador por fecha para los CDN que no usan ETag.
+	lastModified := httpx.SetLastModified(writer, item.UpdatedAt, handler.now())
+	if httpx.NoneMatch(request, etag) || (lastModified && httpx.NotModifiedSince(request, item.UpdatedAt)) {
+		httpx.NotModified(writer)
+		return
+	}
+
+	projected, err := fields.apply(item)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, projected)
+}
+
+// defaultRelatedLimit es la cantidad de sugerencias de GET /items/{id}/related si no se pide limit.
+const defaultRelatedLimit = 5
+
+// Related maneja GET /items/{id}/related: items con nombre parecido para "productos similares".
+// Devuelve un array plano, sin paginación. Un limit mayor a 20 se recorta (o es 400 con paginación estricta).
+func (handler *Handler) Related(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, []string{"fields", "limit"}) {
+		return
+	}
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+
+	limit := defaultRelatedLimit
+	if value := strings.TrimSpace(request.URL.Query().Get("limit")); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "limit must be a positive integer")
+			return
+		}
+	}
+	if limit > maxRelatedLimit {
+		if handler.strictPagination {
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", maxRelatedLimit))
+			return
+		}
+		limit = maxRelatedLimit
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(limit))
+	}
+
+	related, err := handler.service.Related(request.Context(), id, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case errors.Is(err, ErrorFuzzyUnavailable):
+			httpx.Fail(writer, request, http.StatusNotImplemented, "fuzzy_unavailable", "fuzzy search is not available on this server")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+	if related == nil {
+		related = []Item{}
+	}
+
+	projected, err := fields.applyAll(related)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, projected)
+}
+
+// Límites de GET /items/suggest: q corto no sirve para sugerir y uno muy largo solo encarece
+// la similitud de trigramas.
+const (
+	defaultSuggestLimit = 8
+	minSuggestQuery     = 2
+	maxSuggestQuery     = 100
+)
+
+// Suggest maneja GET /items/suggest?q=: el autocompletado del buscador. Devuelve un array plano de
+// {id, name}, sin paginación ni total. Un limit mayor a 10 se recorta (o es 400 con paginación estricta).
+func (handler *Handler) Suggest(writer http.ResponseWriter, request *http.Request) {
+	if !handler.knownParams(writer, request, []string{"q", "limit"}) {
+		return
+	}
+	prefix := strings.TrimSpace(request.URL.Query().Get("q"))
+	if length := utf8.RuneCountInString(prefix); length < minSuggestQuery || length > maxSuggestQuery {
+		failInvalidFilter(writer, request, &FilterError{Field: "q", Message: fmt.Sprintf("q must be between %d and %d characters", minSuggestQuery, maxSuggestQuery)})
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if value := strings.TrimSpace(request.URL.Query().Get("limit")); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "limit must be a positive integer")
+			return
+		}
+	}
+	if limit > maxSuggestLimit {
+		if handler.strictPagination {
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", maxSuggestLimit))
+			return
+		}
+		limit = maxSuggestLimit
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(limit))
+	}
+
+	suggestions, err := handler.service.Suggest(request.Context(), prefix, limit)
+	if err != nil {
+		if errors.Is(err, ErrorFuzzyUnavailable) {
+			httpx.Fail(writer, request, http.StatusNotImplemented, "fuzzy_unavailable", "fuzzy search is not available on this server")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	if suggestions == nil {
+		suggestions = []Suggestion{}
+	}
+	httpx.OK(writer, request, http.StatusOK, suggestions)
+}
+
+// slugRuleMessage describe el formato de slug para los errores 400.
+const slugRuleMessage = "slug must be lowercase letters, digits and hyphens, up to 120 characters"
+
+// GetBySlug maneja GET /items/slug/{slug}, para URLs legibles en el storefront.
+// Un slug con formato inválido no puede existir, así que se rechaza sin consultar la DB. Los borradores
+// responden 404: todavía no tienen URL pública.
+func (handler *Handler) GetBySlug(writer http.ResponseWriter, request *http.Request) {
+	slug := chi.URLParam(request, "slug")
+	if !isValidSlug(slug) {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_slug", slugRuleMessage)
+		return
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+
+	item, err := handler.service.GetBySlug(request.Context(), slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	projected, err := fields.apply(item)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, projected)
+}
+
+// GetByBarcode maneja GET /items/barcode/{code}, la lectura de los scanners de EAN-13 y UPC-A.
+// Un código mal formado o con dígito verificador inválido responde 400 invalid_barcode sin ir a la base.
+func (handler *Handler) GetByBarcode(writer http.ResponseWriter, request *http.Request) {
+	code := strings.TrimSpace(chi.URLParam(request, "code"))
+	if err := barcodeError(code); err != nil {
+		var validationError *ValidationError
+		errors.As(err, &validationError)
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_barcode", validationError.Message)
+		return
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+
+	item, err := handler.service.GetByBarcode(request.Context(), code)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	projected, err := fields.apply(item)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, projected)
+}
+
+// skuRuleMessage describe el formato de SKU para los errores 400.
+const skuRuleMessage = "sku must be 3 to 64 letters, digits, dots, underscores or hyphens"
+
+// GetBySKU maneja GET /items/sku/{sku}, la lectura directa de los scanners del depósito.
+// El SKU se normaliza igual que en el alta, así que "kb-001" encuentra "KB-001".
+func (handler *Handler) GetBySKU(writer http.ResponseWriter, request *http.Request) {
+	sku := normalizeSKU(chi.URLParam(request, "sku"))
+	if !isValidSKU(sku) {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_sku", skuRuleMessage)
+		return
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
+
+	item, err := handler.service.GetBySKU(request.Context(), sku)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	projected, err := fields.apply(item)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, projected)
+}
+
+// Replace maneja PUT /items/{id}: reemplaza el item completo con las reglas del alta.
+// Lo que no viene vuelve al valor por defecto (description null, stock 0, slug generado);
+// los campos de solo lectura de la representación (id, sku, created_at...) se ignoran.
+func (handler *Handler) Replace(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var itemInput ReplaceItemInput
+	if err := json.NewDecoder(request.Body).Decode(&itemInput); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	item, err := handler.service.Replace(request.Context(), id, itemInput)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case isDuplicate(err):
+			failDuplicate(writer, request, err)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	httpx.OK(writer, request, http.StatusOK, item)
+}
+
+// Duplicate maneja POST /items/{id}/duplicate: crea una copia del item y responde 201 con la copia.
+// El body lleva el sku de la copia; sin copy_stock la copia arranca con stock 0.
+func (handler *Handler) Duplicate(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var input DuplicateItemInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	item, err := handler.service.Duplicate(request.Context(), id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case isDuplicate(err):
+			failDuplicate(writer, request, err)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	httpx.Created(writer, request, itemLocation(item.ID), item)
+}
+
+// Patch maneja PATCH /items/{id}.
+// Con Content-Type application/merge-patch+json aplica la semántica de RFC 7386
+// (null limpia campos nullable y attributes se mezcla con los actuales), con application/json-patch+json aplica JSON Patch (RFC 6902)
+// y con application/json mantiene el comportamiento original.
+func (handler *Handler) Patch(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	ifVersion, ok := handler.ifMatch(writer, request)
+	if !ok {
+		return
+	}
+
+	if isJSONPatch(request.Header.Get("Content-Type")) {
+		handler.patchJSON(writer, request, id, ifVersion)
+		return
+	}
+
+	// Leemos el body registrando qué campos vinieron, para diferenciar "no tocar" de "set null".
+	document, err := decodePatchDocument(request.Body)
+	if err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	var itemInputUpdated UpdateItemInput
+	if isMergePatch(request.Header.Get("Content-Type")) {
+		itemInputUpdated, err = document.mergePatchInput()
+	} else {
+		itemInputUpdated, err = document.updateInput(false)
+	}
+	if err != nil {
+		if errors.Is(err, ErrorInvalidInput) {
+			failInvalidInput(writer, request, err)
+			return
+		}
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+	itemInputUpdated.IfVersion = ifVersion
+	if raw := request.URL.Query().Get("allow_currency_change"); raw != "" {
+		allow, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_allow_currency_change", "allow_currency_change must be a boolean")
+			return
+		}
+		itemInputUpdated.AllowCurrencyChange = allow
+	}
+
+	item, err := handler.service.Update(request.Context(), id, itemInputUpdated)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case errors.Is(err, ErrorVersionMismatch):
+			failVersionMismatch(writer, request)
+		case isDuplicate(err):
+			failDuplicate(writer, request, err)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	writer.Header().Set("ETag", itemETag(item))
+	httpx.OK(writer, request, http.StatusOK, item)
+}
+
+// patchJSON maneja PATCH /items/{id} con application/json-patch+json.
+func (handler *Handler) patchJSON(writer http.ResponseWriter, request *http.Request, id string, ifVersion *int) {
+	var operations []PatchOperation
+	if err := json.NewDecoder(request.Body).Decode(&operations); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "body must be a JSON Patch array")
+		return
+	}
+
+	item, err := handler.service.ApplyJSONPatch(request.Context(), id, operations, ifVersion)
+	if err != nil {
+		var operationError *PatchOperationError
+		switch {
+		case errors.As(err, &operationError) && errors.Is(err, ErrorPatchTestFailed):
+			failPatchOperation(writer, request, http.StatusConflict, "precondition_failed", "patch test operation failed", operationError)
+		case errors.As(err, &operationError):
+			failPatchOperation(writer, request, http.StatusBadRequest, "invalid_patch", "unsupported patch operation", operationError)
+		case errors.Is(err, ErrorPatchTooLarge):
+			httpx.Fail(writer, request, http.StatusBadRequest, "patch_too_large", fmt.Sprintf("patch must have at most %d operations", maxPatchOperations))
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case errors.Is(err, ErrorVersionMismatch):
+			failVersionMismatch(writer, request)
+		case isDuplicate(err):
+			failDuplicate(writer, request, err)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	writer.Header().Set("ETag", itemETag(item))
+	httpx.OK(writer, request, http.StatusOK, item)
+}
+
+// itemETag es el ETag del item: su versión como entity tag fuerte ("3").
+func itemETag(item Item) string {
+	return `"` + strconv.Itoa(item.Version) + `"`
+}
+
+// ifMatch lee If-Match de un PATCH o DELETE y devuelve la versión esperada (nil sin precondición
+// o con "*"). Si el request no puede seguir responde y devuelve false:
+//   - 428 precondition_required si falta el header y la opción WithRequireIfMatch está activa.
+//   - 412 precondition_failed si el valor no puede coincidir con ninguna versión: un ETag débil
+//     (If-Match compara en forma fuerte), varios ETags o algo que no es una versión.
+func (handler *Handler) ifMatch(writer http.ResponseWriter, request *http.Request) (*int, bool) {
+	tags := httpx.IfMatch(request)
+	if tags == nil {
+		if handler.requireIfMatch {
+			httpx.Fail(writer, request, http.StatusPreconditionRequired, "precondition_required", "If-Match header is required")
+			return nil, false
+		}
+		return nil, true
+	}
+	if len(tags) == 1 && tags[0] == "*" {
+		return nil, true
+	}
+
+	if len(tags) == 1 && len(tags[0]) > 2 && strings.HasPrefix(tags[0], `"`) && strings.HasSuffix(tags[0], `"`) {
+		if version, err := strconv.Atoi(strings.Trim(tags[0], `"`)); err == nil && version > 0 {
+			return &version, true
+		}
+	}
+	failVersionMismatch(writer, request)
+	return nil, false
+}
+
+// failVersionMismatch responde 412 cuando If-Match no coincide con la versión actual del item.
+func failVersionMismatch(writer http.ResponseWriter, request *http.Request) {
+	httpx.Fail(writer, request, http.StatusPreconditionFailed, "precondition_failed", "item was modified by another request; fetch it again and retry")
+}
+
+// failPatchOperation responde con el índice de la operación que falló como detalle
+// (field es un JSON Pointer al elemento del array, por ejemplo "/2").
+func failPatchOperation(writer http.ResponseWriter, request *http.Request, status int, code, message string, operationError *PatchOperationError) {
+	httpx.FailWithDetails(writer, request, status, code, message, []httpx.ErrorDetail{
+		{Field: fmt.Sprintf("/%d", operationError.Index), Message: operationError.Reason},
+	})
+}
+
+// Delete maneja DELETE /items/{id}. Por defecto responde 204; con ?return=representation responde 200
+// con el último estado del item (incluido deleted_at), pensado para ofrecer "deshacer".
+func (handler *Handler) Delete(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	representation := false
+	switch request.URL.Query().Get("return") {
+	case "", "minimal":
+	case "representation":
+		representation = true
+	default:
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_return", "return must be one of: minimal, representation")
+		return
+	}
+
+	ifVersion, ok := handler.ifMatch(writer, request)
+	if !ok {
+		return
+	}
+
+	item, err := handler.service.Delete(request.Context(), id, ifVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case errors.Is(err, ErrorVersionMismatch):
+			failVersionMismatch(writer, request)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	if representation {
+		httpx.OK(writer, request, http.StatusOK, item)
+		return
+	}
+	// 204 No Content: respuesta vacía.
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// AdjustStock maneja POST /items/{id}/stock-adjustments: suma delta al stock y deja el motivo
+// en el historial. Responde el item actualizado, como PATCH.
+func (handler *Handler) AdjustStock(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var input StockAdjustmentInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	item, err := handler.service.AdjustStock(request.Context(), id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	writer.Header().Set("ETag", itemETag(item))
+	httpx.OK(writer, request, http.StatusOK, item)
+}
+
+// Publish maneja POST /items/{id}/publish: pasa un borrador a published si está completo.
+func (handler *Handler) Publish(writer http.ResponseWriter, request *http.Request) {
+	handler.changeState(writer, request, handler.service.Publish)
+}
+
+// Unpublish maneja POST /items/{id}/unpublish: vuelve el item a draft.
+func (handler *Handler) Unpublish(writer http.ResponseWriter, request *http.Request) {
+	handler.changeState(writer, request, handler.service.Unpublish)
+}
+
+// changeState es el cuerpo común de Publish y Unpublish; change es el método del service.
+func (handler *Handler) changeState(writer http.ResponseWriter, request *http.Request, change func(ctx context.Context, id string) (Item, error)) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	item, err := change(request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	writer.Header().Set("ETag", itemETag(item))
+	httpx.OK(writer, request, http.StatusOK, item)
+}
+
+// StockMovements maneja GET /items/{id}/stock-movements: el historial de stock del item,
+// del más nuevo al más viejo, paginado con page y limit como el listado (sin cursor).
+func (handler *Handler) StockMovements(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	page, err := handler.parsePagination(request)
+	if err == nil && page.Cursor != nil {
+		err = errorInvalidPagination
+	}
+	if err != nil {
+		if errors.Is(err, errorLimitTooLarge) {
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", handler.maxLimit))
+			return
+		}
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		return
+	}
+
+	result, err := handler.service.StockMovements(request.Context(), id, page.Page, page.Limit)
+	if err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	if page.Capped {
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(page.Limit))
+	}
+	movements := result.Movements
+	if movements == nil {
+		movements = []StockMovement{}
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"movements":  movements,
+		"pagination": newPagination(page.Page, page.Limit, result.Total),
+	})
+}
+
+// PriceHistory maneja GET /items/{id}/price-history: los cambios de precio del item, del más nuevo
+// al más viejo, paginados como el historial de stock. ?from= y ?to= acotan por fecha del cambio.
+func (handler *Handler) PriceHistory(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	page, err := handler.parsePagination(request)
+	if err == nil && page.Cursor != nil {
+		err = errorInvalidPagination
+	}
+	if err != nil {
+		if errors.Is(err, errorLimitTooLarge) {
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", handler.maxLimit))
+			return
+		}
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		return
+	}
+	filter, err := parsePriceHistoryFilter(request)
+	if err != nil {
+		failInvalidFilter(writer, request, err)
+		return
+	}
+
+	result, err := handler.service.PriceHistory(request.Context(), id, filter, page.Page, page.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidFilter):
+			failInvalidFilter(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	if page.Capped {
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(page.Limit))
+	}
+	entries := result.Entries
+	if entries == nil {
+		entries = []PriceHistoryEntry{}
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"entries":    entries,
+		"pagination": newPagination(page.Page, page.Limit, result.Total),
+	})
+}
+
+// Audit maneja GET /items/{id}/audit: quién cambió qué del item, de la entrada más nueva a la más
+// vieja, paginada como el historial de precios.
+func (handler *Handler) Audit(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	page, err := handler.parsePagination(request)
+	if err == nil && page.Cursor != nil {
+		err = errorInvalidPagination
+	}
+	if err != nil {
+		if errors.Is(err, errorLimitTooLarge) {
+			httpx.Fail(writer, request, http.StatusBadRequest, "limit_too_large", fmt.Sprintf("limit must be at most %d", handler.maxLimit))
+			return
+		}
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		return
+	}
+
+	result, err := handler.service.Audit(request.Context(), id, page.Page, page.Limit)
+	if err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	if page.Capped {
+		writer.Header().Set("X-Limit-Capped", strconv.Itoa(page.Limit))
+	}
+	entries := result.Entries
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"entries":    entries,
+		"pagination": newPagination(page.Page, page.Limit, result.Total),
+	})
+}
+
+// parsePriceHistoryFilter lee ?from= y ?to= del historial de precios. Aceptan un instante RFC 3339
+// o una fecha YYYY-MM-DD (medianoche UTC), así "qué costaba en marzo" es from=2025-03-01&to=2025-04-01.
+func parsePriceHistoryFilter(request *http.Request) (PriceHistoryFilter, error) {
+	var filter PriceHistoryFilter
+	for _, bound := range []struct {
+		param       string
+		destination **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := strings.TrimSpace(request.URL.Query().Get(bound.param))
+		if value == "" {
+			continue
+		}
+		instant, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if instant, err = time.Parse(dateLayout, value); err != nil {
+				return PriceHistoryFilter{}, &FilterError{Field: bound.param, Message: bound.param + " must be an RFC 3339 timestamp or a YYYY-MM-DD date"}
+			}
+		}
+		*bound.destination = &instant
+	}
+	return filter, nil
+}
+
+// Reserve maneja POST /items/{id}/reservations: retiene stock durante ttl_seconds (600 si no viene).
+// Si el stock disponible no alcanza responde 409 insufficient_stock, y si quantity es menor que el
+// min_order_qty del item, 422 below_min_order_qty.
+func (handler *Handler) Reserve(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var input ReserveInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	reservation, err := handler.service.Reserve(request.Context(), id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case errors.Is(err, ErrorInsufficientStock):
+			httpx.Fail(writer, request, http.StatusConflict, "insufficient_stock", "not enough available stock for this reservation")
+		case errors.Is(err, ErrorBelowMinOrderQty):
+			httpx.Fail(writer, request, http.StatusUnprocessableEntity, "below_min_order_qty", "quantity is below the minimum order quantity of this item")
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	httpx.Created(writer, request, itemLocation(id)+"/reservations/"+reservation.ID, reservation)
+}
+
+// Release maneja DELETE /items/{id}/reservations/{rid}: libera la reserva antes de que venza.
+func (handler *Handler) Release(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	reservationID := chi.URLParam(request, "rid")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	if _, err := uuid.Parse(reservationID); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "reservation id must be a valid UUID")
+		return
+	}
+
+	if err := handler.service.Release(request.Context(), id, reservationID); err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "reservation not found")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// Variants maneja GET /items/{id}/variants: todas las variantes del item, sin paginar.
+func (handler *Handler) Variants(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	variants, err := handler.service.Variants(request.Context(), id)
+	if err != nil {
+		failVariant(writer, request, err)
+		return
+	}
+	if variants == nil {
+		variants = []Variant{}
+	}
+	httpx.OK(writer, request, http.StatusOK, variants)
 }
 
-type pagination struct {
-	Page  int `json:"page"`
-	Limit int `json:"limit"`
-	Total int `json:"total"`
+// CreateVariant maneja POST /items/{id}/variants y responde 201 con la variante.
+func (handler *Handler) CreateVariant(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var input CreateVariantInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	variant, err := handler.service.CreateVariant(request.Context(), id, input)
+	if err != nil {
+		failVariant(writer, request, err)
+		return
+	}
+	httpx.Created(writer, request, variantLocation(id, variant.ID), variant)
 }
 
-// Create maneja POST /items.
-func (handler *Handler) Create(writer http.ResponseWriter, request *http.Request) {
-	var itemInput CreateItemInput
-	if err := json.NewDecoder(request.Body).Decode(&itemInput); err != nil {
+// UpdateVariant maneja PATCH /items/{id}/variants/{vid}. price en null vuelve a heredar el precio del item.
+func (handler *Handler) UpdateVariant(writer http.ResponseWriter, request *http.Request) {
+	id, variantID, ok := variantIDs(writer, request)
+	if !ok {
+		return
+	}
+
+	document, err := decodePatchDocument(request.Body)
+	if err != nil {
 		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
 		return
 	}
+	var input UpdateVariantInput
+	if err := json.Unmarshal(mustMarshal(document.raw), &input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+	input.PricePresent = document.present("price")
+	input.AttributesPresent = document.present("attributes")
 
-	item, err := handler.service.Create(request.Context(), itemInput)
+	variant, err := handler.service.UpdateVariant(request.Context(), id, variantID, input)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrorInvalidInput):
-			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
-		case errors.Is(err, ErrorDuplicateName):
-			httpx.Fail(writer, request, http.StatusConflict, "conflict", "item name already exists")
-		default:
-			// No filtramos detalles internos.
-			httpx.Fail(writer, request, http.StatusInternalServerError, "internal_error", "unexpected error")
-		}
+		failVariant(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, variant)
+}
+
+// DeleteVariant maneja DELETE /items/{id}/variants/{vid}.
+func (handler *Handler) DeleteVariant(writer http.ResponseWriter, request *http.Request) {
+	id, variantID, ok := variantIDs(writer, request)
+	if !ok {
 		return
 	}
 
-	httpx.OK(writer, request, http.StatusCreated, item)
+	if err := handler.service.DeleteVariant(request.Context(), id, variantID); err != nil {
+		failVariant(writer, request, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
 }
 
-// List maneja GET /items con paginación y búsqueda.
-func (handler *Handler) List(writer http.ResponseWriter, request *http.Request) {
-	page, limit, err := parsePagination(request)
+// variantIDs lee y valida el id del item y el de la variante. Si alguno es inválido ya respondió 400.
+func variantIDs(writer http.ResponseWriter, request *http.Request) (string, string, bool) {
+	id := chi.URLParam(request, "id")
+	variantID := chi.URLParam(request, "vid")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return "", "", false
+	}
+	if _, err := uuid.Parse(variantID); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "variant id must be a valid UUID")
+		return "", "", false
+	}
+	return id, variantID, true
+}
+
+// failVariant traduce los errores de los endpoints de variantes.
+func failVariant(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorInvalidInput):
+		failInvalidInput(writer, request, err)
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+	case errors.Is(err, ErrorVariantNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "variant not found")
+	case errors.Is(err, ErrorDuplicateVariantSKU):
+		httpx.FailWithDetails(writer, request, http.StatusConflict, "conflict", "variant sku already exists", []httpx.ErrorDetail{
+			{Field: "sku", Message: "another variant of this item already has this sku"},
+		})
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// variantLocation es el path de la variante para el header Location.
+func variantLocation(itemID, variantID string) string {
+	return itemLocation(itemID) + "/variants/" + variantID
+}
+
+// PriceSchedules maneja GET /items/{id}/price-schedules: los cambios de precio del item, pendientes
+// y aplicados, sin paginar.
+func (handler *Handler) PriceSchedules(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	schedules, err := handler.service.PriceSchedules(request.Context(), id)
 	if err != nil {
-		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		failPriceSchedule(writer, request, err)
+		return
+	}
+	if schedules == nil {
+		schedules = []PriceSchedule{}
+	}
+	httpx.OK(writer, request, http.StatusOK, schedules)
+}
+
+// CreatePriceSchedule maneja POST /items/{id}/price-schedules y responde 201 con el cambio programado.
+func (handler *Handler) CreatePriceSchedule(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var input CreatePriceScheduleInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	schedule, err := handler.service.CreatePriceSchedule(request.Context(), id, input)
+	if err != nil {
+		failPriceSchedule(writer, request, err)
+		return
+	}
+	httpx.Created(writer, request, itemLocation(id)+"/price-schedules/"+schedule.ID, schedule)
+}
+
+// DeletePriceSchedule maneja DELETE /items/{id}/price-schedules/{sid}: cancela un cambio pendiente.
+func (handler *Handler) DeletePriceSchedule(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	scheduleID := chi.URLParam(request, "sid")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	if _, err := uuid.Parse(scheduleID); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "price schedule id must be a valid UUID")
 		return
 	}
 
-	query := strings.TrimSpace(request.URL.Query().Get("query"))
+	if err := handler.service.DeletePriceSchedule(request.Context(), id, scheduleID); err != nil {
+		failPriceSchedule(writer, request, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// failPriceSchedule traduce los errores de los endpoints de cambios de precio.
+func failPriceSchedule(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorInvalidInput):
+		failInvalidInput(writer, request, err)
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+	case errors.Is(err, ErrorPriceScheduleNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "price schedule not found")
+	case errors.Is(err, ErrorDuplicatePriceSchedule):
+		httpx.FailWithDetails(writer, request, http.StatusConflict, "conflict", "price schedule already exists", []httpx.ErrorDetail{
+			{Field: "effective_at", Message: "this item already has a pending price change at this instant"},
+		})
+	case errors.Is(err, ErrorScheduledBelowSalePrice):
+		httpx.FailWithDetails(writer, request, http.StatusUnprocessableEntity, "below_sale_price", "scheduled price must be above the sale price", []httpx.ErrorDetail{
+			{Field: "new_price", Message: "new_price must be above the item's current sale_price"},
+		})
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// GetByExternalRef maneja GET /items/by-ref/{system}/{external_id}: el item al que apunta el ID de
+// un sistema externo, para los jobs de sincronización. Encuentra también los borradores.
+func (handler *Handler) GetByExternalRef(writer http.ResponseWriter, request *http.Request) {
+	system, externalID, ok := externalRefParams(writer, request)
+	if !ok {
+		return
+	}
+	fields, err := parseFields(request)
+	if err != nil {
+		failInvalidFields(writer, request, err)
+		return
+	}
 
-	items, total, err := handler.service.List(request.Context(), page, limit, query)
+	item, err := handler.service.GetByExternalRef(request.Context(), system, externalID)
 	if err != nil {
 		switch {
-		case errors.Is(err, ErrorInvalidInput):
-			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
+		case errors.Is(err, ErrorNotFound):
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
 		default:
-			httpx.Fail(writer, request, http.StatusInternalServerError, "internal_error", "unexpected error")
+			httpx.FailUnexpected(writer, request, err)
 		}
 		return
 	}
 
-	httpx.OK(writer, request, http.StatusOK, map[string]any{
-		"items": items,
-		"pagination": pagination{
-			Page:  page,
-			Limit: limit,
-			Total: total,
-		},
-	})
+	projected, err := fields.apply(item)
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, projected)
 }
 
-// parsePagination parsea page y limit con defaults y límites razonables.
-func parsePagination(request *http.Request) (int, int, error) {
-	const (
-		defaultPage  = 1
-		defaultLimit = 20
-		maxLimit     = 100
-	)
+// AddExternalRef maneja POST /items/{id}/refs. Responde 201 con la ref nueva, o 200 si el item ya
+// la tenía: así un job de sincronización puede repetir el alta sin chequear antes.
+func (handler *Handler) AddExternalRef(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
 
-	query := request.URL.Query()
+	var input ExternalRefInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
 
-	page := defaultPage
-	limit := defaultLimit
+	ref, created, err := handler.service.AddExternalRef(request.Context(), id, input)
+	if err != nil {
+		failExternalRef(writer, request, err)
+		return
+	}
+	if !created {
+		httpx.OK(writer, request, http.StatusOK, ref)
+		return
+	}
+	httpx.Created(writer, request, externalRefLocation(id, ref), ref)
+}
 
-	if value := strings.TrimSpace(query.Get("page")); value != "" {
-		pageNumber, err := strconv.Atoi(value)
-		if err != nil || pageNumber < 1 {
-			return 0, 0, err
-		}
-		page = pageNumber
+// RemoveExternalRef maneja DELETE /items/{id}/refs/{system}/{external_id}.
+func (handler *Handler) RemoveExternalRef(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+	system, externalID, ok := externalRefParams(writer, request)
+	if !ok {
+		return
 	}
 
-	if value := strings.TrimSpace(query.Get("limit")); value != "" {
-		limitNumber, err := strconv.Atoi(value)
-		if err != nil || limitNumber < 1 {
-			return 0, 0, err
-		}
-		if limitNumber > maxLimit {
-			limitNumber = maxLimit
-		}
-		limit = limitNumber
+	if err := handler.service.RemoveExternalRef(request.Context(), id, system, externalID); err != nil {
+		failExternalRef(writer, request, err)
+		return
 	}
+	writer.WriteHeader(http.StatusNoContent)
+}
 
-	return page, limit, nil
+// externalRefParams lee {system} y {external_id} del path. chi deja los params escapados cuando el
+// path tiene caracteres escapados (un "/" en el ID externo llega como %2F), así que se desescapan acá.
+// Si alguno no es válido responde 400 y devuelve ok en false.
+func externalRefParams(writer http.ResponseWriter, request *http.Request) (system, externalID string, ok bool) {
+	system, systemErr := url.PathUnescape(chi.URLParam(request, "system"))
+	externalID, externalIDErr := url.PathUnescape(chi.URLParam(request, "external_id"))
+	if systemErr != nil || externalIDErr != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_ref", "system and external_id must be valid path segments")
+		return "", "", false
+	}
+	system, externalID = normalizeExternalRef(system, externalID)
+	if err := externalRefError(system, externalID); err != nil {
+		var validationError *ValidationError
+		errors.As(err, &validationError)
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_ref", validationError.Message)
+		return "", "", false
+	}
+	return system, externalID, true
 }
 
-// GetByID maneja GET /items/{id}.
-// Valida que el id sea UUID porque en DB es uuid; esto evita errores innecesarios.
-func (handler *Handler) GetByID(writer http.ResponseWriter, request *http.Request) {
+// externalRefLocation es el path de una ref del item, el mismo con el que se borra.
+func externalRefLocation(itemID string, ref ExternalRef) string {
+	return itemLocation(itemID) + "/refs/" + url.PathEscape(ref.System) + "/" + url.PathEscape(ref.ExternalID)
+}
+
+// failExternalRef traduce los errores de los endpoints de refs externas. Un conflicto nombra el
+// sistema, para que el job sepa qué mapeo está desalineado.
+func failExternalRef(writer http.ResponseWriter, request *http.Request, err error) {
+	var conflictError *ExternalRefConflictError
+	switch {
+	case errors.Is(err, ErrorInvalidInput):
+		failInvalidInput(writer, request, err)
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+	case errors.Is(err, ErrorExternalRefNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "external ref not found")
+	case errors.As(err, &conflictError):
+		httpx.FailWithDetails(writer, request, http.StatusConflict, "conflict", fmt.Sprintf("external ref already exists in %s", conflictError.System), []httpx.ErrorDetail{
+			{Field: "external_id", Message: conflictError.Error()},
+		})
+	case errors.Is(err, ErrorDuplicateExternalRef):
+		httpx.Fail(writer, request, http.StatusConflict, "conflict", "external ref already exists")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// Translations maneja GET /items/{id}/translations: las traducciones cargadas del item, sin la
+// del locale base (que son los campos del item).
+func (handler *Handler) Translations(writer http.ResponseWriter, request *http.Request) {
 	id := chi.URLParam(request, "id")
 	if _, err := uuid.Parse(id); err != nil {
 		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
 		return
 	}
 
-	item, err := handler.service.Get(request.Context(), id)
+	translations, err := handler.service.Translations(request.Context(), id)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrorNotFound):
-			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
-		default:
-			httpx.Fail(writer, request, http.StatusInternalServerError, "internal_error", "unexpected error")
-		}
+		failTranslation(writer, request, err)
 		return
 	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{"translations": translations})
+}
 
-	httpx.OK(writer, request, http.StatusOK, item)
+// PutTranslation maneja PUT /items/{id}/translations/{locale}: crea o reemplaza la traducción.
+// Responde 200 en los dos casos, con la traducción guardada.
+func (handler *Handler) PutTranslation(writer http.ResponseWriter, request *http.Request) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return
+	}
+
+	var input TranslationInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	translation, err := handler.service.PutTranslation(request.Context(), id, chi.URLParam(request, "locale"), input)
+	if err != nil {
+		failTranslation(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, translation)
 }
 
-// Patch maneja PATCH /items/{id}.
-func (handler *Handler) Patch(writer http.ResponseWriter, request *http.Request) {
+// failTranslation traduce los errores de los endpoints de traducciones.
+func failTranslation(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorInvalidInput):
+		failInvalidInput(writer, request, err)
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// negotiateLocale elige el idioma de la respuesta de GET /items y GET /items/{id}. ?locale= manda y
+// tiene que ser uno de los soportados (si no, responde 400 unsupported_locale y devuelve ok en
+// false); sin él se negocia Accept-Language. Devuelve "" si el request no pidió ningún idioma
+// soportado: la respuesta sale con los campos base y sin marca de locale.
+func (handler *Handler) negotiateLocale(writer http.ResponseWriter, request *http.Request) (tag string, ok bool) {
+	// La respuesta depende del header aunque este request no lo mande.
+	writer.Header().Add("Vary", "Accept-Language")
+	supported := handler.service.Locales()
+	if value := request.URL.Query().Get("locale"); value != "" {
+		tag = locale.Normalize(value)
+		if !slices.Contains(supported, tag) {
+			httpx.Fail(writer, request, http.StatusBadRequest, "unsupported_locale", "locale must be one of: "+strings.Join(supported, ", "))
+			return "", false
+		}
+		return tag, true
+	}
+	return locale.Negotiate(request.Header.Get("Accept-Language"), supported), true
+}
+
+// setContentLanguage informa el idioma de la respuesta, si se negoció uno.
+func setContentLanguage(writer http.ResponseWriter, tag string) {
+	if tag != "" {
+		writer.Header().Set("Content-Language", tag)
+	}
+}
+
+// Purge maneja DELETE /items/{id}/purge: borra definitivamente un item de la papelera.
+// Un item que no existe o que no está borrado responde 404.
+func (handler *Handler) Purge(writer http.ResponseWriter, request *http.Request) {
 	id := chi.URLParam(request, "id")
 	if _, err := uuid.Parse(id); err != nil {
 		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
 		return
 	}
 
-	// Primero leemos raw para saber qué campos vinieron.
-	var raw map[string]json.RawMessage
-	if err := json.NewDecoder(request.Body).Decode(&raw); err != nil {
+	if err := handler.service.Purge(request.Context(), id); err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found in trash")
+			return
+		}
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// bulkDeleteRequest es el body de POST /items/bulk-delete.
+type bulkDeleteRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BulkDelete maneja POST /items/bulk-delete: borra hasta 500 items en una sola sentencia y
+// responde cuántos borró y qué IDs no existían. Si algún ID no es un UUID válido no borra nada
+// y responde 400 con la posición de cada ID inválido.
+func (handler *Handler) BulkDelete(writer http.ResponseWriter, request *http.Request) {
+	var body bulkDeleteRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
 		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
 		return
 	}
 
-	// Re-encode y decode al struct para reutilizar tags y tipos.
-	byteJson, _ := json.Marshal(raw)
+	var details []httpx.ErrorDetail
+	for index, id := range body.IDs {
+		if _, err := uuid.Parse(id); err != nil {
+			details = append(details, httpx.ErrorDetail{Field: fmt.Sprintf("ids[%d]", index), Message: "must be a valid UUID"})
+		}
+	}
+	if len(details) > 0 {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_id", "ids must be valid UUIDs", details)
+		return
+	}
+
+	result, err := handler.service.DeleteMany(request.Context(), body.IDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+
+	httpx.OK(writer, request, http.StatusOK, result)
+}
+
+// bulkUpdateRequest es el body de PATCH /items/bulk. Cada entrada es un objeto con id y los campos
+// a cambiar, con la misma semántica que PATCH /items/{id} en application/json (description en null la limpia).
+type bulkUpdateRequest struct {
+	Updates []json.RawMessage `json:"updates"`
+}
 
-	var itemInputUpdated UpdateItemInput
-	if err := json.Unmarshal(byteJson, &itemInputUpdated); err != nil {
+// BulkUpdate maneja PATCH /items/bulk: aplica hasta 200 updates parciales en una sola transacción.
+// Una entrada mal formada (id inválido, tipos incorrectos) rechaza el pedido con 400 y la posición
+// de cada una. Si alguna entrada falla no se aplica ninguna y se responde el error de la primera, con
+// su status (400, 404 o 409). Si no, 200 con el resultado por entrada. Con ?atomic=false cada
+// entrada se aplica por su cuenta y responde 200 con las que fallan como failed.
+func (handler *Handler) BulkUpdate(writer http.ResponseWriter, request *http.Request) {
+	atomic, ok := httpx.ParseAtomic(writer, request, true)
+	if !ok {
+		return
+	}
+
+	var body bulkUpdateRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
 		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
 		return
 	}
 
-	// Manejo explícito de description:
-	// - Si el cliente envió "description": null => queremos setear NULL.
-	// - Si NO envió "description" => no queremos tocar.
-	_, descPresent := raw["description"]
-	itemInputUpdated.DescriptionPresent = descPresent
+	entries := make([]BulkUpdateEntry, 0, len(body.Updates))
+	var details []httpx.ErrorDetail
+	for index, raw := range body.Updates {
+		entry, err := decodeBulkUpdateEntry(raw)
+		if err != nil {
+			field := fmt.Sprintf("updates[%d]", index)
+			if err.Field != "" {
+				field += "." + err.Field
+			}
+			details = append(details, httpx.ErrorDetail{Field: field, Message: err.Message})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(details) > 0 {
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data", details)
+		return
+	}
 
-	item, err := handler.service.Update(request.Context(), id, itemInputUpdated)
+	update := handler.service.UpdateMany
+	if !atomic {
+		update = handler.service.UpdateEach
+	}
+	results, err := update(request.Context(), entries)
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrorInvalidInput):
-			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
-		case errors.Is(err, ErrorNotFound):
-			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
-		case errors.Is(err, ErrorDuplicateName):
-			httpx.Fail(writer, request, http.StatusConflict, "conflict", "item name already exists")
+			failInvalidInput(writer, request, err)
 		default:
-			httpx.Fail(writer, request, http.StatusInternalServerError, "internal_error", "unexpected error")
+			httpx.FailUnexpected(writer, request, err)
 		}
 		return
 	}
 
-	httpx.OK(writer, request, http.StatusOK, item)
+	report := httpx.NewBulkReport(len(results))
+	for _, result := range results {
+		ref := httpx.ByID(result.ID)
+		if result.Err == nil {
+			report.Succeed(ref, result.Item)
+			continue
+		}
+		status, code, message := bulkEntryError(result.Err)
+		if errors.Is(result.Err, ErrorNotApplied) {
+			report.Skip(ref, code, message)
+			continue
+		}
+		report.Fail(ref, status, code, message)
+	}
+	if failure, found := report.FirstFailure(); atomic && found {
+		httpx.Fail(writer, request, failure.HTTPStatus, failure.Error.Code, "item "+failure.ID+": "+failure.Error.Message)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, report)
 }
 
-// Delete maneja DELETE /items/{id}.
-func (handler *Handler) Delete(writer http.ResponseWriter, request *http.Request) {
-	id := chi.URLParam(request, "id")
-	if _, err := uuid.Parse(id); err != nil {
-		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+// AdjustPrices maneja POST /items/price-adjustments: un ajuste masivo (porcentaje o monto fijo) del
+// precio de lista de los items del filtro. Con dry_run responde cuántos items cambiarían y una muestra
+// sin tocar nada. ?confirm_over=N autoriza a aplicarlo a más items que el tope, hasta N.
+func (handler *Handler) AdjustPrices(writer http.ResponseWriter, request *http.Request) {
+	var input PriceAdjustmentInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
 		return
 	}
+	if value := strings.TrimSpace(request.URL.Query().Get("confirm_over")); value != "" {
+		confirmOver, err := strconv.Atoi(value)
+		if err != nil {
+			failInvalidInput(writer, request, &ValidationError{Field: "confirm_over", Message: "confirm_over must be a non-negative integer"})
+			return
+		}
+		input.ConfirmOver = &confirmOver
+	}
 
-	err := handler.service.Delete(request.Context(), id)
+	result, err := handler.service.AdjustPrices(request.Context(), input)
 	if err != nil {
+		var limitError *PriceAdjustmentLimitError
 		switch {
-		case errors.Is(err, ErrorNotFound):
-			httpx.Fail(writer, request, http.StatusNotFound, "not_found", "item not found")
+		case errors.As(err, &limitError):
+			httpx.Fail(writer, request, http.StatusUnprocessableEntity, "confirmation_required", limitError.Error())
+		case errors.Is(err, ErrorAdjustedPriceNotPositive):
+			httpx.Fail(writer, request, http.StatusUnprocessableEntity, "non_positive_price", "adjustment would leave a price at or below zero")
+		case errors.Is(err, ErrorAdjustedBelowSalePrice):
+			httpx.Fail(writer, request, http.StatusUnprocessableEntity, "below_sale_price", "adjustment would leave a price at or below its sale price")
+		case errors.Is(err, ErrorInvalidInput):
+			failInvalidInput(writer, request, err)
 		default:
-			httpx.Fail(writer, request, http.StatusInternalServerError, "internal_error", "unexpected error")
+			httpx.FailUnexpected(writer, request, err)
 		}
 		return
 	}
 
-	// 204 No Content: respuesta vacía.
-	writer.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+	if result.Sample == nil {
+		result.Sample = []PriceAdjustmentSample{}
+	}
+	httpx.OK(writer, request, http.StatusOK, result)
+}
+
+// decodeBulkUpdateEntry convierte una entrada de PATCH /items/bulk en BulkUpdateEntry.
+// El error indica el campo de la entrada con problemas (vacío si la entrada no es un objeto).
+func decodeBulkUpdateEntry(raw json.RawMessage) (BulkUpdateEntry, *ValidationError) {
+	document, err := decodePatchDocument(bytes.NewReader(raw))
+	if err != nil {
+		return BulkUpdateEntry{}, &ValidationError{Message: "must be a JSON object"}
+	}
+
+	var id string
+	if err := json.Unmarshal(document.raw["id"], &id); err != nil {
+		return BulkUpdateEntry{}, &ValidationError{Field: "id", Message: "must be a valid UUID"}
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return BulkUpdateEntry{}, &ValidationError{Field: "id", Message: "must be a valid UUID"}
+	}
+	delete(document.raw, "id")
+
+	input, err := document.updateInput(false)
+	if err != nil {
+		var validationError *ValidationError
+		if errors.As(err, &validationError) {
+			return BulkUpdateEntry{}, validationError
+		}
+		return BulkUpdateEntry{}, &ValidationError{Message: "fields have invalid types"}
+	}
+	return BulkUpdateEntry{ID: id, Input: input}, nil
+}
+
+// bulkEntryError traduce el error de una entrada de una operación masiva a código y mensaje,
+// con los mismos códigos que la operación individual.
+func bulkEntryError(err error) (status int, code, message string) {
+	var validationError *ValidationError
+	switch {
+	case errors.Is(err, ErrorNotApplied):
+		return http.StatusOK, "not_applied", "not applied because another entry failed"
+	case errors.As(err, &validationError):
+		return http.StatusBadRequest, "invalid_input", validationError.Error()
+	case errors.Is(err, ErrorNotFound):
+		return http.StatusNotFound, "not_found", "item not found"
+	}
+	if duplicate, ok := DuplicateFieldOf(err); ok {
+		return http.StatusConflict, "conflict", duplicate.Message
+	}
+	for _, reason := range invalidInputReasons {
+		if errors.Is(err, reason.err) {
+			return http.StatusBadRequest, reason.code, reason.message
+		}
+	}
+	return http.StatusBadRequest, "invalid_input", "invalid input data"
+}
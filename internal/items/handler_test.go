@@ -1,27 +1,71 @@
 package items_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Lelo88/catalog-api-golang/internal/httpx"
 	"github.com/Lelo88/catalog-api-golang/internal/items"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
 type stubService struct {
-	createFn func(ctx context.Context, in items.CreateItemInput) (items.Item, error)
-	listFn   func(ctx context.Context, page, limit int, query string) ([]items.Item, int, error)
-	getFn    func(ctx context.Context, id string) (items.Item, error)
-	updateFn func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error)
-	deleteFn func(ctx context.Context, id string) error
+	createFn       func(ctx context.Context, in items.CreateItemInput) (items.Item, error)
+	listFn         func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error)
+	afterFn        func(ctx context.Context, after items.CreatedAtID, limit int, filter items.ListFilter) (items.ListPage, error)
+	countFn        func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error)
+	exportFn       func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error
+	versionFn      func(ctx context.Context) (items.CollectionVersion, error)
+	getFn          func(ctx context.Context, id string) (items.Item, error)
+	slugFn         func(ctx context.Context, slug string) (items.Item, error)
+	skuFn          func(ctx context.Context, sku string) (items.Item, error)
+	barcodeFn      func(ctx context.Context, barcode string) (items.Item, error)
+	relatedFn      func(ctx context.Context, id string, limit int) ([]items.Item, error)
+	suggestFn      func(ctx context.Context, prefix string, limit int) ([]items.Suggestion, error)
+	didYouMeanFn   func(ctx context.Context, query string) (string, error)
+	replaceFn      func(ctx context.Context, id string, in items.ReplaceItemInput) (items.Item, error)
+	updateFn       func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error)
+	deleteFn       func(ctx context.Context, id string) error
+	purgeFn        func(ctx context.Context, id string) error
+	reserveFn      func(ctx context.Context, id string, in items.ReserveInput) (items.Reservation, error)
+	adjustFn       func(ctx context.Context, id string, in items.StockAdjustmentInput) (items.Item, error)
+	movementsFn    func(ctx context.Context, id string, page, limit int) (items.StockMovementPage, error)
+	historyFn      func(ctx context.Context, id string, filter items.PriceHistoryFilter, page, limit int) (items.PriceHistoryPage, error)
+	auditFn        func(ctx context.Context, id string, page, limit int) (items.AuditPage, error)
+	restockFn      func(ctx context.Context, page, limit int) (items.ListPage, error)
+	changesFn      func(ctx context.Context, after items.UpdatedAtID, limit int) (items.ChangesPage, error)
+	adjustPricesFn func(ctx context.Context, input items.PriceAdjustmentInput) (items.PriceAdjustmentResult, error)
+	releaseFn      func(ctx context.Context, id, reservationID string) error
+	deleteManyFn   func(ctx context.Context, ids []string) (items.BulkDeleteResult, error)
+	updateManyFn   func(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error)
+	updateEachFn   func(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error)
+	duplicateFn    func(ctx context.Context, id string, input items.DuplicateItemInput) (items.Item, error)
+	stateFn        func(ctx context.Context, id string, state items.ItemState) (items.Item, error)
+	patchFn        func(ctx context.Context, id string, operations []items.PatchOperation) (items.Item, error)
+	variantsFn     func(ctx context.Context, itemID string) ([]items.Variant, error)
+	variantFn      func(ctx context.Context, itemID, variantID string) (items.Variant, error)
+	scheduleFn     func(ctx context.Context, itemID, scheduleID string) (items.PriceSchedule, error)
+	refFn          func(ctx context.Context, itemID, system, externalID string) (items.ExternalRef, bool, error)
+	byRefFn        func(ctx context.Context, system, externalID string) (items.Item, error)
+	translateFn    func(ctx context.Context, itemID, tag string, in items.TranslationInput) (items.Translation, error)
+	localizeFn     func(ctx context.Context, tag string, list []items.Item) ([]items.Item, error)
 
 	createCalled bool
 	createInput  items.CreateItemInput
@@ -29,17 +73,109 @@ type stubService struct {
 	listCalled bool
 	listPage   int
 	listLimit  int
-	listQuery  string
+	listFilter items.ListFilter
 
-	getCalled bool
-	getID     string
+	afterCalled bool
+	afterCursor items.CreatedAtID
+	afterLimit  int
+
+	countCalled bool
+	countFilter items.ListFilter
+
+	getCalled  bool
+	getID      string
+	getSlug    string
+	getSKU     string
+	getBarcode string
+
+	relatedCalled bool
+	relatedLimit  int
+
+	suggestCalled bool
+	suggestPrefix string
+	suggestLimit  int
+
+	didYouMeanCalled bool
+	didYouMeanQuery  string
 
 	updateCalled bool
 	updateID     string
 	updateInput  items.UpdateItemInput
 
-	deleteCalled bool
-	deleteID     string
+	replaceCalled bool
+	replaceID     string
+	replaceInput  items.ReplaceItemInput
+
+	deleteCalled    bool
+	deleteID        string
+	deleteIfVersion *int
+
+	deleteManyCalled bool
+	deleteManyIDs    []string
+
+	purgeCalled bool
+	purgeID     string
+
+	reserveCalled bool
+	reserveInput  items.ReserveInput
+
+	adjustCalled bool
+	adjustInput  items.StockAdjustmentInput
+
+	movementsCalled bool
+	movementsPage   int
+	movementsLimit  int
+
+	changesCalled bool
+	changesAfter  items.UpdatedAtID
+	changesLimit  int
+
+	restockCalled bool
+	restockPage   int
+	restockLimit  int
+
+	adjustPricesInput items.PriceAdjustmentInput
+
+	historyFilter items.PriceHistoryFilter
+
+	releaseCalled        bool
+	releaseReservationID string
+
+	variantItemID       string
+	variantID           string
+	createVariantInput  items.CreateVariantInput
+	updateVariantInput  items.UpdateVariantInput
+	deleteVariantCalled bool
+
+	scheduleItemID       string
+	scheduleID           string
+	createScheduleInput  items.CreatePriceScheduleInput
+	deleteScheduleCalled bool
+
+	refItemID       string
+	refSystem       string
+	refExternalID   string
+	removeRefCalled bool
+
+	translationItemID string
+	translationLocale string
+	translationInput  items.TranslationInput
+	localizeTag       string
+
+	updateManyCalled  bool
+	updateManyEntries []items.BulkUpdateEntry
+	updateEachCalled  bool
+
+	duplicateCalled bool
+
+	stateID        string
+	state          items.ItemState
+	duplicateID    string
+	duplicateInput items.DuplicateItemInput
+
+	patchCalled     bool
+	patchOperations []items.PatchOperation
+	patchIfVersion  *int
 }
 
 func (service *stubService) Create(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
@@ -51,15 +187,49 @@ func (service *stubService) Create(ctx context.Context, in items.CreateItemInput
 	return items.Item{}, nil
 }
 
-func (service *stubService) List(ctx context.Context, page, limit int, query string) ([]items.Item, int, error) {
+func (service *stubService) List(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
 	service.listCalled = true
 	service.listPage = page
 	service.listLimit = limit
-	service.listQuery = query
+	service.listFilter = filter
 	if service.listFn != nil {
-		return service.listFn(ctx, page, limit, query)
+		return service.listFn(ctx, page, limit, filter)
+	}
+	return items.ListPage{}, nil
+}
+
+func (service *stubService) ListAfter(ctx context.Context, after items.CreatedAtID, limit int, filter items.ListFilter) (items.ListPage, error) {
+	service.afterCalled = true
+	service.afterCursor = after
+	service.afterLimit = limit
+	service.listFilter = filter
+	if service.afterFn != nil {
+		return service.afterFn(ctx, after, limit, filter)
+	}
+	return items.ListPage{}, nil
+}
+
+func (service *stubService) CollectionVersion(ctx context.Context) (items.CollectionVersion, error) {
+	if service.versionFn != nil {
+		return service.versionFn(ctx)
+	}
+	return items.CollectionVersion{}, nil
+}
+
+func (service *stubService) Export(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+	if service.exportFn != nil {
+		return service.exportFn(ctx, filter, fn)
+	}
+	return nil
+}
+
+func (service *stubService) Count(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+	service.countCalled = true
+	service.countFilter = filter
+	if service.countFn != nil {
+		return service.countFn(ctx, filter)
 	}
-	return nil, 0, nil
+	return items.ItemCount{}, nil
 }
 
 func (service *stubService) Get(ctx context.Context, id string) (items.Item, error) {
@@ -71,6 +241,62 @@ func (service *stubService) Get(ctx context.Context, id string) (items.Item, err
 	return items.Item{}, nil
 }
 
+func (service *stubService) GetBySKU(ctx context.Context, sku string) (items.Item, error) {
+	service.getCalled = true
+	service.getSKU = sku
+	if service.skuFn != nil {
+		return service.skuFn(ctx, sku)
+	}
+	return items.Item{}, nil
+}
+
+func (service *stubService) GetByBarcode(ctx context.Context, barcode string) (items.Item, error) {
+	service.getCalled = true
+	service.getBarcode = barcode
+	if service.barcodeFn != nil {
+		return service.barcodeFn(ctx, barcode)
+	}
+	return items.Item{}, nil
+}
+
+func (service *stubService) GetBySlug(ctx context.Context, slug string) (items.Item, error) {
+	service.getCalled = true
+	service.getSlug = slug
+	if service.slugFn != nil {
+		return service.slugFn(ctx, slug)
+	}
+	return items.Item{}, nil
+}
+
+func (service *stubService) Suggest(ctx context.Context, prefix string, limit int) ([]items.Suggestion, error) {
+	service.suggestCalled = true
+	service.suggestPrefix = prefix
+	service.suggestLimit = limit
+	if service.suggestFn != nil {
+		return service.suggestFn(ctx, prefix, limit)
+	}
+	return nil, nil
+}
+
+func (service *stubService) DidYouMean(ctx context.Context, query string) (string, error) {
+	service.didYouMeanCalled = true
+	service.didYouMeanQuery = query
+	if service.didYouMeanFn != nil {
+		return service.didYouMeanFn(ctx, query)
+	}
+	return "", nil
+}
+
+func (service *stubService) Related(ctx context.Context, id string, limit int) ([]items.Item, error) {
+	service.relatedCalled = true
+	service.getID = id
+	service.relatedLimit = limit
+	if service.relatedFn != nil {
+		return service.relatedFn(ctx, id, limit)
+	}
+	return nil, nil
+}
+
 func (service *stubService) Update(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
 	service.updateCalled = true
 	service.updateID = id
@@ -81,15 +307,326 @@ func (service *stubService) Update(ctx context.Context, id string, in items.Upda
 	return items.Item{}, nil
 }
 
-func (service *stubService) Delete(ctx context.Context, id string) error {
+func (service *stubService) Replace(ctx context.Context, id string, in items.ReplaceItemInput) (items.Item, error) {
+	service.replaceCalled = true
+	service.replaceID = id
+	service.replaceInput = in
+	if service.replaceFn != nil {
+		return service.replaceFn(ctx, id, in)
+	}
+	return items.Item{ID: id, Name: in.Name, Description: in.Description, Price: in.Price, Stock: in.Stock}, nil
+}
+
+func (service *stubService) Delete(ctx context.Context, id string, ifVersion *int) (items.Item, error) {
 	service.deleteCalled = true
 	service.deleteID = id
+	service.deleteIfVersion = ifVersion
 	if service.deleteFn != nil {
-		return service.deleteFn(ctx, id)
+		if err := service.deleteFn(ctx, id); err != nil {
+			return items.Item{}, err
+		}
+	}
+	return items.Item{ID: id}, nil
+}
+
+func (service *stubService) Purge(ctx context.Context, id string) error {
+	service.purgeCalled = true
+	service.purgeID = id
+	if service.purgeFn != nil {
+		return service.purgeFn(ctx, id)
+	}
+	return nil
+}
+
+func (service *stubService) AdjustStock(ctx context.Context, id string, in items.StockAdjustmentInput) (items.Item, error) {
+	service.adjustCalled = true
+	service.adjustInput = in
+	if service.adjustFn != nil {
+		return service.adjustFn(ctx, id, in)
+	}
+	return items.Item{ID: id, Stock: in.Delta, Version: 2}, nil
+}
+
+func (service *stubService) StockMovements(ctx context.Context, id string, page, limit int) (items.StockMovementPage, error) {
+	service.movementsCalled = true
+	service.movementsPage = page
+	service.movementsLimit = limit
+	if service.movementsFn != nil {
+		return service.movementsFn(ctx, id, page, limit)
+	}
+	return items.StockMovementPage{}, nil
+}
+
+func (service *stubService) RestockNeeded(ctx context.Context, page, limit int) (items.ListPage, error) {
+	service.restockCalled = true
+	service.restockPage = page
+	service.restockLimit = limit
+	if service.restockFn != nil {
+		return service.restockFn(ctx, page, limit)
+	}
+	return items.ListPage{}, nil
+}
+
+func (service *stubService) Changes(ctx context.Context, after items.UpdatedAtID, limit int) (items.ChangesPage, error) {
+	service.changesCalled = true
+	service.changesAfter = after
+	service.changesLimit = limit
+	if service.changesFn != nil {
+		return service.changesFn(ctx, after, limit)
+	}
+	return items.ChangesPage{Next: after}, nil
+}
+
+func (service *stubService) AdjustPrices(ctx context.Context, input items.PriceAdjustmentInput) (items.PriceAdjustmentResult, error) {
+	service.adjustPricesInput = input
+	if service.adjustPricesFn != nil {
+		return service.adjustPricesFn(ctx, input)
+	}
+	return items.PriceAdjustmentResult{DryRun: input.DryRun}, nil
+}
+
+func (service *stubService) PriceHistory(ctx context.Context, id string, filter items.PriceHistoryFilter, page, limit int) (items.PriceHistoryPage, error) {
+	service.historyFilter = filter
+	service.movementsPage = page
+	service.movementsLimit = limit
+	if service.historyFn != nil {
+		return service.historyFn(ctx, id, filter, page, limit)
+	}
+	return items.PriceHistoryPage{}, nil
+}
+
+func (service *stubService) Audit(ctx context.Context, id string, page, limit int) (items.AuditPage, error) {
+	service.movementsPage = page
+	service.movementsLimit = limit
+	if service.auditFn != nil {
+		return service.auditFn(ctx, id, page, limit)
+	}
+	return items.AuditPage{}, nil
+}
+
+func (service *stubService) Reserve(ctx context.Context, id string, in items.ReserveInput) (items.Reservation, error) {
+	service.reserveCalled = true
+	service.reserveInput = in
+	if service.reserveFn != nil {
+		return service.reserveFn(ctx, id, in)
+	}
+	return items.Reservation{ID: "res-1", ItemID: id, Quantity: in.Quantity}, nil
+}
+
+func (service *stubService) Release(ctx context.Context, id, reservationID string) error {
+	service.releaseCalled = true
+	service.releaseReservationID = reservationID
+	if service.releaseFn != nil {
+		return service.releaseFn(ctx, id, reservationID)
+	}
+	return nil
+}
+
+func (service *stubService) Variants(ctx context.Context, itemID string) ([]items.Variant, error) {
+	service.variantItemID = itemID
+	if service.variantsFn != nil {
+		return service.variantsFn(ctx, itemID)
+	}
+	return nil, nil
+}
+
+// CreateVariant, UpdateVariant y DeleteVariant comparten variantFn (variantID vacío en el alta).
+func (service *stubService) CreateVariant(ctx context.Context, itemID string, in items.CreateVariantInput) (items.Variant, error) {
+	service.variantItemID = itemID
+	service.createVariantInput = in
+	if service.variantFn != nil {
+		return service.variantFn(ctx, itemID, "")
+	}
+	return items.Variant{ID: "variant-1", ItemID: itemID, SKU: in.SKU, Stock: in.Stock}, nil
+}
+
+func (service *stubService) UpdateVariant(ctx context.Context, itemID, variantID string, in items.UpdateVariantInput) (items.Variant, error) {
+	service.variantItemID = itemID
+	service.variantID = variantID
+	service.updateVariantInput = in
+	if service.variantFn != nil {
+		return service.variantFn(ctx, itemID, variantID)
+	}
+	return items.Variant{ID: variantID, ItemID: itemID}, nil
+}
+
+func (service *stubService) DeleteVariant(ctx context.Context, itemID, variantID string) error {
+	service.variantItemID = itemID
+	service.variantID = variantID
+	service.deleteVariantCalled = true
+	if service.variantFn != nil {
+		_, err := service.variantFn(ctx, itemID, variantID)
+		return err
+	}
+	return nil
+}
+
+func (service *stubService) PriceSchedules(ctx context.Context, itemID string) ([]items.PriceSchedule, error) {
+	service.scheduleItemID = itemID
+	if service.scheduleFn != nil {
+		_, err := service.scheduleFn(ctx, itemID, "")
+		return nil, err
+	}
+	return nil, nil
+}
+
+// CreatePriceSchedule y DeletePriceSchedule comparten scheduleFn (scheduleID vacío en el alta).
+func (service *stubService) CreatePriceSchedule(ctx context.Context, itemID string, in items.CreatePriceScheduleInput) (items.PriceSchedule, error) {
+	service.scheduleItemID = itemID
+	service.createScheduleInput = in
+	if service.scheduleFn != nil {
+		return service.scheduleFn(ctx, itemID, "")
+	}
+	return items.PriceSchedule{ID: "schedule-1", ItemID: itemID, NewPrice: in.NewPrice, EffectiveAt: in.EffectiveAt}, nil
+}
+
+func (service *stubService) DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error {
+	service.scheduleItemID = itemID
+	service.scheduleID = scheduleID
+	service.deleteScheduleCalled = true
+	if service.scheduleFn != nil {
+		_, err := service.scheduleFn(ctx, itemID, scheduleID)
+		return err
+	}
+	return nil
+}
+
+func (service *stubService) GetByExternalRef(ctx context.Context, system, externalID string) (items.Item, error) {
+	service.getCalled = true
+	service.refSystem = system
+	service.refExternalID = externalID
+	if service.byRefFn != nil {
+		return service.byRefFn(ctx, system, externalID)
+	}
+	return items.Item{ID: "id-1", Name: "Phone", Version: 1}, nil
+}
+
+// AddExternalRef y RemoveExternalRef comparten refFn, que recibe la ref del body o del path.
+func (service *stubService) AddExternalRef(ctx context.Context, itemID string, in items.ExternalRefInput) (items.ExternalRef, bool, error) {
+	service.refItemID = itemID
+	service.refSystem = in.System
+	service.refExternalID = in.ExternalID
+	if service.refFn != nil {
+		return service.refFn(ctx, itemID, in.System, in.ExternalID)
+	}
+	return items.ExternalRef{System: in.System, ExternalID: in.ExternalID}, true, nil
+}
+
+func (service *stubService) RemoveExternalRef(ctx context.Context, itemID, system, externalID string) error {
+	service.refItemID = itemID
+	service.refSystem = system
+	service.refExternalID = externalID
+	service.removeRefCalled = true
+	if service.refFn != nil {
+		_, _, err := service.refFn(ctx, itemID, system, externalID)
+		return err
 	}
 	return nil
 }
 
+func (service *stubService) Locales() []string {
+	return []string{"en", "es", "pt"}
+}
+
+func (service *stubService) Translations(ctx context.Context, itemID string) ([]items.Translation, error) {
+	service.translationItemID = itemID
+	if service.translateFn != nil {
+		translation, err := service.translateFn(ctx, itemID, "", items.TranslationInput{})
+		if err != nil {
+			return nil, err
+		}
+		return []items.Translation{translation}, nil
+	}
+	return []items.Translation{}, nil
+}
+
+func (service *stubService) PutTranslation(ctx context.Context, itemID, tag string, in items.TranslationInput) (items.Translation, error) {
+	service.translationItemID = itemID
+	service.translationLocale = tag
+	service.translationInput = in
+	if service.translateFn != nil {
+		return service.translateFn(ctx, itemID, tag, in)
+	}
+	return items.Translation{Locale: tag, Name: in.Name, Description: in.Description}, nil
+}
+
+// Localize devuelve los items sin tocar salvo que el test defina localizeFn.
+func (service *stubService) Localize(ctx context.Context, tag string, list []items.Item) ([]items.Item, error) {
+	service.localizeTag = tag
+	if service.localizeFn != nil {
+		return service.localizeFn(ctx, tag, list)
+	}
+	return list, nil
+}
+
+func (service *stubService) UpdateEach(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error) {
+	service.updateEachCalled = true
+	if service.updateEachFn != nil {
+		return service.updateEachFn(ctx, entries)
+	}
+	return nil, errors.New("unexpected UpdateEach call")
+}
+
+func (service *stubService) UpdateMany(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error) {
+	service.updateManyCalled = true
+	service.updateManyEntries = entries
+	if service.updateManyFn != nil {
+		return service.updateManyFn(ctx, entries)
+	}
+	results := make([]items.BulkUpdateResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, items.BulkUpdateResult{ID: entry.ID, Item: items.Item{ID: entry.ID}})
+	}
+	return results, nil
+}
+
+func (service *stubService) Duplicate(ctx context.Context, id string, input items.DuplicateItemInput) (items.Item, error) {
+	service.duplicateCalled = true
+	service.duplicateID = id
+	service.duplicateInput = input
+	if service.duplicateFn != nil {
+		return service.duplicateFn(ctx, id, input)
+	}
+	return items.Item{ID: "copy-id", Name: "Phone (copy)", Price: "1.00"}, nil
+}
+
+// Publish y Unpublish comparten stateFn, que recibe el estado pedido.
+func (service *stubService) Publish(ctx context.Context, id string) (items.Item, error) {
+	return service.changeState(ctx, id, items.StatePublished)
+}
+
+func (service *stubService) Unpublish(ctx context.Context, id string) (items.Item, error) {
+	return service.changeState(ctx, id, items.StateDraft)
+}
+
+func (service *stubService) changeState(ctx context.Context, id string, state items.ItemState) (items.Item, error) {
+	service.stateID = id
+	service.state = state
+	if service.stateFn != nil {
+		return service.stateFn(ctx, id, state)
+	}
+	return items.Item{ID: id, Name: "Phone", Price: "1.00", State: state, Version: 2}, nil
+}
+
+func (service *stubService) DeleteMany(ctx context.Context, ids []string) (items.BulkDeleteResult, error) {
+	service.deleteManyCalled = true
+	service.deleteManyIDs = ids
+	if service.deleteManyFn != nil {
+		return service.deleteManyFn(ctx, ids)
+	}
+	return items.BulkDeleteResult{Deleted: len(ids), Missing: []string{}}, nil
+}
+
+func (service *stubService) ApplyJSONPatch(ctx context.Context, id string, operations []items.PatchOperation, ifVersion *int) (items.Item, error) {
+	service.patchCalled = true
+	service.patchOperations = operations
+	service.patchIfVersion = ifVersion
+	if service.patchFn != nil {
+		return service.patchFn(ctx, id, operations)
+	}
+	return items.Item{}, nil
+}
+
 func TestHandler_Create(t *testing.T) {
 	t.Run("invalid json", func(t *testing.T) {
 		service := &stubService{}
@@ -145,6 +682,25 @@ func TestHandler_Create(t *testing.T) {
 		require.Equal(t, "conflict", resp.Error.Code)
 	})
 
+	t.Run("pagination too deep", func(t *testing.T) {
+		service := &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, items.ErrorPaginationTooDeep
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?page=5000000&limit=100", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "pagination_too_deep", resp.Error.Code)
+		require.Contains(t, resp.Error.Message, "cursor")
+	})
+
 	t.Run("internal error", func(t *testing.T) {
 		service := &stubService{
 			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
@@ -179,6 +735,7 @@ func TestHandler_Create(t *testing.T) {
 		handler.Create(rec, req)
 
 		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/items/id-1", rec.Header().Get("Location"))
 		resp := decodeResponse(t, rec)
 		data := asMap(t, resp.Data)
 		require.Equal(t, "id-1", data["id"])
@@ -187,6 +744,185 @@ func TestHandler_Create(t *testing.T) {
 	})
 }
 
+func TestHandler_InvalidInputReasons(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{"bare sentinel keeps invalid_input", items.ErrorInvalidInput, "invalid_input"},
+		{"name", items.ErrorInvalidName, "invalid_name"},
+		{"price", items.ErrorInvalidPrice, "invalid_price"},
+		{"stock", items.ErrorInvalidStock, "invalid_stock"},
+		{"wrapped reason", fmt.Errorf("validating: %w", items.ErrorInvalidPrice), "invalid_price"},
+		{"validation error", &items.ValidationError{Field: "name", Message: "name contains a blocked word"}, "invalid_input"},
+	}
+
+	for _, tt := range tests {
+		t.Run("create "+tt.name, func(t *testing.T) {
+			service := &stubService{
+				createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+					return items.Item{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Phone","price":"10.00","stock":1}`))
+			rec := httptest.NewRecorder()
+
+			handler.Create(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			resp := decodeResponse(t, rec)
+			require.Equal(t, tt.wantCode, resp.Error.Code)
+			require.NotEmpty(t, resp.Error.Message)
+		})
+
+		t.Run("patch "+tt.name, func(t *testing.T) {
+			service := &stubService{
+				updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+					return items.Item{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			id := "550e8400-e29b-41d4-a716-446655440000"
+			req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"price":"x"}`))
+			rec := httptest.NewRecorder()
+			req = withURLParam(req, "id", id)
+
+			handler.Patch(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			resp := decodeResponse(t, rec)
+			require.Equal(t, tt.wantCode, resp.Error.Code)
+		})
+	}
+}
+
+func TestHandler_ValidationErrorDetails(t *testing.T) {
+	service := &stubService{
+		createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+			return items.Item{}, &items.ValidationError{Field: "name", Message: "name contains a blocked word"}
+		},
+	}
+	handler := items.NewHandler(service)
+
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Replica","price":"10.00","stock":1}`))
+	rec := httptest.NewRecorder()
+
+	handler.Create(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	resp := decodeResponse(t, rec)
+	require.Equal(t, "invalid_input", resp.Error.Code)
+	require.Equal(t, []httpx.ErrorDetail{{Field: "name", Message: "name contains a blocked word"}}, resp.Error.Details)
+}
+
+func TestHandler_ListETag(t *testing.T) {
+	version := items.CollectionVersion{LastUpdatedAt: time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), Count: 3, Today: "2024-05-01"}
+	newService := func() *stubService {
+		return &stubService{
+			versionFn: func(ctx context.Context) (items.CollectionVersion, error) {
+				return version, nil
+			},
+		}
+	}
+	list := func(service *stubService, target string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).List(rec, req)
+		return rec
+	}
+
+	t.Run("weak etag from version and normalized query", func(t *testing.T) {
+		first := list(newService(), "/items?query=phone&limit=5", nil)
+		reordered := list(newService(), "/items?limit=5&query=phone", nil)
+		other := list(newService(), "/items?query=phone&limit=6", nil)
+
+		require.Equal(t, http.StatusOK, first.Code)
+		etag := first.Header().Get("ETag")
+		require.True(t, strings.HasPrefix(etag, `W/"`), etag)
+		require.Equal(t, etag, reordered.Header().Get("ETag"))
+		require.NotEqual(t, etag, other.Header().Get("ETag"))
+	})
+
+	t.Run("mutation changes the etag", func(t *testing.T) {
+		before := list(newService(), "/items", nil).Header().Get("ETag")
+
+		service := newService()
+		service.versionFn = func(ctx context.Context) (items.CollectionVersion, error) {
+			return items.CollectionVersion{LastUpdatedAt: version.LastUpdatedAt, Count: version.Count - 1}, nil
+		}
+		after := list(service, "/items", nil).Header().Get("ETag")
+
+		require.NotEqual(t, before, after)
+	})
+
+	t.Run("a new day changes the etag when expired items are excluded", func(t *testing.T) {
+		nextDay := func(target string) string {
+			service := newService()
+			service.versionFn = func(ctx context.Context) (items.CollectionVersion, error) {
+				return items.CollectionVersion{LastUpdatedAt: version.LastUpdatedAt, Count: version.Count, Today: "2024-05-02"}, nil
+			}
+			return list(service, target, nil).Header().Get("ETag")
+		}
+
+		// El listado por defecto deja afuera los vencidos: a medianoche puede vencer un item sin que cambie ninguna fila.
+		require.NotEqual(t, list(newService(), "/items", nil).Header().Get("ETag"), nextDay("/items"))
+		require.NotEqual(t, list(newService(), "/items?status=all&exclude_expired=true", nil).Header().Get("ETag"),
+			nextDay("/items?status=all&exclude_expired=true"))
+		require.Equal(t, list(newService(), "/items?exclude_expired=false", nil).Header().Get("ETag"), nextDay("/items?exclude_expired=false"))
+	})
+
+	t.Run("matching if-none-match skips the list query", func(t *testing.T) {
+		etag := list(newService(), "/items?query=phone", nil).Header().Get("ETag")
+		service := newService()
+
+		rec := list(service, "/items?query=phone", map[string]string{"If-None-Match": etag})
+
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Equal(t, etag, rec.Header().Get("ETag"))
+		require.Zero(t, rec.Body.Len())
+		require.False(t, service.listCalled)
+	})
+
+	t.Run("stale if-none-match runs the list", func(t *testing.T) {
+		service := newService()
+
+		rec := list(service, "/items", map[string]string{"If-None-Match": `W/"stale"`})
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listCalled)
+	})
+
+	t.Run("fuzzy opts out", func(t *testing.T) {
+		rec := list(newService(), "/items?query=keybord&fuzzy=true", nil)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("ETag"))
+		require.Equal(t, "fuzzy", rec.Header().Get("X-ETag-Skipped"))
+	})
+
+	t.Run("version failure still serves the list", func(t *testing.T) {
+		service := &stubService{
+			versionFn: func(ctx context.Context) (items.CollectionVersion, error) {
+				return items.CollectionVersion{}, errors.New("boom")
+			},
+		}
+
+		rec := list(service, "/items", map[string]string{"If-None-Match": "*"})
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("ETag"))
+		require.Equal(t, "version_unavailable", rec.Header().Get("X-ETag-Skipped"))
+		require.True(t, service.listCalled)
+	})
+}
+
 func TestHandler_List(t *testing.T) {
 	t.Run("invalid pagination value", func(t *testing.T) {
 		service := &stubService{}
@@ -205,8 +941,8 @@ func TestHandler_List(t *testing.T) {
 
 	t.Run("invalid input from service", func(t *testing.T) {
 		service := &stubService{
-			listFn: func(ctx context.Context, page, limit int, query string) ([]items.Item, int, error) {
-				return nil, 0, items.ErrorInvalidInput
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, items.ErrorInvalidInput
 			},
 		}
 		handler := items.NewHandler(service)
@@ -223,8 +959,8 @@ func TestHandler_List(t *testing.T) {
 
 	t.Run("internal error", func(t *testing.T) {
 		service := &stubService{
-			listFn: func(ctx context.Context, page, limit int, query string) ([]items.Item, int, error) {
-				return nil, 0, errors.New("boom")
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, errors.New("boom")
 			},
 		}
 		handler := items.NewHandler(service)
@@ -241,8 +977,8 @@ func TestHandler_List(t *testing.T) {
 
 	t.Run("success with defaults and trimmed query", func(t *testing.T) {
 		service := &stubService{
-			listFn: func(ctx context.Context, page, limit int, query string) ([]items.Item, int, error) {
-				return []items.Item{{ID: "id-1"}}, 1, nil
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: "id-1"}}, Total: 1}, nil
 			},
 		}
 		handler := items.NewHandler(service)
@@ -256,7 +992,8 @@ func TestHandler_List(t *testing.T) {
 		require.True(t, service.listCalled)
 		require.Equal(t, 1, service.listPage)
 		require.Equal(t, 20, service.listLimit)
-		require.Equal(t, "phone", service.listQuery)
+		require.Equal(t, "phone", service.listFilter.Query)
+		require.Equal(t, items.MatchContains, service.listFilter.Match)
 
 		resp := decodeResponse(t, rec)
 		data := asMap(t, resp.Data)
@@ -266,366 +1003,5192 @@ func TestHandler_List(t *testing.T) {
 		require.Equal(t, json.Number("1"), pagination["page"])
 		require.Equal(t, json.Number("20"), pagination["limit"])
 		require.Equal(t, json.Number("1"), pagination["total"])
+		filters := asMap(t, data["filters"])
+		require.Equal(t, "phone", filters["query"])
 	})
 
-	t.Run("limit capped", func(t *testing.T) {
+	t.Run("estimated total", func(t *testing.T) {
 		service := &stubService{
-			listFn: func(ctx context.Context, page, limit int, query string) ([]items.Item, int, error) {
-				return []items.Item{}, 0, nil
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: "id-1"}}, Total: 2000000, TotalIsEstimate: true}, nil
 			},
 		}
 		handler := items.NewHandler(service)
 
-		req := httptest.NewRequest(http.MethodGet, "/items?page=1&limit=500", nil)
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
 		rec := httptest.NewRecorder()
 
 		handler.List(rec, req)
 
 		require.Equal(t, http.StatusOK, rec.Code)
-		require.True(t, service.listCalled)
-		require.Equal(t, 100, service.listLimit)
+		pagination := asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"])
+		require.Equal(t, true, pagination["total_is_estimate"])
+		require.Equal(t, json.Number("2000000"), pagination["total"])
 	})
-}
 
-func TestHandler_GetByID(t *testing.T) {
-	t.Run("invalid id", func(t *testing.T) {
+	t.Run("exact total is not flagged", func(t *testing.T) {
 		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		req := httptest.NewRequest(http.MethodGet, "/items/not-uuid", nil)
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", "not-uuid")
 
-		handler.GetByID(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusBadRequest, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "invalid_id", resp.Error.Code)
-		require.False(t, service.getCalled)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotContains(t, asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"]), "total_is_estimate")
 	})
 
-	t.Run("not found", func(t *testing.T) {
-		service := &stubService{
-			getFn: func(ctx context.Context, id string) (items.Item, error) {
-				return items.Item{}, items.ErrorNotFound
-			},
+	t.Run("page math", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			target         string
+			total          int
+			returned       int
+			wantTotalPages int
+			wantHasNext    bool
+			wantHasPrev    bool
+		}{
+			{"empty catalog", "/items?limit=10", 0, 0, 0, false, false},
+			{"first of many", "/items?limit=10", 25, 10, 3, true, false},
+			{"middle", "/items?page=2&limit=10", 25, 10, 3, true, true},
+			{"last partial", "/items?page=3&limit=10", 25, 5, 3, false, true},
+			{"exact multiple", "/items?page=2&limit=10", 20, 10, 2, false, true},
+			{"beyond last", "/items?page=9&limit=10", 25, 0, 3, false, true},
 		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service := &stubService{
+					listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+						if tt.returned == 0 {
+							return items.ListPage{Total: tt.total}, nil
+						}
+						return items.ListPage{Items: make([]items.Item, tt.returned), Total: tt.total}, nil
+					},
+				}
+				handler := items.NewHandler(service)
+
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, http.StatusOK, rec.Code)
+				data := asMap(t, decodeResponse(t, rec).Data)
+				require.Len(t, asSlice(t, data["items"]), tt.returned)
+				pagination := asMap(t, data["pagination"])
+				require.Equal(t, json.Number(strconv.Itoa(tt.wantTotalPages)), pagination["total_pages"])
+				require.Equal(t, tt.wantHasNext, pagination["has_next"])
+				require.Equal(t, tt.wantHasPrev, pagination["has_prev"])
+			})
+		}
+	})
+
+	t.Run("match mode is passed to service", func(t *testing.T) {
+		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?query=Cable+HDMI&match=PREFIX", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.GetByID(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, http.StatusOK, rec.Code)
+		// Sin ?status= el listado es el de los items a la venta: activos, publicados y sin vencer.
+		excludeExpired := true
+		require.Equal(t, items.ListFilter{Query: "Cable HDMI", Match: items.MatchPrefix, Status: items.StatusActive, State: items.StatePublished, ExcludeExpired: &excludeExpired}, service.listFilter)
 		resp := decodeResponse(t, rec)
-		require.Equal(t, "not_found", resp.Error.Code)
+		filters := asMap(t, asMap(t, resp.Data)["filters"])
+		require.Equal(t, "prefix", filters["match"])
 	})
 
-	t.Run("internal error", func(t *testing.T) {
+	t.Run("invalid match mode", func(t *testing.T) {
 		service := &stubService{
-			getFn: func(ctx context.Context, id string) (items.Item, error) {
-				return items.Item{}, errors.New("boom")
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, items.ErrorInvalidMatch
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?query=cable&match=fuzzy", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.GetByID(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
 		resp := decodeResponse(t, rec)
-		require.Equal(t, "internal_error", resp.Error.Code)
+		require.Equal(t, "invalid_match", resp.Error.Code)
+		require.Equal(t, items.MatchMode("fuzzy"), service.listFilter.Match)
 	})
 
-	t.Run("success", func(t *testing.T) {
-		service := &stubService{
-			getFn: func(ctx context.Context, id string) (items.Item, error) {
-				return items.Item{ID: id, Name: "Phone"}, nil
-			},
-		}
+	t.Run("sort is passed to service", func(t *testing.T) {
+		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?sort=stock,+-price", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.GetByID(rec, req)
+		handler.List(rec, req)
 
 		require.Equal(t, http.StatusOK, rec.Code)
-		resp := decodeResponse(t, rec)
-		data := asMap(t, resp.Data)
-		require.Equal(t, id, data["id"])
-		require.True(t, service.getCalled)
-		require.Equal(t, id, service.getID)
+		require.Equal(t, []items.SortKey{"stock", "-price"}, service.listFilter.Sort)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "stock,-price", asMap(t, data["filters"])["sort"])
 	})
-}
 
-func TestHandler_Patch(t *testing.T) {
-	t.Run("invalid id", func(t *testing.T) {
-		service := &stubService{}
+	t.Run("invalid sort is rejected before the service", func(t *testing.T) {
+		for _, sort := range []string{"weight", "price,-price", "price,,stock", "stock,"} {
+			t.Run(sort, func(t *testing.T) {
+				service := &stubService{}
+				handler := items.NewHandler(service)
+
+				req := httptest.NewRequest(http.MethodGet, "/items?sort="+url.QueryEscape(sort), nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, "invalid_sort", decodeResponse(t, rec).Error.Code)
+				require.False(t, service.listCalled)
+			})
+		}
+	})
+
+	t.Run("invalid sort from service", func(t *testing.T) {
+		service := &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, items.ErrorInvalidSort
+			},
+		}
 		handler := items.NewHandler(service)
 
-		req := httptest.NewRequest(http.MethodPatch, "/items/not-uuid", strings.NewReader(`{"name":"x"}`))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?sort=stock", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", "not-uuid")
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
 		require.Equal(t, http.StatusBadRequest, rec.Code)
 		resp := decodeResponse(t, rec)
-		require.Equal(t, "invalid_id", resp.Error.Code)
-		require.False(t, service.updateCalled)
+		require.Equal(t, "invalid_sort", resp.Error.Code)
 	})
 
-	t.Run("invalid json", func(t *testing.T) {
+	t.Run("price range is passed to service", func(t *testing.T) {
 		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader("{"))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?query=cable&min_price=10.00&max_price=99.99", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusBadRequest, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "invalid_json", resp.Error.Code)
-		require.False(t, service.updateCalled)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "10.00", service.listFilter.MinPrice)
+		require.Equal(t, "99.99", service.listFilter.MaxPrice)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "10.00", filters["min_price"])
+		require.Equal(t, "99.99", filters["max_price"])
 	})
 
-	t.Run("invalid json payload", func(t *testing.T) {
+	t.Run("search fields are passed to service", func(t *testing.T) {
 		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"stock":"abc"}`))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?query=leather&search_fields=name,+description", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusBadRequest, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "invalid_json", resp.Error.Code)
-		require.False(t, service.updateCalled)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []string{"name", "description"}, service.listFilter.SearchFields)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, []any{"name", "description"}, filters["search_fields"])
 	})
 
-	t.Run("invalid input", func(t *testing.T) {
+	t.Run("identifier search keeps the ranking of the service", func(t *testing.T) {
+		exact := items.Item{ID: uuid.NewString(), Name: "Keyboard"}
+		byName := items.Item{ID: uuid.NewString(), Name: "kb-001 replacement keycaps"}
 		service := &stubService{
-			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
-				return items.Item{}, items.ErrorInvalidInput
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{exact, byName}, Total: 2}, nil
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":""}`))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?query=kb-001&search_fields=name,sku,barcode", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusBadRequest, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "invalid_input", resp.Error.Code)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []string{"name", "sku", "barcode"}, service.listFilter.SearchFields)
+		listed := asSlice(t, asMap(t, decodeResponse(t, rec).Data)["items"])
+		require.Len(t, listed, 2)
+		require.Equal(t, exact.ID, asMap(t, listed[0])["id"])
+		require.Equal(t, byName.ID, asMap(t, listed[1])["id"])
 	})
 
-	t.Run("not found", func(t *testing.T) {
-		service := &stubService{
-			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
-				return items.Item{}, items.ErrorNotFound
-			},
-		}
+	t.Run("search fields all is passed to service", func(t *testing.T) {
+		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":"New"}`))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?query=7790001000012&search_fields=all", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusNotFound, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "not_found", resp.Error.Code)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []string{"all"}, service.listFilter.SearchFields)
 	})
 
-	t.Run("duplicate name", func(t *testing.T) {
+	t.Run("cursor", func(t *testing.T) {
+		createdAt := time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)
+		cursorID := uuid.NewString()
+		cursor := base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + cursorID))
+		last := items.Item{ID: uuid.NewString(), CreatedAt: createdAt.Add(-time.Second)}
 		service := &stubService{
-			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
-				return items.Item{}, items.ErrorDuplicateName
+			afterFn: func(ctx context.Context, after items.CreatedAtID, limit int, filter items.ListFilter) (items.ListPage, error) {
+				cursor := items.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID}
+				return items.ListPage{Items: []items.Item{{ID: uuid.NewString(), CreatedAt: createdAt}, last}, Total: 5, HasMore: true, Cursor: &cursor}, nil
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":"New"}`))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?cursor="+cursor+"&limit=2", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusConflict, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "conflict", resp.Error.Code)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.afterCalled)
+		require.False(t, service.listCalled)
+		require.Equal(t, cursorID, service.afterCursor.ID)
+		require.True(t, createdAt.Equal(service.afterCursor.CreatedAt))
+		require.Equal(t, 2, service.afterLimit)
+
+		paginationBlock := asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"])
+		require.NotContains(t, paginationBlock, "page")
+		require.Equal(t, json.Number("3"), paginationBlock["total_pages"])
+		require.Equal(t, true, paginationBlock["has_next"])
+		require.Equal(t, true, paginationBlock["has_prev"])
+		require.Equal(t, json.Number("5"), paginationBlock["total"])
+		next, err := base64.RawURLEncoding.DecodeString(paginationBlock["next_cursor"].(string))
+		require.NoError(t, err)
+		require.Equal(t, last.CreatedAt.Format(time.RFC3339Nano)+"|"+last.ID, string(next))
 	})
 
-	t.Run("internal error", func(t *testing.T) {
+	t.Run("cursor keeps the side of the identifier ranking", func(t *testing.T) {
+		// La primera página de una búsqueda por SKU termina en la coincidencia exacta: el cursor lo
+		// recuerda y la página siguiente lo recibe de vuelta.
+		hit := items.Item{ID: uuid.NewString(), CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
 		service := &stubService{
-			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
-				return items.Item{}, errors.New("boom")
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{hit}, Total: 3, Cursor: &items.CreatedAtID{CreatedAt: hit.CreatedAt, ID: hit.ID, Ranked: true}}, nil
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":"New"}`))
-		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?query=kb-001&limit=1", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		cursor := asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"])["next_cursor"].(string)
 
-		handler.Patch(rec, req)
+		rec = httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?query=kb-001&limit=1&cursor="+cursor, nil))
 
-		require.Equal(t, http.StatusInternalServerError, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "internal_error", resp.Error.Code)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.afterCalled)
+		require.Equal(t, items.CreatedAtID{CreatedAt: hit.CreatedAt, ID: hit.ID, Ranked: true}, service.afterCursor)
 	})
 
-	t.Run("success with description null", func(t *testing.T) {
+	t.Run("last cursor page has no next_cursor", func(t *testing.T) {
+		cursor := base64.RawURLEncoding.EncodeToString([]byte(time.Now().UTC().Format(time.RFC3339Nano) + "|" + uuid.NewString()))
 		service := &stubService{
-			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
-				return items.Item{ID: id, Name: "Updated"}, nil
+			afterFn: func(ctx context.Context, after items.CreatedAtID, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: uuid.NewString()}}, Total: 5}, nil
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		body := `{"description":null,"price":"10.00"}`
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(body))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?cursor="+cursor, nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
 		require.Equal(t, http.StatusOK, rec.Code)
-		require.True(t, service.updateCalled)
-		require.Equal(t, id, service.updateID)
-		require.True(t, service.updateInput.DescriptionPresent)
-		require.Nil(t, service.updateInput.Description)
-		require.NotNil(t, service.updateInput.Price)
+		paginationBlock := asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"])
+		require.NotContains(t, paginationBlock, "next_cursor")
+		require.Equal(t, false, paginationBlock["has_next"])
 	})
 
-	t.Run("success without description", func(t *testing.T) {
+	t.Run("next_cursor in offset mode", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			target   string
+			returned int
+			total    int
+			cursor   bool
+			wantNext bool
+		}{
+			{"more pages", "/items?limit=2", 2, 3, true, true},
+			{"last page", "/items?page=2&limit=2", 1, 3, true, false},
+			{"order without cursor", "/items?limit=2&sort=price", 2, 3, false, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service := &stubService{
+					listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+						list := make([]items.Item, 0, tt.returned)
+						for range tt.returned {
+							list = append(list, items.Item{ID: uuid.NewString(), CreatedAt: time.Now()})
+						}
+						result := items.ListPage{Items: list, Total: tt.total}
+						if tt.cursor {
+							last := list[len(list)-1]
+							result.Cursor = &items.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID}
+						}
+						return result, nil
+					},
+				}
+				handler := items.NewHandler(service)
+
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, http.StatusOK, rec.Code)
+				paginationBlock := asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"])
+				_, hasNext := paginationBlock["next_cursor"]
+				require.Equal(t, tt.wantNext, hasNext)
+			})
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		validCursor := base64.RawURLEncoding.EncodeToString([]byte(time.Now().UTC().Format(time.RFC3339Nano) + "|" + uuid.NewString()))
+		tests := []struct {
+			name     string
+			target   string
+			wantCode string
+		}{
+			{"not base64", "/items?cursor=!!!", "invalid_cursor"},
+			{"no separator", "/items?cursor=" + base64.RawURLEncoding.EncodeToString([]byte("abc")), "invalid_cursor"},
+			{"bad timestamp", "/items?cursor=" + base64.RawURLEncoding.EncodeToString([]byte("yesterday|"+uuid.NewString())), "invalid_cursor"},
+			{"bad id", "/items?cursor=" + base64.RawURLEncoding.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)+"|123")), "invalid_cursor"},
+			{"combined with page", "/items?page=1&cursor=" + validCursor, "invalid_pagination"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service := &stubService{}
+				handler := items.NewHandler(service)
+
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, tt.wantCode, decodeResponse(t, rec).Error.Code)
+				require.False(t, service.afterCalled)
+				require.False(t, service.listCalled)
+			})
+		}
+	})
+
+	t.Run("cursor with custom sort", func(t *testing.T) {
+		cursor := base64.RawURLEncoding.EncodeToString([]byte(time.Now().UTC().Format(time.RFC3339Nano) + "|" + uuid.NewString()))
 		service := &stubService{
-			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
-				return items.Item{ID: id, Name: "Updated"}, nil
+			afterFn: func(ctx context.Context, after items.CreatedAtID, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, items.ErrorCursorUnsupported
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		body := `{"name":"Updated"}`
-		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(body))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/items?sort=price&cursor="+cursor, nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Patch(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusOK, rec.Code)
-		require.True(t, service.updateCalled)
-		require.False(t, service.updateInput.DescriptionPresent)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_cursor", decodeResponse(t, rec).Error.Code)
 	})
-}
 
-func TestHandler_Delete(t *testing.T) {
-	t.Run("invalid id", func(t *testing.T) {
+	t.Run("name_eq", func(t *testing.T) {
+		tests := []struct {
+			target         string
+			wantIgnoreCase bool
+		}{
+			{"/items?name_eq=Phone%20X", false},
+			{"/items?name_eq=Phone%20X&case_sensitive=true", false},
+			{"/items?name_eq=Phone%20X&case_sensitive=false", true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.target, func(t *testing.T) {
+				service := &stubService{}
+				handler := items.NewHandler(service)
+
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, http.StatusOK, rec.Code)
+				require.Equal(t, "Phone X", service.listFilter.NameEq)
+				require.Equal(t, tt.wantIgnoreCase, service.listFilter.NameEqIgnoreCase)
+				filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+				require.Equal(t, "Phone X", filters["name_eq"])
+				require.Equal(t, !tt.wantIgnoreCase, filters["case_sensitive"])
+			})
+		}
+	})
+
+	t.Run("invalid case_sensitive", func(t *testing.T) {
 		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		req := httptest.NewRequest(http.MethodDelete, "/items/not-uuid", nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?name_eq=x&case_sensitive=nope", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", "not-uuid")
 
-		handler.Delete(rec, req)
+		handler.List(rec, req)
 
 		require.Equal(t, http.StatusBadRequest, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "invalid_id", resp.Error.Code)
-		require.False(t, service.deleteCalled)
+		require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
 	})
 
-	t.Run("not found", func(t *testing.T) {
+	t.Run("fuzzy", func(t *testing.T) {
+		score := 0.53
 		service := &stubService{
-			deleteFn: func(ctx context.Context, id string) error {
-				return items.ErrorNotFound
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: "id-1", Name: "Keyboard", Price: "10.00", Similarity: &score}}, Total: 1}, nil
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodDelete, "/items/"+id, nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?query=keybord&fuzzy=true", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Delete(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusNotFound, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "not_found", resp.Error.Code)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listFilter.Fuzzy)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		listed := data["items"].([]any)
+		require.Equal(t, json.Number("0.53"), asMap(t, listed[0])["similarity"])
+		require.Equal(t, true, asMap(t, data["filters"])["fuzzy"])
 	})
 
-	t.Run("internal error", func(t *testing.T) {
+	t.Run("invalid fuzzy", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?query=x&fuzzy=yes", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.listCalled)
+	})
+
+	t.Run("fuzzy unavailable", func(t *testing.T) {
 		service := &stubService{
-			deleteFn: func(ctx context.Context, id string) error {
-				return errors.New("boom")
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, items.ErrorFuzzyUnavailable
 			},
 		}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodDelete, "/items/"+id, nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?query=keybord&fuzzy=true", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Delete(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusInternalServerError, rec.Code)
-		resp := decodeResponse(t, rec)
-		require.Equal(t, "internal_error", resp.Error.Code)
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+		require.Equal(t, "fuzzy_unavailable", decodeResponse(t, rec).Error.Code)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("stock filters are passed to service", func(t *testing.T) {
 		service := &stubService{}
 		handler := items.NewHandler(service)
 
-		id := "550e8400-e29b-41d4-a716-446655440000"
-		req := httptest.NewRequest(http.MethodDelete, "/items/"+id, nil)
+		req := httptest.NewRequest(http.MethodGet, "/items?query=cable&in_stock=true&stock_gte=1&stock_lte=5&page=2", nil)
 		rec := httptest.NewRecorder()
-		req = withURLParam(req, "id", id)
 
-		handler.Delete(rec, req)
+		handler.List(rec, req)
 
-		require.Equal(t, http.StatusNoContent, rec.Code)
-		require.True(t, service.deleteCalled)
-		require.Equal(t, id, service.deleteID)
-		require.Empty(t, rec.Body.String())
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 2, service.listPage)
+		require.Equal(t, "cable", service.listFilter.Query)
+		require.True(t, *service.listFilter.InStock)
+		require.Equal(t, 1, *service.listFilter.StockGTE)
+		require.Equal(t, 5, *service.listFilter.StockLTE)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, true, filters["in_stock"])
+		require.Equal(t, json.Number("5"), filters["stock_lte"])
+	})
+
+	t.Run("sku filter is passed to service", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?sku=kb-001", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "KB-001", service.listFilter.SKU)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "KB-001", filters["sku"])
+	})
+
+	t.Run("category filter is passed to service", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?category_id=550e8400-e29b-41d4-a716-446655440000", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "550e8400-e29b-41d4-a716-446655440000", service.listFilter.CategoryID)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "550e8400-e29b-41d4-a716-446655440000", filters["category_id"])
+	})
+
+	t.Run("brand filter is passed to service", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?brand_id=6ba7b810-9dad-11d1-80b4-00c04fd430c8", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", service.listFilter.BrandID)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", filters["brand_id"])
+	})
+
+	t.Run("invalid stock filters", func(t *testing.T) {
+		tests := []struct {
+			query   string
+			field   string
+			message string
+		}{
+			{"in_stock=maybe", "in_stock", "in_stock must be true or false"},
+			{"stock_lte=abc", "stock_lte", "stock_lte must be a non-negative integer"},
+			{"stock_gte=-1", "stock_gte", "stock_gte must be a non-negative integer"},
+			{"stock_gte=1.5", "stock_gte", "stock_gte must be a non-negative integer"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				service := &stubService{}
+				handler := items.NewHandler(service)
+
+				req := httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+				resp := decodeResponse(t, rec)
+				require.Equal(t, "invalid_filter", resp.Error.Code)
+				require.Equal(t, []httpx.ErrorDetail{{Field: tt.field, Message: tt.message}}, resp.Error.Details)
+				require.False(t, service.listCalled)
+			})
+		}
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		service := &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{}, &items.FilterError{Field: "min_price", Message: "min_price must be less than or equal to max_price"}
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?min_price=100&max_price=10", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "min_price", Message: "min_price must be less than or equal to max_price"}}, resp.Error.Details)
+	})
+
+	t.Run("limit capped", func(t *testing.T) {
+		service := &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{}}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?page=1&limit=500", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listCalled)
+		require.Equal(t, 100, service.listLimit)
+		require.Equal(t, "100", rec.Header().Get("X-Limit-Capped"))
+
+		resp := decodeResponse(t, rec)
+		data := asMap(t, resp.Data)
+		pagination := asMap(t, data["pagination"])
+		require.Equal(t, json.Number("100"), pagination["limit"])
+	})
+
+	t.Run("limit within max is not flagged", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?limit=100", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 100, service.listLimit)
+		require.Empty(t, rec.Header().Get("X-Limit-Capped"))
+	})
+
+	t.Run("custom page sizes", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			target     string
+			strict     bool
+			wantStatus int
+			wantLimit  int
+			wantCapped string
+		}{
+			{"default limit", "/items", false, http.StatusOK, 10, ""},
+			{"at custom max", "/items?limit=25", false, http.StatusOK, 25, ""},
+			{"capped to custom max", "/items?limit=26", false, http.StatusOK, 25, "25"},
+			{"strict above custom max", "/items?limit=26", true, http.StatusBadRequest, 0, ""},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service := &stubService{}
+				handler := items.NewHandler(service, items.WithPageSizes(10, 25), items.WithStrictPagination(tt.strict))
+
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+				rec := httptest.NewRecorder()
+
+				handler.List(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+				require.Equal(t, tt.wantCapped, rec.Header().Get("X-Limit-Capped"))
+				if tt.wantStatus != http.StatusOK {
+					require.Equal(t, "limit must be at most 25", decodeResponse(t, rec).Error.Message)
+					return
+				}
+				require.Equal(t, tt.wantLimit, service.listLimit)
+				pagination := asMap(t, asMap(t, decodeResponse(t, rec).Data)["pagination"])
+				require.Equal(t, json.Number(strconv.Itoa(tt.wantLimit)), pagination["limit"])
+			})
+		}
+	})
+
+	t.Run("strict pagination rejects limit above max", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service, items.WithStrictPagination(true))
+
+		req := httptest.NewRequest(http.MethodGet, "/items?page=1&limit=500", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "limit_too_large", resp.Error.Code)
+		require.Equal(t, "limit must be at most 100", resp.Error.Message)
+		require.False(t, service.listCalled)
+		require.Empty(t, rec.Header().Get("X-Limit-Capped"))
+	})
+
+	t.Run("strict pagination accepts limit at max", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service, items.WithStrictPagination(true))
+
+		req := httptest.NewRequest(http.MethodGet, "/items?limit=100", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 100, service.listLimit)
+	})
+
+	t.Run("page zero is invalid pagination", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?page=0", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_pagination", resp.Error.Code)
+		require.False(t, service.listCalled)
+	})
+}
+
+func TestHandler_ListHighlights(t *testing.T) {
+	description := "Keyboard <b>&</b> mouse"
+	service := &stubService{
+		listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+			return items.ListPage{Items: []items.Item{{ID: "id-1", Name: "<i>Keyboard</i>", Description: &description}}, Total: 1}, nil
+		},
+	}
+	list := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).List(rec, req)
+		return rec
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		rec := list("/items?query=keyboard")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		item := asMap(t, asSlice(t, asMap(t, decodeResponse(t, rec).Data)["items"])[0])
+		require.NotContains(t, item, "highlights")
+	})
+
+	t.Run("escaped fields with marked matches", func(t *testing.T) {
+		rec := list("/items?query=keyboard&search_fields=name,description&highlight=true")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		item := asMap(t, asSlice(t, data["items"])[0])
+		require.Equal(t, map[string]any{
+			"name":        "&lt;i&gt;<em>Keyboard</em>&lt;/i&gt;",
+			"description": "<em>Keyboard</em> &lt;b&gt;&amp;&lt;/b&gt; mouse",
+		}, asMap(t, item["highlights"]))
+		require.Equal(t, "<i>Keyboard</i>", item["name"])
+		require.Equal(t, true, asMap(t, data["filters"])["highlight"])
+		require.True(t, service.listFilter.Highlight)
+	})
+
+	t.Run("without query there is nothing to highlight", func(t *testing.T) {
+		rec := list("/items?highlight=true")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		item := asMap(t, asSlice(t, asMap(t, decodeResponse(t, rec).Data)["items"])[0])
+		require.NotContains(t, item, "highlights")
+	})
+
+	t.Run("invalid highlight", func(t *testing.T) {
+		rec := list("/items?query=keyboard&highlight=maybe")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_ListDidYouMean(t *testing.T) {
+	keyboard := func(ctx context.Context, query string) (string, error) {
+		return "Keyboard", nil
+	}
+	list := func(handler func(http.ResponseWriter, *http.Request), target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	t.Run("zero results suggest a name", func(t *testing.T) {
+		service := &stubService{didYouMeanFn: keyboard}
+
+		rec := list(items.NewHandler(service).List, "/items?query=keybord")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "Keyboard", resp.Meta.Suggestion)
+		require.Empty(t, asSlice(t, asMap(t, resp.Data)["items"]))
+		require.Equal(t, "keybord", service.didYouMeanQuery)
+	})
+
+	t.Run("results skip the lookup", func(t *testing.T) {
+		service := &stubService{
+			didYouMeanFn: keyboard,
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: "id-1", Name: "Keyboard"}}, Total: 1}, nil
+			},
+		}
+
+		rec := list(items.NewHandler(service).List, "/items?query=keyboard")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, decodeResponse(t, rec).Meta.Suggestion)
+		require.False(t, service.didYouMeanCalled)
+	})
+
+	t.Run("a page past the end is not a zero-result search", func(t *testing.T) {
+		service := &stubService{
+			didYouMeanFn: keyboard,
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Total: 3}, nil
+			},
+		}
+
+		rec := list(items.NewHandler(service).List, "/items?query=keyboard&page=9")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, service.didYouMeanCalled)
+	})
+
+	t.Run("without query there is nothing to suggest", func(t *testing.T) {
+		service := &stubService{didYouMeanFn: keyboard}
+
+		rec := list(items.NewHandler(service).List, "/items")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, service.didYouMeanCalled)
+	})
+
+	t.Run("the trash does not suggest", func(t *testing.T) {
+		service := &stubService{didYouMeanFn: keyboard}
+
+		rec := list(items.NewHandler(service).Trash, "/items/trash?query=keybord")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, service.didYouMeanCalled)
+	})
+
+	t.Run("a failed lookup still returns the empty page", func(t *testing.T) {
+		service := &stubService{
+			didYouMeanFn: func(ctx context.Context, query string) (string, error) {
+				return "", errors.New("db down")
+			},
+		}
+
+		rec := list(items.NewHandler(service).List, "/items?query=keybord")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, decodeResponse(t, rec).Meta.Suggestion)
+	})
+}
+
+func TestHandler_Trash(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 2, 9, 30, 0, 0, time.UTC)
+	newService := func() *stubService {
+		return &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: "id-1", Name: "Phone", Price: "10.00", DeletedAt: &deletedAt}}, Total: 1}, nil
+			},
+			versionFn: func(ctx context.Context) (items.CollectionVersion, error) {
+				return items.CollectionVersion{Count: 3}, nil
+			},
+		}
+	}
+
+	t.Run("lists deleted items with the list params", func(t *testing.T) {
+		service := newService()
+
+		req := httptest.NewRequest(http.MethodGet, "/items/trash?query=phone&page=1&limit=5", nil)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Trash(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.ScopeDeleted, service.listFilter.Scope)
+		require.Equal(t, "phone", service.listFilter.Query)
+
+		resp := decodeResponse(t, rec)
+		data := resp.Data.(map[string]any)
+		first := data["items"].([]any)[0].(map[string]any)
+		require.Equal(t, "2024-05-02T09:30:00Z", first["deleted_at"])
+	})
+
+	t.Run("list stays on active items", func(t *testing.T) {
+		service := newService()
+
+		req := httptest.NewRequest(http.MethodGet, "/items?query=phone", nil)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.ScopeActive, service.listFilter.Scope)
+	})
+
+	t.Run("etag differs from the list with the same query", func(t *testing.T) {
+		listRec := httptest.NewRecorder()
+		items.NewHandler(newService()).List(listRec, httptest.NewRequest(http.MethodGet, "/items?query=phone", nil))
+		trashRec := httptest.NewRecorder()
+		items.NewHandler(newService()).Trash(trashRec, httptest.NewRequest(http.MethodGet, "/items/trash?query=phone", nil))
+
+		require.NotEmpty(t, trashRec.Header().Get("ETag"))
+		require.NotEqual(t, listRec.Header().Get("ETag"), trashRec.Header().Get("ETag"))
+	})
+}
+
+func TestHandler_UnknownQueryParams(t *testing.T) {
+	t.Run("typos are rejected", func(t *testing.T) {
+		service := &stubService{}
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service).List(rec, httptest.NewRequest(http.MethodGet, "/items?serach=phone&limit=5", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "unknown_parameter", resp.Error.Code)
+		require.Equal(t, "unknown query parameters: serach", resp.Error.Message)
+		require.Equal(t, "serach", resp.Error.Details[0].Field)
+		require.False(t, service.listCalled)
+	})
+
+	t.Run("filters, attributes and cache busters pass", func(t *testing.T) {
+		service := &stubService{}
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service).List(rec, httptest.NewRequest(http.MethodGet, "/items?query=phone&attr.color=red&fields=id&page=2&_=1700000000&utm_source=mail", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listCalled)
+	})
+
+	t.Run("each endpoint has its own parameters", func(t *testing.T) {
+		for _, target := range []struct {
+			path    string
+			handler func(*items.Handler) http.HandlerFunc
+		}{
+			{"/items/count?page=2", func(handler *items.Handler) http.HandlerFunc { return handler.Count }},
+			{"/items/export?limit=10", func(handler *items.Handler) http.HandlerFunc { return handler.Export }},
+			{"/items/changes?query=phone", func(handler *items.Handler) http.HandlerFunc { return handler.Changes }},
+			{"/items/suggest?q=ph&fields=id", func(handler *items.Handler) http.HandlerFunc { return handler.Suggest }},
+			{"/items/expiring?day=10", func(handler *items.Handler) http.HandlerFunc { return handler.Expiring }},
+		} {
+			rec := httptest.NewRecorder()
+
+			target.handler(items.NewHandler(&stubService{}))(rec, httptest.NewRequest(http.MethodGet, target.path, nil))
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, target.path)
+			require.Equal(t, "unknown_parameter", decodeResponse(t, rec).Error.Code, target.path)
+		}
+	})
+
+	t.Run("escape hatch", func(t *testing.T) {
+		service := &stubService{}
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service, items.WithAllowUnknownQueryParams(true)).List(rec, httptest.NewRequest(http.MethodGet, "/items?serach=phone", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listCalled)
+	})
+}
+
+func TestHandler_Count(t *testing.T) {
+	t.Run("same filters as the list", func(t *testing.T) {
+		service := &stubService{
+			countFn: func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+				return items.ItemCount{Total: 42}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/count?query=phone&min_price=10&in_stock=true", nil)
+		rec := httptest.NewRecorder()
+
+		handler.Count(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, map[string]any{"total": json.Number("42")}, asMap(t, resp.Data))
+		require.True(t, service.countCalled)
+		require.False(t, service.listCalled)
+
+		listReq := httptest.NewRequest(http.MethodGet, "/items?query=phone&min_price=10&in_stock=true", nil)
+		handler.List(httptest.NewRecorder(), listReq)
+		require.Equal(t, service.listFilter, service.countFilter)
+	})
+
+	t.Run("estimated total", func(t *testing.T) {
+		service := &stubService{
+			countFn: func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+				return items.ItemCount{Total: 1000, TotalIsEstimate: true}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Count(rec, httptest.NewRequest(http.MethodGet, "/items/count", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, map[string]any{"total": json.Number("1000"), "total_is_estimate": true}, asMap(t, resp.Data))
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Count(rec, httptest.NewRequest(http.MethodGet, "/items/count?in_stock=maybe", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", resp.Error.Code)
+		require.False(t, service.countCalled)
+	})
+
+	t.Run("service errors map like the list", func(t *testing.T) {
+		service := &stubService{
+			countFn: func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+				return items.ItemCount{}, items.ErrorInvalidMatch
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Count(rec, httptest.NewRequest(http.MethodGet, "/items/count?match=fuzzy", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_match", resp.Error.Code)
+	})
+}
+
+func TestHandler_LastModified(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	updatedAt := time.Date(2024, 5, 1, 13, 0, 0, 700_000_000, time.UTC)
+	newService := func() *stubService {
+		return &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone", UpdatedAt: updatedAt}, nil
+			},
+		}
+	}
+	get := func(handler *items.Handler, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.GetByID(rec, withURLParam(req, "id", id))
+		return rec
+	}
+
+	t.Run("sets last-modified truncated to seconds", func(t *testing.T) {
+		rec := get(items.NewHandler(newService()), nil)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "Wed, 01 May 2024 13:00:00 GMT", rec.Header().Get("Last-Modified"))
+	})
+
+	t.Run("not modified since", func(t *testing.T) {
+		rec := get(items.NewHandler(newService()), map[string]string{"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT"})
+
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Equal(t, "Wed, 01 May 2024 13:00:00 GMT", rec.Header().Get("Last-Modified"))
+		require.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("modified since", func(t *testing.T) {
+		rec := get(items.NewHandler(newService()), map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:59:59 GMT"})
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "Phone", asMap(t, decodeResponse(t, rec).Data)["name"])
+	})
+
+	t.Run("if-none-match wins over if-modified-since", func(t *testing.T) {
+		rec := get(items.NewHandler(newService()), map[string]string{
+			"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT",
+			"If-None-Match":     `"other"`,
+		})
+
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestHandler_ItemVersion(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	newService := func() *stubService {
+		return &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone", Version: 3}, nil
+			},
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone", Version: 4}, nil
+			},
+		}
+	}
+	send := func(handler *items.Handler, method, contentType, body, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/items/"+id, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		switch method {
+		case http.MethodPatch:
+			handler.Patch(rec, req)
+		case http.MethodDelete:
+			handler.Delete(rec, req)
+		}
+		return rec
+	}
+
+	t.Run("get sets the version as etag", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(newService()).GetByID(rec, withURLParam(req, "id", id))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"3"`, rec.Header().Get("ETag"))
+		require.Equal(t, json.Number("3"), asMap(t, decodeResponse(t, rec).Data)["version"])
+	})
+
+	t.Run("get with matching if-none-match", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		req.Header.Set("If-None-Match", `"3"`)
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(newService()).GetByID(rec, withURLParam(req, "id", id))
+
+		require.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("patch passes if-match version", func(t *testing.T) {
+		service := newService()
+
+		rec := send(items.NewHandler(service), http.MethodPatch, "application/json", `{"stock":1}`, `"3"`)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 3, *service.updateInput.IfVersion)
+		require.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("patch without if-match keeps last write wins", func(t *testing.T) {
+		service := newService()
+
+		rec := send(items.NewHandler(service), http.MethodPatch, "application/json", `{"stock":1}`, "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Nil(t, service.updateInput.IfVersion)
+	})
+
+	t.Run("json patch passes if-match version", func(t *testing.T) {
+		service := newService()
+
+		rec := send(items.NewHandler(service), http.MethodPatch, "application/json-patch+json", `[{"op":"replace","path":"/stock","value":1}]`, `"3"`)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 3, *service.patchIfVersion)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		service := newService()
+		service.updateFn = func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+			return items.Item{}, items.ErrorVersionMismatch
+		}
+
+		rec := send(items.NewHandler(service), http.MethodPatch, "application/json", `{"stock":1}`, `"2"`)
+
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		require.Equal(t, "precondition_failed", decodeResponse(t, rec).Error.Code)
+	})
+
+	for _, ifMatch := range []string{`W/"3"`, `"3", "4"`, `"abc"`, `3`} {
+		t.Run("unmatchable if-match "+ifMatch, func(t *testing.T) {
+			service := newService()
+
+			rec := send(items.NewHandler(service), http.MethodPatch, "application/json", `{"stock":1}`, ifMatch)
+
+			require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+			require.False(t, service.updateCalled)
+		})
+	}
+
+	t.Run("any version", func(t *testing.T) {
+		service := newService()
+
+		rec := send(items.NewHandler(service), http.MethodPatch, "application/json", `{"stock":1}`, "*")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Nil(t, service.updateInput.IfVersion)
+	})
+
+	t.Run("delete passes if-match version", func(t *testing.T) {
+		service := newService()
+
+		rec := send(items.NewHandler(service), http.MethodDelete, "", "", `"3"`)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, 3, *service.deleteIfVersion)
+	})
+
+	t.Run("delete stale version", func(t *testing.T) {
+		service := newService()
+		service.deleteFn = func(ctx context.Context, id string) error {
+			return items.ErrorVersionMismatch
+		}
+
+		rec := send(items.NewHandler(service), http.MethodDelete, "", "", `"2"`)
+
+		require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("required if-match", func(t *testing.T) {
+		service := newService()
+		handler := items.NewHandler(service, items.WithRequireIfMatch(true))
+
+		patch := send(handler, http.MethodPatch, "application/json", `{"stock":1}`, "")
+		remove := send(handler, http.MethodDelete, "", "", "")
+
+		require.Equal(t, http.StatusPreconditionRequired, patch.Code)
+		require.Equal(t, "precondition_required", decodeResponse(t, patch).Error.Code)
+		require.Equal(t, http.StatusPreconditionRequired, remove.Code)
+		require.False(t, service.updateCalled)
+		require.False(t, service.deleteCalled)
+	})
+}
+
+func TestHandler_GetByID(t *testing.T) {
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/not-uuid", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", "not-uuid")
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_id", resp.Error.Code)
+		require.False(t, service.getCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "not_found", resp.Error.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{}, errors.New("boom")
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "internal_error", resp.Error.Code)
+	})
+
+	t.Run("no time left maps to timeout", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{}, items.ErrorTimeout
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "timeout", resp.Error.Code)
+	})
+
+	t.Run("client disconnect is not an internal error", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		ctx, cancel := context.WithCancel(context.Background())
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				cancel()
+				return items.Item{}, fmt.Errorf("query failed: %w", ctx.Err())
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, 499, rec.Code)
+		require.Empty(t, rec.Body.String())
+	})
+
+	t.Run("request deadline exceeded maps to timeout", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{}, fmt.Errorf("query failed: %w", context.DeadlineExceeded)
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "timeout", resp.Error.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		data := asMap(t, resp.Data)
+		require.Equal(t, id, data["id"])
+		require.True(t, service.getCalled)
+		require.Equal(t, id, service.getID)
+	})
+}
+
+func TestHandler_Replace(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	replace := func(service *stubService, itemID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/items/"+itemID, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Replace(rec, withURLParam(req, "id", itemID))
+		return rec
+	}
+
+	t.Run("missing description means null", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := replace(service, id, `{"id":"ignored","sku":"KB-001","name":"Phone","price":"10.00","stock":3}`)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, id, service.replaceID)
+		require.Equal(t, items.ReplaceItemInput{Name: "Phone", Price: "10.00", Stock: 3}, service.replaceInput)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "Phone", data["name"])
+		require.NotContains(t, data, "description")
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := replace(service, "nope", `{"name":"Phone","price":"10.00"}`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.replaceCalled)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		rec := replace(&stubService{}, id, `{`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+	})
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing name", items.ErrorInvalidName, http.StatusBadRequest, "invalid_name"},
+		{"missing price", items.ErrorInvalidPrice, http.StatusBadRequest, "invalid_price"},
+		{"not found", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+		{"name conflict", items.ErrorDuplicateName, http.StatusConflict, "conflict"},
+		{"slug conflict", items.ErrorDuplicateSlug, http.StatusConflict, "conflict"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				replaceFn: func(ctx context.Context, id string, in items.ReplaceItemInput) (items.Item, error) {
+					return items.Item{}, tt.err
+				},
+			}
+
+			rec := replace(service, id, `{"name":"Phone","price":"10.00"}`)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Equal(t, tt.wantCode, decodeResponse(t, rec).Error.Code)
+		})
+	}
+}
+
+func TestHandler_Related(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	related := func(service *stubService, options []items.HandlerOption, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service, options...).Related(rec, withURLParam(req, "id", id))
+		return rec
+	}
+
+	t.Run("plain array with the default limit", func(t *testing.T) {
+		service := &stubService{
+			relatedFn: func(ctx context.Context, id string, limit int) ([]items.Item, error) {
+				return []items.Item{{ID: "id-2", Name: "Keyboard Pro"}}, nil
+			},
+		}
+
+		rec := related(service, nil, "/items/"+id+"/related")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		list := asSlice(t, decodeResponse(t, rec).Data)
+		require.Len(t, list, 1)
+		require.Equal(t, "id-2", asMap(t, list[0])["id"])
+		require.Equal(t, id, service.getID)
+		require.Equal(t, 5, service.relatedLimit)
+	})
+
+	t.Run("no matches is an empty array", func(t *testing.T) {
+		rec := related(&stubService{}, nil, "/items/"+id+"/related?limit=3")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, asSlice(t, decodeResponse(t, rec).Data))
+	})
+
+	t.Run("limit above the cap", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := related(service, nil, "/items/"+id+"/related?limit=50")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 20, service.relatedLimit)
+		require.Equal(t, "20", rec.Header().Get("X-Limit-Capped"))
+	})
+
+	t.Run("limit above the cap in strict mode", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := related(service, []items.HandlerOption{items.WithStrictPagination(true)}, "/items/"+id+"/related?limit=50")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "limit_too_large", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.relatedCalled)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := related(service, nil, "/items/"+id+"/related?limit=0")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.False(t, service.relatedCalled)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		req := httptest.NewRequest(http.MethodGet, "/items/nope/related", nil)
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service).Related(rec, withURLParam(req, "id", "nope"))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.relatedCalled)
+	})
+
+	t.Run("missing base item", func(t *testing.T) {
+		service := &stubService{
+			relatedFn: func(ctx context.Context, id string, limit int) ([]items.Item, error) {
+				return nil, items.ErrorNotFound
+			},
+		}
+
+		rec := related(service, nil, "/items/"+id+"/related")
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("pg_trgm missing", func(t *testing.T) {
+		service := &stubService{
+			relatedFn: func(ctx context.Context, id string, limit int) ([]items.Item, error) {
+				return nil, items.ErrorFuzzyUnavailable
+			},
+		}
+
+		rec := related(service, nil, "/items/"+id+"/related")
+
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+		require.Equal(t, "fuzzy_unavailable", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_Suggest(t *testing.T) {
+	suggest := func(service *stubService, options []items.HandlerOption, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service, options...).Suggest(rec, req)
+		return rec
+	}
+
+	t.Run("id and name only with the default limit", func(t *testing.T) {
+		service := &stubService{
+			suggestFn: func(ctx context.Context, prefix string, limit int) ([]items.Suggestion, error) {
+				return []items.Suggestion{{ID: "id-1", Name: "Phone case"}}, nil
+			},
+		}
+
+		rec := suggest(service, nil, "/items/suggest?q=%20pho%20")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		list := asSlice(t, decodeResponse(t, rec).Data)
+		require.Len(t, list, 1)
+		require.Equal(t, map[string]any{"id": "id-1", "name": "Phone case"}, asMap(t, list[0]))
+		require.Equal(t, "pho", service.suggestPrefix)
+		require.Equal(t, 8, service.suggestLimit)
+	})
+
+	t.Run("no matches is an empty array", func(t *testing.T) {
+		rec := suggest(&stubService{}, nil, "/items/suggest?q=zz&limit=3")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, asSlice(t, decodeResponse(t, rec).Data))
+	})
+
+	t.Run("limit above the cap", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := suggest(service, nil, "/items/suggest?q=pho&limit=50")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 10, service.suggestLimit)
+		require.Equal(t, "10", rec.Header().Get("X-Limit-Capped"))
+	})
+
+	t.Run("limit above the cap in strict mode", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := suggest(service, []items.HandlerOption{items.WithStrictPagination(true)}, "/items/suggest?q=pho&limit=50")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "limit_too_large", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.suggestCalled)
+	})
+
+	errorTests := []struct {
+		name     string
+		target   string
+		wantCode string
+	}{
+		{"missing q", "/items/suggest", "invalid_filter"},
+		{"q too short", "/items/suggest?q=p", "invalid_filter"},
+		{"q too short after trimming", "/items/suggest?q=%20p%20", "invalid_filter"},
+		{"q too long", "/items/suggest?q=" + strings.Repeat("a", 101), "invalid_filter"},
+		{"invalid limit", "/items/suggest?q=pho&limit=0", "invalid_pagination"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{}
+
+			rec := suggest(service, nil, tt.target)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Equal(t, tt.wantCode, decodeResponse(t, rec).Error.Code)
+			require.False(t, service.suggestCalled)
+		})
+	}
+
+	t.Run("pg_trgm missing", func(t *testing.T) {
+		service := &stubService{
+			suggestFn: func(ctx context.Context, prefix string, limit int) ([]items.Suggestion, error) {
+				return nil, items.ErrorFuzzyUnavailable
+			},
+		}
+
+		rec := suggest(service, nil, "/items/suggest?q=pho")
+
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+		require.Equal(t, "fuzzy_unavailable", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_SKU(t *testing.T) {
+	t.Run("lookup", func(t *testing.T) {
+		service := &stubService{
+			skuFn: func(ctx context.Context, sku string) (items.Item, error) {
+				return items.Item{ID: "id-1", SKU: &sku}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/sku/KB-001", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "sku", "KB-001")
+
+		handler.GetBySKU(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "KB-001", asMap(t, resp.Data)["sku"])
+		require.Equal(t, "KB-001", service.getSKU)
+	})
+
+	t.Run("invalid sku", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/sku/KB%231", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "sku", "KB#1")
+
+		handler.GetBySKU(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_sku", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.getCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			skuFn: func(ctx context.Context, sku string) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/sku/KB-404", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "sku", "KB-404")
+
+		handler.GetBySKU(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("duplicate sku on create names the field", func(t *testing.T) {
+		service := &stubService{
+			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorDuplicateSKU
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Keyboard","sku":"KB-001","price":"10.00","stock":1}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "conflict", resp.Error.Code)
+		require.Len(t, resp.Error.Details, 1)
+		require.Equal(t, "sku", resp.Error.Details[0].Field)
+		require.Equal(t, "KB-001", *service.createInput.SKU)
+	})
+
+	t.Run("lookup is case insensitive", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/sku/kb-001", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "sku", "kb-001")
+
+		handler.GetBySKU(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "KB-001", service.getSKU)
+	})
+
+	t.Run("sku can be patched", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"sku":"KB-002"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "KB-002", *service.updateInput.SKU)
+	})
+
+	t.Run("duplicate sku on patch names the field", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorDuplicateSKU
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"sku":"KB-002"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "item sku already exists", resp.Error.Message)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "sku", Message: "another item already has this sku"}}, resp.Error.Details)
+	})
+}
+
+func TestHandler_Barcode(t *testing.T) {
+	t.Run("lookup", func(t *testing.T) {
+		service := &stubService{
+			barcodeFn: func(ctx context.Context, barcode string) (items.Item, error) {
+				return items.Item{ID: "id-1", Barcode: &barcode}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/barcode/4006381333931", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "code", "4006381333931")
+
+		handler.GetByBarcode(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "4006381333931", asMap(t, decodeResponse(t, rec).Data)["barcode"])
+		require.Equal(t, "4006381333931", service.getBarcode)
+	})
+
+	t.Run("invalid check digit", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/barcode/4006381333932", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "code", "4006381333932")
+
+		handler.GetByBarcode(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_barcode", resp.Error.Code)
+		require.Equal(t, "barcode check digit is invalid", resp.Error.Message)
+		require.False(t, service.getCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			barcodeFn: func(ctx context.Context, barcode string) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/barcode/036000291452", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "code", "036000291452")
+
+		handler.GetByBarcode(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("null on patch clears the barcode", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"barcode":null}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateInput.BarcodePresent)
+		require.Nil(t, service.updateInput.Barcode)
+	})
+
+	t.Run("duplicate barcode on patch names the field", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorDuplicateBarcode
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"barcode":"4006381333931"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "barcode", Message: "another item already has this barcode"}}, decodeResponse(t, rec).Error.Details)
+	})
+}
+
+func TestHandler_Category(t *testing.T) {
+	t.Run("unknown category on create is a bad request", func(t *testing.T) {
+		service := &stubService{
+			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorUnknownCategory
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Keyboard","sku":"KB-001","price":"10.00","stock":1,"category_id":"550e8400-e29b-41d4-a716-446655440000"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_category", decodeResponse(t, rec).Error.Code)
+		require.Equal(t, "550e8400-e29b-41d4-a716-446655440000", *service.createInput.CategoryID)
+	})
+
+	t.Run("the item embeds its category", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Category: &items.ItemCategory{ID: "category-1", Name: "Audio"}}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/items/"+id, nil), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, map[string]any{"id": "category-1", "name": "Audio"}, asMap(t, decodeResponse(t, rec).Data)["category"])
+	})
+}
+
+func TestHandler_Variants(t *testing.T) {
+	itemID := "11111111-1111-1111-1111-111111111111"
+	variantID := "22222222-2222-2222-2222-222222222222"
+	variantRequest := func(method, body string) *http.Request {
+		req := httptest.NewRequest(method, "/items/"+itemID+"/variants/"+variantID, strings.NewReader(body))
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", itemID)
+		routeCtx.URLParams.Add("vid", variantID)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+	}
+
+	t.Run("create", func(t *testing.T) {
+		service := &stubService{
+			variantFn: func(ctx context.Context, itemID, variantID string) (items.Variant, error) {
+				return items.Variant{ID: "22222222-2222-2222-2222-222222222222", ItemID: itemID, SKU: "TS-M"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/variants", strings.NewReader(`{"sku":"TS-M","price":"12.50","stock":3,"attributes":{"size":"M"}}`))
+		rec := httptest.NewRecorder()
+		handler.CreateVariant(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/items/"+itemID+"/variants/"+variantID, rec.Header().Get("Location"))
+		require.Equal(t, itemID, service.variantItemID)
+		require.Equal(t, "TS-M", service.createVariantInput.SKU)
+		require.Equal(t, "12.50", *service.createVariantInput.Price)
+		require.Equal(t, 3, service.createVariantInput.Stock)
+		require.Equal(t, map[string]string{"size": "M"}, service.createVariantInput.Attributes)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		service := &stubService{
+			variantsFn: func(ctx context.Context, itemID string) ([]items.Variant, error) {
+				return []items.Variant{{ID: variantID, ItemID: itemID, SKU: "TS-M"}}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+itemID+"/variants", nil)
+		rec := httptest.NewRecorder()
+		handler.Variants(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, decodeResponse(t, rec).Data, 1)
+	})
+
+	t.Run("null price inherits", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.UpdateVariant(rec, variantRequest(http.MethodPatch, `{"price":null,"stock":4}`))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, variantID, service.variantID)
+		require.True(t, service.updateVariantInput.PricePresent)
+		require.Nil(t, service.updateVariantInput.Price)
+		require.False(t, service.updateVariantInput.AttributesPresent)
+		require.Equal(t, 4, *service.updateVariantInput.Stock)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.DeleteVariant(rec, variantRequest(http.MethodDelete, ""))
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.True(t, service.deleteVariantCalled)
+	})
+
+	t.Run("invalid variant id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+itemID+"/variants/nope", nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", itemID)
+		routeCtx.URLParams.Add("vid", "nope")
+		rec := httptest.NewRecorder()
+		handler.DeleteVariant(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.deleteVariantCalled)
+	})
+
+	errorTests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"missing item", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+		{"missing variant", items.ErrorVariantNotFound, http.StatusNotFound, "not_found"},
+		{"duplicate sku", items.ErrorDuplicateVariantSKU, http.StatusConflict, "conflict"},
+		{"invalid sku", items.ErrorInvalidSKU, http.StatusBadRequest, "invalid_sku"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				variantFn: func(ctx context.Context, itemID, variantID string) (items.Variant, error) {
+					return items.Variant{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			rec := httptest.NewRecorder()
+			handler.UpdateVariant(rec, variantRequest(http.MethodPatch, `{"sku":"TS-M"}`))
+
+			require.Equal(t, tt.status, rec.Code)
+			require.Equal(t, tt.code, decodeResponse(t, rec).Error.Code)
+		})
+	}
+
+	t.Run("stock of an item with variants", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorStockManagedByVariants
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+itemID, strings.NewReader(`{"stock":9}`))
+		rec := httptest.NewRecorder()
+		handler.Patch(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "stock_managed_by_variants", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_PriceSchedules(t *testing.T) {
+	itemID := "11111111-1111-1111-1111-111111111111"
+	scheduleID := "33333333-3333-3333-3333-333333333333"
+	scheduleRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+itemID+"/price-schedules/"+id, nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", itemID)
+		routeCtx.URLParams.Add("sid", id)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+	}
+
+	t.Run("create", func(t *testing.T) {
+		service := &stubService{
+			scheduleFn: func(ctx context.Context, itemID, scheduleID string) (items.PriceSchedule, error) {
+				return items.PriceSchedule{ID: "33333333-3333-3333-3333-333333333333", ItemID: itemID, NewPrice: "19.99"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/price-schedules", strings.NewReader(`{"new_price":"19.99","effective_at":"2025-07-01T03:00:00Z"}`))
+		rec := httptest.NewRecorder()
+		handler.CreatePriceSchedule(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/items/"+itemID+"/price-schedules/"+scheduleID, rec.Header().Get("Location"))
+		require.Equal(t, itemID, service.scheduleItemID)
+		require.Equal(t, "19.99", service.createScheduleInput.NewPrice)
+		require.Equal(t, time.Date(2025, 7, 1, 3, 0, 0, 0, time.UTC), service.createScheduleInput.EffectiveAt)
+	})
+
+	t.Run("create with an invalid instant", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/price-schedules", strings.NewReader(`{"new_price":"19.99","effective_at":"tomorrow"}`))
+		rec := httptest.NewRecorder()
+		handler.CreatePriceSchedule(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+		require.Empty(t, service.scheduleItemID)
+	})
+
+	t.Run("list of an item without schedules", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+itemID+"/price-schedules", nil)
+		rec := httptest.NewRecorder()
+		handler.PriceSchedules(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []any{}, decodeResponse(t, rec).Data)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.DeletePriceSchedule(rec, scheduleRequest(scheduleID))
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, scheduleID, service.scheduleID)
+		require.True(t, service.deleteScheduleCalled)
+	})
+
+	t.Run("invalid schedule id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.DeletePriceSchedule(rec, scheduleRequest("nope"))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.deleteScheduleCalled)
+	})
+
+	errorTests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		field  string
+	}{
+		{"missing item", items.ErrorNotFound, http.StatusNotFound, "not_found", ""},
+		{"missing schedule", items.ErrorPriceScheduleNotFound, http.StatusNotFound, "not_found", ""},
+		{"same instant", items.ErrorDuplicatePriceSchedule, http.StatusConflict, "conflict", "effective_at"},
+		{"past instant", &items.ValidationError{Field: "effective_at", Message: "effective_at must be in the future"}, http.StatusBadRequest, "invalid_input", "effective_at"},
+		{"at or below the sale price", items.ErrorScheduledBelowSalePrice, http.StatusUnprocessableEntity, "below_sale_price", "new_price"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				scheduleFn: func(ctx context.Context, itemID, scheduleID string) (items.PriceSchedule, error) {
+					return items.PriceSchedule{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/price-schedules", strings.NewReader(`{"new_price":"19.99","effective_at":"2025-07-01T03:00:00Z"}`))
+			rec := httptest.NewRecorder()
+			handler.CreatePriceSchedule(rec, withURLParam(req, "id", itemID))
+
+			require.Equal(t, tt.status, rec.Code)
+			response := decodeResponse(t, rec)
+			require.Equal(t, tt.code, response.Error.Code)
+			if tt.field != "" {
+				require.Len(t, response.Error.Details, 1)
+				require.Equal(t, tt.field, response.Error.Details[0].Field)
+			}
+		})
+	}
+}
+
+func TestHandler_ExternalRefs(t *testing.T) {
+	itemID := "11111111-1111-1111-1111-111111111111"
+	refRequest := func(method, target, system, externalID string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", itemID)
+		routeCtx.URLParams.Add("system", system)
+		routeCtx.URLParams.Add("external_id", externalID)
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+	}
+
+	t.Run("add", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/refs", strings.NewReader(`{"system":"erp","external_id":"A/100"}`))
+		rec := httptest.NewRecorder()
+		handler.AddExternalRef(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/items/"+itemID+"/refs/erp/A%2F100", rec.Header().Get("Location"))
+		require.Equal(t, itemID, service.refItemID)
+		require.Equal(t, "A/100", service.refExternalID)
+	})
+
+	t.Run("adding an existing ref of the item is a 200", func(t *testing.T) {
+		service := &stubService{
+			refFn: func(ctx context.Context, itemID, system, externalID string) (items.ExternalRef, bool, error) {
+				return items.ExternalRef{System: system, ExternalID: externalID}, false, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/refs", strings.NewReader(`{"system":"erp","external_id":"A-100"}`))
+		rec := httptest.NewRecorder()
+		handler.AddExternalRef(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Location"))
+	})
+
+	t.Run("remove unescapes the path", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.RemoveExternalRef(rec, refRequest(http.MethodDelete, "/items/"+itemID+"/refs/ERP/A%2F100", "ERP", "A%2F100"))
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.True(t, service.removeRefCalled)
+		require.Equal(t, "erp", service.refSystem)
+		require.Equal(t, "A/100", service.refExternalID)
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.GetByExternalRef(rec, refRequest(http.MethodGet, "/items/by-ref/marketplace/MLA-1", "marketplace", "MLA-1"))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "marketplace", service.refSystem)
+		require.Equal(t, "MLA-1", service.refExternalID)
+		require.Equal(t, "id-1", asMap(t, decodeResponse(t, rec).Data)["id"])
+	})
+
+	t.Run("lookup with an invalid system", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.GetByExternalRef(rec, refRequest(http.MethodGet, "/items/by-ref/my%20erp/A-100", "my%20erp", "A-100"))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_ref", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.getCalled)
+	})
+
+	t.Run("lookup of an unknown ref", func(t *testing.T) {
+		service := &stubService{
+			byRefFn: func(ctx context.Context, system, externalID string) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.GetByExternalRef(rec, refRequest(http.MethodGet, "/items/by-ref/erp/missing", "erp", "missing"))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("conflict names the system", func(t *testing.T) {
+		service := &stubService{
+			refFn: func(ctx context.Context, itemID, system, externalID string) (items.ExternalRef, bool, error) {
+				return items.ExternalRef{}, false, &items.ExternalRefConflictError{System: "marketplace", ExternalID: "MLA-1"}
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/refs", strings.NewReader(`{"system":"marketplace","external_id":"MLA-1"}`))
+		rec := httptest.NewRecorder()
+		handler.AddExternalRef(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		response := decodeResponse(t, rec)
+		require.Equal(t, "conflict", response.Error.Code)
+		require.Equal(t, "external ref already exists in marketplace", response.Error.Message)
+		require.Equal(t, "external_id", response.Error.Details[0].Field)
+	})
+
+	errorTests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"missing item", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+		{"missing ref", items.ErrorExternalRefNotFound, http.StatusNotFound, "not_found"},
+		{"invalid system", &items.ValidationError{Field: "system", Message: "system must be 1 to 32 lowercase letters, digits, underscores or hyphens"}, http.StatusBadRequest, "invalid_input"},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				refFn: func(ctx context.Context, itemID, system, externalID string) (items.ExternalRef, bool, error) {
+					return items.ExternalRef{}, false, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			req := httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/refs", strings.NewReader(`{"system":"erp","external_id":"A-100"}`))
+			rec := httptest.NewRecorder()
+			handler.AddExternalRef(rec, withURLParam(req, "id", itemID))
+
+			require.Equal(t, tt.status, rec.Code)
+			require.Equal(t, tt.code, decodeResponse(t, rec).Error.Code)
+		})
+	}
+}
+
+func TestHandler_Translations(t *testing.T) {
+	itemID := "11111111-1111-1111-1111-111111111111"
+	spanish := func(ctx context.Context, tag string, list []items.Item) ([]items.Item, error) {
+		for index := range list {
+			list[index].Name = "Teclado"
+			list[index].Locale = tag
+		}
+		return list, nil
+	}
+
+	t.Run("list negotiates accept-language", func(t *testing.T) {
+		service := &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{{ID: itemID, Name: "Keyboard"}}, Total: 1}, nil
+			},
+			localizeFn: spanish,
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set("Accept-Language", "es-AR,es;q=0.9,en;q=0.5")
+		rec := httptest.NewRecorder()
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "es", service.localizeTag)
+		require.Equal(t, "es", rec.Header().Get("Content-Language"))
+		require.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+		resp := decodeResponse(t, rec)
+		first := asMap(t, asMap(t, resp.Data)["items"].([]any)[0])
+		require.Equal(t, "Teclado", first["name"])
+		require.Equal(t, "es", first["locale"])
+	})
+
+	t.Run("locale query param wins over the header", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?locale=PT", nil)
+		req.Header.Set("Accept-Language", "es")
+		rec := httptest.NewRecorder()
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "pt", service.localizeTag)
+	})
+
+	t.Run("without a supported language the response has no locale", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set("Accept-Language", "fr-FR")
+		rec := httptest.NewRecorder()
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, service.localizeTag)
+		require.Empty(t, rec.Header().Get("Content-Language"))
+	})
+
+	t.Run("the list etag depends on the language", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+		etagFor := func(language string) string {
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			req.Header.Set("Accept-Language", language)
+			rec := httptest.NewRecorder()
+			handler.List(rec, req)
+			return rec.Header().Get("ETag")
+		}
+
+		require.NotEqual(t, etagFor("es"), etagFor("en"))
+	})
+
+	t.Run("unsupported locale", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?locale=fr", nil)
+		rec := httptest.NewRecorder()
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "unsupported_locale", resp.Error.Code)
+		require.False(t, service.listCalled)
+	})
+
+	t.Run("search translations", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?query=teclado&search_translations=true", nil)
+		rec := httptest.NewRecorder()
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listFilter.SearchTranslations)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, true, asMap(t, asMap(t, resp.Data)["filters"])["search_translations"])
+	})
+
+	t.Run("invalid search translations", func(t *testing.T) {
+		handler := items.NewHandler(&stubService{})
+
+		req := httptest.NewRequest(http.MethodGet, "/items?search_translations=maybe", nil)
+		rec := httptest.NewRecorder()
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", resp.Error.Code)
+	})
+
+	t.Run("get by id is localized", func(t *testing.T) {
+		service := &stubService{localizeFn: spanish}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+itemID+"?locale=es", nil)
+		rec := httptest.NewRecorder()
+		handler.GetByID(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "es", rec.Header().Get("Content-Language"))
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "Teclado", asMap(t, resp.Data)["name"])
+		require.Equal(t, "es", asMap(t, resp.Data)["locale"])
+	})
+
+	t.Run("put", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPut, "/items/"+itemID+"/translations/es", strings.NewReader(`{"name":"Teclado","description":"Mecánico"}`))
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", itemID)
+		routeCtx.URLParams.Add("locale", "es")
+		rec := httptest.NewRecorder()
+		handler.PutTranslation(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, itemID, service.translationItemID)
+		require.Equal(t, "es", service.translationLocale)
+		require.Equal(t, "Teclado", service.translationInput.Name)
+		require.Equal(t, "Mecánico", *service.translationInput.Description)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+itemID+"/translations", nil)
+		rec := httptest.NewRecorder()
+		handler.Translations(rec, withURLParam(req, "id", itemID))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, itemID, service.translationItemID)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, []any{}, asMap(t, resp.Data)["translations"])
+	})
+
+	errorTests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"unsupported locale", items.ErrorUnsupportedLocale, http.StatusBadRequest, "unsupported_locale"},
+		{"blank name", items.ErrorInvalidName, http.StatusBadRequest, "invalid_name"},
+		{"name too long", &items.ValidationError{Field: "name", Message: "name must be at most 200 characters"}, http.StatusBadRequest, "invalid_input"},
+		{"missing item", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				translateFn: func(ctx context.Context, itemID, tag string, in items.TranslationInput) (items.Translation, error) {
+					return items.Translation{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			req := httptest.NewRequest(http.MethodPut, "/items/"+itemID+"/translations/fr", strings.NewReader(`{"name":"Clavier"}`))
+			rec := httptest.NewRecorder()
+			handler.PutTranslation(rec, withURLParam(req, "id", itemID))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			resp := decodeResponse(t, rec)
+			require.Equal(t, tt.wantCode, resp.Error.Code)
+		})
+	}
+}
+
+func TestHandler_TaxRate(t *testing.T) {
+	id := "11111111-1111-1111-1111-111111111111"
+
+	t.Run("get returns the net and gross prices", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Name: "Mouse", Price: "0.99", TaxRateBPS: 1900, PriceWithTax: "1.18", Currency: "USD"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.GetByID(rec, withURLParam(httptest.NewRequest(http.MethodGet, "/items/"+id, nil), "id", id))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "0.99", data["price"])
+		require.Equal(t, "1.18", data["price_with_tax"])
+		require.Equal(t, json.Number("1900"), data["tax_rate_bps"])
+	})
+
+	t.Run("create and patch pass the rate", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Create(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Mouse","sku":"MS-001","price":"10.00","tax_rate_bps":2100}`)))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, 2100, *service.createInput.TaxRateBPS)
+
+		rec = httptest.NewRecorder()
+		handler.Patch(rec, withURLParam(httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"tax_rate_bps":0}`)), "id", id))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 0, *service.updateInput.TaxRateBPS)
+	})
+
+	t.Run("patch rejects a null rate", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"tax_rate_bps":null}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec := httptest.NewRecorder()
+		handler.Patch(rec, withURLParam(req, "id", id))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.False(t, service.updateCalled)
+	})
+}
+
+func TestHandler_MinOrderQty(t *testing.T) {
+	t.Run("create passes the minimum", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Create(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Screws","sku":"SC-001","price":"1.00","min_order_qty":100}`)))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, 100, *service.createInput.MinOrderQty)
+	})
+
+	t.Run("list hides bulk-only items", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?min_order_qty_lte=1", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 1, *service.listFilter.MinOrderQtyLTE)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, json.Number("1"), filters["min_order_qty_lte"])
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?min_order_qty_lte=many", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		response := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", response.Error.Code)
+		require.Equal(t, "min_order_qty_lte", response.Error.Details[0].Field)
+	})
+}
+
+func TestHandler_Expiration(t *testing.T) {
+	t.Run("default listing hides expired items", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Count(rec, httptest.NewRequest(http.MethodGet, "/items/count", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, *service.countFilter.ExcludeExpired)
+	})
+
+	t.Run("explicit status or exclude_expired keeps expired items", func(t *testing.T) {
+		for _, query := range []string{"status=active", "exclude_expired=false"} {
+			service := &stubService{}
+			handler := items.NewHandler(service)
+
+			rec := httptest.NewRecorder()
+			handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?"+query, nil))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.False(t, service.listFilter.ExcludeExpired != nil && *service.listFilter.ExcludeExpired, query)
+		}
+	})
+
+	t.Run("expiring before", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?expiring_before=2025-01-01&exclude_expired=true", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "2025-01-01", service.listFilter.ExpiringBefore)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "2025-01-01", filters["expiring_before"])
+		require.Equal(t, true, filters["exclude_expired"])
+	})
+
+	t.Run("invalid exclude_expired", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?exclude_expired=maybe", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "exclude_expired", decodeResponse(t, rec).Error.Details[0].Field)
+	})
+
+	t.Run("expiring report", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		before := time.Now().UTC().AddDate(0, 0, 8).Format("2006-01-02")
+		rec := httptest.NewRecorder()
+		handler.Expiring(rec, httptest.NewRequest(http.MethodGet, "/items/expiring?days=7", nil))
+		after := time.Now().UTC().AddDate(0, 0, 8).Format("2006-01-02")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, []string{before, after}, service.listFilter.ExpiringBefore)
+		require.True(t, *service.listFilter.ExcludeExpired)
+		require.Equal(t, []items.SortKey{"expires_at"}, service.listFilter.Sort)
+		require.Equal(t, items.StatusActive, service.listFilter.Status)
+	})
+
+	t.Run("expiring report keeps the requested sort", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Expiring(rec, httptest.NewRequest(http.MethodGet, "/items/expiring?sort=-stock", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []items.SortKey{"-stock"}, service.listFilter.Sort)
+	})
+
+	t.Run("expiring report rejects invalid days", func(t *testing.T) {
+		for _, days := range []string{"-1", "366", "soon"} {
+			service := &stubService{}
+			handler := items.NewHandler(service)
+
+			rec := httptest.NewRecorder()
+			handler.Expiring(rec, httptest.NewRequest(http.MethodGet, "/items/expiring?days="+days, nil))
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, days)
+			response := decodeResponse(t, rec)
+			require.Equal(t, "invalid_filter", response.Error.Code)
+			require.Equal(t, "days", response.Error.Details[0].Field)
+			require.False(t, service.listCalled)
+		}
+	})
+}
+
+func TestHandler_SalePrice(t *testing.T) {
+	t.Run("create passes the sale price", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Create(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Mouse","sku":"MS-001","price":"10.00","sale_price":"7.99"}`)))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "7.99", *service.createInput.SalePrice)
+	})
+
+	t.Run("patch with null ends the sale", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+		id := "11111111-1111-1111-1111-111111111111"
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"sale_price":null}`))
+		rec := httptest.NewRecorder()
+		handler.Patch(rec, withURLParam(req, "id", id))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateInput.SalePricePresent)
+		require.Nil(t, service.updateInput.SalePrice)
+	})
+
+	t.Run("invalid sale price", func(t *testing.T) {
+		service := &stubService{
+			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorInvalidSalePrice
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Create(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Mouse","sku":"MS-001","price":"10.00","sale_price":"12.00"}`)))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_sale_price", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("list by effective price", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?use_effective_price=true&max_price=10.00&sort=price", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.listFilter.UseEffectivePrice)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, true, filters["use_effective_price"])
+	})
+
+	t.Run("invalid use_effective_price", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?use_effective_price=maybe", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "use_effective_price", decodeResponse(t, rec).Error.Details[0].Field)
+	})
+}
+
+func TestHandler_Dimensions(t *testing.T) {
+	t.Run("patch clears a single dimension", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+		id := "11111111-1111-1111-1111-111111111111"
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"weight_grams":800,"height_mm":null}`))
+		rec := httptest.NewRecorder()
+		handler.Patch(rec, withURLParam(req, "id", id))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 800, *service.updateInput.WeightGrams)
+		require.True(t, service.updateInput.HeightMMPresent)
+		require.Nil(t, service.updateInput.HeightMM)
+		require.False(t, service.updateInput.WidthMMPresent)
+	})
+
+	t.Run("max weight filter", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?max_weight=2000", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 2000, *service.listFilter.MaxWeight)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, json.Number("2000"), filters["max_weight"])
+	})
+
+	t.Run("invalid max weight", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?max_weight=2kg", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		response := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", response.Error.Code)
+		require.Equal(t, "max_weight", response.Error.Details[0].Field)
+	})
+}
+
+func TestHandler_Attributes(t *testing.T) {
+	t.Run("create passes the attributes", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Create(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Phone","sku":"PH-001","price":"10.00","attributes":{"color":"red","size":42}}`)))
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, map[string]any{"color": "red", "size": float64(42)}, service.createInput.Attributes)
+	})
+
+	t.Run("invalid attributes report the field", func(t *testing.T) {
+		service := &stubService{
+			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+				return items.Item{}, &items.ValidationError{Field: "attributes", Message: "attributes can have at most 50 keys"}
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Create(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Phone","sku":"PH-001","price":"10.00","attributes":{"color":"red"}}`)))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		response := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", response.Error.Code)
+		require.Equal(t, "attributes", response.Error.Details[0].Field)
+	})
+
+	t.Run("list filter", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?attr.color=red&attr.size=42", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, map[string]string{"color": "red", "size": "42"}, service.listFilter.Attributes)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, map[string]any{"color": "red", "size": "42"}, filters["attributes"])
+	})
+}
+
+func TestHandler_Currency(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("allow_currency_change reaches the service", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPatch, "/items/"+id+"?allow_currency_change=true", strings.NewReader(`{"currency":"EUR"}`)), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "EUR", *service.updateInput.Currency)
+		require.True(t, service.updateInput.AllowCurrencyChange)
+	})
+
+	t.Run("invalid allow_currency_change", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPatch, "/items/"+id+"?allow_currency_change=maybe", strings.NewReader(`{"currency":"EUR"}`)), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_allow_currency_change", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("currency change without the flag", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorCurrencyChange
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"currency":"EUR"}`)), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "currency_change_not_allowed", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.updateInput.AllowCurrencyChange)
+	})
+
+	t.Run("list filter", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?currency=eur", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "EUR", service.listFilter.Currency)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "EUR", filters["currency"])
+	})
+}
+
+func TestHandler_Status(t *testing.T) {
+	t.Run("list defaults to active items", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.StatusActive, service.listFilter.Status)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "active", filters["status"])
+	})
+
+	t.Run("list can widen to all", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?status=ALL", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.StatusAll, service.listFilter.Status)
+	})
+
+	t.Run("trash does not filter by status", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Trash(rec, httptest.NewRequest(http.MethodGet, "/items/trash", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, service.listFilter.Status)
+	})
+
+	t.Run("invalid status on patch", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorInvalidStatus
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := withURLParam(httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"status":"archived"}`)), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_status", decodeResponse(t, rec).Error.Code)
+		require.Equal(t, items.ItemStatus("archived"), *service.updateInput.Status)
+	})
+}
+
+func TestHandler_Brand(t *testing.T) {
+	t.Run("unknown brand on create is invalid input with a field detail", func(t *testing.T) {
+		service := &stubService{
+			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorUnknownBrand
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Keyboard","sku":"KB-001","price":"10.00","stock":1,"brand_id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "brand_id", Message: "brand_id does not reference an existing brand"}}, resp.Error.Details)
+		require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", *service.createInput.BrandID)
+	})
+
+	t.Run("the item embeds its brand", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Brand: &items.ItemBrand{ID: "brand-1", Name: "Acme"}}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/items/"+id, nil), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, map[string]any{"id": "brand-1", "name": "Acme"}, asMap(t, decodeResponse(t, rec).Data)["brand"])
+	})
+}
+
+func TestHandler_GetBySlug(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{
+			slugFn: func(ctx context.Context, slug string) (items.Item, error) {
+				return items.Item{ID: "id-1", Name: "Wireless Keyboard", Slug: slug}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/slug/wireless-keyboard", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "slug", "wireless-keyboard")
+
+		handler.GetBySlug(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		data := asMap(t, resp.Data)
+		require.Equal(t, "wireless-keyboard", data["slug"])
+		require.Equal(t, "wireless-keyboard", service.getSlug)
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/slug/Wireless_Keyboard", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "slug", "Wireless_Keyboard")
+
+		handler.GetBySlug(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_slug", resp.Error.Code)
+		require.False(t, service.getCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			slugFn: func(ctx context.Context, slug string) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/slug/missing", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "slug", "missing")
+
+		handler.GetBySlug(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("duplicate slug on create is a conflict", func(t *testing.T) {
+		service := &stubService{
+			createFn: func(ctx context.Context, in items.CreateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorDuplicateSlug
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"Keyboard","slug":"keyboard","price":"10.00","stock":1}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "conflict", resp.Error.Code)
+		require.Equal(t, "item slug already exists", resp.Error.Message)
+		require.Equal(t, "keyboard", service.createInput.Slug)
+	})
+
+	t.Run("invalid slug on patch", func(t *testing.T) {
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorInvalidSlug
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"slug":"Bad Slug"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_slug", resp.Error.Code)
+		require.Equal(t, "Bad Slug", *service.updateInput.Slug)
+	})
+}
+
+func TestHandler_Fields(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	description := "Smartphone"
+	item := items.Item{ID: id, Name: "Phone", Description: &description, Price: "10.00", Stock: 3}
+
+	t.Run("list prunes each item and keeps id", func(t *testing.T) {
+		service := &stubService{
+			listFn: func(ctx context.Context, page, limit int, filter items.ListFilter) (items.ListPage, error) {
+				return items.ListPage{Items: []items.Item{item}, Total: 1}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?fields=name,price", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Data struct {
+				Items []map[string]any `json:"items"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Equal(t, []map[string]any{{"id": id, "name": "Phone", "price": "10.00"}}, body.Data.Items)
+	})
+
+	t.Run("get prunes the item", func(t *testing.T) {
+		service := &stubService{
+			getFn: func(ctx context.Context, id string) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone", Price: "10.00"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id+"?fields=name,description", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		// description no tiene valor pero se pidió: viene como null en vez de faltar.
+		require.Equal(t, map[string]any{"id": id, "name": "Phone", "description": nil}, asMap(t, resp.Data))
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id+"?fields=name,colour", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.GetByID(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_fields", resp.Error.Code)
+		require.Contains(t, resp.Error.Message, `"colour"`)
+		require.False(t, service.getCalled)
+	})
+
+	t.Run("unknown field on list", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/items?fields=id,,name", nil)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_fields", resp.Error.Code)
+		require.False(t, service.listCalled)
+	})
+}
+
+func TestHandler_Patch(t *testing.T) {
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/not-uuid", strings.NewReader(`{"name":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", "not-uuid")
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_id", resp.Error.Code)
+		require.False(t, service.updateCalled)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader("{"))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_json", resp.Error.Code)
+		require.False(t, service.updateCalled)
+	})
+
+	t.Run("invalid json payload", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"stock":"abc"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_json", resp.Error.Code)
+		require.False(t, service.updateCalled)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorInvalidInput
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":""}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", resp.Error.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":"New"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "not_found", resp.Error.Code)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, items.ErrorDuplicateName
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":"New"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "conflict", resp.Error.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{}, errors.New("boom")
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":"New"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "internal_error", resp.Error.Code)
+	})
+
+	t.Run("success with description null", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{ID: id, Name: "Updated"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		body := `{"description":null,"price":"10.00"}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateCalled)
+		require.Equal(t, id, service.updateID)
+		require.True(t, service.updateInput.DescriptionPresent)
+		require.Nil(t, service.updateInput.Description)
+		require.NotNil(t, service.updateInput.Price)
+	})
+
+	t.Run("success without description", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{ID: id, Name: "Updated"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		body := `{"name":"Updated"}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateCalled)
+		require.False(t, service.updateInput.DescriptionPresent)
+	})
+}
+
+func TestHandler_PatchMergePatch(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("null clears a nullable field", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"description":null}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateInput.DescriptionPresent)
+		require.Nil(t, service.updateInput.Description)
+	})
+
+	t.Run("attributes are merged with null deleting a key", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"attributes":{"color":"blue","size":null}}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateInput.AttributesPresent)
+		require.Nil(t, service.updateInput.Attributes)
+		require.Equal(t, map[string]any{"color": "blue", "size": nil}, service.updateInput.AttributesPatch)
+	})
+
+	t.Run("null on a required field is invalid", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":null}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "name", Message: "name cannot be null"}}, resp.Error.Details)
+		require.False(t, service.updateCalled)
+	})
+
+	t.Run("plain json ignores null on required fields", func(t *testing.T) {
+		service := &stubService{
+			updateFn: func(ctx context.Context, id string, in items.UpdateItemInput) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(`{"name":null,"stock":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Patch(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Nil(t, service.updateInput.Name)
+	})
+}
+
+func TestHandler_PatchJSONPatch(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPatch, "/items/"+id, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		return withURLParam(req, "id", id)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{
+			patchFn: func(ctx context.Context, id string, operations []items.PatchOperation) (items.Item, error) {
+				return items.Item{ID: id, Name: "Phone X"}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, newRequest(`[{"op":"replace","path":"/name","value":"Phone X"}]`))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, service.updateCalled)
+		require.Equal(t, []items.PatchOperation{{Op: "replace", Path: "/name", Value: json.RawMessage(`"Phone X"`)}}, service.patchOperations)
+	})
+
+	t.Run("failed test op", func(t *testing.T) {
+		service := &stubService{
+			patchFn: func(ctx context.Context, id string, operations []items.PatchOperation) (items.Item, error) {
+				return items.Item{}, &items.PatchOperationError{Index: 1, Reason: "value at /stock does not match", Err: items.ErrorPatchTestFailed}
+			},
+		}
+		handler := items.NewHandler(service)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, newRequest(`[{"op":"replace","path":"/stock","value":1},{"op":"test","path":"/stock","value":5}]`))
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "precondition_failed", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "/1", Message: "value at /stock does not match"}}, resp.Error.Details)
+	})
+
+	t.Run("unsupported op", func(t *testing.T) {
+		service := &stubService{
+			patchFn: func(ctx context.Context, id string, operations []items.PatchOperation) (items.Item, error) {
+				return items.Item{}, &items.PatchOperationError{Index: 0, Reason: `unsupported op "copy"`, Err: items.ErrorInvalidPatch}
+			},
+		}
+		handler := items.NewHandler(service)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, newRequest(`[{"op":"copy","from":"/name","path":"/description"}]`))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_patch", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "/0", Message: `unsupported op "copy"`}}, resp.Error.Details)
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		service := &stubService{
+			patchFn: func(ctx context.Context, id string, operations []items.PatchOperation) (items.Item, error) {
+				return items.Item{}, items.ErrorPatchTooLarge
+			},
+		}
+		handler := items.NewHandler(service)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, newRequest(`[{"op":"test","path":"/stock","value":1}]`))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "patch_too_large", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("body is not an array", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, newRequest(`{"name":"x"}`))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_json", resp.Error.Code)
+		require.False(t, service.patchCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			patchFn: func(ctx context.Context, id string, operations []items.PatchOperation) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+		rec := httptest.NewRecorder()
+
+		handler.Patch(rec, newRequest(`[]`))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_Delete(t *testing.T) {
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodDelete, "/items/not-uuid", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", "not-uuid")
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_id", resp.Error.Code)
+		require.False(t, service.deleteCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			deleteFn: func(ctx context.Context, id string) error {
+				return items.ErrorNotFound
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "not_found", resp.Error.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		service := &stubService{
+			deleteFn: func(ctx context.Context, id string) error {
+				return errors.New("boom")
+			},
+		}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "internal_error", resp.Error.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id, nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.True(t, service.deleteCalled)
+		require.Equal(t, id, service.deleteID)
+		require.Empty(t, rec.Body.String())
+	})
+
+	t.Run("return representation", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id+"?return=representation", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, id, resp.Data.(map[string]any)["id"])
+	})
+
+	t.Run("return minimal", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id+"?return=minimal", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("invalid return", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		id := "550e8400-e29b-41d4-a716-446655440000"
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id+"?return=full", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Delete(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_return", resp.Error.Code)
+		require.False(t, service.deleteCalled)
+	})
+}
+
+func TestHandler_Purge(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	purge := func(service *stubService, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id+"/purge", nil)
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Purge(rec, req)
+		return rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := purge(service, id)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, id, service.purgeID)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := purge(service, "not-uuid")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.purgeCalled)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		service := &stubService{
+			purgeFn: func(ctx context.Context, id string) error {
+				return items.ErrorNotFound
+			},
+		}
+
+		rec := purge(service, id)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "not_found", resp.Error.Code)
+		require.Equal(t, "item not found in trash", resp.Error.Message)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		service := &stubService{
+			purgeFn: func(ctx context.Context, id string) error {
+				return errors.New("boom")
+			},
+		}
+
+		rec := purge(service, id)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_AdjustStock(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	adjust := func(service *stubService, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items/"+id+"/stock-adjustments", strings.NewReader(body))
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).AdjustStock(rec, req)
+		return rec
+	}
+
+	t.Run("returns the updated item", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := adjust(service, `{"delta":-3,"reason":"damaged"}`)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+		require.Equal(t, items.StockAdjustmentInput{Delta: -3, Reason: "damaged"}, service.adjustInput)
+	})
+
+	t.Run("negative result is invalid input", func(t *testing.T) {
+		service := &stubService{
+			adjustFn: func(ctx context.Context, id string, in items.StockAdjustmentInput) (items.Item, error) {
+				return items.Item{}, items.ErrorInvalidStock
+			},
+		}
+
+		rec := adjust(service, `{"delta":-30}`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := &stubService{
+			adjustFn: func(ctx context.Context, id string, in items.StockAdjustmentInput) (items.Item, error) {
+				return items.Item{}, items.ErrorNotFound
+			},
+		}
+
+		rec := adjust(service, `{"delta":1}`)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := adjust(service, `[`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.False(t, service.adjustCalled)
+	})
+}
+
+func TestHandler_StockMovements(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	list := func(service *stubService, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id+"/stock-movements"+query, nil)
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).StockMovements(rec, req)
+		return rec
+	}
+
+	t.Run("paginates newest first", func(t *testing.T) {
+		service := &stubService{
+			movementsFn: func(ctx context.Context, id string, page, limit int) (items.StockMovementPage, error) {
+				return items.StockMovementPage{
+					Movements: []items.StockMovement{{ID: 2, ItemID: id, Delta: -1, ResultingStock: 4, Reason: "update"}},
+					Total:     3,
+				}, nil
+			},
+		}
+
+		rec := list(service, "?page=2&limit=1")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 2, service.movementsPage)
+		require.Equal(t, 1, service.movementsLimit)
+		var body struct {
+			Data struct {
+				Movements  []items.StockMovement `json:"movements"`
+				Pagination struct {
+					Total   int  `json:"total"`
+					HasNext bool `json:"has_next"`
+				} `json:"pagination"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Data.Movements, 1)
+		require.Equal(t, 3, body.Data.Pagination.Total)
+		require.True(t, body.Data.Pagination.HasNext)
+	})
+
+	t.Run("empty history is an empty array", func(t *testing.T) {
+		rec := list(&stubService{}, "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"movements":[]`)
+	})
+
+	t.Run("cursor is not supported", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := list(service, "?cursor=abc")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_pagination", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.movementsCalled)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := &stubService{
+			movementsFn: func(ctx context.Context, id string, page, limit int) (items.StockMovementPage, error) {
+				return items.StockMovementPage{}, items.ErrorNotFound
+			},
+		}
+
+		rec := list(service, "")
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_RestockNeeded(t *testing.T) {
+	list := func(service *stubService, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/restock-needed"+query, nil)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).RestockNeeded(rec, req)
+		return rec
+	}
+
+	t.Run("paginates with suggested quantities", func(t *testing.T) {
+		reorderPoint, suggested := 10, 18
+		service := &stubService{
+			restockFn: func(ctx context.Context, page, limit int) (items.ListPage, error) {
+				return items.ListPage{
+					Items: []items.Item{{ID: "id-1", Stock: 2, ReorderPoint: &reorderPoint, SuggestedOrderQty: &suggested}},
+					Total: 3,
+				}, nil
+			},
+		}
+
+		rec := list(service, "?page=2&limit=1")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 2, service.restockPage)
+		require.Equal(t, 1, service.restockLimit)
+		var body struct {
+			Data struct {
+				Items      []items.Item `json:"items"`
+				Pagination struct {
+					Total   int  `json:"total"`
+					HasNext bool `json:"has_next"`
+				} `json:"pagination"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Data.Items, 1)
+		require.Equal(t, 10, *body.Data.Items[0].ReorderPoint)
+		require.Equal(t, 18, *body.Data.Items[0].SuggestedOrderQty)
+		require.Equal(t, 3, body.Data.Pagination.Total)
+		require.True(t, body.Data.Pagination.HasNext)
+	})
+
+	t.Run("nothing to restock is an empty array", func(t *testing.T) {
+		rec := list(&stubService{}, "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"items":[]`)
+	})
+
+	t.Run("cursor is not supported", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := list(service, "?cursor=abc")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_pagination", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.restockCalled)
+	})
+}
+
+func TestHandler_Changes(t *testing.T) {
+	changes := func(service *stubService, handler *items.Handler, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/changes"+query, nil)
+		rec := httptest.NewRecorder()
+		handler.Changes(rec, req)
+		return rec
+	}
+	updatedAt := time.Date(2024, 6, 1, 10, 0, 0, 123456000, time.UTC)
+	deletedAt := updatedAt.Add(-time.Hour)
+
+	t.Run("returns the batch with deleted markers and the next watermark", func(t *testing.T) {
+		service := &stubService{
+			changesFn: func(ctx context.Context, after items.UpdatedAtID, limit int) (items.ChangesPage, error) {
+				return items.ChangesPage{
+					Items: []items.Item{
+						{ID: "550e8400-e29b-41d4-a716-446655440000", UpdatedAt: updatedAt},
+						{ID: "550e8400-e29b-41d4-a716-446655440001", UpdatedAt: updatedAt, DeletedAt: &deletedAt, Deleted: true},
+					},
+					Next:    items.UpdatedAtID{UpdatedAt: updatedAt, ID: "550e8400-e29b-41d4-a716-446655440001"},
+					HasMore: true,
+				}, nil
+			},
+		}
+
+		rec := changes(service, items.NewHandler(service), "?since=2024-06-01T00:00:00Z&limit=500")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.UpdatedAtID{UpdatedAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}, service.changesAfter)
+		require.Equal(t, 500, service.changesLimit)
+		var body struct {
+			Data struct {
+				Items     []map[string]any `json:"items"`
+				NextSince string           `json:"next_since"`
+				HasMore   bool             `json:"has_more"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Data.Items, 2)
+		require.NotContains(t, body.Data.Items[0], "deleted")
+		require.Equal(t, true, body.Data.Items[1]["deleted"])
+		require.True(t, body.Data.HasMore)
+
+		// next_since vuelve como since y el handler recupera el mismo par.
+		next := changes(service, items.NewHandler(service), "?since="+body.Data.NextSince)
+		require.Equal(t, http.StatusOK, next.Code)
+		require.Equal(t, items.UpdatedAtID{UpdatedAt: updatedAt, ID: "550e8400-e29b-41d4-a716-446655440001"}, service.changesAfter)
+	})
+
+	t.Run("without since starts from the beginning with the default limit", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := changes(service, items.NewHandler(service), "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.UpdatedAtID{}, service.changesAfter)
+		require.Equal(t, 100, service.changesLimit)
+		require.Contains(t, rec.Body.String(), `"items":[]`)
+		require.Contains(t, rec.Body.String(), `"has_more":false`)
+	})
+
+	t.Run("caps the limit at 1000", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := changes(service, items.NewHandler(service), "?limit=5000")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 1000, service.changesLimit)
+		require.Equal(t, "1000", rec.Header().Get("X-Limit-Capped"))
+	})
+
+	t.Run("strict pagination rejects a limit over 1000", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := changes(service, items.NewHandler(service, items.WithStrictPagination(true)), "?limit=5000")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "limit_too_large", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.changesCalled)
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := changes(service, items.NewHandler(service), "?since=yesterday")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.changesCalled)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := changes(service, items.NewHandler(service), "?limit=0")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_pagination", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.changesCalled)
+	})
+}
+
+// channelSource es un EventSource con un canal que maneja el test.
+type channelSource struct {
+	events       chan items.Event
+	unsubscribed chan struct{}
+}
+
+func newChannelSource() *channelSource {
+	return &channelSource{events: make(chan items.Event, 1), unsubscribed: make(chan struct{})}
+}
+
+func (source *channelSource) Subscribe() (<-chan items.Event, func()) {
+	return source.events, func() { close(source.unsubscribed) }
+}
+
+// flushRecorder anota cuántos bytes se escribieron entre un Flush y el siguiente.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	chunks  []int
+	pending int
+}
+
+func (recorder *flushRecorder) Write(data []byte) (int, error) {
+	recorder.pending += len(data)
+	return recorder.ResponseRecorder.Write(data)
+}
+
+func (recorder *flushRecorder) Flush() {
+	recorder.chunks = append(recorder.chunks, recorder.pending)
+	recorder.pending = 0
+	recorder.ResponseRecorder.Flush()
+}
+
+func TestHandler_Export(t *testing.T) {
+	t.Run("one item per line with the list filters", func(t *testing.T) {
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				for _, id := range []string{"id-1", "id-2", "id-3"} {
+					if err := fn(items.Item{ID: id, Name: "Phone " + id, Price: "10.00"}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=ndjson&query=phone&min_price=10", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		require.Regexp(t, `^attachment; filename="items-\d{4}-\d{2}-\d{2}\.ndjson"$`, rec.Header().Get("Content-Disposition"))
+		lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+		require.Len(t, lines, 3)
+		var first map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		require.Equal(t, "id-1", first["id"])
+
+		var exported items.ListFilter
+		service.exportFn = func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+			exported = filter
+			return nil
+		}
+		handler.Export(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/export?query=phone&min_price=10", nil))
+		handler.Count(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/count?query=phone&min_price=10", nil))
+		require.Equal(t, service.countFilter, exported)
+	})
+
+	t.Run("large exports are streamed in bounded chunks", func(t *testing.T) {
+		const total = 100_000
+		rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				for i := range total {
+					// Lo anterior ya salió hacia el cliente antes de que la fuente produzca el siguiente lote.
+					require.Equal(t, i/500, len(rec.chunks))
+					if err := fn(items.Item{ID: strconv.Itoa(i), Name: "Item " + strconv.Itoa(i), Price: "1.00"}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, rec.chunks, total/500)
+		for _, chunk := range rec.chunks {
+			require.Less(t, chunk, 500*1024, "each flush carries one batch, not the whole catalog")
+		}
+		require.Equal(t, total, strings.Count(rec.Body.String(), "\n"))
+	})
+
+	t.Run("empty result is an empty file", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		items.NewHandler(&stubService{}).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		require.Empty(t, rec.Body.String())
+	})
+
+	t.Run("csv with stable columns and quoting", func(t *testing.T) {
+		description := "Dual SIM"
+		created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				if err := fn(items.Item{ID: "id-1", Name: "Phone, 6\" \"Pro\"", Description: &description, Price: "10.00", Stock: 3, CreatedAt: created, UpdatedAt: created}); err != nil {
+					return err
+				}
+				return fn(items.Item{ID: "id-2", Name: "Line\nbreak", Price: "2.50", CreatedAt: created, UpdatedAt: created})
+			},
+		}
+
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=csv", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		require.Regexp(t, `^attachment; filename="items-\d{4}-\d{2}-\d{2}\.csv"$`, rec.Header().Get("Content-Disposition"))
+		records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"id", "name", "description", "price", "stock", "created_at", "updated_at"},
+			{"id-1", `Phone, 6" "Pro"`, "Dual SIM", "10.00", "3", "2024-05-01T12:00:00Z", "2024-05-01T12:00:00Z"},
+			{"id-2", "Line\nbreak", "", "2.50", "0", "2024-05-01T12:00:00Z", "2024-05-01T12:00:00Z"},
+		}, records)
+	})
+
+	t.Run("csv rows are flushed in batches", func(t *testing.T) {
+		const total = 2_000
+		rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				for i := range total {
+					require.Equal(t, i/500, len(rec.chunks))
+					if err := fn(items.Item{ID: strconv.Itoa(i), Name: "Item " + strconv.Itoa(i), Price: "1.00"}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=csv", nil))
+
+		require.Len(t, rec.chunks, total/500)
+		require.Equal(t, total+1, strings.Count(rec.Body.String(), "\n"))
+	})
+
+	t.Run("fields project ndjson and csv", func(t *testing.T) {
+		created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		var exported items.ListFilter
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				exported = filter
+				return fn(items.Item{ID: "id-1", Name: "Phone, Pro", Price: "10.00", Stock: 3, Attributes: map[string]any{"color": "red"}, CreatedAt: created, UpdatedAt: created})
+			},
+		}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?fields=name,price&category_id=cat-1&min_price=5&status=inactive", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"id":"id-1","name":"Phone, Pro","price":"10.00"}`, rec.Body.String())
+		require.Equal(t, "cat-1", exported.CategoryID)
+		require.Equal(t, "5", exported.MinPrice)
+		require.Equal(t, items.StatusInactive, exported.Status)
+
+		rec = httptest.NewRecorder()
+		handler.Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=csv&fields=stock,description,name,attributes,created_at", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"id", "name", "description", "stock", "attributes", "created_at"},
+			{"id-1", "Phone, Pro", "", "3", `{"color":"red"}`, "2024-05-01T12:00:00Z"},
+		}, records)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		service := &stubService{}
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=csv&fields=colour", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_fields", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("empty csv keeps the header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		items.NewHandler(&stubService{}).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=csv", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "id,name,description,price,stock,created_at,updated_at\n", rec.Body.String())
+	})
+
+	t.Run("xlsx workbook with typed cells", func(t *testing.T) {
+		createdAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+		description := `Fish & "chips" <large>`
+		service := &stubService{
+			countFn: func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+				return items.ItemCount{Total: 2}, nil
+			},
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				if err := fn(items.Item{ID: "id-1", Name: "Phone X", Description: &description, Price: "10.50", Stock: 3, CreatedAt: createdAt, UpdatedAt: createdAt}); err != nil {
+					return err
+				}
+				return fn(items.Item{ID: "id-2", Name: "Tablet", Price: "1200.00", CreatedAt: createdAt, UpdatedAt: createdAt})
+			},
+		}
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=xlsx", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rec.Header().Get("Content-Type"))
+		require.Regexp(t, `^attachment; filename="items-\d{4}-\d{2}-\d{2}\.xlsx"$`, rec.Header().Get("Content-Disposition"))
+		parts := readXLSX(t, rec.Body.Bytes())
+		require.Contains(t, parts, "xl/styles.xml")
+		sheet := parts["xl/worksheets/sheet1.xml"]
+		require.Contains(t, sheet, `<c r="D1" s="4" t="inlineStr"><is><t xml:space="preserve">price</t></is></c>`, "bold header")
+		require.Contains(t, sheet, `<c r="C2" t="inlineStr"><is><t xml:space="preserve">Fish &amp; &#34;chips&#34; &lt;large&gt;</t></is></c>`)
+		require.Contains(t, sheet, `<c r="D2" s="1"><v>10.50</v></c>`, "price is a number with two decimals")
+		require.Contains(t, sheet, `<c r="E2"><v>3</v></c>`)
+		require.Contains(t, sheet, `<c r="F2" s="2"><v>45717.5</v></c>`, "timestamps are date cells")
+		require.NotContains(t, sheet, `r="C3"`, "a null description has no cell")
+		require.Contains(t, sheet, `<c r="D3" s="1"><v>1200.00</v></c>`)
+	})
+
+	t.Run("xlsx with fields", func(t *testing.T) {
+		service := &stubService{
+			countFn: func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+				return items.ItemCount{Total: 1}, nil
+			},
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				return fn(items.Item{ID: "id-1", Name: "Phone X", AllowBackorder: true, Attributes: map[string]any{"color": "red"}})
+			},
+		}
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=xlsx&fields=name,attributes,allow_backorder", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		sheet := readXLSX(t, rec.Body.Bytes())["xl/worksheets/sheet1.xml"]
+		require.Contains(t, sheet, `<t xml:space="preserve">allow_backorder</t>`)
+		require.Contains(t, sheet, `<c r="C2" t="inlineStr"><is><t xml:space="preserve">{&#34;color&#34;:&#34;red&#34;}</t></is></c>`)
+		require.Contains(t, sheet, `<c r="D2" t="b"><v>1</v></c>`)
+	})
+
+	t.Run("xlsx over the row cap", func(t *testing.T) {
+		service := &stubService{
+			countFn: func(ctx context.Context, filter items.ListFilter) (items.ItemCount, error) {
+				return items.ItemCount{Total: 3}, nil
+			},
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				t.Fatal("export must not start")
+				return nil
+			},
+		}
+		rec := httptest.NewRecorder()
+
+		items.NewHandler(service, items.WithXLSXMaxRows(2)).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=xlsx", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		body := decodeResponse(t, rec)
+		require.Equal(t, "export_too_large", body.Error.Code)
+		require.Contains(t, body.Error.Message, "format=csv")
+	})
+
+	t.Run("empty xlsx keeps the header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		items.NewHandler(&stubService{}).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=xlsx", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		sheet := readXLSX(t, rec.Body.Bytes())["xl/worksheets/sheet1.xml"]
+		require.Contains(t, sheet, `<row r="1">`)
+		require.NotContains(t, sheet, `<row r="2">`)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		items.NewHandler(&stubService{}).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export?format=xml", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "format", decodeResponse(t, rec).Error.Details[0].Field)
+	})
+
+	t.Run("errors before the first item are regular responses", func(t *testing.T) {
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				return items.ErrorInvalidSort
+			},
+		}
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Export(rec, httptest.NewRequest(http.MethodGet, "/items/export", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("errors mid-stream abort the response", func(t *testing.T) {
+		service := &stubService{
+			exportFn: func(ctx context.Context, filter items.ListFilter, fn func(items.Item) error) error {
+				if err := fn(items.Item{ID: "id-1"}); err != nil {
+					return err
+				}
+				return context.Canceled
+			},
+		}
+
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			items.NewHandler(service).Export(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/export", nil))
+		})
+	})
+}
+
+// readXLSX abre el zip de un xlsx y devuelve el contenido de cada parte, verificando que todas sean
+// XML bien formado.
+func readXLSX(t *testing.T, body []byte) map[string]string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			_, err := decoder.Token()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err, file.Name)
+		}
+		parts[file.Name] = string(content)
+	}
+	return parts
+}
+
+func TestHandler_Events(t *testing.T) {
+	t.Run("without a source the stream is unavailable", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		items.NewHandler(&stubService{}).Events(rec, httptest.NewRequest(http.MethodGet, "/items/events", nil))
+
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+		require.Equal(t, "events_unavailable", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("streams each event until the source closes", func(t *testing.T) {
+		source := newChannelSource()
+		server := httptest.NewServer(http.HandlerFunc(items.NewHandler(&stubService{}, items.WithEventSource(source)).Events))
+		defer server.Close()
+
+		response, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		require.Equal(t, "no-cache", response.Header.Get("Cache-Control"))
+
+		source.events <- items.Event{ID: "id-1", Operation: items.EventUpdated, Item: &items.Item{ID: "id-1", Name: "Phone", Price: "12.00"}}
+		close(source.events)
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		require.True(t, strings.HasPrefix(string(body), "event: item.updated\ndata: {\"id\":\"id-1\",\"operation\":\"updated\",\"item\":{"))
+		require.Contains(t, string(body), `"price":"12.00"`)
+		require.True(t, strings.HasSuffix(string(body), "}\n\n"))
+		<-source.unsubscribed
+	})
+
+	t.Run("a client disconnect ends the stream and unsubscribes", func(t *testing.T) {
+		source := newChannelSource()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			req := httptest.NewRequest(http.MethodGet, "/items/events", nil).WithContext(ctx)
+			items.NewHandler(&stubService{}, items.WithEventSource(source)).Events(httptest.NewRecorder(), req)
+		}()
+
+		cancel()
+
+		<-done
+		<-source.unsubscribed
+	})
+}
+
+func TestHandler_AdjustPrices(t *testing.T) {
+	adjust := func(service *stubService, query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items/price-adjustments"+query, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).AdjustPrices(rec, req)
+		return rec
+	}
+	const body = `{"filter":{"category_id":"550e8400-e29b-41d4-a716-446655440000"},"adjustment":{"type":"percent","value":"5"},"dry_run":true}`
+
+	t.Run("dry run returns the count and an empty sample", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := adjust(service, "", body)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"dry_run":true`)
+		require.Contains(t, rec.Body.String(), `"sample":[]`)
+		require.Equal(t, "550e8400-e29b-41d4-a716-446655440000", service.adjustPricesInput.Filter.CategoryID)
+		require.Equal(t, items.PriceAdjustment{Type: items.AdjustmentPercent, Value: "5"}, service.adjustPricesInput.Adjustment)
+		require.Nil(t, service.adjustPricesInput.ConfirmOver)
+	})
+
+	t.Run("confirm_over is passed to the service", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := adjust(service, "?confirm_over=250", body)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 250, *service.adjustPricesInput.ConfirmOver)
+	})
+
+	t.Run("invalid confirm_over", func(t *testing.T) {
+		rec := adjust(&stubService{}, "?confirm_over=many", body)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_input", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("guard rails", func(t *testing.T) {
+		for err, code := range map[error]string{
+			items.ErrorAdjustedPriceNotPositive:                         "non_positive_price",
+			items.ErrorAdjustedBelowSalePrice:                           "below_sale_price",
+			&items.PriceAdjustmentLimitError{Affected: 150, Limit: 100}: "confirmation_required",
+		} {
+			service := &stubService{adjustPricesFn: func(context.Context, items.PriceAdjustmentInput) (items.PriceAdjustmentResult, error) {
+				return items.PriceAdjustmentResult{}, err
+			}}
+
+			rec := adjust(service, "", body)
+
+			require.Equal(t, http.StatusUnprocessableEntity, rec.Code, code)
+			require.Equal(t, code, decodeResponse(t, rec).Error.Code)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		rec := adjust(&stubService{}, "", `{`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_Audit(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	list := func(service *stubService, id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id+"/audit"+query, nil)
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Audit(rec, req)
+		return rec
+	}
+
+	t.Run("paginates newest first", func(t *testing.T) {
+		service := &stubService{
+			auditFn: func(ctx context.Context, id string, page, limit int) (items.AuditPage, error) {
+				return items.AuditPage{
+					Entries: []items.AuditEntry{{ID: 4, ItemID: id, Action: items.AuditUpdated, Changes: []items.FieldChange{
+						{Field: "price", Old: json.RawMessage(`"10.00"`), New: json.RawMessage(`"12.50"`)},
+					}, RequestID: "req-1"}},
+					Total: 3,
+				}, nil
+			},
+		}
+
+		rec := list(service, id, "?page=2&limit=1")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 2, service.movementsPage)
+		require.Equal(t, 1, service.movementsLimit)
+		require.Contains(t, rec.Body.String(), `"action":"updated","changes":[{"field":"price","old":"10.00","new":"12.50"}],"request_id":"req-1"`)
+		require.Contains(t, rec.Body.String(), `"total":3`)
+	})
+
+	t.Run("empty audit is an empty array", func(t *testing.T) {
+		rec := list(&stubService{}, id, "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"entries":[]`)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := &stubService{
+			auditFn: func(ctx context.Context, id string, page, limit int) (items.AuditPage, error) {
+				return items.AuditPage{}, items.ErrorNotFound
+			},
+		}
+
+		rec := list(service, id, "")
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		rec := list(&stubService{}, "nope", "")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_PriceHistory(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	list := func(service *stubService, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/"+id+"/price-history"+query, nil)
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).PriceHistory(rec, req)
+		return rec
+	}
+
+	t.Run("paginates newest first", func(t *testing.T) {
+		oldPrice := "10.00"
+		service := &stubService{
+			historyFn: func(ctx context.Context, id string, filter items.PriceHistoryFilter, page, limit int) (items.PriceHistoryPage, error) {
+				return items.PriceHistoryPage{
+					Entries: []items.PriceHistoryEntry{{ID: 2, ItemID: id, OldPrice: &oldPrice, NewPrice: "12.50", Reason: "update"}},
+					Total:   3,
+				}, nil
+			},
+		}
+
+		rec := list(service, "?page=2&limit=1")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, 2, service.movementsPage)
+		require.Equal(t, 1, service.movementsLimit)
+		require.Contains(t, rec.Body.String(), `"old_price":"10.00","new_price":"12.50"`)
+		require.Contains(t, rec.Body.String(), `"total":3`)
+	})
+
+	t.Run("range accepts dates and timestamps", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := list(service, "?from=2025-03-01&to=2025-03-31T21:00:00-03:00")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Equal(*service.historyFilter.From))
+		require.True(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC).Equal(*service.historyFilter.To))
+	})
+
+	t.Run("empty history is an empty array", func(t *testing.T) {
+		rec := list(&stubService{}, "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"entries":[]`)
+	})
+
+	t.Run("invalid range bound", func(t *testing.T) {
+		rec := list(&stubService{}, "?from=march")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", resp.Error.Code)
+		require.Equal(t, "from", resp.Error.Details[0].Field)
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		service := &stubService{
+			historyFn: func(ctx context.Context, id string, filter items.PriceHistoryFilter, page, limit int) (items.PriceHistoryPage, error) {
+				return items.PriceHistoryPage{}, &items.FilterError{Field: "to", Message: "to must be after from"}
+			},
+		}
+
+		rec := list(service, "?from=2025-04-01&to=2025-03-01")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := &stubService{
+			historyFn: func(ctx context.Context, id string, filter items.PriceHistoryFilter, page, limit int) (items.PriceHistoryPage, error) {
+				return items.PriceHistoryPage{}, items.ErrorNotFound
+			},
+		}
+
+		rec := list(service, "")
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_Reserve(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	reserve := func(service *stubService, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items/"+id+"/reservations", strings.NewReader(body))
+		req = withURLParam(req, "id", id)
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Reserve(rec, req)
+		return rec
+	}
+
+	t.Run("created with location", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := reserve(service, id, `{"quantity":2,"ttl_seconds":600}`)
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/items/"+id+"/reservations/res-1", rec.Header().Get("Location"))
+		require.Equal(t, items.ReserveInput{Quantity: 2, TTLSeconds: 600}, service.reserveInput)
+	})
+
+	t.Run("insufficient stock", func(t *testing.T) {
+		service := &stubService{
+			reserveFn: func(ctx context.Context, id string, in items.ReserveInput) (items.Reservation, error) {
+				return items.Reservation{}, items.ErrorInsufficientStock
+			},
+		}
+
+		rec := reserve(service, id, `{"quantity":2}`)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, "insufficient_stock", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("below minimum order quantity", func(t *testing.T) {
+		service := &stubService{
+			reserveFn: func(ctx context.Context, id string, in items.ReserveInput) (items.Reservation, error) {
+				return items.Reservation{}, items.ErrorBelowMinOrderQty
+			},
+		}
+
+		rec := reserve(service, id, `{"quantity":2}`)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Equal(t, "below_min_order_qty", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("invalid quantity", func(t *testing.T) {
+		service := &stubService{
+			reserveFn: func(ctx context.Context, id string, in items.ReserveInput) (items.Reservation, error) {
+				return items.Reservation{}, &items.ValidationError{Field: "quantity", Message: "quantity must be at least 1"}
+			},
+		}
+
+		rec := reserve(service, id, `{"quantity":0}`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_input", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := &stubService{
+			reserveFn: func(ctx context.Context, id string, in items.ReserveInput) (items.Reservation, error) {
+				return items.Reservation{}, items.ErrorNotFound
+			},
+		}
+
+		rec := reserve(service, id, `{"quantity":1}`)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := reserve(service, id, `{`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.reserveCalled)
+	})
+}
+
+func TestHandler_Release(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	reservationID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	release := func(service *stubService, reservationID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/items/"+id+"/reservations/"+reservationID, nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", id)
+		routeCtx.URLParams.Add("rid", reservationID)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+		rec := httptest.NewRecorder()
+		items.NewHandler(service).Release(rec, req)
+		return rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := release(service, reservationID)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, reservationID, service.releaseReservationID)
+	})
+
+	t.Run("invalid reservation id", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := release(service, "nope")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.False(t, service.releaseCalled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service := &stubService{
+			releaseFn: func(ctx context.Context, id, reservationID string) error {
+				return items.ErrorNotFound
+			},
+		}
+
+		rec := release(service, reservationID)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "reservation not found", decodeResponse(t, rec).Error.Message)
+	})
+}
+
+func TestHandler_Duplicate(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("passes the sku of the copy", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+id+"/duplicate", strings.NewReader(`{"sku":"PX-2"}`))
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Duplicate(rec, req)
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/items/copy-id", rec.Header().Get("Location"))
+		require.Equal(t, id, service.duplicateID)
+		require.Equal(t, items.DuplicateItemInput{SKU: "PX-2"}, service.duplicateInput)
+		require.Equal(t, "Phone (copy)", asMap(t, decodeResponse(t, rec).Data)["name"])
+	})
+
+	t.Run("copy_stock", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+id+"/duplicate", strings.NewReader(`{"sku":"PX-2","copy_stock":true}`))
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Duplicate(rec, req)
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.True(t, service.duplicateInput.CopyStock)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/nope/duplicate", nil)
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", "nope")
+
+		handler.Duplicate(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.duplicateCalled)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/"+id+"/duplicate", strings.NewReader(`{"copy_stock":`))
+		rec := httptest.NewRecorder()
+		req = withURLParam(req, "id", id)
+
+		handler.Duplicate(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"source not found", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+		{"no free copy name", items.ErrorDuplicateName, http.StatusConflict, "conflict"},
+		{"sku taken", items.ErrorDuplicateSKU, http.StatusConflict, "conflict"},
+		{"missing sku", &items.ValidationError{Field: "sku", Message: "sku is required"}, http.StatusBadRequest, "invalid_input"},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				duplicateFn: func(ctx context.Context, id string, input items.DuplicateItemInput) (items.Item, error) {
+					return items.Item{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			req := httptest.NewRequest(http.MethodPost, "/items/"+id+"/duplicate", nil)
+			rec := httptest.NewRecorder()
+			req = withURLParam(req, "id", id)
+
+			handler.Duplicate(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Equal(t, tt.wantCode, decodeResponse(t, rec).Error.Code)
+		})
+	}
+}
+
+func TestHandler_State(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("listing and count default to published items", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.Count(rec, httptest.NewRequest(http.MethodGet, "/items/count", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.StatePublished, service.countFilter.State)
+	})
+
+	t.Run("state filter is passed to service", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		rec := httptest.NewRecorder()
+		handler.List(rec, httptest.NewRequest(http.MethodGet, "/items?state=Draft", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.StateDraft, service.listFilter.State)
+		filters := asMap(t, asMap(t, decodeResponse(t, rec).Data)["filters"])
+		require.Equal(t, "draft", filters["state"])
+	})
+
+	t.Run("publish returns the item with its etag", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/items/"+id+"/publish", nil), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.Publish(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `"2"`, rec.Header().Get("ETag"))
+		require.Equal(t, id, service.stateID)
+		require.Equal(t, items.StatePublished, service.state)
+		require.Equal(t, "published", asMap(t, decodeResponse(t, rec).Data)["state"])
+	})
+
+	t.Run("unpublish", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/items/"+id+"/unpublish", nil), "id", id)
+		rec := httptest.NewRecorder()
+
+		handler.Unpublish(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, items.StateDraft, service.state)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/items/nope/publish", nil), "id", "nope")
+		rec := httptest.NewRecorder()
+
+		handler.Publish(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_id", decodeResponse(t, rec).Error.Code)
+		require.Empty(t, service.stateID)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"incomplete item", &items.ValidationError{Field: "price", Message: "price is required to publish"}, http.StatusBadRequest, "invalid_input"},
+		{"not found", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+		{"internal error", errors.New("boom"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				stateFn: func(ctx context.Context, id string, state items.ItemState) (items.Item, error) {
+					return items.Item{}, tt.err
+				},
+			}
+			handler := items.NewHandler(service)
+
+			req := withURLParam(httptest.NewRequest(http.MethodPost, "/items/"+id+"/publish", nil), "id", id)
+			rec := httptest.NewRecorder()
+
+			handler.Publish(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Equal(t, tt.wantCode, decodeResponse(t, rec).Error.Code)
+		})
+	}
+}
+
+func TestHandler_BulkUpdate(t *testing.T) {
+	first := "550e8400-e29b-41d4-a716-446655440000"
+	second := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		body := `{"updates":[{"id":"` + first + `","price":"9.99"},{"id":"` + second + `","description":null}]}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/bulk", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.BulkUpdate(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, service.updateManyEntries, 2)
+		require.Equal(t, first, service.updateManyEntries[0].ID)
+		require.Equal(t, "9.99", *service.updateManyEntries[0].Input.Price)
+		require.True(t, service.updateManyEntries[1].Input.DescriptionPresent)
+		require.Nil(t, service.updateManyEntries[1].Input.Description)
+
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, map[string]any{"succeeded": json.Number("2"), "failed": json.Number("0"), "skipped": json.Number("0")}, data["summary"])
+		results := asSlice(t, data["results"])
+		require.Equal(t, first, asMap(t, results[0])["id"])
+		require.Equal(t, "succeeded", asMap(t, results[0])["status"])
+	})
+
+	t.Run("atomic responds the first failure", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			err        error
+			wantStatus int
+			wantCode   string
+		}{
+			{"not found", items.ErrorNotFound, http.StatusNotFound, "not_found"},
+			{"duplicate", items.ErrorDuplicateName, http.StatusConflict, "conflict"},
+			{"validation", &items.ValidationError{Field: "stock", Message: "stock must be >= 0"}, http.StatusBadRequest, "invalid_input"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service := &stubService{
+					updateManyFn: func(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error) {
+						return []items.BulkUpdateResult{
+							{ID: first, Err: items.ErrorNotApplied},
+							{ID: second, Err: tt.err},
+						}, nil
+					},
+				}
+				handler := items.NewHandler(service)
+
+				body := `{"updates":[{"id":"` + first + `","stock":1},{"id":"` + second + `","name":"Taken"}]}`
+				req := httptest.NewRequest(http.MethodPatch, "/items/bulk", strings.NewReader(body))
+				rec := httptest.NewRecorder()
+
+				handler.BulkUpdate(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+				resp := decodeResponse(t, rec)
+				require.Equal(t, tt.wantCode, resp.Error.Code)
+				require.True(t, strings.HasPrefix(resp.Error.Message, "item "+second+": "), resp.Error.Message)
+			})
+		}
+	})
+
+	t.Run("partial success with atomic=false", func(t *testing.T) {
+		service := &stubService{
+			updateEachFn: func(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error) {
+				return []items.BulkUpdateResult{
+					{ID: first, Item: items.Item{ID: first}},
+					{ID: second, Err: items.ErrorDuplicateName},
+				}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		body := `{"updates":[{"id":"` + first + `","stock":1},{"id":"` + second + `","name":"Taken"}]}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/bulk?atomic=false", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.BulkUpdate(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, service.updateEachCalled)
+		require.False(t, service.updateManyCalled)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, map[string]any{"succeeded": json.Number("1"), "failed": json.Number("1"), "skipped": json.Number("0")}, data["summary"])
+		results := asSlice(t, data["results"])
+		require.Equal(t, "succeeded", asMap(t, results[0])["status"])
+		require.Equal(t, "failed", asMap(t, results[1])["status"])
+	})
+
+	t.Run("invalid atomic", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		body := `{"updates":[{"id":"` + first + `","stock":1}]}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/bulk?atomic=maybe", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.BulkUpdate(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_atomic", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "atomic", Message: "must be true or false"}}, resp.Error.Details)
+		require.False(t, service.updateManyCalled)
+	})
+
+	t.Run("malformed entries are listed by position", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		body := `{"updates":[{"id":"nope","stock":1},{"id":"` + first + `","stock":1},{"id":"` + second + `","name":7},{"id":"` + second + `","stock":"x"},3]}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/bulk", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.BulkUpdate(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{
+			{Field: "updates[0].id", Message: "must be a valid UUID"},
+			{Field: "updates[2]", Message: "fields have invalid types"},
+			{Field: "updates[3]", Message: "fields have invalid types"},
+			{Field: "updates[4]", Message: "must be a JSON object"},
+		}, resp.Error.Details)
+		require.False(t, service.updateManyCalled)
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		service := &stubService{
+			updateManyFn: func(ctx context.Context, entries []items.BulkUpdateEntry) ([]items.BulkUpdateResult, error) {
+				return nil, &items.ValidationError{Field: "updates[1].id", Message: "duplicate id " + first}
+			},
+		}
+		handler := items.NewHandler(service)
+
+		body := `{"updates":[{"id":"` + first + `","stock":1},{"id":"` + first + `","stock":2}]}`
+		req := httptest.NewRequest(http.MethodPatch, "/items/bulk", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.BulkUpdate(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_input", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPatch, "/items/bulk", strings.NewReader(`{"updates":{}}`))
+		rec := httptest.NewRecorder()
+
+		handler.BulkUpdate(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestHandler_BulkDelete(t *testing.T) {
+	first := "550e8400-e29b-41d4-a716-446655440000"
+	second := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	t.Run("success", func(t *testing.T) {
+		service := &stubService{
+			deleteManyFn: func(ctx context.Context, ids []string) (items.BulkDeleteResult, error) {
+				return items.BulkDeleteResult{Deleted: 1, Missing: []string{second}}, nil
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/bulk-delete", strings.NewReader(`{"ids":["`+first+`","`+second+`"]}`))
+		rec := httptest.NewRecorder()
+
+		handler.BulkDelete(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []string{first, second}, service.deleteManyIDs)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, json.Number("1"), data["deleted"])
+		require.Equal(t, []any{second}, data["missing"])
+	})
+
+	t.Run("invalid ids are listed by position", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/bulk-delete", strings.NewReader(`{"ids":["nope","`+first+`","123"]}`))
+		rec := httptest.NewRecorder()
+
+		handler.BulkDelete(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_id", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{
+			{Field: "ids[0]", Message: "must be a valid UUID"},
+			{Field: "ids[2]", Message: "must be a valid UUID"},
+		}, resp.Error.Details)
+		require.False(t, service.deleteManyCalled)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		service := &stubService{}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/bulk-delete", strings.NewReader(`{"ids":`))
+		rec := httptest.NewRecorder()
+
+		handler.BulkDelete(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_json", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.deleteManyCalled)
+	})
+
+	t.Run("too many ids", func(t *testing.T) {
+		service := &stubService{
+			deleteManyFn: func(ctx context.Context, ids []string) (items.BulkDeleteResult, error) {
+				return items.BulkDeleteResult{}, &items.ValidationError{Field: "ids", Message: "ids must have between 1 and 500 entries"}
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/bulk-delete", strings.NewReader(`{"ids":[]}`))
+		rec := httptest.NewRecorder()
+
+		handler.BulkDelete(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", resp.Error.Code)
+		require.Equal(t, []httpx.ErrorDetail{{Field: "ids", Message: "ids must have between 1 and 500 entries"}}, resp.Error.Details)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		service := &stubService{
+			deleteManyFn: func(ctx context.Context, ids []string) (items.BulkDeleteResult, error) {
+				return items.BulkDeleteResult{}, errors.New("boom")
+			},
+		}
+		handler := items.NewHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/items/bulk-delete", strings.NewReader(`{"ids":["`+first+`"]}`))
+		rec := httptest.NewRecorder()
+
+		handler.BulkDelete(rec, req)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 }
 
@@ -0,0 +1,85 @@
+package items
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Marcas que envuelven cada coincidencia en los highlights. Son lo único del texto que no se escapa.
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+)
+
+// highlightItem completa Highlights con los campos donde se buscó filter.Query (name si no se pidió
+// ninguno), marcando las coincidencias. Sin Highlight o sin Query devuelve el item sin cambios.
+// Se aplica sobre el texto que ve el cliente, así que con un locale negociado marca la traducción.
+func highlightItem(item Item, filter ListFilter) Item {
+	if !filter.Highlight || filter.Query == "" {
+		return item
+	}
+	fields := filter.SearchFields
+	if len(fields) == 0 {
+		fields = []string{"name"}
+	}
+	// Fuzzy no exige que Query aparezca entera: se marca cada palabra que aparezca.
+	terms := []string{filter.Query}
+	if filter.Fuzzy {
+		terms = strings.Fields(filter.Query)
+	}
+	// prefix y exact solo pueden coincidir al principio del texto.
+	anchored := !filter.Fuzzy && (filter.Match == MatchPrefix || filter.Match == MatchExact)
+
+	highlights := &Highlights{}
+	for _, field := range fields {
+		switch field {
+		case "name":
+			name := highlight(item.Name, terms, anchored)
+			highlights.Name = &name
+		case "description":
+			if item.Description != nil {
+				description := highlight(*item.Description, terms, anchored)
+				highlights.Description = &description
+			}
+		}
+	}
+	item.Highlights = highlights
+	return item
+}
+
+// highlight escapa text como HTML y envuelve en <em> cada coincidencia de alguno de terms, sin
+// distinguir mayúsculas. Las coincidencias no se superponen; en una misma posición gana el término
+// más largo. Con anchored solo marca una coincidencia al principio.
+func highlight(text string, terms []string, anchored bool) string {
+	runes := []rune(text)
+	var builder strings.Builder
+	// plain es el inicio del tramo todavía no escrito, que va sin marcar.
+	plain := 0
+	for start := 0; start < len(runes) && !(anchored && start > 0); {
+		length := matchLength(runes[start:], terms)
+		if length == 0 {
+			start++
+			continue
+		}
+		builder.WriteString(html.EscapeString(string(runes[plain:start])))
+		builder.WriteString(highlightOpen + html.EscapeString(string(runes[start:start+length])) + highlightClose)
+		start += length
+		plain = start
+	}
+	builder.WriteString(html.EscapeString(string(runes[plain:])))
+	return builder.String()
+}
+
+// matchLength devuelve cuántas runas del principio de runes coinciden con el término más largo
+// de terms, o 0 si ninguno coincide.
+func matchLength(runes []rune, terms []string) int {
+	longest := 0
+	for _, term := range terms {
+		length := utf8.RuneCountInString(term)
+		if length > longest && length <= len(runes) && strings.EqualFold(string(runes[:length]), term) {
+			longest = length
+		}
+	}
+	return longest
+}
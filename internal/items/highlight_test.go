@@ -0,0 +1,85 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		anchored bool
+		expected string
+	}{
+		{"every occurrence ignoring case", "Phone case for phones", []string{"PHONE"}, false, "<em>Phone</em> case for <em>phone</em>s"},
+		{"no match is only escaped", "Cable <USB>", []string{"hdmi"}, false, "Cable &lt;USB&gt;"},
+		{"markup in the name is escaped", `<script>alert("x")</script> phone`, []string{"phone"}, false, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <em>phone</em>"},
+		{"markup in the match is escaped", "a <b> tag", []string{"<b>"}, false, "a <em>&lt;b&gt;</em> tag"},
+		{"multibyte text", "Cañón y CAÑÓN", []string{"cañón"}, false, "<em>Cañón</em> y <em>CAÑÓN</em>"},
+		{"longest term wins", "keyboard", []string{"key", "keyboard"}, false, "<em>keyboard</em>"},
+		{"matches do not overlap", "aaaa", []string{"aa"}, false, "<em>aa</em><em>aa</em>"},
+		{"anchored matches the start", "Phone phone", []string{"phone"}, true, "<em>Phone</em> phone"},
+		{"anchored ignores later matches", "Smart phone", []string{"phone"}, true, "Smart phone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, highlight(tt.text, tt.terms, tt.anchored))
+		})
+	}
+}
+
+func TestHighlightItem(t *testing.T) {
+	description := "Wireless keyboard & mouse"
+	item := Item{ID: "id-1", Name: "Keyboard Pro", Description: &description}
+
+	t.Run("off by default", func(t *testing.T) {
+		highlighted := highlightItem(item, ListFilter{Query: "keyboard", Match: MatchContains})
+
+		require.Nil(t, highlighted.Highlights)
+	})
+
+	t.Run("without query", func(t *testing.T) {
+		highlighted := highlightItem(item, ListFilter{Highlight: true})
+
+		require.Nil(t, highlighted.Highlights)
+	})
+
+	t.Run("name by default", func(t *testing.T) {
+		highlighted := highlightItem(item, ListFilter{Query: "keyboard", Match: MatchContains, Highlight: true})
+
+		require.Equal(t, "<em>Keyboard</em> Pro", *highlighted.Highlights.Name)
+		require.Nil(t, highlighted.Highlights.Description)
+		require.Equal(t, "Keyboard Pro", highlighted.Name)
+	})
+
+	t.Run("searched fields", func(t *testing.T) {
+		highlighted := highlightItem(item, ListFilter{Query: "keyboard", Match: MatchContains, SearchFields: []string{"name", "description"}, Highlight: true})
+
+		require.Equal(t, "<em>Keyboard</em> Pro", *highlighted.Highlights.Name)
+		require.Equal(t, "Wireless <em>keyboard</em> &amp; mouse", *highlighted.Highlights.Description)
+	})
+
+	t.Run("description without value", func(t *testing.T) {
+		highlighted := highlightItem(Item{Name: "Keyboard"}, ListFilter{Query: "keyboard", Match: MatchContains, SearchFields: []string{"description"}, Highlight: true})
+
+		require.NotNil(t, highlighted.Highlights)
+		require.Nil(t, highlighted.Highlights.Name)
+		require.Nil(t, highlighted.Highlights.Description)
+	})
+
+	t.Run("prefix only marks the start", func(t *testing.T) {
+		highlighted := highlightItem(Item{Name: "Pro keyboard pro"}, ListFilter{Query: "pro", Match: MatchPrefix, Highlight: true})
+
+		require.Equal(t, "<em>Pro</em> keyboard pro", *highlighted.Highlights.Name)
+	})
+
+	t.Run("fuzzy marks each word", func(t *testing.T) {
+		highlighted := highlightItem(item, ListFilter{Query: "keybord pro", Fuzzy: true, Highlight: true})
+
+		require.Equal(t, "Keyboard <em>Pro</em>", *highlighted.Highlights.Name)
+	})
+}
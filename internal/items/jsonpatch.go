@@ -0,0 +1,199 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// contentTypeJSONPatch es el Content-Type de JSON Patch (RFC 6902) en PATCH /items/{id}.
+const contentTypeJSONPatch = "application/json-patch+json"
+
+// maxPatchOperations es la cantidad máxima de operaciones de un JSON Patch.
+const maxPatchOperations = 20
+
+// Errores de JSON Patch. Se devuelven envueltos en *PatchOperationError con el índice de la operación.
+var (
+	// ErrorInvalidPatch indica una operación o path no soportado, o un valor con tipo incorrecto.
+	ErrorInvalidPatch = errors.New("invalid patch operation")
+	// ErrorPatchTestFailed indica que una operación test no se cumplió (precondición fallida).
+	ErrorPatchTestFailed = errors.New("patch test operation failed")
+	// ErrorPatchTooLarge indica que el patch trae más de maxPatchOperations operaciones.
+	ErrorPatchTooLarge = errors.New("too many patch operations")
+)
+
+// PatchOperation es una operación de JSON Patch (RFC 6902).
+// Value es nil cuando el cliente no lo envió y "null" cuando lo envió en null.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchOperationError describe qué operación del patch falló.
+// Err es ErrorInvalidPatch o ErrorPatchTestFailed.
+type PatchOperationError struct {
+	Index  int
+	Reason string
+	Err    error
+}
+
+// Error implementa error.
+func (operationError *PatchOperationError) Error() string {
+	return fmt.Sprintf("operation %d: %s", operationError.Index, operationError.Reason)
+}
+
+// Unwrap devuelve ErrorInvalidPatch o ErrorPatchTestFailed.
+func (operationError *PatchOperationError) Unwrap() error {
+	return operationError.Err
+}
+
+// jsonPatchPaths es la whitelist de paths → campo del item. Cualquier otro path se rechaza.
+// Los arrays (por ejemplo /tags/-) se agregan acá cuando existan en el modelo.
+var jsonPatchPaths = map[string]string{
+	"/name":            "name",
+	"/slug":            "slug",
+	"/description":     "description",
+	"/price":           "price",
+	"/sale_price":      "sale_price",
+	"/tax_rate_bps":    "tax_rate_bps",
+	"/min_order_qty":   "min_order_qty",
+	"/expires_at":      "expires_at",
+	"/reorder_point":   "reorder_point",
+	"/stock":           "stock",
+	"/sku":             "sku",
+	"/barcode":         "barcode",
+	"/category_id":     "category_id",
+	"/brand_id":        "brand_id",
+	"/status":          "status",
+	"/attributes":      "attributes",
+	"/weight_grams":    "weight_grams",
+	"/width_mm":        "width_mm",
+	"/height_mm":       "height_mm",
+	"/depth_mm":        "depth_mm",
+	"/allow_backorder": "allow_backorder",
+}
+
+// applyJSONPatch aplica las operaciones en orden sobre el item actual y devuelve los cambios
+// como UpdateItemInput. El bool indica si alguna operación modifica el item (un patch con
+// solo operaciones test no cambia nada). Las reglas de negocio las valida después el service.
+func applyJSONPatch(current Item, operations []PatchOperation) (UpdateItemInput, bool, error) {
+	document := map[string]json.RawMessage{
+		"name":            mustMarshal(current.Name),
+		"slug":            mustMarshal(current.Slug),
+		"description":     mustMarshal(current.Description),
+		"price":           mustMarshal(current.Price),
+		"sale_price":      mustMarshal(current.SalePrice),
+		"tax_rate_bps":    mustMarshal(current.TaxRateBPS),
+		"min_order_qty":   mustMarshal(current.MinOrderQty),
+		"expires_at":      mustMarshal(current.ExpiresAt),
+		"reorder_point":   mustMarshal(current.ReorderPoint),
+		"stock":           mustMarshal(current.Stock),
+		"sku":             mustMarshal(current.SKU),
+		"barcode":         mustMarshal(current.Barcode),
+		"category_id":     mustMarshal(categoryID(current)),
+		"brand_id":        mustMarshal(brandID(current)),
+		"status":          mustMarshal(current.Status),
+		"attributes":      mustMarshal(current.Attributes),
+		"weight_grams":    mustMarshal(current.WeightGrams),
+		"width_mm":        mustMarshal(current.WidthMM),
+		"height_mm":       mustMarshal(current.HeightMM),
+		"depth_mm":        mustMarshal(current.DepthMM),
+		"allow_backorder": mustMarshal(current.AllowBackorder),
+	}
+	touched := map[string]json.RawMessage{}
+
+	for index, operation := range operations {
+		field, ok := jsonPatchPaths[operation.Path]
+		if !ok {
+			return UpdateItemInput{}, false, invalidPatch(index, fmt.Sprintf("unsupported path %q", operation.Path))
+		}
+
+		switch operation.Op {
+		case "add", "replace":
+			if operation.Value == nil {
+				return UpdateItemInput{}, false, invalidPatch(index, "value is required")
+			}
+			if !valueFitsField(field, operation.Value) {
+				return UpdateItemInput{}, false, invalidPatch(index, fmt.Sprintf("invalid value type for %s", operation.Path))
+			}
+			document[field] = operation.Value
+			touched[field] = operation.Value
+		case "remove":
+			if !patchFields[field] {
+				return UpdateItemInput{}, false, invalidPatch(index, fmt.Sprintf("%s cannot be removed", operation.Path))
+			}
+			document[field] = json.RawMessage("null")
+			touched[field] = json.RawMessage("null")
+		case "test":
+			if operation.Value == nil {
+				return UpdateItemInput{}, false, invalidPatch(index, "value is required")
+			}
+			if !jsonEqual(document[field], operation.Value) {
+				return UpdateItemInput{}, false, &PatchOperationError{
+					Index:  index,
+					Reason: fmt.Sprintf("value at %s does not match", operation.Path),
+					Err:    ErrorPatchTestFailed,
+				}
+			}
+		default:
+			return UpdateItemInput{}, false, invalidPatch(index, fmt.Sprintf("unsupported op %q", operation.Op))
+		}
+	}
+
+	if len(touched) == 0 {
+		return UpdateItemInput{}, false, nil
+	}
+
+	// Los campos tocados se interpretan como un merge patch: null limpia los nullable
+	// y es un error de validación en los obligatorios.
+	input, err := patchDocument{raw: touched}.updateInput(true)
+	if err != nil {
+		return UpdateItemInput{}, false, err
+	}
+	return input, true, nil
+}
+
+// categoryID es el category_id del item para el documento de JSON Patch, o nil si no tiene categoría.
+func categoryID(item Item) *string {
+	if item.Category == nil {
+		return nil
+	}
+	return &item.Category.ID
+}
+
+func invalidPatch(index int, reason string) error {
+	return &PatchOperationError{Index: index, Reason: reason, Err: ErrorInvalidPatch}
+}
+
+// valueFitsField verifica que el valor se pueda decodificar en el tipo del campo.
+func valueFitsField(field string, value json.RawMessage) bool {
+	var probe UpdateItemInput
+	return json.Unmarshal(mustMarshal(map[string]json.RawMessage{field: value}), &probe) == nil
+}
+
+// jsonEqual compara dos valores JSON por contenido (3 y 3.0 son iguales, el orden de claves no importa).
+func jsonEqual(left, right json.RawMessage) bool {
+	var leftValue, rightValue any
+	if json.Unmarshal(left, &leftValue) != nil || json.Unmarshal(right, &rightValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(leftValue, rightValue)
+}
+
+func mustMarshal(value any) json.RawMessage {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
+// brandID es el brand_id del item para el documento de JSON Patch, o nil si no tiene marca.
+func brandID(item Item) *string {
+	if item.Brand == nil {
+		return nil
+	}
+	return &item.Brand.ID
+}
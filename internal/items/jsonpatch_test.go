@@ -0,0 +1,255 @@
+package items
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	description := "Old"
+	current := Item{ID: "id-1", Name: "Phone", Slug: "phone", Description: &description, Price: "10.00", Stock: 3}
+
+	operation := func(op, path, value string) PatchOperation {
+		operation := PatchOperation{Op: op, Path: path}
+		if value != "" {
+			operation.Value = json.RawMessage(value)
+		}
+		return operation
+	}
+
+	t.Run("replace and add set fields", func(t *testing.T) {
+		input, changed, err := applyJSONPatch(current, []PatchOperation{
+			operation("replace", "/name", `"Phone X"`),
+			operation("add", "/stock", `7`),
+		})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, "Phone X", *input.Name)
+		require.Equal(t, 7, *input.Stock)
+		require.Nil(t, input.Price)
+		require.False(t, input.DescriptionPresent)
+	})
+
+	t.Run("allow_backorder can be tested and replaced", func(t *testing.T) {
+		input, changed, err := applyJSONPatch(current, []PatchOperation{
+			operation("test", "/allow_backorder", `false`),
+			operation("replace", "/allow_backorder", `true`),
+		})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.True(t, *input.AllowBackorder)
+	})
+
+	t.Run("slug can be tested and replaced", func(t *testing.T) {
+		input, changed, err := applyJSONPatch(current, []PatchOperation{
+			operation("test", "/slug", `"phone"`),
+			operation("replace", "/slug", `"phone-x"`),
+		})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, "phone-x", *input.Slug)
+		require.Nil(t, input.Name)
+	})
+
+	t.Run("category_id is tested against the embedded category", func(t *testing.T) {
+		categorized := current
+		categorized.Category = &ItemCategory{ID: "550e8400-e29b-41d4-a716-446655440000", Name: "Audio"}
+
+		input, changed, err := applyJSONPatch(categorized, []PatchOperation{
+			operation("test", "/category_id", `"550e8400-e29b-41d4-a716-446655440000"`),
+			operation("remove", "/category_id", ""),
+		})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.True(t, input.CategoryIDPresent)
+		require.Nil(t, input.CategoryID)
+	})
+
+	t.Run("remove clears a nullable field", func(t *testing.T) {
+		input, changed, err := applyJSONPatch(current, []PatchOperation{operation("remove", "/description", "")})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.True(t, input.DescriptionPresent)
+		require.Nil(t, input.Description)
+	})
+
+	t.Run("attributes are tested and replaced as a whole", func(t *testing.T) {
+		withAttributes := current
+		withAttributes.Attributes = map[string]any{"color": "red"}
+
+		input, changed, err := applyJSONPatch(withAttributes, []PatchOperation{
+			operation("test", "/attributes", `{"color":"red"}`),
+			operation("replace", "/attributes", `{"color":"blue","size":42}`),
+		})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.True(t, input.AttributesPresent)
+		require.Equal(t, map[string]any{"color": "blue", "size": float64(42)}, input.Attributes)
+
+		input, _, err = applyJSONPatch(withAttributes, []PatchOperation{operation("remove", "/attributes", "")})
+		require.NoError(t, err)
+		require.True(t, input.AttributesPresent)
+		require.Nil(t, input.Attributes)
+	})
+
+	t.Run("sale price can be added and removed", func(t *testing.T) {
+		input, _, err := applyJSONPatch(current, []PatchOperation{operation("add", "/sale_price", `"7.99"`)})
+
+		require.NoError(t, err)
+		require.True(t, input.SalePricePresent)
+		require.Equal(t, "7.99", *input.SalePrice)
+
+		input, _, err = applyJSONPatch(current, []PatchOperation{operation("remove", "/sale_price", "")})
+
+		require.NoError(t, err)
+		require.True(t, input.SalePricePresent)
+		require.Nil(t, input.SalePrice)
+	})
+
+	t.Run("dimensions can be replaced and removed", func(t *testing.T) {
+		input, _, err := applyJSONPatch(current, []PatchOperation{
+			operation("replace", "/height_mm", `120`),
+			operation("remove", "/weight_grams", ""),
+		})
+
+		require.NoError(t, err)
+		require.True(t, input.HeightMMPresent)
+		require.Equal(t, 120, *input.HeightMM)
+		require.True(t, input.WeightGramsPresent)
+		require.Nil(t, input.WeightGrams)
+	})
+
+	t.Run("tax rate can be replaced but not removed", func(t *testing.T) {
+		input, _, err := applyJSONPatch(current, []PatchOperation{operation("replace", "/tax_rate_bps", "2100")})
+
+		require.NoError(t, err)
+		require.Equal(t, 2100, *input.TaxRateBPS)
+
+		_, _, err = applyJSONPatch(current, []PatchOperation{operation("remove", "/tax_rate_bps", "")})
+
+		requirePatchError(t, err, ErrorInvalidPatch, 0)
+	})
+
+	t.Run("expiration date can be added and removed", func(t *testing.T) {
+		input, _, err := applyJSONPatch(current, []PatchOperation{operation("add", "/expires_at", `"2025-06-30"`)})
+
+		require.NoError(t, err)
+		require.True(t, input.ExpiresAtPresent)
+		require.Equal(t, "2025-06-30", *input.ExpiresAt)
+
+		input, _, err = applyJSONPatch(current, []PatchOperation{operation("remove", "/expires_at", "")})
+
+		require.NoError(t, err)
+		require.True(t, input.ExpiresAtPresent)
+		require.Nil(t, input.ExpiresAt)
+	})
+
+	t.Run("minimum order quantity can be replaced", func(t *testing.T) {
+		input, _, err := applyJSONPatch(current, []PatchOperation{operation("replace", "/min_order_qty", "12")})
+
+		require.NoError(t, err)
+		require.Equal(t, 12, *input.MinOrderQty)
+	})
+
+	t.Run("remove on a required field is rejected", func(t *testing.T) {
+		_, _, err := applyJSONPatch(current, []PatchOperation{operation("remove", "/price", "")})
+
+		requirePatchError(t, err, ErrorInvalidPatch, 0)
+	})
+
+	t.Run("test sees previous operations", func(t *testing.T) {
+		input, changed, err := applyJSONPatch(current, []PatchOperation{
+			operation("test", "/price", `"10.00"`),
+			operation("replace", "/price", `"12.50"`),
+			operation("test", "/price", `"12.50"`),
+			operation("test", "/stock", `3.0`),
+		})
+
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, "12.50", *input.Price)
+	})
+
+	t.Run("test only patch has no changes", func(t *testing.T) {
+		_, changed, err := applyJSONPatch(current, []PatchOperation{operation("test", "/description", `"Old"`)})
+
+		require.NoError(t, err)
+		require.False(t, changed)
+	})
+
+	t.Run("failed test reports its index", func(t *testing.T) {
+		_, _, err := applyJSONPatch(current, []PatchOperation{
+			operation("replace", "/stock", `4`),
+			operation("test", "/name", `"Tablet"`),
+		})
+
+		requirePatchError(t, err, ErrorPatchTestFailed, 1)
+	})
+
+	t.Run("unsupported op", func(t *testing.T) {
+		_, _, err := applyJSONPatch(current, []PatchOperation{
+			operation("replace", "/stock", `4`),
+			{Op: "move", Path: "/name"},
+		})
+
+		requirePatchError(t, err, ErrorInvalidPatch, 1)
+	})
+
+	t.Run("unsupported paths", func(t *testing.T) {
+		for _, path := range []string{"/id", "/created_at", "/name/0", "/tags/5", ""} {
+			_, _, err := applyJSONPatch(current, []PatchOperation{operation("replace", path, `"x"`)})
+
+			requirePatchError(t, err, ErrorInvalidPatch, 0)
+		}
+	})
+
+	t.Run("sku can be tested and replaced but not removed", func(t *testing.T) {
+		sku := "KB-001"
+		withSKU := current
+		withSKU.SKU = &sku
+
+		input, changed, err := applyJSONPatch(withSKU, []PatchOperation{
+			operation("test", "/sku", `"KB-001"`),
+			operation("replace", "/sku", `"KB-002"`),
+		})
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, "KB-002", *input.SKU)
+
+		_, _, err = applyJSONPatch(withSKU, []PatchOperation{operation("remove", "/sku", "")})
+		requirePatchError(t, err, ErrorInvalidPatch, 0)
+	})
+
+	t.Run("missing or mistyped value", func(t *testing.T) {
+		_, _, err := applyJSONPatch(current, []PatchOperation{{Op: "replace", Path: "/name"}})
+		requirePatchError(t, err, ErrorInvalidPatch, 0)
+
+		_, _, err = applyJSONPatch(current, []PatchOperation{operation("replace", "/stock", `"many"`)})
+		requirePatchError(t, err, ErrorInvalidPatch, 0)
+	})
+
+	t.Run("null on a required field is a validation error", func(t *testing.T) {
+		_, _, err := applyJSONPatch(current, []PatchOperation{operation("replace", "/name", `null`)})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "name", validationError.Field)
+	})
+}
+
+func requirePatchError(t *testing.T, err error, kind error, index int) {
+	t.Helper()
+
+	var operationError *PatchOperationError
+	require.ErrorAs(t, err, &operationError)
+	require.ErrorIs(t, err, kind)
+	require.Equal(t, index, operationError.Index)
+}
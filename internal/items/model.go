@@ -1,36 +1,769 @@
 package items
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // Item representa un registro persistido en DB.
 // Price se modela como string para evitar errores de precisión con float.
+// SalePrice es el precio de oferta, si hay, y siempre es menor que Price. EffectivePrice es el que
+// se cobra (SalePrice si existe, si no Price); no se persiste.
+// TaxRateBPS es la alícuota de impuesto en puntos básicos (1900 = 19%). Price es neto y PriceWithTax
+// es Price más el impuesto, redondeado a los decimales de la moneda; no se persiste.
+// NextPriceChange es el próximo cambio de precio programado, si hay uno pendiente.
+// SKU es obligatorio en el alta y se puede cambiar con PATCH; los items previos a la columna pueden no tenerlo.
+// Barcode es el EAN-13 o UPC-A del item; es opcional.
+// Category es la categoría del item (id y nombre), si tiene. Brand es lo mismo para la marca.
+// Currency es el código ISO 4217 del precio (por ejemplo "EUR").
+// Attributes son los datos propios del tipo de producto (color, material, ...): un mapa plano
+// de string a string, número o bool que se devuelve tal como se guardó.
+// WeightGrams, WidthMM, HeightMM y DepthMM son el peso y las medidas para envíos; son opcionales.
+// MinOrderQty es la cantidad mínima que se puede reservar de una vez (1 para los items de venta minorista).
+// ExpiresAt es la fecha de vencimiento (YYYY-MM-DD) de los perecederos; es opcional.
+// ReorderPoint es el stock a partir del cual hay que reponer; es opcional. SuggestedOrderQty solo
+// viene en el reporte de reposición (GET /items/restock-needed).
+// Status es active o inactive; un item inactive no está a la venta pero se puede seguir editando.
+// State es draft o published: un borrador se está preparando y no aparece en el listado por defecto
+// ni por slug, pero se puede leer por ID. Es independiente de Status.
+// Refs son los IDs del item en sistemas externos (ERP, marketplaces), si tiene.
+// Locale es el idioma de Name y Description. Solo viene cuando el request negoció un idioma
+// (Accept-Language o ?locale=); si el item no tiene traducción es el locale base.
+// Version arranca en 1 y cada update la incrementa; es el ETag del item (concurrencia optimista con If-Match).
+// Available es Stock menos lo reservado por reservas vigentes; no se persiste, se calcula en cada lectura.
 type Item struct {
-	ID          string    `json:"id"`
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	Locale          string         `json:"locale,omitempty"`
+	Slug            string         `json:"slug"`
+	SKU             *string        `json:"sku,omitempty"`
+	Barcode         *string        `json:"barcode,omitempty"`
+	Category        *ItemCategory  `json:"category,omitempty"`
+	Brand           *ItemBrand     `json:"brand,omitempty"`
+	Description     *string        `json:"description,omitempty"`
+	Price           string         `json:"price"`
+	SalePrice       *string        `json:"sale_price,omitempty"`
+	EffectivePrice  string         `json:"effective_price"`
+	TaxRateBPS      int            `json:"tax_rate_bps"`
+	PriceWithTax    string         `json:"price_with_tax"`
+	NextPriceChange *PriceChange   `json:"next_price_change,omitempty"`
+	Currency        string         `json:"currency"`
+	Stock           int            `json:"stock"`
+	Available       int            `json:"available"`
+	Status          ItemStatus     `json:"status"`
+	State           ItemState      `json:"state"`
+	Refs            []ExternalRef  `json:"refs,omitempty"`
+	Attributes      map[string]any `json:"attributes,omitempty"`
+	WeightGrams     *int           `json:"weight_grams,omitempty"`
+	WidthMM         *int           `json:"width_mm,omitempty"`
+	HeightMM        *int           `json:"height_mm,omitempty"`
+	DepthMM         *int           `json:"depth_mm,omitempty"`
+	MinOrderQty     int            `json:"min_order_qty"`
+	ExpiresAt       *string        `json:"expires_at,omitempty"`
+	ReorderPoint    *int           `json:"reorder_point,omitempty"`
+	// SuggestedOrderQty es reorder_point*2 - stock (al menos 1); no se persiste.
+	SuggestedOrderQty *int `json:"suggested_order_qty,omitempty"`
+	// AllowBackorder permite que el stock quede negativo (hasta el piso configurado).
+	AllowBackorder bool `json:"allow_backorder"`
+	// Backordered es true cuando el stock es negativo; se calcula en cada lectura.
+	Backordered bool      `json:"backordered,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
+	// DeletedAt solo tiene valor en los items de la papelera (borrados lógicamente).
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Deleted marca los items borrados lógicamente en el feed de cambios; en el resto de las
+	// respuestas no viene.
+	Deleted bool `json:"deleted,omitempty"`
+	// Similarity es el score de pg_trgm (0 a 1) contra la búsqueda. Solo viene en el listado con fuzzy=true
+	// y en los items relacionados.
+	Similarity *float64 `json:"similarity,omitempty"`
+	// Highlights son los campos buscados con las coincidencias marcadas. Solo viene en el listado
+	// con query y highlight=true.
+	Highlights *Highlights `json:"highlights,omitempty"`
+}
+
+// Highlights son el nombre y la descripción con las coincidencias de la búsqueda envueltas en <em>.
+// El resto del texto está escapado como HTML, así que el cliente lo puede insertar tal cual.
+// Solo vienen los campos donde se buscó (y la descripción, si el item tiene).
+type Highlights struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// ItemStatus es el estado de venta del item.
+type ItemStatus string
+
+const (
+	// StatusActive es el estado por defecto: el item está a la venta.
+	StatusActive ItemStatus = "active"
+	// StatusInactive saca el item de la venta sin borrarlo.
+	StatusInactive ItemStatus = "inactive"
+	// StatusAll solo vale en ListFilter.Status: no filtra por estado.
+	StatusAll ItemStatus = "all"
+)
+
+// ItemState es el estado editorial del item.
+type ItemState string
+
+const (
+	// StatePublished es el estado por defecto: el item es visible.
+	StatePublished ItemState = "published"
+	// StateDraft es un item en preparación: solo se ve por ID o con ?state=draft.
+	StateDraft ItemState = "draft"
+	// StateAll solo vale en ListFilter.State: no filtra por estado editorial.
+	StateAll ItemState = "all"
+)
+
+// ItemCategory es la categoría embebida en el item: lo justo para mostrarla sin otro request.
+type ItemCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ItemBrand es la marca embebida en el item.
+type ItemBrand struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ExternalRef es el ID del item en un sistema externo. Un mismo (System, ExternalID) apunta a un
+// solo item; un item puede tener varios IDs en el mismo sistema.
+type ExternalRef struct {
+	System     string    `json:"system"`
+	ExternalID string    `json:"external_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ExternalRefInput es el payload de POST /items/{id}/refs. System se normaliza a minúsculas.
+type ExternalRefInput struct {
+	System     string `json:"system"`
+	ExternalID string `json:"external_id"`
+}
+
+// Translation es el nombre y la descripción de un item en un locale distinto del base.
+type Translation struct {
+	Locale      string    `json:"locale"`
 	Name        string    `json:"name"`
 	Description *string   `json:"description,omitempty"`
-	Price       string    `json:"price"`
-	Stock       int       `json:"stock"`
-	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// TranslationInput es el payload de PUT /items/{id}/translations/{locale}. Reemplaza la
+// traducción entera: una descripción ausente la deja sin descripción (cae en la del item).
+type TranslationInput struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// PriceChange es el próximo cambio de precio programado, embebido en el item.
+type PriceChange struct {
+	Price       string    `json:"price"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// PriceSchedule es un cambio de precio programado: desde EffectiveAt el item pasa a costar NewPrice.
+// El job de cambios de precio lo aplica y completa AppliedAt; si no puede aplicarlo completa FailedAt
+// y FailureReason. Los aplicados y los fallidos ya no están pendientes y quedan como registro.
+type PriceSchedule struct {
+	ID            string     `json:"id"`
+	ItemID        string     `json:"item_id"`
+	NewPrice      string     `json:"new_price"`
+	EffectiveAt   time.Time  `json:"effective_at"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty"`
+	FailedAt      *time.Time `json:"failed_at,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Motivos por los que el job marca fallido un cambio de precio programado en lugar de aplicarlo.
+const (
+	// PriceScheduleFailureBelowSalePrice: el precio nuevo no queda por encima del de oferta del item.
+	PriceScheduleFailureBelowSalePrice = "below_sale_price"
+	// PriceScheduleFailureCurrencyPrecision: el precio nuevo tiene más decimales que la moneda actual del item.
+	PriceScheduleFailureCurrencyPrecision = "currency_precision"
+)
+
+// CreatePriceScheduleInput es el payload de POST /items/{id}/price-schedules. EffectiveAt tiene que
+// ser futuro y no puede coincidir con otro cambio pendiente del mismo item.
+type CreatePriceScheduleInput struct {
+	NewPrice    string    `json:"new_price"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// Reservation retiene Quantity unidades de un item hasta ExpiresAt (por ejemplo, durante el pago).
+// Mientras está vigente descuenta del available del item.
+type Reservation struct {
+	ID        string    `json:"id"`
+	ItemID    string    `json:"item_id"`
+	Quantity  int       `json:"quantity"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReserveInput es el payload de una reserva. TTLSeconds 0 usa defaultReservationTTL.
+type ReserveInput struct {
+	Quantity   int `json:"quantity"`
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+// Variant es una variante vendible de un item (un talle, un color). Price nil hereda el precio
+// del item y EffectivePrice es el que corresponde en cada caso. Cuando un item tiene variantes,
+// su stock es la suma del stock de ellas.
+type Variant struct {
+	ID             string            `json:"id"`
+	ItemID         string            `json:"item_id"`
+	SKU            string            `json:"sku"`
+	Price          *string           `json:"price"`
+	EffectivePrice string            `json:"effective_price"`
+	Stock          int               `json:"stock"`
+	Attributes     map[string]string `json:"attributes"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// CreateVariantInput es el payload de POST /items/{id}/variants. SKU es obligatorio y único
+// dentro del item; Price es opcional (sin precio hereda el del item).
+type CreateVariantInput struct {
+	SKU        string            `json:"sku"`
+	Price      *string           `json:"price,omitempty"`
+	Stock      int               `json:"stock"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// UpdateVariantInput es el payload de PATCH /items/{id}/variants/{vid}. Lo que no viene no se toca;
+// price en null vuelve a heredar el precio del item y attributes reemplaza el mapa completo.
+type UpdateVariantInput struct {
+	SKU        *string           `json:"sku,omitempty"`
+	Price      *string           `json:"price,omitempty"`
+	Stock      *int              `json:"stock,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// PricePresent indica si el cliente envió "price", para diferenciar "no tocar" de "heredar".
+	PricePresent bool `json:"-"`
+	// AttributesPresent es lo mismo para "attributes": presente en null deja el mapa vacío.
+	AttributesPresent bool `json:"-"`
+}
+
+// StockMovement es una fila del historial de stock: cuánto cambió (Delta), cómo quedó
+// (ResultingStock), por qué y en qué request.
+type StockMovement struct {
+	ID             int64     `json:"id"`
+	ItemID         string    `json:"item_id"`
+	Delta          int       `json:"delta"`
+	ResultingStock int       `json:"resulting_stock"`
+	Reason         string    `json:"reason"`
+	RequestID      string    `json:"request_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// StockMovementPage es una página del historial de stock de un item, del más nuevo al más viejo.
+type StockMovementPage struct {
+	Movements []StockMovement
+	Total     int
+}
+
+// PriceHistoryEntry es una fila del historial de precios: el precio de lista antes y después del
+// cambio, por qué y en qué request. OldPrice es nil en el alta del item.
+type PriceHistoryEntry struct {
+	ID        int64     `json:"id"`
+	ItemID    string    `json:"item_id"`
+	OldPrice  *string   `json:"old_price"`
+	NewPrice  string    `json:"new_price"`
+	Reason    string    `json:"reason"`
+	RequestID string    `json:"request_id,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// PriceHistoryFilter acota el historial de precios por fecha del cambio: From inclusive y To
+// exclusive. nil no acota.
+type PriceHistoryFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// PriceHistoryPage es una página del historial de precios de un item, del más nuevo al más viejo.
+type PriceHistoryPage struct {
+	Entries []PriceHistoryEntry
+	Total   int
+}
+
+// AuditAction es qué le pasó al item en una entrada de auditoría.
+type AuditAction string
+
+const (
+	AuditCreated AuditAction = "created"
+	AuditUpdated AuditAction = "updated"
+	AuditDeleted AuditAction = "deleted"
+)
+
+// FieldChange es un campo que cambió: el valor antes y después, como viene en el JSON del item.
+// Old es null en el alta y cuando el campo no tenía valor; New es null cuando se limpió.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// AuditEntry es una fila de la auditoría de un item. Changes viene vacío en las bajas. Actor queda
+// vacío hasta que la API tenga autenticación.
+type AuditEntry struct {
+	ID        int64         `json:"id"`
+	ItemID    string        `json:"item_id"`
+	Action    AuditAction   `json:"action"`
+	Changes   []FieldChange `json:"changes"`
+	RequestID string        `json:"request_id,omitempty"`
+	Actor     string        `json:"actor,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// AuditPage es una página de la auditoría de un item, de la más nueva a la más vieja.
+type AuditPage struct {
+	Entries []AuditEntry
+	Total   int
+}
+
+// StockAdjustmentInput es el payload de un ajuste de stock (recepción de mercadería, rotura, conteo).
+// Reason vacío queda como StockReasonAdjustment.
+type StockAdjustmentInput struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+}
+
 // CreateItemInput representa el payload para crear un item.
 // Nota: Price es string por precisión (DB: numeric(10,2)).
+// Slug es opcional: si no viene, el service lo genera a partir del nombre. SKU es obligatorio.
+// Barcode es opcional; si viene tiene que ser un EAN-13 o UPC-A con dígito verificador válido.
+// CategoryID y BrandID son opcionales y tienen que ser una categoría y una marca existentes.
+// Currency es opcional: si no viene se usa la moneda por defecto del service (DEFAULT_CURRENCY).
+// Attributes es opcional: hasta 50 claves de hasta 64 caracteres y valores string (hasta 512
+// caracteres), número o bool.
+// WeightGrams y las medidas son opcionales: hasta 1.000 kg y 10 m.
+// SalePrice es opcional, con el mismo formato que Price y menor que él.
+// TaxRateBPS es opcional (0 a 10000): si no viene se usa la alícuota por defecto del service.
+// MinOrderQty es opcional: si no viene es 1.
+// ExpiresAt es opcional: una fecha YYYY-MM-DD posterior a hoy.
+// ReorderPoint es opcional: si viene tiene que ser 0 o más.
+// State es opcional: draft o published (el default).
 type CreateItemInput struct {
 	Name        string  `json:"name"`
+	Slug        string  `json:"slug,omitempty"`
+	SKU         *string `json:"sku,omitempty"`
+	Barcode     *string `json:"barcode,omitempty"`
+	CategoryID  *string `json:"category_id,omitempty"`
+	BrandID     *string `json:"brand_id,omitempty"`
 	Description *string `json:"description,omitempty"`
 	Price       string  `json:"price"`
+	SalePrice   *string `json:"sale_price,omitempty"`
+	TaxRateBPS  *int    `json:"tax_rate_bps,omitempty"`
+	Currency    string  `json:"currency,omitempty"`
 	Stock       int     `json:"stock"`
+	// Attributes se guarda tal cual en la columna jsonb.
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	WeightGrams  *int           `json:"weight_grams,omitempty"`
+	WidthMM      *int           `json:"width_mm,omitempty"`
+	HeightMM     *int           `json:"height_mm,omitempty"`
+	DepthMM      *int           `json:"depth_mm,omitempty"`
+	MinOrderQty  *int           `json:"min_order_qty,omitempty"`
+	ExpiresAt    *string        `json:"expires_at,omitempty"`
+	ReorderPoint *int           `json:"reorder_point,omitempty"`
+	State        ItemState      `json:"state,omitempty"`
+	// AllowBackorder permite crear el item con stock negativo (hasta el piso configurado).
+	AllowBackorder bool `json:"allow_backorder,omitempty"`
+}
+
+// ReplaceItemInput representa el payload de PUT: reemplaza el item completo.
+// A diferencia de PATCH, lo que no viene vuelve a su valor por defecto: Description nil es NULL,
+// Stock ausente es 0, AllowBackorder ausente es false y Slug vacío se regenera a partir del nombre.
+// El SKU, el barcode, la categoría, la marca, el estado, la moneda, los atributos, el peso, las medidas,
+// el precio de oferta, la alícuota, la cantidad mínima, el vencimiento y el punto de reposición no se reemplazan; el precio se valida con la moneda que ya tiene el item
+// y tiene que seguir siendo mayor que el de oferta.
+type ReplaceItemInput struct {
+	Name           string  `json:"name"`
+	Slug           string  `json:"slug,omitempty"`
+	Description    *string `json:"description,omitempty"`
+	Price          string  `json:"price"`
+	Stock          int     `json:"stock"`
+	AllowBackorder bool    `json:"allow_backorder,omitempty"`
+}
+
+// DuplicateItemInput es el payload de POST /items/{id}/duplicate. SKU es el de la copia (el SKU es
+// único, no se copia); sin CopyStock la copia arranca con stock 0.
+type DuplicateItemInput struct {
+	SKU       string `json:"sku"`
+	CopyStock bool   `json:"copy_stock"`
 }
 
 // UpdateItemInput representa el payload para actualizar un item.
+// Si cambia Name y no viene Slug, el service regenera el slug a partir del nuevo nombre.
 type UpdateItemInput struct {
 	Name        *string `json:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty"`
+	SKU         *string `json:"sku,omitempty"`
+	Barcode     *string `json:"barcode,omitempty"`
+	CategoryID  *string `json:"category_id,omitempty"`
+	BrandID     *string `json:"brand_id,omitempty"`
 	Description *string `json:"description,omitempty"`
 	Price       *string `json:"price,omitempty"`
-	Stock       *int    `json:"stock,omitempty"`
+	// SalePrice tiene que ser menor que el precio (el que viene en el mismo update o el actual).
+	SalePrice *string `json:"sale_price,omitempty"`
+	// TaxRateBPS cambia la alícuota (0 a 10000); no admite null.
+	TaxRateBPS *int `json:"tax_rate_bps,omitempty"`
+	// Currency solo se puede cambiar con AllowCurrencyChange; si no, es ErrorCurrencyChange
+	// (salvo que sea la misma moneda que ya tiene el item).
+	Currency *string `json:"currency,omitempty"`
+	Stock    *int    `json:"stock,omitempty"`
+	// AllowBackorder activa o desactiva los backorders. Desactivarlo con stock negativo es ErrorInvalidStock.
+	AllowBackorder *bool `json:"allow_backorder,omitempty"`
+	// Status pasa el item a active o inactive; otro valor es ErrorInvalidStatus.
+	Status *ItemStatus `json:"status,omitempty"`
+	// State cambia el estado editorial. No viene del cliente: lo usan publish y unpublish, que
+	// validan que el item esté completo.
+	State *ItemState `json:"-"`
+	// Attributes reemplaza el objeto completo (no se mezcla con el actual).
+	Attributes  map[string]any `json:"attributes,omitempty"`
+	WeightGrams *int           `json:"weight_grams,omitempty"`
+	WidthMM     *int           `json:"width_mm,omitempty"`
+	HeightMM    *int           `json:"height_mm,omitempty"`
+	DepthMM     *int           `json:"depth_mm,omitempty"`
+	// AttributesPatch es el objeto attributes de un application/merge-patch+json: en lugar de
+	// reemplazar, se mezcla con los atributos actuales (RFC 7386, una clave en null se borra) y se
+	// valida el resultado. No se serializa.
+	AttributesPatch map[string]any `json:"-"`
+	// MinOrderQty cambia la cantidad mínima de reserva (al menos 1); no admite null.
+	MinOrderQty *int `json:"min_order_qty,omitempty"`
+	// ExpiresAt cambia la fecha de vencimiento (YYYY-MM-DD). A diferencia del alta puede ser pasada,
+	// para corregir la carga de un item que ya venció.
+	ExpiresAt *string `json:"expires_at,omitempty"`
+	// ReorderPoint cambia el punto de reposición (0 o más).
+	ReorderPoint *int `json:"reorder_point,omitempty"`
+	// AllowCurrencyChange viene de ?allow_currency_change=true en el PATCH.
+	AllowCurrencyChange bool `json:"-"`
 	// DescriptionPresent indica si el cliente envió el campo "description".
 	// No se serializa, solo sirve para diferenciar "no tocar" vs "set null".
 	DescriptionPresent bool `json:"-"`
+	// BarcodePresent es lo mismo para "barcode": presente en null limpia el código.
+	BarcodePresent bool `json:"-"`
+	// SalePricePresent es lo mismo para "sale_price": presente en null termina la oferta.
+	SalePricePresent bool `json:"-"`
+	// CategoryIDPresent es lo mismo para "category_id": presente en null deja el item sin categoría.
+	CategoryIDPresent bool `json:"-"`
+	// BrandIDPresent es lo mismo para "brand_id".
+	BrandIDPresent bool `json:"-"`
+	// AttributesPresent es lo mismo para "attributes": presente en null borra todos los atributos.
+	AttributesPresent bool `json:"-"`
+	// WeightGramsPresent, WidthMMPresent, HeightMMPresent y DepthMMPresent son lo mismo para el peso
+	// y cada medida: presentes en null las limpian.
+	WeightGramsPresent bool `json:"-"`
+	WidthMMPresent     bool `json:"-"`
+	HeightMMPresent    bool `json:"-"`
+	DepthMMPresent     bool `json:"-"`
+	// ExpiresAtPresent es lo mismo para "expires_at": presente en null saca el vencimiento.
+	ExpiresAtPresent bool `json:"-"`
+	// ReorderPointPresent es lo mismo para "reorder_point": presente en null saca el item del reporte de reposición.
+	ReorderPointPresent bool `json:"-"`
+	// IfVersion, si no es nil, es la versión que el cliente espera (If-Match). Si el item
+	// tiene otra, el update no se aplica y devuelve ErrorVersionMismatch.
+	IfVersion *int `json:"-"`
+}
+
+// MatchMode define cómo se compara el texto de búsqueda contra name.
+type MatchMode string
+
+const (
+	// MatchContains busca el texto en cualquier parte del nombre (ILIKE '%q%').
+	MatchContains MatchMode = "contains"
+	// MatchPrefix busca nombres que empiezan con el texto. Puede usar índice.
+	MatchPrefix MatchMode = "prefix"
+	// MatchExact compara el nombre completo, sin distinguir mayúsculas.
+	MatchExact MatchMode = "exact"
+)
+
+// DeletionScope define qué items ve el listado según su borrado lógico.
+type DeletionScope string
+
+const (
+	// ScopeActive son los items vivos. Es el valor cero, así un ListFilter sin Scope no ve la papelera.
+	ScopeActive DeletionScope = ""
+	// ScopeDeleted son los items de la papelera.
+	ScopeDeleted DeletionScope = "deleted"
+	// ScopeAll son todos los items, borrados o no.
+	ScopeAll DeletionScope = "all"
+)
+
+// SortKey es una clave de orden del listado. El prefijo "-" indica orden descendente
+// (por ejemplo "price" o "-price").
+type SortKey string
+
+// Field devuelve el campo de la clave, sin el prefijo de dirección.
+func (key SortKey) Field() string {
+	return strings.TrimPrefix(string(key), "-")
+}
+
+// Descending indica si la clave ordena de mayor a menor.
+func (key SortKey) Descending() bool {
+	return strings.HasPrefix(string(key), "-")
+}
+
+// ListFilter agrupa los filtros del listado de items.
+// Se comparte entre List y Count para que el total siempre coincida con la página.
+// Sort solo afecta a List: las claves se aplican en orden y vacío equivale a "-created_at".
+type ListFilter struct {
+	Query string
+	Match MatchMode
+	// SearchFields son las columnas donde se busca Query (OR entre ellas). Vacío equivale a name, sku y
+	// barcode; sku y barcode se comparan exacto, sin distinguir mayúsculas.
+	SearchFields []string
+	// SearchTranslations hace que Query también busque en los nombres y descripciones traducidos
+	// (en cualquier locale). No aplica a Fuzzy.
+	SearchTranslations bool
+	// Highlight pide que cada item del listado traiga Highlights. No cambia la query.
+	Highlight bool
+	// NameEq busca un nombre exacto, sin pasar por Match. Por defecto distingue mayúsculas
+	// (usa ux_items_name); con NameEqIgnoreCase compara lower(name).
+	NameEq           string
+	NameEqIgnoreCase bool
+	// Fuzzy busca Query por similitud de trigramas sobre name (pg_trgm), en lugar de Match,
+	// y ordena por similitud. FuzzyThreshold es el score mínimo; lo completa el service.
+	Fuzzy          bool
+	FuzzyThreshold float64
+	// MinPrice y MaxPrice acotan el precio (inclusive). Vacío no filtra.
+	MinPrice string
+	MaxPrice string
+	// UseEffectivePrice hace que MinPrice, MaxPrice y el orden por price usen el precio efectivo
+	// (el de oferta si hay) en lugar del de lista.
+	UseEffectivePrice bool
+	// InStock filtra por disponibilidad: true es stock > 0, false es stock = 0. nil no filtra.
+	InStock *bool
+	// StockGTE y StockLTE acotan el stock (inclusive). nil no filtra.
+	StockGTE *int
+	StockLTE *int
+	// SKU busca el item con ese SKU exacto (ya normalizado a mayúsculas). Vacío no filtra.
+	SKU string
+	// CategoryID deja solo los items de esa categoría. Vacío no filtra.
+	CategoryID string
+	// BrandID deja solo los items de esa marca. Vacío no filtra.
+	BrandID string
+	// Status deja solo los items en ese estado; StatusAll o vacío no filtra. GET /items y
+	// GET /items/count usan StatusActive si el cliente no pide otro.
+	Status ItemStatus
+	// State deja solo los items en ese estado editorial; StateAll o vacío no filtra. GET /items y
+	// GET /items/count usan StatePublished si el cliente no pide otro.
+	State ItemState
+	// Currency deja solo los items con precio en esa moneda. Vacío no filtra.
+	Currency string
+	// Attributes deja solo los items que tienen todos esos atributos (?attr.color=red). Los valores
+	// vienen del query string, sin tipo; ver attributeFilterDocuments.
+	Attributes map[string]string
+	// MaxWeight deja solo los items con peso cargado de hasta MaxWeight gramos (inclusive). nil no filtra.
+	MaxWeight *int
+	// MinOrderQtyLTE deja solo los items que se pueden reservar en cantidades de hasta MinOrderQtyLTE
+	// unidades (por ejemplo 1 oculta los que solo se venden por mayor). nil no filtra.
+	MinOrderQtyLTE *int
+	// ExpiringBefore deja solo los items que vencen antes de esa fecha (YYYY-MM-DD, exclusive).
+	// Vacío no filtra.
+	ExpiringBefore string
+	// ExcludeExpired en true deja afuera los items vencidos (los que no tienen vencimiento quedan).
+	// GET /items y GET /items/count lo usan por defecto junto con StatusActive: un item vencido se
+	// trata como inactive. nil no filtra.
+	ExcludeExpired *bool
+	Sort           []SortKey
+	// Scope elige entre items vivos (por defecto), borrados o todos.
+	Scope DeletionScope
+}
+
+// CreatedAtID es la posición de un item en el orden por defecto del listado (created_at DESC, id DESC).
+// Es lo que codifica el cursor de paginación: la página siguiente empieza después de este par.
+type CreatedAtID struct {
+	CreatedAt time.Time
+	ID        string
+	// Ranked indica que el item coincidió exacto con un SKU o barcode buscado. Una búsqueda por
+	// identificadores ordena primero esas coincidencias, así que el cursor tiene que saber de qué
+	// lado del ranking quedó; sin ese ranking no se usa.
+	Ranked bool
+}
+
+// UpdatedAtID es la posición de un item en el feed de cambios (updated_at ASC, id ASC).
+// Es lo que codifica next_since: la llamada siguiente sigue después de este par.
+type UpdatedAtID struct {
+	UpdatedAt time.Time
+	ID        string
+}
+
+// ChangesPage es una tanda del feed de cambios. Next es la posición desde la que sigue la próxima
+// llamada: la del último item, o la recibida si la tanda vino vacía.
+type ChangesPage struct {
+	Items   []Item
+	Next    UpdatedAtID
+	HasMore bool
+}
+
+// EventOperation es el tipo de mutación de un Event.
+type EventOperation string
+
+// Operaciones de los eventos de GET /items/events.
+const (
+	EventCreated EventOperation = "created"
+	EventUpdated EventOperation = "updated"
+	EventDeleted EventOperation = "deleted"
+)
+
+// Event es una mutación ya confirmada de un item, tal como sale por GET /items/events. Item es el
+// item después del cambio; no viene en las operaciones masivas que solo conocen los IDs (el borrado
+// masivo y el ajuste masivo de precios).
+type Event struct {
+	ID        string         `json:"id"`
+	Operation EventOperation `json:"operation"`
+	Item      *Item          `json:"item,omitempty"`
+}
+
+// Type es el nombre del evento: "item." más la operación ("item.created"). Es el event: de
+// GET /items/events y el event_type del outbox.
+func (event Event) Type() string {
+	return "item." + string(event.Operation)
+}
+
+// ChangesChannel es el canal de PostgreSQL donde se avisa cada alta, cambio o baja de un item ya
+// confirmado, para que las otras instancias invaliden lo que tengan cacheado.
+const ChangesChannel = "items_changed"
+
+// ChangePurged es la operación de ChangeNotification cuando un item sale de la papelera para siempre.
+const ChangePurged EventOperation = "purged"
+
+// ChangeRestored es la operación de ChangeNotification cuando se restaura un snapshot del catálogo
+// entero (POST /admin/import/snapshot); el ID va vacío.
+const ChangeRestored EventOperation = "restored"
+
+// ChangeNotification es el payload de cada NOTIFY en ChangesChannel: la operación del evento del
+// cambio o ChangePurged.
+type ChangeNotification struct {
+	ID        string         `json:"id"`
+	Operation EventOperation `json:"op"`
+}
+
+// Suggestion es una sugerencia del autocompletado: solo lo que el buscador necesita mostrar.
+type Suggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ListPage es el resultado del listado: los items de la página y el total según el filtro.
+type ListPage struct {
+	Items []Item
+	Total int
+	// TotalIsEstimate indica que Total es la estimación del planner y no un COUNT exacto.
+	TotalIsEstimate bool
+	// HasMore indica si hay items después de la página. Solo lo completa ListAfter.
+	HasMore bool
+	// Cursor es la posición del último item, para pedir la página siguiente por cursor; nil si la
+	// página está vacía o el orden del listado no admite cursor.
+	Cursor *CreatedAtID
+}
+
+// BulkDeleteResult es el resultado de borrar varios items por ID.
+// Missing son los IDs pedidos que no existían, en el orden del pedido.
+type BulkDeleteResult struct {
+	Deleted int      `json:"deleted"`
+	Missing []string `json:"missing"`
+}
+
+// PriceAdjustmentType es cómo se aplica un ajuste masivo de precios.
+type PriceAdjustmentType string
+
+const (
+	// AdjustmentPercent suma Value por ciento al precio de lista (negativo lo baja).
+	AdjustmentPercent PriceAdjustmentType = "percent"
+	// AdjustmentFixed suma Value al precio de lista, en la moneda del item.
+	AdjustmentFixed PriceAdjustmentType = "fixed"
+)
+
+// PriceAdjustment es el ajuste a aplicar. Value es un decimal con signo y hasta dos decimales ("5", "-2.50").
+type PriceAdjustment struct {
+	Type  PriceAdjustmentType `json:"type"`
+	Value string              `json:"value"`
+}
+
+// PriceAdjustmentFilter elige los items de un ajuste masivo con un subconjunto de los filtros del
+// listado. Vacío son todos los items vivos, de cualquier status y state.
+type PriceAdjustmentFilter struct {
+	CategoryID string `json:"category_id,omitempty"`
+	BrandID    string `json:"brand_id,omitempty"`
+	Query      string `json:"query,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+}
+
+// PriceAdjustmentInput es el payload de POST /items/price-adjustments. Con DryRun solo se calcula el
+// resultado. ConfirmOver viene de ?confirm_over= y autoriza ajustes de más items que el tope, hasta
+// ConfirmOver.
+type PriceAdjustmentInput struct {
+	Filter      PriceAdjustmentFilter `json:"filter"`
+	Adjustment  PriceAdjustment       `json:"adjustment"`
+	DryRun      bool                  `json:"dry_run"`
+	ConfirmOver *int                  `json:"-"`
 }
 
+// PriceAdjustmentSample es un item del ajuste con su precio de lista antes y después.
+type PriceAdjustmentSample struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	OldPrice string `json:"old_price"`
+	NewPrice string `json:"new_price"`
+}
+
+// PriceAdjustmentPreview es el ajuste calculado sin aplicar: cuántos items toca, cuántos quedarían
+// con precio de cero o menos y cuántos con el precio de oferta en o por encima del de lista, y una muestra.
+type PriceAdjustmentPreview struct {
+	Affected          int
+	NonPositive       int
+	NotAboveSalePrice int
+	Sample            []PriceAdjustmentSample
+}
+
+// PriceAdjustmentResult es la respuesta de un ajuste masivo. Sample es la muestra del cálculo
+// previo, también cuando el ajuste se aplicó.
+type PriceAdjustmentResult struct {
+	Affected int                     `json:"affected"`
+	DryRun   bool                    `json:"dry_run"`
+	Sample   []PriceAdjustmentSample `json:"sample"`
+}
+
+// BulkUpdateEntry es una entrada de un update masivo: el item a cambiar y sus cambios,
+// con la misma semántica que UpdateItemInput en PATCH /items/{id}.
+type BulkUpdateEntry struct {
+	ID    string
+	Input UpdateItemInput
+}
+
+// BulkUpdateResult es el resultado de una entrada de un update masivo, en el orden del pedido.
+// Err es nil si la entrada se aplicó (Item es el item actualizado); ErrorNotApplied si la entrada
+// era válida pero no se aplicó porque falló otra.
+type BulkUpdateResult struct {
+	ID   string
+	Item Item
+	Err  error
+}
+
+// ItemCount es el total de items según un filtro, sin la página.
+type ItemCount struct {
+	Total int
+	// TotalIsEstimate indica que Total es la estimación del planner y no un COUNT exacto.
+	TotalIsEstimate bool
+}
+
+// CollectionVersion identifica el estado del catálogo completo: cualquier alta, cambio o baja
+// mueve LastUpdatedAt o Count. Sirve para derivar el ETag del listado sin correrlo.
+type CollectionVersion struct {
+	// LastUpdatedAt es el updated_at más reciente; cero si no hay items.
+	LastUpdatedAt time.Time
+	Count         int
+	// Today es la fecha de la base (current_date, YYYY-MM-DD): un item vence al cambiar el día
+	// sin que se mueva ninguna fila, así que el ETag de un listado sin vencidos depende también de ella.
+	Today string
+}
+
+// CatalogStats resume el tamaño del catálogo para métricas.
+type CatalogStats struct {
+	Total      int
+	OutOfStock int
+}
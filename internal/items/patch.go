@@ -0,0 +1,159 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+)
+
+// Content types aceptados por PATCH /items/{id}.
+const (
+	contentTypeJSON       = "application/json"
+	contentTypeMergePatch = "application/merge-patch+json"
+)
+
+// errorInvalidPatchBody indica que el body no es un objeto JSON válido para el PATCH.
+var errorInvalidPatchBody = errors.New("invalid patch body")
+
+// patchFields lista los campos que acepta un PATCH y si admiten null.
+// Un campo nullable enviado en null se limpia en DB (SET NULL); los demás no pueden quedar vacíos.
+// sku no es nullable aunque los items previos a la columna no lo tengan: una vez cargado no se borra.
+var patchFields = map[string]bool{
+	"name":            false,
+	"slug":            false,
+	"sku":             false,
+	"barcode":         true,
+	"category_id":     true,
+	"brand_id":        true,
+	"status":          false,
+	"currency":        false,
+	"attributes":      true,
+	"weight_grams":    true,
+	"width_mm":        true,
+	"height_mm":       true,
+	"depth_mm":        true,
+	"description":     true,
+	"price":           false,
+	"sale_price":      true,
+	"tax_rate_bps":    false,
+	"min_order_qty":   false,
+	"expires_at":      true,
+	"reorder_point":   true,
+	"stock":           false,
+	"allow_backorder": false,
+}
+
+// patchDocument es el body de un PATCH con registro de qué campos vinieron, incluso en null.
+// Lo comparten application/json y application/merge-patch+json; solo cambia cómo se interpreta null.
+type patchDocument struct {
+	raw map[string]json.RawMessage
+}
+
+// decodePatchDocument lee el body como objeto JSON conservando la presencia de cada campo.
+func decodePatchDocument(body io.Reader) (patchDocument, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil || raw == nil {
+		return patchDocument{}, errorInvalidPatchBody
+	}
+	return patchDocument{raw: raw}, nil
+}
+
+// present indica si el cliente envió el campo (con cualquier valor, incluido null).
+func (document patchDocument) present(field string) bool {
+	_, ok := document.raw[field]
+	return ok
+}
+
+// isNull indica si el campo vino explícitamente en null.
+func (document patchDocument) isNull(field string) bool {
+	value, ok := document.raw[field]
+	return ok && string(value) == "null"
+}
+
+// updateInput convierte el documento en UpdateItemInput.
+// Con mergePatch (RFC 7386) null significa "borrar el campo": se acepta en campos nullable
+// y es un error de validación en los obligatorios. Sin mergePatch se mantiene el comportamiento
+// histórico de application/json: null solo tiene efecto en description y se ignora en el resto.
+func (document patchDocument) updateInput(mergePatch bool) (UpdateItemInput, error) {
+	if mergePatch {
+		for field, nullable := range patchFields {
+			if !nullable && document.isNull(field) {
+				return UpdateItemInput{}, &ValidationError{Field: field, Message: field + " cannot be null"}
+			}
+		}
+	}
+
+	// Re-encode y decode al struct para reutilizar tags y tipos.
+	encoded, err := json.Marshal(document.raw)
+	if err != nil {
+		return UpdateItemInput{}, errorInvalidPatchBody
+	}
+	var input UpdateItemInput
+	if err := json.Unmarshal(encoded, &input); err != nil {
+		return UpdateItemInput{}, errorInvalidPatchBody
+	}
+
+	input.DescriptionPresent = document.present("description")
+	input.BarcodePresent = document.present("barcode")
+	input.SalePricePresent = document.present("sale_price")
+	input.CategoryIDPresent = document.present("category_id")
+	input.BrandIDPresent = document.present("brand_id")
+	input.AttributesPresent = document.present("attributes")
+	input.WeightGramsPresent = document.present("weight_grams")
+	input.WidthMMPresent = document.present("width_mm")
+	input.HeightMMPresent = document.present("height_mm")
+	input.DepthMMPresent = document.present("depth_mm")
+	input.ExpiresAtPresent = document.present("expires_at")
+	input.ReorderPointPresent = document.present("reorder_point")
+	return input, nil
+}
+
+// mergePatchInput es updateInput para application/merge-patch+json. Además de null como borrado,
+// el objeto attributes no reemplaza al actual sino que se mezcla con él (AttributesPatch), así que
+// puede traer null para borrar una clave; la mezcla la hace el service con el item bloqueado.
+func (document patchDocument) mergePatchInput() (UpdateItemInput, error) {
+	input, err := document.updateInput(true)
+	if err != nil {
+		return UpdateItemInput{}, err
+	}
+	if input.Attributes != nil {
+		input.AttributesPatch, input.Attributes = input.Attributes, nil
+	}
+	return input, nil
+}
+
+// mergePatch aplica patch sobre target según RFC 7386: si patch es un objeto se mezcla clave por
+// clave, recursivamente, y una clave en null se borra; cualquier otro valor reemplaza a target.
+// No modifica target.
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, _ := target.(map[string]any)
+	merged := make(map[string]any, len(targetObject)+len(patchObject))
+	for key, value := range targetObject {
+		merged[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergePatch(merged[key], value)
+	}
+	return merged
+}
+
+// isJSONPatch indica si el Content-Type del request es application/json-patch+json.
+func isJSONPatch(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == contentTypeJSONPatch
+}
+
+// isMergePatch indica si el Content-Type del request es application/merge-patch+json.
+func isMergePatch(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == contentTypeMergePatch
+}
@@ -0,0 +1,267 @@
+package items
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchDocument_UpdateInput(t *testing.T) {
+	t.Run("presence is tracked in both modes", func(t *testing.T) {
+		for _, mergePatch := range []bool{false, true} {
+			document, err := decodePatchDocument(strings.NewReader(`{"description":null,"stock":3}`))
+			require.NoError(t, err)
+
+			input, err := document.updateInput(mergePatch)
+
+			require.NoError(t, err)
+			require.True(t, input.DescriptionPresent)
+			require.Nil(t, input.Description)
+			require.Equal(t, 3, *input.Stock)
+			require.Nil(t, input.Name)
+			require.Nil(t, input.Price)
+		}
+	})
+
+	t.Run("absent fields are untouched", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"name":"Phone"}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.False(t, input.DescriptionPresent)
+		require.Equal(t, "Phone", *input.Name)
+	})
+
+	// Cada campo registrado en patchFields: los nullable se limpian con null en merge patch,
+	// los obligatorios devuelven un error de validación sobre ese campo.
+	for field, nullable := range patchFields {
+		t.Run("merge patch null "+field, func(t *testing.T) {
+			document, err := decodePatchDocument(strings.NewReader(`{"` + field + `":null}`))
+			require.NoError(t, err)
+
+			_, err = document.updateInput(true)
+
+			if nullable {
+				require.NoError(t, err)
+				return
+			}
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, field, validationError.Field)
+			require.ErrorIs(t, err, ErrorInvalidInput)
+		})
+
+		t.Run("json null "+field+" keeps legacy behavior", func(t *testing.T) {
+			document, err := decodePatchDocument(strings.NewReader(`{"` + field + `":null}`))
+			require.NoError(t, err)
+
+			_, err = document.updateInput(false)
+
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("sku can be changed in both modes", func(t *testing.T) {
+		for _, mergePatch := range []bool{false, true} {
+			document, err := decodePatchDocument(strings.NewReader(`{"sku":"KB-002","stock":3}`))
+			require.NoError(t, err)
+
+			input, err := document.updateInput(mergePatch)
+
+			require.NoError(t, err)
+			require.Equal(t, "KB-002", *input.SKU)
+		}
+	})
+
+	t.Run("category_id presence is tracked", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"category_id":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.CategoryIDPresent)
+		require.Nil(t, input.CategoryID)
+	})
+
+	t.Run("brand_id presence is tracked", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"brand_id":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.BrandIDPresent)
+		require.Nil(t, input.BrandID)
+	})
+
+	t.Run("attributes null clears them and a map replaces them", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"attributes":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.AttributesPresent)
+		require.Nil(t, input.Attributes)
+
+		document, err = decodePatchDocument(strings.NewReader(`{"attributes":{"color":"red","size":42}}`))
+		require.NoError(t, err)
+
+		input, err = document.updateInput(false)
+
+		require.NoError(t, err)
+		require.True(t, input.AttributesPresent)
+		require.Equal(t, map[string]any{"color": "red", "size": float64(42)}, input.Attributes)
+	})
+
+	t.Run("merge patch attributes are merged instead of replaced", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"attributes":{"color":"red","size":null}}`))
+		require.NoError(t, err)
+
+		input, err := document.mergePatchInput()
+
+		require.NoError(t, err)
+		require.True(t, input.AttributesPresent)
+		require.Nil(t, input.Attributes)
+		require.Equal(t, map[string]any{"color": "red", "size": nil}, input.AttributesPatch)
+
+		document, err = decodePatchDocument(strings.NewReader(`{"attributes":null}`))
+		require.NoError(t, err)
+
+		input, err = document.mergePatchInput()
+
+		require.NoError(t, err)
+		require.True(t, input.AttributesPresent)
+		require.Nil(t, input.Attributes)
+		require.Nil(t, input.AttributesPatch)
+	})
+
+	t.Run("sale price null ends the sale", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"sale_price":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.SalePricePresent)
+		require.Nil(t, input.SalePrice)
+	})
+
+	t.Run("tax rate", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"tax_rate_bps":1900}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.Equal(t, 1900, *input.TaxRateBPS)
+	})
+
+	t.Run("expiration date null clears it", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"expires_at":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.ExpiresAtPresent)
+		require.Nil(t, input.ExpiresAt)
+	})
+
+	t.Run("reorder point null clears it", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"reorder_point":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.ReorderPointPresent)
+		require.Nil(t, input.ReorderPoint)
+	})
+
+	t.Run("minimum order quantity", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"min_order_qty":6}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.Equal(t, 6, *input.MinOrderQty)
+	})
+
+	t.Run("weight and dimensions are independently nullable", func(t *testing.T) {
+		document, err := decodePatchDocument(strings.NewReader(`{"weight_grams":1500,"depth_mm":null}`))
+		require.NoError(t, err)
+
+		input, err := document.updateInput(true)
+
+		require.NoError(t, err)
+		require.True(t, input.WeightGramsPresent)
+		require.Equal(t, 1500, *input.WeightGrams)
+		require.True(t, input.DepthMMPresent)
+		require.Nil(t, input.DepthMM)
+		require.False(t, input.WidthMMPresent)
+		require.False(t, input.HeightMMPresent)
+	})
+
+	t.Run("invalid bodies", func(t *testing.T) {
+		for _, body := range []string{`{`, `[]`, `null`, `"x"`} {
+			_, err := decodePatchDocument(strings.NewReader(body))
+			require.ErrorIs(t, err, errorInvalidPatchBody, body)
+		}
+
+		document, err := decodePatchDocument(strings.NewReader(`{"stock":"abc"}`))
+		require.NoError(t, err)
+		_, err = document.updateInput(true)
+		require.ErrorIs(t, err, errorInvalidPatchBody)
+	})
+}
+
+func TestMergePatch(t *testing.T) {
+	// Casos de RFC 7386, apéndice A.
+	tests := []struct {
+		name   string
+		target any
+		patch  any
+		want   any
+	}{
+		{"replaces a value", map[string]any{"a": "b"}, map[string]any{"a": "c"}, map[string]any{"a": "c"}},
+		{"adds a key", map[string]any{"a": "b"}, map[string]any{"b": "c"}, map[string]any{"a": "b", "b": "c"}},
+		{"null deletes a key", map[string]any{"a": "b", "b": "c"}, map[string]any{"a": nil}, map[string]any{"b": "c"}},
+		{
+			"nested objects are merged",
+			map[string]any{"a": map[string]any{"b": "c", "d": "e"}},
+			map[string]any{"a": map[string]any{"b": "x", "d": nil}},
+			map[string]any{"a": map[string]any{"b": "x"}},
+		},
+		{"a scalar replaces an object", map[string]any{"a": map[string]any{"b": "c"}}, map[string]any{"a": "d"}, map[string]any{"a": "d"}},
+		{"an object replaces a scalar", map[string]any{"a": "b"}, map[string]any{"a": map[string]any{"c": nil, "d": "e"}}, map[string]any{"a": map[string]any{"d": "e"}}},
+		{"missing target", nil, map[string]any{"a": "b"}, map[string]any{"a": "b"}},
+		{"a non-object patch replaces everything", map[string]any{"a": "b"}, []any{"c"}, []any{"c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, mergePatch(tt.target, tt.patch))
+		})
+	}
+
+	t.Run("target is not modified", func(t *testing.T) {
+		target := map[string]any{"a": "b"}
+
+		mergePatch(target, map[string]any{"a": nil, "c": "d"})
+
+		require.Equal(t, map[string]any{"a": "b"}, target)
+	})
+}
+
+func TestIsMergePatch(t *testing.T) {
+	require.True(t, isMergePatch("application/merge-patch+json"))
+	require.True(t, isMergePatch("application/merge-patch+json; charset=utf-8"))
+	require.False(t, isMergePatch("application/json"))
+	require.False(t, isMergePatch(""))
+}
@@ -0,0 +1,32 @@
+package items
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxExternalIDLength es el largo máximo del ID de un item en un sistema externo.
+const maxExternalIDLength = 128
+
+// externalSystemPattern acepta el nombre de un sistema externo ya normalizado: "erp", "mercado-libre".
+var externalSystemPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// normalizeExternalRef recorta espacios y pasa el sistema a minúsculas, así "ERP" y "erp" son el
+// mismo sistema. El ID externo se respeta tal cual: cada sistema decide si distingue mayúsculas.
+func normalizeExternalRef(system, externalID string) (string, string) {
+	return strings.ToLower(strings.TrimSpace(system)), strings.TrimSpace(externalID)
+}
+
+// externalRefError valida una ref ya normalizada y devuelve el error de campo correspondiente,
+// o nil si es válida.
+func externalRefError(system, externalID string) error {
+	if !externalSystemPattern.MatchString(system) {
+		return &ValidationError{Field: "system", Message: "system must be 1 to 32 lowercase letters, digits, underscores or hyphens"}
+	}
+	if length := utf8.RuneCountInString(externalID); length == 0 || length > maxExternalIDLength {
+		return &ValidationError{Field: "external_id", Message: fmt.Sprintf("external_id must have between 1 and %d characters", maxExternalIDLength)}
+	}
+	return nil
+}
@@ -2,12 +2,18 @@ package items
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Lelo88/catalog-api-golang/internal/currency"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
 )
 
 // dbQuerier define el contrato para acceder a la base de datos.
@@ -20,215 +26,2858 @@ type dbQuerier interface {
 // Contiene SQL y mapeo DB → modelo.
 type Repository struct {
 	database dbQuerier
+	budget   db.QueryBudget
+}
+
+// txBeginner lo implementan el pool y pgx.Tx (en ese caso Begin crea un savepoint).
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// copier lo implementa el pool: CopyInsert carga con COPY en lugar de un INSERT por fila.
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// snapshotBeginner lo implementa el pool: permite elegir el aislamiento de la transacción.
+type snapshotBeginner interface {
+	BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error)
+}
+
+// RepositoryOption configura comportamiento opcional del repositorio.
+type RepositoryOption func(*Repository)
+
+// WithQueryBudget coordina el contexto de cada query con el deadline del request.
+// Sin esta opción las queries usan el contexto tal como llega.
+func WithQueryBudget(budget db.QueryBudget) RepositoryOption {
+	return func(repository *Repository) {
+		repository.budget = budget
+	}
 }
 
 // NewRepository crea un repositorio de items.
-func NewRepository(database dbQuerier) *Repository {
-	return &Repository{database: database}
+func NewRepository(database dbQuerier, options ...RepositoryOption) *Repository {
+	repository := &Repository{database: database}
+	for _, option := range options {
+		option(repository)
+	}
+	return repository
+}
+
+// queryContext deriva el contexto de una query según el presupuesto configurado.
+// Si no queda tiempo devuelve ErrorTimeout y la query no debe ejecutarse.
+func (repository *Repository) queryContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	queryCtx, cancel, err := repository.budget.Context(ctx)
+	if errors.Is(err, db.ErrorNoBudget) {
+		return queryCtx, cancel, ErrorTimeout
+	}
+	return queryCtx, cancel, err
+}
+
+// itemColumns son las columnas de Item en el orden en que las escanea itemDestinations.
+// Todas las queries que devuelven items las usan para que agregar una columna sea un solo cambio.
+// available no es una columna: es stock menos las reservas vigentes, así una reserva vencida
+// deja de contar aunque el job de limpieza todavía no la haya borrado.
+// category tampoco: es la categoría del item armada como objeto JSON, o NULL si no tiene.
+const itemColumns = `id, name, slug, sku, description, price::text, stock, created_at, updated_at, version, deleted_at, ` +
+	availableColumn + `, allow_backorder, stock < 0 AS backordered, barcode, ` + categoryColumn + `, ` + brandColumn + `, status, currency, attributes, ` +
+	`weight_grams, width_mm, height_mm, depth_mm, sale_price::text, ` + effectivePriceColumn + `::text AS effective_price, tax_rate_bps, min_order_qty, expires_at::text, ` +
+	nextPriceChangeColumn + `, state, ` + refsColumn + `, reorder_point`
+
+// effectivePriceColumn es el precio que se cobra: el de oferta si hay, si no el de lista. Va calificado
+// por lo mismo que sortColumns.
+const effectivePriceColumn = `coalesce(items.sale_price, items.price)`
+
+// categoryColumn calcula Item.Category. Es una subquery y no un JOIN para que itemColumns
+// siga sirviendo en los RETURNING de INSERT y UPDATE.
+const categoryColumn = `(SELECT json_build_object('id', categories.id, 'name', categories.name) ` +
+	`FROM categories WHERE categories.id = items.category_id) AS category`
+
+// brandColumn calcula Item.Brand con el mismo criterio que categoryColumn.
+const brandColumn = `(SELECT json_build_object('id', brands.id, 'name', brands.name) ` +
+	`FROM brands WHERE brands.id = items.brand_id) AS brand`
+
+// nextPriceChangeColumn calcula Item.NextPriceChange: el cambio de precio pendiente más próximo,
+// o NULL si no hay. ux_price_schedules_item_effective_at_pending resuelve la búsqueda.
+const nextPriceChangeColumn = `(SELECT json_build_object('price', pending.new_price::text, 'effective_at', pending.effective_at) ` +
+	`FROM price_schedules pending WHERE pending.item_id = items.id AND pending.applied_at IS NULL AND pending.failed_at IS NULL ` +
+	`ORDER BY pending.effective_at LIMIT 1) AS next_price_change`
+
+// refsColumn calcula Item.Refs: las refs externas del item como array JSON, o NULL si no tiene.
+// ix_item_external_refs_item_id resuelve la búsqueda.
+const refsColumn = `(SELECT json_agg(json_build_object('system', refs.system, 'external_id', refs.external_id, 'created_at', refs.created_at) ` +
+	`ORDER BY refs.system, refs.external_id) FROM item_external_refs refs WHERE refs.item_id = items.id) AS refs`
+
+// availableColumn calcula Item.Available; ix_item_reservations_item_id_expires_at resuelve la suma.
+const availableColumn = `(stock - coalesce((SELECT sum(quantity) FROM item_reservations ` +
+	`WHERE item_reservations.item_id = items.id AND item_reservations.expires_at > now()), 0))::integer AS available`
+
+// notDeleted es el predicado que deja afuera los items borrados lógicamente (deleted_at no nulo).
+// Todas las lecturas y escrituras sobre items vivos lo incluyen; TakenSlugs y CollectionVersion
+// lo omiten a propósito, y el listado lo elige según ListFilter.Scope.
+const notDeleted = "deleted_at IS NULL"
+
+// notExpired deja afuera los items vencidos: un item vence al terminar el día de expires_at.
+const notExpired = "(expires_at IS NULL OR expires_at >= current_date)"
+
+// scopePredicate devuelve la condición de borrado lógico para el scope, o "" si no filtra (ScopeAll).
+func scopePredicate(scope DeletionScope) string {
+	switch scope {
+	case ScopeDeleted:
+		return "deleted_at IS NOT NULL"
+	case ScopeAll:
+		return ""
+	default:
+		return notDeleted
+	}
+}
+
+// itemDestinations devuelve los destinos de Scan para las columnas de itemColumns.
+func itemDestinations(item *Item) []any {
+	return []any{&item.ID, &item.Name, &item.Slug, &item.SKU, &item.Description, &item.Price, &item.Stock, &item.CreatedAt, &item.UpdatedAt, &item.Version, &item.DeletedAt, &item.Available,
+		&item.AllowBackorder, &item.Backordered, &item.Barcode, &item.Category, &item.Brand, &item.Status, &item.Currency, &item.Attributes,
+		&item.WeightGrams, &item.WidthMM, &item.HeightMM, &item.DepthMM, &item.SalePrice, &item.EffectivePrice,
+		taxRateDestination{item}, &item.MinOrderQty, &item.ExpiresAt, &item.NextPriceChange, &item.State, &item.Refs, &item.ReorderPoint}
 }
 
 // Insert crea un item y devuelve el registro persistido.
 // Usamos RETURNING para obtener id y timestamps generados por DB.
 func (repository *Repository) Insert(ctx context.Context, input CreateItemInput) (Item, error) {
 	const query = `
-		INSERT INTO items (name, description, price, stock)
-		VALUES ($1, $2, $3::numeric, $4)
-		RETURNING id, name, description, price::text, stock, created_at, updated_at;
+		INSERT INTO items (name, slug, sku, description, price, stock, allow_backorder, barcode, category_id, brand_id, currency, attributes,
+			weight_grams, width_mm, height_mm, depth_mm, sale_price, tax_rate_bps, min_order_qty, expires_at, state, reorder_point)
+		VALUES ($1, $2, $3, $4, $5::numeric, $6, $7, $8, $9, $10, $11, $12::jsonb, $13, $14, $15, $16, $17::numeric, $18, $19, $20::date, $21, $22)
+		RETURNING ` + itemColumns + `;
 	`
 
-	var item Item
-	err := repository.database.QueryRow(ctx, query, input.Name, input.Description, input.Price, input.Stock).
-		Scan(&item.ID, &item.Name, &item.Description, &item.Price, &item.Stock, &item.CreatedAt, &item.UpdatedAt)
+	ctx, cancel, err := repository.queryContext(ctx)
 	if err != nil {
-		// Detectar conflicto por índice unique (ux_items_name).
-		// Postgres: unique_violation = 23505
-		var postgressError *pgconn.PgError
-		if errors.As(err, &postgressError) && postgressError.Code == "23505" {
-			return Item{}, ErrorDuplicateName
-		}
 		return Item{}, err
 	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(ctx, query, input.Name, input.Slug, input.SKU, input.Description, input.Price, input.Stock, input.AllowBackorder, input.Barcode, input.CategoryID, input.BrandID, input.Currency, attributesArg(input.Attributes),
+		input.WeightGrams, input.WidthMM, input.HeightMM, input.DepthMM, input.SalePrice, input.TaxRateBPS, input.MinOrderQty, input.ExpiresAt, stateArg(input.State), input.ReorderPoint).
+		Scan(itemDestinations(&item)...)
+	if err != nil {
+		return Item{}, constraintViolation(err)
+	}
 
 	return item, nil
 }
 
-// List devuelve items paginados. Si nameQuery no está vacío, filtra por name usando ILIKE.
-// Nota: ILIKE con %...% puede no usar el índice btree. Para portfolio está perfecto;
-// si luego querés optimizar, se puede migrar a trigram (pg_trgm) o búsqueda full-text.
-func (repository *Repository) List(context context.Context, nameQuery string, limit, offset int) ([]Item, error) {
-	const base = `
-		SELECT id, name, description, price::text, stock, created_at, updated_at
+// UpsertBySKU inserta input o, si ya hay un item con su SKU, le actualiza el precio, el stock y la
+// descripción (si input trae una) con un INSERT ... ON CONFLICT (sku): el resto de input solo se usa
+// si el item se crea.
+// Devuelve true si lo creó. ux_items_sku abarca los items borrados; si el SKU es de uno borrado no se
+// toca y devuelve ErrorDuplicateSKU, igual que Insert.
+func (repository *Repository) UpsertBySKU(ctx context.Context, input CreateItemInput) (Item, bool, error) {
+	const query = `
+		INSERT INTO items (name, slug, sku, description, price, stock, allow_backorder, barcode, category_id, brand_id, currency, attributes,
+			weight_grams, width_mm, height_mm, depth_mm, sale_price, tax_rate_bps, min_order_qty, expires_at, state, reorder_point)
+		VALUES ($1, $2, $3, $4, $5::numeric, $6, $7, $8, $9, $10, $11, $12::jsonb, $13, $14, $15, $16, $17::numeric, $18, $19, $20::date, $21, $22)
+		ON CONFLICT (sku) DO UPDATE
+		SET price = EXCLUDED.price, stock = EXCLUDED.stock, description = coalesce(EXCLUDED.description, items.description),
+			updated_at = now(), version = items.version + 1
+		WHERE items.deleted_at IS NULL
+		RETURNING ` + itemColumns + `, xmax = 0;
+	`
+
+	ctx, cancel, err := repository.queryContext(ctx)
+	if err != nil {
+		return Item{}, false, err
+	}
+	defer cancel()
+
+	var item Item
+	var created bool
+	err = repository.database.QueryRow(ctx, query, input.Name, input.Slug, input.SKU, input.Description, input.Price, input.Stock, input.AllowBackorder, input.Barcode, input.CategoryID, input.BrandID, input.Currency, attributesArg(input.Attributes),
+		input.WeightGrams, input.WidthMM, input.HeightMM, input.DepthMM, input.SalePrice, input.TaxRateBPS, input.MinOrderQty, input.ExpiresAt, stateArg(input.State), input.ReorderPoint).
+		Scan(append(itemDestinations(&item), &created)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Item{}, false, ErrorDuplicateSKU
+	}
+	if err != nil {
+		return Item{}, false, constraintViolation(err)
+	}
+	return item, created, nil
+}
+
+// copyColumns son las columnas que carga CopyInsert; las demás quedan con el default de la tabla.
+var copyColumns = []string{"name", "slug", "sku", "description", "price", "stock", "allow_backorder", "currency", "tax_rate_bps", "min_order_qty", "state"}
+
+// CopyInsert carga inputs con un solo COPY, todos o ninguno. Los constraints de la tabla valen
+// igual que en Insert; un duplicado se informa con los mismos errores.
+func (repository *Repository) CopyInsert(ctx context.Context, inputs []CreateItemInput) (int64, error) {
+	database, ok := repository.database.(copier)
+	if !ok {
+		return 0, errors.New("items: database does not support COPY")
+	}
+	copied, err := database.CopyFrom(ctx, pgx.Identifier{"items"}, copyColumns, pgx.CopyFromSlice(len(inputs), func(index int) ([]any, error) {
+		input := inputs[index]
+		// COPY manda los valores en binario: el precio tiene que ir como numeric, no como texto.
+		var price pgtype.Numeric
+		if err := price.Scan(input.Price); err != nil {
+			return nil, fmt.Errorf("item %d: price: %w", index, err)
+		}
+		return []any{input.Name, input.Slug, input.SKU, input.Description, price, input.Stock, input.AllowBackorder, input.Currency, input.TaxRateBPS, input.MinOrderQty, stateArg(input.State)}, nil
+	}))
+	if err != nil {
+		return 0, constraintViolation(err)
+	}
+	return copied, nil
+}
+
+// Truncate borra todos los items y, en cascada, todo lo que cuelga de ellos (historial, reservas,
+// refs, variantes). No pasa por la papelera: solo lo usa cmd/seed para arrancar de un catálogo vacío.
+func (repository *Repository) Truncate(ctx context.Context) error {
+	rows, err := repository.database.Query(ctx, `TRUNCATE items CASCADE;`)
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	return rows.Err()
+}
+
+// stateArg es el estado de un alta: vacío es published, el mismo default que la columna.
+func stateArg(state ItemState) string {
+	if state == "" {
+		return string(StatePublished)
+	}
+	return string(state)
+}
+
+// List devuelve items paginados según el filtro.
+// limit y offset son siempre $1 y $2; los parámetros del filtro van a continuación.
+// Con filter.Fuzzy cada item trae su score de similitud y el orden principal es ese score.
+func (repository *Repository) List(context context.Context, filter ListFilter, limit, offset int) ([]Item, error) {
+	items, _, err := repository.list(context, filter, limit, offset, false)
+	return items, err
+}
+
+// ListWithTotal es List más el total de items que cumplen el filtro, en un solo round trip:
+// el total sale de COUNT(*) OVER () sobre el mismo WHERE, así nunca se desalinea con la página.
+// Una página vacía no trae filas (ni total); si no es la primera, puede haber items antes
+// y el total se completa con Count.
+func (repository *Repository) ListWithTotal(context context.Context, filter ListFilter, limit, offset int) ([]Item, int, error) {
+	items, total, err := repository.list(context, filter, limit, offset, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(items) == 0 && offset > 0 {
+		if total, err = repository.Count(context, filter); err != nil {
+			return nil, 0, err
+		}
+	}
+	return items, total, nil
+}
+
+// list arma y ejecuta la query de List. Con withTotal agrega COUNT(*) OVER () como última columna.
+func (repository *Repository) list(context context.Context, filter ListFilter, limit, offset int, withTotal bool) ([]Item, int, error) {
+	columns := `
+		SELECT ` + itemColumns
+	const from = `
 		FROM items
 	`
-	const orderLimit = `
-		ORDER BY created_at DESC
+	const limitOffset = `
 		LIMIT $1 OFFSET $2;
 	`
 
-	var rowsQuery string
-	var args []any
+	where, filterArgs := buildListWhere(filter, 3)
+	args := append([]any{limit, offset}, filterArgs...)
+
+	// buildListWhere siempre usa el primer placeholder libre ($3) para Query.
+	orderBy := listOrderBy(filter, "$3")
+	if filter.Fuzzy {
+		columns += ", similarity(name, $3)"
+	}
+	if withTotal {
+		columns += ", COUNT(*) OVER () AS total"
+	}
+	rowsQuery := columns + from + where + orderBy + limitOffset
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, rowsQuery, args...)
+	if err != nil {
+		return nil, 0, listError(filter, err)
+	}
 
-	if nameQuery == "" {
-		rowsQuery = base + orderLimit
-		args = []any{limit, offset}
+	var total int
+	totalDestination := &total
+	if !withTotal {
+		totalDestination = nil
+	}
+	items, err := scanList(rows, filter, limit, totalDestination)
+	return items, total, err
+}
+
+// ListAfter devuelve hasta limit items posteriores a after en el orden por defecto
+// (created_at DESC, id DESC), con los mismos filtros que List.
+// Es paginación por keyset: en lugar de OFFSET compara contra el último par visto,
+// así la base no recorre las filas de las páginas anteriores y las altas o bajas
+// entre requests no generan duplicados ni huecos. No soporta Sort ni Fuzzy. Si la búsqueda adelanta
+// las coincidencias exactas de sku o barcode, como List, el orden es (coincidencia, created_at, id)
+// y el keyset compara también after.Ranked.
+// El costo no depende de la profundidad de la página: recorre ix_items_created_at_id (migración 0004)
+// desde el cursor y lee solo limit filas.
+func (repository *Repository) ListAfter(context context.Context, filter ListFilter, after CreatedAtID, limit int) ([]Item, error) {
+	where, filterArgs := buildListWhere(filter, 4)
+	args := append([]any{after.CreatedAt, after.ID, limit}, filterArgs...)
+
+	// buildListWhere siempre usa el primer placeholder libre ($4) para Query.
+	keyset := "(created_at, id) < ($1, $2)"
+	if match := identifierMatch(filter, "$4"); match != "" {
+		args = append(args, after.Ranked)
+		keyset = fmt.Sprintf("(%s, created_at, id) < ($%d, $1, $2)", match, len(args))
+	}
+	if where == "" {
+		where = " WHERE " + keyset
 	} else {
-		rowsQuery = base + `
-			WHERE name ILIKE '%' || $3 || '%'
-		` + orderLimit
-		args = []any{limit, offset, nameQuery}
+		where += " AND " + keyset
+	}
+
+	query := `
+		SELECT ` + itemColumns + `
+		FROM items` + where + listOrderBy(filter, "$4") + `
+		LIMIT $3;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
-	rows, err := repository.database.Query(context, rowsQuery, args...)
+	rows, err := repository.database.Query(queryContext, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	return scanList(rows, filter, limit, nil)
+}
+
+// scanList lee las filas de List y ListAfter. Con filter.Fuzzy espera el score después de las columnas
+// del item; si total no es nil, la última columna es el COUNT(*) OVER () y se guarda ahí.
+func scanList(rows pgx.Rows, filter ListFilter, limit int, total *int) ([]Item, error) {
 	defer rows.Close()
 
 	out := make([]Item, 0, limit)
 	for rows.Next() {
 		var it Item
-		if err := rows.Scan(&it.ID, &it.Name, &it.Description, &it.Price, &it.Stock, &it.CreatedAt, &it.UpdatedAt); err != nil {
+		destinations := itemDestinations(&it)
+		if filter.Fuzzy {
+			it.Similarity = new(float64)
+			destinations = append(destinations, it.Similarity)
+		}
+		if total != nil {
+			destinations = append(destinations, total)
+		}
+		if err := rows.Scan(destinations...); err != nil {
 			return nil, err
 		}
 		out = append(out, it)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, listError(filter, err)
 	}
 
 	return out, nil
 }
 
-// Count devuelve la cantidad total de items según el filtro nameQuery.
-// Se usa para calcular paginación (total pages, etc.).
-func (repository *Repository) Count(context context.Context, nameQuery string) (int, error) {
-	const base = `SELECT COUNT(*) FROM items`
-	var query string
-	var args []any
-
-	if nameQuery == "" {
-		query = base
-		args = nil
-	} else {
-		query = base + ` WHERE name ILIKE '%' || $1 || '%'`
-		args = []any{nameQuery}
+// listError traduce el error de una búsqueda fuzzy sin pg_trgm instalado
+// (undefined_function, 42883) a ErrorFuzzyUnavailable para que el cliente reciba un mensaje claro.
+func listError(filter ListFilter, err error) error {
+	if filter.Fuzzy {
+		return trigramError(err)
 	}
+	return err
+}
 
-	var total int
-	if err := repository.database.QueryRow(context, query, args...).Scan(&total); err != nil {
-		return 0, err
+// trigramError traduce undefined_function (42883) en una query que usa similarity() a ErrorFuzzyUnavailable.
+func trigramError(err error) error {
+	var postgresError *pgconn.PgError
+	if errors.As(err, &postgresError) && postgresError.Code == "42883" {
+		return ErrorFuzzyUnavailable
 	}
-	return total, nil
+	return err
 }
 
-// GetByID busca un item por su ID (UUID).
-// Devuelve (Item, nil) si existe.
-// Devuelve (Item{}, pgx.ErrNoRows) si no existe.
-func (repository *Repository) GetByID(context context.Context, id string) (Item, error) {
+// maxRelatedLimit es el máximo de items que devuelve Related, pida lo que pida el caller.
+const maxRelatedLimit = 20
+
+// Related devuelve hasta limit items con nombre parecido al de item, sin incluirlo: los que tienen
+// similitud de trigramas de al menos threshold o comparten la primera palabra del nombre
+// (así "Mouse" encuentra "Mouse inalámbrico" aunque el score sea bajo). Ordena por similitud,
+// que viene en Similarity. Un limit mayor a maxRelatedLimit se recorta.
+func (repository *Repository) Related(context context.Context, item Item, threshold float64, limit int) ([]Item, error) {
 	const query = `
-		SELECT id, name, description, price::text, stock, created_at, updated_at
+		SELECT ` + itemColumns + `, similarity(name, $1)
 		FROM items
-		WHERE id = $1;
+		WHERE id <> $2 AND deleted_at IS NULL
+		  AND (similarity(name, $1) >= $3 OR lower(split_part(name, ' ', 1)) = lower(split_part($1, ' ', 1)))
+		ORDER BY similarity(name, $1) DESC, created_at DESC, id DESC
+		LIMIT $4;
 	`
+	limit = min(limit, maxRelatedLimit)
 
-	var item Item
-	err := repository.database.QueryRow(context, query, id).
-		Scan(&item.ID, &item.Name, &item.Description, &item.Price, &item.Stock, &item.CreatedAt, &item.UpdatedAt)
+	queryContext, cancel, err := repository.queryContext(context)
 	if err != nil {
-		return Item{}, err
+		return nil, err
 	}
+	defer cancel()
 
-	return item, nil
+	rows, err := repository.database.Query(queryContext, query, item.Name, item.ID, threshold, limit)
+	if err != nil {
+		return nil, trigramError(err)
+	}
+	// Las filas tienen la forma del listado fuzzy: columnas del item y el score.
+	return scanList(rows, ListFilter{Fuzzy: true}, limit, nil)
 }
 
-// Update aplica un PATCH parcial.
-// Genera SQL dinámico con parámetros (evita SQL injection).
-func (repository *Repository) Update(context context.Context, id string, itemInputUpdated UpdateItemInput) (Item, error) {
-	setParts := make([]string, 0, 4)
-	args := make([]any, 0, 6)
-	argPos := 1
+// maxSuggestLimit es el máximo de sugerencias que devuelve Suggest, pida lo que pida el caller.
+const maxSuggestLimit = 10
 
-	// Helper para agregar "campo = $n"
-	addSet := func(expr string, val any) {
-		setParts = append(setParts, fmt.Sprintf(expr, argPos))
-		args = append(args, val)
-		argPos++
+// Suggest devuelve hasta limit sugerencias para el autocompletado: items a la venta cuyo nombre
+// empieza con prefix o se le parece por trigramas (score de al menos threshold). Primero los que
+// empiezan con prefix, después por similitud y por nombre. Se llama en cada tecla, así que solo
+// trae id y name, no cuenta el total y recorta limit a maxSuggestLimit.
+func (repository *Repository) Suggest(context context.Context, prefix string, threshold float64, limit int) ([]Suggestion, error) {
+	const query = `
+		SELECT id, name
+		FROM items
+		WHERE deleted_at IS NULL AND status = 'active' AND state = 'published' AND ` + notExpired + `
+		  AND (lower(name) LIKE lower($1) || '%' OR similarity(name, $1) >= $2)
+		ORDER BY (lower(name) LIKE lower($1) || '%') DESC, similarity(name, $1) DESC, name, id
+		LIMIT $3;
+	`
+	limit = min(limit, maxSuggestLimit)
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
 
-	if itemInputUpdated.Name != nil {
-		addSet("name = $%d", *itemInputUpdated.Name)
+	rows, err := repository.database.Query(queryContext, query, prefix, threshold, limit)
+	if err != nil {
+		return nil, trigramError(err)
 	}
+	defer rows.Close()
 
-	// description:
-	// - si no vino, no tocar
-	// - si vino null, setear NULL
-	// - si vino con string, setear string
-	if itemInputUpdated.DescriptionPresent {
-		if itemInputUpdated.Description != nil {
-			addSet("description = $%d", *itemInputUpdated.Description)
-		} else {
-			setParts = append(setParts, "description = NULL")
+	out := make([]Suggestion, 0, limit)
+	for rows.Next() {
+		var suggestion Suggestion
+		if err := rows.Scan(&suggestion.ID, &suggestion.Name); err != nil {
+			return nil, err
 		}
+		out = append(out, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, trigramError(err)
 	}
+	return out, nil
+}
 
-	if itemInputUpdated.Price != nil {
-		// casteo explícito a numeric
-		addSet("price = $%d::numeric", *itemInputUpdated.Price)
+// ClosestName devuelve el nombre del item a la venta más parecido a query por trigramas, si
+// tiene similitud de al menos threshold; si no hay ninguno devuelve "". Es el "quizás quisiste
+// decir" de las búsquedas sin resultados, así que solo se llama en ese caso.
+func (repository *Repository) ClosestName(context context.Context, query string, threshold float64) (string, error) {
+	const statement = `
+		SELECT name
+		FROM items
+		WHERE deleted_at IS NULL AND status = 'active' AND state = 'published' AND ` + notExpired + `
+		  AND similarity(name, $1) >= $2
+		ORDER BY similarity(name, $1) DESC, name
+		LIMIT 1;
+	`
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return "", err
 	}
+	defer cancel()
 
-	if itemInputUpdated.Stock != nil {
-		addSet("stock = $%d", *itemInputUpdated.Stock)
+	var name string
+	err = repository.database.QueryRow(queryContext, statement, query, threshold).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", trigramError(err)
 	}
+	return name, nil
+}
 
-	if len(setParts) == 0 {
-		return Item{}, ErrorInvalidInput
+// RestockNeeded devuelve una página de los items vivos que tienen punto de reposición y stock en o
+// debajo de él, del mayor faltante (reorder_point - stock) al menor, y el total (COUNT(*) OVER (), como
+// ListStockMovements). Los items sin reorder_point no entran; ix_items_reorder_point (migración 0031)
+// deja afuera a esos sin leerlos.
+func (repository *Repository) RestockNeeded(context context.Context, limit, offset int) ([]Item, int, error) {
+	const query = `
+		SELECT ` + itemColumns + `, COUNT(*) OVER () AS total
+		FROM items
+		WHERE ` + notDeleted + ` AND reorder_point IS NOT NULL AND stock <= reorder_point
+		ORDER BY reorder_point - stock DESC, id
+		LIMIT $1 OFFSET $2;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, 0, err
 	}
+	defer cancel()
 
-	// updated_at siempre se actualiza.
-	setParts = append(setParts, "updated_at = now()")
+	rows, err := repository.database.Query(queryContext, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := 0
+	items, err := scanList(rows, ListFilter{}, limit, &total)
+	return items, total, err
+}
 
-	// id va al final
-	args = append(args, id)
+// Changes devuelve hasta limit items, borrados lógicamente incluidos, con (updated_at, id) posterior a
+// after y updated_at anterior a until, en orden ascendente. Es keyset sobre ix_items_updated_at_id
+// (migración 0032): el id desempata los items con el mismo updated_at, así ninguno queda afuera
+// entre una llamada y la siguiente.
+func (repository *Repository) Changes(context context.Context, after UpdatedAtID, until time.Time, limit int) ([]Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE (updated_at, id) > ($1, $2) AND updated_at < $3
+		ORDER BY updated_at, id
+		LIMIT $4;
+	`
 
-	query := fmt.Sprintf(`
-		UPDATE items
-		SET %s
-		WHERE id = $%d
-		RETURNING id, name, description, price::text, stock, created_at, updated_at;
-	`, strings.Join(setParts, ", "), argPos)
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
 
-	var item Item
-	err := repository.database.QueryRow(context, query, args...).
-		Scan(&item.ID, &item.Name, &item.Description, &item.Price, &item.Stock, &item.CreatedAt, &item.UpdatedAt)
+	rows, err := repository.database.Query(queryContext, query, after.UpdatedAt, after.ID, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanList(rows, ListFilter{}, limit, nil)
+}
+
+// Count devuelve la cantidad total de items según el filtro.
+// Se usa para calcular paginación (total pages, etc.).
+func (repository *Repository) Count(context context.Context, filter ListFilter) (int, error) {
+	where, args := buildListWhere(filter, 1)
+	query := `SELECT COUNT(*) FROM items` + where
 
+	queryContext, cancel, err := repository.queryContext(context)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return Item{}, ErrorNotFound
+		return 0, err
+	}
+	defer cancel()
+
+	var total int
+	if err := repository.database.QueryRow(queryContext, query, args...).Scan(&total); err != nil {
+		return 0, listError(filter, err)
+	}
+	return total, nil
+}
+
+// EstimateCount devuelve la cantidad aproximada de items que cumplen filter según el planner: las
+// filas que EXPLAIN calcula con las estadísticas que mantienen ANALYZE y autovacuum, sin recorrer la
+// tabla. ok es false si la tabla nunca se analizó (pg_class.reltuples = -1): sin estadísticas el
+// planner adivina.
+func (repository *Repository) EstimateCount(context context.Context, filter ListFilter) (int, bool, error) {
+	const analyzedQuery = `SELECT reltuples::bigint FROM pg_class WHERE oid = 'items'::regclass`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return 0, false, err
+	}
+	defer cancel()
+
+	var reltuples int64
+	if err := repository.database.QueryRow(queryContext, analyzedQuery).Scan(&reltuples); err != nil {
+		return 0, false, err
+	}
+	if reltuples < 0 {
+		return 0, false, nil
+	}
+
+	where, args := buildListWhere(filter, 1)
+	var plan string
+	if err := repository.database.QueryRow(queryContext, "EXPLAIN (FORMAT JSON) SELECT 1 FROM items"+where, args...).Scan(&plan); err != nil {
+		return 0, false, err
+	}
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil {
+		return 0, false, fmt.Errorf("decode query plan: %w", err)
+	}
+	if len(explained) == 0 {
+		return 0, false, nil
+	}
+	return int(explained[0].Plan.Rows), true, nil
+}
+
+// CollectionVersion lee el updated_at más reciente y la cantidad de filas de toda la tabla, incluida
+// la papelera, así la misma versión sirve para el listado y para la papelera. max(updated_at) sale
+// de la punta de ix_items_updated_at_id y también cambia con un borrado lógico, que actualiza updated_at;
+// count(*) detecta las filas que desaparecen del todo. current_date es la misma fecha contra la que
+// compara notExpired.
+func (repository *Repository) CollectionVersion(context context.Context) (CollectionVersion, error) {
+	const query = `SELECT max(updated_at), count(*), current_date::text FROM items`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return CollectionVersion{}, err
+	}
+	defer cancel()
+
+	var (
+		lastUpdatedAt *time.Time
+		version       CollectionVersion
+	)
+	if err := repository.database.QueryRow(queryContext, query).Scan(&lastUpdatedAt, &version.Count, &version.Today); err != nil {
+		return CollectionVersion{}, err
+	}
+	if lastUpdatedAt != nil {
+		version.LastUpdatedAt = *lastUpdatedAt
+	}
+	return version, nil
+}
+
+// Each recorre todo el catálogo en orden estable (created_at, id) y llama a fn por cada item,
+// sin cargarlo entero en memoria. Pensado para exports: no aplica el presupuesto por query
+// porque puede durar bastante más que un request; el límite lo pone ctx.
+func (repository *Repository) Each(ctx context.Context, fn func(Item) error) error {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, id;
+	`
+
+	rows, err := repository.database.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(itemDestinations(&item)...); err != nil {
+			return err
 		}
-		var paginationError *pgconn.PgError
-		if errors.As(err, &paginationError) && paginationError.Code == "23505" {
-			return Item{}, ErrorDuplicateName
+		if err := fn(item); err != nil {
+			return err
 		}
-		return Item{}, err
 	}
-
-	return item, nil
+	return rows.Err()
 }
 
-// Delete elimina un item por ID.
-// Devuelve ErrNotFound si no existe.
-func (repository *Repository) Delete(context context.Context, id string) error {
-	const query = `DELETE FROM items WHERE id = $1 RETURNING id;`
+// Export recorre los items que cumplen filter, en el mismo orden que List, y llama a fn por cada
+// uno a medida que llegan del cursor, sin acumularlos. Como Each, no aplica el presupuesto por
+// query: dura lo que dura la descarga y el límite lo pone ctx.
+func (repository *Repository) Export(ctx context.Context, filter ListFilter, fn func(Item) error) error {
+	where, args := buildListWhere(filter, 1)
+	// buildListWhere usa el primer placeholder ($1) para Query.
+	query := `SELECT ` + itemColumns + ` FROM items` + where + listOrderBy(filter, "$1") + `;`
 
-	var deletedID string
-	err := repository.database.QueryRow(context, query, id).Scan(&deletedID)
+	rows, err := repository.database.Query(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrorNotFound
+		return listError(filter, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(itemDestinations(&item)...); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
 		}
-		return err
 	}
+	return listError(filter, rows.Err())
+}
 
-	return nil
+// Stats cuenta items totales y sin stock en una sola pasada.
+// No forma parte de RepositoryAPI: lo usa el job que refresca las métricas del catálogo.
+func (repository *Repository) Stats(context context.Context) (CatalogStats, error) {
+	const query = `SELECT count(*), count(*) FILTER (WHERE stock = 0) FROM items WHERE deleted_at IS NULL`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return CatalogStats{}, err
+	}
+	defer cancel()
+
+	var stats CatalogStats
+	if err := repository.database.QueryRow(queryContext, query).Scan(&stats.Total, &stats.OutOfStock); err != nil {
+		return CatalogStats{}, err
+	}
+	return stats, nil
+}
+
+// sortColumns es la whitelist de claves de orden → columna SQL.
+// Las columnas van calificadas con la tabla a propósito: el SELECT devuelve price::text con nombre
+// de salida "price", y un ORDER BY price sin calificar ordenaría por ese texto ("9.50" > "100.00").
+// items.price referencia la columna numeric original. En expires_at los items sin vencimiento
+// quedan al final en orden ascendente (NULLS LAST es el default de Postgres).
+var sortColumns = map[string]string{
+	"created_at": "items.created_at",
+	"name":       "items.name",
+	"price":      "items.price",
+	"stock":      "items.stock",
+	"expires_at": "items.expires_at",
+}
+
+// defaultSort es el orden del listado cuando no se pide ninguno: más nuevos primero.
+var defaultSort = []SortKey{"-created_at"}
+
+// isSortable indica si la clave (con o sin "-") está en la whitelist.
+func isSortable(key SortKey) bool {
+	_, ok := sortColumns[key.Field()]
+	return ok
+}
+
+// listOrderBy arma el ORDER BY de List y Export; query es el placeholder de Query. Primero van los
+// items cuyo SKU o barcode coincide exacto con Query, después (con fuzzy) los más parecidos y al
+// final el orden pedido.
+func listOrderBy(filter ListFilter, query string) string {
+	terms := orderByTerms(filter.Sort, filter.UseEffectivePrice)
+	if filter.Fuzzy {
+		terms = append([]string{"similarity(name, " + query + ") DESC"}, terms...)
+	}
+	if match := identifierMatch(filter, query); match != "" {
+		terms = append([]string{match + " DESC"}, terms...)
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// orderByClause traduce las claves de orden a ORDER BY, en el orden recibido.
+// Con effectivePrice la clave price ordena por el precio efectivo.
+func orderByClause(keys []SortKey, effectivePrice bool) string {
+	return " ORDER BY " + strings.Join(orderByTerms(keys, effectivePrice), ", ")
+}
+
+// orderByTerms devuelve los términos del ORDER BY para las claves recibidas.
+// Siempre termina con items.id como desempate (en la dirección de la última clave) para que
+// filas con el mismo created_at o price no cambien de página entre requests.
+// Claves desconocidas se ignoran; el service ya las rechaza antes de llegar acá.
+func orderByTerms(keys []SortKey, effectivePrice bool) []string {
+	terms := make([]string, 0, len(keys)+1)
+	lastDirection := "DESC"
+	for _, key := range keys {
+		column, ok := sortColumns[key.Field()]
+		if !ok {
+			continue
+		}
+		if effectivePrice && key.Field() == "price" {
+			column = effectivePriceColumn
+		}
+		lastDirection = "ASC"
+		if key.Descending() {
+			lastDirection = "DESC"
+		}
+		terms = append(terms, column+" "+lastDirection)
+	}
+	if len(terms) == 0 {
+		return orderByTerms(defaultSort, false)
+	}
+	return append(terms, "items.id "+lastDirection)
+}
+
+// searchColumns es la whitelist de campos de búsqueda → expresión SQL. %s es el prefijo de la
+// tabla: "" para items y "translations." para las traducciones.
+// description es nullable: coalesce evita que un NULL vuelva NULL todo el OR.
+var searchColumns = map[string]string{
+	"name":        "%sname",
+	"description": "coalesce(%sdescription, '')",
+}
+
+// identifierColumns son los campos de búsqueda que identifican un item: se comparan por igualdad
+// exacta con cualquier modo de match. Los SKUs se guardan en mayúsculas (ck_items_sku_format), así
+// que upper() alcanza para ignorar mayúsculas y la igualdad la resuelve ux_items_sku; el barcode son
+// solo dígitos y lo resuelve ux_items_barcode. item_translations no tiene estas columnas.
+var identifierColumns = map[string]string{
+	"sku":     "sku = upper(%s)",
+	"barcode": "barcode = %s",
+}
+
+// defaultSearchFields son los campos donde se busca Query si el cliente no manda search_fields: el
+// nombre y los identificadores, así el mismo cuadro de búsqueda encuentra un SKU o un código escaneado.
+var defaultSearchFields = []string{"name", "sku", "barcode"}
+
+// isSearchable indica si el campo está en la whitelist de búsqueda.
+func isSearchable(field string) bool {
+	_, ok := searchColumns[field]
+	_, identifier := identifierColumns[field]
+	return ok || identifier
+}
+
+// searchFields devuelve los campos de búsqueda pedidos, o defaultSearchFields si no se pidió ninguno.
+func searchFields(filter ListFilter) []string {
+	if len(filter.SearchFields) == 0 {
+		return defaultSearchFields
+	}
+	return filter.SearchFields
+}
+
+// searchPredicate arma la condición de búsqueda sobre cada campo pedido, unidas con OR.
+// Todas comparten el mismo placeholder. Con un solo campo no agrega paréntesis.
+// Con SearchTranslations suma un EXISTS que aplica las mismas condiciones de texto a item_translations.
+func searchPredicate(filter ListFilter, placeholder string) string {
+	conditions := searchConditions(filter, placeholder, "")
+	conditions = append(conditions, identifierConditions(filter, placeholder)...)
+	if translated := searchConditions(filter, placeholder, "translations."); filter.SearchTranslations && len(translated) > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM item_translations translations WHERE translations.item_id = items.id AND "+
+			joinOr(translated)+")")
+	}
+	return joinOr(conditions)
+}
+
+// identifierConditions devuelve la igualdad exacta de cada identificador pedido (sku, barcode).
+func identifierConditions(filter ListFilter, placeholder string) []string {
+	var conditions []string
+	for _, field := range searchFields(filter) {
+		if expression, ok := identifierColumns[field]; ok {
+			conditions = append(conditions, fmt.Sprintf(expression, placeholder))
+		}
+	}
+	return conditions
+}
+
+// identifierMatch devuelve la expresión que vale true si Query coincide exacto con un identificador
+// pedido, para ordenar esos items antes que las coincidencias por nombre. Sin Query o sin
+// identificadores en la búsqueda devuelve "". Un SKU o barcode NULL cuenta como false.
+func identifierMatch(filter ListFilter, placeholder string) string {
+	if filter.Query == "" {
+		return ""
+	}
+	conditions := identifierConditions(filter, placeholder)
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "coalesce(" + strings.Join(conditions, " OR ") + ", false)"
+}
+
+// identifierHit evalúa identifierMatch sobre un item ya leído, con las mismas comparaciones que
+// identifierColumns. Lo usa el cursor, que tiene que saber de qué lado del ranking quedó el item.
+func identifierHit(filter ListFilter, item Item) bool {
+	if filter.Query == "" {
+		return false
+	}
+	for _, field := range searchFields(filter) {
+		switch field {
+		case "sku":
+			if item.SKU != nil && *item.SKU == strings.ToUpper(filter.Query) {
+				return true
+			}
+		case "barcode":
+			if item.Barcode != nil && *item.Barcode == filter.Query {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchConditions devuelve una condición por cada campo de texto pedido (name, description), sobre
+// las columnas de la tabla indicada por qualifier. Los identificadores los agrega identifierConditions.
+func searchConditions(filter ListFilter, placeholder, qualifier string) []string {
+	fields := searchFields(filter)
+	conditions := make([]string, 0, len(fields))
+	for _, field := range fields {
+		expression, ok := searchColumns[field]
+		if !ok {
+			continue
+		}
+		column := fmt.Sprintf(expression, qualifier)
+		switch filter.Match {
+		case MatchPrefix:
+			conditions = append(conditions, fmt.Sprintf("lower(%s) LIKE lower(%s) || '%%'", column, placeholder))
+		case MatchExact:
+			conditions = append(conditions, fmt.Sprintf("lower(%s) = lower(%s)", column, placeholder))
+		default:
+			conditions = append(conditions, fmt.Sprintf("%s ILIKE '%%' || %s || '%%'", column, placeholder))
+		}
+	}
+	return conditions
+}
+
+// joinOr une las condiciones con OR, entre paréntesis salvo que haya una sola.
+func joinOr(conditions []string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// buildListWhere arma el WHERE compartido por List y Count.
+// argStart es el primer placeholder libre ($n), así ambas queries usan exactamente el mismo predicado.
+// Primero va la condición de filter.Scope (por defecto, solo items vivos) y después los filtros, con AND.
+// Si no hay ninguna condición (ScopeAll sin filtros) devuelve "" y nil.
+func buildListWhere(filter ListFilter, argStart int) (string, []any) {
+	predicates, args := filterPredicates(filter, argStart)
+	if scope := scopePredicate(filter.Scope); scope != "" {
+		predicates = append([]string{scope}, predicates...)
+	}
+	if len(predicates) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(predicates, " AND "), args
+}
+
+// filterPredicates devuelve las condiciones de los filtros de ListFilter y sus parámetros.
+//
+// Modos de búsqueda (sobre name y, si se pide, description):
+//   - contains: ILIKE '%q%' (no usa índice btree).
+//   - prefix: lower(name) LIKE lower(q) || '%', usa ix_items_name_lower_pattern (text_pattern_ops).
+//   - exact: lower(name) = lower(q), también resuelto por el mismo índice.
+//
+// sku y barcode ignoran el modo: siempre se comparan por igualdad (sku en mayúsculas), con sus índices únicos.
+//
+// El rango de precio compara como numeric (price >= '9.50'::numeric), nunca como texto.
+func filterPredicates(filter ListFilter, argStart int) ([]string, []any) {
+	var predicates []string
+	var args []any
+	// placeholder agrega value a los args y devuelve su $n.
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", argStart+len(args)-1)
+	}
+
+	// Query siempre es el primer parámetro: List lo reutiliza para el score de similitud.
+	if filter.Query != "" && filter.Fuzzy {
+		query := placeholder(filter.Query)
+		conditions := []string{fmt.Sprintf("similarity(name, %s) >= %s", query, placeholder(filter.FuzzyThreshold))}
+		predicates = append(predicates, joinOr(append(conditions, identifierConditions(filter, query)...)))
+	} else if filter.Query != "" {
+		predicates = append(predicates, searchPredicate(filter, placeholder(filter.Query)))
+	}
+	if filter.NameEq != "" {
+		if filter.NameEqIgnoreCase {
+			predicates = append(predicates, "lower(name) = lower("+placeholder(filter.NameEq)+")")
+		} else {
+			predicates = append(predicates, "name = "+placeholder(filter.NameEq))
+		}
+	}
+	price := "price"
+	if filter.UseEffectivePrice {
+		price = effectivePriceColumn
+	}
+	if filter.MinPrice != "" {
+		predicates = append(predicates, price+" >= "+placeholder(filter.MinPrice)+"::numeric")
+	}
+	if filter.MaxPrice != "" {
+		predicates = append(predicates, price+" <= "+placeholder(filter.MaxPrice)+"::numeric")
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			predicates = append(predicates, "stock > 0")
+		} else {
+			predicates = append(predicates, "stock = 0")
+		}
+	}
+	if filter.StockGTE != nil {
+		predicates = append(predicates, "stock >= "+placeholder(*filter.StockGTE))
+	}
+	if filter.StockLTE != nil {
+		predicates = append(predicates, "stock <= "+placeholder(*filter.StockLTE))
+	}
+	if filter.SKU != "" {
+		// Igualdad exacta: la resuelve ux_items_sku.
+		predicates = append(predicates, "sku = "+placeholder(filter.SKU))
+	}
+	if filter.CategoryID != "" {
+		predicates = append(predicates, "category_id = "+placeholder(filter.CategoryID)+"::uuid")
+	}
+	if filter.BrandID != "" {
+		predicates = append(predicates, "brand_id = "+placeholder(filter.BrandID)+"::uuid")
+	}
+	if filter.Currency != "" {
+		predicates = append(predicates, "currency = "+placeholder(filter.Currency))
+	}
+	if filter.Status != "" && filter.Status != StatusAll {
+		predicates = append(predicates, "status = "+placeholder(string(filter.Status)))
+	}
+	if filter.State != "" && filter.State != StateAll {
+		predicates = append(predicates, "state = "+placeholder(string(filter.State)))
+	}
+	// Los items sin peso cargado no entran: no se sabe si caben.
+	if filter.MaxWeight != nil {
+		predicates = append(predicates, "weight_grams <= "+placeholder(*filter.MaxWeight))
+	}
+	if filter.MinOrderQtyLTE != nil {
+		predicates = append(predicates, "min_order_qty <= "+placeholder(*filter.MinOrderQtyLTE))
+	}
+	if filter.ExpiringBefore != "" {
+		predicates = append(predicates, "expires_at < "+placeholder(filter.ExpiringBefore)+"::date")
+	}
+	if filter.ExcludeExpired != nil && *filter.ExcludeExpired {
+		predicates = append(predicates, notExpired)
+	}
+	// Cada atributo es una contención (attributes @> '{"color":"red"}'), que resuelve ix_items_attributes.
+	for _, key := range sortedAttributeKeys(filter.Attributes) {
+		var alternatives []string
+		for _, document := range attributeFilterDocuments(key, filter.Attributes[key]) {
+			alternatives = append(alternatives, "attributes @> "+placeholder(document)+"::jsonb")
+		}
+		if len(alternatives) == 1 {
+			predicates = append(predicates, alternatives[0])
+		} else {
+			predicates = append(predicates, "("+strings.Join(alternatives, " OR ")+")")
+		}
+	}
+
+	return predicates, args
+}
+
+// isUnfiltered indica si el filtro no agrega condiciones al WHERE más allá de excluir los borrados
+// y de las del listado por defecto (withDefaultStatus: activos, publicados y sin vencer), o sea si
+// el total es el del catálogo vivo o el del catálogo a la venta. Esas condiciones son igualdades
+// sobre pocas columnas que el planner estima bien. Sort no cuenta: no cambia el total.
+func isUnfiltered(filter ListFilter) bool {
+	defaults := withDefaultStatus(ListFilter{})
+	if filter.Status == defaults.Status {
+		filter.Status = ""
+	}
+	if filter.State == defaults.State {
+		filter.State = ""
+	}
+	if filter.ExcludeExpired != nil && *filter.ExcludeExpired {
+		filter.ExcludeExpired = nil
+	}
+	predicates, _ := filterPredicates(filter, 1)
+	return len(predicates) == 0 && scopePredicate(filter.Scope) == notDeleted
+}
+
+// GetByID busca un item por su ID (UUID).
+// Devuelve (Item, nil) si existe.
+// Devuelve (Item{}, pgx.ErrNoRows) si no existe.
+func (repository *Repository) GetByID(context context.Context, id string) (Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(queryContext, query, id).
+		Scan(itemDestinations(&item)...)
+	if err != nil {
+		return Item{}, err
+	}
+
+	return item, nil
+}
+
+// GetBySKU busca un item por su SKU.
+// Igual que GetByID, devuelve pgx.ErrNoRows si no existe.
+func (repository *Repository) GetBySKU(context context.Context, sku string) (Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE sku = $1 AND deleted_at IS NULL;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	if err := repository.database.QueryRow(queryContext, query, sku).Scan(itemDestinations(&item)...); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// GetByBarcode busca un item por su código de barras.
+// Igual que GetByID, devuelve pgx.ErrNoRows si no existe.
+func (repository *Repository) GetByBarcode(context context.Context, barcode string) (Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE barcode = $1 AND deleted_at IS NULL;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	if err := repository.database.QueryRow(queryContext, query, barcode).Scan(itemDestinations(&item)...); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// GetBySlug busca un item publicado por su slug. Devuelve ErrorNotFound si no existe o es un borrador:
+// el slug es la URL pública del item y un borrador todavía no se muestra.
+func (repository *Repository) GetBySlug(context context.Context, slug string) (Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE slug = $1 AND deleted_at IS NULL AND state = 'published';
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(queryContext, query, slug).Scan(itemDestinations(&item)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, err
+	}
+
+	return item, nil
+}
+
+// TakenSlugs devuelve los slugs ya usados que colisionan con base: base mismo y base-N.
+// exceptID excluye al item que se está editando (vacío en un alta), así su slug actual no cuenta
+// como tomado. El slug solo tiene [a-z0-9-], así que no hace falta escapar el LIKE.
+// Incluye los items borrados: ux_items_slug abarca toda la tabla, así un item nuevo con el nombre
+// de uno borrado recibe otro slug y el borrado se puede restaurar sin conflicto.
+func (repository *Repository) TakenSlugs(context context.Context, base, exceptID string) ([]string, error) {
+	const query = `
+		SELECT slug
+		FROM items
+		WHERE (slug = $1 OR slug LIKE $2) AND id IS DISTINCT FROM $3;
+	`
+
+	var except any
+	if exceptID != "" {
+		except = exceptID
+	}
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, base, base+"-%", except)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taken []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		taken = append(taken, slug)
+	}
+	return taken, rows.Err()
+}
+
+// CheckUniqueKeys dice, sin escribir, si el alta de input chocaría con un índice único: devuelve el
+// mismo error que traduciría constraintViolation del INSERT (ErrorDuplicateName, ErrorDuplicateSlug,
+// ErrorDuplicateSKU o ErrorDuplicateBarcode) o nil. Cada clave es un EXISTS con el criterio de su
+// índice: el nombre entre los items no borrados; el slug, el SKU y el barcode entre todos. Un slug
+// vacío no se mira porque el alta elige uno libre.
+func (repository *Repository) CheckUniqueKeys(context context.Context, input CreateItemInput) error {
+	const query = `
+		SELECT
+			EXISTS (SELECT 1 FROM items WHERE name = $1 AND deleted_at IS NULL),
+			$2 <> '' AND EXISTS (SELECT 1 FROM items WHERE slug = $2),
+			EXISTS (SELECT 1 FROM items WHERE sku = $3),
+			EXISTS (SELECT 1 FROM items WHERE barcode = $4);
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var name, slug, sku, barcode bool
+	if err := repository.database.QueryRow(queryContext, query, input.Name, input.Slug, input.SKU, input.Barcode).Scan(&name, &slug, &sku, &barcode); err != nil {
+		return err
+	}
+	switch {
+	case name:
+		return ErrorDuplicateName
+	case slug:
+		return ErrorDuplicateSlug
+	case sku:
+		return ErrorDuplicateSKU
+	case barcode:
+		return ErrorDuplicateBarcode
+	}
+	return nil
+}
+
+// GetForUpdate busca un item por ID y bloquea la fila hasta que termine la transacción.
+// Solo tiene sentido dentro de InTx; fuera de una transacción el lock se libera al instante.
+func (repository *Repository) GetForUpdate(context context.Context, id string) (Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(queryContext, query, id).
+		Scan(itemDestinations(&item)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, err
+	}
+
+	return item, nil
+}
+
+// InTx ejecuta fn dentro de una transacción. fn recibe un repositorio atado a la transacción;
+// si devuelve error se hace rollback, si no, commit.
+// Si el repositorio ya está atado a una transacción, fn corre en esa misma: así un paso que necesita
+// transacción (por ejemplo, cambiar stock y registrar el movimiento) se puede componer dentro de otra.
+func (repository *Repository) InTx(ctx context.Context, fn func(tx RepositoryAPI) error) error {
+	if _, inTx := repository.database.(pgx.Tx); inTx {
+		return fn(repository)
+	}
+	beginner, ok := repository.database.(txBeginner)
+	if !ok {
+		return errors.New("items: database does not support transactions")
+	}
+
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	return repository.runInTx(ctx, tx, fn)
+}
+
+// InSnapshot ejecuta fn en una transacción de solo lectura REPEATABLE READ: todas las queries de fn
+// ven la misma foto de la base, aunque entre una y otra se creen o borren items.
+// Si el repositorio ya está atado a una transacción, fn corre en esa misma.
+func (repository *Repository) InSnapshot(ctx context.Context, fn func(tx RepositoryAPI) error) error {
+	if _, inTx := repository.database.(pgx.Tx); inTx {
+		return fn(repository)
+	}
+	beginner, ok := repository.database.(snapshotBeginner)
+	if !ok {
+		return errors.New("items: database does not support transactions")
+	}
+
+	tx, err := beginner.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	return repository.runInTx(ctx, tx, fn)
+}
+
+// runInTx corre fn con un repositorio atado a tx y hace commit, o rollback si fn falla.
+func (repository *Repository) runInTx(ctx context.Context, tx pgx.Tx, fn func(tx RepositoryAPI) error) error {
+	// Rollback después de Commit no hace nada; cubre los caminos de error y pánico.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(&Repository{database: tx, budget: repository.budget}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Replace reescribe todas las columnas editables del item (name, slug, description, price, stock)
+// y updated_at en una sola sentencia. Devuelve ErrorNotFound si el id no existe.
+func (repository *Repository) Replace(context context.Context, id string, in ReplaceItemInput) (Item, error) {
+	const query = `
+		UPDATE items
+		SET name = $2, slug = $3, description = $4, price = $5::numeric, stock = $6, allow_backorder = $7,
+			updated_at = now(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING ` + itemColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(queryContext, query, id, in.Name, in.Slug, in.Description, in.Price, in.Stock, in.AllowBackorder).
+		Scan(itemDestinations(&item)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, constraintViolation(err)
+	}
+	return item, nil
+}
+
+// Update aplica un PATCH parcial e incrementa version.
+// Genera SQL dinámico con parámetros (evita SQL injection).
+// Con IfVersion solo actualiza si la versión coincide; si no, devuelve ErrorVersionMismatch o ErrorNotFound.
+func (repository *Repository) Update(context context.Context, id string, itemInputUpdated UpdateItemInput) (Item, error) {
+	setParts := make([]string, 0, 4)
+	args := make([]any, 0, 6)
+	argPos := 1
+
+	// Helper para agregar "campo = $n"
+	addSet := func(expr string, val any) {
+		setParts = append(setParts, fmt.Sprintf(expr, argPos))
+		args = append(args, val)
+		argPos++
+	}
+
+	if itemInputUpdated.Name != nil {
+		addSet("name = $%d", *itemInputUpdated.Name)
+	}
+
+	if itemInputUpdated.Slug != nil {
+		addSet("slug = $%d", *itemInputUpdated.Slug)
+	}
+
+	if itemInputUpdated.SKU != nil {
+		addSet("sku = $%d", *itemInputUpdated.SKU)
+	}
+
+	// description:
+	// - si no vino, no tocar
+	// - si vino null, setear NULL
+	// - si vino con string, setear string
+	if itemInputUpdated.DescriptionPresent {
+		if itemInputUpdated.Description != nil {
+			addSet("description = $%d", *itemInputUpdated.Description)
+		} else {
+			setParts = append(setParts, "description = NULL")
+		}
+	}
+
+	// barcode sigue la misma regla que description: null lo limpia.
+	if itemInputUpdated.BarcodePresent {
+		if itemInputUpdated.Barcode != nil {
+			addSet("barcode = $%d", *itemInputUpdated.Barcode)
+		} else {
+			setParts = append(setParts, "barcode = NULL")
+		}
+	}
+
+	// category_id también: null deja el item sin categoría.
+	if itemInputUpdated.CategoryIDPresent {
+		if itemInputUpdated.CategoryID != nil {
+			addSet("category_id = $%d::uuid", *itemInputUpdated.CategoryID)
+		} else {
+			setParts = append(setParts, "category_id = NULL")
+		}
+	}
+	if itemInputUpdated.Status != nil {
+		addSet("status = $%d", string(*itemInputUpdated.Status))
+	}
+	if itemInputUpdated.State != nil {
+		addSet("state = $%d", string(*itemInputUpdated.State))
+	}
+	if itemInputUpdated.Currency != nil {
+		addSet("currency = $%d", *itemInputUpdated.Currency)
+	}
+	// attributes se reemplaza entero; null lo deja en NULL.
+	if itemInputUpdated.AttributesPresent {
+		if itemInputUpdated.Attributes != nil {
+			addSet("attributes = $%d::jsonb", attributesArg(itemInputUpdated.Attributes))
+		} else {
+			setParts = append(setParts, "attributes = NULL")
+		}
+	}
+	if itemInputUpdated.BrandIDPresent {
+		if itemInputUpdated.BrandID != nil {
+			addSet("brand_id = $%d::uuid", *itemInputUpdated.BrandID)
+		} else {
+			setParts = append(setParts, "brand_id = NULL")
+		}
+	}
+	// peso, medidas y punto de reposición: cada uno por separado, null lo limpia.
+	for _, column := range []struct {
+		name    string
+		present bool
+		value   *int
+	}{
+		{"weight_grams", itemInputUpdated.WeightGramsPresent, itemInputUpdated.WeightGrams},
+		{"width_mm", itemInputUpdated.WidthMMPresent, itemInputUpdated.WidthMM},
+		{"height_mm", itemInputUpdated.HeightMMPresent, itemInputUpdated.HeightMM},
+		{"depth_mm", itemInputUpdated.DepthMMPresent, itemInputUpdated.DepthMM},
+		{"reorder_point", itemInputUpdated.ReorderPointPresent, itemInputUpdated.ReorderPoint},
+	} {
+		if !column.present {
+			continue
+		}
+		if column.value != nil {
+			addSet(column.name+" = $%d", *column.value)
+		} else {
+			setParts = append(setParts, column.name+" = NULL")
+		}
+	}
+
+	if itemInputUpdated.Price != nil {
+		// casteo explícito a numeric
+		addSet("price = $%d::numeric", *itemInputUpdated.Price)
+	}
+
+	// sale_price: null termina la oferta. Que quede debajo del precio lo asegura
+	// ck_items_sale_price_below_price, también cuando el update solo baja el precio.
+	if itemInputUpdated.SalePricePresent {
+		if itemInputUpdated.SalePrice != nil {
+			addSet("sale_price = $%d::numeric", *itemInputUpdated.SalePrice)
+		} else {
+			setParts = append(setParts, "sale_price = NULL")
+		}
+	}
+
+	if itemInputUpdated.TaxRateBPS != nil {
+		addSet("tax_rate_bps = $%d", *itemInputUpdated.TaxRateBPS)
+	}
+
+	if itemInputUpdated.MinOrderQty != nil {
+		addSet("min_order_qty = $%d", *itemInputUpdated.MinOrderQty)
+	}
+
+	if itemInputUpdated.ExpiresAtPresent {
+		if itemInputUpdated.ExpiresAt != nil {
+			addSet("expires_at = $%d::date", *itemInputUpdated.ExpiresAt)
+		} else {
+			setParts = append(setParts, "expires_at = NULL")
+		}
+	}
+
+	if itemInputUpdated.Stock != nil {
+		addSet("stock = $%d", *itemInputUpdated.Stock)
+	}
+
+	if itemInputUpdated.AllowBackorder != nil {
+		addSet("allow_backorder = $%d", *itemInputUpdated.AllowBackorder)
+	}
+
+	if len(setParts) == 0 {
+		return Item{}, ErrorInvalidInput
+	}
+
+	// updated_at y version siempre se actualizan.
+	setParts = append(setParts, "updated_at = now()", "version = version + 1")
+
+	// id va al final; con IfVersion, la versión esperada va después.
+	args = append(args, id)
+	where := fmt.Sprintf("id = $%d AND %s", argPos, notDeleted)
+	if itemInputUpdated.IfVersion != nil {
+		args = append(args, *itemInputUpdated.IfVersion)
+		where += fmt.Sprintf(" AND version = $%d", argPos+1)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE items
+		SET %s
+		WHERE %s
+		RETURNING %s;
+	`, strings.Join(setParts, ", "), where, itemColumns)
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(queryContext, query, args...).
+		Scan(itemDestinations(&item)...)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if itemInputUpdated.IfVersion != nil {
+				return Item{}, repository.staleOrMissing(queryContext, id)
+			}
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, constraintViolation(err)
+	}
+
+	return item, nil
+}
+
+// constraintViolation traduce una violación de constraint al error de dominio.
+// Unicidad (23505) según el índice: ux_items_slug es ErrorDuplicateSlug, ux_items_sku es
+// ErrorDuplicateSKU, ux_items_barcode es ErrorDuplicateBarcode y cualquier otro (ux_items_name)
+// es ErrorDuplicateName.
+// Los checks (23514): el de stock es ErrorInvalidStock (por ejemplo, desactivar allow_backorder con
+// stock negativo), el de formato de SKU es ErrorInvalidSKU, el de estado, ErrorInvalidStatus, y el del
+// precio de oferta (que quede debajo del precio), ErrorInvalidSalePrice. El de la alícuota no se mapea:
+// el service la valida antes y a la DB no llega una fuera de rango.
+// La FK de la categoría (23503) es ErrorUnknownCategory: el cliente mandó un category_id que no existe.
+// La de la marca es ErrorUnknownBrand, por el mismo motivo.
+// Un valor más largo que la columna (22001) es ErrorInvalidInput: el service valida los largos
+// antes, esto es solo el respaldo para no responder 500.
+func constraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if !errors.As(err, &postgresError) {
+		return err
+	}
+	if postgresError.Code == "22001" {
+		return ErrorInvalidInput
+	}
+	if postgresError.Code == "23503" && postgresError.ConstraintName == "fk_items_category" {
+		return ErrorUnknownCategory
+	}
+	if postgresError.Code == "23503" && postgresError.ConstraintName == "fk_items_brand" {
+		return ErrorUnknownBrand
+	}
+	if postgresError.Code == "23514" {
+		switch postgresError.ConstraintName {
+		case "ck_items_stock_non_negative_unless_backorder":
+			return ErrorInvalidStock
+		case "ck_items_status":
+			return ErrorInvalidStatus
+		case "ck_items_state":
+			return ErrorInvalidState
+		case "ck_items_sku_format":
+			return ErrorInvalidSKU
+		case "ck_items_sale_price_below_price":
+			return ErrorInvalidSalePrice
+		}
+		return err
+	}
+	if postgresError.Code != "23505" {
+		return err
+	}
+	switch postgresError.ConstraintName {
+	case "ux_items_slug":
+		return ErrorDuplicateSlug
+	case "ux_items_sku":
+		return ErrorDuplicateSKU
+	case "ux_items_barcode":
+		return ErrorDuplicateBarcode
+	default:
+		return ErrorDuplicateName
+	}
+}
+
+// DeleteMany borra lógicamente en una sola sentencia los items de ids y devuelve los IDs que efectivamente borró.
+// Los que no existen o ya estaban borrados simplemente no aparecen en el resultado.
+func (repository *Repository) DeleteMany(context context.Context, ids []string) ([]string, error) {
+	const query = `UPDATE items SET deleted_at = now(), updated_at = now() WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL RETURNING id;`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deleted := make([]string, 0, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// Delete borra lógicamente un item por ID: completa deleted_at y la fila queda fuera de todas las lecturas.
+// Devuelve el item tal como quedó. ErrorNotFound si no existe o ya estaba borrado.
+// Con ifVersion solo borra si la versión coincide (si no, ErrorVersionMismatch).
+func (repository *Repository) Delete(context context.Context, id string, ifVersion *int) (Item, error) {
+	const query = `
+		UPDATE items
+		SET deleted_at = now(), updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::integer IS NULL OR version = $2)
+		RETURNING ` + itemColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	err = repository.database.QueryRow(queryContext, query, id, ifVersion).Scan(itemDestinations(&item)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if ifVersion != nil {
+				return Item{}, repository.staleOrMissing(queryContext, id)
+			}
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, err
+	}
+
+	return item, nil
+}
+
+// purgedNotification es el NOTIFY en ChangesChannel de cada item purgado; las sentencias que
+// purgan lo agregan con un LATERAL sobre las filas borradas (purged).
+const purgedNotification = `LATERAL (SELECT pg_notify('` + ChangesChannel + `', json_build_object('id', purged.id, 'op', '` + string(ChangePurged) + `')::text)) AS notified`
+
+// TouchedNotification es el NOTIFY en ChangesChannel de cada item que cambió por una tabla que
+// embebe (una categoría o marca renombrada o borrada). Las sentencias de esos paquetes lo agregan
+// como subconsulta sobre un CTE touched que devuelve el id de los items; vale la cantidad avisada.
+const TouchedNotification = `(SELECT count(pg_notify('` + ChangesChannel + `', json_build_object('id', touched.id, 'op', '` +
+	string(EventUpdated) + `')::text)) FROM touched)`
+
+// Purge borra definitivamente un item de la papelera. Devuelve ErrorNotFound si no existe
+// o si no está borrado: un item vivo primero tiene que pasar por Delete.
+func (repository *Repository) Purge(context context.Context, id string) error {
+	const query = `
+		WITH purged AS (
+			DELETE FROM items WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id
+		)
+		SELECT purged.id FROM purged, ` + purgedNotification + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var purgedID string
+	if err := repository.database.QueryRow(queryContext, query, id).Scan(&purgedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// purgeBatchSize es la cantidad de filas que borra cada sentencia de PurgeDeletedBefore.
+const purgeBatchSize = 1000
+
+// PurgeDeletedBefore borra definitivamente los items de la papelera con deleted_at anterior a cutoff
+// y devuelve cuántos borró. Trabaja en tandas de purgeBatchSize para no tener locks largos y,
+// si ctx se cancela entre tandas, devuelve lo borrado hasta ahí junto con el error del contexto.
+// No forma parte de RepositoryAPI: lo usa el job que vacía la papelera.
+func (repository *Repository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `
+		WITH purged AS (
+			DELETE FROM items
+			WHERE id IN (SELECT id FROM items WHERE deleted_at < $1 LIMIT $2)
+			RETURNING id
+		)
+		SELECT purged.id FROM purged, ` + purgedNotification + `;
+	`
+
+	purged := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		batch, err := repository.purgeBatch(ctx, query, cutoff)
+		purged += batch
+		if err != nil || batch < purgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
+// purgeBatch ejecuta una tanda de PurgeDeletedBefore y devuelve cuántas filas borró.
+func (repository *Repository) purgeBatch(ctx context.Context, query string, cutoff time.Time) (int, error) {
+	queryContext, cancel, err := repository.queryContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, cutoff, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	return count, rows.Err()
+}
+
+// reservationColumns son las columnas de Reservation en el orden de reservationDestinations.
+const reservationColumns = `id, item_id, quantity, expires_at, created_at`
+
+func reservationDestinations(reservation *Reservation) []any {
+	return []any{&reservation.ID, &reservation.ItemID, &reservation.Quantity, &reservation.ExpiresAt, &reservation.CreatedAt}
+}
+
+// ReservedQuantity suma las unidades de las reservas vigentes de un item.
+// Para decidir una reserva nueva hay que llamarlo después de GetForUpdate, dentro de InTx:
+// así es una sentencia nueva que ve las reservas que otra transacción confirmó mientras esperábamos el lock.
+func (repository *Repository) ReservedQuantity(context context.Context, itemID string) (int, error) {
+	const query = `
+		SELECT coalesce(sum(quantity), 0)::integer
+		FROM item_reservations
+		WHERE item_id = $1 AND expires_at > now();
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	var reserved int
+	if err := repository.database.QueryRow(queryContext, query, itemID).Scan(&reserved); err != nil {
+		return 0, err
+	}
+	return reserved, nil
+}
+
+// InsertReservation guarda una reserva de quantity unidades que vence en ttl. No verifica el stock:
+// eso lo hace el service con el item bloqueado. En la misma sentencia sube version y updated_at del
+// item, porque cambia su available y el ETag y las cachés tienen que enterarse.
+func (repository *Repository) InsertReservation(context context.Context, itemID string, quantity int, ttl time.Duration) (Reservation, error) {
+	const query = `
+		WITH touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id = $1 AND deleted_at IS NULL
+		)
+		INSERT INTO item_reservations (item_id, quantity, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		RETURNING ` + reservationColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Reservation{}, err
+	}
+	defer cancel()
+
+	var reservation Reservation
+	err = repository.database.QueryRow(queryContext, query, itemID, quantity, ttl.Seconds()).
+		Scan(reservationDestinations(&reservation)...)
+	if err != nil {
+		return Reservation{}, err
+	}
+	return reservation, nil
+}
+
+// DeleteReservation libera una reserva del item. Devuelve ErrorNotFound si la reserva no existe
+// o es de otro item. Una reserva vencida que todavía no se limpió se borra igual.
+// Si borró la reserva sube version y updated_at del item, igual que InsertReservation.
+func (repository *Repository) DeleteReservation(context context.Context, itemID, reservationID string) error {
+	const query = `
+		WITH released AS (
+			DELETE FROM item_reservations WHERE id = $1 AND item_id = $2 RETURNING id, item_id
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id IN (SELECT item_id FROM released)
+		)
+		SELECT id FROM released;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var deletedID string
+	if err := repository.database.QueryRow(queryContext, query, reservationID, itemID).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteExpiredReservations borra las reservas vencidas y devuelve cuántas borró y los IDs de los
+// items que tenían alguna. Las vencidas ya no cuentan para available, pero al vencer no cambió la
+// versión del item: se la sube a esos items, así el ETag y las cachés dejan de servir el available
+// viejo. Los items borrados no se tocan ni se devuelven.
+func (repository *Repository) DeleteExpiredReservations(ctx context.Context) (int, []string, error) {
+	const query = `
+		WITH expired AS (
+			DELETE FROM item_reservations WHERE expires_at <= now() RETURNING id, item_id
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id IN (SELECT item_id FROM expired) AND deleted_at IS NULL
+			RETURNING id
+		)
+		SELECT (SELECT count(*) FROM expired)::integer, coalesce((SELECT array_agg(id::text) FROM touched), '{}');
+	`
+
+	queryContext, cancel, err := repository.queryContext(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cancel()
+
+	var (
+		deleted int
+		itemIDs []string
+	)
+	if err := repository.database.QueryRow(queryContext, query).Scan(&deleted, &itemIDs); err != nil {
+		return 0, nil, err
+	}
+	return deleted, itemIDs, nil
+}
+
+// variantColumns son las columnas de Variant en el orden de variantDestinations. effective_price
+// es el precio propio o, si es NULL, el del item (una subquery, para que sirva en los RETURNING).
+const variantColumns = `id, item_id, sku, price::text, ` +
+	`coalesce(price, (SELECT items.price FROM items WHERE items.id = item_variants.item_id))::text AS effective_price, ` +
+	`stock, attributes, created_at, updated_at`
+
+func variantDestinations(variant *Variant) []any {
+	return []any{&variant.ID, &variant.ItemID, &variant.SKU, &variant.Price, &variant.EffectivePrice,
+		&variant.Stock, &variant.Attributes, &variant.CreatedAt, &variant.UpdatedAt}
+}
+
+// variantAttributesArg es el parámetro jsonb de los atributos de una variante; sin atributos es {}.
+func variantAttributesArg(attributes map[string]string) string {
+	if attributes == nil {
+		return "{}"
+	}
+	return string(mustMarshal(attributes))
+}
+
+// ListVariants devuelve las variantes del item en el orden en que se crearon.
+func (repository *Repository) ListVariants(context context.Context, itemID string) ([]Variant, error) {
+	const query = `
+		SELECT ` + variantColumns + `
+		FROM item_variants
+		WHERE item_id = $1
+		ORDER BY created_at, id;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []Variant{}
+	for rows.Next() {
+		var variant Variant
+		if err := rows.Scan(variantDestinations(&variant)...); err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, rows.Err()
+}
+
+// InsertVariant crea una variante del item. No toca el stock del item: eso lo hace el service
+// en la misma transacción.
+func (repository *Repository) InsertVariant(context context.Context, itemID string, input CreateVariantInput) (Variant, error) {
+	const query = `
+		INSERT INTO item_variants (item_id, sku, price, stock, attributes)
+		VALUES ($1, $2, $3::numeric, $4, $5::jsonb)
+		RETURNING ` + variantColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Variant{}, err
+	}
+	defer cancel()
+
+	var variant Variant
+	err = repository.database.QueryRow(queryContext, query, itemID, input.SKU, input.Price, input.Stock, variantAttributesArg(input.Attributes)).
+		Scan(variantDestinations(&variant)...)
+	if err != nil {
+		return Variant{}, variantConstraintViolation(err)
+	}
+	return variant, nil
+}
+
+// UpdateVariant aplica los campos presentes de input. Devuelve ErrorVariantNotFound si la variante
+// no existe o es de otro item, y ErrorInvalidInput si input no trae ningún campo.
+func (repository *Repository) UpdateVariant(context context.Context, itemID, variantID string, input UpdateVariantInput) (Variant, error) {
+	var setParts []string
+	var args []any
+	addSet := func(format string, value any) {
+		args = append(args, value)
+		setParts = append(setParts, fmt.Sprintf(format, len(args)))
+	}
+
+	if input.SKU != nil {
+		addSet("sku = $%d", *input.SKU)
+	}
+	// price en null vuelve a heredar el precio del item.
+	if input.PricePresent {
+		if input.Price != nil {
+			addSet("price = $%d::numeric", *input.Price)
+		} else {
+			setParts = append(setParts, "price = NULL")
+		}
+	}
+	if input.Stock != nil {
+		addSet("stock = $%d", *input.Stock)
+	}
+	if input.AttributesPresent {
+		addSet("attributes = $%d::jsonb", variantAttributesArg(input.Attributes))
+	}
+	if len(setParts) == 0 {
+		return Variant{}, ErrorInvalidInput
+	}
+	setParts = append(setParts, "updated_at = now()")
+
+	args = append(args, variantID, itemID)
+	query := fmt.Sprintf(`
+		UPDATE item_variants
+		SET %s
+		WHERE id = $%d AND item_id = $%d
+		RETURNING %s;
+	`, strings.Join(setParts, ", "), len(args)-1, len(args), variantColumns)
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Variant{}, err
+	}
+	defer cancel()
+
+	var variant Variant
+	if err := repository.database.QueryRow(queryContext, query, args...).Scan(variantDestinations(&variant)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Variant{}, ErrorVariantNotFound
+		}
+		return Variant{}, variantConstraintViolation(err)
+	}
+	return variant, nil
+}
+
+// DeleteVariant borra una variante del item. Devuelve ErrorVariantNotFound si no existe o es de otro item.
+func (repository *Repository) DeleteVariant(context context.Context, itemID, variantID string) error {
+	const query = `DELETE FROM item_variants WHERE id = $1 AND item_id = $2 RETURNING id;`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var deletedID string
+	if err := repository.database.QueryRow(queryContext, query, variantID, itemID).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorVariantNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// VariantStock devuelve cuántas variantes tiene el item y la suma de su stock.
+// ux_item_variants_item_sku resuelve la búsqueda por item_id.
+func (repository *Repository) VariantStock(context context.Context, itemID string) (count, total int, err error) {
+	const query = `
+		SELECT count(*)::integer, coalesce(sum(stock), 0)::integer
+		FROM item_variants
+		WHERE item_id = $1;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cancel()
+
+	if err := repository.database.QueryRow(queryContext, query, itemID).Scan(&count, &total); err != nil {
+		return 0, 0, err
+	}
+	return count, total, nil
+}
+
+// variantConstraintViolation traduce las violaciones de constraint de item_variants: el SKU repetido
+// dentro del item es ErrorDuplicateVariantSKU y los checks de precio y stock, ErrorInvalidPrice y
+// ErrorInvalidStock.
+func variantConstraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if !errors.As(err, &postgresError) {
+		return err
+	}
+	switch postgresError.ConstraintName {
+	case "ux_item_variants_item_sku":
+		return ErrorDuplicateVariantSKU
+	case "ck_item_variants_price_positive":
+		return ErrorInvalidPrice
+	case "ck_item_variants_stock_non_negative":
+		return ErrorInvalidStock
+	}
+	return err
+}
+
+// priceScheduleColumns son las columnas de PriceSchedule en el orden de priceScheduleDestinations.
+const priceScheduleColumns = `id, item_id, new_price::text, effective_at, applied_at, failed_at, coalesce(failure_reason, ''), created_at`
+
+func priceScheduleDestinations(schedule *PriceSchedule) []any {
+	return []any{&schedule.ID, &schedule.ItemID, &schedule.NewPrice, &schedule.EffectiveAt, &schedule.AppliedAt,
+		&schedule.FailedAt, &schedule.FailureReason, &schedule.CreatedAt}
+}
+
+// ListPriceSchedules devuelve los cambios de precio del item, pendientes, aplicados y fallidos, por effective_at.
+func (repository *Repository) ListPriceSchedules(context context.Context, itemID string) ([]PriceSchedule, error) {
+	const query = `
+		SELECT ` + priceScheduleColumns + `
+		FROM price_schedules
+		WHERE item_id = $1
+		ORDER BY effective_at, id;
+	`
+	return repository.queryPriceSchedules(context, query, itemID)
+}
+
+// DuePriceSchedules devuelve hasta limit cambios pendientes cuyo effective_at ya pasó, del más viejo
+// al más nuevo, así dos cambios vencidos del mismo item se aplican en orden y gana el último.
+func (repository *Repository) DuePriceSchedules(context context.Context, limit int) ([]PriceSchedule, error) {
+	const query = `
+		SELECT ` + priceScheduleColumns + `
+		FROM price_schedules
+		WHERE applied_at IS NULL AND failed_at IS NULL AND effective_at <= now()
+		ORDER BY effective_at, id
+		LIMIT $1;
+	`
+	return repository.queryPriceSchedules(context, query, limit)
+}
+
+// queryPriceSchedules corre una query que devuelve priceScheduleColumns.
+func (repository *Repository) queryPriceSchedules(context context.Context, query string, args ...any) ([]PriceSchedule, error) {
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []PriceSchedule{}
+	for rows.Next() {
+		var schedule PriceSchedule
+		if err := rows.Scan(priceScheduleDestinations(&schedule)...); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// InsertPriceSchedule programa un cambio de precio del item. En la misma sentencia sube version y
+// updated_at del item, porque cambia su next_price_change.
+func (repository *Repository) InsertPriceSchedule(context context.Context, itemID string, input CreatePriceScheduleInput) (PriceSchedule, error) {
+	const query = `
+		WITH touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id = $1 AND deleted_at IS NULL
+		)
+		INSERT INTO price_schedules (item_id, new_price, effective_at)
+		VALUES ($1, $2::numeric, $3)
+		RETURNING ` + priceScheduleColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return PriceSchedule{}, err
+	}
+	defer cancel()
+
+	var schedule PriceSchedule
+	err = repository.database.QueryRow(queryContext, query, itemID, input.NewPrice, input.EffectiveAt).
+		Scan(priceScheduleDestinations(&schedule)...)
+	if err != nil {
+		return PriceSchedule{}, priceScheduleConstraintViolation(err)
+	}
+	return schedule, nil
+}
+
+// DeletePriceSchedule borra un cambio pendiente del item. Los aplicados y los fallidos no se borran:
+// quedan como registro.
+// Si lo borró sube version y updated_at del item, igual que InsertPriceSchedule.
+func (repository *Repository) DeletePriceSchedule(context context.Context, itemID, scheduleID string) error {
+	const query = `
+		WITH cancelled AS (
+			DELETE FROM price_schedules
+			WHERE id = $1 AND item_id = $2 AND applied_at IS NULL AND failed_at IS NULL
+			RETURNING id, item_id
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id IN (SELECT item_id FROM cancelled)
+		)
+		SELECT id FROM cancelled;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var deletedID string
+	if err := repository.database.QueryRow(queryContext, query, scheduleID, itemID).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorPriceScheduleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// MarkPriceScheduleApplied completa applied_at de un cambio pendiente. El UPDATE bloquea la fila, así
+// que si dos instancias del job toman el mismo cambio, la segunda no lo ve pendiente y recibe
+// ErrorPriceScheduleNotFound.
+func (repository *Repository) MarkPriceScheduleApplied(context context.Context, scheduleID string) (PriceSchedule, error) {
+	const query = `
+		UPDATE price_schedules
+		SET applied_at = now()
+		WHERE id = $1 AND applied_at IS NULL AND failed_at IS NULL
+		RETURNING ` + priceScheduleColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return PriceSchedule{}, err
+	}
+	defer cancel()
+
+	var schedule PriceSchedule
+	if err := repository.database.QueryRow(queryContext, query, scheduleID).Scan(priceScheduleDestinations(&schedule)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PriceSchedule{}, ErrorPriceScheduleNotFound
+		}
+		return PriceSchedule{}, err
+	}
+	return schedule, nil
+}
+
+// MarkPriceScheduleFailed marca fallido un cambio que el job tomó con MarkPriceScheduleApplied
+// (usar en la misma transacción): vuelve applied_at a NULL y guarda el motivo. Deja de ser el
+// próximo cambio del item, así que también le sube version y updated_at.
+func (repository *Repository) MarkPriceScheduleFailed(context context.Context, scheduleID, reason string) error {
+	const query = `
+		WITH failed AS (
+			UPDATE price_schedules
+			SET applied_at = NULL, failed_at = now(), failure_reason = $2
+			WHERE id = $1
+			RETURNING item_id
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id IN (SELECT item_id FROM failed)
+		)
+		SELECT item_id FROM failed;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var itemID string
+	if err := repository.database.QueryRow(queryContext, query, scheduleID, reason).Scan(&itemID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorPriceScheduleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// priceScheduleConstraintViolation traduce las violaciones de constraint de price_schedules: otro
+// cambio pendiente en el mismo instante es ErrorDuplicatePriceSchedule, el check de precio es
+// ErrorInvalidPrice y un item que no existe, ErrorNotFound.
+func priceScheduleConstraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if !errors.As(err, &postgresError) {
+		return err
+	}
+	switch postgresError.ConstraintName {
+	case "ux_price_schedules_item_effective_at_pending":
+		return ErrorDuplicatePriceSchedule
+	case "ck_price_schedules_new_price_positive":
+		return ErrorInvalidPrice
+	case "fk_price_schedules_item":
+		return ErrorNotFound
+	}
+	return err
+}
+
+// externalRefColumns son las columnas de ExternalRef en el orden de externalRefDestinations.
+const externalRefColumns = `system, external_id, created_at`
+
+func externalRefDestinations(ref *ExternalRef) []any {
+	return []any{&ref.System, &ref.ExternalID, &ref.CreatedAt}
+}
+
+// GetByExternalRef busca el item al que apunta la ref (system, externalID). Los borradores también
+// se encuentran: los jobs de sincronización los necesitan. Igual que GetByID, devuelve pgx.ErrNoRows
+// si no existe.
+func (repository *Repository) GetByExternalRef(context context.Context, system, externalID string) (Item, error) {
+	const query = `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE id = (SELECT item_id FROM item_external_refs WHERE system = $1 AND external_id = $2) AND deleted_at IS NULL;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Item{}, err
+	}
+	defer cancel()
+
+	var item Item
+	if err := repository.database.QueryRow(queryContext, query, system, externalID).Scan(itemDestinations(&item)...); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// InsertExternalRef agrega una ref externa al item. En la misma sentencia sube version y updated_at
+// del item, porque cambian sus refs.
+func (repository *Repository) InsertExternalRef(context context.Context, itemID string, input ExternalRefInput) (ExternalRef, error) {
+	const query = `
+		WITH touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id = $1 AND deleted_at IS NULL
+		)
+		INSERT INTO item_external_refs (item_id, system, external_id)
+		VALUES ($1, $2, $3)
+		RETURNING ` + externalRefColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return ExternalRef{}, err
+	}
+	defer cancel()
+
+	var ref ExternalRef
+	err = repository.database.QueryRow(queryContext, query, itemID, input.System, input.ExternalID).
+		Scan(externalRefDestinations(&ref)...)
+	if err != nil {
+		return ExternalRef{}, externalRefConstraintViolation(err)
+	}
+	return ref, nil
+}
+
+// DeleteExternalRef quita una ref externa del item. Si la quitó sube version y updated_at del item,
+// igual que InsertExternalRef.
+func (repository *Repository) DeleteExternalRef(context context.Context, itemID, system, externalID string) error {
+	const query = `
+		WITH removed AS (
+			DELETE FROM item_external_refs WHERE system = $1 AND external_id = $2 AND item_id = $3 RETURNING item_id
+		), touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id IN (SELECT item_id FROM removed)
+		)
+		SELECT item_id FROM removed;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var deletedID string
+	if err := repository.database.QueryRow(queryContext, query, system, externalID, itemID).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorExternalRefNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// externalRefConstraintViolation traduce las violaciones de constraint de item_external_refs: la ref
+// ya usada por otro item es ErrorDuplicateExternalRef y un item que no existe, ErrorNotFound.
+func externalRefConstraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if !errors.As(err, &postgresError) {
+		return err
+	}
+	switch postgresError.ConstraintName {
+	case "pk_item_external_refs":
+		return ErrorDuplicateExternalRef
+	case "fk_item_external_refs_item":
+		return ErrorNotFound
+	}
+	return err
+}
+
+// translationColumns son las columnas de Translation en el orden de translationDestinations.
+const translationColumns = `locale, name, description, updated_at`
+
+func translationDestinations(translation *Translation) []any {
+	return []any{&translation.Locale, &translation.Name, &translation.Description, &translation.UpdatedAt}
+}
+
+// ListTranslations devuelve las traducciones del item, ordenadas por locale.
+func (repository *Repository) ListTranslations(context context.Context, itemID string) ([]Translation, error) {
+	const query = `
+		SELECT ` + translationColumns + `
+		FROM item_translations
+		WHERE item_id = $1
+		ORDER BY locale;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []Translation{}
+	for rows.Next() {
+		var translation Translation
+		if err := rows.Scan(translationDestinations(&translation)...); err != nil {
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+	return translations, rows.Err()
+}
+
+// TranslationsFor devuelve las traducciones a locale de los items pedidos, por ID de item. Los
+// items sin traducción no aparecen en el map.
+func (repository *Repository) TranslationsFor(context context.Context, locale string, itemIDs []string) (map[string]Translation, error) {
+	const query = `
+		SELECT item_id, ` + translationColumns + `
+		FROM item_translations
+		WHERE locale = $1 AND item_id = ANY($2::uuid[]);
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, locale, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[string]Translation, len(itemIDs))
+	for rows.Next() {
+		var itemID string
+		var translation Translation
+		if err := rows.Scan(append([]any{&itemID}, translationDestinations(&translation)...)...); err != nil {
+			return nil, err
+		}
+		translations[itemID] = translation
+	}
+	return translations, rows.Err()
+}
+
+// UpsertTranslation crea o reemplaza la traducción del item a locale. También incrementa la versión
+// del item: la representación traducida cambió, así que los ETags (del item y del listado) también.
+func (repository *Repository) UpsertTranslation(context context.Context, itemID, locale string, input TranslationInput) (Translation, error) {
+	const query = `
+		WITH touched AS (
+			UPDATE items SET updated_at = now(), version = version + 1
+			WHERE id = $1 AND deleted_at IS NULL
+		)
+		INSERT INTO item_translations (item_id, locale, name, description)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (item_id, locale) DO UPDATE
+		SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = now()
+		RETURNING ` + translationColumns + `;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return Translation{}, err
+	}
+	defer cancel()
+
+	var translation Translation
+	err = repository.database.QueryRow(queryContext, query, itemID, locale, input.Name, input.Description).
+		Scan(translationDestinations(&translation)...)
+	if err != nil {
+		var postgresError *pgconn.PgError
+		if errors.As(err, &postgresError) && postgresError.ConstraintName == "fk_item_translations_item" {
+			return Translation{}, ErrorNotFound
+		}
+		return Translation{}, err
+	}
+	return translation, nil
+}
+
+// stockMovementColumns son las columnas de StockMovement en el orden de stockMovementDestinations.
+const stockMovementColumns = `id, item_id, delta, resulting_stock, reason, coalesce(request_id, ''), created_at`
+
+func stockMovementDestinations(movement *StockMovement) []any {
+	return []any{&movement.ID, &movement.ItemID, &movement.Delta, &movement.ResultingStock,
+		&movement.Reason, &movement.RequestID, &movement.CreatedAt}
+}
+
+// InsertStockMovement agrega un movimiento al historial. Se llama dentro de la misma transacción
+// que cambió el stock. Un RequestID vacío se guarda como NULL.
+func (repository *Repository) InsertStockMovement(context context.Context, movement StockMovement) error {
+	const query = `
+		INSERT INTO stock_movements (item_id, delta, resulting_stock, reason, request_id)
+		VALUES ($1, $2, $3, $4, nullif($5, ''))
+		RETURNING id;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var id int64
+	return repository.database.QueryRow(queryContext, query,
+		movement.ItemID, movement.Delta, movement.ResultingStock, movement.Reason, movement.RequestID,
+	).Scan(&id)
+}
+
+// ListStockMovements devuelve una página del historial de un item, del más nuevo al más viejo,
+// y el total de movimientos del item (COUNT(*) OVER (), como ListWithTotal).
+// Una página más allá de la última vuelve vacía y con total 0.
+func (repository *Repository) ListStockMovements(context context.Context, itemID string, limit, offset int) ([]StockMovement, int, error) {
+	const query = `
+		SELECT ` + stockMovementColumns + `, COUNT(*) OVER () AS total
+		FROM stock_movements
+		WHERE item_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, itemID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := make([]StockMovement, 0, limit)
+	total := 0
+	for rows.Next() {
+		var movement StockMovement
+		if err := rows.Scan(append(stockMovementDestinations(&movement), &total)...); err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+// priceHistoryColumns son las columnas de PriceHistoryEntry en el orden de priceHistoryDestinations.
+const priceHistoryColumns = `id, item_id, old_price::text, new_price::text, reason, coalesce(request_id, ''), changed_at`
+
+func priceHistoryDestinations(entry *PriceHistoryEntry) []any {
+	return []any{&entry.ID, &entry.ItemID, &entry.OldPrice, &entry.NewPrice, &entry.Reason, &entry.RequestID, &entry.ChangedAt}
+}
+
+// InsertPriceChange agrega un cambio al historial de precios. Un request id vacío queda NULL.
+func (repository *Repository) InsertPriceChange(context context.Context, entry PriceHistoryEntry) error {
+	const query = `
+		INSERT INTO price_history (item_id, old_price, new_price, reason, request_id)
+		VALUES ($1, $2::numeric, $3::numeric, $4, nullif($5, ''))
+		RETURNING id;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var id int64
+	return repository.database.QueryRow(queryContext, query,
+		entry.ItemID, entry.OldPrice, entry.NewPrice, entry.Reason, entry.RequestID,
+	).Scan(&id)
+}
+
+// InsertOutbox agrega los eventos al outbox en una sola sentencia, en el orden de events. El payload
+// es el evento en JSON, lo mismo que manda GET /items/events.
+func (repository *Repository) InsertOutbox(context context.Context, events []Event) error {
+	const query = `
+		WITH inserted AS (
+			INSERT INTO outbox (event_type, payload)
+			SELECT event_type, payload::jsonb
+			FROM unnest($1::text[], $2::text[]) WITH ORDINALITY AS events (event_type, payload, position)
+			ORDER BY position
+			RETURNING 1
+		)
+		SELECT count(*) FROM inserted;
+	`
+
+	types := make([]string, len(events))
+	payloads := make([]string, len(events))
+	for index, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		types[index] = event.Type()
+		payloads[index] = string(payload)
+	}
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var inserted int
+	return repository.database.QueryRow(queryContext, query, types, payloads).Scan(&inserted)
+}
+
+// NotifyChanges hace un NOTIFY en ChangesChannel por evento, con {"id", "op"} como payload. Dentro
+// de una transacción PostgreSQL los manda al confirmar y descarta los repetidos.
+func (repository *Repository) NotifyChanges(context context.Context, events []Event) error {
+	const query = `
+		SELECT count(*) FROM (
+			SELECT pg_notify($1, payload) FROM unnest($2::text[]) AS payload
+		) AS notified;
+	`
+
+	payloads := make([]string, len(events))
+	for index, event := range events {
+		payload, err := json.Marshal(ChangeNotification{ID: event.ID, Operation: event.Operation})
+		if err != nil {
+			return err
+		}
+		payloads[index] = string(payload)
+	}
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var notified int
+	return repository.database.QueryRow(queryContext, query, ChangesChannel, payloads).Scan(&notified)
+}
+
+// InsertAudit agrega las entradas a la auditoría en una sola sentencia. Changes va como JSON y un
+// request id o actor vacío queda NULL.
+func (repository *Repository) InsertAudit(context context.Context, entries []AuditEntry) error {
+	const query = `
+		WITH inserted AS (
+			INSERT INTO item_audit (item_id, action, changes, request_id, actor)
+			SELECT item_id, action, changes::jsonb, nullif(request_id, ''), nullif(actor, '')
+			FROM unnest($1::uuid[], $2::text[], $3::text[], $4::text[], $5::text[]) WITH ORDINALITY
+				AS entries (item_id, action, changes, request_id, actor, position)
+			ORDER BY position
+			RETURNING 1
+		)
+		SELECT count(*) FROM inserted;
+	`
+
+	itemIDs := make([]string, len(entries))
+	actions := make([]string, len(entries))
+	changes := make([]string, len(entries))
+	requestIDs := make([]string, len(entries))
+	actors := make([]string, len(entries))
+	for index, entry := range entries {
+		encoded, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+		itemIDs[index], actions[index], changes[index] = entry.ItemID, string(entry.Action), string(encoded)
+		requestIDs[index], actors[index] = entry.RequestID, entry.Actor
+	}
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var inserted int
+	return repository.database.QueryRow(queryContext, query, itemIDs, actions, changes, requestIDs, actors).Scan(&inserted)
+}
+
+// ListAudit devuelve una página de la auditoría de un item, de la más nueva a la más vieja, y el total
+// de entradas (COUNT(*) OVER (), como ListPriceHistory).
+func (repository *Repository) ListAudit(context context.Context, itemID string, limit, offset int) ([]AuditEntry, int, error) {
+	const query = `
+		SELECT id, item_id, action, changes, coalesce(request_id, ''), coalesce(actor, ''), created_at,
+			COUNT(*) OVER () AS total
+		FROM item_audit
+		WHERE item_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, itemID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]AuditEntry, 0, limit)
+	total := 0
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.ItemID, &entry.Action, &entry.Changes, &entry.RequestID, &entry.Actor, &entry.CreatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// ListPriceHistory devuelve una página del historial de precios de un item dentro de filter, del
+// más nuevo al más viejo, y el total de cambios que matchean (COUNT(*) OVER (), como ListStockMovements).
+func (repository *Repository) ListPriceHistory(context context.Context, itemID string, filter PriceHistoryFilter, limit, offset int) ([]PriceHistoryEntry, int, error) {
+	query := `
+		SELECT ` + priceHistoryColumns + `, COUNT(*) OVER () AS total
+		FROM price_history
+		WHERE item_id = $1`
+	args := []any{itemID, limit, offset}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND changed_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND changed_at < $%d", len(args))
+	}
+	query += `
+		ORDER BY changed_at DESC, id DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]PriceHistoryEntry, 0, limit)
+	total := 0
+	for rows.Next() {
+		var entry PriceHistoryEntry
+		if err := rows.Scan(append(priceHistoryDestinations(&entry), &total)...); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// priceAdjustmentExpressions es el precio nuevo de cada tipo de ajuste masivo; $1 es el valor del ajuste.
+// Es una whitelist: el tipo nunca se interpola en la query.
+var priceAdjustmentExpressions = map[PriceAdjustmentType]string{
+	AdjustmentPercent: "price * (1 + $1::numeric / 100)",
+	AdjustmentFixed:   "price + $1::numeric",
+}
+
+// adjustedPriceColumn devuelve el precio nuevo de adjustment redondeado a los decimales de la moneda
+// de cada item (la mitad hacia arriba); $2 son las monedas sin centavos.
+func adjustedPriceColumn(adjustment PriceAdjustment) (string, error) {
+	expression, ok := priceAdjustmentExpressions[adjustment.Type]
+	if !ok {
+		return "", fmt.Errorf("unsupported price adjustment %q", adjustment.Type)
+	}
+	return "round(" + expression + ", CASE WHEN currency = ANY($2::text[]) THEN 0 ELSE 2 END)", nil
+}
+
+// PreviewPriceAdjustment calcula adjustment sobre los items del filtro sin cambiarlos: los totales
+// salen de COUNT(*) OVER () sobre todo el filtro (como ListWithTotal) y la muestra son los primeros
+// sampleSize items por nombre.
+func (repository *Repository) PreviewPriceAdjustment(context context.Context, filter ListFilter, adjustment PriceAdjustment, sampleSize int) (PriceAdjustmentPreview, error) {
+	newPrice, err := adjustedPriceColumn(adjustment)
+	if err != nil {
+		return PriceAdjustmentPreview{}, err
+	}
+	where, filterArgs := buildListWhere(filter, 4)
+	query := `
+		WITH adjusted AS (
+			SELECT id, name, currency, price, sale_price, ` + newPrice + ` AS new_price
+			FROM items` + where + `
+		)
+		SELECT id, name, currency, price::text, new_price::numeric(10,2)::text,
+			COUNT(*) OVER (),
+			COUNT(*) FILTER (WHERE new_price <= 0) OVER (),
+			COUNT(*) FILTER (WHERE sale_price >= new_price) OVER ()
+		FROM adjusted
+		ORDER BY name, id
+		LIMIT $3;
+	`
+	args := append([]any{adjustment.Value, currency.WholeUnit(), sampleSize}, filterArgs...)
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return PriceAdjustmentPreview{}, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, args...)
+	if err != nil {
+		return PriceAdjustmentPreview{}, err
+	}
+	defer rows.Close()
+
+	preview := PriceAdjustmentPreview{Sample: make([]PriceAdjustmentSample, 0, sampleSize)}
+	for rows.Next() {
+		var sample PriceAdjustmentSample
+		if err := rows.Scan(&sample.ID, &sample.Name, &sample.Currency, &sample.OldPrice, &sample.NewPrice,
+			&preview.Affected, &preview.NonPositive, &preview.NotAboveSalePrice); err != nil {
+			return PriceAdjustmentPreview{}, err
+		}
+		preview.Sample = append(preview.Sample, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return PriceAdjustmentPreview{}, err
+	}
+	return preview, nil
+}
+
+// AdjustPrices aplica adjustment a los items del filtro en un solo statement: bloquea los items, cambia
+// el precio de lista (con version y updated_at, como Update) y registra en price_history y en la
+// auditoría los que cambiaron, con reason y requestID. Devuelve los IDs de los items que actualizó.
+// El statement no lee los items en el service, así que arma acá el único cambio de la auditoría, con
+// el precio en el mismo formato que el JSON del item.
+// No valida los precios resultantes: ck_items_price_positive y ck_items_sale_price_below_price
+// rechazan el statement entero; el service los revisa antes con PreviewPriceAdjustment.
+func (repository *Repository) AdjustPrices(context context.Context, filter ListFilter, adjustment PriceAdjustment, reason, requestID string) ([]string, error) {
+	newPrice, err := adjustedPriceColumn(adjustment)
+	if err != nil {
+		return nil, err
+	}
+	where, filterArgs := buildListWhere(filter, 5)
+	query := `
+		WITH target AS (
+			SELECT id, price AS old_price
+			FROM items` + where + `
+			FOR UPDATE
+		), adjusted AS (
+			UPDATE items SET price = ` + newPrice + `, updated_at = now(), version = version + 1
+			FROM target
+			WHERE items.id = target.id
+			RETURNING items.id, target.old_price, items.price
+		), history AS (
+			INSERT INTO price_history (item_id, old_price, new_price, reason, request_id)
+			SELECT id, old_price, price, $3, nullif($4, '')
+			FROM adjusted
+			WHERE price <> old_price
+		), audit AS (
+			INSERT INTO item_audit (item_id, action, changes, request_id)
+			SELECT id, 'updated', jsonb_build_array(jsonb_build_object('field', 'price', 'old', old_price::text, 'new', price::text)), nullif($4, '')
+			FROM adjusted
+			WHERE price <> old_price
+		)
+		SELECT id FROM adjusted;
+	`
+	args := append([]any{adjustment.Value, currency.WholeUnit(), reason, requestID}, filterArgs...)
+
+	queryContext, cancel, err := repository.queryContext(context)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	rows, err := repository.database.Query(queryContext, query, args...)
+	if err != nil {
+		return nil, constraintViolation(err)
+	}
+	defer rows.Close()
+
+	var adjusted []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		adjusted = append(adjusted, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, constraintViolation(err)
+	}
+	return adjusted, nil
+}
+
+// staleOrMissing distingue por qué un update o delete con versión esperada no tocó ninguna fila:
+// ErrorVersionMismatch si el item existe (otro request lo cambió) o ErrorNotFound si no existe o está borrado.
+func (repository *Repository) staleOrMissing(context context.Context, id string) error {
+	const query = `SELECT EXISTS (SELECT 1 FROM items WHERE id = $1 AND deleted_at IS NULL);`
+
+	var exists bool
+	if err := repository.database.QueryRow(context, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrorVersionMismatch
+	}
+	return ErrorNotFound
 }
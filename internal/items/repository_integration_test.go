@@ -0,0 +1,1882 @@
+//go:build integration
+
+package items
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func newIntegrationPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// seedItems inserta items con un prefijo único por test y los borra definitivamente al terminar.
+func seedItems(t *testing.T, repository *Repository, inputs ...CreateItemInput) []Item {
+	t.Helper()
+
+	created := make([]Item, 0, len(inputs))
+	for _, input := range inputs {
+		// El repositorio no genera slugs (lo hace el service); los nombres de prueba ya son únicos.
+		if input.Slug == "" {
+			input.Slug = slugify(input.Name)
+		}
+		item, err := repository.Insert(context.Background(), input)
+		require.NoError(t, err)
+		created = append(created, item)
+	}
+	t.Cleanup(func() {
+		for _, item := range created {
+			_, _ = repository.Delete(context.Background(), item.ID, nil)
+			_ = repository.Purge(context.Background(), item.ID)
+		}
+	})
+	return created
+}
+
+func TestRepositoryIntegration_SortByPriceIsNumeric(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "sort-price-" + uuid.NewString()
+	seedItems(t, repository,
+		CreateItemInput{Name: prefix + "-a", Price: "9.50", Stock: 1},
+		CreateItemInput{Name: prefix + "-b", Price: "100.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-c", Price: "20.00", Stock: 1},
+	)
+
+	filter := ListFilter{Query: prefix, Match: MatchPrefix, Sort: []SortKey{"price"}}
+	ascending, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"9.50", "20.00", "100.00"}, prices(ascending))
+
+	filter.Sort = []SortKey{"-price"}
+	descending, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"100.00", "20.00", "9.50"}, prices(descending))
+}
+
+func TestRepositoryIntegration_MultiFieldSort(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "sort-multi-" + uuid.NewString()
+	seedItems(t, repository,
+		CreateItemInput{Name: prefix + "-a", Price: "10.00", Stock: 5},
+		CreateItemInput{Name: prefix + "-b", Price: "30.00", Stock: 0},
+		CreateItemInput{Name: prefix + "-c", Price: "20.00", Stock: 5},
+		CreateItemInput{Name: prefix + "-d", Price: "15.00", Stock: 0},
+	)
+
+	filter := ListFilter{Query: prefix, Match: MatchPrefix, Sort: []SortKey{"stock", "-price"}}
+	listed, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"30.00", "15.00", "20.00", "10.00"}, prices(listed))
+}
+
+func TestRepositoryIntegration_PriceRange(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "price-range-" + uuid.NewString()
+	seedItems(t, repository,
+		CreateItemInput{Name: prefix + "-a", Price: "9.50", Stock: 1},
+		CreateItemInput{Name: prefix + "-b", Price: "10.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-c", Price: "99.99", Stock: 1},
+		CreateItemInput{Name: prefix + "-d", Price: "100.00", Stock: 1},
+	)
+
+	filter := ListFilter{Query: prefix, Match: MatchPrefix, MinPrice: "10.00", MaxPrice: "99.99", Sort: []SortKey{"price"}}
+	listed, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.00", "99.99"}, prices(listed))
+
+	total, err := repository.Count(context.Background(), filter)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+}
+
+func TestRepositoryIntegration_StockFilters(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "stock-filter-" + uuid.NewString()
+	seedItems(t, repository,
+		CreateItemInput{Name: prefix + "-a", Price: "1.00", Stock: 0},
+		CreateItemInput{Name: prefix + "-b", Price: "2.00", Stock: 3},
+		CreateItemInput{Name: prefix + "-c", Price: "3.00", Stock: 12},
+	)
+
+	inStock := true
+	lte := 5
+	filter := ListFilter{Query: prefix, Match: MatchPrefix, InStock: &inStock, StockLTE: &lte}
+	listed, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2.00"}, prices(listed))
+
+	total, err := repository.Count(context.Background(), filter)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
+
+func TestRepositoryIntegration_SearchDescription(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	marker := "desc-search-" + uuid.NewString()
+	description := "Funda de cuero " + marker
+	seedItems(t, repository,
+		CreateItemInput{Name: marker + "-name", Price: "1.00", Stock: 1},
+		CreateItemInput{Name: "funda-" + uuid.NewString(), Description: &description, Price: "2.00", Stock: 1},
+		// Sin descripción: no tiene que romper el OR ni aparecer por description.
+		CreateItemInput{Name: "otro-" + uuid.NewString(), Price: "3.00", Stock: 1},
+	)
+
+	byName := ListFilter{Query: marker, Match: MatchContains}
+	listed, err := repository.List(context.Background(), byName, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	both := ListFilter{Query: marker, Match: MatchContains, SearchFields: []string{"name", "description"}}
+	listed, err = repository.List(context.Background(), both, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	total, err := repository.Count(context.Background(), both)
+	require.NoError(t, err)
+	require.Equal(t, len(listed), total)
+}
+
+func TestRepositoryIntegration_Fuzzy(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	suffix := uuid.NewString()[:8]
+	seedItems(t, repository,
+		CreateItemInput{Name: "keyboard " + suffix, Price: "10.00", Stock: 1},
+		CreateItemInput{Name: "monitor " + suffix, Price: "20.00", Stock: 1},
+	)
+
+	filter := ListFilter{Query: "keybord " + suffix, Fuzzy: true, FuzzyThreshold: 0.3}
+	listed, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, listed)
+	require.Equal(t, "keyboard "+suffix, listed[0].Name)
+	require.NotNil(t, listed[0].Similarity)
+	require.Greater(t, *listed[0].Similarity, 0.3)
+
+	total, err := repository.Count(context.Background(), filter)
+	require.NoError(t, err)
+	require.Equal(t, len(listed), total)
+}
+
+func TestRepositoryIntegration_Suggest(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "sg" + uuid.NewString()[:8]
+	seedItems(t, repository,
+		CreateItemInput{Name: prefix + " phone", Price: "10.00", Stock: 1},
+		CreateItemInput{Name: prefix + " case", Price: "5.00", Stock: 1},
+		CreateItemInput{Name: prefix + " draft", Price: "5.00", Stock: 1, State: StateDraft},
+		CreateItemInput{Name: "cover " + prefix, Price: "5.00", Stock: 1},
+	)
+
+	suggestions, err := repository.Suggest(context.Background(), prefix, 0.3, 10)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(suggestions), 3)
+	// Los que empiezan con el prefijo van primero; el borrador no se sugiere.
+	require.ElementsMatch(t, []string{prefix + " case", prefix + " phone"}, []string{suggestions[0].Name, suggestions[1].Name})
+	require.Equal(t, "cover "+prefix, suggestions[2].Name)
+	for _, suggestion := range suggestions {
+		require.NotEqual(t, prefix+" draft", suggestion.Name)
+	}
+}
+
+func TestRepositoryIntegration_ClosestName(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	suffix := uuid.NewString()[:8]
+	seedItems(t, repository, CreateItemInput{Name: "keyboard " + suffix, Price: "10.00", Stock: 1})
+
+	name, err := repository.ClosestName(context.Background(), "keybord "+suffix, 0.4)
+	require.NoError(t, err)
+	require.Equal(t, "keyboard "+suffix, name)
+
+	name, err = repository.ClosestName(context.Background(), "zzzzzzzz "+uuid.NewString(), 0.9)
+	require.NoError(t, err)
+	require.Empty(t, name)
+}
+
+func TestRepositoryIntegration_NameEq(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	name := "Phone X " + uuid.NewString()
+	seedItems(t, repository,
+		CreateItemInput{Name: name, Price: "10.00", Stock: 1},
+		CreateItemInput{Name: name + " Pro", Price: "20.00", Stock: 1},
+	)
+
+	exact := ListFilter{NameEq: name}
+	listed, err := repository.List(context.Background(), exact, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	total, err := repository.Count(context.Background(), exact)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+
+	upper := strings.ToUpper(name)
+	total, err = repository.Count(context.Background(), ListFilter{NameEq: upper})
+	require.NoError(t, err)
+	require.Equal(t, 0, total)
+
+	total, err = repository.Count(context.Background(), ListFilter{NameEq: upper, NameEqIgnoreCase: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+}
+
+func TestRepositoryIntegration_ListAfterSurvivesInserts(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "cursor-" + uuid.NewString()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: prefix + "-a", Price: "1.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-b", Price: "2.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-c", Price: "3.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-d", Price: "4.00", Stock: 1},
+	)
+
+	filter := ListFilter{Query: prefix, Match: MatchPrefix}
+	first, err := repository.List(context.Background(), filter, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+
+	// Un alta entre páginas corre OFFSET un lugar; el cursor no se entera.
+	seedItems(t, repository, CreateItemInput{Name: prefix + "-e", Price: "5.00", Stock: 1})
+
+	last := first[len(first)-1]
+	second, err := repository.ListAfter(context.Background(), filter, CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID}, 10)
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+	for _, item := range append(first, second...) {
+		require.False(t, seen[item.ID], "item %s listed twice", item.Name)
+		seen[item.ID] = true
+	}
+	for _, item := range seeded {
+		require.True(t, seen[item.ID], "item %s missing", item.Name)
+	}
+	require.Len(t, seen, len(seeded))
+}
+
+func TestRepositoryIntegration_ListAfterKeepsTheIdentifierRanking(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	// El item del SKU buscado es el más viejo: sin el ranking en el keyset, seguir el cursor de la
+	// primera página (donde va adelante) lo repetía y se salteaba las coincidencias por nombre.
+	sku := integrationSKU()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Ranked hit " + uuid.NewString(), SKU: sku, Price: "1.00", Stock: 1},
+		CreateItemInput{Name: "Case for " + *sku + " a", Price: "2.00", Stock: 1},
+		CreateItemInput{Name: "Case for " + *sku + " b", Price: "3.00", Stock: 1},
+	)
+	filter := ListFilter{Query: strings.ToLower(*sku)}
+
+	page, err := service.List(context.Background(), 1, 1, filter)
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total)
+	listed := page.Items
+	for page.Cursor != nil && len(listed) < page.Total {
+		page, err = service.ListAfter(context.Background(), *page.Cursor, 1, filter)
+		require.NoError(t, err)
+		listed = append(listed, page.Items...)
+	}
+
+	ids := make([]string, 0, len(listed))
+	for _, item := range listed {
+		ids = append(ids, item.ID)
+	}
+	require.Equal(t, []string{seeded[0].ID, seeded[2].ID, seeded[1].ID}, ids)
+}
+
+func TestRepositoryIntegration_ListWithTotal(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "with-total-" + uuid.NewString()
+	seedItems(t, repository,
+		CreateItemInput{Name: prefix + "-a", Price: "1.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-b", Price: "2.00", Stock: 1},
+		CreateItemInput{Name: prefix + "-c", Price: "3.00", Stock: 1},
+	)
+	filter := ListFilter{Query: prefix, Match: MatchPrefix}
+
+	listed, total, err := repository.ListWithTotal(context.Background(), filter, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	require.Equal(t, 3, total)
+
+	listed, total, err = repository.ListWithTotal(context.Background(), filter, 2, 10)
+	require.NoError(t, err)
+	require.Empty(t, listed)
+	require.Equal(t, 3, total)
+
+	listed, total, err = repository.ListWithTotal(context.Background(), ListFilter{Query: prefix + "-none", Match: MatchPrefix}, 2, 0)
+	require.NoError(t, err)
+	require.Empty(t, listed)
+	require.Zero(t, total)
+}
+
+// TestRepositoryIntegration_StableOrderWithSameCreatedAt inserta items en una misma transacción
+// (now() es el mismo para todos) y verifica que el desempate por id mantiene el orden entre páginas.
+func TestRepositoryIntegration_StableOrderWithSameCreatedAt(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "same-ts-" + uuid.NewString()
+	var created []Item
+	err := repository.InTx(context.Background(), func(tx RepositoryAPI) error {
+		for index := range 5 {
+			item, err := tx.Insert(context.Background(), CreateItemInput{
+				Name: fmt.Sprintf("%s-%d", prefix, index), Slug: fmt.Sprintf("%s-%d", prefix, index), Price: "1.00", Stock: 1,
+			})
+			if err != nil {
+				return err
+			}
+			created = append(created, item)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		for _, item := range created {
+			_, _ = repository.Delete(context.Background(), item.ID, nil)
+			_ = repository.Purge(context.Background(), item.ID)
+		}
+	})
+	for _, item := range created {
+		require.True(t, created[0].CreatedAt.Equal(item.CreatedAt))
+	}
+
+	filter := ListFilter{Query: prefix, Match: MatchPrefix}
+	var paged []string
+	for offset := 0; offset < len(created); offset += 2 {
+		first, err := repository.List(context.Background(), filter, 2, offset)
+		require.NoError(t, err)
+		again, err := repository.List(context.Background(), filter, 2, offset)
+		require.NoError(t, err)
+		require.Equal(t, ids(first), ids(again))
+		paged = append(paged, ids(first)...)
+	}
+
+	expected := ids(created)
+	sort.Sort(sort.Reverse(sort.StringSlice(expected)))
+	require.Equal(t, expected, paged)
+
+	// Por cursor el empate se resuelve igual: la tupla (created_at, id) compara también el id.
+	firstPage, err := repository.List(context.Background(), filter, 2, 0)
+	require.NoError(t, err)
+	last := firstPage[len(firstPage)-1]
+	rest, err := repository.ListAfter(context.Background(), filter, CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID}, 10)
+	require.NoError(t, err)
+	require.Equal(t, expected[2:], ids(rest))
+}
+
+// TestRepositoryIntegration_ListAfterSkipsNoRows compara los planes de OFFSET y keyset
+// para la misma página: OFFSET lee todas las filas anteriores, ListAfter solo las de la página.
+func TestRepositoryIntegration_ListAfterSkipsNoRows(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	prefix := "keyset-plan-" + uuid.NewString()
+	inputs := make([]CreateItemInput, 0, 60)
+	for index := range 60 {
+		inputs = append(inputs, CreateItemInput{Name: fmt.Sprintf("%s-%02d", prefix, index), Price: "1.00", Stock: 1})
+	}
+	seedItems(t, repository, inputs...)
+
+	const offset, limit = 30, 10
+	previous, err := repository.List(context.Background(), ListFilter{}, offset, 0)
+	require.NoError(t, err)
+	require.Len(t, previous, offset)
+	last := previous[len(previous)-1]
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+	// Con pocas filas el planner prefiere un seq scan; lo apagamos para ver el plan que usaría una tabla grande.
+	_, err = tx.Exec(ctx, "SET LOCAL enable_seqscan = off")
+	require.NoError(t, err)
+
+	database := &capturingQuerier{dbQuerier: tx}
+	captured := NewRepository(database)
+
+	_, err = captured.List(ctx, ListFilter{}, limit, offset)
+	require.NoError(t, err)
+	offsetRows := scannedRows(t, tx, database.query, database.args)
+
+	page, err := captured.ListAfter(ctx, ListFilter{}, CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID}, limit)
+	require.NoError(t, err)
+	require.Len(t, page, limit)
+	keysetRows := scannedRows(t, tx, database.query, database.args)
+
+	require.GreaterOrEqual(t, offsetRows, offset+limit)
+	require.LessOrEqual(t, keysetRows, limit)
+}
+
+// capturingQuerier registra la última query para poder correr EXPLAIN sobre el SQL real del repositorio.
+type capturingQuerier struct {
+	dbQuerier
+	query string
+	args  []any
+}
+
+func (database *capturingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	database.query, database.args = sql, args
+	return database.dbQuerier.Query(ctx, sql, args...)
+}
+
+// scannedRows ejecuta EXPLAIN ANALYZE y devuelve cuántas filas leyó el nodo de scan (la hoja del plan).
+func scannedRows(t *testing.T, tx pgx.Tx, query string, args []any) int {
+	t.Helper()
+
+	var raw string
+	require.NoError(t, tx.QueryRow(context.Background(), "EXPLAIN (ANALYZE, FORMAT JSON) "+query, args...).Scan(&raw))
+
+	type planNode struct {
+		NodeType   string     `json:"Node Type"`
+		ActualRows int        `json:"Actual Rows"`
+		Plans      []planNode `json:"Plans"`
+	}
+	var explained []struct {
+		Plan planNode `json:"Plan"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(raw), &explained))
+	require.NotEmpty(t, explained)
+
+	node := explained[0].Plan
+	for len(node.Plans) > 0 {
+		node = node.Plans[0]
+	}
+	require.Contains(t, node.NodeType, "Index", "expected an index scan, got %s", node.NodeType)
+	return node.ActualRows
+}
+
+func TestRepositoryIntegration_Slug(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Slug Keyboard " + uuid.NewString()
+	first, err := service.Create(context.Background(), CreateItemInput{Name: name, SKU: integrationSKU(), Price: "10.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = repository.Delete(context.Background(), first.ID, nil) })
+	require.Equal(t, slugify(name), first.Slug)
+
+	// Otro nombre que produce el mismo slug recibe el sufijo -2.
+	second, err := service.Create(context.Background(), CreateItemInput{Name: name + "!", SKU: integrationSKU(), Price: "10.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = repository.Delete(context.Background(), second.ID, nil) })
+	require.Equal(t, slugify(name)+"-2", second.Slug)
+
+	found, err := service.GetBySlug(context.Background(), second.Slug)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, found.ID)
+
+	_, err = service.Update(context.Background(), second.ID, UpdateItemInput{Slug: &first.Slug})
+	require.ErrorIs(t, err, ErrorDuplicateSlug)
+
+	// Renombrar regenera el slug sin contar el propio como tomado.
+	renamed, err := service.Update(context.Background(), first.ID, UpdateItemInput{Name: stringPointer(name + " v2")})
+	require.NoError(t, err)
+	require.Equal(t, slugify(name+" v2"), renamed.Slug)
+}
+
+func TestRepositoryIntegration_SKU(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	sku := "IT-" + strings.ToUpper(uuid.NewString()[:8])
+	created, err := service.Create(context.Background(), CreateItemInput{Name: "SKU Keyboard " + uuid.NewString(), SKU: &sku, Price: "10.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = repository.Delete(context.Background(), created.ID, nil) })
+	require.Equal(t, sku, *created.SKU)
+
+	found, err := service.GetBySKU(context.Background(), sku)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+
+	_, err = service.Create(context.Background(), CreateItemInput{Name: "SKU Mouse " + uuid.NewString(), SKU: &sku, Price: "10.00", Stock: 1})
+	require.ErrorIs(t, err, ErrorDuplicateSKU)
+
+	_, err = service.GetBySKU(context.Background(), "IT-MISSING-"+strings.ToUpper(uuid.NewString()[:8]))
+	require.ErrorIs(t, err, ErrorNotFound)
+
+	// PATCH cambia el SKU normalizado y ?sku= lo encuentra; otro item no puede tomarlo.
+	renamed := strings.ToLower(*integrationSKU())
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{SKU: &renamed})
+	require.NoError(t, err)
+	require.Equal(t, strings.ToUpper(renamed), *updated.SKU)
+
+	page, err := service.List(context.Background(), 1, 20, ListFilter{SKU: renamed})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, created.ID, page.Items[0].ID)
+
+	other, err := service.Create(context.Background(), CreateItemInput{Name: "SKU Mouse " + uuid.NewString(), SKU: integrationSKU(), Price: "10.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = repository.Delete(context.Background(), other.ID, nil) })
+	_, err = service.Update(context.Background(), other.ID, UpdateItemInput{SKU: &renamed})
+	require.ErrorIs(t, err, ErrorDuplicateSKU)
+}
+
+// integrationSKU devuelve un SKU único para los items creados con el service, que lo exige.
+func integrationSKU() *string {
+	sku := "IT-" + strings.ToUpper(uuid.NewString()[:8])
+	return &sku
+}
+
+func TestRepositoryIntegration_CollectionVersion(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	before, err := repository.CollectionVersion(context.Background())
+	require.NoError(t, err)
+
+	created, err := repository.Insert(context.Background(), CreateItemInput{Name: "version-" + uuid.NewString(), Slug: "version-" + uuid.NewString(), Price: "1.00", Stock: 1})
+	require.NoError(t, err)
+	afterInsert, err := repository.CollectionVersion(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, before, afterInsert)
+
+	// El borrado lógico actualiza updated_at.
+	_, err = repository.Delete(context.Background(), created.ID, nil)
+	require.NoError(t, err)
+	afterDelete, err := repository.CollectionVersion(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, afterInsert, afterDelete)
+
+	// La purga no deja un updated_at nuevo; la detecta el count.
+	require.NoError(t, repository.Purge(context.Background(), created.ID))
+	afterPurge, err := repository.CollectionVersion(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, afterDelete, afterPurge)
+}
+
+func TestRepositoryIntegration_Replace(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	description := "original"
+	name := "Replace Phone " + uuid.NewString()
+	created := seedItems(t, repository, CreateItemInput{Name: name, Description: &description, Price: "10.00", Stock: 4})[0]
+
+	replaced, err := service.Replace(context.Background(), created.ID, ReplaceItemInput{Name: name, Price: "12.00"})
+	require.NoError(t, err)
+	require.Nil(t, replaced.Description)
+	require.Equal(t, "12.00", replaced.Price)
+	require.Zero(t, replaced.Stock)
+	require.Equal(t, created.Slug, replaced.Slug)
+	require.True(t, replaced.UpdatedAt.After(created.UpdatedAt))
+
+	_, err = service.Replace(context.Background(), uuid.NewString(), ReplaceItemInput{Name: name + " x", Price: "1.00"})
+	require.ErrorIs(t, err, ErrorNotFound)
+}
+
+func TestRepositoryIntegration_Version(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	created := seedItems(t, repository, CreateItemInput{Name: "Versioned Phone " + uuid.NewString(), Price: "10.00", Stock: 1})[0]
+	require.Equal(t, 1, created.Version)
+
+	stock := 2
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{Stock: &stock, IfVersion: &created.Version})
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.Version)
+
+	// Un segundo update con la versión vieja no se aplica.
+	stock = 3
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Stock: &stock, IfVersion: &created.Version})
+	require.ErrorIs(t, err, ErrorVersionMismatch)
+	_, err = service.Delete(context.Background(), created.ID, &created.Version)
+	require.ErrorIs(t, err, ErrorVersionMismatch)
+
+	current, err := repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, current.Stock)
+
+	deleted, err := service.Delete(context.Background(), created.ID, &updated.Version)
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+	_, err = service.Delete(context.Background(), created.ID, &updated.Version)
+	require.ErrorIs(t, err, ErrorNotFound)
+}
+
+func TestRepositoryIntegration_Duplicate(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Duplicate Phone " + uuid.NewString()
+	source := seedItems(t, repository, CreateItemInput{Name: name, Price: "10.00", Stock: 4})[0]
+
+	first, err := service.Duplicate(context.Background(), source.ID, DuplicateItemInput{SKU: *integrationSKU()})
+	require.NoError(t, err)
+	require.Equal(t, name+" (copy)", first.Name)
+	require.Zero(t, first.Stock)
+	require.NotEqual(t, source.Slug, first.Slug)
+
+	second, err := service.Duplicate(context.Background(), source.ID, DuplicateItemInput{SKU: *integrationSKU(), CopyStock: true})
+	require.NoError(t, err)
+	require.Equal(t, name+" (copy 2)", second.Name)
+	require.Equal(t, 4, second.Stock)
+}
+
+func TestRepositoryIntegration_UpdateMany(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	suffix := uuid.NewString()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Bulk Update A " + suffix, Price: "1.00", Stock: 1},
+		CreateItemInput{Name: "Bulk Update B " + suffix, Price: "2.00", Stock: 2},
+	)
+	price := "5.00"
+	stock := 9
+
+	results, err := service.UpdateMany(context.Background(), []BulkUpdateEntry{
+		{ID: seeded[0].ID, Input: UpdateItemInput{Price: &price}},
+		{ID: seeded[1].ID, Input: UpdateItemInput{Stock: &stock}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "5.00", results[0].Item.Price)
+	require.Equal(t, 9, results[1].Item.Stock)
+
+	// Un ID inexistente revierte también el cambio del primero.
+	otherPrice := "7.00"
+	results, err = service.UpdateMany(context.Background(), []BulkUpdateEntry{
+		{ID: seeded[0].ID, Input: UpdateItemInput{Price: &otherPrice}},
+		{ID: uuid.NewString(), Input: UpdateItemInput{Stock: &stock}},
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, results[0].Err, ErrorNotApplied)
+	require.ErrorIs(t, results[1].Err, ErrorNotFound)
+
+	current, err := repository.GetByID(context.Background(), seeded[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "5.00", current.Price)
+}
+
+func TestRepositoryIntegration_DeleteMany(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	suffix := uuid.NewString()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Bulk Delete A " + suffix, Price: "1.00"},
+		CreateItemInput{Name: "Bulk Delete B " + suffix, Price: "2.00"},
+	)
+	missing := uuid.NewString()
+
+	result, err := service.DeleteMany(context.Background(), []string{seeded[0].ID, missing, seeded[1].ID})
+	require.NoError(t, err)
+	require.Equal(t, BulkDeleteResult{Deleted: 2, Missing: []string{missing}}, result)
+
+	_, err = repository.GetByID(context.Background(), seeded[0].ID)
+	require.ErrorIs(t, err, ErrorNotFound)
+}
+
+func TestRepositoryIntegration_SoftDelete(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Soft Deleted Phone " + uuid.NewString()
+	created := seedItems(t, repository, CreateItemInput{Name: name, Price: "10.00", Stock: 1})[0]
+
+	_, err := service.Delete(context.Background(), created.ID, nil)
+	require.NoError(t, err)
+	_, err = service.Delete(context.Background(), created.ID, nil)
+	require.ErrorIs(t, err, ErrorNotFound)
+
+	_, err = service.Get(context.Background(), created.ID)
+	require.ErrorIs(t, err, ErrorNotFound)
+	total, err := repository.Count(context.Background(), ListFilter{NameEq: name})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	// La fila sigue en la tabla.
+	var deletedAt *time.Time
+	require.NoError(t, pool.QueryRow(context.Background(), `SELECT deleted_at FROM items WHERE id = $1`, created.ID).Scan(&deletedAt))
+	require.NotNil(t, deletedAt)
+
+	trash, err := repository.List(context.Background(), ListFilter{NameEq: name, Scope: ScopeDeleted}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.NotNil(t, trash[0].DeletedAt)
+
+	// El nombre queda libre; el slug no, porque el borrado se puede restaurar.
+	reused, err := service.Create(context.Background(), CreateItemInput{Name: name, SKU: integrationSKU(), Price: "12.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = repository.Delete(context.Background(), reused.ID, nil) })
+	require.NotEqual(t, created.Slug, reused.Slug)
+}
+
+func TestRepositoryIntegration_Purge(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Purge Recent " + uuid.NewString(), Price: "1.00"},
+		CreateItemInput{Name: "Purge Old " + uuid.NewString(), Price: "1.00"},
+		CreateItemInput{Name: "Purge Alive " + uuid.NewString(), Price: "1.00"},
+	)
+	recent, old, alive := seeded[0], seeded[1], seeded[2]
+
+	// Un item vivo no se purga.
+	require.ErrorIs(t, repository.Purge(context.Background(), alive.ID), ErrorNotFound)
+
+	_, err := repository.Delete(context.Background(), recent.ID, nil)
+	require.NoError(t, err)
+	_, err = repository.Delete(context.Background(), old.ID, nil)
+	require.NoError(t, err)
+	_, err = pool.Exec(context.Background(), `UPDATE items SET deleted_at = now() - interval '40 days' WHERE id = $1`, old.ID)
+	require.NoError(t, err)
+
+	purged, err := repository.PurgeDeletedBefore(context.Background(), time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, 1)
+
+	var exists bool
+	require.NoError(t, pool.QueryRow(context.Background(), `SELECT EXISTS (SELECT 1 FROM items WHERE id = $1)`, old.ID).Scan(&exists))
+	require.False(t, exists)
+
+	require.NoError(t, repository.Purge(context.Background(), recent.ID))
+	require.ErrorIs(t, repository.Purge(context.Background(), recent.ID), ErrorNotFound)
+}
+
+func TestRepositoryIntegration_Reservations(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	item := seedItems(t, repository, CreateItemInput{Name: "Reserve Lamp " + uuid.NewString(), Price: "10.00", Stock: 3})[0]
+	require.Equal(t, 3, item.Available)
+
+	reservation, err := service.Reserve(context.Background(), item.ID, ReserveInput{Quantity: 2})
+	require.NoError(t, err)
+	require.Equal(t, item.ID, reservation.ItemID)
+
+	got, err := repository.GetByID(context.Background(), item.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, got.Stock)
+	require.Equal(t, 1, got.Available)
+	require.Equal(t, item.Version+1, got.Version, "reserving changes available, so the version goes up")
+
+	_, err = service.Reserve(context.Background(), item.ID, ReserveInput{Quantity: 2})
+	require.ErrorIs(t, err, ErrorInsufficientStock)
+
+	// Una reserva vencida deja de contar aunque todavía no la haya borrado el job.
+	_, err = pool.Exec(context.Background(), `UPDATE item_reservations SET expires_at = now() - interval '1 second' WHERE id = $1`, reservation.ID)
+	require.NoError(t, err)
+	got, err = repository.GetByID(context.Background(), item.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, got.Available)
+
+	deleted, err := service.ExpireReservations(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, 1)
+	require.ErrorIs(t, service.Release(context.Background(), item.ID, reservation.ID), ErrorNotFound)
+
+	active, err := service.Reserve(context.Background(), item.ID, ReserveInput{Quantity: 3})
+	require.NoError(t, err)
+	reserved, err := repository.GetByID(context.Background(), item.ID)
+	require.NoError(t, err)
+	require.NoError(t, service.Release(context.Background(), item.ID, active.ID))
+	released, err := repository.GetByID(context.Background(), item.ID)
+	require.NoError(t, err)
+	require.Equal(t, reserved.Version+1, released.Version)
+}
+
+func TestRepositoryIntegration_ConcurrentReservationsOfTheLastUnit(t *testing.T) {
+	pool := newIntegrationPool(t)
+	service := NewService(NewRepository(pool))
+
+	item := seedItems(t, NewRepository(pool), CreateItemInput{Name: "Last Unit " + uuid.NewString(), Price: "10.00", Stock: 1})[0]
+
+	const attempts = 10
+	results := make(chan error, attempts)
+	start := make(chan struct{})
+	for range attempts {
+		go func() {
+			<-start
+			_, err := service.Reserve(context.Background(), item.ID, ReserveInput{Quantity: 1})
+			results <- err
+		}()
+	}
+	close(start)
+
+	succeeded := 0
+	for range attempts {
+		err := <-results
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrorInsufficientStock)
+	}
+	require.Equal(t, 1, succeeded)
+
+	var reserved int
+	require.NoError(t, pool.QueryRow(context.Background(), `SELECT coalesce(sum(quantity), 0) FROM item_reservations WHERE item_id = $1`, item.ID).Scan(&reserved))
+	require.Equal(t, 1, reserved)
+}
+
+func TestRepositoryIntegration_PriceHistory(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	created, err := service.Create(context.Background(), CreateItemInput{Name: "Price Box " + uuid.NewString(), SKU: integrationSKU(), Price: "5.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	price, samePrice := "7.5", "7.50"
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Price: &price})
+	require.NoError(t, err)
+	// Ni un PATCH sin precio ni uno con el mismo precio en otro formato agregan filas.
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Stock: &[]int{4}[0]})
+	require.NoError(t, err)
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Price: &samePrice})
+	require.NoError(t, err)
+
+	page, err := service.PriceHistory(context.Background(), created.ID, PriceHistoryFilter{}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, "7.50", page.Entries[0].NewPrice)
+	require.Equal(t, "5.00", *page.Entries[0].OldPrice)
+	require.Equal(t, StockReasonUpdate, page.Entries[0].Reason)
+	require.Nil(t, page.Entries[1].OldPrice)
+	require.Equal(t, StockReasonCreate, page.Entries[1].Reason)
+
+	future := time.Now().Add(time.Hour)
+	page, err = service.PriceHistory(context.Background(), created.ID, PriceHistoryFilter{From: &future}, 1, 10)
+	require.NoError(t, err)
+	require.Zero(t, page.Total)
+}
+
+func TestRepositoryIntegration_Audit(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-audit")
+
+	created, err := service.Create(ctx, CreateItemInput{Name: "Audit Box " + uuid.NewString(), SKU: integrationSKU(), Price: "5.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	price := "7.5"
+	_, err = service.Update(ctx, created.ID, UpdateItemInput{Price: &price})
+	require.NoError(t, err)
+	// El mismo precio en otro formato no es un cambio.
+	samePrice := "7.50"
+	_, err = service.Update(ctx, created.ID, UpdateItemInput{Price: &samePrice})
+	require.NoError(t, err)
+
+	page, err := service.Audit(context.Background(), created.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, AuditUpdated, page.Entries[0].Action)
+	require.Equal(t, "req-audit", page.Entries[0].RequestID)
+	require.Len(t, page.Entries[0].Changes, 1)
+	require.Equal(t, "price", page.Entries[0].Changes[0].Field)
+	require.JSONEq(t, `"5.00"`, string(page.Entries[0].Changes[0].Old))
+	require.JSONEq(t, `"7.50"`, string(page.Entries[0].Changes[0].New))
+	require.Equal(t, AuditCreated, page.Entries[1].Action)
+}
+
+func TestRepositoryIntegration_PriceAdjustment(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	suffix := uuid.NewString()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Adjust case " + suffix, Price: "10.00", Currency: "USD", Stock: 1},
+		CreateItemInput{Name: "Adjust phone " + suffix, Price: "20.00", SalePrice: stringPointer("19.00"), Currency: "USD", Stock: 1},
+	)
+	filter := PriceAdjustmentFilter{Query: suffix, Currency: "USD"}
+
+	// -5% deja el teléfono en 19.00, igual que la oferta: se rechaza entero.
+	_, err := service.AdjustPrices(context.Background(), PriceAdjustmentInput{Filter: filter, Adjustment: PriceAdjustment{Type: AdjustmentPercent, Value: "-5"}})
+	require.ErrorIs(t, err, ErrorAdjustedBelowSalePrice)
+	_, err = service.AdjustPrices(context.Background(), PriceAdjustmentInput{Filter: filter, Adjustment: PriceAdjustment{Type: AdjustmentFixed, Value: "-10"}})
+	require.ErrorIs(t, err, ErrorAdjustedPriceNotPositive)
+
+	dryRun, err := service.AdjustPrices(context.Background(), PriceAdjustmentInput{Filter: filter, Adjustment: PriceAdjustment{Type: AdjustmentPercent, Value: "2.5"}, DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, dryRun.Affected)
+	require.Equal(t, "10.25", dryRun.Sample[0].NewPrice)
+	unchanged, err := service.Get(context.Background(), seeded[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "10.00", unchanged.Price)
+
+	result, err := service.AdjustPrices(context.Background(), PriceAdjustmentInput{Filter: filter, Adjustment: PriceAdjustment{Type: AdjustmentPercent, Value: "2.5"}})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Affected)
+
+	adjusted, err := service.Get(context.Background(), seeded[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, "20.50", adjusted.Price)
+	require.Equal(t, seeded[1].Version+1, adjusted.Version)
+	history, err := service.PriceHistory(context.Background(), seeded[1].ID, PriceHistoryFilter{}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, PriceReasonBulkAdjustment, history.Entries[0].Reason)
+	require.Equal(t, "20.00", *history.Entries[0].OldPrice)
+}
+
+func TestRepositoryIntegration_StockLedger(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	created, err := service.Create(context.Background(), CreateItemInput{Name: "Ledger Box " + uuid.NewString(), SKU: integrationSKU(), Price: "5.00", Stock: 50})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Stock: &[]int{10}[0]})
+	require.NoError(t, err)
+	adjusted, err := service.AdjustStock(context.Background(), created.ID, StockAdjustmentInput{Delta: -7, Reason: "damaged"})
+	require.NoError(t, err)
+	require.Equal(t, 3, adjusted.Stock)
+
+	// Un ajuste que dejaría el stock negativo no toca ni el item ni el historial.
+	_, err = service.AdjustStock(context.Background(), created.ID, StockAdjustmentInput{Delta: -4})
+	require.ErrorIs(t, err, ErrorInvalidStock)
+
+	page, err := service.StockMovements(context.Background(), created.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, page.Total)
+	require.Len(t, page.Movements, 3)
+	deltas := make([]int, 0, len(page.Movements))
+	for _, movement := range page.Movements {
+		deltas = append(deltas, movement.Delta)
+	}
+	require.Equal(t, []int{-7, -40, 50}, deltas)
+	require.Equal(t, "damaged", page.Movements[0].Reason)
+	require.Equal(t, 3, page.Movements[0].ResultingStock)
+}
+
+func TestRepositoryIntegration_Backorder(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository, WithBackorderFloor(-20))
+
+	created, err := service.Create(context.Background(), CreateItemInput{Name: "Backorder Box " + uuid.NewString(), SKU: integrationSKU(), Price: "5.00", Stock: 2, AllowBackorder: true})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	adjusted, err := service.AdjustStock(context.Background(), created.ID, StockAdjustmentInput{Delta: -12})
+	require.NoError(t, err)
+	require.Equal(t, -10, adjusted.Stock)
+	require.True(t, adjusted.Backordered)
+
+	_, err = service.AdjustStock(context.Background(), created.ID, StockAdjustmentInput{Delta: -11})
+	require.ErrorIs(t, err, ErrorStockBelowFloor)
+
+	// La constraint de la base impide apagar el flag mientras el stock es negativo.
+	disallow := false
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{AllowBackorder: &disallow})
+	require.ErrorIs(t, err, ErrorInvalidStock)
+
+	restocked, err := service.Update(context.Background(), created.ID, UpdateItemInput{Stock: &[]int{4}[0], AllowBackorder: &disallow})
+	require.NoError(t, err)
+	require.False(t, restocked.Backordered)
+	require.False(t, restocked.AllowBackorder)
+}
+
+// integrationBarcode arma un EAN-13 al azar con dígito verificador válido, para no chocar con
+// ux_items_barcode entre corridas.
+func integrationBarcode() string {
+	digits := strings.Map(func(character rune) rune {
+		return '0' + character%10
+	}, uuid.NewString()[:12])
+	for check := '0'; check <= '9'; check++ {
+		if barcodeError(digits+string(check)) == nil {
+			return digits + string(check)
+		}
+	}
+	panic("unreachable")
+}
+
+func TestRepositoryIntegration_Barcode(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	barcode := integrationBarcode()
+	created, err := service.Create(context.Background(), CreateItemInput{Name: "Barcode Box " + uuid.NewString(), SKU: integrationSKU(), Barcode: &barcode, Price: "5.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, barcode, *created.Barcode)
+
+	found, err := service.GetByBarcode(context.Background(), barcode)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+
+	_, err = service.Create(context.Background(), CreateItemInput{Name: "Barcode Copy " + uuid.NewString(), SKU: integrationSKU(), Barcode: &barcode, Price: "5.00", Stock: 1})
+	require.ErrorIs(t, err, ErrorDuplicateBarcode)
+
+	cleared, err := service.Update(context.Background(), created.ID, UpdateItemInput{BarcodePresent: true})
+	require.NoError(t, err)
+	require.Nil(t, cleared.Barcode)
+
+	_, err = service.GetByBarcode(context.Background(), barcode)
+	require.ErrorIs(t, err, ErrorNotFound)
+}
+
+func TestRepositoryIntegration_Status(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Status Box " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{Name: name, SKU: integrationSKU(), Price: "5.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, StatusActive, created.Status)
+
+	inactive := StatusInactive
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{Status: &inactive})
+	require.NoError(t, err)
+	require.Equal(t, StatusInactive, updated.Status)
+
+	// Sigue editable: el estado no bloquea cambios de precio o stock.
+	price := "6.00"
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Price: &price})
+	require.NoError(t, err)
+
+	active, err := service.Count(context.Background(), ListFilter{NameEq: name, Status: StatusActive})
+	require.NoError(t, err)
+	require.Equal(t, 0, active.Total)
+	all, err := service.Count(context.Background(), ListFilter{NameEq: name, Status: StatusAll})
+	require.NoError(t, err)
+	require.Equal(t, 1, all.Total)
+}
+
+func TestRepositoryIntegration_State(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "State Box " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{Name: name, SKU: integrationSKU(), Price: "5.00", Stock: 1, State: StateDraft})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, StateDraft, created.State)
+
+	// El borrador se lee por id pero no por slug ni en el listado de publicados.
+	_, err = service.Get(context.Background(), created.ID)
+	require.NoError(t, err)
+	_, err = service.GetBySlug(context.Background(), created.Slug)
+	require.ErrorIs(t, err, ErrorNotFound)
+	published, err := service.Count(context.Background(), ListFilter{NameEq: name, State: StatePublished})
+	require.NoError(t, err)
+	require.Equal(t, 0, published.Total)
+	drafts, err := service.Count(context.Background(), ListFilter{NameEq: name, State: StateDraft})
+	require.NoError(t, err)
+	require.Equal(t, 1, drafts.Total)
+
+	item, err := service.Publish(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatePublished, item.State)
+	bySlug, err := service.GetBySlug(context.Background(), created.Slug)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, bySlug.ID)
+}
+
+func TestRepositoryIntegration_ExternalRefs(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	create := func(name string) Item {
+		created, err := service.Create(context.Background(), CreateItemInput{Name: name + " " + uuid.NewString(), SKU: integrationSKU(), Price: "5.00", State: StateDraft})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = repository.Delete(context.Background(), created.ID, nil)
+			_ = repository.Purge(context.Background(), created.ID)
+		})
+		return created
+	}
+	first, second := create("Ref Box"), create("Other Ref Box")
+	externalID := "A-" + uuid.NewString()
+
+	_, created, err := service.AddExternalRef(context.Background(), first.ID, ExternalRefInput{System: "ERP", ExternalID: externalID})
+	require.NoError(t, err)
+	require.True(t, created)
+	_, created, err = service.AddExternalRef(context.Background(), first.ID, ExternalRefInput{System: "erp", ExternalID: externalID})
+	require.NoError(t, err)
+	require.False(t, created)
+
+	// La misma ref en otro item es un conflicto; el mismo ID en otro sistema no.
+	_, _, err = service.AddExternalRef(context.Background(), second.ID, ExternalRefInput{System: "erp", ExternalID: externalID})
+	var conflictError *ExternalRefConflictError
+	require.ErrorAs(t, err, &conflictError)
+	require.Equal(t, "erp", conflictError.System)
+	_, _, err = service.AddExternalRef(context.Background(), second.ID, ExternalRefInput{System: "marketplace", ExternalID: externalID})
+	require.NoError(t, err)
+
+	// El lookup encuentra también los borradores y el item embebe sus refs.
+	found, err := service.GetByExternalRef(context.Background(), "erp", externalID)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, found.ID)
+	require.Len(t, found.Refs, 1)
+	require.Equal(t, externalID, found.Refs[0].ExternalID)
+	require.Equal(t, first.Version+1, found.Version, "adding a ref bumps the version once, the repeated add does not")
+
+	require.NoError(t, service.RemoveExternalRef(context.Background(), first.ID, "erp", externalID))
+	removed, err := repository.GetByID(context.Background(), first.ID)
+	require.NoError(t, err)
+	require.Equal(t, found.Version+1, removed.Version)
+	_, err = service.GetByExternalRef(context.Background(), "erp", externalID)
+	require.ErrorIs(t, err, ErrorNotFound)
+	err = service.RemoveExternalRef(context.Background(), first.ID, "erp", externalID)
+	require.ErrorIs(t, err, ErrorExternalRefNotFound)
+}
+
+func TestRepositoryIntegration_Translations(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	marker := uuid.NewString()
+	description := "Wireless keyboard"
+	created, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard " + marker, SKU: integrationSKU(), Price: "5.00", Description: &description})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	translation, err := service.PutTranslation(context.Background(), created.ID, "es", TranslationInput{Name: "Teclado " + marker})
+	require.NoError(t, err)
+	require.Equal(t, "es", translation.Locale)
+	// Reemplazar la traducción no agrega otra fila, y guardarla cambia la versión del item.
+	_, err = service.PutTranslation(context.Background(), created.ID, "es", TranslationInput{Name: "Teclado inalámbrico " + marker})
+	require.NoError(t, err)
+	translations, err := service.Translations(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Len(t, translations, 1)
+	current, err := service.Get(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, created.Version+2, current.Version)
+
+	// Sin descripción traducida queda la del item.
+	localized, err := service.Localize(context.Background(), "es", []Item{current})
+	require.NoError(t, err)
+	require.Equal(t, "Teclado inalámbrico "+marker, localized[0].Name)
+	require.Equal(t, description, *localized[0].Description)
+	require.Equal(t, "es", localized[0].Locale)
+
+	// La búsqueda encuentra el item por su nombre traducido solo si se pide.
+	filter := ListFilter{Query: "inalámbrico " + marker}
+	page, err := service.List(context.Background(), 1, 10, filter)
+	require.NoError(t, err)
+	require.Empty(t, page.Items)
+	filter.SearchTranslations = true
+	page, err = service.List(context.Background(), 1, 10, filter)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, created.ID, page.Items[0].ID)
+}
+
+func TestRepositoryIntegration_Currency(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Currency Box " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{Name: name, SKU: integrationSKU(), Price: "1500", Currency: "jpy", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, "JPY", created.Currency)
+
+	price := "1500.50"
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Price: &price})
+	require.ErrorIs(t, err, ErrorInvalidInput)
+
+	euro := "EUR"
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{Currency: &euro, AllowCurrencyChange: true})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", updated.Currency)
+
+	count, err := service.Count(context.Background(), ListFilter{NameEq: name, Currency: "EUR", Status: StatusActive})
+	require.NoError(t, err)
+	require.Equal(t, 1, count.Total)
+}
+
+func TestRepositoryIntegration_Attributes(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Attributes Box " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: name, SKU: integrationSKU(), Price: "5.00", Stock: 1,
+		Attributes: map[string]any{"color": "red", "size": 42, "waterproof": true},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, map[string]any{"color": "red", "size": float64(42), "waterproof": true}, created.Attributes)
+
+	for _, attributes := range []map[string]string{{"color": "red"}, {"size": "42"}, {"waterproof": "true", "color": "red"}} {
+		count, err := service.Count(context.Background(), ListFilter{NameEq: name, Attributes: attributes})
+		require.NoError(t, err)
+		require.Equal(t, 1, count.Total, attributes)
+	}
+	count, err := service.Count(context.Background(), ListFilter{NameEq: name, Attributes: map[string]string{"color": "blue"}})
+	require.NoError(t, err)
+	require.Equal(t, 0, count.Total)
+
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{AttributesPresent: true})
+	require.NoError(t, err)
+	require.Nil(t, updated.Attributes)
+}
+
+func TestRepositoryIntegration_SalePrice(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Sale Mouse " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: name, SKU: integrationSKU(), Price: "10.00", SalePrice: stringPointer("7.99"), Stock: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, "7.99", *created.SalePrice)
+	require.Equal(t, "7.99", created.EffectivePrice)
+
+	count, err := service.Count(context.Background(), ListFilter{NameEq: name, MaxPrice: "8.00"})
+	require.NoError(t, err)
+	require.Equal(t, 0, count.Total)
+	count, err = service.Count(context.Background(), ListFilter{NameEq: name, MaxPrice: "8.00", UseEffectivePrice: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, count.Total)
+
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{Price: stringPointer("7.50")})
+	require.ErrorIs(t, err, ErrorInvalidSalePrice)
+
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{SalePricePresent: true})
+	require.NoError(t, err)
+	require.Nil(t, updated.SalePrice)
+	require.Equal(t, "10.00", updated.EffectivePrice)
+}
+
+func TestRepositoryIntegration_TaxRate(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository, WithDefaultTaxRate(1900))
+
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: "Taxed Mouse " + uuid.NewString(), SKU: integrationSKU(), Price: "0.99", Stock: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, 1900, created.TaxRateBPS)
+	require.Equal(t, "1.18", created.PriceWithTax)
+
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{TaxRateBPS: integerPointer(0)})
+	require.NoError(t, err)
+	require.Equal(t, "0.99", updated.PriceWithTax)
+
+	fetched, err := service.Get(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "0.99", fetched.Price)
+	require.Equal(t, "0.99", fetched.PriceWithTax)
+}
+
+func TestRepositoryIntegration_MinOrderQty(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Bulk Screws " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: name, SKU: integrationSKU(), Price: "0.10", Stock: 500, MinOrderQty: integerPointer(100),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, 100, created.MinOrderQty)
+
+	count, err := service.Count(context.Background(), ListFilter{NameEq: name, MinOrderQtyLTE: integerPointer(1)})
+	require.NoError(t, err)
+	require.Equal(t, 0, count.Total)
+
+	_, err = service.Reserve(context.Background(), created.ID, ReserveInput{Quantity: 99})
+	require.ErrorIs(t, err, ErrorBelowMinOrderQty)
+	reservation, err := service.Reserve(context.Background(), created.ID, ReserveInput{Quantity: 100})
+	require.NoError(t, err)
+	require.Equal(t, 100, reservation.Quantity)
+}
+
+func TestRepositoryIntegration_ExpiresAt(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Fresh Milk " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: name, SKU: integrationSKU(), Price: "1.50", Stock: 10, ExpiresAt: stringPointer("2999-12-31"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, "2999-12-31", *created.ExpiresAt)
+
+	excludeExpired := true
+	count, err := service.Count(context.Background(), ListFilter{NameEq: name, ExpiringBefore: "3000-01-01", ExcludeExpired: &excludeExpired})
+	require.NoError(t, err)
+	require.Equal(t, 1, count.Total)
+
+	_, err = service.Update(context.Background(), created.ID, UpdateItemInput{ExpiresAt: stringPointer("2000-01-01"), ExpiresAtPresent: true})
+	require.NoError(t, err)
+	count, err = service.Count(context.Background(), ListFilter{NameEq: name, ExcludeExpired: &excludeExpired})
+	require.NoError(t, err)
+	require.Equal(t, 0, count.Total)
+
+	fetched, err := service.Get(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "2000-01-01", *fetched.ExpiresAt)
+}
+
+func TestRepositoryIntegration_RestockNeeded(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	suffix := uuid.NewString()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Restock low " + suffix, Price: "1.00", Stock: 5, ReorderPoint: integerPointer(6)},
+		CreateItemInput{Name: "Restock empty " + suffix, Price: "1.00", Stock: 1, ReorderPoint: integerPointer(10)},
+		CreateItemInput{Name: "Restock fine " + suffix, Price: "1.00", Stock: 20, ReorderPoint: integerPointer(5)},
+		CreateItemInput{Name: "Restock untracked " + suffix, Price: "1.00", Stock: 0},
+	)
+
+	page, err := service.RestockNeeded(context.Background(), 1, 1000)
+	require.NoError(t, err)
+
+	// La base es compartida: solo importan los items de este test y su orden relativo.
+	var mine []Item
+	for _, item := range page.Items {
+		if strings.HasSuffix(item.Name, suffix) {
+			mine = append(mine, item)
+		}
+	}
+	require.Len(t, mine, 2)
+	require.Equal(t, seeded[1].ID, mine[0].ID)
+	require.Equal(t, 19, *mine[0].SuggestedOrderQty)
+	require.Equal(t, seeded[0].ID, mine[1].ID)
+	require.Equal(t, 7, *mine[1].SuggestedOrderQty)
+
+	_, err = service.Update(context.Background(), seeded[1].ID, UpdateItemInput{ReorderPointPresent: true})
+	require.NoError(t, err)
+	fetched, err := service.Get(context.Background(), seeded[1].ID)
+	require.NoError(t, err)
+	require.Nil(t, fetched.ReorderPoint)
+}
+
+func TestRepositoryIntegration_Changes(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	// Con el reloj adelantado el margen de changesSettleWindow no deja afuera lo recién escrito.
+	service.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	suffix := uuid.NewString()
+	seeded := seedItems(t, repository,
+		CreateItemInput{Name: "Changes first " + suffix, Price: "1.00", Stock: 1},
+		CreateItemInput{Name: "Changes deleted " + suffix, Price: "1.00", Stock: 1},
+		CreateItemInput{Name: "Changes last " + suffix, Price: "1.00", Stock: 1},
+	)
+	_, err := service.Delete(context.Background(), seeded[1].ID, nil)
+	require.NoError(t, err)
+
+	// La base es compartida: recorremos el feed en tandas chicas siguiendo next y solo miramos lo
+	// de este test. Cada item tiene que salir una sola vez.
+	after := UpdatedAtID{UpdatedAt: seeded[0].UpdatedAt}
+	var mine []Item
+	for {
+		page, err := service.Changes(context.Background(), after, 2)
+		require.NoError(t, err)
+		for _, item := range page.Items {
+			if strings.HasSuffix(item.Name, suffix) {
+				mine = append(mine, item)
+			}
+		}
+		after = page.Next
+		if !page.HasMore {
+			break
+		}
+	}
+	require.Len(t, mine, 3)
+	require.Equal(t, seeded[0].ID, mine[0].ID)
+	require.Equal(t, seeded[2].ID, mine[1].ID)
+	require.Equal(t, seeded[1].ID, mine[2].ID, "the delete bumps updated_at")
+	require.True(t, mine[2].Deleted)
+	require.NotNil(t, mine[2].DeletedAt)
+	require.False(t, mine[0].Deleted)
+}
+
+func TestRepositoryIntegration_Dimensions(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	name := "Parcel Box " + uuid.NewString()
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: name, SKU: integrationSKU(), Price: "5.00", Stock: 1,
+		WeightGrams: integerPointer(1500), WidthMM: integerPointer(300), HeightMM: integerPointer(200), DepthMM: integerPointer(100),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+	require.Equal(t, 1500, *created.WeightGrams)
+
+	for maxWeight, want := range map[int]int{2000: 1, 1500: 1, 1000: 0} {
+		count, err := service.Count(context.Background(), ListFilter{NameEq: name, MaxWeight: &maxWeight})
+		require.NoError(t, err)
+		require.Equal(t, want, count.Total, maxWeight)
+	}
+
+	updated, err := service.Update(context.Background(), created.ID, UpdateItemInput{WeightGramsPresent: true, DepthMM: integerPointer(150), DepthMMPresent: true})
+	require.NoError(t, err)
+	require.Nil(t, updated.WeightGrams)
+	require.Equal(t, 300, *updated.WidthMM)
+	require.Equal(t, 150, *updated.DepthMM)
+}
+
+func TestRepositoryIntegration_Variants(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: "Variants Tee " + uuid.NewString(), SKU: integrationSKU(), Price: "15.00", Stock: 7,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	small, err := service.CreateVariant(context.Background(), created.ID, CreateVariantInput{SKU: "TEE-S", Stock: 2, Attributes: map[string]string{"size": "S"}})
+	require.NoError(t, err)
+	require.Nil(t, small.Price)
+	require.Equal(t, "15.00", small.EffectivePrice)
+	_, err = service.CreateVariant(context.Background(), created.ID, CreateVariantInput{SKU: "TEE-L", Price: stringPointer("17.50"), Stock: 3})
+	require.NoError(t, err)
+
+	_, err = service.CreateVariant(context.Background(), created.ID, CreateVariantInput{SKU: "TEE-S"})
+	require.ErrorIs(t, err, ErrorDuplicateVariantSKU)
+
+	item, err := repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, 5, item.Stock)
+
+	_, err = service.AdjustStock(context.Background(), created.ID, StockAdjustmentInput{Delta: 1})
+	require.ErrorIs(t, err, ErrorStockManagedByVariants)
+
+	require.NoError(t, service.DeleteVariant(context.Background(), created.ID, small.ID))
+	item, err = repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, item.Stock)
+}
+
+func TestRepositoryIntegration_PriceSchedules(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	// Con el reloj atrasado una hora se puede programar un cambio que ya venció.
+	service.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: "Scheduled " + uuid.NewString(), SKU: integrationSKU(), Price: "10.00", Stock: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	due := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+	later := time.Now().Add(24 * time.Hour).Truncate(time.Microsecond)
+	_, err = service.CreatePriceSchedule(context.Background(), created.ID, CreatePriceScheduleInput{NewPrice: "12.00", EffectiveAt: due})
+	require.NoError(t, err)
+	_, err = service.CreatePriceSchedule(context.Background(), created.ID, CreatePriceScheduleInput{NewPrice: "15.00", EffectiveAt: later})
+	require.NoError(t, err)
+	_, err = service.CreatePriceSchedule(context.Background(), created.ID, CreatePriceScheduleInput{NewPrice: "16.00", EffectiveAt: later})
+	require.ErrorIs(t, err, ErrorDuplicatePriceSchedule)
+
+	item, err := repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "12.00", item.NextPriceChange.Price)
+	require.Equal(t, created.Version+2, item.Version, "each schedule changes next_price_change")
+
+	_, err = service.ApplyDuePriceSchedules(context.Background())
+	require.NoError(t, err)
+
+	item, err = repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "12.00", item.Price)
+	require.Equal(t, "15.00", item.NextPriceChange.Price)
+	require.True(t, later.Equal(item.NextPriceChange.EffectiveAt))
+
+	schedules, err := service.PriceSchedules(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	require.NotNil(t, schedules[0].AppliedAt)
+	require.ErrorIs(t, service.DeletePriceSchedule(context.Background(), created.ID, schedules[0].ID), ErrorPriceScheduleNotFound)
+	require.NoError(t, service.DeletePriceSchedule(context.Background(), created.ID, schedules[1].ID))
+
+	cancelled, err := repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Nil(t, cancelled.NextPriceChange)
+	require.Equal(t, item.Version+1, cancelled.Version)
+}
+
+func TestRepositoryIntegration_PriceScheduleFailsBelowTheSalePrice(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	service.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	created, err := service.Create(context.Background(), CreateItemInput{
+		Name: "Failed schedule " + uuid.NewString(), SKU: integrationSKU(), Price: "20.00", Stock: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repository.Delete(context.Background(), created.ID, nil)
+		_ = repository.Purge(context.Background(), created.ID)
+	})
+
+	_, err = service.CreatePriceSchedule(context.Background(), created.ID, CreatePriceScheduleInput{
+		NewPrice: "12.00", EffectiveAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	// La oferta se carga después de programar el cambio, así que para cuando vence ya no es válido.
+	salePrice := "15.00"
+	_, err = repository.Update(context.Background(), created.ID, UpdateItemInput{SalePrice: &salePrice})
+	require.NoError(t, err)
+
+	applied, err := service.ApplyDuePriceSchedules(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, applied)
+
+	item, err := repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "20.00", item.Price)
+	require.Nil(t, item.NextPriceChange)
+
+	schedules, err := service.PriceSchedules(context.Background(), created.ID)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	require.Nil(t, schedules[0].AppliedAt)
+	require.NotNil(t, schedules[0].FailedAt)
+	require.Equal(t, PriceScheduleFailureBelowSalePrice, schedules[0].FailureReason)
+
+	due, err := repository.DuePriceSchedules(context.Background(), maxDuePriceSchedules)
+	require.NoError(t, err)
+	for _, schedule := range due {
+		require.NotEqual(t, schedules[0].ID, schedule.ID, "a failed schedule leaves the due queue")
+	}
+}
+
+func TestRepositoryIntegration_InTxRollsBack(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+
+	name := "tx-rollback-" + uuid.NewString()
+	created := seedItems(t, repository, CreateItemInput{Name: name, Price: "10.00", Stock: 1})
+
+	rollbackErr := errors.New("rollback")
+	err := repository.InTx(context.Background(), func(tx RepositoryAPI) error {
+		item, err := tx.GetForUpdate(context.Background(), created[0].ID)
+		require.NoError(t, err)
+		_, err = tx.Update(context.Background(), item.ID, UpdateItemInput{Stock: &[]int{99}[0]})
+		require.NoError(t, err)
+		return rollbackErr
+	})
+	require.ErrorIs(t, err, rollbackErr)
+
+	item, err := repository.GetByID(context.Background(), created[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, item.Stock)
+}
+
+func ids(items []Item) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.ID)
+	}
+	return out
+}
+
+func prices(items []Item) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.Price)
+	}
+	return out
+}
+
+func TestRepositoryIntegration_NotifyChanges(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	ctx := context.Background()
+
+	conn, err := pgx.Connect(ctx, os.Getenv("DATABASE_URL"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close(ctx) })
+	_, err = conn.Exec(ctx, "LISTEN "+ChangesChannel)
+	require.NoError(t, err)
+
+	// waitPayload espera el próximo NOTIFY del canal y devuelve su payload decodificado.
+	waitPayload := func() ChangeNotification {
+		t.Helper()
+		waitContext, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		notification, err := conn.WaitForNotification(waitContext)
+		require.NoError(t, err)
+		var payload ChangeNotification
+		require.NoError(t, json.Unmarshal([]byte(notification.Payload), &payload))
+		return payload
+	}
+
+	item := seedItems(t, repository, CreateItemInput{Name: "Notify " + uuid.NewString(), Price: "1.00"})[0]
+
+	// En una transacción el aviso sale con el commit y no con el rollback.
+	rollback := errors.New("rollback")
+	err = repository.InTx(ctx, func(tx RepositoryAPI) error {
+		require.NoError(t, tx.NotifyChanges(ctx, []Event{{ID: "not-committed", Operation: EventUpdated}}))
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+	require.NoError(t, repository.InTx(ctx, func(tx RepositoryAPI) error {
+		return tx.NotifyChanges(ctx, []Event{{ID: item.ID, Operation: EventUpdated}})
+	}))
+	require.Equal(t, ChangeNotification{ID: item.ID, Operation: EventUpdated}, waitPayload())
+
+	_, err = repository.Delete(ctx, item.ID, nil)
+	require.NoError(t, err)
+	require.NoError(t, repository.Purge(ctx, item.ID))
+	require.Equal(t, ChangeNotification{ID: item.ID, Operation: ChangePurged}, waitPayload())
+}
+
+func TestRepositoryIntegration_CopyInsert(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	ctx := context.Background()
+
+	prefix := "copy-" + uuid.NewString()
+	inputs := make([]CreateItemInput, 3)
+	for index := range inputs {
+		sku := fmt.Sprintf("CP-%s-%d", prefix[5:13], index)
+		inputs[index] = CreateItemInput{Name: fmt.Sprintf("%s-%d", prefix, index), SKU: &sku, Price: "12.34", Stock: index}
+	}
+
+	copied, err := service.CreateMany(ctx, inputs)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), copied)
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, `DELETE FROM items WHERE name LIKE $1`, prefix+"%")
+	})
+
+	loaded, err := repository.List(ctx, ListFilter{Query: prefix, Match: MatchPrefix, Sort: []SortKey{"stock"}}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, loaded, 3)
+	require.Equal(t, "12.34", loaded[2].Price)
+	require.Equal(t, 2, loaded[2].Stock)
+
+	_, err = service.CreateMany(ctx, inputs[:1])
+	require.ErrorIs(t, err, ErrorDuplicateName)
+}
+
+func TestRepositoryIntegration_CreateAll(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	ctx := context.Background()
+
+	prefix := "all-" + uuid.NewString()
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, `DELETE FROM items WHERE name LIKE $1`, prefix+"%")
+	})
+	sku := "AL-" + prefix[4:12]
+	inputs := []CreateItemInput{
+		{Name: prefix + "-0", SKU: &sku, Price: "1.00"},
+		{Name: prefix + "-1", SKU: &sku, Price: "1.00"},
+	}
+
+	_, err := service.CreateAll(ctx, inputs)
+	var batchError *BatchError
+	require.ErrorAs(t, err, &batchError)
+	require.Equal(t, 1, batchError.Index)
+	require.ErrorIs(t, err, ErrorDuplicateSKU)
+	_, err = repository.GetBySKU(ctx, sku)
+	require.ErrorIs(t, err, pgx.ErrNoRows, "the first item is rolled back")
+
+	otherSKU := sku + "-2"
+	inputs[1].SKU = &otherSKU
+	created, err := service.CreateAll(ctx, inputs)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	require.Equal(t, slugify(prefix+"-1"), created[1].Slug)
+}
+
+func TestRepositoryIntegration_CheckUniqueKeys(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	ctx := context.Background()
+
+	name := "unique-" + uuid.NewString()
+	sku := "UQ-" + name[7:15]
+	item, err := service.Create(ctx, CreateItemInput{Name: name, SKU: &sku, Price: "1.00"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, `DELETE FROM items WHERE id = $1`, item.ID)
+	})
+
+	otherSKU := sku + "-2"
+	require.ErrorIs(t, repository.CheckUniqueKeys(ctx, CreateItemInput{Name: name, SKU: &otherSKU}), ErrorDuplicateName)
+	require.ErrorIs(t, repository.CheckUniqueKeys(ctx, CreateItemInput{Name: name + "-2", SKU: &sku}), ErrorDuplicateSKU)
+	require.ErrorIs(t, repository.CheckUniqueKeys(ctx, CreateItemInput{Name: name + "-2", Slug: item.Slug, SKU: &otherSKU}), ErrorDuplicateSlug)
+	require.NoError(t, repository.CheckUniqueKeys(ctx, CreateItemInput{Name: name + "-2", SKU: &otherSKU}))
+
+	// Un item borrado libera el nombre pero no el SKU, igual que los índices.
+	_, err = repository.Delete(ctx, item.ID, nil)
+	require.NoError(t, err)
+	require.NoError(t, repository.CheckUniqueKeys(ctx, CreateItemInput{Name: name, SKU: &otherSKU}))
+	require.ErrorIs(t, repository.CheckUniqueKeys(ctx, CreateItemInput{Name: name, SKU: &sku}), ErrorDuplicateSKU)
+}
+
+func TestRepositoryIntegration_UpsertBySKU(t *testing.T) {
+	pool := newIntegrationPool(t)
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	ctx := context.Background()
+
+	name := "upsert-" + uuid.NewString()
+	sku := "UP-" + name[7:15]
+	item, created, err := service.UpsertBySKU(ctx, CreateItemInput{Name: name, SKU: &sku, Price: "10.00", Stock: 2})
+	require.NoError(t, err)
+	require.True(t, created)
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, `DELETE FROM items WHERE id = $1`, item.ID)
+	})
+
+	description := "restocked"
+	updated, created, err := service.UpsertBySKU(ctx, CreateItemInput{Name: "ignored on update", SKU: &sku, Description: &description, Price: "12.50", Stock: 9})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, item.ID, updated.ID)
+	require.Equal(t, name, updated.Name)
+	require.Equal(t, "12.50", updated.Price)
+	require.Equal(t, 9, updated.Stock)
+	require.Equal(t, description, *updated.Description)
+	require.Equal(t, item.Version+1, updated.Version)
+
+	history, total, err := repository.ListPriceHistory(ctx, item.ID, PriceHistoryFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, StockReasonImport, history[0].Reason)
+
+	// El SKU de un item borrado sigue ocupado: no se revive ni se duplica.
+	_, err = repository.Delete(ctx, item.ID, nil)
+	require.NoError(t, err)
+	_, _, err = service.UpsertBySKU(ctx, CreateItemInput{Name: name, SKU: &sku, Price: "1.00"})
+	require.ErrorIs(t, err, ErrorDuplicateSKU)
+}
@@ -2,16 +2,23 @@ package items
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/currency"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
 )
 
 func TestRepository_Insert(t *testing.T) {
@@ -20,10 +27,13 @@ func TestRepository_Insert(t *testing.T) {
 		repository := NewRepository(database)
 
 		description := "High-end phone"
+		taxRate := 1900
 		input := CreateItemInput{
 			Name:        "Phone X",
+			Slug:        "phone-x",
 			Description: &description,
 			Price:       "10.50",
+			TaxRateBPS:  &taxRate,
 			Stock:       3,
 		}
 
@@ -32,15 +42,19 @@ func TestRepository_Insert(t *testing.T) {
 		expected := Item{
 			ID:          "id-1",
 			Name:        input.Name,
+			Slug:        input.Slug,
 			Description: &description,
 			Price:       input.Price,
 			Stock:       input.Stock,
 			CreatedAt:   createdAt,
 			UpdatedAt:   updatedAt,
+			// 10.50 con 19% es 12.495: la mitad redondea hacia arriba.
+			TaxRateBPS:   1900,
+			PriceWithTax: "12.50",
 		}
 
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{values: []any{expected.ID, expected.Name, description, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt}}
+			return &fakeRow{values: []any{expected.ID, expected.Name, expected.Slug, nil, description, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt, expected.Version, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1900, nil, nil, nil, nil, nil, nil}}
 		}
 
 		item, err := repository.Insert(context.Background(), input)
@@ -49,7 +63,8 @@ func TestRepository_Insert(t *testing.T) {
 		require.Equal(t, expected, item)
 		require.True(t, database.queryRowCalled)
 		require.Contains(t, database.lastQuery, "INSERT INTO items")
-		require.Equal(t, []any{input.Name, input.Description, input.Price, input.Stock}, database.lastArgs)
+		require.Contains(t, normalizeSQL(database.lastQuery), "$10, $11, $12::jsonb, $13, $14, $15, $16, $17::numeric, $18, $19, $20::date, $21, $22) RETURNING")
+		require.Equal(t, []any{input.Name, input.Slug, input.SKU, input.Description, input.Price, input.Stock, input.AllowBackorder, input.Barcode, input.CategoryID, input.BrandID, input.Currency, nil, input.WeightGrams, input.WidthMM, input.HeightMM, input.DepthMM, input.SalePrice, input.TaxRateBPS, input.MinOrderQty, input.ExpiresAt, "published", input.ReorderPoint}, database.lastArgs)
 	})
 
 	t.Run("success without description", func(t *testing.T) {
@@ -58,6 +73,7 @@ func TestRepository_Insert(t *testing.T) {
 
 		input := CreateItemInput{
 			Name:        "Keyboard",
+			Slug:        "keyboard",
 			Description: nil,
 			Price:       "20.00",
 			Stock:       5,
@@ -68,6 +84,7 @@ func TestRepository_Insert(t *testing.T) {
 		expected := Item{
 			ID:          "id-2",
 			Name:        input.Name,
+			Slug:        input.Slug,
 			Description: nil,
 			Price:       input.Price,
 			Stock:       input.Stock,
@@ -76,7 +93,7 @@ func TestRepository_Insert(t *testing.T) {
 		}
 
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{values: []any{expected.ID, expected.Name, nil, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt}}
+			return &fakeRow{values: []any{expected.ID, expected.Name, expected.Slug, nil, nil, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt, expected.Version, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
 		}
 
 		item, err := repository.Insert(context.Background(), input)
@@ -84,7 +101,7 @@ func TestRepository_Insert(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, expected, item)
 		require.True(t, database.queryRowCalled)
-		require.Equal(t, []any{input.Name, input.Description, input.Price, input.Stock}, database.lastArgs)
+		require.Equal(t, []any{input.Name, input.Slug, input.SKU, input.Description, input.Price, input.Stock, input.AllowBackorder, input.Barcode, input.CategoryID, input.BrandID, input.Currency, nil, input.WeightGrams, input.WidthMM, input.HeightMM, input.DepthMM, input.SalePrice, input.TaxRateBPS, input.MinOrderQty, input.ExpiresAt, "published", input.ReorderPoint}, database.lastArgs)
 	})
 
 	t.Run("duplicate name returns domain error", func(t *testing.T) {
@@ -105,6 +122,47 @@ func TestRepository_Insert(t *testing.T) {
 		require.True(t, database.queryRowCalled)
 	})
 
+	t.Run("duplicate sku returns domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_items_sku"}}
+		}
+
+		_, err := repository.Insert(context.Background(), CreateItemInput{Name: "Keyboard", Slug: "keyboard", SKU: stringPointer("KB-001"), Price: "15.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+		require.Equal(t, stringPointer("KB-001"), database.lastArgs[2])
+	})
+
+	t.Run("duplicate barcode returns domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_items_barcode"}}
+		}
+
+		_, err := repository.Insert(context.Background(), CreateItemInput{Name: "Keyboard", Slug: "keyboard", Barcode: stringPointer("4006381333931"), Price: "15.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateBarcode)
+		require.Equal(t, stringPointer("4006381333931"), database.lastArgs[7])
+	})
+
+	t.Run("duplicate slug returns domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_items_slug"}}
+		}
+
+		_, err := repository.Insert(context.Background(), CreateItemInput{Name: "Repeated", Slug: "repeated", Price: "15.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateSlug)
+	})
+
 	t.Run("other database errors are returned", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
@@ -134,14 +192,14 @@ func TestRepository_List(t *testing.T) {
 		updatedAt := time.Now().Add(-time.Minute)
 
 		rows := &fakeRows{rows: [][]any{
-			{"id-1", "Phone", "desc", "10.00", 1, createdAt, updatedAt},
-			{"id-2", "Mouse", nil, "5.00", 2, createdAt, updatedAt},
+			{"id-1", "Phone", "phone", nil, "desc", "10.00", 1, createdAt, updatedAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+			{"id-2", "Mouse", "mouse", nil, nil, "5.00", 2, createdAt, updatedAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
 		}}
 		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 			return rows, nil
 		}
 
-		items, err := repository.List(context.Background(), "", 10, 20)
+		items, err := repository.List(context.Background(), ListFilter{}, 10, 20)
 
 		require.NoError(t, err)
 		require.Len(t, items, 2)
@@ -159,14 +217,14 @@ func TestRepository_List(t *testing.T) {
 		createdAt := time.Now().Add(-2 * time.Hour)
 		updatedAt := time.Now().Add(-time.Minute)
 		rows := &fakeRows{rows: [][]any{
-			{"id-3", "Phone", "desc", "12.00", 3, createdAt, updatedAt},
+			{"id-3", "Phone", "phone", nil, "desc", "12.00", 3, createdAt, updatedAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
 		}}
 
 		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 			return rows, nil
 		}
 
-		items, err := repository.List(context.Background(), "phone", 5, 0)
+		items, err := repository.List(context.Background(), ListFilter{Query: "phone"}, 5, 0)
 
 		require.NoError(t, err)
 		require.Len(t, items, 1)
@@ -184,7 +242,7 @@ func TestRepository_List(t *testing.T) {
 			return nil, queryErr
 		}
 
-		items, err := repository.List(context.Background(), "", 1, 0)
+		items, err := repository.List(context.Background(), ListFilter{}, 1, 0)
 
 		require.ErrorIs(t, err, queryErr)
 		require.Nil(t, items)
@@ -194,12 +252,12 @@ func TestRepository_List(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
 
-		rows := &fakeRows{rows: [][]any{{"id", "name", nil, "1.00", 1, time.Now(), time.Now()}}, scanErr: errors.New("scan")}
+		rows := &fakeRows{rows: [][]any{{"id", "name", "name", nil, nil, "1.00", 1, time.Now(), time.Now(), 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}, scanErr: errors.New("scan")}
 		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 			return rows, nil
 		}
 
-		items, err := repository.List(context.Background(), "", 1, 0)
+		items, err := repository.List(context.Background(), ListFilter{}, 1, 0)
 
 		require.Error(t, err)
 		require.Nil(t, items)
@@ -214,7 +272,7 @@ func TestRepository_List(t *testing.T) {
 			return rows, nil
 		}
 
-		items, err := repository.List(context.Background(), "", 1, 0)
+		items, err := repository.List(context.Background(), ListFilter{}, 1, 0)
 
 		require.Error(t, err)
 		require.Nil(t, items)
@@ -230,7 +288,7 @@ func TestRepository_Count(t *testing.T) {
 			return &fakeRow{values: []any{5}}
 		}
 
-		count, err := repository.Count(context.Background(), "")
+		count, err := repository.Count(context.Background(), ListFilter{})
 
 		require.NoError(t, err)
 		require.Equal(t, 5, count)
@@ -245,7 +303,7 @@ func TestRepository_Count(t *testing.T) {
 			return &fakeRow{values: []any{2}}
 		}
 
-		count, err := repository.Count(context.Background(), "phone")
+		count, err := repository.Count(context.Background(), ListFilter{Query: "phone"})
 
 		require.NoError(t, err)
 		require.Equal(t, 2, count)
@@ -262,310 +320,3096 @@ func TestRepository_Count(t *testing.T) {
 			return &fakeRow{err: queryErr}
 		}
 
-		count, err := repository.Count(context.Background(), "")
+		count, err := repository.Count(context.Background(), ListFilter{})
 
 		require.ErrorIs(t, err, queryErr)
 		require.Zero(t, count)
 	})
 }
 
-func TestRepository_GetByID(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestRepository_ListWithTotal(t *testing.T) {
+	t.Run("total from window function", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
 
-		createdAt := time.Now().Add(-time.Minute)
-		updatedAt := time.Now()
-		expected := Item{
-			ID:          "id-10",
-			Name:        "Phone",
-			Description: stringPointer("desc"),
-			Price:       "10.00",
-			Stock:       2,
-			CreatedAt:   createdAt,
-			UpdatedAt:   updatedAt,
-		}
-
-		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{values: []any{expected.ID, expected.Name, "desc", expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt}}
+		createdAt := time.Now()
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{"id-1", "Phone", "phone", nil, nil, "10.00", 1, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 42},
+				{"id-2", "Mouse", "mouse", nil, nil, "5.00", 2, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 42},
+			}}, nil
 		}
 
-		item, err := repository.GetByID(context.Background(), "id-10")
+		items, total, err := repository.ListWithTotal(context.Background(), ListFilter{Query: "o"}, 2, 0)
 
 		require.NoError(t, err)
-		require.Equal(t, expected, item)
-		require.Equal(t, []any{"id-10"}, database.lastArgs)
+		require.Len(t, items, 2)
+		require.Equal(t, 42, total)
+		require.Contains(t, normalizeSQL(database.lastQuery), "AS refs, reorder_point, COUNT(*) OVER () AS total FROM items WHERE deleted_at IS NULL AND (name ILIKE")
+		require.False(t, database.queryRowCalled, "no separate COUNT query")
 	})
 
-	t.Run("query error", func(t *testing.T) {
+	t.Run("fuzzy keeps the score before the total", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
 
-		dbErr := errors.New("query failed")
-		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{err: dbErr}
+		createdAt := time.Now()
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{"id-1", "Keyboard", "keyboard", nil, nil, "10.00", 1, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0.5, 1},
+			}}, nil
 		}
 
-		item, err := repository.GetByID(context.Background(), "id-11")
+		items, total, err := repository.ListWithTotal(context.Background(), ListFilter{Query: "keybord", Fuzzy: true, FuzzyThreshold: 0.3}, 10, 0)
 
-		require.ErrorIs(t, err, dbErr)
-		require.Equal(t, Item{}, item)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		require.Equal(t, 0.5, *items[0].Similarity)
+		require.Contains(t, normalizeSQL(database.lastQuery), "similarity(name, $3), COUNT(*) OVER () AS total FROM items")
 	})
-}
 
-func TestRepository_Update(t *testing.T) {
-	t.Run("requires at least one field", func(t *testing.T) {
+	t.Run("no matches on the first page", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
 
-		_, err := repository.Update(context.Background(), "id", UpdateItemInput{})
+		items, total, err := repository.ListWithTotal(context.Background(), ListFilter{Query: "nothing"}, 10, 0)
 
-		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.NoError(t, err)
+		require.Empty(t, items)
+		require.Zero(t, total)
 		require.False(t, database.queryRowCalled)
 	})
 
-	t.Run("success with all fields", func(t *testing.T) {
+	t.Run("empty page past the end counts separately", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
-
-		createdAt := time.Now().Add(-time.Hour)
-		updatedAt := time.Now()
-		description := "updated"
-		expected := Item{
-			ID:          "id-20",
-			Name:        "New",
-			Description: &description,
-			Price:       "12.00",
-			Stock:       5,
-			CreatedAt:   createdAt,
-			UpdatedAt:   updatedAt,
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
 		}
-
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{values: []any{expected.ID, expected.Name, description, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt}}
+			return &fakeRow{values: []any{25}}
 		}
 
-		name := "New"
-		price := "12.00"
-		stock := 5
-		item, err := repository.Update(context.Background(), "id-20", UpdateItemInput{
-			Name:               &name,
-			Description:        &description,
-			DescriptionPresent: true,
-			Price:              &price,
-			Stock:              &stock,
-		})
+		items, total, err := repository.ListWithTotal(context.Background(), ListFilter{Query: "phone"}, 10, 90)
 
 		require.NoError(t, err)
-		require.Equal(t, expected, item)
+		require.Empty(t, items)
+		require.Equal(t, 25, total)
 		require.True(t, database.queryRowCalled)
-		require.Contains(t, database.lastQuery, "UPDATE items")
-		require.Equal(t, "id-20", database.lastArgs[len(database.lastArgs)-1])
-		require.Len(t, database.lastArgs, 5)
+		require.Contains(t, database.lastQuery, "SELECT COUNT(*) FROM items")
+		require.Equal(t, []any{"phone"}, database.lastArgs)
 	})
 
-	t.Run("success with description null", func(t *testing.T) {
+	t.Run("count error past the end", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
-
-		createdAt := time.Now().Add(-time.Hour)
-		updatedAt := time.Now()
-		expected := Item{
-			ID:          "id-21",
-			Name:        "Name",
-			Description: nil,
-			Price:       "9.00",
-			Stock:       1,
-			CreatedAt:   createdAt,
-			UpdatedAt:   updatedAt,
+		countErr := errors.New("count failed")
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
 		}
-
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{values: []any{expected.ID, expected.Name, nil, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt}}
+			return &fakeRow{err: countErr}
 		}
 
-		price := "9.00"
-		item, err := repository.Update(context.Background(), "id-21", UpdateItemInput{
-			DescriptionPresent: true,
-			Description:        nil,
-			Price:              &price,
-		})
+		_, _, err := repository.ListWithTotal(context.Background(), ListFilter{}, 10, 90)
 
-		require.NoError(t, err)
-		require.Equal(t, expected, item)
-		require.Contains(t, normalizeSQL(database.lastQuery), "description = NULL")
-		require.Equal(t, "id-21", database.lastArgs[len(database.lastArgs)-1])
-		require.Len(t, database.lastArgs, 2)
+		require.ErrorIs(t, err, countErr)
 	})
+}
 
-	t.Run("not found maps to domain error", func(t *testing.T) {
+func TestRepository_ListAfter(t *testing.T) {
+	after := CreatedAtID{CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: "id-0"}
+
+	t.Run("keyset without filters", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
 
-		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{err: pgx.ErrNoRows}
+		createdAt := after.CreatedAt.Add(-time.Minute)
+		rows := &fakeRows{rows: [][]any{
+			{"id-1", "Phone", "phone", nil, nil, "10.00", 1, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		}}
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return rows, nil
 		}
 
-		_, err := repository.Update(context.Background(), "id-22", UpdateItemInput{
-			Name: stringPointer("Name"),
-		})
+		items, err := repository.ListAfter(context.Background(), ListFilter{}, after, 20)
 
-		require.ErrorIs(t, err, ErrorNotFound)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.True(t, rows.closed)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "FROM items WHERE deleted_at IS NULL AND (created_at, id) < ($1, $2) ORDER BY items.created_at DESC, items.id DESC LIMIT $3")
+		require.NotContains(t, query, "OFFSET")
+		require.Equal(t, []any{after.CreatedAt, "id-0", 20}, database.lastArgs)
 	})
 
-	t.Run("duplicate name maps to domain error", func(t *testing.T) {
+	t.Run("keyset with filters", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
 
-		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{err: &pgconn.PgError{Code: "23505"}}
+		_, err := repository.ListAfter(context.Background(), ListFilter{Query: "phone", Match: MatchContains, MinPrice: "10", SearchFields: []string{"name"}}, after, 5)
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery),
+			"WHERE deleted_at IS NULL AND name ILIKE '%' || $4 || '%' AND price >= $5::numeric AND (created_at, id) < ($1, $2) ORDER BY items.created_at DESC")
+		require.Equal(t, []any{after.CreatedAt, "id-0", 5, "phone", "10"}, database.lastArgs)
+	})
+
+	t.Run("keyset follows the identifier ranking", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
 		}
+		ranked := CreatedAtID{CreatedAt: after.CreatedAt, ID: after.ID, Ranked: true}
 
-		_, err := repository.Update(context.Background(), "id-23", UpdateItemInput{
-			Name: stringPointer("Name"),
-		})
+		_, err := repository.ListAfter(context.Background(), ListFilter{Query: "kb-001", Match: MatchContains, MinPrice: "10"}, ranked, 5)
 
-		require.ErrorIs(t, err, ErrorDuplicateName)
+		require.NoError(t, err)
+		match := "coalesce(sku = upper($4) OR barcode = $4, false)"
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "AND price >= $5::numeric AND ("+match+", created_at, id) < ($6, $1, $2)")
+		require.Contains(t, query, "ORDER BY "+match+" DESC, items.created_at DESC, items.id DESC LIMIT $3")
+		require.Equal(t, []any{after.CreatedAt, "id-0", 5, "kb-001", "10", true}, database.lastArgs)
 	})
 
-	t.Run("other error is returned", func(t *testing.T) {
+	t.Run("query error", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
 
-		dbErr := errors.New("db failed")
-		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{err: dbErr}
+		queryErr := errors.New("query failed")
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return nil, queryErr
 		}
 
-		_, err := repository.Update(context.Background(), "id-24", UpdateItemInput{
-			Name: stringPointer("Name"),
-		})
+		items, err := repository.ListAfter(context.Background(), ListFilter{}, after, 5)
 
-		require.ErrorIs(t, err, dbErr)
-		require.True(t, err == dbErr, "expected same error instance")
+		require.ErrorIs(t, err, queryErr)
+		require.Nil(t, items)
 	})
 }
 
-func TestRepository_Delete(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestRepository_EstimateCount(t *testing.T) {
+	t.Run("planner rows for the listing defaults", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
-
+		var queries []string
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{values: []any{"id-30"}}
+			queries = append(queries, normalizeSQL(sql))
+			if strings.Contains(sql, "pg_class") {
+				return &fakeRow{values: []any{int64(2000000)}}
+			}
+			return &fakeRow{values: []any{`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1250000.0}}]`}}
 		}
 
-		err := repository.Delete(context.Background(), "id-30")
+		total, ok, err := repository.EstimateCount(context.Background(), withDefaultStatus(ListFilter{}))
 
 		require.NoError(t, err)
-		require.Equal(t, []any{"id-30"}, database.lastArgs)
+		require.True(t, ok)
+		require.Equal(t, 1250000, total)
+		require.Len(t, queries, 2)
+		require.Equal(t, "EXPLAIN (FORMAT JSON) SELECT 1 FROM items WHERE deleted_at IS NULL AND status = $1 AND state = $2 AND "+notExpired, queries[1])
+		require.Equal(t, []any{string(StatusActive), string(StatePublished)}, database.lastArgs)
+		for _, query := range queries {
+			require.NotContains(t, query, "COUNT")
+		}
 	})
 
-	t.Run("not found maps to domain error", func(t *testing.T) {
+	// reltuples = -1: la tabla nunca se analizó, no hay estimación.
+	t.Run("never analyzed", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
-
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{err: pgx.ErrNoRows}
+			return &fakeRow{values: []any{int64(-1)}}
 		}
 
-		err := repository.Delete(context.Background(), "id-31")
+		total, ok, err := repository.EstimateCount(context.Background(), ListFilter{})
 
-		require.ErrorIs(t, err, ErrorNotFound)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Zero(t, total)
+		require.Contains(t, database.lastQuery, "FROM pg_class")
 	})
+}
 
-	t.Run("other error is returned", func(t *testing.T) {
+func TestRepository_CollectionVersion(t *testing.T) {
+	t.Run("latest update and count", func(t *testing.T) {
 		database := &fakeDB{}
 		repository := NewRepository(database)
+		lastUpdatedAt := time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{lastUpdatedAt, 3, "2024-05-01"}}
+		}
 
-		dbErr := errors.New("db failed")
+		version, err := repository.CollectionVersion(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, CollectionVersion{LastUpdatedAt: lastUpdatedAt, Count: 3, Today: "2024-05-01"}, version)
+		require.Equal(t, "SELECT max(updated_at), count(*), current_date::text FROM items", normalizeSQL(database.lastQuery))
+	})
+
+	t.Run("empty catalog", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
 		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
-			return &fakeRow{err: dbErr}
+			return &fakeRow{values: []any{nil, 0, "2024-05-01"}}
 		}
 
-		err := repository.Delete(context.Background(), "id-32")
+		version, err := repository.CollectionVersion(context.Background())
 
-		require.ErrorIs(t, err, dbErr)
-		require.True(t, err == dbErr, "expected same error instance")
+		require.NoError(t, err)
+		require.Equal(t, CollectionVersion{Today: "2024-05-01"}, version)
 	})
 }
 
-type fakeDB struct {
-	queryRowFn func(ctx context.Context, sql string, args ...any) pgx.Row
-	queryFn    func(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
-
-	lastQuery      string
-	lastArgs       []any
-	queryRowCalled bool
-	queryCalled    bool
+func TestIsUnfiltered(t *testing.T) {
+	require.True(t, isUnfiltered(ListFilter{}))
+	require.True(t, isUnfiltered(withDefaultStatus(ListFilter{})))
+	require.True(t, isUnfiltered(ListFilter{Status: StatusAll, State: StateAll}))
+	require.False(t, isUnfiltered(ListFilter{Status: StatusInactive}))
+	require.False(t, isUnfiltered(ListFilter{State: StateDraft}))
+	require.False(t, isUnfiltered(ListFilter{ExpiringBefore: "2026-01-01"}))
+	require.True(t, isUnfiltered(ListFilter{Match: MatchContains, Sort: []SortKey{"price"}}))
+	require.False(t, isUnfiltered(ListFilter{Query: "phone"}))
+	require.False(t, isUnfiltered(ListFilter{InStock: new(bool)}))
 }
 
-func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	db.queryRowCalled = true
-	db.lastQuery = sql
-	db.lastArgs = args
-	if db.queryRowFn == nil {
-		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+func TestRepository_ListMatchModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		match     MatchMode
+		predicate string
+	}{
+		{"contains", MatchContains, "WHERE deleted_at IS NULL AND (name ILIKE '%%' || $%[1]d || '%%' OR sku = upper($%[1]d) OR barcode = $%[1]d)"},
+		{"default is contains", "", "WHERE deleted_at IS NULL AND (name ILIKE '%%' || $%[1]d || '%%' OR sku = upper($%[1]d) OR barcode = $%[1]d)"},
+		{"prefix", MatchPrefix, "WHERE deleted_at IS NULL AND (lower(name) LIKE lower($%[1]d) || '%%' OR sku = upper($%[1]d) OR barcode = $%[1]d)"},
+		{"exact", MatchExact, "WHERE deleted_at IS NULL AND (lower(name) = lower($%[1]d) OR sku = upper($%[1]d) OR barcode = $%[1]d)"},
 	}
-	return db.queryRowFn(ctx, sql, args...)
-}
 
-func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	db.queryCalled = true
-	db.lastQuery = sql
-	db.lastArgs = args
-	if db.queryFn == nil {
-		return nil, errors.New("unexpected Query call")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			filter := ListFilter{Query: "Cable HDMI", Match: tt.match}
+
+			database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				return &fakeRows{}, nil
+			}
+			_, err := repository.List(context.Background(), filter, 10, 0)
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), fmt.Sprintf(tt.predicate, 3))
+			require.Equal(t, []any{10, 0, "Cable HDMI"}, database.lastArgs)
+
+			// Count tiene que aplicar exactamente el mismo predicado para que el total coincida.
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{values: []any{1}}
+			}
+			_, err = repository.Count(context.Background(), filter)
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), fmt.Sprintf(tt.predicate, 1))
+			require.Equal(t, []any{"Cable HDMI"}, database.lastArgs)
+		})
 	}
-	return db.queryFn(ctx, sql, args...)
 }
 
-type fakeRow struct {
-	values []any
-	err    error
-}
+func TestRepository_ListSearchFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		fields    []string
+		match     MatchMode
+		predicate string
+	}{
+		{
+			"default is name and identifiers",
+			nil,
+			MatchContains,
+			"WHERE deleted_at IS NULL AND (name ILIKE '%%' || $%[1]d || '%%' OR sku = upper($%[1]d) OR barcode = $%[1]d)",
+		},
+		{"sku only", []string{"sku"}, MatchContains, "WHERE deleted_at IS NULL AND sku = upper($%d)"},
+		{"barcode ignores the match mode", []string{"barcode"}, MatchPrefix, "WHERE deleted_at IS NULL AND barcode = $%d"},
+		{
+			"all fields",
+			[]string{"name", "description", "sku", "barcode"},
+			MatchContains,
+			"WHERE deleted_at IS NULL AND (name ILIKE '%%' || $%[1]d || '%%' OR coalesce(description, '') ILIKE '%%' || $%[1]d || '%%' OR sku = upper($%[1]d) OR barcode = $%[1]d)",
+		},
+		{"description only", []string{"description"}, MatchContains, "WHERE deleted_at IS NULL AND coalesce(description, '') ILIKE '%%' || $%d || '%%'"},
+		{
+			"name or description",
+			[]string{"name", "description"},
+			MatchContains,
+			"WHERE deleted_at IS NULL AND (name ILIKE '%%' || $%[1]d || '%%' OR coalesce(description, '') ILIKE '%%' || $%[1]d || '%%')",
+		},
+		{
+			"prefix on both",
+			[]string{"name", "description"},
+			MatchPrefix,
+			"WHERE deleted_at IS NULL AND (lower(name) LIKE lower($%[1]d) || '%%' OR lower(coalesce(description, '')) LIKE lower($%[1]d) || '%%')",
+		},
+	}
 
-func (row *fakeRow) Scan(dest ...any) error {
-	if row.err != nil {
-		return row.err
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			filter := ListFilter{Query: "leather", Match: tt.match, SearchFields: tt.fields, InStock: new(bool)}
+
+			database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				return &fakeRows{}, nil
+			}
+			_, err := repository.List(context.Background(), filter, 10, 0)
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), fmt.Sprintf(tt.predicate, 3)+" AND stock = 0")
+			require.Equal(t, []any{10, 0, "leather"}, database.lastArgs)
+
+			// Count tiene que aplicar exactamente el mismo predicado para que el total coincida.
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{values: []any{1}}
+			}
+			_, err = repository.Count(context.Background(), filter)
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), fmt.Sprintf(tt.predicate, 1)+" AND stock = 0")
+			require.Equal(t, []any{"leather"}, database.lastArgs)
+		})
 	}
-	return assignValues(dest, row.values)
 }
 
-type fakeRows struct {
-	rows    [][]any
-	idx     int
-	closed  bool
-	err     error
-	scanErr error
-}
+func TestRepository_ListIdentifierRanking(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  ListFilter
+		orderBy string
+	}{
+		{
+			"exact sku or barcode first",
+			ListFilter{Query: "kb-001"},
+			"ORDER BY coalesce(sku = upper($3) OR barcode = $3, false) DESC, items.created_at DESC, items.id DESC LIMIT $1 OFFSET $2",
+		},
+		{
+			"ranking before the requested sort",
+			ListFilter{Query: "kb-001", SearchFields: []string{"name", "sku"}, Sort: []SortKey{"price"}},
+			"ORDER BY coalesce(sku = upper($3), false) DESC, items.price ASC, items.id ASC LIMIT $1 OFFSET $2",
+		},
+		{
+			"no ranking without identifiers",
+			ListFilter{Query: "kb-001", SearchFields: []string{"name"}},
+			"ORDER BY items.created_at DESC, items.id DESC LIMIT $1 OFFSET $2",
+		},
+		{
+			"no ranking without query",
+			ListFilter{},
+			"ORDER BY items.created_at DESC, items.id DESC LIMIT $1 OFFSET $2",
+		},
+	}
 
-func (rows *fakeRows) Close() {
-	rows.closed = true
-}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				return &fakeRows{}, nil
+			}
 
-func (rows *fakeRows) Err() error {
-	return rows.err
-}
+			_, err := repository.List(context.Background(), tt.filter, 10, 0)
 
-func (rows *fakeRows) CommandTag() pgconn.CommandTag {
-	return pgconn.CommandTag{}
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), tt.orderBy)
+		})
+	}
 }
 
-func (rows *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
-	return nil
-}
+func TestRepository_Related(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := Item{ID: "id-1", Name: "Wireless Keyboard"}
 
-func (rows *fakeRows) Next() bool {
-	if rows.closed {
-		return false
-	}
-	if rows.idx >= len(rows.rows) {
-		rows.closed = true
-		return false
-	}
-	rows.idx++
-	return true
+	t.Run("excludes the item and orders by similarity", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{"id-2", "Wireless Keyboard Pro", "wireless-keyboard-pro", nil, nil, "20.00", 1, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0.72},
+				{"id-3", "Wireless Mouse", "wireless-mouse", nil, nil, "8.00", 1, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0.21},
+			}}, nil
+		}
+
+		related, err := repository.Related(context.Background(), base, 0.3, 5)
+
+		require.NoError(t, err)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "AS refs, reorder_point, similarity(name, $1) FROM items WHERE id <> $2 AND deleted_at IS NULL")
+		require.Contains(t, query, "similarity(name, $1) >= $3 OR lower(split_part(name, ' ', 1)) = lower(split_part($1, ' ', 1))")
+		require.Contains(t, query, "ORDER BY similarity(name, $1) DESC, created_at DESC, id DESC LIMIT $4")
+		require.Equal(t, []any{"Wireless Keyboard", "id-1", 0.3, 5}, database.lastArgs)
+		require.Len(t, related, 2)
+		require.Equal(t, "id-2", related[0].ID)
+		require.InDelta(t, 0.72, *related[0].Similarity, 0.0001)
+	})
+
+	t.Run("caps the limit", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+
+		_, err := repository.Related(context.Background(), base, 0.3, 500)
+
+		require.NoError(t, err)
+		require.Equal(t, maxRelatedLimit, database.lastArgs[3])
+	})
+
+	t.Run("missing extension", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return nil, &pgconn.PgError{Code: "42883"}
+		}
+
+		_, err := repository.Related(context.Background(), base, 0.3, 5)
+
+		require.ErrorIs(t, err, ErrorFuzzyUnavailable)
+	})
+}
+
+func TestRepository_Suggest(t *testing.T) {
+	t.Run("issues a single narrow select", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		queries := 0
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			queries++
+			return &fakeRows{rows: [][]any{
+				{"id-1", "Phone case"},
+				{"id-2", "Headphones"},
+			}}, nil
+		}
+
+		suggestions, err := repository.Suggest(context.Background(), "pho", 0.3, 8)
+
+		require.NoError(t, err)
+		require.Equal(t, []Suggestion{{ID: "id-1", Name: "Phone case"}, {ID: "id-2", Name: "Headphones"}}, suggestions)
+		require.Equal(t, 1, queries)
+		require.False(t, database.queryRowCalled)
+		query := normalizeSQL(database.lastQuery)
+		require.True(t, strings.HasPrefix(query, "SELECT id, name FROM items WHERE"), query)
+		require.NotContains(t, query, "COUNT")
+		require.NotContains(t, query, "OVER")
+		require.NotContains(t, query, "JOIN")
+		require.Contains(t, query, "deleted_at IS NULL AND status = 'active' AND state = 'published' AND "+notExpired)
+		require.Contains(t, query, "lower(name) LIKE lower($1) || '%' OR similarity(name, $1) >= $2")
+		require.Contains(t, query, "ORDER BY (lower(name) LIKE lower($1) || '%') DESC, similarity(name, $1) DESC, name, id LIMIT $3")
+		require.Equal(t, []any{"pho", 0.3, 8}, database.lastArgs)
+	})
+
+	t.Run("caps the limit", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+
+		suggestions, err := repository.Suggest(context.Background(), "pho", 0.3, 500)
+
+		require.NoError(t, err)
+		require.Empty(t, suggestions)
+		require.Equal(t, maxSuggestLimit, database.lastArgs[2])
+	})
+
+	t.Run("missing extension", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return nil, &pgconn.PgError{Code: "42883"}
+		}
+
+		_, err := repository.Suggest(context.Background(), "pho", 0.3, 8)
+
+		require.ErrorIs(t, err, ErrorFuzzyUnavailable)
+	})
+}
+
+func TestRepository_ClosestName(t *testing.T) {
+	t.Run("top match above the threshold", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"Keyboard"}}
+		}
+
+		name, err := repository.ClosestName(context.Background(), "keybord", 0.4)
+
+		require.NoError(t, err)
+		require.Equal(t, "Keyboard", name)
+		query := normalizeSQL(database.lastQuery)
+		require.True(t, strings.HasPrefix(query, "SELECT name FROM items WHERE"), query)
+		require.Contains(t, query, "deleted_at IS NULL AND status = 'active' AND state = 'published' AND "+notExpired)
+		require.Contains(t, query, "AND similarity(name, $1) >= $2 ORDER BY similarity(name, $1) DESC, name LIMIT 1")
+		require.Equal(t, []any{"keybord", 0.4}, database.lastArgs)
+		require.False(t, database.queryCalled)
+	})
+
+	t.Run("nothing similar enough", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		name, err := repository.ClosestName(context.Background(), "zzzz", 0.4)
+
+		require.NoError(t, err)
+		require.Empty(t, name)
+	})
+
+	t.Run("missing extension", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "42883"}}
+		}
+
+		_, err := repository.ClosestName(context.Background(), "keybord", 0.4)
+
+		require.ErrorIs(t, err, ErrorFuzzyUnavailable)
+	})
+}
+
+func TestRepository_ListFuzzy(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := ListFilter{Query: "keybord", Fuzzy: true, FuzzyThreshold: 0.3, InStock: new(bool)}
+
+	t.Run("orders by similarity and returns the score", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{"id-1", "Keyboard", "keyboard", nil, nil, "10.00", 0, createdAt, createdAt, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0.53},
+			}}, nil
+		}
+
+		listed, err := repository.List(context.Background(), filter, 10, 0)
+
+		require.NoError(t, err)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "AS refs, reorder_point, similarity(name, $3) FROM items")
+		require.Contains(t, query, "WHERE deleted_at IS NULL AND (similarity(name, $3) >= $4 OR sku = upper($3) OR barcode = $3) AND stock = 0")
+		require.Contains(t, query,
+			"ORDER BY coalesce(sku = upper($3) OR barcode = $3, false) DESC, similarity(name, $3) DESC, items.created_at DESC, items.id DESC LIMIT $1 OFFSET $2")
+		require.Equal(t, []any{10, 0, "keybord", 0.3}, database.lastArgs)
+		require.Len(t, listed, 1)
+		require.InDelta(t, 0.53, *listed[0].Similarity, 0.0001)
+	})
+
+	t.Run("count applies the same predicate", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{1}}
+		}
+
+		_, err := repository.Count(context.Background(), filter)
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE deleted_at IS NULL AND (similarity(name, $1) >= $2 OR sku = upper($1) OR barcode = $1) AND stock = 0")
+		require.Equal(t, []any{"keybord", 0.3}, database.lastArgs)
+	})
+
+	t.Run("missing extension", func(t *testing.T) {
+		undefinedFunction := &pgconn.PgError{Code: "42883", Message: "function similarity(text, unknown) does not exist"}
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return nil, undefinedFunction
+		}
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: undefinedFunction}
+		}
+
+		_, err := repository.List(context.Background(), filter, 10, 0)
+		require.ErrorIs(t, err, ErrorFuzzyUnavailable)
+
+		_, err = repository.Count(context.Background(), filter)
+		require.ErrorIs(t, err, ErrorFuzzyUnavailable)
+
+		// Sin fuzzy, el mismo código no se reinterpreta.
+		_, err = repository.List(context.Background(), ListFilter{}, 10, 0)
+		require.ErrorIs(t, err, undefinedFunction)
+	})
+}
+
+func TestRepository_ListNameEq(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    ListFilter
+		predicate string
+	}{
+		// Igualdad directa sobre name: la resuelve ux_items_name.
+		{"case sensitive", ListFilter{NameEq: "Phone X"}, "FROM items WHERE deleted_at IS NULL AND name = $1"},
+		{"case insensitive", ListFilter{NameEq: "Phone X", NameEqIgnoreCase: true}, "FROM items WHERE deleted_at IS NULL AND lower(name) = lower($1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{values: []any{1}}
+			}
+
+			total, err := repository.Count(context.Background(), tt.filter)
+
+			require.NoError(t, err)
+			require.Equal(t, 1, total)
+			require.Contains(t, normalizeSQL(database.lastQuery), tt.predicate)
+			require.NotContains(t, database.lastQuery, "ILIKE")
+			require.Equal(t, []any{"Phone X"}, database.lastArgs)
+		})
+	}
+}
+
+func TestRepository_ListPriceRange(t *testing.T) {
+	database := &fakeDB{}
+	repository := NewRepository(database)
+	filter := ListFilter{Query: "cable", Match: MatchPrefix, MinPrice: "10.00", MaxPrice: "99.99"}
+
+	database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		return &fakeRows{}, nil
+	}
+	_, err := repository.List(context.Background(), filter, 10, 0)
+	require.NoError(t, err)
+	require.Contains(t, normalizeSQL(database.lastQuery),
+		"WHERE deleted_at IS NULL AND (lower(name) LIKE lower($3) || '%' OR sku = upper($3) OR barcode = $3) AND price >= $4::numeric AND price <= $5::numeric")
+	require.Equal(t, []any{10, 0, "cable", "10.00", "99.99"}, database.lastArgs)
+
+	database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		return &fakeRow{values: []any{1}}
+	}
+	_, err = repository.Count(context.Background(), ListFilter{MaxPrice: "99.99"})
+	require.NoError(t, err)
+	require.Contains(t, normalizeSQL(database.lastQuery), "FROM items WHERE deleted_at IS NULL AND price <= $1::numeric")
+	require.Equal(t, []any{"99.99"}, database.lastArgs)
+}
+
+func TestRepository_ListScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope DeletionScope
+		want  string
+	}{
+		{"active", ScopeActive, "FROM items WHERE deleted_at IS NULL AND stock > 0"},
+		{"deleted", ScopeDeleted, "FROM items WHERE deleted_at IS NOT NULL AND stock > 0"},
+		{"all", ScopeAll, "FROM items WHERE stock > 0"},
+	}
+
+	inStock := true
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{values: []any{0}}
+			}
+
+			_, err := repository.Count(context.Background(), ListFilter{InStock: &inStock, Scope: tt.scope})
+
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), tt.want)
+		})
+	}
+
+	t.Run("all without filters has no where", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{0}}
+		}
+
+		_, err := repository.Count(context.Background(), ListFilter{Scope: ScopeAll})
+
+		require.NoError(t, err)
+		require.Equal(t, "SELECT COUNT(*) FROM items", normalizeSQL(database.lastQuery))
+	})
+}
+
+func TestRepository_ListStockFilters(t *testing.T) {
+	inStock, outOfStock, excludeExpired := true, false, true
+	gte, lte, maxWeight, minOrderQty := 2, 10, 2000, 1
+	tests := []struct {
+		name      string
+		filter    ListFilter
+		predicate string
+		args      []any
+	}{
+		{"in stock", ListFilter{InStock: &inStock}, "WHERE deleted_at IS NULL AND stock > 0", nil},
+		{"out of stock", ListFilter{InStock: &outOfStock}, "WHERE deleted_at IS NULL AND stock = 0", nil},
+		{"range", ListFilter{StockGTE: &gte, StockLTE: &lte}, "WHERE deleted_at IS NULL AND stock >= $1 AND stock <= $2", []any{2, 10}},
+		{
+			"composed with name search",
+			ListFilter{Query: "cable", InStock: &inStock, StockLTE: &lte},
+			"WHERE deleted_at IS NULL AND (name ILIKE '%' || $1 || '%' OR sku = upper($1) OR barcode = $1) AND stock > 0 AND stock <= $2",
+			[]any{"cable", 10},
+		},
+		{"sku", ListFilter{SKU: "KB-001", InStock: &inStock}, "WHERE deleted_at IS NULL AND stock > 0 AND sku = $1", []any{"KB-001"}},
+		{"category", ListFilter{CategoryID: "550e8400-e29b-41d4-a716-446655440000"}, "WHERE deleted_at IS NULL AND category_id = $1::uuid", []any{"550e8400-e29b-41d4-a716-446655440000"}},
+		{"brand", ListFilter{BrandID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, "WHERE deleted_at IS NULL AND brand_id = $1::uuid", []any{"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}},
+		{"inactive", ListFilter{Status: StatusInactive}, "WHERE deleted_at IS NULL AND status = $1", []any{"inactive"}},
+		{"currency", ListFilter{Currency: "EUR", Status: StatusActive}, "WHERE deleted_at IS NULL AND currency = $1 AND status = $2", []any{"EUR", "active"}},
+		{"all statuses", ListFilter{Status: StatusAll, InStock: &inStock}, "WHERE deleted_at IS NULL AND stock > 0", nil},
+		{"drafts", ListFilter{State: StateDraft, Status: StatusActive}, "WHERE deleted_at IS NULL AND status = $1 AND state = $2", []any{"active", "draft"}},
+		{"all states", ListFilter{State: StateAll, InStock: &inStock}, "WHERE deleted_at IS NULL AND stock > 0", nil},
+		{
+			"attributes",
+			ListFilter{Attributes: map[string]string{"size": "42", "color": "red"}},
+			`WHERE deleted_at IS NULL AND attributes @> $1::jsonb AND (attributes @> $2::jsonb OR attributes @> $3::jsonb)`,
+			[]any{`{"color":"red"}`, `{"size":"42"}`, `{"size":42}`},
+		},
+		{"max weight", ListFilter{MaxWeight: &maxWeight, Status: StatusActive}, "WHERE deleted_at IS NULL AND status = $1 AND weight_grams <= $2", []any{"active", 2000}},
+		{"min order quantity", ListFilter{MinOrderQtyLTE: &minOrderQty}, "WHERE deleted_at IS NULL AND min_order_qty <= $1", []any{1}},
+		{
+			"expiring before, without expired",
+			ListFilter{ExpiringBefore: "2025-04-01", ExcludeExpired: &excludeExpired},
+			"WHERE deleted_at IS NULL AND expires_at < $1::date AND (expires_at IS NULL OR expires_at >= current_date)",
+			[]any{"2025-04-01"},
+		},
+		{
+			"effective price range",
+			ListFilter{MinPrice: "5.00", MaxPrice: "10.00", UseEffectivePrice: true},
+			"WHERE deleted_at IS NULL AND coalesce(items.sale_price, items.price) >= $1::numeric AND coalesce(items.sale_price, items.price) <= $2::numeric",
+			[]any{"5.00", "10.00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{values: []any{1}}
+			}
+
+			_, err := repository.Count(context.Background(), tt.filter)
+
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), tt.predicate)
+			require.Equal(t, tt.args, database.lastArgs)
+		})
+	}
+}
+
+func TestRepository_ListSort(t *testing.T) {
+	tests := []struct {
+		name           string
+		sort           []SortKey
+		effectivePrice bool
+		orderBy        string
+	}{
+		{"default newest first", nil, false, "ORDER BY items.created_at DESC, items.id DESC"},
+		{"created_at ascending", []SortKey{"created_at"}, false, "ORDER BY items.created_at ASC, items.id ASC"},
+		{"created_at descending", []SortKey{"-created_at"}, false, "ORDER BY items.created_at DESC, items.id DESC"},
+		// Ordena por la columna numeric, no por el price::text del SELECT.
+		{"price ascending", []SortKey{"price"}, false, "ORDER BY items.price ASC, items.id ASC"},
+		{"price descending", []SortKey{"-price"}, false, "ORDER BY items.price DESC, items.id DESC"},
+		{"multiple fields keep their order", []SortKey{"stock", "-price"}, false, "ORDER BY items.stock ASC, items.price DESC, items.id DESC"},
+		{"name", []SortKey{"name"}, false, "ORDER BY items.name ASC, items.id ASC"},
+		{"unknown falls back to default", []SortKey{"weight"}, false, "ORDER BY items.created_at DESC, items.id DESC"},
+		{"effective price", []SortKey{"-price", "name"}, true, "ORDER BY coalesce(items.sale_price, items.price) DESC, items.name ASC, items.id ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				return &fakeRows{}, nil
+			}
+
+			_, err := repository.List(context.Background(), ListFilter{Sort: tt.sort, UseEffectivePrice: tt.effectivePrice}, 10, 0)
+
+			require.NoError(t, err)
+			require.Contains(t, normalizeSQL(database.lastQuery), tt.orderBy+" LIMIT $1 OFFSET $2")
+			require.NotContains(t, normalizeSQL(database.lastQuery), "ORDER BY price")
+		})
+	}
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		createdAt := time.Now().Add(-time.Minute)
+		updatedAt := time.Now()
+		expected := Item{
+			ID:          "id-10",
+			Name:        "Phone",
+			Slug:        "phone",
+			Description: stringPointer("desc"),
+			Price:       "10.00",
+			Stock:       2,
+			CreatedAt:   createdAt,
+			UpdatedAt:   updatedAt,
+		}
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{expected.ID, expected.Name, expected.Slug, nil, "desc", expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt, expected.Version, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.GetByID(context.Background(), "id-10")
+
+		require.NoError(t, err)
+		require.Equal(t, expected, item)
+		require.Equal(t, []any{"id-10"}, database.lastArgs)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id = $1 AND deleted_at IS NULL")
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		dbErr := errors.New("query failed")
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: dbErr}
+		}
+
+		item, err := repository.GetByID(context.Background(), "id-11")
+
+		require.ErrorIs(t, err, dbErr)
+		require.Equal(t, Item{}, item)
+	})
+}
+
+func TestRepository_GetBySKU(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1", "Keyboard", "keyboard", "KB-001", nil, "10.00", 1, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.GetBySKU(context.Background(), "KB-001")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Equal(t, stringPointer("KB-001"), item.SKU)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE sku = $1 AND deleted_at IS NULL")
+		require.Equal(t, []any{"KB-001"}, database.lastArgs)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.GetBySKU(context.Background(), "KB-404")
+
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestRepository_GetByBarcode(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1", "Keyboard", "keyboard", "KB-001", nil, "10.00", 1, now, now, 1, nil, 0, false, false, "4006381333931", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.GetByBarcode(context.Background(), "4006381333931")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Equal(t, stringPointer("4006381333931"), item.Barcode)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE barcode = $1 AND deleted_at IS NULL")
+		require.Equal(t, []any{"4006381333931"}, database.lastArgs)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.GetByBarcode(context.Background(), "4006381333931")
+
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestRepository_GetBySlug(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1", "Wireless Keyboard", "wireless-keyboard", nil, nil, "10.00", 1, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.GetBySlug(context.Background(), "wireless-keyboard")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Equal(t, "wireless-keyboard", item.Slug)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE slug = $1 AND deleted_at IS NULL AND state = 'published'")
+		require.Equal(t, []any{"wireless-keyboard"}, database.lastArgs)
+	})
+
+	t.Run("not found maps to domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.GetBySlug(context.Background(), "missing")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_TakenSlugs(t *testing.T) {
+	t.Run("new item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"keyboard"}, {"keyboard-2"}}}, nil
+		}
+
+		taken, err := repository.TakenSlugs(context.Background(), "keyboard", "")
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"keyboard", "keyboard-2"}, taken)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE (slug = $1 OR slug LIKE $2) AND id IS DISTINCT FROM $3")
+		require.Equal(t, []any{"keyboard", "keyboard-%", nil}, database.lastArgs)
+	})
+
+	t.Run("excludes the edited item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+
+		taken, err := repository.TakenSlugs(context.Background(), "keyboard", "id-1")
+
+		require.NoError(t, err)
+		require.Empty(t, taken)
+		require.Equal(t, "id-1", database.lastArgs[2])
+	})
+}
+
+func TestRepository_Replace(t *testing.T) {
+	t.Run("rewrites every editable column", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1", "Phone", "phone", nil, nil, "10.00", 0, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "Phone", Slug: "phone", Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Nil(t, item.Description)
+		require.Contains(t, normalizeSQL(database.lastQuery),
+			"SET name = $2, slug = $3, description = $4, price = $5::numeric, stock = $6, allow_backorder = $7, updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL")
+		require.Equal(t, []any{"id-1", "Phone", "phone", (*string)(nil), "10.00", 0, false}, database.lastArgs)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "Phone", Slug: "phone", Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "items_name_key"}}
+		}
+
+		_, err := repository.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "Phone", Slug: "phone", Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateName)
+	})
+}
+
+func TestRepository_Update(t *testing.T) {
+	t.Run("requires at least one field", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		_, err := repository.Update(context.Background(), "id", UpdateItemInput{})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, database.queryRowCalled)
+	})
+
+	t.Run("success with all fields", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		createdAt := time.Now().Add(-time.Hour)
+		updatedAt := time.Now()
+		description := "updated"
+		expected := Item{
+			ID:          "id-20",
+			Name:        "New",
+			Slug:        "new",
+			Description: &description,
+			Price:       "12.00",
+			Stock:       5,
+			CreatedAt:   createdAt,
+			UpdatedAt:   updatedAt,
+		}
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{expected.ID, expected.Name, expected.Slug, nil, description, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt, expected.Version, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		name := "New"
+		price := "12.00"
+		stock := 5
+		item, err := repository.Update(context.Background(), "id-20", UpdateItemInput{
+			Name:               &name,
+			Description:        &description,
+			DescriptionPresent: true,
+			Price:              &price,
+			Stock:              &stock,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, expected, item)
+		require.True(t, database.queryRowCalled)
+		require.Contains(t, database.lastQuery, "UPDATE items")
+		require.Equal(t, "id-20", database.lastArgs[len(database.lastArgs)-1])
+		require.Len(t, database.lastArgs, 5)
+	})
+
+	t.Run("success with description null", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		createdAt := time.Now().Add(-time.Hour)
+		updatedAt := time.Now()
+		expected := Item{
+			ID:          "id-21",
+			Name:        "Name",
+			Slug:        "name",
+			Description: nil,
+			Price:       "9.00",
+			Stock:       1,
+			CreatedAt:   createdAt,
+			UpdatedAt:   updatedAt,
+		}
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{expected.ID, expected.Name, expected.Slug, nil, nil, expected.Price, expected.Stock, expected.CreatedAt, expected.UpdatedAt, expected.Version, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		price := "9.00"
+		item, err := repository.Update(context.Background(), "id-21", UpdateItemInput{
+			DescriptionPresent: true,
+			Description:        nil,
+			Price:              &price,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, expected, item)
+		require.Contains(t, normalizeSQL(database.lastQuery), "description = NULL")
+		require.Equal(t, "id-21", database.lastArgs[len(database.lastArgs)-1])
+		require.Len(t, database.lastArgs, 2)
+	})
+
+	t.Run("not found maps to domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.Update(context.Background(), "id-22", UpdateItemInput{
+			Name: stringPointer("Name"),
+		})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("duplicate name maps to domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505"}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-23", UpdateItemInput{
+			Name: stringPointer("Name"),
+		})
+
+		require.ErrorIs(t, err, ErrorDuplicateName)
+	})
+
+	t.Run("sets and clears the barcode", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-24", "Name", "name", nil, nil, "9.00", 1, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-24", UpdateItemInput{BarcodePresent: true, Barcode: stringPointer("4006381333931")})
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "barcode = $1")
+		require.Equal(t, []any{"4006381333931", "id-24"}, database.lastArgs)
+
+		_, err = repository.Update(context.Background(), "id-24", UpdateItemInput{BarcodePresent: true})
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "barcode = NULL")
+		require.Equal(t, []any{"id-24"}, database.lastArgs)
+	})
+
+	t.Run("sets and clears the category", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-25", "Name", "name", nil, nil, "9.00", 1, now, now, 2, nil, 0, false, false, nil, ItemCategory{ID: "category-1", Name: "Audio"}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-25", UpdateItemInput{CategoryIDPresent: true, CategoryID: stringPointer("category-1")})
+		require.NoError(t, err)
+		require.Equal(t, &ItemCategory{ID: "category-1", Name: "Audio"}, item.Category)
+		require.Contains(t, normalizeSQL(database.lastQuery), "category_id = $1::uuid")
+
+		_, err = repository.Update(context.Background(), "id-25", UpdateItemInput{CategoryIDPresent: true})
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "category_id = NULL")
+	})
+
+	t.Run("unknown category maps to domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_items_category"}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-25", UpdateItemInput{CategoryIDPresent: true, CategoryID: stringPointer("category-404")})
+
+		require.ErrorIs(t, err, ErrorUnknownCategory)
+	})
+
+	t.Run("sets and clears the brand", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-26", "Name", "name", nil, nil, "9.00", 1, now, now, 2, nil, 0, false, false, nil, nil, ItemBrand{ID: "brand-1", Name: "Acme"}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-26", UpdateItemInput{BrandIDPresent: true, BrandID: stringPointer("brand-1")})
+		require.NoError(t, err)
+		require.Equal(t, &ItemBrand{ID: "brand-1", Name: "Acme"}, item.Brand)
+		require.Contains(t, normalizeSQL(database.lastQuery), "brand_id = $1::uuid")
+
+		_, err = repository.Update(context.Background(), "id-26", UpdateItemInput{BrandIDPresent: true})
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "brand_id = NULL")
+	})
+
+	t.Run("unknown brand maps to a field error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_items_brand"}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-26", UpdateItemInput{BrandIDPresent: true, BrandID: stringPointer("brand-404")})
+
+		require.ErrorIs(t, err, ErrorUnknownBrand)
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "brand_id", validationError.Field)
+	})
+
+	t.Run("sets the status", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-27", "Name", "name", nil, nil, "9.00", 1, now, now, 2, nil, 0, false, false, nil, nil, nil, "inactive", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+		status := StatusInactive
+
+		item, err := repository.Update(context.Background(), "id-27", UpdateItemInput{Status: &status})
+
+		require.NoError(t, err)
+		require.Equal(t, StatusInactive, item.Status)
+		require.Contains(t, normalizeSQL(database.lastQuery), "status = $1")
+		require.Equal(t, "inactive", database.lastArgs[0])
+	})
+
+	t.Run("sets the currency", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-28", "Name", "name", nil, nil, "9.00", 1, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "EUR", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-28", UpdateItemInput{Currency: stringPointer("EUR")})
+
+		require.NoError(t, err)
+		require.Equal(t, "EUR", item.Currency)
+		require.Contains(t, normalizeSQL(database.lastQuery), "currency = $1")
+	})
+
+	t.Run("replaces and clears the attributes", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-29", "Name", "name", nil, nil, "9.00", 1, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "USD", map[string]any{"color": "red"}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-29", UpdateItemInput{Attributes: map[string]any{"color": "red"}, AttributesPresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"color": "red"}, item.Attributes)
+		require.Contains(t, normalizeSQL(database.lastQuery), "attributes = $1::jsonb")
+		require.Equal(t, `{"color":"red"}`, database.lastArgs[0])
+
+		_, err = repository.Update(context.Background(), "id-29", UpdateItemInput{AttributesPresent: true})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "attributes = NULL")
+	})
+
+	t.Run("sets and clears the weight and dimensions", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-30", "Name", "name", nil, nil, "9.00", 1, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "USD", nil, 1500, 300, nil, 100, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-30", UpdateItemInput{
+			WeightGrams: integerPointer(1500), WeightGramsPresent: true,
+			WidthMM: integerPointer(300), WidthMMPresent: true,
+			HeightMMPresent: true,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1500, *item.WeightGrams)
+		require.Nil(t, item.HeightMM)
+		require.Equal(t, 100, *item.DepthMM)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "weight_grams = $1, width_mm = $2, height_mm = NULL, updated_at")
+		require.NotContains(t, query, "depth_mm =")
+		require.Equal(t, []any{1500, 300, "id-30"}, database.lastArgs)
+	})
+
+	t.Run("sets and clears the sale price", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-31", "Name", "name", nil, nil, "10.00", 1, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "USD", nil, nil, nil, nil, nil, "7.99", "7.99", nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-31", UpdateItemInput{SalePrice: stringPointer("7.99"), SalePricePresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "7.99", *item.SalePrice)
+		require.Equal(t, "7.99", item.EffectivePrice)
+		require.Contains(t, normalizeSQL(database.lastQuery), "sale_price = $1::numeric")
+
+		_, err = repository.Update(context.Background(), "id-31", UpdateItemInput{SalePricePresent: true})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "sale_price = NULL")
+	})
+
+	t.Run("sets the tax rate", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-33", "Name", "name", nil, nil, "0.99", 1, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "USD", nil, nil, nil, nil, nil, nil, "0.99", 1900, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-33", UpdateItemInput{TaxRateBPS: integerPointer(1900)})
+
+		require.NoError(t, err)
+		require.Equal(t, 1900, item.TaxRateBPS)
+		require.Equal(t, "1.18", item.PriceWithTax)
+		require.Contains(t, normalizeSQL(database.lastQuery), "tax_rate_bps = $1")
+	})
+
+	t.Run("sets the minimum order quantity", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-34", "Name", "name", nil, nil, "1.00", 500, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "USD", nil, nil, nil, nil, nil, nil, "1.00", 0, 50, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-34", UpdateItemInput{MinOrderQty: integerPointer(50)})
+
+		require.NoError(t, err)
+		require.Equal(t, 50, item.MinOrderQty)
+		require.Contains(t, normalizeSQL(database.lastQuery), "min_order_qty = $1")
+	})
+
+	t.Run("sets and clears the expiration date", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		now := time.Now()
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-35", "Name", "name", nil, nil, "1.50", 5, now, now, 2, nil, 0, false, false, nil, nil, nil, "active", "USD", nil, nil, nil, nil, nil, nil, "1.50", 0, 1, "2025-03-20", nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-35", UpdateItemInput{ExpiresAt: stringPointer("2025-03-20"), ExpiresAtPresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "2025-03-20", *item.ExpiresAt)
+		require.Contains(t, normalizeSQL(database.lastQuery), "expires_at = $1::date")
+
+		_, err = repository.Update(context.Background(), "id-35", UpdateItemInput{ExpiresAtPresent: true})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "expires_at = NULL")
+	})
+
+	t.Run("sale price check maps to invalid sale price", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23514", ConstraintName: "ck_items_sale_price_below_price"}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-32", UpdateItemInput{Price: stringPointer("5.00")})
+
+		require.ErrorIs(t, err, ErrorInvalidSalePrice)
+	})
+
+	t.Run("value too long maps to invalid input", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "22001"}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-36", UpdateItemInput{Name: stringPointer("Keyboard")})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+	})
+
+	t.Run("status check maps to invalid status", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23514", ConstraintName: "ck_items_status"}}
+		}
+		status := ItemStatus("archived")
+
+		_, err := repository.Update(context.Background(), "id-27", UpdateItemInput{Status: &status})
+
+		require.ErrorIs(t, err, ErrorInvalidStatus)
+	})
+
+	t.Run("disabling backorders with negative stock maps to invalid stock", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23514", ConstraintName: "ck_items_stock_non_negative_unless_backorder"}}
+		}
+		allow := false
+
+		_, err := repository.Update(context.Background(), "id-23", UpdateItemInput{AllowBackorder: &allow})
+
+		require.ErrorIs(t, err, ErrorInvalidStock)
+		require.Contains(t, normalizeSQL(database.lastQuery), "allow_backorder = $1")
+	})
+
+	t.Run("sets slug", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_items_slug"}}
+		}
+
+		_, err := repository.Update(context.Background(), "id-24", UpdateItemInput{Slug: stringPointer("taken")})
+
+		require.ErrorIs(t, err, ErrorDuplicateSlug)
+		require.Contains(t, normalizeSQL(database.lastQuery), "SET slug = $1, updated_at = now(), version = version + 1")
+		require.Equal(t, []any{"taken", "id-24"}, database.lastArgs)
+	})
+
+	t.Run("other error is returned", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		dbErr := errors.New("db failed")
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: dbErr}
+		}
+
+		_, err := repository.Update(context.Background(), "id-24", UpdateItemInput{
+			Name: stringPointer("Name"),
+		})
+
+		require.ErrorIs(t, err, dbErr)
+		require.True(t, err == dbErr, "expected same error instance")
+	})
+
+	t.Run("if version adds the condition", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		now := time.Now()
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-25", "Phone", "phone", nil, nil, "10.00", 4, now, now, 4, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.Update(context.Background(), "id-25", UpdateItemInput{Stock: integerPointer(4), IfVersion: integerPointer(3)})
+
+		require.NoError(t, err)
+		require.Equal(t, 4, item.Version)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id = $2 AND deleted_at IS NULL AND version = $3 RETURNING")
+		require.Equal(t, []any{4, "id-25", 3}, database.lastArgs)
+	})
+
+	for _, tt := range []struct {
+		name    string
+		exists  bool
+		wantErr error
+	}{
+		{"stale version", true, ErrorVersionMismatch},
+		{"missing item with version", false, ErrorNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				if strings.HasPrefix(sql, "SELECT EXISTS") {
+					return &fakeRow{values: []any{tt.exists}}
+				}
+				return &fakeRow{err: pgx.ErrNoRows}
+			}
+
+			_, err := repository.Update(context.Background(), "id-26", UpdateItemInput{Stock: integerPointer(1), IfVersion: integerPointer(2)})
+
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, []any{"id-26"}, database.lastArgs)
+		})
+	}
+}
+
+func TestRepository_Delete(t *testing.T) {
+	t.Run("success is a soft delete", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return deletedItemRow("id-30")
+		}
+
+		item, err := repository.Delete(context.Background(), "id-30", nil)
+
+		require.NoError(t, err)
+		require.Equal(t, "id-30", item.ID)
+		require.NotNil(t, item.DeletedAt)
+		require.Contains(t, normalizeSQL(database.lastQuery), "UPDATE items SET deleted_at = now(), updated_at = now() WHERE id = $1 AND deleted_at IS NULL")
+		require.Contains(t, normalizeSQL(database.lastQuery), "RETURNING "+itemColumns)
+		require.NotContains(t, database.lastQuery, "DELETE FROM")
+		require.Equal(t, []any{"id-30", (*int)(nil)}, database.lastArgs)
+	})
+
+	t.Run("not found maps to domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.Delete(context.Background(), "id-31", nil)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("other error is returned", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		dbErr := errors.New("db failed")
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: dbErr}
+		}
+
+		_, err := repository.Delete(context.Background(), "id-32", nil)
+
+		require.ErrorIs(t, err, dbErr)
+		require.True(t, err == dbErr, "expected same error instance")
+	})
+
+	t.Run("if version", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return deletedItemRow("id-33")
+		}
+
+		version := 5
+		_, err := repository.Delete(context.Background(), "id-33", &version)
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id = $1 AND deleted_at IS NULL AND ($2::integer IS NULL OR version = $2)")
+		require.Equal(t, []any{"id-33", &version}, database.lastArgs)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			if strings.HasPrefix(sql, "SELECT EXISTS") {
+				return &fakeRow{values: []any{true}}
+			}
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.Delete(context.Background(), "id-34", integerPointer(1))
+
+		require.ErrorIs(t, err, ErrorVersionMismatch)
+	})
+}
+
+func TestRepository_Purge(t *testing.T) {
+	t.Run("only deletes items in the trash", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-40"}}
+		}
+
+		err := repository.Purge(context.Background(), "id-40")
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "DELETE FROM items WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id")
+		require.Contains(t, database.lastQuery, `pg_notify('items_changed', json_build_object('id', purged.id, 'op', 'purged')::text)`)
+		require.Equal(t, []any{"id-40"}, database.lastArgs)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		require.ErrorIs(t, repository.Purge(context.Background(), "id-41"), ErrorNotFound)
+	})
+}
+
+func TestRepository_PurgeDeletedBefore(t *testing.T) {
+	// idRows arma una tanda de n filas con un id cada una.
+	idRows := func(n int) *fakeRows {
+		rows := make([][]any, n)
+		for index := range rows {
+			rows[index] = []any{fmt.Sprintf("id-%d", index)}
+		}
+		return &fakeRows{rows: rows}
+	}
+	cutoff := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("deletes in batches until one is short", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		batches := []int{purgeBatchSize, 3}
+		calls := 0
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			calls++
+			return idRows(batches[calls-1]), nil
+		}
+
+		purged, err := repository.PurgeDeletedBefore(context.Background(), cutoff)
+
+		require.NoError(t, err)
+		require.Equal(t, purgeBatchSize+3, purged)
+		require.Equal(t, 2, calls)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id IN (SELECT id FROM items WHERE deleted_at < $1 LIMIT $2)")
+		require.Contains(t, database.lastQuery, "pg_notify('items_changed'")
+		require.Equal(t, []any{cutoff, purgeBatchSize}, database.lastArgs)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		ctx, cancel := context.WithCancel(context.Background())
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			cancel()
+			return idRows(purgeBatchSize), nil
+		}
+
+		purged, err := repository.PurgeDeletedBefore(ctx, cutoff)
+
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, purgeBatchSize, purged)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		dbErr := errors.New("db failed")
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return nil, dbErr
+		}
+
+		purged, err := repository.PurgeDeletedBefore(context.Background(), cutoff)
+
+		require.ErrorIs(t, err, dbErr)
+		require.Zero(t, purged)
+	})
+}
+
+func TestRepository_Reservations(t *testing.T) {
+	t.Run("reserved quantity only counts active reservations", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{4}}
+		}
+
+		reserved, err := repository.ReservedQuantity(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, 4, reserved)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE item_id = $1 AND expires_at > now()")
+	})
+
+	t.Run("insert passes the ttl in seconds", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		expires := time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC)
+		created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"res-1", "id-1", 2, expires, created}}
+		}
+
+		reservation, err := repository.InsertReservation(context.Background(), "id-1", 2, 10*time.Minute)
+
+		require.NoError(t, err)
+		require.Equal(t, Reservation{ID: "res-1", ItemID: "id-1", Quantity: 2, ExpiresAt: expires, CreatedAt: created}, reservation)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "now() + make_interval(secs => $3)")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+			"available changes, so the item version goes up in the same statement")
+		require.Equal(t, []any{"id-1", 2, float64(600)}, database.lastArgs)
+	})
+
+	t.Run("delete is scoped to the item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		err := repository.DeleteReservation(context.Background(), "id-1", "res-9")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "DELETE FROM item_reservations WHERE id = $1 AND item_id = $2 RETURNING id, item_id")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id IN (SELECT item_id FROM released)")
+		require.Equal(t, []any{"res-9", "id-1"}, database.lastArgs)
+	})
+
+	t.Run("sweeper deletes expired reservations", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(context.Context, string, ...any) pgx.Row {
+			return &fakeRow{values: []any{2, []string{"id-1"}}}
+		}
+
+		deleted, itemIDs, err := repository.DeleteExpiredReservations(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+		require.Equal(t, []string{"id-1"}, itemIDs)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "WHERE expires_at <= now()")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id IN (SELECT item_id FROM expired)")
+		require.Contains(t, query, "array_agg(id::text) FROM touched")
+	})
+}
+
+func TestRepository_Variants(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("insert stores the attributes as json", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"variant-1", "id-1", "TS-M", nil, "15.00", 3, map[string]string{"size": "M"}, created, created}}
+		}
+
+		variant, err := repository.InsertVariant(context.Background(), "id-1", CreateVariantInput{SKU: "TS-M", Stock: 3, Attributes: map[string]string{"size": "M"}})
+
+		require.NoError(t, err)
+		require.Equal(t, Variant{ID: "variant-1", ItemID: "id-1", SKU: "TS-M", EffectivePrice: "15.00", Stock: 3, Attributes: map[string]string{"size": "M"}, CreatedAt: created, UpdatedAt: created}, variant)
+		require.Contains(t, normalizeSQL(database.lastQuery), "VALUES ($1, $2, $3::numeric, $4, $5::jsonb)")
+		require.Contains(t, normalizeSQL(database.lastQuery), "coalesce(price, (SELECT items.price FROM items WHERE items.id = item_variants.item_id))::text AS effective_price")
+		require.Equal(t, []any{"id-1", "TS-M", (*string)(nil), 3, `{"size":"M"}`}, database.lastArgs)
+	})
+
+	t.Run("insert maps the per-item sku index", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_item_variants_item_sku"}}
+		}
+
+		_, err := repository.InsertVariant(context.Background(), "id-1", CreateVariantInput{SKU: "TS-M"})
+
+		require.ErrorIs(t, err, ErrorDuplicateVariantSKU)
+	})
+
+	t.Run("list returns an empty slice", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+
+		variants, err := repository.ListVariants(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, []Variant{}, variants)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE item_id = $1 ORDER BY created_at, id;")
+	})
+
+	t.Run("update clears the price and replaces the attributes", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"variant-1", "id-1", "TS-M", nil, "15.00", 5, map[string]string{}, created, created}}
+		}
+
+		_, err := repository.UpdateVariant(context.Background(), "id-1", "variant-1", UpdateVariantInput{Stock: integerPointer(5), PricePresent: true, AttributesPresent: true})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "SET price = NULL, stock = $1, attributes = $2::jsonb, updated_at = now() WHERE id = $3 AND item_id = $4")
+		require.Equal(t, []any{5, "{}", "variant-1", "id-1"}, database.lastArgs)
+	})
+
+	t.Run("update of another item's variant is not found", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.UpdateVariant(context.Background(), "id-1", "variant-9", UpdateVariantInput{Stock: integerPointer(1)})
+
+		require.ErrorIs(t, err, ErrorVariantNotFound)
+	})
+
+	t.Run("delete is scoped to the item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		err := repository.DeleteVariant(context.Background(), "id-1", "variant-9")
+
+		require.ErrorIs(t, err, ErrorVariantNotFound)
+		require.Equal(t, []any{"variant-9", "id-1"}, database.lastArgs)
+	})
+
+	t.Run("variant stock counts and sums", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{3, 12}}
+		}
+
+		count, total, err := repository.VariantStock(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		require.Equal(t, 12, total)
+	})
+}
+
+func TestRepository_PriceSchedules(t *testing.T) {
+	effectiveAt := time.Date(2025, 7, 1, 3, 0, 0, 0, time.UTC)
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("insert returns the schedule", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"schedule-1", "id-1", "19.99", effectiveAt, nil, nil, "", created}}
+		}
+
+		schedule, err := repository.InsertPriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "19.99", EffectiveAt: effectiveAt})
+
+		require.NoError(t, err)
+		require.Equal(t, PriceSchedule{ID: "schedule-1", ItemID: "id-1", NewPrice: "19.99", EffectiveAt: effectiveAt, CreatedAt: created}, schedule)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "INSERT INTO price_schedules (item_id, new_price, effective_at) VALUES ($1, $2::numeric, $3)")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+			"next_price_change changes, so the item version goes up in the same statement")
+		require.Equal(t, []any{"id-1", "19.99", effectiveAt}, database.lastArgs)
+	})
+
+	t.Run("insert maps the pending instant index", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_price_schedules_item_effective_at_pending"}}
+		}
+
+		_, err := repository.InsertPriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "19.99", EffectiveAt: effectiveAt})
+
+		require.ErrorIs(t, err, ErrorDuplicatePriceSchedule)
+	})
+
+	t.Run("due schedules are the pending ones oldest first", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"schedule-1", "id-1", "19.99", effectiveAt, nil, nil, "", created}}}, nil
+		}
+
+		schedules, err := repository.DuePriceSchedules(context.Background(), 100)
+
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE applied_at IS NULL AND failed_at IS NULL AND effective_at <= now() ORDER BY effective_at, id LIMIT $1;")
+		require.Equal(t, []any{100}, database.lastArgs)
+	})
+
+	t.Run("mark applied of a schedule that is no longer pending", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.MarkPriceScheduleApplied(context.Background(), "schedule-1")
+
+		require.ErrorIs(t, err, ErrorPriceScheduleNotFound)
+		require.Contains(t, normalizeSQL(database.lastQuery), "SET applied_at = now() WHERE id = $1 AND applied_at IS NULL AND failed_at IS NULL")
+	})
+
+	t.Run("delete only removes pending schedules of the item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		err := repository.DeletePriceSchedule(context.Background(), "id-1", "schedule-9")
+
+		require.ErrorIs(t, err, ErrorPriceScheduleNotFound)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "WHERE id = $1 AND item_id = $2 AND applied_at IS NULL AND failed_at IS NULL")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id IN (SELECT item_id FROM cancelled)")
+		require.Equal(t, []any{"schedule-9", "id-1"}, database.lastArgs)
+	})
+
+	t.Run("mark failed takes the schedule out of the queue with the reason", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1"}}
+		}
+
+		err := repository.MarkPriceScheduleFailed(context.Background(), "schedule-1", PriceScheduleFailureBelowSalePrice)
+
+		require.NoError(t, err)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "SET applied_at = NULL, failed_at = now(), failure_reason = $2 WHERE id = $1")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id IN (SELECT item_id FROM failed)",
+			"next_price_change changes, so the item version goes up in the same statement")
+		require.Equal(t, []any{"schedule-1", "below_sale_price"}, database.lastArgs)
+	})
+
+	t.Run("pending schedules exclude the failed ones", func(t *testing.T) {
+		require.Contains(t, nextPriceChangeColumn, "pending.applied_at IS NULL AND pending.failed_at IS NULL")
+	})
+}
+
+func TestRepository_ExternalRefs(t *testing.T) {
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("lookup finds the item of the ref", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1", "Keyboard", "keyboard", nil, nil, "10.00", 1, created, created, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.GetByExternalRef(context.Background(), "erp", "A-100")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id = (SELECT item_id FROM item_external_refs WHERE system = $1 AND external_id = $2) AND deleted_at IS NULL")
+		require.Equal(t, []any{"erp", "A-100"}, database.lastArgs)
+	})
+
+	t.Run("item columns embed the refs", func(t *testing.T) {
+		require.Contains(t, itemColumns, "FROM item_external_refs refs WHERE refs.item_id = items.id) AS refs")
+	})
+
+	t.Run("insert returns the ref", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"erp", "A-100", created}}
+		}
+
+		ref, err := repository.InsertExternalRef(context.Background(), "id-1", ExternalRefInput{System: "erp", ExternalID: "A-100"})
+
+		require.NoError(t, err)
+		require.Equal(t, ExternalRef{System: "erp", ExternalID: "A-100", CreatedAt: created}, ref)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "INSERT INTO item_external_refs (item_id, system, external_id) VALUES ($1, $2, $3)")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+			"refs change, so the item version goes up in the same statement")
+		require.Equal(t, []any{"id-1", "erp", "A-100"}, database.lastArgs)
+	})
+
+	constraints := []struct {
+		constraint string
+		want       error
+	}{
+		{"pk_item_external_refs", ErrorDuplicateExternalRef},
+		{"fk_item_external_refs_item", ErrorNotFound},
+	}
+	for _, tt := range constraints {
+		t.Run("insert maps "+tt.constraint, func(t *testing.T) {
+			database := &fakeDB{}
+			repository := NewRepository(database)
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{err: &pgconn.PgError{Code: "23505", ConstraintName: tt.constraint}}
+			}
+
+			_, err := repository.InsertExternalRef(context.Background(), "id-1", ExternalRefInput{System: "erp", ExternalID: "A-100"})
+
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("delete only removes refs of the item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		err := repository.DeleteExternalRef(context.Background(), "id-1", "erp", "A-100")
+
+		require.ErrorIs(t, err, ErrorExternalRefNotFound)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "WHERE system = $1 AND external_id = $2 AND item_id = $3")
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id IN (SELECT item_id FROM removed)")
+		require.Equal(t, []any{"erp", "A-100", "id-1"}, database.lastArgs)
+	})
+}
+
+func TestRepository_Translations(t *testing.T) {
+	updated := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("list returns the translations of the item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"es", "Teclado", nil, updated}}}, nil
+		}
+
+		translations, err := repository.ListTranslations(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, []Translation{{Locale: "es", Name: "Teclado", UpdatedAt: updated}}, translations)
+		require.Contains(t, normalizeSQL(database.lastQuery), "FROM item_translations WHERE item_id = $1 ORDER BY locale")
+	})
+
+	t.Run("translations for a page are keyed by item", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		description := "Mecánico"
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"id-2", "es", "Teclado", description, updated}}}, nil
+		}
+
+		translations, err := repository.TranslationsFor(context.Background(), "es", []string{"id-1", "id-2"})
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]Translation{"id-2": {Locale: "es", Name: "Teclado", Description: &description, UpdatedAt: updated}}, translations)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE locale = $1 AND item_id = ANY($2::uuid[])")
+		require.Equal(t, []any{"es", []string{"id-1", "id-2"}}, database.lastArgs)
+	})
+
+	t.Run("upsert replaces the translation and bumps the item version", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"es", "Teclado", nil, updated}}
+		}
+
+		translation, err := repository.UpsertTranslation(context.Background(), "id-1", "es", TranslationInput{Name: "Teclado"})
+
+		require.NoError(t, err)
+		require.Equal(t, Translation{Locale: "es", Name: "Teclado", UpdatedAt: updated}, translation)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "UPDATE items SET updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL")
+		require.Contains(t, query, "ON CONFLICT (item_id, locale) DO UPDATE")
+		require.Equal(t, []any{"id-1", "es", "Teclado", (*string)(nil)}, database.lastArgs)
+	})
+
+	t.Run("upsert of a missing item is not found", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_item_translations_item"}}
+		}
+
+		_, err := repository.UpsertTranslation(context.Background(), "id-1", "es", TranslationInput{Name: "Teclado"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("search can include translations", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+		filter := ListFilter{Query: "teclado", SearchFields: []string{"name", "description"}, SearchTranslations: true}
+
+		_, err := repository.List(context.Background(), filter, 10, 0)
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE deleted_at IS NULL AND (name ILIKE '%' || $3 || '%' OR coalesce(description, '') ILIKE '%' || $3 || '%'"+
+			" OR EXISTS (SELECT 1 FROM item_translations translations WHERE translations.item_id = items.id AND"+
+			" (translations.name ILIKE '%' || $3 || '%' OR coalesce(translations.description, '') ILIKE '%' || $3 || '%')))")
+		require.Equal(t, []any{10, 0, "teclado"}, database.lastArgs)
+	})
+
+	t.Run("identifiers are not searched in translations", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+		filter := ListFilter{Query: "kb-001", SearchTranslations: true}
+
+		_, err := repository.List(context.Background(), filter, 10, 0)
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE deleted_at IS NULL AND (name ILIKE '%' || $3 || '%' OR sku = upper($3) OR barcode = $3"+
+			" OR EXISTS (SELECT 1 FROM item_translations translations WHERE translations.item_id = items.id AND translations.name ILIKE '%' || $3 || '%'))")
+	})
+}
+
+func TestRepository_StockMovements(t *testing.T) {
+	t.Run("insert stores an empty request id as null", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{int64(1)}}
+		}
+
+		err := repository.InsertStockMovement(context.Background(), StockMovement{ItemID: "id-1", Delta: -2, ResultingStock: 3, Reason: "update"})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "VALUES ($1, $2, $3, $4, nullif($5, ''))")
+		require.Equal(t, []any{"id-1", -2, 3, "update", ""}, database.lastArgs)
+	})
+
+	t.Run("list is newest first with the total", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{int64(7), "id-1", 5, 8, "adjustment", "req-1", created, 2},
+				{int64(3), "id-1", 3, 3, "create", "", created, 2},
+			}}, nil
+		}
+
+		movements, total, err := repository.ListStockMovements(context.Background(), "id-1", 20, 0)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, total)
+		require.Equal(t, []StockMovement{
+			{ID: 7, ItemID: "id-1", Delta: 5, ResultingStock: 8, Reason: "adjustment", RequestID: "req-1", CreatedAt: created},
+			{ID: 3, ItemID: "id-1", Delta: 3, ResultingStock: 3, Reason: "create", CreatedAt: created},
+		}, movements)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE item_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3;")
+		require.Equal(t, []any{"id-1", 20, 0}, database.lastArgs)
+	})
+}
+
+func TestRepository_RestockNeeded(t *testing.T) {
+	database := &fakeDB{}
+	repository := NewRepository(database)
+	createdAt := time.Now()
+	database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+		return &fakeRows{rows: [][]any{
+			{"id-1", "Keyboard", "keyboard", nil, nil, "10.00", 2, createdAt, createdAt, 1, nil, 2, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 10, 5},
+		}}, nil
+	}
+
+	items, total, err := repository.RestockNeeded(context.Background(), 20, 40)
+
+	require.NoError(t, err)
+	require.Equal(t, 5, total)
+	require.Len(t, items, 1)
+	require.Equal(t, 10, *items[0].ReorderPoint)
+	require.Contains(t, normalizeSQL(database.lastQuery), "WHERE deleted_at IS NULL AND reorder_point IS NOT NULL AND stock <= reorder_point ORDER BY reorder_point - stock DESC, id LIMIT $1 OFFSET $2;")
+	require.Equal(t, []any{20, 40}, database.lastArgs)
+}
+
+func TestRepository_Changes(t *testing.T) {
+	database := &fakeDB{}
+	repository := NewRepository(database)
+	updatedAt := time.Now()
+	database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+		return &fakeRows{rows: [][]any{
+			{"id-1", "Keyboard", "keyboard", nil, nil, "10.00", 2, updatedAt, updatedAt, 1, updatedAt, 2, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		}}, nil
+	}
+	after := UpdatedAtID{UpdatedAt: updatedAt.Add(-time.Hour), ID: "id-0"}
+	until := updatedAt.Add(time.Minute)
+
+	items, err := repository.Changes(context.Background(), after, until, 500)
+
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].DeletedAt)
+	require.Contains(t, normalizeSQL(database.lastQuery), "FROM items WHERE (updated_at, id) > ($1, $2) AND updated_at < $3 ORDER BY updated_at, id LIMIT $4;")
+	require.Equal(t, []any{after.UpdatedAt, "id-0", until, 500}, database.lastArgs)
+}
+
+func TestRepository_PriceAdjustment(t *testing.T) {
+	categoryFilter := ListFilter{CategoryID: "category-1"}
+
+	t.Run("preview reads the totals from the window functions", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{"id-1", "Case", "USD", "10.00", "10.50", 7, 0, 1},
+				{"id-2", "Phone", "JPY", "100.00", "105.00", 7, 0, 1},
+			}}, nil
+		}
+
+		preview, err := repository.PreviewPriceAdjustment(context.Background(), categoryFilter, PriceAdjustment{Type: AdjustmentPercent, Value: "5"}, 5)
+
+		require.NoError(t, err)
+		require.Equal(t, PriceAdjustmentPreview{
+			Affected:          7,
+			NotAboveSalePrice: 1,
+			Sample: []PriceAdjustmentSample{
+				{ID: "id-1", Name: "Case", Currency: "USD", OldPrice: "10.00", NewPrice: "10.50"},
+				{ID: "id-2", Name: "Phone", Currency: "JPY", OldPrice: "100.00", NewPrice: "105.00"},
+			},
+		}, preview)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "round(price * (1 + $1::numeric / 100), CASE WHEN currency = ANY($2::text[]) THEN 0 ELSE 2 END) AS new_price")
+		require.Contains(t, query, "FROM items WHERE deleted_at IS NULL AND category_id = $4::uuid )")
+		require.Contains(t, query, "ORDER BY name, id LIMIT $3;")
+		require.Equal(t, []any{"5", currency.WholeUnit(), 5, "category-1"}, database.lastArgs)
+	})
+
+	t.Run("apply updates and records history in one statement", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"id-1"}, {"id-2"}}}, nil
+		}
+
+		adjusted, err := repository.AdjustPrices(context.Background(), categoryFilter, PriceAdjustment{Type: AdjustmentFixed, Value: "-1.50"}, PriceReasonBulkAdjustment, "req-1")
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"id-1", "id-2"}, adjusted)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "FROM items WHERE deleted_at IS NULL AND category_id = $5::uuid FOR UPDATE")
+		require.Contains(t, query, "UPDATE items SET price = round(price + $1::numeric, CASE WHEN currency = ANY($2::text[]) THEN 0 ELSE 2 END), updated_at = now(), version = version + 1")
+		require.Contains(t, query, "INSERT INTO price_history (item_id, old_price, new_price, reason, request_id) SELECT id, old_price, price, $3, nullif($4, '') FROM adjusted WHERE price <> old_price )")
+		require.Contains(t, query, "INSERT INTO item_audit (item_id, action, changes, request_id) SELECT id, 'updated', jsonb_build_array(jsonb_build_object('field', 'price', 'old', old_price::text, 'new', price::text)), nullif($4, '') FROM adjusted WHERE price <> old_price ) SELECT id FROM adjusted;")
+		require.Equal(t, []any{"-1.50", currency.WholeUnit(), PriceReasonBulkAdjustment, "req-1", "category-1"}, database.lastArgs)
+	})
+
+	t.Run("unknown adjustment type never reaches the database", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		_, err := repository.AdjustPrices(context.Background(), categoryFilter, PriceAdjustment{Type: "multiply", Value: "2"}, PriceReasonBulkAdjustment, "")
+
+		require.Error(t, err)
+		require.False(t, database.queryCalled)
+	})
+}
+
+func TestRepository_PriceHistory(t *testing.T) {
+	t.Run("insert stores the prices as numeric", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{int64(1)}}
+		}
+		oldPrice := "10.00"
+
+		err := repository.InsertPriceChange(context.Background(), PriceHistoryEntry{ItemID: "id-1", OldPrice: &oldPrice, NewPrice: "12.50", Reason: "update", RequestID: "req-1"})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO price_history (item_id, old_price, new_price, reason, request_id) VALUES ($1, $2::numeric, $3::numeric, $4, nullif($5, ''))")
+		require.Equal(t, []any{"id-1", &oldPrice, "12.50", "update", "req-1"}, database.lastArgs)
+	})
+
+	t.Run("list is newest first with the total", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		changed := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{int64(7), "id-1", "10.00", "12.50", "update", "req-1", changed, 2},
+				{int64(3), "id-1", nil, "10.00", "create", "", changed, 2},
+			}}, nil
+		}
+
+		entries, total, err := repository.ListPriceHistory(context.Background(), "id-1", PriceHistoryFilter{}, 20, 0)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, total)
+		oldPrice := "10.00"
+		require.Equal(t, []PriceHistoryEntry{
+			{ID: 7, ItemID: "id-1", OldPrice: &oldPrice, NewPrice: "12.50", Reason: "update", RequestID: "req-1", ChangedAt: changed},
+			{ID: 3, ItemID: "id-1", NewPrice: "10.00", Reason: "create", ChangedAt: changed},
+		}, entries)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE item_id = $1 ORDER BY changed_at DESC, id DESC LIMIT $2 OFFSET $3;")
+		require.Equal(t, []any{"id-1", 20, 0}, database.lastArgs)
+	})
+
+	t.Run("list filters by range", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+		_, _, err := repository.ListPriceHistory(context.Background(), "id-1", PriceHistoryFilter{From: &from, To: &to}, 20, 40)
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE item_id = $1 AND changed_at >= $4 AND changed_at < $5 ORDER BY")
+		require.Equal(t, []any{"id-1", 20, 40, from, to}, database.lastArgs)
+	})
+}
+
+// copyDB es un fakeDB que además acepta COPY.
+type copyDB struct {
+	*fakeDB
+	table   pgx.Identifier
+	columns []string
+	rows    [][]any
+	err     error
+}
+
+func (db *copyDB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	db.table, db.columns = tableName, columnNames
+	for rowSrc.Next() {
+		values, err := rowSrc.Values()
+		if err != nil {
+			return 0, err
+		}
+		db.rows = append(db.rows, values)
+	}
+	if db.err != nil {
+		return 0, db.err
+	}
+	return int64(len(db.rows)), rowSrc.Err()
+}
+
+func TestRepository_UpsertBySKU(t *testing.T) {
+	sku := "SKU-001"
+	input := CreateItemInput{Name: "Phone X", Slug: "phone-x", SKU: &sku, Price: "12.50", Stock: 7}
+	row := func(created bool) []any {
+		return []any{"id-1", "Phone X", "phone-x", sku, nil, "12.50", 7, time.Now(), time.Now(), 5, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, nil, created}
+	}
+
+	t.Run("updated", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: row(false)}
+		}
+
+		item, created, err := repository.UpsertBySKU(context.Background(), input)
+
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, "12.50", item.Price)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price, stock = EXCLUDED.stock, description = coalesce(EXCLUDED.description, items.description)")
+		require.Contains(t, query, "WHERE items.deleted_at IS NULL")
+		require.Contains(t, query, ", xmax = 0;")
+	})
+
+	t.Run("created", func(t *testing.T) {
+		database := &fakeDB{}
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: row(true)}
+		}
+
+		_, created, err := NewRepository(database).UpsertBySKU(context.Background(), input)
+
+		require.NoError(t, err)
+		require.True(t, created)
+	})
+
+	t.Run("sku of a deleted item", func(t *testing.T) {
+		database := &fakeDB{}
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, _, err := NewRepository(database).UpsertBySKU(context.Background(), input)
+
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+	})
+}
+
+func TestRepository_CheckUniqueKeys(t *testing.T) {
+	sku, barcode := "SKU-001", "4006381333931"
+	input := CreateItemInput{Name: "Phone X", Slug: "phone-x", SKU: &sku, Barcode: &barcode}
+
+	t.Run("free keys", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{false, false, false, false}}
+		}
+
+		require.NoError(t, repository.CheckUniqueKeys(context.Background(), input))
+		require.Contains(t, normalizeSQL(database.lastQuery), "EXISTS (SELECT 1 FROM items WHERE name = $1 AND deleted_at IS NULL)")
+		require.Equal(t, []any{"Phone X", "phone-x", &sku, &barcode}, database.lastArgs)
+	})
+
+	t.Run("taken keys map to the insert errors", func(t *testing.T) {
+		for index, want := range []error{ErrorDuplicateName, ErrorDuplicateSlug, ErrorDuplicateSKU, ErrorDuplicateBarcode} {
+			values := []any{false, false, false, false}
+			values[index] = true
+			database := &fakeDB{}
+			database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+				return &fakeRow{values: values}
+			}
+
+			require.ErrorIs(t, NewRepository(database).CheckUniqueKeys(context.Background(), input), want)
+		}
+	})
+}
+
+func TestRepository_CopyInsert(t *testing.T) {
+	sku := "SKU-1"
+	taxRate, minOrderQty := 0, 1
+
+	t.Run("one copy with numeric prices", func(t *testing.T) {
+		database := &copyDB{fakeDB: &fakeDB{}}
+		repository := NewRepository(database)
+
+		copied, err := repository.CopyInsert(context.Background(), []CreateItemInput{
+			{Name: "Phone", Slug: "phone", SKU: &sku, Price: "10.50", Stock: 3, Currency: "USD", TaxRateBPS: &taxRate, MinOrderQty: &minOrderQty},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(1), copied)
+		require.Equal(t, pgx.Identifier{"items"}, database.table)
+		require.Equal(t, copyColumns, database.columns)
+		require.Len(t, database.rows[0], len(copyColumns))
+		price, ok := database.rows[0][4].(pgtype.Numeric)
+		require.True(t, ok, "price goes as numeric, got %T", database.rows[0][4])
+		value, err := price.Value()
+		require.NoError(t, err)
+		require.Equal(t, "10.50", value)
+		require.Equal(t, string(StatePublished), database.rows[0][10])
+	})
+
+	t.Run("duplicates map to domain errors", func(t *testing.T) {
+		database := &copyDB{fakeDB: &fakeDB{}, err: &pgconn.PgError{Code: "23505", ConstraintName: "ux_items_sku"}}
+		repository := NewRepository(database)
+
+		_, err := repository.CopyInsert(context.Background(), []CreateItemInput{{Name: "Phone", Slug: "phone", SKU: &sku, Price: "1.00"}})
+
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+	})
+
+	t.Run("database without copy", func(t *testing.T) {
+		_, err := NewRepository(&fakeDB{}).CopyInsert(context.Background(), nil)
+
+		require.ErrorContains(t, err, "does not support COPY")
+	})
+}
+
+func TestRepository_InsertOutbox(t *testing.T) {
+	database := &fakeDB{}
+	repository := NewRepository(database)
+	database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		return &fakeRow{values: []any{2}}
+	}
+
+	err := repository.InsertOutbox(context.Background(), []Event{
+		{ID: "id-1", Operation: EventCreated, Item: &Item{ID: "id-1", Name: "Phone"}},
+		{ID: "id-2", Operation: EventDeleted},
+	})
+
+	require.NoError(t, err)
+	require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO outbox (event_type, payload)")
+	require.Contains(t, normalizeSQL(database.lastQuery), "FROM unnest($1::text[], $2::text[]) WITH ORDINALITY")
+	require.Equal(t, []string{"item.created", "item.deleted"}, database.lastArgs[0])
+	payloads := database.lastArgs[1].([]string)
+	require.Contains(t, payloads[0], `"name":"Phone"`)
+	require.JSONEq(t, `{"id":"id-2","operation":"deleted"}`, payloads[1])
+}
+
+func TestRepository_NotifyChanges(t *testing.T) {
+	database := &fakeDB{}
+	repository := NewRepository(database)
+	database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+		return &fakeRow{values: []any{2}}
+	}
+
+	err := repository.NotifyChanges(context.Background(), []Event{
+		{ID: "id-1", Operation: EventUpdated, Item: &Item{ID: "id-1", Name: "Phone"}},
+		{ID: "id-2", Operation: EventDeleted},
+	})
+
+	require.NoError(t, err)
+	require.Contains(t, normalizeSQL(database.lastQuery), "SELECT pg_notify($1, payload) FROM unnest($2::text[]) AS payload")
+	require.Equal(t, []any{ChangesChannel, []string{`{"id":"id-1","op":"updated"}`, `{"id":"id-2","op":"deleted"}`}}, database.lastArgs)
+}
+
+func TestRepository_Audit(t *testing.T) {
+	t.Run("insert writes every entry in one statement", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{2}}
+		}
+
+		err := repository.InsertAudit(context.Background(), []AuditEntry{
+			{ItemID: "id-1", Action: AuditUpdated, Changes: []FieldChange{{Field: "price", Old: json.RawMessage(`"10.00"`), New: json.RawMessage(`"12.50"`)}}, RequestID: "req-1"},
+			{ItemID: "id-2", Action: AuditDeleted, Changes: []FieldChange{}},
+		})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO item_audit (item_id, action, changes, request_id, actor)")
+		require.Contains(t, normalizeSQL(database.lastQuery), "FROM unnest($1::uuid[], $2::text[], $3::text[], $4::text[], $5::text[]) WITH ORDINALITY")
+		require.Equal(t, []any{
+			[]string{"id-1", "id-2"},
+			[]string{"updated", "deleted"},
+			[]string{`[{"field":"price","old":"10.00","new":"12.50"}]`, `[]`},
+			[]string{"req-1", ""},
+			[]string{"", ""},
+		}, database.lastArgs)
+	})
+
+	t.Run("list is newest first with the total", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		created := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+		changes := []FieldChange{{Field: "name", Old: json.RawMessage(`"Lamp"`), New: json.RawMessage(`"Desk lamp"`)}}
+		database.queryFn = func(context.Context, string, ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{int64(9), "id-1", AuditUpdated, changes, "req-1", "", created, 5},
+			}}, nil
+		}
+
+		entries, total, err := repository.ListAudit(context.Background(), "id-1", 1, 2)
+
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+		require.Equal(t, []AuditEntry{{ID: 9, ItemID: "id-1", Action: AuditUpdated, Changes: changes, RequestID: "req-1", CreatedAt: created}}, entries)
+		require.Contains(t, normalizeSQL(database.lastQuery), "FROM item_audit WHERE item_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3;")
+		require.Equal(t, []any{"id-1", 1, 2}, database.lastArgs)
+	})
+}
+
+func TestRepository_DeleteMany(t *testing.T) {
+	t.Run("single statement returns deleted ids", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{{"id-1"}, {"id-3"}}}, nil
+		}
+
+		deleted, err := repository.DeleteMany(context.Background(), []string{"id-1", "id-2", "id-3"})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"id-1", "id-3"}, deleted)
+		require.Equal(t, "UPDATE items SET deleted_at = now(), updated_at = now() WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL RETURNING id;", normalizeSQL(database.lastQuery))
+		require.Equal(t, []any{[]string{"id-1", "id-2", "id-3"}}, database.lastArgs)
+	})
+
+	t.Run("query error is returned", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		dbErr := errors.New("db failed")
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return nil, dbErr
+		}
+
+		_, err := repository.DeleteMany(context.Background(), []string{"id-1"})
+
+		require.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestRepository_Each(t *testing.T) {
+	t.Run("visits every row in order", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		now := time.Now()
+		rows := &fakeRows{rows: [][]any{
+			{"id-1", "A", "a", nil, nil, "1.00", 1, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+			{"id-2", "B", "b", nil, nil, "2.00", 2, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		}}
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return rows, nil
+		}
+
+		var visited []string
+		err := repository.Each(context.Background(), func(item Item) error {
+			visited = append(visited, item.ID)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"id-1", "id-2"}, visited)
+		require.Contains(t, database.lastQuery, "ORDER BY created_at, id")
+		require.True(t, rows.closed)
+	})
+
+	t.Run("callback error stops the iteration", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		now := time.Now()
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{rows: [][]any{
+				{"id-1", "A", "a", nil, nil, "1.00", 1, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+				{"id-2", "B", "b", nil, nil, "2.00", 2, now, now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+			}}, nil
+		}
+		stopErr := errors.New("stop")
+
+		calls := 0
+		err := repository.Each(context.Background(), func(item Item) error {
+			calls++
+			return stopErr
+		})
+
+		require.ErrorIs(t, err, stopErr)
+		require.Equal(t, 1, calls)
+	})
+}
+
+// generatedRows produce total filas de a una, a medida que se piden, como un cursor de pgx.
+type generatedRows struct {
+	*fakeRows
+	total    int
+	produced int
+	now      time.Time
+}
+
+func (rows *generatedRows) Next() bool {
+	if rows.closed || rows.produced >= rows.total {
+		rows.closed = true
+		return false
+	}
+	rows.produced++
+	return true
+}
+
+func (rows *generatedRows) Scan(dest ...any) error {
+	id := "id-" + strconv.Itoa(rows.produced)
+	return assignValues(dest, []any{id, "Item " + id, id, nil, nil, "1.00", 1, rows.now, rows.now, 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil})
+}
+
+func TestRepository_Export(t *testing.T) {
+	t.Run("streams a large rowset one row at a time", func(t *testing.T) {
+		const total = 100_000
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		rows := &generatedRows{fakeRows: &fakeRows{}, total: total, now: time.Now()}
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return rows, nil
+		}
+
+		visited := 0
+		err := repository.Export(context.Background(), ListFilter{Query: "phone", Status: StatusActive}, func(item Item) error {
+			visited++
+			require.Equal(t, visited, rows.produced, "each item is handed over before the next row is read")
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, total, visited)
+		require.True(t, rows.closed)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "FROM items WHERE deleted_at IS NULL AND (name ILIKE '%' || $1 || '%' OR sku = upper($1) OR barcode = $1) AND status = $2")
+		require.True(t, strings.HasSuffix(query, "ORDER BY coalesce(sku = upper($1) OR barcode = $1, false) DESC, items.created_at DESC, items.id DESC;"),
+			"no LIMIT: the whole filter is read, exact identifier hits first")
+		require.Equal(t, []any{"phone", string(StatusActive)}, database.lastArgs)
+	})
+
+	t.Run("callback error stops the iteration", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+		rows := &generatedRows{fakeRows: &fakeRows{}, total: 10, now: time.Now()}
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return rows, nil
+		}
+		stopErr := errors.New("client gone")
+
+		err := repository.Export(context.Background(), ListFilter{}, func(item Item) error {
+			return stopErr
+		})
+
+		require.ErrorIs(t, err, stopErr)
+		require.Equal(t, 1, rows.produced)
+		require.True(t, rows.closed)
+	})
+}
+
+func TestRepository_Stats(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{42, 5}}
+		}
+
+		stats, err := repository.Stats(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, CatalogStats{Total: 42, OutOfStock: 5}, stats)
+		require.Contains(t, database.lastQuery, "FILTER (WHERE stock = 0)")
+	})
+
+	t.Run("error is returned", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		dbErr := errors.New("db failed")
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: dbErr}
+		}
+
+		_, err := repository.Stats(context.Background())
+
+		require.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestRepository_GetForUpdate(t *testing.T) {
+	t.Run("locks the row", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{"id-1", "Phone", "phone", nil, nil, "10.00", 3, time.Now(), time.Now(), 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+		}
+
+		item, err := repository.GetForUpdate(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Contains(t, database.lastQuery, "FOR UPDATE")
+	})
+
+	t.Run("not found maps to domain error", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{err: pgx.ErrNoRows}
+		}
+
+		_, err := repository.GetForUpdate(context.Background(), "id-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_InTx(t *testing.T) {
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		database := &fakeTxDB{fakeDB: &fakeDB{}}
+		repository := NewRepository(database)
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return deletedItemRow("id-1")
+		}
+
+		err := repository.InTx(context.Background(), func(tx RepositoryAPI) error {
+			_, err := tx.Delete(context.Background(), "id-1", nil)
+			return err
+		})
+
+		require.NoError(t, err)
+		require.True(t, database.tx.committed)
+		require.True(t, database.tx.queryRowCalled, "queries inside fn must go through the transaction")
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		database := &fakeTxDB{fakeDB: &fakeDB{}}
+		repository := NewRepository(database)
+		fnErr := errors.New("fn failed")
+
+		err := repository.InTx(context.Background(), func(tx RepositoryAPI) error {
+			return fnErr
+		})
+
+		require.ErrorIs(t, err, fnErr)
+		require.False(t, database.tx.committed)
+		require.True(t, database.tx.rolledBack)
+	})
+
+	t.Run("begin error", func(t *testing.T) {
+		beginErr := errors.New("begin failed")
+		database := &fakeTxDB{fakeDB: &fakeDB{}, beginErr: beginErr}
+		repository := NewRepository(database)
+
+		err := repository.InTx(context.Background(), func(tx RepositoryAPI) error {
+			t.Fatal("fn must not run")
+			return nil
+		})
+
+		require.ErrorIs(t, err, beginErr)
+	})
+
+	t.Run("database without transactions", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{})
+
+		err := repository.InTx(context.Background(), func(tx RepositoryAPI) error { return nil })
+
+		require.Error(t, err)
+	})
+
+	t.Run("nested call joins the outer transaction", func(t *testing.T) {
+		database := &fakeTxDB{fakeDB: &fakeDB{}}
+		repository := NewRepository(database)
+		innerErr := errors.New("inner failed")
+
+		err := repository.InTx(context.Background(), func(tx RepositoryAPI) error {
+			outer := database.tx
+			// fakeTx no implementa Begin: si el InTx anidado abriera otra transacción, entraría en pánico.
+			err := tx.InTx(context.Background(), func(inner RepositoryAPI) error {
+				require.Same(t, outer, database.tx)
+				return innerErr
+			})
+			return err
+		})
+
+		require.ErrorIs(t, err, innerErr)
+		require.True(t, database.tx.rolledBack)
+	})
+}
+
+func TestRepository_InSnapshot(t *testing.T) {
+	t.Run("page and total share one read-only repeatable-read transaction", func(t *testing.T) {
+		database := &fakeTxDB{fakeDB: &fakeDB{}}
+		repository := NewRepository(database)
+		database.queryFn = func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			return &fakeRows{}, nil
+		}
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &fakeRow{values: []any{3}}
+		}
+
+		var total int
+		err := repository.InSnapshot(context.Background(), func(tx RepositoryAPI) error {
+			if _, err := tx.List(context.Background(), ListFilter{}, 10, 0); err != nil {
+				return err
+			}
+			var err error
+			total, err = tx.Count(context.Background(), ListFilter{})
+			return err
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+		require.Equal(t, &pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, database.txOptions)
+		require.True(t, database.tx.queryCalled, "the page must be read through the transaction")
+		require.True(t, database.tx.queryRowCalled, "the total must be read through the transaction")
+		require.False(t, database.queryCalled)
+		require.False(t, database.queryRowCalled)
+		require.True(t, database.tx.committed)
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		database := &fakeTxDB{fakeDB: &fakeDB{}}
+		repository := NewRepository(database)
+		fnErr := errors.New("fn failed")
+
+		err := repository.InSnapshot(context.Background(), func(tx RepositoryAPI) error {
+			return fnErr
+		})
+
+		require.ErrorIs(t, err, fnErr)
+		require.True(t, database.tx.rolledBack)
+	})
+
+	t.Run("reuses an open transaction", func(t *testing.T) {
+		tx := &fakeTx{fakeDB: &fakeDB{}}
+		repository := NewRepository(tx)
+
+		var inner RepositoryAPI
+		err := repository.InSnapshot(context.Background(), func(snapshot RepositoryAPI) error {
+			inner = snapshot
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Same(t, repository, inner)
+		require.False(t, tx.committed)
+	})
+
+	t.Run("database without transactions", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{})
+
+		err := repository.InSnapshot(context.Background(), func(tx RepositoryAPI) error { return nil })
+
+		require.Error(t, err)
+	})
+}
+
+func TestRepository_QueryBudget(t *testing.T) {
+	t.Run("fails fast when the margin leaves no budget", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database, WithQueryBudget(db.NewQueryBudget(200*time.Millisecond, time.Second)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := repository.GetByID(ctx, "id-1")
+		require.ErrorIs(t, err, ErrorTimeout)
+		_, err = repository.List(ctx, ListFilter{}, 10, 0)
+		require.ErrorIs(t, err, ErrorTimeout)
+		_, err = repository.Delete(ctx, "id-1", nil)
+		require.ErrorIs(t, err, ErrorTimeout)
+
+		require.False(t, database.queryRowCalled)
+		require.False(t, database.queryCalled)
+	})
+
+	t.Run("query context ends before the request deadline", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database, WithQueryBudget(db.NewQueryBudget(200*time.Millisecond, time.Minute)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		requestDeadline, _ := ctx.Deadline()
+
+		var queryDeadline time.Time
+		database.queryRowFn = func(ctx context.Context, sql string, args ...any) pgx.Row {
+			queryDeadline, _ = ctx.Deadline()
+			return deletedItemRow("id-1")
+		}
+
+		_, err := repository.Delete(ctx, "id-1", nil)
+		require.NoError(t, err)
+		require.Equal(t, requestDeadline.Add(-200*time.Millisecond), queryDeadline)
+	})
+}
+
+// deletedItemRow es la fila que devuelve Delete: el item completo con deleted_at.
+func deletedItemRow(id string) *fakeRow {
+	now := time.Now()
+	return &fakeRow{values: []any{id, "Phone", "phone", nil, nil, "10.00", 1, now, now, 1, now, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+}
+
+type fakeDB struct {
+	queryRowFn func(ctx context.Context, sql string, args ...any) pgx.Row
+	queryFn    func(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+
+	lastQuery      string
+	lastArgs       []any
+	queryRowCalled bool
+	queryCalled    bool
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.queryRowCalled = true
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.queryRowFn == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.queryRowFn(ctx, sql, args...)
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.queryCalled = true
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.queryFn == nil {
+		return nil, errors.New("unexpected Query call")
+	}
+	return db.queryFn(ctx, sql, args...)
+}
+
+// fakeTxDB agrega Begin y BeginTx a fakeDB; las queries de la transacción se registran en tx.
+type fakeTxDB struct {
+	*fakeDB
+	beginErr  error
+	tx        *fakeTx
+	txOptions *pgx.TxOptions
+}
+
+func (database *fakeTxDB) BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error) {
+	database.txOptions = &options
+	return database.Begin(ctx)
+}
+
+func (database *fakeTxDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	if database.beginErr != nil {
+		return nil, database.beginErr
+	}
+	database.tx = &fakeTx{fakeDB: &fakeDB{queryRowFn: database.queryRowFn, queryFn: database.queryFn}}
+	return database.tx, nil
+}
+
+// fakeTx implementa lo que usa el repositorio de pgx.Tx; el resto de la interfaz queda sin implementar.
+type fakeTx struct {
+	pgx.Tx
+	*fakeDB
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.fakeDB.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.fakeDB.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	return assignValues(dest, row.values)
+}
+
+type fakeRows struct {
+	rows    [][]any
+	idx     int
+	closed  bool
+	err     error
+	scanErr error
+}
+
+func (rows *fakeRows) Close() {
+	rows.closed = true
+}
+
+func (rows *fakeRows) Err() error {
+	return rows.err
+}
+
+func (rows *fakeRows) CommandTag() pgconn.CommandTag {
+	return pgconn.CommandTag{}
+}
+
+func (rows *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	return nil
+}
+
+func (rows *fakeRows) Next() bool {
+	if rows.closed {
+		return false
+	}
+	if rows.idx >= len(rows.rows) {
+		rows.closed = true
+		return false
+	}
+	rows.idx++
+	return true
 }
 
 func (rows *fakeRows) Scan(dest ...any) error {
@@ -606,6 +3450,9 @@ func assignValues(dest []any, values []any) error {
 }
 
 func assignValue(dest any, value any) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
 	destValue := reflect.ValueOf(dest)
 	if destValue.Kind() != reflect.Ptr {
 		return fmt.Errorf("dest is not pointer")
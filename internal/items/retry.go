@@ -0,0 +1,737 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Valores por defecto del reintento: hasta 2 reintentos con backoff chico y jitter.
+const (
+	defaultMaxRetries  = 2
+	defaultBaseBackoff = 25 * time.Millisecond
+)
+
+// RetryingRepository decora un RepositoryAPI reintentando errores transitorios de la base
+// (failover, conexión cortada, serialization failure/deadlock).
+// Las lecturas se reintentan ante cualquier error transitorio; las escrituras solo si
+// pgconn.SafeToRetry garantiza que la sentencia no llegó a ejecutarse.
+type RetryingRepository struct {
+	inner       RepositoryAPI
+	maxRetries  int
+	baseBackoff time.Duration
+	onRetry     func(operation string)
+	sleep       func(ctx context.Context, duration time.Duration) error
+}
+
+// RetryOption configura el RetryingRepository.
+type RetryOption func(*RetryingRepository)
+
+// WithMaxRetries cambia la cantidad máxima de reintentos (además del intento original).
+func WithMaxRetries(maxRetries int) RetryOption {
+	return func(repository *RetryingRepository) {
+		repository.maxRetries = maxRetries
+	}
+}
+
+// WithRetryHook registra una función que se llama antes de cada reintento (por ejemplo, para métricas).
+func WithRetryHook(onRetry func(operation string)) RetryOption {
+	return func(repository *RetryingRepository) {
+		repository.onRetry = onRetry
+	}
+}
+
+// NewRetryingRepository envuelve inner con la política de reintentos.
+func NewRetryingRepository(inner RepositoryAPI, options ...RetryOption) *RetryingRepository {
+	repository := &RetryingRepository{
+		inner:       inner,
+		maxRetries:  defaultMaxRetries,
+		baseBackoff: defaultBaseBackoff,
+		onRetry:     func(string) {},
+		sleep:       sleepContext,
+	}
+	for _, option := range options {
+		option(repository)
+	}
+	return repository
+}
+
+// Insert implementa RepositoryAPI.
+func (repository *RetryingRepository) Insert(ctx context.Context, in CreateItemInput) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "insert", isSafeToRetry, func() error {
+		var err error
+		item, err = repository.inner.Insert(ctx, in)
+		return err
+	})
+	return item, err
+}
+
+// List implementa RepositoryAPI.
+func (repository *RetryingRepository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]Item, error) {
+	var list []Item
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, err = repository.inner.List(ctx, filter, limit, offset)
+		return err
+	})
+	return list, err
+}
+
+// ListAfter implementa RepositoryAPI.
+func (repository *RetryingRepository) ListAfter(ctx context.Context, filter ListFilter, after CreatedAtID, limit int) ([]Item, error) {
+	var list []Item
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, err = repository.inner.ListAfter(ctx, filter, after, limit)
+		return err
+	})
+	return list, err
+}
+
+// ListWithTotal implementa RepositoryAPI.
+func (repository *RetryingRepository) ListWithTotal(ctx context.Context, filter ListFilter, limit, offset int) ([]Item, int, error) {
+	var (
+		list  []Item
+		total int
+	)
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, total, err = repository.inner.ListWithTotal(ctx, filter, limit, offset)
+		return err
+	})
+	return list, total, err
+}
+
+// Related implementa RepositoryAPI.
+func (repository *RetryingRepository) Related(ctx context.Context, item Item, threshold float64, limit int) ([]Item, error) {
+	var list []Item
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, err = repository.inner.Related(ctx, item, threshold, limit)
+		return err
+	})
+	return list, err
+}
+
+// Suggest implementa RepositoryAPI.
+func (repository *RetryingRepository) Suggest(ctx context.Context, prefix string, threshold float64, limit int) ([]Suggestion, error) {
+	var list []Suggestion
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, err = repository.inner.Suggest(ctx, prefix, threshold, limit)
+		return err
+	})
+	return list, err
+}
+
+// RestockNeeded implementa RepositoryAPI.
+func (repository *RetryingRepository) RestockNeeded(ctx context.Context, limit, offset int) ([]Item, int, error) {
+	var (
+		list  []Item
+		total int
+	)
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, total, err = repository.inner.RestockNeeded(ctx, limit, offset)
+		return err
+	})
+	return list, total, err
+}
+
+// Changes implementa RepositoryAPI.
+func (repository *RetryingRepository) Changes(ctx context.Context, after UpdatedAtID, until time.Time, limit int) ([]Item, error) {
+	var list []Item
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		list, err = repository.inner.Changes(ctx, after, until, limit)
+		return err
+	})
+	return list, err
+}
+
+// ClosestName implementa RepositoryAPI.
+func (repository *RetryingRepository) ClosestName(ctx context.Context, query string, threshold float64) (string, error) {
+	var name string
+	err := repository.do(ctx, "get", isTransient, func() error {
+		var err error
+		name, err = repository.inner.ClosestName(ctx, query, threshold)
+		return err
+	})
+	return name, err
+}
+
+// Count implementa RepositoryAPI.
+func (repository *RetryingRepository) Count(ctx context.Context, filter ListFilter) (int, error) {
+	var total int
+	err := repository.do(ctx, "count", isTransient, func() error {
+		var err error
+		total, err = repository.inner.Count(ctx, filter)
+		return err
+	})
+	return total, err
+}
+
+// Export implementa RepositoryAPI. No se reintenta: fn ya pudo haber mandado parte de los items.
+func (repository *RetryingRepository) Export(ctx context.Context, filter ListFilter, fn func(Item) error) error {
+	return repository.inner.Export(ctx, filter, fn)
+}
+
+// EstimateCount implementa RepositoryAPI.
+func (repository *RetryingRepository) EstimateCount(ctx context.Context, filter ListFilter) (int, bool, error) {
+	var (
+		total int
+		ok    bool
+	)
+	err := repository.do(ctx, "count", isTransient, func() error {
+		var err error
+		total, ok, err = repository.inner.EstimateCount(ctx, filter)
+		return err
+	})
+	return total, ok, err
+}
+
+// CollectionVersion implementa RepositoryAPI.
+func (repository *RetryingRepository) CollectionVersion(ctx context.Context) (CollectionVersion, error) {
+	var version CollectionVersion
+	err := repository.do(ctx, "count", isTransient, func() error {
+		var err error
+		version, err = repository.inner.CollectionVersion(ctx)
+		return err
+	})
+	return version, err
+}
+
+// GetByID implementa RepositoryAPI.
+func (repository *RetryingRepository) GetByID(ctx context.Context, id string) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "get", isTransient, func() error {
+		var err error
+		item, err = repository.inner.GetByID(ctx, id)
+		return err
+	})
+	return item, err
+}
+
+// GetByBarcode implementa RepositoryAPI.
+func (repository *RetryingRepository) GetByBarcode(ctx context.Context, barcode string) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "get", isTransient, func() error {
+		var err error
+		item, err = repository.inner.GetByBarcode(ctx, barcode)
+		return err
+	})
+	return item, err
+}
+
+// GetBySKU implementa RepositoryAPI.
+func (repository *RetryingRepository) GetBySKU(ctx context.Context, sku string) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "get", isTransient, func() error {
+		var err error
+		item, err = repository.inner.GetBySKU(ctx, sku)
+		return err
+	})
+	return item, err
+}
+
+// GetBySlug implementa RepositoryAPI.
+func (repository *RetryingRepository) GetBySlug(ctx context.Context, slug string) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "get", isTransient, func() error {
+		var err error
+		item, err = repository.inner.GetBySlug(ctx, slug)
+		return err
+	})
+	return item, err
+}
+
+// TakenSlugs implementa RepositoryAPI.
+func (repository *RetryingRepository) TakenSlugs(ctx context.Context, base, exceptID string) ([]string, error) {
+	var taken []string
+	err := repository.do(ctx, "taken_slugs", isTransient, func() error {
+		var err error
+		taken, err = repository.inner.TakenSlugs(ctx, base, exceptID)
+		return err
+	})
+	return taken, err
+}
+
+// CheckUniqueKeys implementa RepositoryAPI.
+func (repository *RetryingRepository) CheckUniqueKeys(ctx context.Context, input CreateItemInput) error {
+	return repository.do(ctx, "check_unique_keys", isTransient, func() error {
+		return repository.inner.CheckUniqueKeys(ctx, input)
+	})
+}
+
+// Replace implementa RepositoryAPI.
+func (repository *RetryingRepository) Replace(ctx context.Context, id string, in ReplaceItemInput) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "update", isSafeToRetry, func() error {
+		var err error
+		item, err = repository.inner.Replace(ctx, id, in)
+		return err
+	})
+	return item, err
+}
+
+// Update implementa RepositoryAPI.
+func (repository *RetryingRepository) Update(ctx context.Context, id string, in UpdateItemInput) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "update", isSafeToRetry, func() error {
+		var err error
+		item, err = repository.inner.Update(ctx, id, in)
+		return err
+	})
+	return item, err
+}
+
+// Delete implementa RepositoryAPI.
+func (repository *RetryingRepository) Delete(ctx context.Context, id string, ifVersion *int) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "delete", isSafeToRetry, func() error {
+		var err error
+		item, err = repository.inner.Delete(ctx, id, ifVersion)
+		return err
+	})
+	return item, err
+}
+
+// Purge implementa RepositoryAPI.
+func (repository *RetryingRepository) Purge(ctx context.Context, id string) error {
+	return repository.do(ctx, "delete", isSafeToRetry, func() error {
+		return repository.inner.Purge(ctx, id)
+	})
+}
+
+// DeleteMany implementa RepositoryAPI.
+func (repository *RetryingRepository) DeleteMany(ctx context.Context, ids []string) ([]string, error) {
+	var deleted []string
+	err := repository.do(ctx, "delete", isSafeToRetry, func() error {
+		var err error
+		deleted, err = repository.inner.DeleteMany(ctx, ids)
+		return err
+	})
+	return deleted, err
+}
+
+// GetForUpdate implementa RepositoryAPI. Como toma un lock, se trata igual que una escritura.
+func (repository *RetryingRepository) GetForUpdate(ctx context.Context, id string) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "get_for_update", isSafeToRetry, func() error {
+		var err error
+		item, err = repository.inner.GetForUpdate(ctx, id)
+		return err
+	})
+	return item, err
+}
+
+// ReservedQuantity implementa RepositoryAPI.
+func (repository *RetryingRepository) ReservedQuantity(ctx context.Context, itemID string) (int, error) {
+	var reserved int
+	err := repository.do(ctx, "count", isTransient, func() error {
+		var err error
+		reserved, err = repository.inner.ReservedQuantity(ctx, itemID)
+		return err
+	})
+	return reserved, err
+}
+
+// InsertReservation implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertReservation(ctx context.Context, itemID string, quantity int, ttl time.Duration) (Reservation, error) {
+	var reservation Reservation
+	err := repository.do(ctx, "insert", isSafeToRetry, func() error {
+		var err error
+		reservation, err = repository.inner.InsertReservation(ctx, itemID, quantity, ttl)
+		return err
+	})
+	return reservation, err
+}
+
+// DeleteReservation implementa RepositoryAPI.
+func (repository *RetryingRepository) DeleteReservation(ctx context.Context, itemID, reservationID string) error {
+	return repository.do(ctx, "delete", isSafeToRetry, func() error {
+		return repository.inner.DeleteReservation(ctx, itemID, reservationID)
+	})
+}
+
+// DeleteExpiredReservations implementa RepositoryAPI.
+func (repository *RetryingRepository) DeleteExpiredReservations(ctx context.Context) (int, []string, error) {
+	var (
+		deleted int
+		itemIDs []string
+	)
+	err := repository.do(ctx, "delete", isSafeToRetry, func() error {
+		var err error
+		deleted, itemIDs, err = repository.inner.DeleteExpiredReservations(ctx)
+		return err
+	})
+	return deleted, itemIDs, err
+}
+
+// InsertStockMovement implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertStockMovement(ctx context.Context, movement StockMovement) error {
+	return repository.do(ctx, "insert", isSafeToRetry, func() error {
+		return repository.inner.InsertStockMovement(ctx, movement)
+	})
+}
+
+// ListStockMovements implementa RepositoryAPI.
+func (repository *RetryingRepository) ListStockMovements(ctx context.Context, itemID string, limit, offset int) ([]StockMovement, int, error) {
+	var (
+		movements []StockMovement
+		total     int
+	)
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		movements, total, err = repository.inner.ListStockMovements(ctx, itemID, limit, offset)
+		return err
+	})
+	return movements, total, err
+}
+
+// PreviewPriceAdjustment implementa RepositoryAPI.
+func (repository *RetryingRepository) PreviewPriceAdjustment(ctx context.Context, filter ListFilter, adjustment PriceAdjustment, sampleSize int) (PriceAdjustmentPreview, error) {
+	var preview PriceAdjustmentPreview
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		preview, err = repository.inner.PreviewPriceAdjustment(ctx, filter, adjustment, sampleSize)
+		return err
+	})
+	return preview, err
+}
+
+// AdjustPrices implementa RepositoryAPI.
+func (repository *RetryingRepository) AdjustPrices(ctx context.Context, filter ListFilter, adjustment PriceAdjustment, reason, requestID string) ([]string, error) {
+	var adjusted []string
+	err := repository.do(ctx, "update", isSafeToRetry, func() error {
+		var err error
+		adjusted, err = repository.inner.AdjustPrices(ctx, filter, adjustment, reason, requestID)
+		return err
+	})
+	return adjusted, err
+}
+
+// InsertPriceChange implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertPriceChange(ctx context.Context, entry PriceHistoryEntry) error {
+	return repository.do(ctx, "insert", isSafeToRetry, func() error {
+		return repository.inner.InsertPriceChange(ctx, entry)
+	})
+}
+
+// InsertOutbox implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertOutbox(ctx context.Context, events []Event) error {
+	return repository.do(ctx, "insert", isSafeToRetry, func() error {
+		return repository.inner.InsertOutbox(ctx, events)
+	})
+}
+
+// NotifyChanges implementa RepositoryAPI.
+func (repository *RetryingRepository) NotifyChanges(ctx context.Context, events []Event) error {
+	return repository.do(ctx, "insert", isSafeToRetry, func() error {
+		return repository.inner.NotifyChanges(ctx, events)
+	})
+}
+
+// InsertAudit implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertAudit(ctx context.Context, entries []AuditEntry) error {
+	return repository.do(ctx, "insert", isSafeToRetry, func() error {
+		return repository.inner.InsertAudit(ctx, entries)
+	})
+}
+
+// ListAudit implementa RepositoryAPI.
+func (repository *RetryingRepository) ListAudit(ctx context.Context, itemID string, limit, offset int) ([]AuditEntry, int, error) {
+	var (
+		entries []AuditEntry
+		total   int
+	)
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		entries, total, err = repository.inner.ListAudit(ctx, itemID, limit, offset)
+		return err
+	})
+	return entries, total, err
+}
+
+// ListPriceHistory implementa RepositoryAPI.
+func (repository *RetryingRepository) ListPriceHistory(ctx context.Context, itemID string, filter PriceHistoryFilter, limit, offset int) ([]PriceHistoryEntry, int, error) {
+	var (
+		entries []PriceHistoryEntry
+		total   int
+	)
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		entries, total, err = repository.inner.ListPriceHistory(ctx, itemID, filter, limit, offset)
+		return err
+	})
+	return entries, total, err
+}
+
+// ListVariants implementa RepositoryAPI.
+func (repository *RetryingRepository) ListVariants(ctx context.Context, itemID string) ([]Variant, error) {
+	var variants []Variant
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		variants, err = repository.inner.ListVariants(ctx, itemID)
+		return err
+	})
+	return variants, err
+}
+
+// InsertVariant implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertVariant(ctx context.Context, itemID string, in CreateVariantInput) (Variant, error) {
+	var variant Variant
+	err := repository.do(ctx, "insert", isSafeToRetry, func() error {
+		var err error
+		variant, err = repository.inner.InsertVariant(ctx, itemID, in)
+		return err
+	})
+	return variant, err
+}
+
+// UpdateVariant implementa RepositoryAPI.
+func (repository *RetryingRepository) UpdateVariant(ctx context.Context, itemID, variantID string, in UpdateVariantInput) (Variant, error) {
+	var variant Variant
+	err := repository.do(ctx, "update", isSafeToRetry, func() error {
+		var err error
+		variant, err = repository.inner.UpdateVariant(ctx, itemID, variantID, in)
+		return err
+	})
+	return variant, err
+}
+
+// DeleteVariant implementa RepositoryAPI.
+func (repository *RetryingRepository) DeleteVariant(ctx context.Context, itemID, variantID string) error {
+	return repository.do(ctx, "delete", isSafeToRetry, func() error {
+		return repository.inner.DeleteVariant(ctx, itemID, variantID)
+	})
+}
+
+// VariantStock implementa RepositoryAPI.
+func (repository *RetryingRepository) VariantStock(ctx context.Context, itemID string) (count, total int, err error) {
+	err = repository.do(ctx, "count", isTransient, func() error {
+		var err error
+		count, total, err = repository.inner.VariantStock(ctx, itemID)
+		return err
+	})
+	return count, total, err
+}
+
+// ListPriceSchedules implementa RepositoryAPI.
+func (repository *RetryingRepository) ListPriceSchedules(ctx context.Context, itemID string) ([]PriceSchedule, error) {
+	var schedules []PriceSchedule
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		schedules, err = repository.inner.ListPriceSchedules(ctx, itemID)
+		return err
+	})
+	return schedules, err
+}
+
+// InsertPriceSchedule implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertPriceSchedule(ctx context.Context, itemID string, in CreatePriceScheduleInput) (PriceSchedule, error) {
+	var schedule PriceSchedule
+	err := repository.do(ctx, "insert", isSafeToRetry, func() error {
+		var err error
+		schedule, err = repository.inner.InsertPriceSchedule(ctx, itemID, in)
+		return err
+	})
+	return schedule, err
+}
+
+// DeletePriceSchedule implementa RepositoryAPI.
+func (repository *RetryingRepository) DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error {
+	return repository.do(ctx, "delete", isSafeToRetry, func() error {
+		return repository.inner.DeletePriceSchedule(ctx, itemID, scheduleID)
+	})
+}
+
+// DuePriceSchedules implementa RepositoryAPI.
+func (repository *RetryingRepository) DuePriceSchedules(ctx context.Context, limit int) ([]PriceSchedule, error) {
+	var schedules []PriceSchedule
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		schedules, err = repository.inner.DuePriceSchedules(ctx, limit)
+		return err
+	})
+	return schedules, err
+}
+
+// MarkPriceScheduleApplied implementa RepositoryAPI.
+func (repository *RetryingRepository) MarkPriceScheduleApplied(ctx context.Context, scheduleID string) (PriceSchedule, error) {
+	var schedule PriceSchedule
+	err := repository.do(ctx, "update", isSafeToRetry, func() error {
+		var err error
+		schedule, err = repository.inner.MarkPriceScheduleApplied(ctx, scheduleID)
+		return err
+	})
+	return schedule, err
+}
+
+// MarkPriceScheduleFailed implementa RepositoryAPI.
+func (repository *RetryingRepository) MarkPriceScheduleFailed(ctx context.Context, scheduleID, reason string) error {
+	return repository.do(ctx, "update", isSafeToRetry, func() error {
+		return repository.inner.MarkPriceScheduleFailed(ctx, scheduleID, reason)
+	})
+}
+
+// GetByExternalRef implementa RepositoryAPI.
+func (repository *RetryingRepository) GetByExternalRef(ctx context.Context, system, externalID string) (Item, error) {
+	var item Item
+	err := repository.do(ctx, "get", isTransient, func() error {
+		var err error
+		item, err = repository.inner.GetByExternalRef(ctx, system, externalID)
+		return err
+	})
+	return item, err
+}
+
+// InsertExternalRef implementa RepositoryAPI.
+func (repository *RetryingRepository) InsertExternalRef(ctx context.Context, itemID string, in ExternalRefInput) (ExternalRef, error) {
+	var ref ExternalRef
+	err := repository.do(ctx, "insert", isSafeToRetry, func() error {
+		var err error
+		ref, err = repository.inner.InsertExternalRef(ctx, itemID, in)
+		return err
+	})
+	return ref, err
+}
+
+// DeleteExternalRef implementa RepositoryAPI.
+func (repository *RetryingRepository) DeleteExternalRef(ctx context.Context, itemID, system, externalID string) error {
+	return repository.do(ctx, "delete", isSafeToRetry, func() error {
+		return repository.inner.DeleteExternalRef(ctx, itemID, system, externalID)
+	})
+}
+
+// ListTranslations implementa RepositoryAPI.
+func (repository *RetryingRepository) ListTranslations(ctx context.Context, itemID string) ([]Translation, error) {
+	var translations []Translation
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		translations, err = repository.inner.ListTranslations(ctx, itemID)
+		return err
+	})
+	return translations, err
+}
+
+// TranslationsFor implementa RepositoryAPI.
+func (repository *RetryingRepository) TranslationsFor(ctx context.Context, locale string, itemIDs []string) (map[string]Translation, error) {
+	var translations map[string]Translation
+	err := repository.do(ctx, "list", isTransient, func() error {
+		var err error
+		translations, err = repository.inner.TranslationsFor(ctx, locale, itemIDs)
+		return err
+	})
+	return translations, err
+}
+
+// UpsertTranslation implementa RepositoryAPI. El upsert deja el mismo resultado si se repite,
+// así que se reintenta como una lectura.
+func (repository *RetryingRepository) UpsertTranslation(ctx context.Context, itemID, locale string, in TranslationInput) (Translation, error) {
+	var translation Translation
+	err := repository.do(ctx, "update", isTransient, func() error {
+		var err error
+		translation, err = repository.inner.UpsertTranslation(ctx, itemID, locale, in)
+		return err
+	})
+	return translation, err
+}
+
+// CopyInsert implementa RepositoryAPI. No se reintenta: un COPY cortado a la mitad no deja filas,
+// pero quien lo llama carga de a lotes grandes y decide si vuelve a empezar.
+func (repository *RetryingRepository) CopyInsert(ctx context.Context, inputs []CreateItemInput) (int64, error) {
+	return repository.inner.CopyInsert(ctx, inputs)
+}
+
+// UpsertBySKU implementa RepositoryAPI. Como Insert, solo se reintenta si la sentencia no llegó a
+// la base: repetirla convertiría un alta en una actualización.
+func (repository *RetryingRepository) UpsertBySKU(ctx context.Context, in CreateItemInput) (Item, bool, error) {
+	var item Item
+	var created bool
+	err := repository.do(ctx, "insert", isSafeToRetry, func() error {
+		var err error
+		item, created, err = repository.inner.UpsertBySKU(ctx, in)
+		return err
+	})
+	return item, created, err
+}
+
+// InTx implementa RepositoryAPI. La transacción no se reintenta: fn puede tener efectos
+// que no conviene repetir, y adentro de la transacción se usa el repositorio sin decorar.
+func (repository *RetryingRepository) InTx(ctx context.Context, fn func(tx RepositoryAPI) error) error {
+	return repository.inner.InTx(ctx, fn)
+}
+
+// InSnapshot implementa RepositoryAPI. A diferencia de InTx se reintenta entera: es de solo lectura,
+// así que repetir fn no tiene efectos.
+func (repository *RetryingRepository) InSnapshot(ctx context.Context, fn func(tx RepositoryAPI) error) error {
+	return repository.do(ctx, "snapshot", isTransient, func() error {
+		return repository.inner.InSnapshot(ctx, fn)
+	})
+}
+
+// do ejecuta operation y la reintenta mientras retryable lo permita y quede presupuesto en ctx.
+func (repository *RetryingRepository) do(ctx context.Context, name string, retryable func(error) bool, operation func() error) error {
+	err := operation()
+	for attempt := 0; attempt < repository.maxRetries && err != nil && retryable(err); attempt++ {
+		backoff := repository.backoff(attempt)
+		// Si el backoff no entra en lo que queda del request, devolvemos el error original.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+			return err
+		}
+		if sleepErr := repository.sleep(ctx, backoff); sleepErr != nil {
+			return err
+		}
+		repository.onRetry(name)
+		err = operation()
+	}
+	return err
+}
+
+// backoff devuelve un valor al azar entre la mitad y el total de base*2^attempt,
+// para que varias réplicas no reintenten todas al mismo tiempo.
+func (repository *RetryingRepository) backoff(attempt int) time.Duration {
+	ceiling := repository.baseBackoff << attempt
+	if ceiling <= 0 {
+		return 0
+	}
+	half := ceiling / 2
+	return half + rand.N(half+1)
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isSafeToRetry indica que el error ocurrió antes de enviar la sentencia al servidor.
+// Es la única condición en la que una escritura se puede reintentar sin riesgo de duplicarla.
+func isSafeToRetry(err error) bool {
+	return pgconn.SafeToRetry(err)
+}
+
+// isTransient clasifica errores que tiene sentido reintentar en una lectura.
+func isTransient(err error) bool {
+	if isSafeToRetry(err) {
+		return true
+	}
+	var postgresError *pgconn.PgError
+	if errors.As(err, &postgresError) {
+		// 40001 serialization_failure, 40P01 deadlock_detected.
+		return postgresError.Code == "40001" || postgresError.Code == "40P01"
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
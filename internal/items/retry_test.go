@@ -0,0 +1,151 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// safeToRetryError simula un error de pgconn donde la sentencia nunca llegó al servidor.
+type safeToRetryError struct{}
+
+func (safeToRetryError) Error() string     { return "connection refused" }
+func (safeToRetryError) SafeToRetry() bool { return true }
+
+// sequenceRow devuelve un error distinto en cada llamada a QueryRow y, al agotarse, la fila final.
+func sequenceRow(calls *int, errs []error, final *fakeRow) func(ctx context.Context, sql string, args ...any) pgx.Row {
+	return func(ctx context.Context, sql string, args ...any) pgx.Row {
+		defer func() { *calls++ }()
+		if *calls < len(errs) {
+			return &fakeRow{err: errs[*calls]}
+		}
+		return final
+	}
+}
+
+func newTestRetryingRepository(database *fakeDB, retries *[]string) *RetryingRepository {
+	repository := NewRetryingRepository(NewRepository(database), WithRetryHook(func(operation string) {
+		*retries = append(*retries, operation)
+	}))
+	repository.sleep = func(ctx context.Context, duration time.Duration) error { return nil }
+	return repository
+}
+
+func TestRetryingRepository_Reads(t *testing.T) {
+	itemRow := &fakeRow{values: []any{"id-1", "Phone", "phone", nil, nil, "10.00", 1, time.Now(), time.Now(), 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+
+	tests := []struct {
+		name        string
+		errs        []error
+		wantErr     bool
+		wantCalls   int
+		wantRetries int
+	}{
+		{"connection reset then success", []error{syscall.ECONNRESET}, false, 2, 1},
+		{"serialization failure then success", []error{&pgconn.PgError{Code: "40001"}}, false, 2, 1},
+		{"deadlock twice then success", []error{&pgconn.PgError{Code: "40P01"}, &pgconn.PgError{Code: "40P01"}}, false, 3, 2},
+		{"safe to retry then success", []error{safeToRetryError{}}, false, 2, 1},
+		{"gives up after two retries", []error{syscall.ECONNRESET, syscall.ECONNRESET, syscall.ECONNRESET}, true, 3, 2},
+		{"not found is not retried", []error{pgx.ErrNoRows}, true, 1, 0},
+		{"unique violation is not retried", []error{&pgconn.PgError{Code: "23505"}}, true, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			calls := 0
+			database.queryRowFn = sequenceRow(&calls, tt.errs, itemRow)
+			var retries []string
+			repository := newTestRetryingRepository(database, &retries)
+
+			item, err := repository.GetByID(context.Background(), "id-1")
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "id-1", item.ID)
+			}
+			require.Equal(t, tt.wantCalls, calls)
+			require.Len(t, retries, tt.wantRetries)
+		})
+	}
+}
+
+func TestRetryingRepository_Writes(t *testing.T) {
+	t.Run("retried when the statement was never sent", func(t *testing.T) {
+		database := &fakeDB{}
+		calls := 0
+		database.queryRowFn = sequenceRow(&calls, []error{safeToRetryError{}}, deletedItemRow("id-1"))
+		var retries []string
+		repository := newTestRetryingRepository(database, &retries)
+
+		_, err := repository.Delete(context.Background(), "id-1", nil)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+		require.Equal(t, []string{"delete"}, retries)
+	})
+
+	t.Run("not retried on errors that may have executed", func(t *testing.T) {
+		database := &fakeDB{}
+		calls := 0
+		database.queryRowFn = sequenceRow(&calls, []error{syscall.ECONNRESET}, deletedItemRow("id-1"))
+		var retries []string
+		repository := newTestRetryingRepository(database, &retries)
+
+		_, err := repository.Delete(context.Background(), "id-1", nil)
+
+		require.ErrorIs(t, err, syscall.ECONNRESET)
+		require.Equal(t, 1, calls)
+		require.Empty(t, retries)
+	})
+}
+
+func TestRetryingRepository_Snapshot(t *testing.T) {
+	database := &fakeTxDB{fakeDB: &fakeDB{}}
+	calls := 0
+	database.queryRowFn = sequenceRow(&calls, []error{&pgconn.PgError{Code: "40001"}}, &fakeRow{values: []any{3}})
+	var retries []string
+	repository := NewRetryingRepository(NewRepository(database), WithRetryHook(func(operation string) {
+		retries = append(retries, operation)
+	}))
+	repository.sleep = func(ctx context.Context, duration time.Duration) error { return nil }
+
+	var total int
+	err := repository.InSnapshot(context.Background(), func(tx RepositoryAPI) error {
+		var err error
+		total, err = tx.Count(context.Background(), ListFilter{})
+		return err
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.Equal(t, 2, calls)
+	require.Equal(t, []string{"snapshot"}, retries)
+}
+
+func TestRetryingRepository_RespectsContextBudget(t *testing.T) {
+	database := &fakeDB{}
+	calls := 0
+	itemRow := &fakeRow{values: []any{"id-1", "Phone", "phone", nil, nil, "10.00", 1, time.Now(), time.Now(), 1, nil, 0, false, false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}}
+	database.queryRowFn = sequenceRow(&calls, []error{syscall.ECONNRESET}, itemRow)
+	var retries []string
+	repository := newTestRetryingRepository(database, &retries)
+	repository.baseBackoff = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := repository.GetByID(ctx, "id-1")
+
+	require.True(t, errors.Is(err, syscall.ECONNRESET))
+	require.Equal(t, 1, calls)
+	require.Empty(t, retries)
+}
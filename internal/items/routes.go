@@ -1,6 +1,9 @@
 package items
 
-import "github.com/go-chi/chi/v5"
+import (
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+)
 
 // RegisterRoutes registra rutas de items en el router.
 // Mantener esto separado hace que main.go no crezca sin control.
@@ -8,8 +11,57 @@ func RegisterRoutes(route chi.Router, handler *Handler) {
 	route.Route("/items", func(route chi.Router) {
 		route.Post("/", handler.Create)
 		route.Get("/", handler.List)
+		// HEAD corre el mismo handler que GET (mismo status y headers) sin mandar el body.
+		route.Head("/", httpx.Head(handler.List))
+		route.Get("/count", handler.Count)
+		route.Get("/export", handler.Export)
+		route.Get("/trash", handler.Trash)
+		route.Get("/expiring", handler.Expiring)
+		route.Get("/restock-needed", handler.RestockNeeded)
+		route.Get("/changes", handler.Changes)
+		route.Get("/suggest", handler.Suggest)
+		route.Post("/bulk-delete", handler.BulkDelete)
+		route.Patch("/bulk", handler.BulkUpdate)
+		route.Post("/price-adjustments", handler.AdjustPrices)
+		route.Get("/slug/{slug}", handler.GetBySlug)
+		route.Get("/sku/{sku}", handler.GetBySKU)
+		route.Get("/barcode/{code}", handler.GetByBarcode)
+		route.Get("/by-ref/{system}/{external_id}", handler.GetByExternalRef)
 		route.Get("/{id}", handler.GetByID)
+		route.Head("/{id}", httpx.Head(handler.GetByID))
+		route.Get("/{id}/related", handler.Related)
+		route.Post("/{id}/duplicate", handler.Duplicate)
+		route.Post("/{id}/publish", handler.Publish)
+		route.Post("/{id}/unpublish", handler.Unpublish)
+		route.Put("/{id}", handler.Replace)
 		route.Patch("/{id}", handler.Patch)
 		route.Delete("/{id}", handler.Delete)
+		route.Delete("/{id}/purge", handler.Purge)
+		route.Post("/{id}/stock-adjustments", handler.AdjustStock)
+		route.Get("/{id}/stock-movements", handler.StockMovements)
+		route.Get("/{id}/price-history", handler.PriceHistory)
+		route.Get("/{id}/audit", handler.Audit)
+		route.Post("/{id}/reservations", handler.Reserve)
+		route.Delete("/{id}/reservations/{rid}", handler.Release)
+		route.Get("/{id}/variants", handler.Variants)
+		route.Post("/{id}/variants", handler.CreateVariant)
+		route.Patch("/{id}/variants/{vid}", handler.UpdateVariant)
+		route.Delete("/{id}/variants/{vid}", handler.DeleteVariant)
+		route.Get("/{id}/price-schedules", handler.PriceSchedules)
+		route.Post("/{id}/price-schedules", handler.CreatePriceSchedule)
+		route.Delete("/{id}/price-schedules/{sid}", handler.DeletePriceSchedule)
+		route.Post("/{id}/refs", handler.AddExternalRef)
+		route.Delete("/{id}/refs/{system}/{external_id}", handler.RemoveExternalRef)
+		route.Get("/{id}/translations", handler.Translations)
+		route.Put("/{id}/translations/{locale}", handler.PutTranslation)
 	})
 }
+
+// EventsPath es la ruta del stream de eventos. main la excluye del timeout global.
+const EventsPath = "/items/events"
+
+// RegisterStreamRoutes registra los streams de items. Van aparte de RegisterRoutes porque una
+// conexión que dura horas no puede ocupar un lugar del limiter de concurrencia (ni toca la DB).
+func RegisterStreamRoutes(route chi.Router, handler *Handler) {
+	route.Get(EventsPath, handler.Events)
+}
@@ -4,35 +4,282 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Lelo88/catalog-api-golang/internal/locale"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
 )
 
+// missingItemID es el id que stubService no encuentra.
+const missingItemID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
 type stubService struct{}
 
 func (service *stubService) Create(ctx context.Context, in CreateItemInput) (Item, error) {
 	return Item{ID: "id", Name: in.Name, Price: in.Price, Stock: in.Stock}, nil
 }
 
-func (service *stubService) List(ctx context.Context, page, limit int, query string) ([]Item, int, error) {
-	return []Item{}, 0, nil
+func (service *stubService) List(ctx context.Context, page, limit int, filter ListFilter) (ListPage, error) {
+	return ListPage{Items: []Item{}}, nil
+}
+
+func (service *stubService) ListAfter(ctx context.Context, after CreatedAtID, limit int, filter ListFilter) (ListPage, error) {
+	return ListPage{Items: []Item{}}, nil
+}
+
+func (service *stubService) Count(ctx context.Context, filter ListFilter) (ItemCount, error) {
+	return ItemCount{}, nil
+}
+
+func (service *stubService) Export(ctx context.Context, filter ListFilter, fn func(Item) error) error {
+	return nil
+}
+
+func (service *stubService) CollectionVersion(ctx context.Context) (CollectionVersion, error) {
+	return CollectionVersion{}, nil
 }
 
 func (service *stubService) Get(ctx context.Context, id string) (Item, error) {
+	if id == missingItemID {
+		return Item{}, ErrorNotFound
+	}
 	return Item{ID: id}, nil
 }
 
+func (service *stubService) GetBySlug(ctx context.Context, slug string) (Item, error) {
+	return Item{Slug: slug}, nil
+}
+
+func (service *stubService) GetBySKU(ctx context.Context, sku string) (Item, error) {
+	return Item{SKU: &sku}, nil
+}
+
+func (service *stubService) GetByBarcode(ctx context.Context, barcode string) (Item, error) {
+	return Item{Barcode: &barcode}, nil
+}
+
+func (service *stubService) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	return []Suggestion{}, nil
+}
+
+func (service *stubService) DidYouMean(ctx context.Context, query string) (string, error) {
+	return "", nil
+}
+
+func (service *stubService) Related(ctx context.Context, id string, limit int) ([]Item, error) {
+	return []Item{}, nil
+}
+
 func (service *stubService) Update(ctx context.Context, id string, in UpdateItemInput) (Item, error) {
 	return Item{ID: id}, nil
 }
 
-func (service *stubService) Delete(ctx context.Context, id string) error {
+func (service *stubService) Replace(ctx context.Context, id string, in ReplaceItemInput) (Item, error) {
+	return Item{ID: id, Name: in.Name}, nil
+}
+
+func (service *stubService) Delete(ctx context.Context, id string, ifVersion *int) (Item, error) {
+	if id == missingItemID {
+		return Item{}, ErrorNotFound
+	}
+	return Item{ID: id}, nil
+}
+
+func (service *stubService) Purge(ctx context.Context, id string) error {
+	if id == missingItemID {
+		return ErrorNotFound
+	}
+	return nil
+}
+
+func (service *stubService) AdjustStock(ctx context.Context, id string, in StockAdjustmentInput) (Item, error) {
+	if id == missingItemID {
+		return Item{}, ErrorNotFound
+	}
+	return Item{ID: id, Stock: in.Delta}, nil
+}
+
+func (service *stubService) StockMovements(ctx context.Context, id string, page, limit int) (StockMovementPage, error) {
+	if id == missingItemID {
+		return StockMovementPage{}, ErrorNotFound
+	}
+	return StockMovementPage{}, nil
+}
+
+func (service *stubService) RestockNeeded(ctx context.Context, page, limit int) (ListPage, error) {
+	return ListPage{}, nil
+}
+
+func (service *stubService) Changes(ctx context.Context, after UpdatedAtID, limit int) (ChangesPage, error) {
+	return ChangesPage{Next: after}, nil
+}
+
+func (service *stubService) AdjustPrices(ctx context.Context, input PriceAdjustmentInput) (PriceAdjustmentResult, error) {
+	return PriceAdjustmentResult{}, nil
+}
+
+func (service *stubService) PriceHistory(ctx context.Context, id string, filter PriceHistoryFilter, page, limit int) (PriceHistoryPage, error) {
+	if id == missingItemID {
+		return PriceHistoryPage{}, ErrorNotFound
+	}
+	return PriceHistoryPage{}, nil
+}
+
+func (service *stubService) Audit(ctx context.Context, id string, page, limit int) (AuditPage, error) {
+	if id == missingItemID {
+		return AuditPage{}, ErrorNotFound
+	}
+	return AuditPage{}, nil
+}
+
+func (service *stubService) Reserve(ctx context.Context, id string, in ReserveInput) (Reservation, error) {
+	if id == missingItemID {
+		return Reservation{}, ErrorNotFound
+	}
+	return Reservation{ID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", ItemID: id, Quantity: in.Quantity}, nil
+}
+
+func (service *stubService) Release(ctx context.Context, id, reservationID string) error {
+	if id == missingItemID {
+		return ErrorNotFound
+	}
+	return nil
+}
+
+func (service *stubService) Variants(ctx context.Context, itemID string) ([]Variant, error) {
+	if itemID == missingItemID {
+		return nil, ErrorNotFound
+	}
+	return []Variant{}, nil
+}
+
+func (service *stubService) CreateVariant(ctx context.Context, itemID string, in CreateVariantInput) (Variant, error) {
+	if itemID == missingItemID {
+		return Variant{}, ErrorNotFound
+	}
+	return Variant{ID: "9b2d3f4e-1a5c-4d6e-8f70-123456789abc", ItemID: itemID, SKU: in.SKU}, nil
+}
+
+func (service *stubService) UpdateVariant(ctx context.Context, itemID, variantID string, in UpdateVariantInput) (Variant, error) {
+	if itemID == missingItemID {
+		return Variant{}, ErrorNotFound
+	}
+	return Variant{ID: variantID, ItemID: itemID}, nil
+}
+
+func (service *stubService) DeleteVariant(ctx context.Context, itemID, variantID string) error {
+	if itemID == missingItemID {
+		return ErrorNotFound
+	}
+	return nil
+}
+
+func (service *stubService) PriceSchedules(ctx context.Context, itemID string) ([]PriceSchedule, error) {
+	if itemID == missingItemID {
+		return nil, ErrorNotFound
+	}
+	return []PriceSchedule{}, nil
+}
+
+func (service *stubService) CreatePriceSchedule(ctx context.Context, itemID string, in CreatePriceScheduleInput) (PriceSchedule, error) {
+	if itemID == missingItemID {
+		return PriceSchedule{}, ErrorNotFound
+	}
+	return PriceSchedule{ID: "5c1e2d3f-4a5b-4c6d-8e7f-123456789abc", ItemID: itemID, NewPrice: in.NewPrice}, nil
+}
+
+func (service *stubService) DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error {
+	if itemID == missingItemID {
+		return ErrorNotFound
+	}
+	return nil
+}
+
+func (service *stubService) GetByExternalRef(ctx context.Context, system, externalID string) (Item, error) {
+	return Item{ID: "550e8400-e29b-41d4-a716-446655440000"}, nil
+}
+
+func (service *stubService) AddExternalRef(ctx context.Context, itemID string, in ExternalRefInput) (ExternalRef, bool, error) {
+	if itemID == missingItemID {
+		return ExternalRef{}, false, ErrorNotFound
+	}
+	return ExternalRef{System: in.System, ExternalID: in.ExternalID}, true, nil
+}
+
+func (service *stubService) RemoveExternalRef(ctx context.Context, itemID, system, externalID string) error {
+	if itemID == missingItemID {
+		return ErrorNotFound
+	}
 	return nil
 }
 
+func (service *stubService) Locales() []string {
+	return locale.Default
+}
+
+func (service *stubService) Translations(ctx context.Context, itemID string) ([]Translation, error) {
+	if itemID == missingItemID {
+		return nil, ErrorNotFound
+	}
+	return []Translation{}, nil
+}
+
+func (service *stubService) PutTranslation(ctx context.Context, itemID, tag string, in TranslationInput) (Translation, error) {
+	if itemID == missingItemID {
+		return Translation{}, ErrorNotFound
+	}
+	return Translation{Locale: tag, Name: in.Name}, nil
+}
+
+func (service *stubService) Localize(ctx context.Context, tag string, items []Item) ([]Item, error) {
+	return items, nil
+}
+
+func (service *stubService) UpdateEach(ctx context.Context, entries []BulkUpdateEntry) ([]BulkUpdateResult, error) {
+	return service.UpdateMany(ctx, entries)
+}
+
+func (service *stubService) UpdateMany(ctx context.Context, entries []BulkUpdateEntry) ([]BulkUpdateResult, error) {
+	results := make([]BulkUpdateResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, BulkUpdateResult{ID: entry.ID, Item: Item{ID: entry.ID}})
+	}
+	return results, nil
+}
+
+func (service *stubService) Duplicate(ctx context.Context, id string, input DuplicateItemInput) (Item, error) {
+	if id == missingItemID {
+		return Item{}, ErrorNotFound
+	}
+	return Item{ID: "copy"}, nil
+}
+
+func (service *stubService) Publish(ctx context.Context, id string) (Item, error) {
+	if id == missingItemID {
+		return Item{}, ErrorNotFound
+	}
+	return Item{ID: id, State: StatePublished}, nil
+}
+
+func (service *stubService) Unpublish(ctx context.Context, id string) (Item, error) {
+	if id == missingItemID {
+		return Item{}, ErrorNotFound
+	}
+	return Item{ID: id, State: StateDraft}, nil
+}
+
+func (service *stubService) DeleteMany(ctx context.Context, ids []string) (BulkDeleteResult, error) {
+	return BulkDeleteResult{Deleted: len(ids), Missing: []string{}}, nil
+}
+
+func (service *stubService) ApplyJSONPatch(ctx context.Context, id string, operations []PatchOperation, ifVersion *int) (Item, error) {
+	return Item{ID: id}, nil
+}
+
 func TestRegisterRoutes(t *testing.T) {
 	router := chi.NewRouter()
 	RegisterRoutes(router, NewHandler(&stubService{}))
@@ -59,12 +306,97 @@ func TestRegisterRoutes(t *testing.T) {
 			path:       "/items/",
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "count items",
+			method:     http.MethodGet,
+			path:       "/items/count",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "trash",
+			method:     http.MethodGet,
+			path:       "/items/trash",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "expiring items",
+			method:     http.MethodGet,
+			path:       "/items/expiring?days=7",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "restock needed",
+			method:     http.MethodGet,
+			path:       "/items/restock-needed",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "changes feed",
+			method:     http.MethodGet,
+			path:       "/items/changes?since=2024-06-01T00:00:00Z",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "suggest items",
+			method:     http.MethodGet,
+			path:       "/items/suggest?q=pho",
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "get item by id",
 			method:     http.MethodGet,
 			path:       "/items/" + id,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "related items",
+			method:     http.MethodGet,
+			path:       "/items/" + id + "/related",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "duplicate item",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/duplicate",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "publish item",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/publish",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unpublish item",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/unpublish",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "get item by slug",
+			method:     http.MethodGet,
+			path:       "/items/slug/wireless-keyboard",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "get item by sku",
+			method:     http.MethodGet,
+			path:       "/items/sku/KB-001",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "get item by barcode",
+			method:     http.MethodGet,
+			path:       "/items/barcode/4006381333931",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "put item",
+			method:     http.MethodPut,
+			path:       "/items/" + id,
+			body:       `{"name":"Phone","price":"10.00","stock":2}`,
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "patch item",
 			method:     http.MethodPatch,
@@ -72,6 +404,167 @@ func TestRegisterRoutes(t *testing.T) {
 			body:       `{"name":"Updated"}`,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "purge item",
+			method:     http.MethodDelete,
+			path:       "/items/" + id + "/purge",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "purge item not in trash",
+			method:     http.MethodDelete,
+			path:       "/items/" + missingItemID + "/purge",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "adjust stock",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/stock-adjustments",
+			body:       `{"delta":5,"reason":"restock"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "stock movements",
+			method:     http.MethodGet,
+			path:       "/items/" + id + "/stock-movements",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "stock movements of a missing item",
+			method:     http.MethodGet,
+			path:       "/items/" + missingItemID + "/stock-movements",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "reserve stock",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/reservations",
+			body:       `{"quantity":1}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "release reservation",
+			method:     http.MethodDelete,
+			path:       "/items/" + id + "/reservations/7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "release reservation of a missing item",
+			method:     http.MethodDelete,
+			path:       "/items/" + missingItemID + "/reservations/7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "list variants",
+			method:     http.MethodGet,
+			path:       "/items/" + id + "/variants",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "create variant",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/variants",
+			body:       `{"sku":"TS-M","stock":3}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "create variant of a missing item",
+			method:     http.MethodPost,
+			path:       "/items/" + missingItemID + "/variants",
+			body:       `{"sku":"TS-M","stock":3}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "patch variant",
+			method:     http.MethodPatch,
+			path:       "/items/" + id + "/variants/9b2d3f4e-1a5c-4d6e-8f70-123456789abc",
+			body:       `{"stock":5}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "delete variant",
+			method:     http.MethodDelete,
+			path:       "/items/" + id + "/variants/9b2d3f4e-1a5c-4d6e-8f70-123456789abc",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "list price schedules",
+			method:     http.MethodGet,
+			path:       "/items/" + id + "/price-schedules",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "create price schedule",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/price-schedules",
+			body:       `{"new_price":"19.99","effective_at":"2025-07-01T03:00:00Z"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "delete price schedule",
+			method:     http.MethodDelete,
+			path:       "/items/" + id + "/price-schedules/5c1e2d3f-4a5b-4c6d-8e7f-123456789abc",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "add external ref",
+			method:     http.MethodPost,
+			path:       "/items/" + id + "/refs",
+			body:       `{"system":"erp","external_id":"A-100"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "remove external ref",
+			method:     http.MethodDelete,
+			path:       "/items/" + id + "/refs/erp/A-100",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "get item by external ref",
+			method:     http.MethodGet,
+			path:       "/items/by-ref/erp/A-100",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "price history",
+			method:     http.MethodGet,
+			path:       "/items/" + id + "/price-history?from=2025-03-01&to=2025-04-01",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "list translations",
+			method:     http.MethodGet,
+			path:       "/items/" + id + "/translations",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "put translation",
+			method:     http.MethodPut,
+			path:       "/items/" + id + "/translations/es",
+			body:       `{"name":"Teléfono"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bulk delete",
+			method:     http.MethodPost,
+			path:       "/items/bulk-delete",
+			body:       `{"ids":["` + id + `"]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bulk update",
+			method:     http.MethodPatch,
+			path:       "/items/bulk",
+			body:       `{"updates":[{"id":"` + id + `","stock":3}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "price adjustment",
+			method:     http.MethodPost,
+			path:       "/items/price-adjustments",
+			body:       `{"adjustment":{"type":"percent","value":"5"},"dry_run":true}`,
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "delete item",
 			method:     http.MethodDelete,
@@ -94,3 +587,102 @@ func TestRegisterRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterRoutes_Head(t *testing.T) {
+	router := chi.NewRouter()
+	RegisterRoutes(router, NewHandler(&stubService{}))
+
+	const id = "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "list", path: "/items/", wantStatus: http.StatusOK},
+		{name: "item", path: "/items/" + id, wantStatus: http.StatusOK},
+		{name: "missing item", path: "/items/" + missingItemID, wantStatus: http.StatusNotFound},
+		{name: "invalid uuid", path: "/items/not-a-uuid", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getRecorder := httptest.NewRecorder()
+			router.ServeHTTP(getRecorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, tt.path, nil))
+
+			require.Equal(t, tt.wantStatus, getRecorder.Code)
+			require.Equal(t, tt.wantStatus, recorder.Code)
+			require.Equal(t, getRecorder.Header().Get("Content-Type"), recorder.Header().Get("Content-Type"))
+			require.Equal(t, strconv.Itoa(getRecorder.Body.Len()), recorder.Header().Get("Content-Length"))
+			require.Zero(t, recorder.Body.Len())
+		})
+	}
+}
+
+func TestRegisterStreamRoutes(t *testing.T) {
+	// Registradas al lado de RegisterRoutes, como en main: /items/events no puede caer en /items/{id}.
+	router := chi.NewRouter()
+	handler := NewHandler(&stubService{})
+	RegisterRoutes(router, handler)
+	RegisterStreamRoutes(router, handler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, EventsPath, nil))
+
+	require.Equal(t, http.StatusNotImplemented, recorder.Code)
+}
+
+func TestRegisterRoutes_ListEstimatedCount(t *testing.T) {
+	// Un GET /items sin parámetros lleva los filtros por defecto (activos, publicados, sin vencer);
+	// con la estimación prendida tiene que usarla igual, sin el COUNT(*) OVER () de ListWithTotal.
+	repository := &fakeRepo{listItems: []Item{{ID: "id-1"}}, countTotal: 7, estimateTotal: 2000000, estimateOK: true}
+	router := chi.NewRouter()
+	RegisterRoutes(router, NewHandler(NewService(repository, WithEstimatedCount(true))))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, repository.estimateCalled)
+	require.False(t, repository.listWithTotalCalled)
+	require.Equal(t, StatusActive, repository.estimateFilter.Status)
+	require.Contains(t, recorder.Body.String(), `"total_is_estimate":true`)
+	require.Contains(t, recorder.Body.String(), `"total":2000000`)
+}
+
+func TestRegisterRoutes_ListETagAfterReserve(t *testing.T) {
+	// Con el cache de versión (como en main) una reserva tiene que invalidar el ETag del listado:
+	// cambia available aunque no cambie ninguna columna del item.
+	const itemID = "550e8400-e29b-41d4-a716-446655440000"
+	cache := NewCollectionVersionCache()
+	cache.Listening()
+	before := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	repository := &fakeRepo{
+		listItems: []Item{{ID: itemID}},
+		getItem:   Item{ID: itemID, Stock: 5},
+		version:   CollectionVersion{LastUpdatedAt: before, Count: 1},
+	}
+	router := chi.NewRouter()
+	RegisterRoutes(router, NewHandler(NewService(repository, WithCollectionVersionCache(cache), WithEventPublisher(cache))))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/items", nil))
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	repository.version = CollectionVersion{LastUpdatedAt: before.Add(time.Second), Count: 1}
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/items/"+itemID+"/reservations", strings.NewReader(`{"quantity":1}`)))
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	request := httptest.NewRequest(http.MethodGet, "/items", nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEqual(t, etag, recorder.Header().Get("ETag"))
+}
@@ -2,10 +2,22 @@ package items
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"math/big"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/Lelo88/catalog-api-golang/internal/currency"
+	"github.com/Lelo88/catalog-api-golang/internal/events"
+	"github.com/Lelo88/catalog-api-golang/internal/locale"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -13,154 +25,3188 @@ import (
 var (
 	ErrorInvalidInput  = errors.New("invalid input")
 	ErrorDuplicateName = errors.New("duplicate item name")
-	ErrorNotFound      = errors.New("item not found")
+	// ErrorDuplicateSlug indica que el slug pedido ya lo usa otro item.
+	ErrorDuplicateSlug = errors.New("duplicate item slug")
+	// ErrorDuplicateSKU indica que otro item ya tiene ese SKU.
+	ErrorDuplicateSKU = errors.New("duplicate item sku")
+	// ErrorDuplicateBarcode indica que otro item ya tiene ese código de barras.
+	ErrorDuplicateBarcode = errors.New("duplicate item barcode")
+	ErrorNotFound         = errors.New("item not found")
+	// ErrorVersionMismatch indica que el item cambió desde que el cliente lo leyó (If-Match con otra versión).
+	ErrorVersionMismatch = errors.New("item version does not match")
+	// ErrorTimeout indica que no quedaba tiempo del request para consultar la DB. Envuelve
+	// context.DeadlineExceeded, así httpx.FailUnexpected lo responde como timeout.
+	ErrorTimeout = fmt.Errorf("not enough time left to query the database: %w", context.DeadlineExceeded)
+	// Motivos concretos de entrada inválida. Todos envuelven ErrorInvalidInput, así que
+	// errors.Is(err, ErrorInvalidInput) sigue funcionando para quien no necesita el detalle.
+	ErrorInvalidName  = fmt.Errorf("%w: name must not be empty", ErrorInvalidInput)
+	ErrorInvalidPrice = fmt.Errorf("%w: price must be a positive amount with up to 2 decimals", ErrorInvalidInput)
+	// ErrorInvalidSalePrice indica un precio de oferta mal formado o que no es menor que el precio.
+	ErrorInvalidSalePrice = fmt.Errorf("%w: sale_price must be a positive amount with up to 2 decimals, lower than price", ErrorInvalidInput)
+	ErrorInvalidStock     = fmt.Errorf("%w: stock must not be negative", ErrorInvalidInput)
+	// ErrorStockBelowFloor indica que un item con allow_backorder quedaría por debajo del piso configurado.
+	ErrorStockBelowFloor = fmt.Errorf("%w: stock is below the backorder floor", ErrorInvalidInput)
+	ErrorInvalidSKU      = fmt.Errorf("%w: sku must be 3 to 64 letters, digits, dots, underscores or hyphens", ErrorInvalidInput)
+	// ErrorInvalidCurrency indica un código que no está en la lista de monedas soportadas.
+	ErrorInvalidCurrency = fmt.Errorf("%w: currency must be a supported ISO 4217 code", ErrorInvalidInput)
+	// ErrorCurrencyChange indica un PATCH que cambia la moneda sin ?allow_currency_change=true.
+	ErrorCurrencyChange = fmt.Errorf("%w: changing the currency requires allow_currency_change", ErrorInvalidInput)
+	ErrorInvalidStatus  = fmt.Errorf("%w: status must be active or inactive", ErrorInvalidInput)
+	ErrorInvalidState   = fmt.Errorf("%w: state must be draft or published", ErrorInvalidInput)
+	ErrorInvalidSlug    = fmt.Errorf("%w: slug must be lowercase letters, digits and hyphens, up to 120 characters", ErrorInvalidInput)
+	// ErrorInvalidCategory indica un category_id que no es un UUID; ErrorUnknownCategory, uno que no existe.
+	ErrorInvalidCategory = fmt.Errorf("%w: category_id must be a UUID", ErrorInvalidInput)
+	ErrorUnknownCategory = fmt.Errorf("%w: category does not exist", ErrorInvalidInput)
+	// ErrorUnknownBrand indica un brand_id que no existe. Es un error de campo para que el
+	// cliente reciba el detalle en el 400 invalid_input.
+	ErrorUnknownBrand error = &ValidationError{Field: "brand_id", Message: "brand_id does not reference an existing brand"}
+	// ErrorInvalidMatch se devuelve cuando el modo de búsqueda no es contains, prefix ni exact.
+	ErrorInvalidMatch = fmt.Errorf("%w: unknown match mode", ErrorInvalidInput)
+	// ErrorInvalidSort se devuelve cuando una clave de orden no está en la whitelist, está vacía o se repite.
+	ErrorInvalidSort = fmt.Errorf("%w: invalid sort", ErrorInvalidInput)
+	// ErrorFuzzyUnavailable indica que la base no tiene pg_trgm instalado (falta la migración 0003).
+	ErrorFuzzyUnavailable = errors.New("fuzzy search unavailable: pg_trgm extension is not installed")
+	// ErrorPaginationTooDeep se devuelve cuando page*limit supera el máximo de filas que se permite saltear.
+	ErrorPaginationTooDeep = fmt.Errorf("%w: pagination too deep", ErrorInvalidInput)
+	// ErrorCursorUnsupported se devuelve cuando se pide paginar por cursor con un orden distinto
+	// al por defecto o con fuzzy: el cursor solo codifica (created_at, id).
+	ErrorCursorUnsupported = fmt.Errorf("%w: cursor pagination only supports the default sort", ErrorInvalidInput)
+	// ErrorInvalidFilter es la base de los *FilterError del listado.
+	ErrorInvalidFilter = fmt.Errorf("%w: invalid filter", ErrorInvalidInput)
+	// ErrorNotApplied marca una entrada de una operación masiva que no se aplicó porque falló otra.
+	ErrorNotApplied = errors.New("not applied because another entry failed")
+	// ErrorInsufficientStock indica que el stock disponible (stock menos reservas vigentes) no alcanza para la reserva.
+	ErrorInsufficientStock = errors.New("insufficient stock")
+	// ErrorBelowMinOrderQty indica una reserva por menos unidades que el min_order_qty del item.
+	ErrorBelowMinOrderQty = errors.New("quantity below minimum order quantity")
+	// ErrorVariantNotFound indica que la variante no existe o es de otro item.
+	ErrorVariantNotFound = errors.New("variant not found")
+	// ErrorDuplicateVariantSKU indica que otra variante del mismo item ya tiene ese SKU.
+	ErrorDuplicateVariantSKU = errors.New("duplicate variant sku")
+	// ErrorPriceScheduleNotFound indica que el cambio de precio no existe, es de otro item o ya se aplicó.
+	ErrorPriceScheduleNotFound = errors.New("price schedule not found")
+	// ErrorDuplicatePriceSchedule indica que el item ya tiene un cambio de precio pendiente para ese instante.
+	ErrorDuplicatePriceSchedule = errors.New("duplicate price schedule")
+	// ErrorScheduledBelowSalePrice indica un cambio de precio programado en o por debajo del precio de
+	// oferta actual del item.
+	ErrorScheduledBelowSalePrice = errors.New("scheduled price would not be above the sale price")
+	// ErrorExternalRefNotFound indica que la ref externa no existe o es de otro item.
+	ErrorExternalRefNotFound = errors.New("external ref not found")
+	// ErrorDuplicateExternalRef indica que la ref externa ya apunta a otro item.
+	ErrorDuplicateExternalRef = errors.New("duplicate external ref")
+	// ErrorUnsupportedLocale indica un locale fuera de SUPPORTED_LOCALES, o el locale base en una
+	// traducción (los campos del item ya están en ese idioma).
+	ErrorUnsupportedLocale = fmt.Errorf("%w: unsupported locale", ErrorInvalidInput)
+	// ErrorStockManagedByVariants indica un cambio directo del stock de un item con variantes:
+	// su stock es la suma del de las variantes y cambia a través de ellas.
+	ErrorStockManagedByVariants = fmt.Errorf("%w: the stock of an item with variants is the sum of its variants", ErrorInvalidInput)
+	// ErrorAdjustedPriceNotPositive indica un ajuste masivo que dejaría algún precio en cero o menos.
+	ErrorAdjustedPriceNotPositive = errors.New("adjusted price would not be positive")
+	// ErrorAdjustedBelowSalePrice indica un ajuste masivo que dejaría algún precio de lista en o por
+	// debajo del precio de oferta del item.
+	ErrorAdjustedBelowSalePrice = errors.New("adjusted price would not be above the sale price")
 )
 
-// RepositoryAPI define lo que el service necesita. 
+// FilterError describe un filtro del listado con un valor inválido. Field es el query param.
+// Envuelve ErrorInvalidFilter (y por lo tanto ErrorInvalidInput).
+type FilterError struct {
+	Field   string
+	Message string
+}
+
+// Error implementa error.
+func (filterError *FilterError) Error() string {
+	return filterError.Field + ": " + filterError.Message
+}
+
+// Unwrap permite que errors.Is reconozca el error como ErrorInvalidFilter.
+func (filterError *FilterError) Unwrap() error {
+	return ErrorInvalidFilter
+}
+
+// ExternalRefConflictError indica que System/ExternalID ya es la ref de otro item.
+// Envuelve ErrorDuplicateExternalRef.
+type ExternalRefConflictError struct {
+	System     string
+	ExternalID string
+}
+
+// Error implementa error.
+func (conflictError *ExternalRefConflictError) Error() string {
+	return fmt.Sprintf("%s ref %q already belongs to another item", conflictError.System, conflictError.ExternalID)
+}
+
+// Unwrap permite que errors.Is reconozca el error como ErrorDuplicateExternalRef.
+func (conflictError *ExternalRefConflictError) Unwrap() error {
+	return ErrorDuplicateExternalRef
+}
+
+// BatchError indica qué input de un alta en lote falló. Envuelve el error de ese input.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+// Error implementa error.
+func (batchError *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", batchError.Index, batchError.Err)
+}
+
+// Unwrap permite que errors.Is y errors.As reconozcan el error del input.
+func (batchError *BatchError) Unwrap() error {
+	return batchError.Err
+}
+
+// PriceAdjustmentLimitError indica un ajuste masivo de más items que el tope sin un ?confirm_over=
+// que los cubra. Affected es cuántos items cambiaría.
+type PriceAdjustmentLimitError struct {
+	Affected int
+	Limit    int
+}
+
+// Error implementa error.
+func (limitError *PriceAdjustmentLimitError) Error() string {
+	return fmt.Sprintf("adjustment affects %d items, more than %d; repeat with confirm_over=%d to apply it",
+		limitError.Affected, limitError.Limit, limitError.Affected)
+}
+
+// RepositoryAPI define lo que el service necesita.
 // Permite testear handlers con stubs sin tocar DB.
 type RepositoryAPI interface {
 	Insert(ctx context.Context, in CreateItemInput) (Item, error)
-	List(ctx context.Context, query string, limit, offset int) ([]Item, error)
-	Count(ctx context.Context, query string) (int, error)
+	// CopyInsert carga muchos items ya validados con un solo COPY.
+	CopyInsert(ctx context.Context, inputs []CreateItemInput) (int64, error)
+	// UpsertBySKU inserta in o actualiza precio, stock y descripción del item con su SKU; devuelve
+	// true si lo creó y ErrorDuplicateSKU si el SKU es de un item borrado.
+	UpsertBySKU(ctx context.Context, in CreateItemInput) (Item, bool, error)
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]Item, error)
+	// ListWithTotal devuelve la página y el total del filtro en una sola query.
+	ListWithTotal(ctx context.Context, filter ListFilter, limit, offset int) ([]Item, int, error)
+	// ListAfter pagina por keyset a partir de after, en el orden por defecto.
+	ListAfter(ctx context.Context, filter ListFilter, after CreatedAtID, limit int) ([]Item, error)
+	Count(ctx context.Context, filter ListFilter) (int, error)
+	// Export llama a fn con cada item del filtro, en el orden del listado, sin acumularlos en memoria.
+	Export(ctx context.Context, filter ListFilter, fn func(Item) error) error
+	// Related devuelve hasta limit items con nombre parecido al de item (máximo 20), sin incluirlo.
+	Related(ctx context.Context, item Item, threshold float64, limit int) ([]Item, error)
+	// Suggest devuelve hasta limit sugerencias (máximo 10) de items a la venta para el autocompletado.
+	Suggest(ctx context.Context, prefix string, threshold float64, limit int) ([]Suggestion, error)
+	// ClosestName devuelve el nombre de item a la venta más parecido a query, con similitud de al
+	// menos threshold, o "" si no hay ninguno.
+	ClosestName(ctx context.Context, query string, threshold float64) (string, error)
+	// RestockNeeded devuelve una página de los items con stock en o debajo de su punto de reposición,
+	// del mayor faltante al menor, y el total.
+	RestockNeeded(ctx context.Context, limit, offset int) ([]Item, int, error)
+	// Changes devuelve hasta limit items, borrados incluidos, posteriores a after y con updated_at
+	// anterior a until, por updated_at e id ascendentes.
+	Changes(ctx context.Context, after UpdatedAtID, until time.Time, limit int) ([]Item, error)
 	GetByID(ctx context.Context, id string) (Item, error)
+	// GetBySKU devuelve pgx.ErrNoRows si ningún item tiene ese SKU.
+	GetBySKU(ctx context.Context, sku string) (Item, error)
+	// GetByBarcode devuelve pgx.ErrNoRows si ningún item tiene ese código de barras.
+	GetByBarcode(ctx context.Context, barcode string) (Item, error)
+	// GetBySlug devuelve ErrorNotFound si ningún item publicado tiene ese slug (los borradores no cuentan).
+	GetBySlug(ctx context.Context, slug string) (Item, error)
+	// TakenSlugs devuelve los slugs usados que colisionan con base (base y base-N), salvo el de exceptID.
+	TakenSlugs(ctx context.Context, base, exceptID string) ([]string, error)
+	// CheckUniqueKeys devuelve el error de duplicado que daría el alta de input, o nil, sin escribir.
+	CheckUniqueKeys(ctx context.Context, input CreateItemInput) error
+	// Update incrementa version. Con in.IfVersion devuelve ErrorVersionMismatch si el item tiene otra versión.
 	Update(ctx context.Context, id string, in UpdateItemInput) (Item, error)
-	Delete(ctx context.Context, id string) error
+	// Replace reescribe todas las columnas editables; devuelve ErrorNotFound si el id no existe.
+	Replace(ctx context.Context, id string, in ReplaceItemInput) (Item, error)
+	// Delete es un borrado lógico y devuelve el item borrado. Devuelve ErrorNotFound si no existe
+	// o ya estaba borrado y, con ifVersion, ErrorVersionMismatch si la versión no coincide.
+	Delete(ctx context.Context, id string, ifVersion *int) (Item, error)
+	// Purge borra definitivamente un item de la papelera; ErrorNotFound si no está borrado o no existe.
+	Purge(ctx context.Context, id string) error
+	// DeleteMany borra lógicamente los items de ids en una sola sentencia y devuelve los IDs borrados.
+	DeleteMany(ctx context.Context, ids []string) ([]string, error)
+	// GetForUpdate lee un item bloqueando la fila (usar dentro de InTx).
+	GetForUpdate(ctx context.Context, id string) (Item, error)
+	// ReservedQuantity suma las unidades reservadas y vigentes de un item.
+	ReservedQuantity(ctx context.Context, itemID string) (int, error)
+	// InsertReservation guarda una reserva sin verificar stock; la verificación es del service.
+	InsertReservation(ctx context.Context, itemID string, quantity int, ttl time.Duration) (Reservation, error)
+	// DeleteReservation libera una reserva del item; ErrorNotFound si no existe o es de otro item.
+	DeleteReservation(ctx context.Context, itemID, reservationID string) error
+	// DeleteExpiredReservations borra las reservas vencidas; devuelve cuántas y los IDs de sus items.
+	DeleteExpiredReservations(ctx context.Context) (int, []string, error)
+	// InsertStockMovement agrega un movimiento al historial de stock (usar en la transacción del cambio).
+	InsertStockMovement(ctx context.Context, movement StockMovement) error
+	// ListStockMovements devuelve una página del historial de un item, del más nuevo al más viejo, y el total.
+	ListStockMovements(ctx context.Context, itemID string, limit, offset int) ([]StockMovement, int, error)
+	// InsertPriceChange agrega un cambio al historial de precios (usar en la transacción del cambio).
+	InsertPriceChange(ctx context.Context, entry PriceHistoryEntry) error
+	// ListPriceHistory devuelve una página del historial de precios de un item, del más nuevo al más viejo, y el total.
+	ListPriceHistory(ctx context.Context, itemID string, filter PriceHistoryFilter, limit, offset int) ([]PriceHistoryEntry, int, error)
+	// InsertOutbox agrega eventos al outbox (usar en la transacción del cambio).
+	InsertOutbox(ctx context.Context, events []Event) error
+	// NotifyChanges avisa los cambios en ChangesChannel (usar en la transacción del cambio: el aviso
+	// sale con el commit y se descarta con el rollback).
+	NotifyChanges(ctx context.Context, events []Event) error
+	// InsertAudit agrega entradas a la auditoría de items (usar en la transacción del cambio).
+	InsertAudit(ctx context.Context, entries []AuditEntry) error
+	// ListAudit devuelve una página de la auditoría de un item, de la más nueva a la más vieja, y el total.
+	ListAudit(ctx context.Context, itemID string, limit, offset int) ([]AuditEntry, int, error)
+	// PreviewPriceAdjustment calcula un ajuste masivo de precios sin aplicarlo.
+	PreviewPriceAdjustment(ctx context.Context, filter ListFilter, adjustment PriceAdjustment, sampleSize int) (PriceAdjustmentPreview, error)
+	// AdjustPrices aplica un ajuste masivo de precios y lo registra en el historial; devuelve los IDs de los items que cambió.
+	AdjustPrices(ctx context.Context, filter ListFilter, adjustment PriceAdjustment, reason, requestID string) ([]string, error)
+	// ListVariants devuelve las variantes del item en el orden en que se crearon.
+	ListVariants(ctx context.Context, itemID string) ([]Variant, error)
+	// InsertVariant crea una variante sin tocar el stock del item; ErrorDuplicateVariantSKU si el SKU ya está en el item.
+	InsertVariant(ctx context.Context, itemID string, in CreateVariantInput) (Variant, error)
+	// UpdateVariant devuelve ErrorVariantNotFound si la variante no existe o es de otro item.
+	UpdateVariant(ctx context.Context, itemID, variantID string, in UpdateVariantInput) (Variant, error)
+	// DeleteVariant devuelve ErrorVariantNotFound si la variante no existe o es de otro item.
+	DeleteVariant(ctx context.Context, itemID, variantID string) error
+	// VariantStock devuelve cuántas variantes tiene el item y la suma de su stock.
+	VariantStock(ctx context.Context, itemID string) (count, total int, err error)
+	// ListPriceSchedules devuelve los cambios de precio del item (pendientes y aplicados) por effective_at.
+	ListPriceSchedules(ctx context.Context, itemID string) ([]PriceSchedule, error)
+	// InsertPriceSchedule devuelve ErrorDuplicatePriceSchedule si el item ya tiene un cambio pendiente para ese instante.
+	InsertPriceSchedule(ctx context.Context, itemID string, in CreatePriceScheduleInput) (PriceSchedule, error)
+	// DeletePriceSchedule borra un cambio pendiente; ErrorPriceScheduleNotFound si no existe, es de otro item o ya se aplicó.
+	DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error
+	// DuePriceSchedules devuelve hasta limit cambios pendientes cuyo effective_at ya pasó, del más viejo al más nuevo.
+	DuePriceSchedules(ctx context.Context, limit int) ([]PriceSchedule, error)
+	// MarkPriceScheduleApplied completa applied_at de un cambio pendiente; ErrorPriceScheduleNotFound si
+	// ya no está pendiente (lo aplicó otra instancia o se borró).
+	MarkPriceScheduleApplied(ctx context.Context, scheduleID string) (PriceSchedule, error)
+	// MarkPriceScheduleFailed saca de la cola un cambio tomado con MarkPriceScheduleApplied que no se pudo
+	// aplicar, guardando reason; ErrorPriceScheduleNotFound si no existe.
+	MarkPriceScheduleFailed(ctx context.Context, scheduleID, reason string) error
+	// GetByExternalRef devuelve pgx.ErrNoRows si ningún item tiene esa ref.
+	GetByExternalRef(ctx context.Context, system, externalID string) (Item, error)
+	// InsertExternalRef devuelve ErrorDuplicateExternalRef si la ref ya existe (en este u otro item).
+	InsertExternalRef(ctx context.Context, itemID string, in ExternalRefInput) (ExternalRef, error)
+	// DeleteExternalRef devuelve ErrorExternalRefNotFound si la ref no existe o es de otro item.
+	DeleteExternalRef(ctx context.Context, itemID, system, externalID string) error
+	// ListTranslations devuelve las traducciones del item, ordenadas por locale.
+	ListTranslations(ctx context.Context, itemID string) ([]Translation, error)
+	// TranslationsFor devuelve las traducciones a locale de esos items, por ID; los que no tienen no aparecen.
+	TranslationsFor(ctx context.Context, locale string, itemIDs []string) (map[string]Translation, error)
+	// UpsertTranslation crea o reemplaza la traducción; ErrorNotFound si el item no existe.
+	UpsertTranslation(ctx context.Context, itemID, locale string, in TranslationInput) (Translation, error)
+	// EstimateCount devuelve el total aproximado de items que cumplen filter, según las estadísticas
+	// de la base. ok es false si la base todavía no tiene estadísticas.
+	EstimateCount(ctx context.Context, filter ListFilter) (total int, ok bool, err error)
+	// CollectionVersion devuelve la versión del catálogo completo, para el ETag del listado.
+	CollectionVersion(ctx context.Context) (CollectionVersion, error)
+	// InTx ejecuta fn en una transacción con un repositorio atado a ella. Llamado sobre el repositorio
+	// de una transacción, fn corre en esa misma.
+	InTx(ctx context.Context, fn func(tx RepositoryAPI) error) error
+	// InSnapshot ejecuta fn en una transacción de solo lectura donde todas las queries ven los mismos datos.
+	InSnapshot(ctx context.Context, fn func(tx RepositoryAPI) error) error
+}
+
+// Service contiene reglas de negocio de items.
+type Service struct {
+	repository     RepositoryAPI
+	validators     []Validator
+	metrics        Metrics
+	events         []EventPublisher
+	bus            events.Publisher
+	fuzzyThreshold float64
+	// didYouMeanThreshold es la similitud mínima del nombre que se sugiere; 0 no sugiere nada.
+	didYouMeanThreshold float64
+	maxOffset           int
+	estimateCount       bool
+	outbox              bool
+	versionCache        *CollectionVersionCache
+	backorderFloor      int
+	// defaultCurrency es la moneda de los items que se crean sin currency.
+	defaultCurrency string
+	// defaultTaxRateBPS es la alícuota de los items que se crean sin tax_rate_bps.
+	defaultTaxRateBPS int
+	// locales son los locales soportados; el primero es el de los campos base de los items.
+	locales []string
+	// now es el reloj con el que se decide si un vencimiento es futuro y hasta dónde llega el feed de
+	// cambios; los tests lo fijan.
+	now func() time.Time
+}
+
+// DefaultFuzzyThreshold es el score mínimo de similitud por defecto; coincide con el de pg_trgm.
+const DefaultFuzzyThreshold = 0.3
+
+// DefaultDidYouMeanThreshold es la similitud mínima por defecto del "quizás quisiste decir". Es más
+// exigente que la de fuzzy: se sugiere un solo nombre y tiene que valer la pena.
+const DefaultDidYouMeanThreshold = 0.4
+
+// DefaultBackorderFloor es el stock más negativo que puede tener un item con allow_backorder.
+const DefaultBackorderFloor = -1000
+
+// changesSettleWindow es la antigüedad mínima de un cambio para salir en el feed. updated_at es el
+// comienzo de la transacción que lo escribió, y esa transacción puede confirmar después de que otra
+// más nueva ya haya salido: sin este margen, la marca del cliente pasaría de largo ese item.
+const changesSettleWindow = 5 * time.Second
+
+// DefaultMaxOffset es cuántas filas puede recorrer como máximo una página por offset (page*limit).
+const DefaultMaxOffset = 10000
+
+// Metrics recibe los eventos de negocio del service, así cualquier transporte (HTTP, jobs, etc.) los cuenta.
+type Metrics interface {
+	ItemCreated()
+	ItemUpdated()
+	ItemDeleted()
+}
+
+// noopMetrics es el default: no registra nada.
+type noopMetrics struct{}
+
+func (noopMetrics) ItemCreated() {}
+func (noopMetrics) ItemUpdated() {}
+func (noopMetrics) ItemDeleted() {}
+
+// EventPublisher recibe cada mutación de un item después de confirmada. Publish corre en el request
+// que hizo el cambio, así que no puede bloquear.
+type EventPublisher interface {
+	Publish(event Event)
+}
+
+// ServiceOption configura comportamiento opcional del service.
+type ServiceOption func(*Service)
+
+// WithValidators registra validators extra que corren después de la validación base, en orden.
+func WithValidators(validators ...Validator) ServiceOption {
+	return func(service *Service) {
+		service.validators = append(service.validators, validators...)
+	}
+}
+
+// WithMetrics registra dónde reportar los eventos de negocio.
+func WithMetrics(metrics Metrics) ServiceOption {
+	return func(service *Service) {
+		service.metrics = metrics
+	}
+}
+
+// WithEventPublisher registra a quién avisarle de cada alta, cambio o baja de un item (el EventHub
+// de GET /items/events, los webhooks). Cada evento les llega a todos, en orden.
+func WithEventPublisher(publishers ...EventPublisher) ServiceOption {
+	return func(service *Service) {
+		service.events = append(service.events, publishers...)
+	}
+}
+
+// WithBus registra el bus de eventos (NATS, ...). Con outbox los eventos le llegan por el relay,
+// que los manda aunque el proceso se corte después del commit; sin outbox el service los publica
+// directo después del commit y, si el bus falla, el evento se pierde.
+func WithBus(publisher events.Publisher) ServiceOption {
+	return func(service *Service) {
+		service.bus = publisher
+	}
+}
+
+// WithFuzzyThreshold cambia el score mínimo (0 a 1) que tiene que tener un item para aparecer con fuzzy=true.
+// Más bajo tolera más errores de tipeo a costa de resultados menos relevantes.
+func WithFuzzyThreshold(threshold float64) ServiceOption {
+	return func(service *Service) {
+		service.fuzzyThreshold = threshold
+	}
+}
+
+// WithDidYouMeanThreshold cambia la similitud mínima (0 a 1) del nombre que se sugiere cuando una
+// búsqueda no encuentra nada. 0 desactiva la sugerencia.
+func WithDidYouMeanThreshold(threshold float64) ServiceOption {
+	return func(service *Service) {
+		service.didYouMeanThreshold = threshold
+	}
+}
+
+// WithMaxOffset cambia el tope de page*limit del listado por offset. Más allá, la base tendría que
+// recorrer y descartar demasiadas filas; para eso está la paginación por cursor. 0 quita el tope.
+func WithMaxOffset(rows int) ServiceOption {
+	return func(service *Service) {
+		service.maxOffset = rows
+	}
+}
+
+// WithOutbox hace que cada alta, cambio o baja escriba también su evento en el outbox, en la misma
+// transacción: si el proceso se corta después del commit, el relay lo publica igual. Las escrituras
+// que eran una sola sentencia (Update, Delete, DeleteMany) pasan a ir en una transacción, sin los
+// reintentos del repositorio. Los EventPublisher siguen recibiendo los eventos después del commit.
+func WithOutbox(enabled bool) ServiceOption {
+	return func(service *Service) {
+		service.outbox = enabled
+	}
+}
+
+// WithCollectionVersionCache hace que CollectionVersion lea de cache en lugar de consultar la base
+// en cada listado. El cache tiene que estar suscripto a ChangesChannel (db.Listener) y recibir los
+// eventos de este service (WithEventPublisher); sin listener conectado no cachea nada.
+func WithCollectionVersionCache(cache *CollectionVersionCache) ServiceOption {
+	return func(service *Service) {
+		service.versionCache = cache
+	}
+}
+
+// WithEstimatedCount hace que el listado sin filtros use la estimación de la base en lugar de COUNT(*),
+// que en tablas grandes tarda bastante más que la página en sí. Los listados filtrados siguen siendo exactos.
+func WithEstimatedCount(enabled bool) ServiceOption {
+	return func(service *Service) {
+		service.estimateCount = enabled
+	}
+}
+
+// WithBackorderFloor cambia el stock más negativo permitido a los items con allow_backorder
+// (0 equivale a no aceptar backorders aunque el item tenga el flag).
+func WithBackorderFloor(floor int) ServiceOption {
+	return func(service *Service) {
+		service.backorderFloor = floor
+	}
+}
+
+// WithDefaultCurrency cambia la moneda de los items que se crean sin currency. code tiene que
+// estar en la lista de monedas soportadas (config lo valida al leer DEFAULT_CURRENCY).
+func WithDefaultCurrency(code string) ServiceOption {
+	return func(service *Service) {
+		service.defaultCurrency = currency.Normalize(code)
+	}
+}
+
+// WithDefaultTaxRate cambia la alícuota (en puntos básicos) de los items que se crean sin
+// tax_rate_bps. Config valida DEFAULT_TAX_RATE_BPS; acá se asume entre 0 y 10000.
+func WithDefaultTaxRate(bps int) ServiceOption {
+	return func(service *Service) {
+		service.defaultTaxRateBPS = bps
+	}
+}
+
+// WithLocales cambia los locales soportados. El primero es el idioma de los campos base de los
+// items; los demás admiten traducciones. Config normaliza y valida SUPPORTED_LOCALES.
+func WithLocales(locales ...string) ServiceOption {
+	return func(service *Service) {
+		if len(locales) > 0 {
+			service.locales = locales
+		}
+	}
+}
+
+// NewService crea un service de items.
+func NewService(repository RepositoryAPI, options ...ServiceOption) *Service {
+	service := &Service{
+		repository:          repository,
+		metrics:             noopMetrics{},
+		fuzzyThreshold:      DefaultFuzzyThreshold,
+		didYouMeanThreshold: DefaultDidYouMeanThreshold,
+		maxOffset:           DefaultMaxOffset,
+		backorderFloor:      DefaultBackorderFloor,
+		defaultCurrency:     currency.Default,
+		locales:             locale.Default,
+		now:                 time.Now,
+	}
+	for _, option := range options {
+		option(service)
+	}
+	return service
+}
+
+// publish avisa a los publishers una mutación de item ya confirmada.
+func (service *Service) publish(operation EventOperation, item Item) {
+	service.publishEvent(itemEvent(operation, item))
+}
+
+// publishEvent manda event a cada publisher registrado y, sin outbox, al bus.
+func (service *Service) publishEvent(event Event) {
+	for _, publisher := range service.events {
+		publisher.Publish(event)
+	}
+	if service.bus != nil && !service.outbox {
+		service.publishToBus(event)
+	}
+}
+
+// publishToBus manda event al bus con el mismo payload que escribe el outbox. El cambio ya está
+// confirmado: un error solo se loguea.
+func (service *Service) publishToBus(event Event) {
+	payload, err := json.Marshal(event)
+	if err == nil {
+		err = service.bus.Publish(context.Background(), events.Event{
+			SchemaVersion: events.SchemaVersion,
+			Type:          event.Type(),
+			OccurredAt:    service.now().UTC(),
+			Data:          payload,
+		})
+	}
+	if err != nil {
+		log.Printf("event_publish failed type=%s id=%s err=%v", event.Type(), event.ID, err)
+	}
+}
+
+// recordEvents avisa events en ChangesChannel y, con outbox, los escribe en el outbox, todo con tx,
+// la transacción del cambio.
+func (service *Service) recordEvents(ctx context.Context, tx RepositoryAPI, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if service.outbox {
+		if err := tx.InsertOutbox(ctx, events); err != nil {
+			return err
+		}
+	}
+	return tx.NotifyChanges(ctx, events)
+}
+
+// itemEvent arma el evento de una mutación de item.
+func itemEvent(operation EventOperation, item Item) Event {
+	return Event{ID: item.ID, Operation: operation, Item: &item}
+}
+
+// idEvents arma los eventos de una operación masiva, que solo conoce los IDs.
+func idEvents(operation EventOperation, ids []string) []Event {
+	events := make([]Event, len(ids))
+	for index, id := range ids {
+		events[index] = Event{ID: id, Operation: operation}
+	}
+	return events
+}
+
+// touchItem corre change en una transacción, para los cambios en tablas asociadas que se ven en el
+// item (reservas, cambios de precio programados, refs, traducciones) y le suben la versión en su
+// mismo SQL. Si change devuelve changed, relee el item, registra el evento en la transacción y lo
+// publica después del commit, como cualquier escritura del item. Un item borrado no se avisa.
+func (service *Service) touchItem(ctx context.Context, itemID string, change func(tx RepositoryAPI) (bool, error)) error {
+	var (
+		item    Item
+		touched bool
+	)
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		changed, err := change(tx)
+		if err != nil || !changed {
+			return err
+		}
+		item, err = tx.GetByID(ctx, itemID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		touched = true
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, item))
+	})
+	if err == nil && touched {
+		service.publish(EventUpdated, item)
+	}
+	return err
+}
+
+// Create valida reglas y crea el item en DB.
+func (service *Service) Create(context context.Context, itemInput CreateItemInput) (Item, error) {
+	itemInput, err := service.prepareCreate(context, itemInput)
+	if err != nil {
+		return Item{}, err
+	}
+
+	// Delegamos persistencia al repo.
+	item, err := service.insert(context, itemInput)
+	if err != nil {
+		// Si el repo detecta duplicado, lo exponemos como error de dominio.
+		switch {
+		case errors.Is(err, ErrorDuplicateName):
+			return Item{}, ErrorDuplicateName
+		case errors.Is(err, ErrorDuplicateSlug):
+			return Item{}, ErrorDuplicateSlug
+		case errors.Is(err, ErrorDuplicateSKU):
+			return Item{}, ErrorDuplicateSKU
+		case errors.Is(err, ErrorDuplicateBarcode):
+			return Item{}, ErrorDuplicateBarcode
+		}
+		return Item{}, err
+	}
+
+	service.metrics.ItemCreated()
+	service.publish(EventCreated, item)
+	return item, nil
+}
+
+// prepareCreate completa los defaults del alta y valida el item con las mismas reglas para Create y
+// CreateMany.
+func (service *Service) prepareCreate(context context.Context, itemInput CreateItemInput) (CreateItemInput, error) {
+	if strings.TrimSpace(itemInput.Currency) == "" {
+		itemInput.Currency = service.defaultCurrency
+	}
+	if itemInput.TaxRateBPS == nil {
+		taxRate := service.defaultTaxRateBPS
+		itemInput.TaxRateBPS = &taxRate
+	}
+	if itemInput.MinOrderQty == nil {
+		minOrderQty := 1
+		itemInput.MinOrderQty = &minOrderQty
+	}
+	itemInput, err := normalizeCreateInput(itemInput)
+	if err != nil {
+		return CreateItemInput{}, err
+	}
+	// El vencimiento futuro solo se exige en el alta; PUT no lo toca y PATCH acepta fechas pasadas.
+	if itemInput.ExpiresAt, err = normalizeExpiresAt(itemInput.ExpiresAt, service.now().UTC(), true); err != nil {
+		return CreateItemInput{}, err
+	}
+	// El SKU es obligatorio solo en el alta: PUT no lo toca y los items previos a la columna no lo tienen.
+	if itemInput.SKU == nil {
+		return CreateItemInput{}, &ValidationError{Field: "sku", Message: "sku is required"}
+	}
+	if err := service.checkBackorderFloor(itemInput.Stock); err != nil {
+		return CreateItemInput{}, err
+	}
+
+	for _, validator := range service.validators {
+		if err := checkValidator(validator.ValidateCreate(context, itemInput)); err != nil {
+			return CreateItemInput{}, err
+		}
+	}
+	return itemInput, nil
+}
+
+// CreateMany valida inputs con las reglas del alta y los carga con un COPY: entran todos o ninguno,
+// y el error de validación dice qué fila falló. Es para cargas masivas (cmd/seed): un slug repetido
+// hace fallar la carga en lugar de buscar uno libre, y no registra historial, auditoría ni eventos.
+func (service *Service) CreateMany(ctx context.Context, inputs []CreateItemInput) (int64, error) {
+	prepared := make([]CreateItemInput, len(inputs))
+	for index, input := range inputs {
+		input, err := service.prepareCreate(ctx, input)
+		if err != nil {
+			return 0, fmt.Errorf("item %d: %w", index, err)
+		}
+		if input.Slug == "" {
+			input.Slug = slugify(input.Name)
+		}
+		prepared[index] = input
+	}
+	return service.repository.CopyInsert(ctx, prepared)
+}
+
+// CreateAll crea inputs en una sola transacción, con las mismas reglas, historial, auditoría y eventos
+// que Create: entran todos o ninguno. Es el import atómico. Si un input falla devuelve un *BatchError
+// con su posición. Un slug generado se busca libre dentro de la transacción, pero a diferencia de
+// Create no se reintenta si otro alta lo toma mientras tanto: la carga falla con ErrorDuplicateSlug.
+func (service *Service) CreateAll(ctx context.Context, inputs []CreateItemInput) ([]Item, error) {
+	prepared := make([]CreateItemInput, len(inputs))
+	for index, input := range inputs {
+		input, err := service.prepareCreate(ctx, input)
+		if err != nil {
+			return nil, &BatchError{Index: index, Err: err}
+		}
+		prepared[index] = input
+	}
+	var created []Item
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		created = make([]Item, 0, len(prepared))
+		for index, input := range prepared {
+			if input.Slug == "" {
+				slug, err := service.freeSlug(ctx, tx, slugify(input.Name), "")
+				if err != nil {
+					return err
+				}
+				input.Slug = slug
+			}
+			item, err := service.insertCreated(ctx, tx, input)
+			if err != nil {
+				return &BatchError{Index: index, Err: err}
+			}
+			created = append(created, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range created {
+		service.metrics.ItemCreated()
+		service.publish(EventCreated, item)
+	}
+	return created, nil
+}
+
+// ValidateCreate corre lo que haría Create con itemInput sin escribir nada: las validaciones del alta y
+// los chequeos de nombre, slug, SKU y barcode repetidos. Devuelve el input normalizado, que es el que
+// se compara contra los índices. No detecta lo que solo aparece al insertar, como una categoría o
+// marca inexistente, ni lo que otro alta ocupe mientras tanto.
+func (service *Service) ValidateCreate(ctx context.Context, itemInput CreateItemInput) (CreateItemInput, error) {
+	itemInput, err := service.prepareCreate(ctx, itemInput)
+	if err != nil {
+		return CreateItemInput{}, err
+	}
+	if err := service.repository.CheckUniqueKeys(ctx, itemInput); err != nil {
+		return CreateItemInput{}, err
+	}
+	return itemInput, nil
+}
+
+// UpsertBySKU crea el item de itemInput o, si ya hay uno con su SKU, le actualiza el precio, el
+// stock y la descripción (si viene); el resto del input solo cuenta para el alta. Es el modo upsert
+// de los imports: volver a correr el import de un proveedor actualiza lo que ya existe en lugar de
+// fallar. Valida itemInput con las reglas del alta y registra historial, auditoría y eventos igual
+// que Create o Update. Como en un PATCH, el stock de un item con variantes no se puede cambiar
+// (ErrorStockManagedByVariants). Devuelve true si el item se creó.
+func (service *Service) UpsertBySKU(ctx context.Context, itemInput CreateItemInput) (Item, bool, error) {
+	// El upsert no cambia la moneda de un item existente: el precio se valida con la suya.
+	var current Item
+	exists := false
+	if itemInput.SKU != nil {
+		var err error
+		current, err = service.repository.GetBySKU(ctx, normalizeSKU(*itemInput.SKU))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, false, err
+		}
+		if err == nil {
+			exists = true
+			itemInput.Currency = current.Currency
+		}
+	}
+	itemInput, err := service.prepareCreate(ctx, itemInput)
+	if err != nil {
+		return Item{}, false, err
+	}
+	if !exists {
+		item, err := service.insert(ctx, itemInput)
+		if err != nil {
+			return Item{}, false, err
+		}
+		service.metrics.ItemCreated()
+		service.publish(EventCreated, item)
+		return item, true, nil
+	}
+
+	// El precio y el stock se validan contra el item existente, como en un PATCH.
+	update := UpdateItemInput{Price: &itemInput.Price, Stock: &itemInput.Stock}
+	var item Item
+	err = service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		var err error
+		item, err = withHistory(ctx, tx, current.ID, StockReasonImport, func(tx RepositoryAPI, current Item) (Item, error) {
+			if itemInput.Stock < 0 && !backorderAllowed(current, update) {
+				return Item{}, ErrorInvalidStock
+			}
+			if err := checkCurrencyUpdate(current, update); err != nil {
+				return Item{}, err
+			}
+			item, created, err := tx.UpsertBySKU(ctx, itemInput)
+			if err == nil && created {
+				// Entre la búsqueda y el lock le cambiaron el SKU al item: el alta no tiene su historial.
+				return Item{}, fmt.Errorf("items: sku %s moved during upsert", *itemInput.SKU)
+			}
+			return item, err
+		})
+		if err != nil {
+			return err
+		}
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, item))
+	})
+	if err != nil {
+		return Item{}, false, err
+	}
+	service.metrics.ItemUpdated()
+	service.publish(EventUpdated, item)
+	return item, false, nil
+}
+
+// normalizeCreateInput recorta espacios y aplica las reglas del alta, que también usa PUT.
+// Las validaciones refuerzan los constraints de la DB.
+func normalizeCreateInput(itemInput CreateItemInput) (CreateItemInput, error) {
+	itemInput.Name = strings.TrimSpace(itemInput.Name)
+	itemInput.Price = strings.TrimSpace(itemInput.Price)
+	itemInput.Slug = strings.TrimSpace(itemInput.Slug)
+
+	if err := nameError(itemInput.Name); err != nil {
+		return CreateItemInput{}, err
+	}
+	if itemInput.Description != nil {
+		description := strings.TrimSpace(*itemInput.Description)
+		if err := descriptionError(description); err != nil {
+			return CreateItemInput{}, err
+		}
+		itemInput.Description = &description
+	}
+	if itemInput.Slug != "" && !isValidSlug(itemInput.Slug) {
+		return CreateItemInput{}, ErrorInvalidSlug
+	}
+	if itemInput.SKU != nil {
+		sku := normalizeSKU(*itemInput.SKU)
+		if !isValidSKU(sku) {
+			return CreateItemInput{}, ErrorInvalidSKU
+		}
+		itemInput.SKU = &sku
+	}
+	if itemInput.Barcode != nil {
+		barcode := strings.TrimSpace(*itemInput.Barcode)
+		if err := barcodeError(barcode); err != nil {
+			return CreateItemInput{}, err
+		}
+		itemInput.Barcode = &barcode
+	}
+	if itemInput.CategoryID != nil {
+		categoryID, err := normalizeCategoryID(*itemInput.CategoryID)
+		if err != nil {
+			return CreateItemInput{}, err
+		}
+		itemInput.CategoryID = &categoryID
+	}
+	if itemInput.BrandID != nil {
+		brandID, err := normalizeBrandID(*itemInput.BrandID)
+		if err != nil {
+			return CreateItemInput{}, err
+		}
+		itemInput.BrandID = &brandID
+	}
+	if err := attributesError(itemInput.Attributes); err != nil {
+		return CreateItemInput{}, err
+	}
+	if err := dimensionsError(itemInput.WeightGrams, itemInput.WidthMM, itemInput.HeightMM, itemInput.DepthMM); err != nil {
+		return CreateItemInput{}, err
+	}
+	if itemInput.TaxRateBPS != nil {
+		if err := taxRateError(*itemInput.TaxRateBPS); err != nil {
+			return CreateItemInput{}, err
+		}
+	}
+	if itemInput.MinOrderQty != nil && *itemInput.MinOrderQty < 1 {
+		return CreateItemInput{}, &ValidationError{Field: "min_order_qty", Message: "min_order_qty must be at least 1"}
+	}
+	if itemInput.ReorderPoint != nil && *itemInput.ReorderPoint < 0 {
+		return CreateItemInput{}, &ValidationError{Field: "reorder_point", Message: "reorder_point must be zero or greater"}
+	}
+	itemInput.State = ItemState(strings.ToLower(strings.TrimSpace(string(itemInput.State))))
+	switch itemInput.State {
+	case "":
+		itemInput.State = StatePublished
+	case StateDraft, StatePublished:
+	default:
+		return CreateItemInput{}, ErrorInvalidState
+	}
+	if itemInput.Price == "" {
+		return CreateItemInput{}, ErrorInvalidPrice
+	}
+	if !isValidPrice(itemInput.Price) {
+		return CreateItemInput{}, ErrorInvalidPrice
+	}
+	itemInput.Price = canonicalPrice(itemInput.Price)
+	if itemInput.SalePrice != nil {
+		salePrice := strings.TrimSpace(*itemInput.SalePrice)
+		if !isValidPrice(salePrice) || !isLowerPrice(salePrice, itemInput.Price) {
+			return CreateItemInput{}, ErrorInvalidSalePrice
+		}
+		salePrice = canonicalPrice(salePrice)
+		itemInput.SalePrice = &salePrice
+	}
+	// Sin moneda (PUT) la precisión del precio se valida después, con la moneda del item.
+	if itemInput.Currency != "" {
+		itemInput.Currency = currency.Normalize(itemInput.Currency)
+		if !currency.IsSupported(itemInput.Currency) {
+			return CreateItemInput{}, ErrorInvalidCurrency
+		}
+		if err := pricePrecisionError(itemInput.Price, itemInput.Currency); err != nil {
+			return CreateItemInput{}, err
+		}
+		if itemInput.SalePrice != nil {
+			if err := amountPrecisionError("sale_price", *itemInput.SalePrice, itemInput.Currency); err != nil {
+				return CreateItemInput{}, err
+			}
+		}
+	}
+	if itemInput.Stock < 0 && !itemInput.AllowBackorder {
+		return CreateItemInput{}, ErrorInvalidStock
+	}
+	return itemInput, nil
+}
+
+// Largos máximos de nombre y descripción, en caracteres (runas) y no en bytes: un nombre con
+// acentos no tiene que quedar más corto que uno en ASCII.
+const (
+	maxNameLength        = 200
+	maxDescriptionLength = 5000
+)
+
+// nameError valida un nombre ya recortado: no vacío y de a lo sumo maxNameLength caracteres.
+func nameError(name string) error {
+	if name == "" {
+		return ErrorInvalidName
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return &ValidationError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters", maxNameLength)}
+	}
+	return nil
+}
+
+// descriptionError valida el largo de una descripción ya recortada. Vacía es válida.
+func descriptionError(description string) error {
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return &ValidationError{Field: "description", Message: fmt.Sprintf("description must be at most %d characters", maxDescriptionLength)}
+	}
+	return nil
+}
+
+// normalizeCategoryID recorta el category_id y verifica que sea un UUID. Que la categoría exista
+// lo verifica la FK al escribir (ErrorUnknownCategory).
+func normalizeCategoryID(categoryID string) (string, error) {
+	categoryID = strings.TrimSpace(categoryID)
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return "", ErrorInvalidCategory
+	}
+	return categoryID, nil
+}
+
+// normalizeBrandID es normalizeCategoryID para brand_id; que la marca exista lo verifica la FK
+// (ErrorUnknownBrand).
+func normalizeBrandID(brandID string) (string, error) {
+	brandID = strings.TrimSpace(brandID)
+	if _, err := uuid.Parse(brandID); err != nil {
+		return "", &ValidationError{Field: "brand_id", Message: "brand_id must be a UUID"}
+	}
+	return brandID, nil
+}
+
+// checkBackorderFloor verifica que un stock negativo (de un item con allow_backorder) no pase el piso.
+func (service *Service) checkBackorderFloor(stock int) error {
+	if stock < 0 && stock < service.backorderFloor {
+		return ErrorStockBelowFloor
+	}
+	return nil
+}
+
+// backorderAllowed indica si el item admite stock negativo después del update: manda el flag del
+// update si viene y, si no, el que ya tiene el item.
+func backorderAllowed(current Item, input UpdateItemInput) bool {
+	if input.AllowBackorder != nil {
+		return *input.AllowBackorder
+	}
+	return current.AllowBackorder
+}
+
+// List devuelve una página de items y el total según los filtros.
+func (service *Service) List(context context.Context, page, limit int, filter ListFilter) (ListPage, error) {
+	// Validación mínima: paginación no puede ser absurda.
+	if page < 1 || limit < 1 {
+		return ListPage{}, ErrorInvalidInput
+	}
+	// page > maxOffset/limit equivale a page*limit > maxOffset sin riesgo de overflow con un page enorme.
+	if service.maxOffset > 0 && page > service.maxOffset/limit {
+		return ListPage{}, ErrorPaginationTooDeep
+	}
+
+	filter, err := service.normalizeListFilter(filter)
+	if err != nil {
+		return ListPage{}, err
+	}
+
+	offset := (page - 1) * limit
+
+	// La página y el total se leen en el mismo snapshot: un alta o baja concurrente
+	// no puede dejar un total que no coincida con los items.
+	var result ListPage
+	err = service.repository.InSnapshot(context, func(tx RepositoryAPI) error {
+		var err error
+		result, err = service.listPage(context, tx, filter, limit, offset)
+		return err
+	})
+	if err != nil {
+		return ListPage{}, err
+	}
+	result.Cursor = cursorAfter(filter, result.Items)
+	return result, nil
+}
+
+// listPage lee una página por offset y su total con repository (la del snapshot).
+func (service *Service) listPage(context context.Context, repository RepositoryAPI, filter ListFilter, limit, offset int) (ListPage, error) {
+	// Con estimación no conviene el COUNT(*) OVER (): recorrería toda la tabla igual que un COUNT(*).
+	if service.estimateCount && isUnfiltered(filter) {
+		total, ok, err := repository.EstimateCount(context, filter)
+		if err != nil {
+			return ListPage{}, err
+		}
+		if ok {
+			items, err := repository.List(context, filter, limit, offset)
+			if err != nil {
+				return ListPage{}, err
+			}
+			return ListPage{Items: items, Total: total, TotalIsEstimate: true}, nil
+		}
+	}
+
+	items, total, err := repository.ListWithTotal(context, filter, limit, offset)
+	if err != nil {
+		return ListPage{}, err
+	}
+	return ListPage{Items: items, Total: total}, nil
+}
+
+// ListAfter devuelve hasta limit items posteriores al cursor after, el total según los filtros
+// y si quedan más items después de esta página.
+// El cursor solo tiene sentido en el orden por defecto, así que rechaza sort y fuzzy. Una búsqueda
+// que adelanta las coincidencias de SKU o barcode sigue ese mismo orden (ver CreatedAtID.Ranked).
+func (service *Service) ListAfter(context context.Context, after CreatedAtID, limit int, filter ListFilter) (ListPage, error) {
+	if limit < 1 {
+		return ListPage{}, ErrorInvalidInput
+	}
+
+	filter, err := service.normalizeListFilter(filter)
+	if err != nil {
+		return ListPage{}, err
+	}
+	if !supportsCursor(filter) {
+		return ListPage{}, ErrorCursorUnsupported
+	}
+
+	// Página y total son dos queries: van en el mismo snapshot para que coincidan.
+	var result ListPage
+	err = service.repository.InSnapshot(context, func(tx RepositoryAPI) error {
+		// Pedimos uno de más para saber si hay página siguiente sin otra query.
+		items, err := tx.ListAfter(context, filter, after, limit+1)
+		if err != nil {
+			return err
+		}
+		result = ListPage{Items: items, HasMore: len(items) > limit}
+		if result.HasMore {
+			result.Items = items[:limit]
+		}
+		result.Total, result.TotalIsEstimate, err = service.count(context, tx, filter)
+		return err
+	})
+	if err != nil {
+		return ListPage{}, err
+	}
+	result.Cursor = cursorAfter(filter, result.Items)
+	return result, nil
+}
+
+// Export llama a fn con cada item que cumple filter, validado igual que en List, a medida que los lee
+// de la DB. Un error de validación sale antes de la primera llamada a fn.
+func (service *Service) Export(ctx context.Context, filter ListFilter, fn func(Item) error) error {
+	filter, err := service.normalizeListFilter(filter)
+	if err != nil {
+		return err
+	}
+	return service.repository.Export(ctx, filter, fn)
+}
+
+// Count devuelve cuántos items matchean el filtro, validado igual que en List, sin leer ninguna página.
+func (service *Service) Count(context context.Context, filter ListFilter) (ItemCount, error) {
+	filter, err := service.normalizeListFilter(filter)
+	if err != nil {
+		return ItemCount{}, err
+	}
+	total, estimated, err := service.count(context, service.repository, filter)
+	if err != nil {
+		return ItemCount{}, err
+	}
+	return ItemCount{Total: total, TotalIsEstimate: estimated}, nil
+}
+
+// count devuelve el total del listado. Sin filtros (o solo con los del listado por defecto) y con
+// WithEstimatedCount usa la estimación de la base; si todavía no hay estadísticas, o hay otros
+// filtros, hace el COUNT exacto.
+func (service *Service) count(context context.Context, repository RepositoryAPI, filter ListFilter) (int, bool, error) {
+	if service.estimateCount && isUnfiltered(filter) {
+		total, ok, err := repository.EstimateCount(context, filter)
+		if err != nil {
+			return 0, false, err
+		}
+		if ok {
+			return total, true, nil
+		}
+	}
+	total, err := repository.Count(context, filter)
+	return total, false, err
+}
+
+// supportsCursor indica si el listado con este filtro se puede paginar por cursor:
+// el cursor codifica (created_at, id), así que solo sirve con el orden por defecto y sin fuzzy.
+// El ranking de identificadores sí se admite: el cursor guarda de qué lado quedó el item.
+func supportsCursor(filter ListFilter) bool {
+	if filter.Fuzzy {
+		return false
+	}
+	return len(filter.Sort) == 0 || (len(filter.Sort) == 1 && filter.Sort[0] == defaultSort[0])
+}
+
+// cursorAfter devuelve la posición del último item de la página, o nil si no hay items o el filtro
+// (ya normalizado) no admite cursor.
+func cursorAfter(filter ListFilter, items []Item) *CreatedAtID {
+	if len(items) == 0 || !supportsCursor(filter) {
+		return nil
+	}
+	last := items[len(items)-1]
+	return &CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID, Ranked: identifierHit(filter, last)}
+}
+
+// normalizeListFilter valida los filtros del listado y completa los valores por defecto.
+// La comparten List y ListAfter.
+func (service *Service) normalizeListFilter(filter ListFilter) (ListFilter, error) {
+	// Normalizamos búsqueda.
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	switch filter.Match {
+	case "":
+		filter.Match = MatchContains
+	case MatchContains, MatchPrefix, MatchExact:
+	default:
+		return ListFilter{}, ErrorInvalidMatch
+	}
+
+	if err := validateSort(filter.Sort); err != nil {
+		return ListFilter{}, err
+	}
+
+	if err := validateSearchFields(filter.SearchFields); err != nil {
+		return ListFilter{}, err
+	}
+	if slices.Equal(filter.SearchFields, []string{searchFieldsAll}) {
+		filter.SearchFields = slices.Clone(allSearchFields)
+	}
+	if filter.NameEq != "" && filter.Query != "" {
+		return ListFilter{}, &FilterError{Field: "name_eq", Message: "name_eq cannot be combined with query"}
+	}
+	if filter.Fuzzy {
+		if err := validateFuzzy(filter); err != nil {
+			return ListFilter{}, err
+		}
+		filter.FuzzyThreshold = service.fuzzyThreshold
+	}
+	if err := normalizePriceRange(&filter); err != nil {
+		return ListFilter{}, err
+	}
+	if err := validateStockRange(filter); err != nil {
+		return ListFilter{}, err
+	}
+	if filter.MaxWeight != nil && *filter.MaxWeight < 0 {
+		return ListFilter{}, &FilterError{Field: "max_weight", Message: "max_weight must be a non-negative integer"}
+	}
+	if filter.MinOrderQtyLTE != nil && *filter.MinOrderQtyLTE < 1 {
+		return ListFilter{}, &FilterError{Field: "min_order_qty_lte", Message: "min_order_qty_lte must be at least 1"}
+	}
+	if filter.ExpiringBefore != "" && !isValidDate(filter.ExpiringBefore) {
+		return ListFilter{}, &FilterError{Field: "expiring_before", Message: "expiring_before must be a date (YYYY-MM-DD)"}
+	}
+	if filter.SKU != "" {
+		filter.SKU = normalizeSKU(filter.SKU)
+		if !isValidSKU(filter.SKU) {
+			return ListFilter{}, &FilterError{Field: "sku", Message: ErrorInvalidSKU.Error()}
+		}
+	}
+	if filter.CategoryID != "" {
+		categoryID, err := normalizeCategoryID(filter.CategoryID)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "category_id", Message: "category_id must be a UUID"}
+		}
+		filter.CategoryID = categoryID
+	}
+	if filter.BrandID != "" {
+		brandID, err := normalizeBrandID(filter.BrandID)
+		if err != nil {
+			return ListFilter{}, &FilterError{Field: "brand_id", Message: "brand_id must be a UUID"}
+		}
+		filter.BrandID = brandID
+	}
+	if filter.Currency != "" {
+		filter.Currency = currency.Normalize(filter.Currency)
+		if !currency.IsSupported(filter.Currency) {
+			return ListFilter{}, &FilterError{Field: "currency", Message: ErrorInvalidCurrency.Error()}
+		}
+	}
+	switch filter.Status {
+	case "", StatusActive, StatusInactive, StatusAll:
+	default:
+		return ListFilter{}, &FilterError{Field: "status", Message: "status must be one of: active, inactive, all"}
+	}
+	switch filter.State {
+	case "", StatePublished, StateDraft, StateAll:
+	default:
+		return ListFilter{}, &FilterError{Field: "state", Message: "state must be one of: draft, published, all"}
+	}
+	if len(filter.Attributes) > maxAttributes {
+		return ListFilter{}, &FilterError{Field: "attr", Message: fmt.Sprintf("at most %d attribute filters are allowed", maxAttributes)}
+	}
+	for key := range filter.Attributes {
+		if !isValidAttributeKey(key) {
+			return ListFilter{}, &FilterError{Field: attributeFilterQueryPrefix + key, Message: fmt.Sprintf("attribute keys must have between 1 and %d characters", maxAttributeKeyLength)}
+		}
+	}
+	return filter, nil
+}
+
+// maxSlugAttempts es cuántas veces se reintenta un slug generado que otro request tomó
+// entre la consulta de slugs usados y el INSERT/UPDATE.
+const maxSlugAttempts = 3
+
+// insert persiste el item. Si el cliente no mandó slug lo genera a partir del nombre, con sufijo
+// si ya está tomado; un slug explícito se usa tal cual y si está tomado es ErrorDuplicateSlug.
+func (service *Service) insert(context context.Context, itemInput CreateItemInput) (Item, error) {
+	if itemInput.Slug != "" {
+		return service.insertWithHistory(context, itemInput)
+	}
+
+	base := slugify(itemInput.Name)
+	for attempt := 1; ; attempt++ {
+		slug, err := service.freeSlug(context, service.repository, base, "")
+		if err != nil {
+			return Item{}, err
+		}
+		itemInput.Slug = slug
+		item, err := service.insertWithHistory(context, itemInput)
+		if errors.Is(err, ErrorDuplicateSlug) && attempt < maxSlugAttempts {
+			continue
+		}
+		return item, err
+	}
+}
+
+// insertWithHistory inserta el item y registra en la misma transacción el precio inicial y, si
+// arranca con stock, el movimiento inicial. Cada intento de insert usa su propia transacción: un slug
+// tomado la aborta.
+func (service *Service) insertWithHistory(ctx context.Context, itemInput CreateItemInput) (Item, error) {
+	var item Item
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		var err error
+		item, err = service.insertCreated(ctx, tx, itemInput)
+		return err
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// insertCreated inserta el item con tx y registra el precio inicial, el movimiento de stock inicial,
+// la auditoría y el evento del alta. Lo comparten Create y CreateAll.
+func (service *Service) insertCreated(ctx context.Context, tx RepositoryAPI, itemInput CreateItemInput) (Item, error) {
+	item, err := tx.Insert(ctx, itemInput)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := recordPriceChange(ctx, tx, nil, item, StockReasonCreate); err != nil {
+		return Item{}, err
+	}
+	if err := recordStockMovement(ctx, tx, item, item.Stock, StockReasonCreate); err != nil {
+		return Item{}, err
+	}
+	if err := recordAudit(ctx, tx, AuditCreated, nil, item); err != nil {
+		return Item{}, err
+	}
+	if err := service.recordEvents(ctx, tx, itemEvent(EventCreated, item)); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// freeSlug devuelve base o el primer base-N que no usa ningún item salvo exceptID.
+func (service *Service) freeSlug(context context.Context, repository RepositoryAPI, base, exceptID string) (string, error) {
+	taken, err := repository.TakenSlugs(context, base, exceptID)
+	if err != nil {
+		return "", err
+	}
+	return nextFreeSlug(base, taken), nil
+}
+
+// GetByBarcode obtiene un item por su código de barras (EAN-13 o UPC-A), para los scanners del depósito.
+func (service *Service) GetByBarcode(context context.Context, barcode string) (Item, error) {
+	item, err := service.repository.GetByBarcode(context, barcode)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// GetBySKU obtiene un item por su SKU, para la lectura de los scanners del depósito.
+func (service *Service) GetBySKU(context context.Context, sku string) (Item, error) {
+	item, err := service.repository.GetBySKU(context, sku)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// Related devuelve hasta limit items parecidos al item id, ordenados por similitud de nombre.
+// Usa el mismo umbral que la búsqueda fuzzy. Devuelve ErrorNotFound si el item no existe.
+func (service *Service) Related(context context.Context, id string, limit int) ([]Item, error) {
+	item, err := service.Get(context, id)
+	if err != nil {
+		return nil, err
+	}
+	return service.repository.Related(context, item, service.fuzzyThreshold, limit)
+}
+
+// Suggest devuelve hasta limit sugerencias para el prefijo que el cliente está tipeando.
+// Usa el mismo umbral que la búsqueda fuzzy para los nombres que no empiezan con prefix.
+func (service *Service) Suggest(context context.Context, prefix string, limit int) ([]Suggestion, error) {
+	return service.repository.Suggest(context, prefix, service.fuzzyThreshold, limit)
+}
+
+// DidYouMean devuelve el nombre a sugerir para una búsqueda de query que no encontró nada: el
+// nombre de item más parecido, si supera el umbral configurado. "" si no hay ninguno, si está
+// desactivado o si el nombre es la misma query (sugerirla no ayuda).
+func (service *Service) DidYouMean(ctx context.Context, query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || service.didYouMeanThreshold == 0 {
+		return "", nil
+	}
+	name, err := service.repository.ClosestName(ctx, query, service.didYouMeanThreshold)
+	if err != nil || strings.EqualFold(name, query) {
+		return "", err
+	}
+	return name, nil
+}
+
+// Publish pasa el item a published. Antes verifica que esté completo (publishError); publicar un
+// item ya publicado no cambia nada y lo devuelve tal cual.
+func (service *Service) Publish(ctx context.Context, id string) (Item, error) {
+	return service.changeState(ctx, id, StatePublished)
+}
+
+// Unpublish vuelve el item a draft: deja de aparecer en el listado por defecto y por slug.
+func (service *Service) Unpublish(ctx context.Context, id string) (Item, error) {
+	return service.changeState(ctx, id, StateDraft)
+}
+
+// changeState cambia el estado editorial con el item bloqueado, así la validación de publish ve
+// el mismo item que se publica.
+func (service *Service) changeState(ctx context.Context, id string, state ItemState) (Item, error) {
+	var item Item
+	changed := false
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		current, err := tx.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.State == state {
+			item = current
+			return nil
+		}
+		if state == StatePublished {
+			if err := publishError(current); err != nil {
+				return err
+			}
+		}
+		if item, err = tx.Update(ctx, id, UpdateItemInput{State: &state}); err != nil {
+			return err
+		}
+		changed = true
+		if err := recordAudit(ctx, tx, AuditUpdated, &current, item); err != nil {
+			return err
+		}
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, item))
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	if changed {
+		service.metrics.ItemUpdated()
+		service.publish(EventUpdated, item)
+	}
+	return item, nil
+}
+
+// publishError verifica que el item tenga lo mínimo para mostrarse: nombre y precio.
+func publishError(item Item) error {
+	if strings.TrimSpace(item.Name) == "" {
+		return &ValidationError{Field: "name", Message: "name is required to publish"}
+	}
+	if !isValidPrice(item.Price) {
+		return &ValidationError{Field: "price", Message: "price is required to publish"}
+	}
+	return nil
+}
+
+// GetBySlug obtiene un item por su slug.
+func (service *Service) GetBySlug(context context.Context, slug string) (Item, error) {
+	return service.repository.GetBySlug(context, slug)
+}
+
+// Get obtiene un item por ID.
+// Nota: el service no valida formato UUID; eso es más de HTTP/entrada (handler).
+func (service *Service) Get(ctx context.Context, id string) (Item, error) {
+	it, err := service.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, ErrorNotFound
+		}
+		return Item{}, err
+	}
+	return it, nil
+}
+
+// CollectionVersion devuelve la versión actual del catálogo. Es una query barata que permite
+// al handler responder 304 al listado sin correr la página ni el total.
+func (service *Service) CollectionVersion(ctx context.Context) (CollectionVersion, error) {
+	if service.versionCache != nil {
+		return service.versionCache.get(ctx, service.repository.CollectionVersion)
+	}
+	return service.repository.CollectionVersion(ctx)
+}
+
+// Update valida reglas y actualiza parcialmente un item.
+// No valida UUID, eso es responsabilidad del handler (capa HTTP).
+// Con IfVersion el update solo se aplica si el item sigue en esa versión (ErrorVersionMismatch si no).
+func (service *Service) Update(context context.Context, id string, itemInputUpdated UpdateItemInput) (Item, error) {
+	itemInputUpdated, err := service.normalizeUpdateInput(context, id, itemInputUpdated)
+	if err != nil {
+		return Item{}, err
+	}
+
+	var item Item
+	err = service.repository.InTx(context, func(tx RepositoryAPI) error {
+		var err error
+		if item, err = service.persistUpdate(context, tx, id, itemInputUpdated); err != nil {
+			return updateError(err)
+		}
+		return service.recordEvents(context, tx, itemEvent(EventUpdated, item))
+	})
+	if err != nil {
+		return Item{}, err
+	}
+
+	service.metrics.ItemUpdated()
+	service.publish(EventUpdated, item)
+	return item, nil
+}
+
+// ApplyJSONPatch aplica un JSON Patch (RFC 6902) sobre el item actual.
+// Lee el item con lock, aplica las operaciones, valida el resultado con las reglas de Update
+// y lo persiste, todo en la misma transacción. Con ifVersion (If-Match) el item leído tiene que
+// estar en esa versión; si no, devuelve ErrorVersionMismatch sin aplicar nada.
+func (service *Service) ApplyJSONPatch(ctx context.Context, id string, operations []PatchOperation, ifVersion *int) (Item, error) {
+	if len(operations) > maxPatchOperations {
+		return Item{}, ErrorPatchTooLarge
+	}
+
+	var item Item
+	changed := false
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		current, err := tx.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if ifVersion != nil && current.Version != *ifVersion {
+			return ErrorVersionMismatch
+		}
+
+		input, hasChanges, err := applyJSONPatch(current, operations)
+		if err != nil {
+			return err
+		}
+		if !hasChanges {
+			item = current
+			return nil
+		}
+
+		if item, err = service.update(ctx, tx, id, input); err != nil {
+			return err
+		}
+		changed = true
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, item))
+	})
+	if err != nil {
+		return Item{}, err
+	}
+
+	if changed {
+		service.metrics.ItemUpdated()
+		service.publish(EventUpdated, item)
+	}
+	return item, nil
+}
+
+// update valida y persiste un update usando repository (el del service o el de una transacción).
+func (service *Service) update(context context.Context, repository RepositoryAPI, id string, itemInputUpdated UpdateItemInput) (Item, error) {
+	itemInputUpdated, err := service.normalizeUpdateInput(context, id, itemInputUpdated)
+	if err != nil {
+		return Item{}, err
+	}
+
+	item, err := service.persistUpdate(context, repository, id, itemInputUpdated)
+	if err != nil {
+		return Item{}, updateError(err)
+	}
+
+	return item, nil
+}
+
+// normalizeUpdateInput aplica las reglas de negocio de un update (incluidos los validators)
+// y devuelve el input con los valores recortados. No toca la base.
+func (service *Service) normalizeUpdateInput(context context.Context, id string, itemInputUpdated UpdateItemInput) (UpdateItemInput, error) {
+	// Debe venir al menos un campo.
+	// description en null cuenta como cambio (limpia el campo).
+	if itemInputUpdated.Name == nil && itemInputUpdated.Slug == nil && !itemInputUpdated.DescriptionPresent &&
+		itemInputUpdated.Description == nil && itemInputUpdated.Price == nil && !itemInputUpdated.SalePricePresent && itemInputUpdated.Stock == nil &&
+		itemInputUpdated.AllowBackorder == nil && itemInputUpdated.SKU == nil && !itemInputUpdated.BarcodePresent &&
+		!itemInputUpdated.CategoryIDPresent && !itemInputUpdated.BrandIDPresent && itemInputUpdated.Status == nil &&
+		itemInputUpdated.Currency == nil && !itemInputUpdated.AttributesPresent && !itemInputUpdated.WeightGramsPresent &&
+		!itemInputUpdated.WidthMMPresent && !itemInputUpdated.HeightMMPresent && !itemInputUpdated.DepthMMPresent &&
+		itemInputUpdated.TaxRateBPS == nil && itemInputUpdated.MinOrderQty == nil && !itemInputUpdated.ExpiresAtPresent &&
+		!itemInputUpdated.ReorderPointPresent {
+		return UpdateItemInput{}, ErrorInvalidInput
+	}
+
+	// Validaciones de negocio (mínimas).
+	if itemInputUpdated.Name != nil {
+		name := strings.TrimSpace(*itemInputUpdated.Name)
+		if err := nameError(name); err != nil {
+			return UpdateItemInput{}, err
+		}
+		itemInputUpdated.Name = &name
+	}
+
+	// description en null la limpia; con valor se recorta como el nombre.
+	if itemInputUpdated.Description != nil {
+		description := strings.TrimSpace(*itemInputUpdated.Description)
+		if err := descriptionError(description); err != nil {
+			return UpdateItemInput{}, err
+		}
+		itemInputUpdated.Description = &description
+	}
+
+	if itemInputUpdated.Slug != nil {
+		slug := strings.TrimSpace(*itemInputUpdated.Slug)
+		if !isValidSlug(slug) {
+			return UpdateItemInput{}, ErrorInvalidSlug
+		}
+		itemInputUpdated.Slug = &slug
+	}
+
+	if itemInputUpdated.SKU != nil {
+		sku := normalizeSKU(*itemInputUpdated.SKU)
+		if !isValidSKU(sku) {
+			return UpdateItemInput{}, ErrorInvalidSKU
+		}
+		itemInputUpdated.SKU = &sku
+	}
+
+	// barcode en null lo limpia, igual que description.
+	if itemInputUpdated.Barcode != nil {
+		barcode := strings.TrimSpace(*itemInputUpdated.Barcode)
+		if err := barcodeError(barcode); err != nil {
+			return UpdateItemInput{}, err
+		}
+		itemInputUpdated.Barcode = &barcode
+	}
+
+	if itemInputUpdated.CategoryID != nil {
+		categoryID, err := normalizeCategoryID(*itemInputUpdated.CategoryID)
+		if err != nil {
+			return UpdateItemInput{}, err
+		}
+		itemInputUpdated.CategoryID = &categoryID
+	}
+
+	if itemInputUpdated.BrandID != nil {
+		brandID, err := normalizeBrandID(*itemInputUpdated.BrandID)
+		if err != nil {
+			return UpdateItemInput{}, err
+		}
+		itemInputUpdated.BrandID = &brandID
+	}
+
+	if err := attributesError(itemInputUpdated.Attributes); err != nil {
+		return UpdateItemInput{}, err
+	}
+
+	// peso y medidas en null se limpian; con valor tienen que estar en rango.
+	if err := dimensionsError(itemInputUpdated.WeightGrams, itemInputUpdated.WidthMM, itemInputUpdated.HeightMM, itemInputUpdated.DepthMM); err != nil {
+		return UpdateItemInput{}, err
+	}
+
+	if itemInputUpdated.TaxRateBPS != nil {
+		if err := taxRateError(*itemInputUpdated.TaxRateBPS); err != nil {
+			return UpdateItemInput{}, err
+		}
+	}
+
+	expiresAt, err := normalizeExpiresAt(itemInputUpdated.ExpiresAt, time.Time{}, false)
+	if err != nil {
+		return UpdateItemInput{}, err
+	}
+	itemInputUpdated.ExpiresAt = expiresAt
+
+	if itemInputUpdated.MinOrderQty != nil && *itemInputUpdated.MinOrderQty < 1 {
+		return UpdateItemInput{}, &ValidationError{Field: "min_order_qty", Message: "min_order_qty must be at least 1"}
+	}
+	if itemInputUpdated.ReorderPoint != nil && *itemInputUpdated.ReorderPoint < 0 {
+		return UpdateItemInput{}, &ValidationError{Field: "reorder_point", Message: "reorder_point must be zero or greater"}
+	}
+
+	if itemInputUpdated.Status != nil {
+		status := ItemStatus(strings.ToLower(strings.TrimSpace(string(*itemInputUpdated.Status))))
+		if status != StatusActive && status != StatusInactive {
+			return UpdateItemInput{}, ErrorInvalidStatus
+		}
+		itemInputUpdated.Status = &status
+	}
+
+	if itemInputUpdated.Price != nil {
+		price := strings.TrimSpace(*itemInputUpdated.Price)
+		if price == "" {
+			return UpdateItemInput{}, ErrorInvalidPrice
+		}
+		if !isValidPrice(price) {
+			return UpdateItemInput{}, ErrorInvalidPrice
+		}
+		price = canonicalPrice(price)
+		itemInputUpdated.Price = &price
+	}
+
+	// Contra el precio actual lo compara ck_items_sale_price_below_price; acá solo si vienen los dos.
+	if itemInputUpdated.SalePrice != nil {
+		salePrice := strings.TrimSpace(*itemInputUpdated.SalePrice)
+		if !isValidPrice(salePrice) {
+			return UpdateItemInput{}, ErrorInvalidSalePrice
+		}
+		if itemInputUpdated.Price != nil && !isLowerPrice(salePrice, *itemInputUpdated.Price) {
+			return UpdateItemInput{}, ErrorInvalidSalePrice
+		}
+		salePrice = canonicalPrice(salePrice)
+		itemInputUpdated.SalePrice = &salePrice
+	}
+
+	// Que la moneda pueda cambiar y la precisión del precio en la moneda del item se verifican con
+	// el item bloqueado en persistUpdate; acá solo el código.
+	if itemInputUpdated.Currency != nil {
+		code := currency.Normalize(*itemInputUpdated.Currency)
+		if !currency.IsSupported(code) {
+			return UpdateItemInput{}, ErrorInvalidCurrency
+		}
+		itemInputUpdated.Currency = &code
+	}
+
+	// Un stock negativo depende de allow_backorder: si el update no lo trae, se decide con el item
+	// bloqueado en persistUpdate.
+	if itemInputUpdated.Stock != nil && *itemInputUpdated.Stock < 0 {
+		if itemInputUpdated.AllowBackorder != nil && !*itemInputUpdated.AllowBackorder {
+			return UpdateItemInput{}, ErrorInvalidStock
+		}
+		if err := service.checkBackorderFloor(*itemInputUpdated.Stock); err != nil {
+			return UpdateItemInput{}, err
+		}
+	}
+
+	for _, validator := range service.validators {
+		if err := checkValidator(validator.ValidateUpdate(context, id, itemInputUpdated)); err != nil {
+			return UpdateItemInput{}, err
+		}
+	}
+
+	return itemInputUpdated, nil
+}
+
+// updateError reduce un error de persistencia de un update al error de dominio correspondiente.
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, ErrorNotFound):
+		return ErrorNotFound
+	case errors.Is(err, ErrorDuplicateName):
+		return ErrorDuplicateName
+	case errors.Is(err, ErrorDuplicateSlug):
+		return ErrorDuplicateSlug
+	case errors.Is(err, ErrorDuplicateSKU):
+		return ErrorDuplicateSKU
+	case errors.Is(err, ErrorDuplicateBarcode):
+		return ErrorDuplicateBarcode
+	default:
+		return err
+	}
+}
+
+// maxBulkUpdateEntries es la cantidad máxima de entradas de un update masivo.
+const maxBulkUpdateEntries = 200
+
+// UpdateMany aplica varios updates parciales en una sola transacción: o se aplican todos o ninguno.
+// Primero valida todas las entradas; si alguna es inválida no toca la base. Después las aplica en
+// orden y la primera que falla (no existe, nombre o slug repetido) revierte la transacción.
+// Los errores por entrada vuelven en cada BulkUpdateResult; el error de retorno queda para
+// pedidos inválidos en conjunto (vacío, más de 200 entradas, IDs repetidos) y fallas inesperadas.
+func (service *Service) UpdateMany(context context.Context, entries []BulkUpdateEntry) ([]BulkUpdateResult, error) {
+	if err := bulkUpdateEntriesError(entries); err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkUpdateResult, len(entries))
+	inputs := make([]UpdateItemInput, len(entries))
+	valid := true
+	for index, entry := range entries {
+		results[index].ID = entry.ID
+		input, err := service.normalizeUpdateInput(context, entry.ID, entry.Input)
+		if err != nil {
+			if !errors.Is(err, ErrorInvalidInput) {
+				return nil, err
+			}
+			results[index].Err = err
+			valid = false
+			continue
+		}
+		inputs[index] = input
+	}
+	if !valid {
+		return markNotApplied(results), nil
+	}
+
+	failed := false
+	err := service.repository.InTx(context, func(tx RepositoryAPI) error {
+		for index, entry := range entries {
+			item, err := service.persistUpdate(context, tx, entry.ID, inputs[index])
+			if err != nil {
+				err = updateError(err)
+				if errors.Is(err, ErrorNotFound) || errors.Is(err, ErrorDuplicateName) || errors.Is(err, ErrorDuplicateSlug) ||
+					errors.Is(err, ErrorDuplicateSKU) || errors.Is(err, ErrorDuplicateBarcode) || errors.Is(err, ErrorInvalidStock) ||
+					errors.Is(err, ErrorInvalidSalePrice) {
+					results[index].Err = err
+					failed = true
+				}
+				return err
+			}
+			results[index].Item = item
+		}
+		events := make([]Event, len(results))
+		for index, result := range results {
+			events[index] = itemEvent(EventUpdated, result.Item)
+		}
+		return service.recordEvents(context, tx, events...)
+	})
+	if failed {
+		return markNotApplied(results), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		service.metrics.ItemUpdated()
+		service.publish(EventUpdated, result.Item)
+	}
+	return results, nil
+}
+
+// UpdateEach aplica varios updates parciales cada uno en su propia transacción, como PATCH
+// /items/{id}: una entrada inválida o que choca con otro item queda con su error y las demás se
+// aplican igual. Un error que no es de la entrada (la DB no responde) corta el pedido y devuelve
+// ese error; las entradas anteriores ya quedaron aplicadas.
+func (service *Service) UpdateEach(context context.Context, entries []BulkUpdateEntry) ([]BulkUpdateResult, error) {
+	if err := bulkUpdateEntriesError(entries); err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkUpdateResult, len(entries))
+	for index, entry := range entries {
+		results[index].ID = entry.ID
+		item, err := service.Update(context, entry.ID, entry.Input)
+		if err != nil {
+			if !errors.Is(err, ErrorInvalidInput) && !errors.Is(err, ErrorNotFound) && !errors.Is(err, ErrorDuplicateName) &&
+				!errors.Is(err, ErrorDuplicateSlug) && !errors.Is(err, ErrorDuplicateSKU) && !errors.Is(err, ErrorDuplicateBarcode) {
+				return nil, err
+			}
+			results[index].Err = err
+			continue
+		}
+		results[index].Item = item
+	}
+	return results, nil
+}
+
+// bulkUpdateEntriesError valida el pedido de un update masivo en conjunto: la cantidad de entradas
+// y que ningún id se repita.
+func bulkUpdateEntriesError(entries []BulkUpdateEntry) error {
+	if len(entries) == 0 || len(entries) > maxBulkUpdateEntries {
+		return &ValidationError{
+			Field:   "updates",
+			Message: fmt.Sprintf("updates must have between 1 and %d entries", maxBulkUpdateEntries),
+		}
+	}
+	seen := make(map[string]bool, len(entries))
+	for index, entry := range entries {
+		if seen[entry.ID] {
+			return &ValidationError{Field: fmt.Sprintf("updates[%d].id", index), Message: "duplicate id " + entry.ID}
+		}
+		seen[entry.ID] = true
+	}
+	return nil
+}
+
+// markNotApplied marca con ErrorNotApplied las entradas sin error propio y descarta los items
+// que quedaron de una transacción revertida.
+func markNotApplied(results []BulkUpdateResult) []BulkUpdateResult {
+	for index := range results {
+		results[index].Item = Item{}
+		if results[index].Err == nil {
+			results[index].Err = ErrorNotApplied
+		}
+	}
+	return results
+}
+
+// persistUpdate aplica el update. Si cambia el nombre y el cliente no mandó slug, regenera el slug
+// a partir del nombre nuevo (sin contar el slug actual del item como tomado).
+// A diferencia del alta no se reintenta si otro request toma el slug en el medio: el update puede
+// correr dentro de una transacción, que queda abortada tras el conflicto; el cliente recibe 409.
+func (service *Service) persistUpdate(context context.Context, repository RepositoryAPI, id string, itemInputUpdated UpdateItemInput) (Item, error) {
+	if itemInputUpdated.Name != nil && itemInputUpdated.Slug == nil {
+		slug, err := service.freeSlug(context, repository, slugify(*itemInputUpdated.Name), id)
+		if err != nil {
+			return Item{}, err
+		}
+		itemInputUpdated.Slug = &slug
+	}
+	// Stock, precio, moneda y un merge-patch de attributes dependen del item actual, así que se
+	// validan con el item bloqueado, y
+	// los cambios de stock y de precio quedan en sus historiales. Si el stock o el precio no cambiaron
+	// (aunque vengan en el PATCH) no se registra nada. La auditoría necesita el item anterior en
+	// cualquier update.
+	return withHistory(context, repository, id, StockReasonUpdate, func(tx RepositoryAPI, current Item) (Item, error) {
+		if itemInputUpdated.AttributesPatch != nil {
+			attributes := mergePatch(current.Attributes, itemInputUpdated.AttributesPatch).(map[string]any)
+			if err := attributesError(attributes); err != nil {
+				return Item{}, err
+			}
+			itemInputUpdated.Attributes, itemInputUpdated.AttributesPatch = attributes, nil
+		}
+		if itemInputUpdated.Stock != nil && *itemInputUpdated.Stock < 0 && !backorderAllowed(current, itemInputUpdated) {
+			return Item{}, ErrorInvalidStock
+		}
+		if err := checkCurrencyUpdate(current, itemInputUpdated); err != nil {
+			return Item{}, err
+		}
+		return tx.Update(context, id, itemInputUpdated)
+	})
+}
+
+// checkCurrencyUpdate valida un update de precio o moneda contra el item actual: cambiar la
+// moneda requiere AllowCurrencyChange (mandar la misma no es un cambio) y el precio y el de oferta
+// resultantes tienen que respetar los decimales de la moneda resultante.
+func checkCurrencyUpdate(current Item, input UpdateItemInput) error {
+	code := current.Currency
+	if input.Currency != nil && *input.Currency != current.Currency {
+		if !input.AllowCurrencyChange {
+			return ErrorCurrencyChange
+		}
+		code = *input.Currency
+	}
+	currencyChanged := code != current.Currency
+	if input.Price != nil || currencyChanged {
+		price := current.Price
+		if input.Price != nil {
+			price = *input.Price
+		}
+		if err := pricePrecisionError(price, code); err != nil {
+			return err
+		}
+	}
+	salePrice := current.SalePrice
+	if input.SalePricePresent {
+		salePrice = input.SalePrice
+	}
+	if salePrice != nil && (input.SalePrice != nil || currencyChanged) {
+		return amountPrecisionError("sale_price", *salePrice, code)
+	}
+	return nil
+}
+
+// Motivos de los movimientos de stock que registra el service. Un ajuste puede traer su propio motivo.
+// El historial de precios usa los mismos (create, update, replace), PriceReasonSchedule y PriceReasonBulkAdjustment.
+const (
+	StockReasonCreate     = "create"
+	StockReasonUpdate     = "update"
+	StockReasonReplace    = "replace"
+	StockReasonAdjustment = "adjustment"
+	// StockReasonImport es una fila de un import en modo upsert que actualizó un item existente.
+	StockReasonImport = "import"
+	// StockReasonVariants es el recálculo del stock de un item como la suma de sus variantes.
+	StockReasonVariants = "variants"
+	// PriceReasonSchedule es un cambio de precio programado que aplicó el job.
+	PriceReasonSchedule = "schedule"
+	// PriceReasonBulkAdjustment es un ajuste masivo de POST /items/price-adjustments.
+	PriceReasonBulkAdjustment = "bulk_adjustment"
+)
+
+// withHistory corre write (un Update o Replace) en una transacción, con el item bloqueado, y registra
+// en los historiales la diferencia entre el stock anterior y el nuevo, si cambió el precio, y en la
+// auditoría los campos que cambiaron.
+// Llamado con el repositorio de una transacción (UpdateMany, JSON Patch) se suma a esa transacción.
+// write recibe el item como estaba antes del cambio.
+func withHistory(ctx context.Context, repository RepositoryAPI, id, reason string, write func(tx RepositoryAPI, current Item) (Item, error)) (Item, error) {
+	var item Item
+	err := repository.InTx(ctx, func(tx RepositoryAPI) error {
+		current, err := tx.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if item, err = write(tx, current); err != nil {
+			return err
+		}
+		if item.Stock != current.Stock {
+			if err := ensureStockEditable(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		if err := recordPriceChange(ctx, tx, &current.Price, item, reason); err != nil {
+			return err
+		}
+		if err := recordStockMovement(ctx, tx, item, item.Stock-current.Stock, reason); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditUpdated, &current, item)
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// recordPriceChange registra el cambio del precio de lista de oldPrice (nil en el alta) al de item.
+// Los dos precios vienen de la base con el mismo formato ("10.00"), así que un PATCH que manda el
+// mismo precio ("10") no es un cambio y no se registra.
+func recordPriceChange(ctx context.Context, repository RepositoryAPI, oldPrice *string, item Item, reason string) error {
+	if oldPrice != nil && *oldPrice == item.Price {
+		return nil
+	}
+	return repository.InsertPriceChange(ctx, PriceHistoryEntry{
+		ItemID:    item.ID,
+		OldPrice:  oldPrice,
+		NewPrice:  item.Price,
+		Reason:    reason,
+		RequestID: middleware.GetReqID(ctx),
+	})
+}
+
+// recordStockMovement registra un cambio de stock de item, que ya tiene el stock resultante.
+// Un delta 0 no es un movimiento y no se registra. El request id es el que dejó el middleware
+// RequestID en el contexto; fuera de un request HTTP queda vacío.
+func recordStockMovement(ctx context.Context, repository RepositoryAPI, item Item, delta int, reason string) error {
+	if delta == 0 {
+		return nil
+	}
+	return repository.InsertStockMovement(ctx, StockMovement{
+		ItemID:         item.ID,
+		Delta:          delta,
+		ResultingStock: item.Stock,
+		Reason:         reason,
+		RequestID:      middleware.GetReqID(ctx),
+	})
+}
+
+// Replace reemplaza el item completo (PUT) con las mismas reglas que el alta. Lo que no viene
+// vuelve a su valor por defecto: description NULL, stock 0 y, sin slug, el generado a partir del nombre.
+// Los validators reciben el cambio como un UpdateItemInput con todos los campos presentes.
+func (service *Service) Replace(context context.Context, id string, itemInput ReplaceItemInput) (Item, error) {
+	normalized, err := normalizeCreateInput(CreateItemInput{
+		Name:           itemInput.Name,
+		Slug:           itemInput.Slug,
+		Description:    itemInput.Description,
+		Price:          itemInput.Price,
+		Stock:          itemInput.Stock,
+		AllowBackorder: itemInput.AllowBackorder,
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	if err := service.checkBackorderFloor(normalized.Stock); err != nil {
+		return Item{}, err
+	}
+	itemInput = ReplaceItemInput{
+		Name:           normalized.Name,
+		Slug:           normalized.Slug,
+		Description:    normalized.Description,
+		Price:          normalized.Price,
+		Stock:          normalized.Stock,
+		AllowBackorder: normalized.AllowBackorder,
+	}
+
+	asUpdate := UpdateItemInput{
+		Name:               &itemInput.Name,
+		Description:        itemInput.Description,
+		DescriptionPresent: true,
+		Price:              &itemInput.Price,
+		Stock:              &itemInput.Stock,
+		AllowBackorder:     &itemInput.AllowBackorder,
+	}
+	if itemInput.Slug != "" {
+		asUpdate.Slug = &itemInput.Slug
+	}
+	for _, validator := range service.validators {
+		if err := checkValidator(validator.ValidateUpdate(context, id, asUpdate)); err != nil {
+			return Item{}, err
+		}
+	}
+
+	if itemInput.Slug == "" {
+		// Sin contar el slug actual del item como tomado: si el nombre no cambió, queda el mismo.
+		if itemInput.Slug, err = service.freeSlug(context, service.repository, slugify(itemInput.Name), id); err != nil {
+			return Item{}, err
+		}
+	}
+
+	item, err := withHistory(context, service.repository, id, StockReasonReplace, func(tx RepositoryAPI, current Item) (Item, error) {
+		if err := pricePrecisionError(itemInput.Price, current.Currency); err != nil {
+			return Item{}, err
+		}
+		return tx.Replace(context, id, itemInput)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorNotFound):
+			return Item{}, ErrorNotFound
+		case errors.Is(err, ErrorDuplicateName):
+			return Item{}, ErrorDuplicateName
+		case errors.Is(err, ErrorDuplicateSlug):
+			return Item{}, ErrorDuplicateSlug
+		default:
+			return Item{}, err
+		}
+	}
+	return item, nil
+}
+
+// maxCopyNameAttempts es la cantidad de nombres "(copy N)" que prueba Duplicate antes de devolver 409.
+const maxCopyNameAttempts = 5
+
+// Duplicate crea un item nuevo copiando nombre, descripción y precio de id. El nombre lleva el sufijo
+// " (copy)" y, si ya existe, " (copy 2)", " (copy 3)", etc. El stock se copia solo si CopyStock;
+// si no arranca en 0. El SKU no se copia (es único): la copia lleva el de input, que es obligatorio.
+// La categoría y la marca se copian. El slug se genera a partir del nombre nuevo.
+func (service *Service) Duplicate(context context.Context, id string, input DuplicateItemInput) (Item, error) {
+	sku := normalizeSKU(input.SKU)
+	if sku == "" {
+		return Item{}, &ValidationError{Field: "sku", Message: "sku is required"}
+	}
+	if !isValidSKU(sku) {
+		return Item{}, ErrorInvalidSKU
+	}
+
+	source, err := service.Get(context, id)
+	if err != nil {
+		return Item{}, err
+	}
+
+	itemInput := CreateItemInput{SKU: &sku, Description: source.Description, Price: source.Price, SalePrice: source.SalePrice, AllowBackorder: source.AllowBackorder}
+	if source.Category != nil {
+		itemInput.CategoryID = &source.Category.ID
+	}
+	if source.Brand != nil {
+		itemInput.BrandID = &source.Brand.ID
+	}
+	itemInput.Currency = source.Currency
+	itemInput.TaxRateBPS = &source.TaxRateBPS
+	itemInput.MinOrderQty = &source.MinOrderQty
+	itemInput.ExpiresAt = source.ExpiresAt
+	itemInput.ReorderPoint = source.ReorderPoint
+	itemInput.State = source.State
+	itemInput.Attributes = source.Attributes
+	itemInput.WeightGrams, itemInput.WidthMM, itemInput.HeightMM, itemInput.DepthMM = source.WeightGrams, source.WidthMM, source.HeightMM, source.DepthMM
+	if input.CopyStock {
+		itemInput.Stock = source.Stock
+	}
+
+	for attempt := 1; ; attempt++ {
+		itemInput.Name = copyName(source.Name, attempt)
+		for _, validator := range service.validators {
+			if err := checkValidator(validator.ValidateCreate(context, itemInput)); err != nil {
+				return Item{}, err
+			}
+		}
+
+		item, err := service.insert(context, itemInput)
+		if errors.Is(err, ErrorDuplicateName) && attempt < maxCopyNameAttempts {
+			continue
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrorDuplicateName):
+				return Item{}, ErrorDuplicateName
+			case errors.Is(err, ErrorDuplicateSlug):
+				return Item{}, ErrorDuplicateSlug
+			case errors.Is(err, ErrorDuplicateSKU):
+				return Item{}, ErrorDuplicateSKU
+			}
+			return Item{}, err
+		}
+
+		service.metrics.ItemCreated()
+		service.publish(EventCreated, item)
+		return item, nil
+	}
+}
+
+// copyName arma el nombre de la copia número attempt: "Phone (copy)", "Phone (copy 2)", ...
+func copyName(name string, attempt int) string {
+	if attempt < 2 {
+		return name + " (copy)"
+	}
+	return fmt.Sprintf("%s (copy %d)", name, attempt)
+}
+
+// Delete borra lógicamente un item por ID y devuelve su último estado, ya con deleted_at.
+// Con ifVersion (If-Match) solo lo borra si sigue en esa versión; si no, devuelve ErrorVersionMismatch.
+func (service *Service) Delete(context context.Context, id string, ifVersion *int) (Item, error) {
+	var item Item
+	err := service.repository.InTx(context, func(tx RepositoryAPI) error {
+		var err error
+		if item, err = tx.Delete(context, id, ifVersion); err != nil {
+			return err
+		}
+		if err := recordDeletes(context, tx, item.ID); err != nil {
+			return err
+		}
+		return service.recordEvents(context, tx, itemEvent(EventDeleted, item))
+	})
+	if err != nil {
+		return Item{}, err
+	}
+	service.metrics.ItemDeleted()
+	service.publish(EventDeleted, item)
+	return item, nil
+}
+
+// Purge borra definitivamente un item que ya está en la papelera. Un item vivo devuelve ErrorNotFound.
+func (service *Service) Purge(context context.Context, id string) error {
+	return service.repository.Purge(context, id)
+}
+
+// maxStockReasonLength es el largo máximo del motivo de un ajuste de stock.
+const maxStockReasonLength = 200
+
+// AdjustStock suma delta al stock del item (negativo para descontar) y registra el movimiento con
+// el motivo del ajuste, todo en la misma transacción y con el item bloqueado. El stock no puede
+// quedar negativo (ErrorInvalidStock) salvo que el item tenga allow_backorder, y en ese caso no por
+// debajo del piso (ErrorStockBelowFloor).
+func (service *Service) AdjustStock(ctx context.Context, id string, input StockAdjustmentInput) (Item, error) {
+	if input.Delta == 0 {
+		return Item{}, &ValidationError{Field: "delta", Message: "delta must not be zero"}
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		reason = StockReasonAdjustment
+	}
+	if len(reason) > maxStockReasonLength {
+		return Item{}, &ValidationError{Field: "reason", Message: fmt.Sprintf("reason must be at most %d characters", maxStockReasonLength)}
+	}
+
+	var item Item
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		current, err := tx.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := ensureStockEditable(ctx, tx, id); err != nil {
+			return err
+		}
+		stock := current.Stock + input.Delta
+		if stock < 0 && !current.AllowBackorder {
+			return ErrorInvalidStock
+		}
+		if err := service.checkBackorderFloor(stock); err != nil {
+			return err
+		}
+		if item, err = tx.Update(ctx, id, UpdateItemInput{Stock: &stock}); err != nil {
+			return err
+		}
+		if err := recordStockMovement(ctx, tx, item, input.Delta, reason); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditUpdated, &current, item); err != nil {
+			return err
+		}
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, item))
+	})
+	if err != nil {
+		return Item{}, err
+	}
+
+	service.metrics.ItemUpdated()
+	service.publish(EventUpdated, item)
+	return item, nil
+}
+
+// StockMovements devuelve una página del historial de stock del item, del más nuevo al más viejo.
+// Un item que no existe (o está en la papelera) devuelve ErrorNotFound.
+func (service *Service) StockMovements(ctx context.Context, id string, page, limit int) (StockMovementPage, error) {
+	if page < 1 || limit < 1 {
+		return StockMovementPage{}, ErrorInvalidInput
+	}
+
+	var result StockMovementPage
+	err := service.repository.InSnapshot(ctx, func(tx RepositoryAPI) error {
+		if _, err := tx.GetByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrorNotFound
+			}
+			return err
+		}
+		movements, total, err := tx.ListStockMovements(ctx, id, limit, (page-1)*limit)
+		result = StockMovementPage{Movements: movements, Total: total}
+		return err
+	})
+	if err != nil {
+		return StockMovementPage{}, err
+	}
+	return result, nil
+}
+
+// AdjustPrices aplica un ajuste masivo (porcentaje o monto fijo) al precio de lista de los items
+// del filtro, o con DryRun solo lo calcula. Antes de aplicarlo, en la misma transacción, lo calcula
+// y lo rechaza entero si algún precio quedaría en cero o menos (ErrorAdjustedPriceNotPositive) o en
+// o por debajo de su precio de oferta (ErrorAdjustedBelowSalePrice), y si cambia más de
+// maxUnconfirmedPriceAdjustment items sin ConfirmOver que los cubra (*PriceAdjustmentLimitError).
+// Cada precio que cambia queda en el historial con PriceReasonBulkAdjustment.
+func (service *Service) AdjustPrices(ctx context.Context, input PriceAdjustmentInput) (PriceAdjustmentResult, error) {
+	filter, err := service.normalizeListFilter(ListFilter{
+		CategoryID: input.Filter.CategoryID,
+		BrandID:    input.Filter.BrandID,
+		Query:      input.Filter.Query,
+		Currency:   input.Filter.Currency,
+	})
+	if err != nil {
+		// El filtro viene en el body: el error es de campo, no de query param.
+		var filterError *FilterError
+		if errors.As(err, &filterError) {
+			return PriceAdjustmentResult{}, &ValidationError{Field: "filter." + filterError.Field, Message: filterError.Message}
+		}
+		return PriceAdjustmentResult{}, err
+	}
+	adjustment, err := normalizeAdjustment(input.Adjustment, filter.Currency)
+	if err != nil {
+		return PriceAdjustmentResult{}, err
+	}
+	if input.ConfirmOver != nil && *input.ConfirmOver < 0 {
+		return PriceAdjustmentResult{}, &ValidationError{Field: "confirm_over", Message: "confirm_over must be a non-negative integer"}
+	}
+
+	var (
+		result   PriceAdjustmentResult
+		adjusted []string
+	)
+	run := func(tx RepositoryAPI) error {
+		preview, err := tx.PreviewPriceAdjustment(ctx, filter, adjustment, priceAdjustmentSampleSize)
+		if err != nil {
+			return err
+		}
+		switch {
+		case preview.NonPositive > 0:
+			return ErrorAdjustedPriceNotPositive
+		case preview.NotAboveSalePrice > 0:
+			return ErrorAdjustedBelowSalePrice
+		}
+		result = PriceAdjustmentResult{Affected: preview.Affected, DryRun: input.DryRun, Sample: preview.Sample}
+		if input.DryRun || preview.Affected == 0 {
+			return nil
+		}
+		if preview.Affected > maxUnconfirmedPriceAdjustment && (input.ConfirmOver == nil || preview.Affected > *input.ConfirmOver) {
+			return &PriceAdjustmentLimitError{Affected: preview.Affected, Limit: maxUnconfirmedPriceAdjustment}
+		}
+		adjusted, err = tx.AdjustPrices(ctx, filter, adjustment, PriceReasonBulkAdjustment, middleware.GetReqID(ctx))
+		result.Affected = len(adjusted)
+		if err != nil {
+			return err
+		}
+		return service.recordEvents(ctx, tx, idEvents(EventUpdated, adjusted)...)
+	}
+	if input.DryRun {
+		err = service.repository.InSnapshot(ctx, run)
+	} else {
+		err = service.repository.InTx(ctx, run)
+	}
+	if err != nil {
+		return PriceAdjustmentResult{}, err
+	}
+
+	for _, event := range idEvents(EventUpdated, adjusted) {
+		service.metrics.ItemUpdated()
+		service.publishEvent(event)
+	}
+	return result, nil
+}
+
+// RestockNeeded devuelve una página de los items que hay que reponer: los que tienen punto de
+// reposición y stock en o debajo de él, del mayor faltante al menor. Cada item trae
+// SuggestedOrderQty, la cantidad a pedir para volver al doble del punto de reposición.
+func (service *Service) RestockNeeded(ctx context.Context, page, limit int) (ListPage, error) {
+	if page < 1 || limit < 1 {
+		return ListPage{}, ErrorInvalidInput
+	}
+
+	items, total, err := service.repository.RestockNeeded(ctx, limit, (page-1)*limit)
+	if err != nil {
+		return ListPage{}, err
+	}
+	for index := range items {
+		items[index].SuggestedOrderQty = suggestedOrderQty(items[index])
+	}
+	return ListPage{Items: items, Total: total}, nil
+}
+
+// suggestedOrderQty es reorder_point*2 - stock, al menos 1 (un reorder_point 0 con stock 0 igual
+// pide una unidad). nil si el item no tiene punto de reposición.
+func suggestedOrderQty(item Item) *int {
+	if item.ReorderPoint == nil {
+		return nil
+	}
+	quantity := max(*item.ReorderPoint*2-item.Stock, 1)
+	return &quantity
+}
+
+// Changes devuelve la tanda del feed de cambios que sigue a after: hasta limit items, borrados
+// lógicamente incluidos (con Deleted), por updated_at e id ascendentes. Un after sin ID empieza en
+// su instante inclusive. Los cambios de los últimos changesSettleWindow todavía no salen.
+func (service *Service) Changes(ctx context.Context, after UpdatedAtID, limit int) (ChangesPage, error) {
+	if limit < 1 {
+		return ChangesPage{}, ErrorInvalidInput
+	}
+	if after.ID == "" {
+		after.ID = uuid.Nil.String()
+	}
+
+	// Pedimos uno de más para saber si quedan cambios sin otra query.
+	items, err := service.repository.Changes(ctx, after, service.now().Add(-changesSettleWindow), limit+1)
+	if err != nil {
+		return ChangesPage{}, err
+	}
+	page := ChangesPage{Items: items, Next: after, HasMore: len(items) > limit}
+	if page.HasMore {
+		page.Items = items[:limit]
+	}
+	for index := range page.Items {
+		page.Items[index].Deleted = page.Items[index].DeletedAt != nil
+	}
+	if count := len(page.Items); count > 0 {
+		last := page.Items[count-1]
+		page.Next = UpdatedAtID{UpdatedAt: last.UpdatedAt, ID: last.ID}
+	}
+	return page, nil
 }
 
-// Service contiene reglas de negocio de items.
-type Service struct {
-	repository RepositoryAPI
+// PriceHistory devuelve una página del historial de precios del item dentro de filter, del más
+// nuevo al más viejo. Un item que no existe (o está en la papelera) devuelve ErrorNotFound.
+func (service *Service) PriceHistory(ctx context.Context, id string, filter PriceHistoryFilter, page, limit int) (PriceHistoryPage, error) {
+	if page < 1 || limit < 1 {
+		return PriceHistoryPage{}, ErrorInvalidInput
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return PriceHistoryPage{}, &FilterError{Field: "to", Message: "to must be after from"}
+	}
+
+	var result PriceHistoryPage
+	err := service.repository.InSnapshot(ctx, func(tx RepositoryAPI) error {
+		if _, err := tx.GetByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrorNotFound
+			}
+			return err
+		}
+		entries, total, err := tx.ListPriceHistory(ctx, id, filter, limit, (page-1)*limit)
+		result = PriceHistoryPage{Entries: entries, Total: total}
+		return err
+	})
+	if err != nil {
+		return PriceHistoryPage{}, err
+	}
+	return result, nil
 }
 
-// NewService crea un service de items.
-func NewService(repository RepositoryAPI) *Service {
-	return &Service{repository: repository}
+// Audit devuelve una página de la auditoría del item, de la entrada más nueva a la más vieja.
+// Un item que no existe (o está en la papelera) devuelve ErrorNotFound.
+func (service *Service) Audit(ctx context.Context, id string, page, limit int) (AuditPage, error) {
+	if page < 1 || limit < 1 {
+		return AuditPage{}, ErrorInvalidInput
+	}
+
+	var result AuditPage
+	err := service.repository.InSnapshot(ctx, func(tx RepositoryAPI) error {
+		if _, err := tx.GetByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrorNotFound
+			}
+			return err
+		}
+		entries, total, err := tx.ListAudit(ctx, id, limit, (page-1)*limit)
+		result = AuditPage{Entries: entries, Total: total}
+		return err
+	})
+	if err != nil {
+		return AuditPage{}, err
+	}
+	return result, nil
 }
 
-// Create valida reglas y crea el item en DB.
-func (service *Service) Create(context context.Context, itemInput CreateItemInput) (Item, error) {
-	// Normalización mínima.
-	itemInput.Name = strings.TrimSpace(itemInput.Name)
-	itemInput.Price = strings.TrimSpace(itemInput.Price)
+// ensureStockEditable devuelve ErrorStockManagedByVariants si el item tiene variantes. Se llama
+// dentro de la transacción de un cambio de stock, con el item ya bloqueado.
+func ensureStockEditable(ctx context.Context, tx RepositoryAPI, itemID string) error {
+	count, _, err := tx.VariantStock(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorStockManagedByVariants
+	}
+	return nil
+}
 
-	// Validaciones de negocio (refuerzan constraints DB).
-	if itemInput.Name == "" {
-		return Item{}, ErrorInvalidInput
+// Variants devuelve las variantes del item. Un item que no existe (o está en la papelera) devuelve ErrorNotFound.
+func (service *Service) Variants(ctx context.Context, itemID string) ([]Variant, error) {
+	var variants []Variant
+	err := service.repository.InSnapshot(ctx, func(tx RepositoryAPI) error {
+		if _, err := tx.GetByID(ctx, itemID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrorNotFound
+			}
+			return err
+		}
+		var err error
+		variants, err = tx.ListVariants(ctx, itemID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	if itemInput.Price == "" {
-		return Item{}, ErrorInvalidInput
+	return variants, nil
+}
+
+// CreateVariant agrega una variante al item y recalcula el stock del item. Un item que no existe
+// devuelve ErrorNotFound; un SKU que ya usa otra variante del item, ErrorDuplicateVariantSKU.
+func (service *Service) CreateVariant(ctx context.Context, itemID string, input CreateVariantInput) (Variant, error) {
+	sku := normalizeSKU(input.SKU)
+	if !isValidSKU(sku) {
+		return Variant{}, ErrorInvalidSKU
 	}
-	if !isValidPrice(itemInput.Price) {
-		return Item{}, ErrorInvalidInput
+	input.SKU = sku
+	price, err := normalizeVariantPrice(input.Price)
+	if err != nil {
+		return Variant{}, err
 	}
-	if itemInput.Stock < 0 {
-		return Item{}, ErrorInvalidInput
+	input.Price = price
+	if input.Stock < 0 {
+		return Variant{}, ErrorInvalidStock
+	}
+	if err := variantAttributesError(input.Attributes); err != nil {
+		return Variant{}, err
 	}
 
-	// Delegamos persistencia al repo.
-	item, err := service.repository.Insert(context, itemInput)
+	var variant Variant
+	err = service.changeVariants(ctx, itemID, func(tx RepositoryAPI, current Item) error {
+		if input.Price != nil {
+			if err := pricePrecisionError(*input.Price, current.Currency); err != nil {
+				return err
+			}
+		}
+		var err error
+		variant, err = tx.InsertVariant(ctx, itemID, input)
+		return err
+	})
 	if err != nil {
-		// Si el repo detecta duplicado, lo exponemos como error de dominio.
-		if errors.Is(err, ErrorDuplicateName) {
-			return Item{}, ErrorDuplicateName
+		return Variant{}, err
+	}
+	return variant, nil
+}
+
+// UpdateVariant cambia los campos presentes de la variante y recalcula el stock del item.
+// Devuelve ErrorVariantNotFound si la variante no existe o es de otro item.
+func (service *Service) UpdateVariant(ctx context.Context, itemID, variantID string, input UpdateVariantInput) (Variant, error) {
+	if input.SKU == nil && !input.PricePresent && input.Stock == nil && !input.AttributesPresent {
+		return Variant{}, ErrorInvalidInput
+	}
+	if input.SKU != nil {
+		sku := normalizeSKU(*input.SKU)
+		if !isValidSKU(sku) {
+			return Variant{}, ErrorInvalidSKU
 		}
-		return Item{}, err
+		input.SKU = &sku
+	}
+	price, err := normalizeVariantPrice(input.Price)
+	if err != nil {
+		return Variant{}, err
+	}
+	input.Price = price
+	if input.Stock != nil && *input.Stock < 0 {
+		return Variant{}, ErrorInvalidStock
+	}
+	if err := variantAttributesError(input.Attributes); err != nil {
+		return Variant{}, err
 	}
 
-	return item, nil
+	var variant Variant
+	err = service.changeVariants(ctx, itemID, func(tx RepositoryAPI, current Item) error {
+		if input.Price != nil {
+			if err := pricePrecisionError(*input.Price, current.Currency); err != nil {
+				return err
+			}
+		}
+		var err error
+		variant, err = tx.UpdateVariant(ctx, itemID, variantID, input)
+		return err
+	})
+	if err != nil {
+		return Variant{}, err
+	}
+	return variant, nil
 }
 
-func (service *Service) List(context context.Context, page, limit int, nameQuery string) ([]Item, int, error) {
-	// Validación mínima: paginación no puede ser absurda.
-	if page < 1 || limit < 1 {
-		return nil, 0, ErrorInvalidInput
+// DeleteVariant borra la variante y recalcula el stock del item; al borrar la última, el item
+// queda con stock 0 y vuelve a manejar su stock directamente.
+func (service *Service) DeleteVariant(ctx context.Context, itemID, variantID string) error {
+	return service.changeVariants(ctx, itemID, func(tx RepositoryAPI, current Item) error {
+		return tx.DeleteVariant(ctx, itemID, variantID)
+	})
+}
+
+// changeVariants corre change con el item bloqueado y después deja su stock como la suma del de
+// sus variantes, registrando la diferencia en el historial. El lock serializa los cambios de
+// variantes del mismo item, así dos altas simultáneas no pisan la suma.
+func (service *Service) changeVariants(ctx context.Context, itemID string, change func(tx RepositoryAPI, current Item) error) error {
+	var (
+		item    Item
+		changed bool
+	)
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		current, err := tx.GetForUpdate(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if err := change(tx, current); err != nil {
+			return err
+		}
+		_, total, err := tx.VariantStock(ctx, itemID)
+		if err != nil || total == current.Stock {
+			return err
+		}
+		if item, err = tx.Update(ctx, itemID, UpdateItemInput{Stock: &total}); err != nil {
+			return err
+		}
+		changed = true
+		if err := recordStockMovement(ctx, tx, item, total-current.Stock, StockReasonVariants); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditUpdated, &current, item); err != nil {
+			return err
+		}
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, item))
+	})
+	if err == nil && changed {
+		service.metrics.ItemUpdated()
+		service.publish(EventUpdated, item)
 	}
+	return err
+}
 
-	// Normalizamos búsqueda.
-	nameQuery = strings.TrimSpace(nameQuery)
+// normalizeVariantPrice recorta, valida y canoniza el precio propio de una variante; nil es heredar el del item.
+func normalizeVariantPrice(price *string) (*string, error) {
+	if price == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*price)
+	if !isValidPrice(trimmed) {
+		return nil, ErrorInvalidPrice
+	}
+	canonical := canonicalPrice(trimmed)
+	return &canonical, nil
+}
 
-	offset := (page - 1) * limit
+// maxDuePriceSchedules es cuántos cambios de precio vencidos aplica como máximo cada corrida del job;
+// los que sobran quedan para la siguiente.
+const maxDuePriceSchedules = 100
 
-	items, err := service.repository.List(context, nameQuery, limit, offset)
+// PriceSchedules devuelve los cambios de precio del item, pendientes y aplicados. Un item que no
+// existe (o está en la papelera) devuelve ErrorNotFound.
+func (service *Service) PriceSchedules(ctx context.Context, itemID string) ([]PriceSchedule, error) {
+	var schedules []PriceSchedule
+	err := service.repository.InSnapshot(ctx, func(tx RepositoryAPI) error {
+		if _, err := tx.GetByID(ctx, itemID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrorNotFound
+			}
+			return err
+		}
+		var err error
+		schedules, err = tx.ListPriceSchedules(ctx, itemID)
+		return err
+	})
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CreatePriceSchedule programa un cambio de precio del item. effective_at tiene que ser futuro y
+// new_price respetar los decimales de la moneda del item y quedar por encima de su precio de oferta
+// (ErrorScheduledBelowSalePrice). Un item que no existe devuelve ErrorNotFound; otro cambio pendiente
+// para el mismo instante, ErrorDuplicatePriceSchedule. Como cambia next_price_change, notifica el item.
+func (service *Service) CreatePriceSchedule(ctx context.Context, itemID string, input CreatePriceScheduleInput) (PriceSchedule, error) {
+	newPrice := strings.TrimSpace(input.NewPrice)
+	if !isValidPrice(newPrice) {
+		return PriceSchedule{}, &ValidationError{Field: "new_price", Message: "new_price must be a positive amount with up to 2 decimals"}
+	}
+	input.NewPrice = canonicalPrice(newPrice)
+	if input.EffectiveAt.IsZero() {
+		return PriceSchedule{}, &ValidationError{Field: "effective_at", Message: "effective_at is required"}
+	}
+	if !input.EffectiveAt.After(service.now()) {
+		return PriceSchedule{}, &ValidationError{Field: "effective_at", Message: "effective_at must be in the future"}
 	}
+	input.EffectiveAt = input.EffectiveAt.UTC()
 
-	total, err := service.repository.Count(context, nameQuery)
+	var schedule PriceSchedule
+	err := service.touchItem(ctx, itemID, func(tx RepositoryAPI) (bool, error) {
+		item, err := tx.GetByID(ctx, itemID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, ErrorNotFound
+			}
+			return false, err
+		}
+		if err := amountPrecisionError("new_price", input.NewPrice, item.Currency); err != nil {
+			return false, err
+		}
+		if item.SalePrice != nil && !isLowerPrice(*item.SalePrice, input.NewPrice) {
+			return false, ErrorScheduledBelowSalePrice
+		}
+		schedule, err = tx.InsertPriceSchedule(ctx, itemID, input)
+		return err == nil, err
+	})
 	if err != nil {
-		return nil, 0, err
+		return PriceSchedule{}, err
 	}
+	return schedule, nil
+}
 
-	return items, total, nil
+// DeletePriceSchedule cancela un cambio de precio pendiente y notifica el item. Devuelve
+// ErrorPriceScheduleNotFound si no existe, es de otro item, ya se aplicó o falló.
+func (service *Service) DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error {
+	return service.touchItem(ctx, itemID, func(tx RepositoryAPI) (bool, error) {
+		if err := tx.DeletePriceSchedule(ctx, itemID, scheduleID); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
 }
 
-// Get obtiene un item por ID.
-// Nota: el service no valida formato UUID; eso es más de HTTP/entrada (handler).
-func (service *Service) Get(ctx context.Context, id string) (Item, error) {
-	it, err := service.repository.GetByID(ctx, id)
+// ApplyDuePriceSchedules aplica los cambios de precio vencidos y devuelve a cuántos items les cambió
+// el precio. Cada cambio va en su propia transacción; si uno falla queda pendiente para la próxima
+// corrida, se sigue con los demás y el error se devuelve al final. Los que ya no se pueden aplicar
+// (ver applyPriceSchedule) quedan fallidos y no cuentan.
+func (service *Service) ApplyDuePriceSchedules(ctx context.Context) (int, error) {
+	due, err := service.repository.DuePriceSchedules(ctx, maxDuePriceSchedules)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	var errs []error
+	for _, schedule := range due {
+		item, outcome, err := service.applyPriceSchedule(ctx, schedule.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("price schedule %s: %w", schedule.ID, err))
+			continue
+		}
+		switch outcome {
+		case scheduleApplied:
+			applied++
+			service.metrics.ItemUpdated()
+			service.publish(EventUpdated, item)
+		case scheduleFailed:
+			service.publish(EventUpdated, item)
+		}
+	}
+	return applied, errors.Join(errs...)
+}
+
+// scheduleOutcome es lo que pasó con el item al procesar un cambio de precio vencido.
+type scheduleOutcome int
+
+const (
+	// scheduleSkipped: el item no cambió (otra instancia lo aplicó, el item se borró o ya tenía ese precio).
+	scheduleSkipped scheduleOutcome = iota
+	// scheduleApplied: el precio del item cambió.
+	scheduleApplied
+	// scheduleFailed: el cambio quedó fallido; el item sólo cambió de next_price_change.
+	scheduleFailed
+)
+
+// applyPriceSchedule marca el cambio como aplicado y actualiza el precio del item en la misma
+// transacción. Si otra instancia ya lo aplicó no hace nada; si el item se borró, el cambio se
+// consume sin tocar el item. Si el item cambió desde que se programó y el precio ya no es válido
+// (no queda por encima del de oferta o no respeta los decimales de la moneda), el cambio se marca
+// fallido con el motivo y sale de la cola.
+func (service *Service) applyPriceSchedule(ctx context.Context, scheduleID string) (updated Item, outcome scheduleOutcome, err error) {
+	err = service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		schedule, err := tx.MarkPriceScheduleApplied(ctx, scheduleID)
+		if errors.Is(err, ErrorPriceScheduleNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		current, err := tx.GetForUpdate(ctx, schedule.ItemID)
+		if errors.Is(err, ErrorNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if current.Price == schedule.NewPrice {
+			return nil
+		}
+		// El precio de oferta y la moneda del item pudieron cambiar después de programar el cambio.
+		if reason := priceScheduleFailure(schedule.NewPrice, current); reason != "" {
+			if err := tx.MarkPriceScheduleFailed(ctx, schedule.ID, reason); err != nil {
+				return err
+			}
+			if updated, err = tx.GetByID(ctx, current.ID); err != nil {
+				return err
+			}
+			outcome = scheduleFailed
+			return service.recordEvents(ctx, tx, itemEvent(EventUpdated, updated))
+		}
+		if updated, err = tx.Update(ctx, current.ID, UpdateItemInput{Price: &schedule.NewPrice}); err != nil {
+			return err
+		}
+		outcome = scheduleApplied
+		if err := recordPriceChange(ctx, tx, &current.Price, updated, PriceReasonSchedule); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditUpdated, &current, updated); err != nil {
+			return err
+		}
+		return service.recordEvents(ctx, tx, itemEvent(EventUpdated, updated))
+	})
+	if err != nil {
+		return Item{}, scheduleSkipped, err
+	}
+	return updated, outcome, nil
+}
+
+// priceScheduleFailure devuelve por qué newPrice ya no se puede aplicar a item, o "" si se puede.
+func priceScheduleFailure(newPrice string, item Item) string {
+	if item.SalePrice != nil && !isLowerPrice(*item.SalePrice, newPrice) {
+		return PriceScheduleFailureBelowSalePrice
+	}
+	if pricePrecisionError(newPrice, item.Currency) != nil {
+		return PriceScheduleFailureCurrencyPrecision
+	}
+	return ""
+}
+
+// AddExternalRef agrega una ref externa al item y lo notifica. Es idempotente: si el item ya tiene
+// esa ref la devuelve con created en false, sin notificar. Si la ref es de otro item devuelve
+// *ExternalRefConflictError; un item que no existe, ErrorNotFound.
+func (service *Service) AddExternalRef(ctx context.Context, itemID string, input ExternalRefInput) (ref ExternalRef, created bool, err error) {
+	input.System, input.ExternalID = normalizeExternalRef(input.System, input.ExternalID)
+	if err := externalRefError(input.System, input.ExternalID); err != nil {
+		return ExternalRef{}, false, err
+	}
+
+	err = service.touchItem(ctx, itemID, func(tx RepositoryAPI) (bool, error) {
+		item, err := tx.GetByID(ctx, itemID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, ErrorNotFound
+			}
+			return false, err
+		}
+		for _, existing := range item.Refs {
+			if existing.System == input.System && existing.ExternalID == input.ExternalID {
+				ref = existing
+				return false, nil
+			}
+		}
+		ref, err = tx.InsertExternalRef(ctx, itemID, input)
+		if errors.Is(err, ErrorDuplicateExternalRef) {
+			return false, &ExternalRefConflictError{System: input.System, ExternalID: input.ExternalID}
+		}
+		created = err == nil
+		return created, err
+	})
+	if err != nil {
+		return ExternalRef{}, false, err
+	}
+	return ref, created, nil
+}
+
+// RemoveExternalRef quita una ref externa del item y lo notifica. Devuelve ErrorExternalRefNotFound
+// si no existe o es de otro item.
+func (service *Service) RemoveExternalRef(ctx context.Context, itemID, system, externalID string) error {
+	system, externalID = normalizeExternalRef(system, externalID)
+	return service.touchItem(ctx, itemID, func(tx RepositoryAPI) (bool, error) {
+		if err := tx.DeleteExternalRef(ctx, itemID, system, externalID); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// GetByExternalRef obtiene el item al que apunta la ref, para los jobs de sincronización.
+func (service *Service) GetByExternalRef(ctx context.Context, system, externalID string) (Item, error) {
+	system, externalID = normalizeExternalRef(system, externalID)
+	item, err := service.repository.GetByExternalRef(ctx, system, externalID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return Item{}, ErrorNotFound
 		}
 		return Item{}, err
 	}
-	return it, nil
+	return item, nil
 }
 
-// Update valida reglas y actualiza parcialmente un item.
-// No valida UUID, eso es responsabilidad del handler (capa HTTP).
-func (service *Service) Update(context context.Context, id string, itemInputUpdated UpdateItemInput) (Item, error) {
-	// Debe venir al menos un campo.
-	if itemInputUpdated.Name == nil && itemInputUpdated.Description == nil && itemInputUpdated.Price == nil && itemInputUpdated.Stock == nil {
-		return Item{}, ErrorInvalidInput
+// Locales devuelve los locales soportados; el primero es el de los campos base de los items.
+func (service *Service) Locales() []string {
+	return service.locales
+}
+
+// Translations devuelve las traducciones del item. ErrorNotFound si el item no existe.
+func (service *Service) Translations(ctx context.Context, itemID string) ([]Translation, error) {
+	var translations []Translation
+	err := service.repository.InSnapshot(ctx, func(tx RepositoryAPI) error {
+		if _, err := tx.GetByID(ctx, itemID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrorNotFound
+			}
+			return err
+		}
+		var err error
+		translations, err = tx.ListTranslations(ctx, itemID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return translations, nil
+}
 
-	// Validaciones de negocio (mínimas).
-	if itemInputUpdated.Name != nil {
-		name := strings.TrimSpace(*itemInputUpdated.Name)
-		if name == "" {
-			return Item{}, ErrorInvalidInput
+// PutTranslation crea o reemplaza la traducción del item a tag y notifica el item. El locale tiene
+// que ser uno de los soportados y distinto del base (ErrorUnsupportedLocale); el nombre y la
+// descripción se recortan y validan con las reglas del alta.
+func (service *Service) PutTranslation(ctx context.Context, itemID, tag string, input TranslationInput) (Translation, error) {
+	tag = locale.Normalize(tag)
+	if !slices.Contains(service.locales[1:], tag) {
+		return Translation{}, ErrorUnsupportedLocale
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := nameError(input.Name); err != nil {
+		return Translation{}, err
+	}
+	if input.Description != nil {
+		description := strings.TrimSpace(*input.Description)
+		if err := descriptionError(description); err != nil {
+			return Translation{}, err
 		}
-		itemInputUpdated.Name = &name
+		input.Description = &description
 	}
 
-	if itemInputUpdated.Price != nil {
-		price := strings.TrimSpace(*itemInputUpdated.Price)
-		if price == "" {
-			return Item{}, ErrorInvalidInput
+	var translation Translation
+	err := service.touchItem(ctx, itemID, func(tx RepositoryAPI) (bool, error) {
+		if _, err := tx.GetByID(ctx, itemID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return false, ErrorNotFound
+			}
+			return false, err
 		}
-		if !isValidPrice(price) {
-			return Item{}, ErrorInvalidInput
+		var err error
+		translation, err = tx.UpsertTranslation(ctx, itemID, tag, input)
+		return err == nil, err
+	})
+	if err != nil {
+		return Translation{}, err
+	}
+	return translation, nil
+}
+
+// Localize pasa los items al locale pedido: les pone name y description traducidos cuando tienen
+// traducción (una traducción sin descripción deja la del item) y marca Locale con el idioma en el
+// que quedaron. Con tag vacío (el request no negoció idioma) los devuelve sin tocar.
+func (service *Service) Localize(ctx context.Context, tag string, items []Item) ([]Item, error) {
+	if tag == "" || len(items) == 0 {
+		return items, nil
+	}
+	base := service.locales[0]
+	for index := range items {
+		items[index].Locale = base
+	}
+	if tag == base {
+		return items, nil
+	}
+
+	ids := make([]string, len(items))
+	for index, item := range items {
+		ids[index] = item.ID
+	}
+	translations, err := service.repository.TranslationsFor(ctx, tag, ids)
+	if err != nil {
+		return nil, err
+	}
+	for index := range items {
+		translation, ok := translations[items[index].ID]
+		if !ok {
+			continue
 		}
-		itemInputUpdated.Price = &price
+		items[index].Name = translation.Name
+		if translation.Description != nil {
+			items[index].Description = translation.Description
+		}
+		items[index].Locale = tag
 	}
+	return items, nil
+}
 
-	if itemInputUpdated.Stock != nil && *itemInputUpdated.Stock < 0 {
-		return Item{}, ErrorInvalidInput
+// defaultReservationTTL es la duración de una reserva cuando el pedido no trae ttl_seconds.
+const defaultReservationTTL = 10 * time.Minute
+
+// maxReservationTTL es la duración máxima de una reserva.
+const maxReservationTTL = time.Hour
+
+// Reserve retiene quantity unidades del item durante el TTL pedido. Bloquea el item con
+// GetForUpdate para que dos reservas simultáneas se decidan de a una: la segunda espera el lock
+// y, al sumar las reservas después, ve la que confirmó la primera.
+// Devuelve ErrorBelowMinOrderQty si quantity es menor que el min_order_qty del item y
+// ErrorInsufficientStock si stock menos lo reservado no alcanza.
+func (service *Service) Reserve(ctx context.Context, id string, input ReserveInput) (Reservation, error) {
+	ttl, err := validateReserveInput(input)
+	if err != nil {
+		return Reservation{}, err
 	}
 
-	item, err := service.repository.Update(context, id, itemInputUpdated)
+	var reservation Reservation
+	err = service.touchItem(ctx, id, func(tx RepositoryAPI) (bool, error) {
+		item, err := tx.GetForUpdate(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if input.Quantity < item.MinOrderQty {
+			return false, ErrorBelowMinOrderQty
+		}
+		reserved, err := tx.ReservedQuantity(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if item.Stock-reserved < input.Quantity {
+			return false, ErrorInsufficientStock
+		}
+		reservation, err = tx.InsertReservation(ctx, id, input.Quantity, ttl)
+		return err == nil, err
+	})
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrorNotFound):
-			return Item{}, ErrorNotFound
-		case errors.Is(err, ErrorDuplicateName):
-			return Item{}, ErrorDuplicateName
-		default:
-			return Item{}, err
+		return Reservation{}, err
+	}
+	return reservation, nil
+}
+
+// Release libera una reserva antes de que venza.
+func (service *Service) Release(ctx context.Context, id, reservationID string) error {
+	return service.touchItem(ctx, id, func(tx RepositoryAPI) (bool, error) {
+		err := tx.DeleteReservation(ctx, id, reservationID)
+		return err == nil, err
+	})
+}
+
+// ExpireReservations borra las reservas vencidas y devuelve cuántas borró. Las vencidas ya no
+// cuentan para available; el borrado le sube la versión a sus items y el cambio se avisa como
+// cualquier otro, así el listado cacheado, SSE y los webhooks ven el available nuevo.
+func (service *Service) ExpireReservations(ctx context.Context) (int, error) {
+	var (
+		deleted int
+		itemIDs []string
+	)
+	err := service.repository.InTx(ctx, func(tx RepositoryAPI) error {
+		var err error
+		if deleted, itemIDs, err = tx.DeleteExpiredReservations(ctx); err != nil {
+			return err
 		}
+		return service.recordEvents(ctx, tx, idEvents(EventUpdated, itemIDs)...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range idEvents(EventUpdated, itemIDs) {
+		service.publishEvent(event)
 	}
+	return deleted, nil
+}
 
-	return item, nil
+// validateReserveInput valida la reserva y devuelve su TTL.
+func validateReserveInput(input ReserveInput) (time.Duration, error) {
+	if input.Quantity < 1 {
+		return 0, &ValidationError{Field: "quantity", Message: "quantity must be at least 1"}
+	}
+	if input.TTLSeconds == 0 {
+		return defaultReservationTTL, nil
+	}
+	ttl := time.Duration(input.TTLSeconds) * time.Second
+	if input.TTLSeconds < 0 || ttl > maxReservationTTL {
+		return 0, &ValidationError{Field: "ttl_seconds", Message: "ttl_seconds must be between 1 and 3600"}
+	}
+	return ttl, nil
+}
+
+// maxBulkDeleteIDs es la cantidad máxima de IDs de un borrado masivo.
+const maxBulkDeleteIDs = 500
+
+// DeleteMany borra varios items por ID en una sola sentencia. Los IDs repetidos cuentan una vez
+// y los que no existen vuelven en Missing, sin que eso sea un error.
+// Igual que Delete, el borrado es lógico; un item ya borrado cuenta como faltante.
+func (service *Service) DeleteMany(context context.Context, ids []string) (BulkDeleteResult, error) {
+	if len(ids) == 0 || len(ids) > maxBulkDeleteIDs {
+		return BulkDeleteResult{}, &ValidationError{
+			Field:   "ids",
+			Message: fmt.Sprintf("ids must have between 1 and %d entries", maxBulkDeleteIDs),
+		}
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	var deleted []string
+	err := service.repository.InTx(context, func(tx RepositoryAPI) error {
+		var err error
+		if deleted, err = tx.DeleteMany(context, unique); err != nil {
+			return err
+		}
+		if err := recordDeletes(context, tx, deleted...); err != nil {
+			return err
+		}
+		return service.recordEvents(context, tx, idEvents(EventDeleted, deleted)...)
+	})
+	if err != nil {
+		return BulkDeleteResult{}, err
+	}
+
+	wasDeleted := make(map[string]bool, len(deleted))
+	for _, event := range idEvents(EventDeleted, deleted) {
+		wasDeleted[event.ID] = true
+		service.metrics.ItemDeleted()
+		service.publishEvent(event)
+	}
+	result := BulkDeleteResult{Deleted: len(deleted), Missing: []string{}}
+	for _, id := range unique {
+		if !wasDeleted[id] {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	return result, nil
+}
+
+// checkValidator normaliza el error de un validator externo.
+// Un *ValidationError se propaga tal cual (400 con detalle por campo). Cualquier otro error se
+// convierte en un error opaco para que no se confunda con un error de dominio (por ejemplo
+// ErrorNotFound) y termine como 500.
+func checkValidator(err error) error {
+	if err == nil {
+		return nil
+	}
+	var validationError *ValidationError
+	if errors.As(err, &validationError) {
+		return err
+	}
+	return fmt.Errorf("items: validator failed: %v", err)
+}
+
+// validateSort verifica que cada clave esté en la whitelist y que ningún campo se repita
+// (ni siquiera con otra dirección: "price,-price" es ambiguo).
+func validateSort(keys []SortKey) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if !isSortable(key) || seen[key.Field()] {
+			return ErrorInvalidSort
+		}
+		seen[key.Field()] = true
+	}
+	return nil
+}
+
+// normalizePriceRange valida min_price y max_price con las mismas reglas que el precio de un item
+// y verifica que el rango no esté invertido.
+func normalizePriceRange(filter *ListFilter) error {
+	filter.MinPrice = strings.TrimSpace(filter.MinPrice)
+	filter.MaxPrice = strings.TrimSpace(filter.MaxPrice)
+
+	if filter.MinPrice != "" && !isValidPrice(filter.MinPrice) {
+		return &FilterError{Field: "min_price", Message: "min_price must be a positive amount with up to 2 decimals"}
+	}
+	if filter.MaxPrice != "" && !isValidPrice(filter.MaxPrice) {
+		return &FilterError{Field: "max_price", Message: "max_price must be a positive amount with up to 2 decimals"}
+	}
+	if filter.MinPrice != "" && filter.MaxPrice != "" {
+		// Ya validados por el regex, así que SetString no puede fallar.
+		minPrice, _ := new(big.Rat).SetString(filter.MinPrice)
+		maxPrice, _ := new(big.Rat).SetString(filter.MaxPrice)
+		if minPrice.Cmp(maxPrice) > 0 {
+			return &FilterError{Field: "min_price", Message: "min_price must be less than or equal to max_price"}
+		}
+	}
+	return nil
+}
+
+// searchFieldsAll es el valor de search_fields que busca en todos los campos (allSearchFields).
+const searchFieldsAll = "all"
+
+// allSearchFields son los campos de búsqueda de search_fields=all.
+var allSearchFields = []string{"name", "description", "sku", "barcode"}
+
+// validateSearchFields verifica que cada campo de búsqueda exista y no se repita. all va solo.
+func validateSearchFields(fields []string) error {
+	if slices.Contains(fields, searchFieldsAll) && len(fields) > 1 {
+		return &FilterError{Field: "search_fields", Message: "search field \"all\" cannot be combined with other fields"}
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !isSearchable(field) && field != searchFieldsAll {
+			return &FilterError{Field: "search_fields", Message: fmt.Sprintf("unknown search field %q (allowed: name, description, sku, barcode, all)", field)}
+		}
+		if seen[field] {
+			return &FilterError{Field: "search_fields", Message: fmt.Sprintf("search field %q is repeated", field)}
+		}
+		seen[field] = true
+	}
+	return nil
+}
+
+// validateFuzzy verifica que la búsqueda aproximada tenga texto y se haga solo sobre name (el índice
+// de trigramas existe únicamente para esa columna). sku y barcode se aceptan porque se comparan
+// exacto, no por parecido.
+func validateFuzzy(filter ListFilter) error {
+	if filter.Query == "" {
+		return &FilterError{Field: "fuzzy", Message: "fuzzy requires a query"}
+	}
+	for _, field := range filter.SearchFields {
+		if field != "name" && field != "sku" && field != "barcode" {
+			return &FilterError{Field: "fuzzy", Message: "fuzzy search only supports the name, sku and barcode fields"}
+		}
+	}
+	return nil
 }
 
-// Delete elimina un item por ID.
-func (service *Service) Delete(context context.Context, id string) error {
-	return service.repository.Delete(context, id)
+// validateStockRange verifica que los límites de stock no sean negativos ni estén invertidos.
+// El handler ya rechaza valores no enteros; esto cubre a otros callers del service.
+func validateStockRange(filter ListFilter) error {
+	if filter.StockGTE != nil && *filter.StockGTE < 0 {
+		return &FilterError{Field: "stock_gte", Message: "stock_gte must be a non-negative integer"}
+	}
+	if filter.StockLTE != nil && *filter.StockLTE < 0 {
+		return &FilterError{Field: "stock_lte", Message: "stock_lte must be a non-negative integer"}
+	}
+	if filter.StockGTE != nil && filter.StockLTE != nil && *filter.StockGTE > *filter.StockLTE {
+		return &FilterError{Field: "stock_gte", Message: "stock_gte must be less than or equal to stock_lte"}
+	}
+	return nil
 }
 
 var pricePattern = regexp.MustCompile(`^\d+(\.\d{1,2})?$`)
 
+// skuPattern es el formato de SKU: de 3 a 64 mayúsculas, dígitos, puntos, guiones bajos o guiones.
+var skuPattern = regexp.MustCompile(`^[A-Z0-9._-]{3,64}$`)
+
+// isValidSKU indica si sku cumple skuPattern. Se aplica después de normalizeSKU.
+func isValidSKU(sku string) bool {
+	return skuPattern.MatchString(sku)
+}
+
+// normalizeSKU recorta espacios y pasa el SKU a mayúsculas: "kb-001 " y "KB-001" son el mismo SKU.
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
 func isValidPrice(value string) bool {
 	price := strings.TrimSpace(value)
 	if !pricePattern.MatchString(price) {
@@ -169,6 +3215,44 @@ func isValidPrice(value string) bool {
 	return isPositiveNonZero(price)
 }
 
+// pricePrecisionError valida que price (ya validado con isValidPrice) no tenga más decimales que
+// los que usa la moneda: en JPY "100" o "100.00" valen pero "100.50" no. Devuelve nil si es válido.
+func pricePrecisionError(price, code string) error {
+	return amountPrecisionError("price", price, code)
+}
+
+// amountPrecisionError es pricePrecisionError para cualquier monto; field es el campo del error.
+func amountPrecisionError(field, amount, code string) error {
+	if currency.Decimals(code) > 0 {
+		return nil
+	}
+	if hasCents(amount) {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%s must be a whole amount in %s", field, code)}
+	}
+	return nil
+}
+
+// canonicalPrice devuelve price (ya validado con isValidPrice) con exactamente dos decimales y sin
+// ceros a la izquierda: "10" queda "10.00" y "010.5", "10.50". Es la forma en que la DB devuelve
+// price::text (numeric(10,2)), así lo que ven los validators coincide con lo que se guarda.
+func canonicalPrice(price string) string {
+	amount, _ := new(big.Rat).SetString(price)
+	return amount.FloatString(2)
+}
+
+// isLowerPrice indica si salePrice es menor que price. Los dos ya pasaron por isValidPrice.
+func isLowerPrice(salePrice, price string) bool {
+	sale, _ := new(big.Rat).SetString(salePrice)
+	list, _ := new(big.Rat).SetString(price)
+	return sale.Cmp(list) < 0
+}
+
+// hasCents indica si price tiene una parte decimal distinta de cero ("10.50" sí, "10.00" no).
+func hasCents(price string) bool {
+	_, fraction, _ := strings.Cut(price, ".")
+	return strings.Trim(fraction, "0") != ""
+}
+
 func isPositiveNonZero(price string) bool {
 	// price ya viene validado con regex: \d+(\.\d{2})?
 	// Entonces los únicos ceros posibles son "0" o "0.00" o "00.00" etc.
@@ -2,12 +2,19 @@ package items
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/events"
 )
 
 // fakeRepo implementa RepositoryAPI para testing.
@@ -15,22 +22,91 @@ type fakeRepo struct {
 	insertCalled bool
 	updateCalled bool
 	listCalled   bool
+	exportCalled bool
 	countCalled  bool
 	getCalled    bool
 
 	insertCreatedInput CreateItemInput
+	copiedInputs       []CreateItemInput
+	uniqueErr          error
+	upsertInput        *CreateItemInput
+	upsertCreated      bool
+	uniqueChecked      []CreateItemInput
 	updateInput        UpdateItemInput
 	insertErr          error
+	// insertErrs se consume de a uno por llamada antes de mirar insertErr (para simular carreras).
+	insertErrs  []error
+	insertCalls int
+
+	takenSlugsCalled bool
+	takenSlugsBase   string
+	takenSlugsExcept string
+	takenSlugs       []string
+	getSlug          string
+	getSKU           string
+	getBarcode       string
 
-	listQuery  string
+	listFilter ListFilter
 	listLimit  int
 	listOffset int
 	listErr    error
 	listItems  []Item
 
-	countQuery string
-	countErr   error
-	countTotal int
+	listWithTotalCalled bool
+	listAfterCalled     bool
+	listAfter           CreatedAtID
+
+	countFilter ListFilter
+	countErr    error
+	countTotal  int
+
+	estimateCalled bool
+	estimateFilter ListFilter
+	estimateTotal  int
+	estimateOK     bool
+	estimateErr    error
+
+	version    CollectionVersion
+	versionErr error
+
+	replaceCalled bool
+	replaceID     string
+	replaceInput  ReplaceItemInput
+	replaceErr    error
+
+	relatedItem      Item
+	relatedThreshold float64
+	relatedLimit     int
+	relatedItems     []Item
+
+	suggestPrefix    string
+	suggestThreshold float64
+	suggestLimit     int
+	suggestions      []Suggestion
+
+	closestCalled    bool
+	closestQuery     string
+	closestThreshold float64
+	closestName      string
+	closestErr       error
+
+	changesAfter UpdatedAtID
+	changesUntil time.Time
+	changesLimit int
+	changesItems []Item
+
+	restockLimit  int
+	restockOffset int
+	restockItems  []Item
+	restockTotal  int
+
+	previewFilter      ListFilter
+	previewAdjustment  PriceAdjustment
+	preview            PriceAdjustmentPreview
+	adjustPricesCalled bool
+	adjustPricesReason string
+	adjustPricesErr    error
+	adjustPricesIDs    []string
 
 	getID   string
 	getErr  error
@@ -39,26 +115,143 @@ type fakeRepo struct {
 	updateID   string
 	updateErr  error
 	updateItem Item
+	// updateErrByID, si tiene el ID, es el error de Update para ese item (para updates masivos).
+	updateErrByID map[string]error
+	updateIDs     []string
+
+	deleteCalled    bool
+	deleteID        string
+	deleteIfVersion *int
+	deleteErr       error
+
+	deleteManyIDs     []string
+	deleteManyDeleted []string
+	deleteManyErr     error
+
+	purgeID  string
+	purgeErr error
+
+	reserved           int
+	reserveCalled      bool
+	reserveQuantity    int
+	reserveTTL         time.Duration
+	releaseReservation string
+	releaseErr         error
+	expiredItemIDs     []string
 
-	deleteCalled bool
-	deleteID     string
-	deleteErr    error
+	movements       []StockMovement
+	movementsErr    error
+	listMovementsID string
+	listMovements   []StockMovement
+	listMovementsN  int
+	listMovementsAt int
+
+	priceChanges     []PriceHistoryEntry
+	priceChangesErr  error
+	outbox           []Event
+	outboxErr        error
+	notified         []Event
+	audit            []AuditEntry
+	auditErr         error
+	listAudit        []AuditEntry
+	historyFilter    PriceHistoryFilter
+	listPriceHistory []PriceHistoryEntry
+
+	// variantCount y variantTotal son lo que devuelve VariantStock (el estado después del cambio).
+	variantCount       int
+	variantTotal       int
+	variants           []Variant
+	insertVariantInput CreateVariantInput
+	insertVariantErr   error
+	updateVariantInput UpdateVariantInput
+	updateVariantErr   error
+	deletedVariantID   string
+	deleteVariantErr   error
+
+	priceSchedules      []PriceSchedule
+	insertScheduleInput CreatePriceScheduleInput
+	insertScheduleErr   error
+	deletedScheduleID   string
+	deleteScheduleErr   error
+	dueSchedules        []PriceSchedule
+	dueLimit            int
+	appliedScheduleIDs  []string
+	// markScheduleErrByID, si tiene el ID, es el error de MarkPriceScheduleApplied para ese cambio.
+	markScheduleErrByID map[string]error
+	// failedSchedules guarda, por ID, el motivo de MarkPriceScheduleFailed.
+	failedSchedules map[string]string
+
+	refLookup      [2]string
+	insertRefInput ExternalRefInput
+	insertRefErr   error
+	deletedRef     [2]string
+	deleteRefErr   error
+
+	translations       []Translation
+	translationsByItem map[string]Translation
+	translationLookup  string
+	upsertTranslation  TranslationInput
+	upsertLocale       string
+	upsertErr          error
+
+	getForUpdateCalled bool
+	inTxCalled         bool
+	inSnapshotCalled   bool
+	// snapshotRepo, si no es nil, es el repositorio que recibe fn en InSnapshot (la "transacción").
+	snapshotRepo *fakeRepo
 }
 
 // Insert implementa RepositoryAPI.Insert
 func (fakerepo *fakeRepo) Insert(ctx context.Context, itemInputCreated CreateItemInput) (Item, error) {
 	fakerepo.insertCalled = true
+	fakerepo.insertCalls++
 	fakerepo.insertCreatedInput = itemInputCreated
+	if len(fakerepo.insertErrs) > 0 {
+		err := fakerepo.insertErrs[0]
+		fakerepo.insertErrs = fakerepo.insertErrs[1:]
+		return Item{}, err
+	}
 	if fakerepo.insertErr != nil {
 		return Item{}, fakerepo.insertErr
 	}
-	return Item{ID: "x", Name: itemInputCreated.Name, Price: itemInputCreated.Price, Stock: itemInputCreated.Stock}, nil
+	return Item{ID: "x", Name: itemInputCreated.Name, Slug: itemInputCreated.Slug, SKU: itemInputCreated.SKU, Barcode: itemInputCreated.Barcode, Price: itemInputCreated.Price, Stock: itemInputCreated.Stock}, nil
+}
+
+// CopyInsert implementa RepositoryAPI.CopyInsert
+func (fakerepo *fakeRepo) CopyInsert(ctx context.Context, inputs []CreateItemInput) (int64, error) {
+	if fakerepo.insertErr != nil {
+		return 0, fakerepo.insertErr
+	}
+	fakerepo.copiedInputs = append(fakerepo.copiedInputs, inputs...)
+	return int64(len(inputs)), nil
+}
+
+// UpsertBySKU implementa RepositoryAPI.UpsertBySKU: actualiza getItem con el precio, el stock y la
+// descripción de in (si trae), o devuelve upsertCreated
+func (fakerepo *fakeRepo) UpsertBySKU(ctx context.Context, in CreateItemInput) (Item, bool, error) {
+	fakerepo.upsertInput = &in
+	if fakerepo.insertErr != nil {
+		return Item{}, false, fakerepo.insertErr
+	}
+	item := fakerepo.getItem
+	item.Price, item.Stock = in.Price, in.Stock
+	if in.Description != nil {
+		item.Description = in.Description
+	}
+	item.Version++
+	return item, fakerepo.upsertCreated, nil
+}
+
+// CheckUniqueKeys implementa RepositoryAPI.CheckUniqueKeys devolviendo uniqueErr
+func (fakerepo *fakeRepo) CheckUniqueKeys(ctx context.Context, input CreateItemInput) error {
+	fakerepo.uniqueChecked = append(fakerepo.uniqueChecked, input)
+	return fakerepo.uniqueErr
 }
 
 // List implementa RepositoryAPI.List
-func (fakerepo *fakeRepo) List(ctx context.Context, query string, limit, offset int) ([]Item, error) {
+func (fakerepo *fakeRepo) List(ctx context.Context, filter ListFilter, limit, offset int) ([]Item, error) {
 	fakerepo.listCalled = true
-	fakerepo.listQuery = query
+	fakerepo.listFilter = filter
 	fakerepo.listLimit = limit
 	fakerepo.listOffset = offset
 	if fakerepo.listErr != nil {
@@ -67,16 +260,132 @@ func (fakerepo *fakeRepo) List(ctx context.Context, query string, limit, offset
 	return fakerepo.listItems, nil
 }
 
+// ListWithTotal implementa RepositoryAPI.ListWithTotal (comparte los campos de List; el total es countTotal)
+func (fakerepo *fakeRepo) ListWithTotal(ctx context.Context, filter ListFilter, limit, offset int) ([]Item, int, error) {
+	fakerepo.listWithTotalCalled = true
+	items, err := fakerepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, fakerepo.countTotal, nil
+}
+
+// Export implementa RepositoryAPI.Export (comparte listFilter/listItems/listErr con List)
+func (fakerepo *fakeRepo) Export(ctx context.Context, filter ListFilter, fn func(Item) error) error {
+	fakerepo.exportCalled = true
+	fakerepo.listFilter = filter
+	if fakerepo.listErr != nil {
+		return fakerepo.listErr
+	}
+	for _, item := range fakerepo.listItems {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAfter implementa RepositoryAPI.ListAfter (comparte listFilter/listLimit/listItems/listErr con List)
+func (fakerepo *fakeRepo) ListAfter(ctx context.Context, filter ListFilter, after CreatedAtID, limit int) ([]Item, error) {
+	fakerepo.listAfterCalled = true
+	fakerepo.listFilter = filter
+	fakerepo.listAfter = after
+	fakerepo.listLimit = limit
+	if fakerepo.listErr != nil {
+		return nil, fakerepo.listErr
+	}
+	return fakerepo.listItems, nil
+}
+
 // Count implementa RepositoryAPI.Count
-func (fakerepo *fakeRepo) Count(ctx context.Context, query string) (int, error) {
+func (fakerepo *fakeRepo) Count(ctx context.Context, filter ListFilter) (int, error) {
 	fakerepo.countCalled = true
-	fakerepo.countQuery = query
+	fakerepo.countFilter = filter
 	if fakerepo.countErr != nil {
 		return 0, fakerepo.countErr
 	}
 	return fakerepo.countTotal, nil
 }
 
+// Replace implementa RepositoryAPI.Replace
+func (fakerepo *fakeRepo) Replace(ctx context.Context, id string, in ReplaceItemInput) (Item, error) {
+	fakerepo.replaceCalled = true
+	fakerepo.replaceID = id
+	fakerepo.replaceInput = in
+	if fakerepo.replaceErr != nil {
+		return Item{}, fakerepo.replaceErr
+	}
+	return Item{ID: id, Name: in.Name, Slug: in.Slug, Description: in.Description, Price: in.Price, Stock: in.Stock}, nil
+}
+
+// Related implementa RepositoryAPI.Related
+func (fakerepo *fakeRepo) Related(ctx context.Context, item Item, threshold float64, limit int) ([]Item, error) {
+	fakerepo.relatedItem = item
+	fakerepo.relatedThreshold = threshold
+	fakerepo.relatedLimit = limit
+	return fakerepo.relatedItems, nil
+}
+
+// Suggest implementa RepositoryAPI.Suggest
+func (fakerepo *fakeRepo) Suggest(ctx context.Context, prefix string, threshold float64, limit int) ([]Suggestion, error) {
+	fakerepo.suggestPrefix = prefix
+	fakerepo.suggestThreshold = threshold
+	fakerepo.suggestLimit = limit
+	return fakerepo.suggestions, nil
+}
+
+// ClosestName implementa RepositoryAPI.ClosestName
+func (fakerepo *fakeRepo) ClosestName(ctx context.Context, query string, threshold float64) (string, error) {
+	fakerepo.closestCalled = true
+	fakerepo.closestQuery = query
+	fakerepo.closestThreshold = threshold
+	return fakerepo.closestName, fakerepo.closestErr
+}
+
+// RestockNeeded implementa RepositoryAPI.RestockNeeded
+func (fakerepo *fakeRepo) RestockNeeded(ctx context.Context, limit, offset int) ([]Item, int, error) {
+	fakerepo.restockLimit = limit
+	fakerepo.restockOffset = offset
+	return fakerepo.restockItems, fakerepo.restockTotal, nil
+}
+
+// Changes implementa RepositoryAPI.Changes
+func (fakerepo *fakeRepo) Changes(ctx context.Context, after UpdatedAtID, until time.Time, limit int) ([]Item, error) {
+	fakerepo.changesAfter = after
+	fakerepo.changesUntil = until
+	fakerepo.changesLimit = limit
+	return fakerepo.changesItems, nil
+}
+
+// PreviewPriceAdjustment implementa RepositoryAPI.PreviewPriceAdjustment
+func (fakerepo *fakeRepo) PreviewPriceAdjustment(ctx context.Context, filter ListFilter, adjustment PriceAdjustment, sampleSize int) (PriceAdjustmentPreview, error) {
+	fakerepo.previewFilter = filter
+	fakerepo.previewAdjustment = adjustment
+	return fakerepo.preview, nil
+}
+
+// AdjustPrices implementa RepositoryAPI.AdjustPrices
+func (fakerepo *fakeRepo) AdjustPrices(ctx context.Context, filter ListFilter, adjustment PriceAdjustment, reason, requestID string) ([]string, error) {
+	fakerepo.adjustPricesCalled = true
+	fakerepo.adjustPricesReason = reason
+	return fakerepo.adjustPricesIDs, fakerepo.adjustPricesErr
+}
+
+// CollectionVersion implementa RepositoryAPI.CollectionVersion
+func (fakerepo *fakeRepo) CollectionVersion(ctx context.Context) (CollectionVersion, error) {
+	return fakerepo.version, fakerepo.versionErr
+}
+
+// EstimateCount implementa RepositoryAPI.EstimateCount
+func (fakerepo *fakeRepo) EstimateCount(ctx context.Context, filter ListFilter) (int, bool, error) {
+	fakerepo.estimateCalled = true
+	fakerepo.estimateFilter = filter
+	if fakerepo.estimateErr != nil {
+		return 0, false, fakerepo.estimateErr
+	}
+	return fakerepo.estimateTotal, fakerepo.estimateOK, nil
+}
+
 // GetByID implementa RepositoryAPI.GetByID
 func (fakerepo *fakeRepo) GetByID(ctx context.Context, id string) (Item, error) {
 	fakerepo.getCalled = true
@@ -87,11 +396,53 @@ func (fakerepo *fakeRepo) GetByID(ctx context.Context, id string) (Item, error)
 	return fakerepo.getItem, nil
 }
 
+// GetBySKU implementa RepositoryAPI.GetBySKU (comparte getItem/getErr con GetByID)
+func (fakerepo *fakeRepo) GetBySKU(ctx context.Context, sku string) (Item, error) {
+	fakerepo.getCalled = true
+	fakerepo.getSKU = sku
+	if fakerepo.getErr != nil {
+		return Item{}, fakerepo.getErr
+	}
+	return fakerepo.getItem, nil
+}
+
+// GetByBarcode implementa RepositoryAPI.GetByBarcode (comparte getItem/getErr con GetByID)
+func (fakerepo *fakeRepo) GetByBarcode(ctx context.Context, barcode string) (Item, error) {
+	fakerepo.getCalled = true
+	fakerepo.getBarcode = barcode
+	if fakerepo.getErr != nil {
+		return Item{}, fakerepo.getErr
+	}
+	return fakerepo.getItem, nil
+}
+
+// GetBySlug implementa RepositoryAPI.GetBySlug (comparte getItem/getErr con GetByID)
+func (fakerepo *fakeRepo) GetBySlug(ctx context.Context, slug string) (Item, error) {
+	fakerepo.getCalled = true
+	fakerepo.getSlug = slug
+	if fakerepo.getErr != nil {
+		return Item{}, fakerepo.getErr
+	}
+	return fakerepo.getItem, nil
+}
+
+// TakenSlugs implementa RepositoryAPI.TakenSlugs devolviendo takenSlugs
+func (fakerepo *fakeRepo) TakenSlugs(ctx context.Context, base, exceptID string) ([]string, error) {
+	fakerepo.takenSlugsCalled = true
+	fakerepo.takenSlugsBase = base
+	fakerepo.takenSlugsExcept = exceptID
+	return fakerepo.takenSlugs, nil
+}
+
 // Update implementa RepositoryAPI.Update
 func (fakerepo *fakeRepo) Update(ctx context.Context, id string, in UpdateItemInput) (Item, error) {
 	fakerepo.updateCalled = true
 	fakerepo.updateInput = in
 	fakerepo.updateID = id
+	fakerepo.updateIDs = append(fakerepo.updateIDs, id)
+	if err := fakerepo.updateErrByID[id]; err != nil {
+		return Item{}, err
+	}
 	if fakerepo.updateErr != nil {
 		return Item{}, fakerepo.updateErr
 	}
@@ -102,440 +453,5052 @@ func (fakerepo *fakeRepo) Update(ctx context.Context, id string, in UpdateItemIn
 }
 
 // Delete implementa RepositoryAPI.Delete
-func (fakerepo *fakeRepo) Delete(ctx context.Context, id string) error {
+func (fakerepo *fakeRepo) Delete(ctx context.Context, id string, ifVersion *int) (Item, error) {
 	fakerepo.deleteCalled = true
 	fakerepo.deleteID = id
+	fakerepo.deleteIfVersion = ifVersion
 	if fakerepo.deleteErr != nil {
-		return fakerepo.deleteErr
+		return Item{}, fakerepo.deleteErr
 	}
-	return nil
+	return Item{ID: id}, nil
 }
 
-// TestService_Create_InvalidInput prueba validaciones de Create
-func TestService_Create_InvalidInput(t *testing.T) {
-	t.Run("invalid name", func(t *testing.T) {
-		repository := &fakeRepo{}
-		service := NewService(repository)
+// Purge implementa RepositoryAPI.Purge
+func (fakerepo *fakeRepo) Purge(ctx context.Context, id string) error {
+	fakerepo.purgeID = id
+	return fakerepo.purgeErr
+}
 
-		_, err := service.Create(context.Background(), CreateItemInput{
-			Name:  "   ",
-			Price: "100.00",
-			Stock: 1,
-		})
-		require.ErrorIs(t, err, ErrorInvalidInput)
-		require.False(t, repository.insertCalled, "repo.Insert should not be called on invalid input")
-	})
+// DeleteMany implementa RepositoryAPI.DeleteMany
+func (fakerepo *fakeRepo) DeleteMany(ctx context.Context, ids []string) ([]string, error) {
+	fakerepo.deleteManyIDs = ids
+	if fakerepo.deleteManyErr != nil {
+		return nil, fakerepo.deleteManyErr
+	}
+	return fakerepo.deleteManyDeleted, nil
+}
 
-	t.Run("price validation", func(t *testing.T) {
-		// Casos diseñados para cubrir casos típicas:
-		// - regex no matchea
-		// - trim
-		// - cero (bloqueo rápido)
-		// - formatos válidos (siempre que tu regex los permita)
-		tests := []struct {
-			name    string
-			price   string
-			wantErr bool
-		}{
-			// No matchea regex / inválidos obvios
-			{"letters", "aaa", true},
-			{"mixed", "100a", true},
-			{"blank", " ", true},
-			{"comma", "10,00", true},
-			{"dot-leading", ".50", true},
-			{"negative", "-1.00", true},
+// GetForUpdate implementa RepositoryAPI.GetForUpdate (comparte getItem/getErr con GetByID)
+func (fakerepo *fakeRepo) GetForUpdate(ctx context.Context, id string) (Item, error) {
+	fakerepo.getForUpdateCalled = true
+	fakerepo.getID = id
+	if fakerepo.getErr != nil {
+		return Item{}, fakerepo.getErr
+	}
+	return fakerepo.getItem, nil
+}
 
-			// Trim + formato
-			{"trimmed valid", " 10.00 ", false},
+// ReservedQuantity implementa RepositoryAPI.ReservedQuantity
+func (fakerepo *fakeRepo) ReservedQuantity(ctx context.Context, itemID string) (int, error) {
+	return fakerepo.reserved, nil
+}
 
-			// Ceros (deben fallar si exigís > 0)
-			{"zero int", "0", true},
-			{"zero decimals", "0.00", true},
+// InsertReservation implementa RepositoryAPI.InsertReservation
+func (fakerepo *fakeRepo) InsertReservation(ctx context.Context, itemID string, quantity int, ttl time.Duration) (Reservation, error) {
+	fakerepo.reserveCalled = true
+	fakerepo.reserveQuantity = quantity
+	fakerepo.reserveTTL = ttl
+	return Reservation{ID: "res-1", ItemID: itemID, Quantity: quantity}, nil
+}
 
-			// Decimales: ajustá según tu regex actual
-			// Si usás `^\d+(\.\d{1,2})?$` estos pasan:
-			{"one decimal", "10.5", false},
-			{"two decimals", "10.50", false},
-			{"int", "10", false},
+// DeleteReservation implementa RepositoryAPI.DeleteReservation
+func (fakerepo *fakeRepo) DeleteReservation(ctx context.Context, itemID, reservationID string) error {
+	fakerepo.releaseReservation = reservationID
+	return fakerepo.releaseErr
+}
 
-			// Si cambiás a `^\d+(\.\d{2})?$`, entonces:
-			// {"one decimal", "10.5", true},
-		}
+// DeleteExpiredReservations implementa RepositoryAPI.DeleteExpiredReservations
+func (fakerepo *fakeRepo) DeleteExpiredReservations(ctx context.Context) (int, []string, error) {
+	return len(fakerepo.expiredItemIDs), fakerepo.expiredItemIDs, nil
+}
 
-		for _, tt := range tests {
-			t.Run(tt.name, func(t *testing.T) {
-				repository := &fakeRepo{}
-				service := NewService(repository)
+// InsertStockMovement implementa RepositoryAPI.InsertStockMovement guardando el movimiento
+func (fakerepo *fakeRepo) InsertStockMovement(ctx context.Context, movement StockMovement) error {
+	if fakerepo.movementsErr != nil {
+		return fakerepo.movementsErr
+	}
+	fakerepo.movements = append(fakerepo.movements, movement)
+	return nil
+}
 
-				_, err := service.Create(context.Background(), CreateItemInput{
-					Name:  "product",
-					Price: tt.price,
-					Stock: 1,
-				})
+// ListStockMovements implementa RepositoryAPI.ListStockMovements
+func (fakerepo *fakeRepo) ListStockMovements(ctx context.Context, itemID string, limit, offset int) ([]StockMovement, int, error) {
+	fakerepo.listMovementsID = itemID
+	fakerepo.listMovementsN = limit
+	fakerepo.listMovementsAt = offset
+	return fakerepo.listMovements, len(fakerepo.listMovements), nil
+}
 
-				if tt.wantErr {
-					require.ErrorIs(t, err, ErrorInvalidInput, "price=%q", tt.price)
-					require.False(t, repository.insertCalled, "repo.Insert should not be called on invalid input (price=%q)", tt.price)
-				} else {
-					require.NoError(t, err, "price=%q", tt.price)
-					require.True(t, repository.insertCalled, "repo.Insert should be called on valid input (price=%q)", tt.price)
-				}
-			})
-		}
-	})
+// InsertPriceChange implementa RepositoryAPI.InsertPriceChange guardando el cambio
+func (fakerepo *fakeRepo) InsertPriceChange(ctx context.Context, entry PriceHistoryEntry) error {
+	if fakerepo.priceChangesErr != nil {
+		return fakerepo.priceChangesErr
+	}
+	fakerepo.priceChanges = append(fakerepo.priceChanges, entry)
+	return nil
+}
 
-	t.Run("invalid stock", func(t *testing.T) {
-		testCases := []int{-1, -2}
-		for _, stock := range testCases {
-			repository := &fakeRepo{}
-			service := NewService(repository)
+// InsertOutbox implementa RepositoryAPI.InsertOutbox guardando los eventos
+func (fakerepo *fakeRepo) InsertOutbox(ctx context.Context, events []Event) error {
+	if fakerepo.outboxErr != nil {
+		return fakerepo.outboxErr
+	}
+	fakerepo.outbox = append(fakerepo.outbox, events...)
+	return nil
+}
 
-			_, err := service.Create(context.Background(), CreateItemInput{
-				Name:  "product",
-				Price: "100",
-				Stock: stock,
-			})
-			require.ErrorIs(t, err, ErrorInvalidInput, "stock=%d", stock)
-			require.False(t, repository.insertCalled, "repo.Insert should not be called on invalid input")
-		}
-	})
+// NotifyChanges implementa RepositoryAPI.NotifyChanges guardando los eventos avisados
+func (fakerepo *fakeRepo) NotifyChanges(ctx context.Context, events []Event) error {
+	fakerepo.notified = append(fakerepo.notified, events...)
+	return nil
+}
 
-	t.Run("insert product", func(t *testing.T) {
-		errDB := errors.New("db down")
-		tests := []struct {
-			name       string
-			insertErr  error
-			wantErr    error
-			expectSame bool
-		}{
-			{
-				name:    "success",
-				wantErr: nil,
-			},
-			{
-				name:       "duplicate name error",
-				insertErr:  fmt.Errorf("wrapped: %w", ErrorDuplicateName),
-				wantErr:    ErrorDuplicateName,
-				expectSame: false,
-			},
-			{
-				name:       "unknown repo error",
-				insertErr:  errDB,
-				wantErr:    errDB,
-				expectSame: true,
-			},
-		}
+// InsertAudit implementa RepositoryAPI.InsertAudit guardando las entradas
+func (fakerepo *fakeRepo) InsertAudit(ctx context.Context, entries []AuditEntry) error {
+	if fakerepo.auditErr != nil {
+		return fakerepo.auditErr
+	}
+	fakerepo.audit = append(fakerepo.audit, entries...)
+	return nil
+}
 
-		for _, tt := range tests {
-			t.Run(tt.name, func(t *testing.T) {
-				repository := &fakeRepo{insertErr: tt.insertErr}
-				service := NewService(repository)
+// ListAudit implementa RepositoryAPI.ListAudit
+func (fakerepo *fakeRepo) ListAudit(ctx context.Context, itemID string, limit, offset int) ([]AuditEntry, int, error) {
+	fakerepo.listMovementsN = limit
+	fakerepo.listMovementsAt = offset
+	return fakerepo.listAudit, len(fakerepo.listAudit), nil
+}
 
-				input := CreateItemInput{
-					Name:  "  product  ",
-					Price: "10.00",
-					Stock: 1,
-				}
+// ListPriceHistory implementa RepositoryAPI.ListPriceHistory
+func (fakerepo *fakeRepo) ListPriceHistory(ctx context.Context, itemID string, filter PriceHistoryFilter, limit, offset int) ([]PriceHistoryEntry, int, error) {
+	fakerepo.historyFilter = filter
+	fakerepo.listMovementsN = limit
+	fakerepo.listMovementsAt = offset
+	return fakerepo.listPriceHistory, len(fakerepo.listPriceHistory), nil
+}
 
-				product, err := service.Create(context.Background(), input)
+// ListVariants implementa RepositoryAPI.ListVariants
+func (fakerepo *fakeRepo) ListVariants(ctx context.Context, itemID string) ([]Variant, error) {
+	return fakerepo.variants, nil
+}
 
-				if tt.wantErr != nil {
-					require.Error(t, err)
-					require.ErrorIs(t, err, tt.wantErr)
-					if tt.expectSame {
-						require.True(t, err == tt.wantErr, "expected same error instance, got %v", err)
-					}
-					require.True(t, repository.insertCalled, "repo.Insert should be called")
-					return
-				}
+// InsertVariant implementa RepositoryAPI.InsertVariant guardando el input
+func (fakerepo *fakeRepo) InsertVariant(ctx context.Context, itemID string, in CreateVariantInput) (Variant, error) {
+	fakerepo.insertVariantInput = in
+	if fakerepo.insertVariantErr != nil {
+		return Variant{}, fakerepo.insertVariantErr
+	}
+	return Variant{ID: "variant-1", ItemID: itemID, SKU: in.SKU, Price: in.Price, Stock: in.Stock, Attributes: in.Attributes}, nil
+}
 
-				require.NoError(t, err)
-				require.True(t, repository.insertCalled, "repo.Insert should be called")
-				require.NotEmpty(t, product.ID, "expected created item, got empty ID")
-				require.Equal(t, "product", repository.insertCreatedInput.Name, "expected trimmed name")
+// UpdateVariant implementa RepositoryAPI.UpdateVariant guardando el input
+func (fakerepo *fakeRepo) UpdateVariant(ctx context.Context, itemID, variantID string, in UpdateVariantInput) (Variant, error) {
+	fakerepo.updateVariantInput = in
+	if fakerepo.updateVariantErr != nil {
+		return Variant{}, fakerepo.updateVariantErr
+	}
+	return Variant{ID: variantID, ItemID: itemID}, nil
+}
+
+// DeleteVariant implementa RepositoryAPI.DeleteVariant
+func (fakerepo *fakeRepo) DeleteVariant(ctx context.Context, itemID, variantID string) error {
+	fakerepo.deletedVariantID = variantID
+	return fakerepo.deleteVariantErr
+}
+
+// VariantStock implementa RepositoryAPI.VariantStock con variantCount/variantTotal
+func (fakerepo *fakeRepo) VariantStock(ctx context.Context, itemID string) (int, int, error) {
+	return fakerepo.variantCount, fakerepo.variantTotal, nil
+}
+
+// ListPriceSchedules implementa RepositoryAPI.ListPriceSchedules
+func (fakerepo *fakeRepo) ListPriceSchedules(ctx context.Context, itemID string) ([]PriceSchedule, error) {
+	return fakerepo.priceSchedules, nil
+}
+
+// InsertPriceSchedule implementa RepositoryAPI.InsertPriceSchedule guardando el input
+func (fakerepo *fakeRepo) InsertPriceSchedule(ctx context.Context, itemID string, in CreatePriceScheduleInput) (PriceSchedule, error) {
+	fakerepo.insertScheduleInput = in
+	if fakerepo.insertScheduleErr != nil {
+		return PriceSchedule{}, fakerepo.insertScheduleErr
+	}
+	return PriceSchedule{ID: "schedule-1", ItemID: itemID, NewPrice: in.NewPrice, EffectiveAt: in.EffectiveAt}, nil
+}
+
+// DeletePriceSchedule implementa RepositoryAPI.DeletePriceSchedule
+func (fakerepo *fakeRepo) DeletePriceSchedule(ctx context.Context, itemID, scheduleID string) error {
+	fakerepo.deletedScheduleID = scheduleID
+	return fakerepo.deleteScheduleErr
+}
+
+// DuePriceSchedules implementa RepositoryAPI.DuePriceSchedules con dueSchedules
+func (fakerepo *fakeRepo) DuePriceSchedules(ctx context.Context, limit int) ([]PriceSchedule, error) {
+	fakerepo.dueLimit = limit
+	return fakerepo.dueSchedules, nil
+}
+
+// MarkPriceScheduleApplied implementa RepositoryAPI.MarkPriceScheduleApplied buscando el cambio en dueSchedules
+func (fakerepo *fakeRepo) MarkPriceScheduleApplied(ctx context.Context, scheduleID string) (PriceSchedule, error) {
+	if err := fakerepo.markScheduleErrByID[scheduleID]; err != nil {
+		return PriceSchedule{}, err
+	}
+	fakerepo.appliedScheduleIDs = append(fakerepo.appliedScheduleIDs, scheduleID)
+	for _, schedule := range fakerepo.dueSchedules {
+		if schedule.ID == scheduleID {
+			return schedule, nil
+		}
+	}
+	return PriceSchedule{}, ErrorPriceScheduleNotFound
+}
+
+// MarkPriceScheduleFailed implementa RepositoryAPI.MarkPriceScheduleFailed guardando el motivo en failedSchedules
+func (fakerepo *fakeRepo) MarkPriceScheduleFailed(ctx context.Context, scheduleID, reason string) error {
+	if fakerepo.failedSchedules == nil {
+		fakerepo.failedSchedules = map[string]string{}
+	}
+	fakerepo.failedSchedules[scheduleID] = reason
+	return nil
+}
+
+// GetByExternalRef implementa RepositoryAPI.GetByExternalRef (comparte getItem/getErr con GetByID)
+func (fakerepo *fakeRepo) GetByExternalRef(ctx context.Context, system, externalID string) (Item, error) {
+	fakerepo.getCalled = true
+	fakerepo.refLookup = [2]string{system, externalID}
+	if fakerepo.getErr != nil {
+		return Item{}, fakerepo.getErr
+	}
+	return fakerepo.getItem, nil
+}
+
+// InsertExternalRef implementa RepositoryAPI.InsertExternalRef guardando el input
+func (fakerepo *fakeRepo) InsertExternalRef(ctx context.Context, itemID string, in ExternalRefInput) (ExternalRef, error) {
+	fakerepo.insertRefInput = in
+	if fakerepo.insertRefErr != nil {
+		return ExternalRef{}, fakerepo.insertRefErr
+	}
+	return ExternalRef{System: in.System, ExternalID: in.ExternalID}, nil
+}
+
+// DeleteExternalRef implementa RepositoryAPI.DeleteExternalRef
+func (fakerepo *fakeRepo) DeleteExternalRef(ctx context.Context, itemID, system, externalID string) error {
+	fakerepo.deletedRef = [2]string{system, externalID}
+	return fakerepo.deleteRefErr
+}
+
+// ListTranslations implementa RepositoryAPI.ListTranslations
+func (fakerepo *fakeRepo) ListTranslations(ctx context.Context, itemID string) ([]Translation, error) {
+	return fakerepo.translations, nil
+}
+
+// TranslationsFor implementa RepositoryAPI.TranslationsFor con translationsByItem, sin mirar el locale
+func (fakerepo *fakeRepo) TranslationsFor(ctx context.Context, locale string, itemIDs []string) (map[string]Translation, error) {
+	fakerepo.translationLookup = locale
+	return fakerepo.translationsByItem, nil
+}
+
+// UpsertTranslation implementa RepositoryAPI.UpsertTranslation guardando el input
+func (fakerepo *fakeRepo) UpsertTranslation(ctx context.Context, itemID, locale string, in TranslationInput) (Translation, error) {
+	fakerepo.upsertLocale = locale
+	fakerepo.upsertTranslation = in
+	if fakerepo.upsertErr != nil {
+		return Translation{}, fakerepo.upsertErr
+	}
+	return Translation{Locale: locale, Name: in.Name, Description: in.Description}, nil
+}
+
+// InTx implementa RepositoryAPI.InTx ejecutando fn con el mismo fake
+func (fakerepo *fakeRepo) InTx(ctx context.Context, fn func(tx RepositoryAPI) error) error {
+	fakerepo.inTxCalled = true
+	return fn(fakerepo)
+}
+
+// InSnapshot implementa RepositoryAPI.InSnapshot ejecutando fn con snapshotRepo, o con el mismo fake si es nil
+func (fakerepo *fakeRepo) InSnapshot(ctx context.Context, fn func(tx RepositoryAPI) error) error {
+	fakerepo.inSnapshotCalled = true
+	if fakerepo.snapshotRepo != nil {
+		return fn(fakerepo.snapshotRepo)
+	}
+	return fn(fakerepo)
+}
+
+// TestService_Create_InvalidInput prueba validaciones de Create
+func TestService_CreateMany(t *testing.T) {
+	t.Run("validated with the create defaults", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithDefaultCurrency("EUR"))
+
+		copied, err := service.CreateMany(context.Background(), []CreateItemInput{
+			{Name: " Phone X ", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 2},
+			{Name: "Tablet", Slug: "tablet-pro", SKU: stringPointer("SKU-002"), Price: "20.00"},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(2), copied)
+		require.Len(t, repository.copiedInputs, 2)
+		require.Equal(t, "Phone X", repository.copiedInputs[0].Name)
+		require.Equal(t, "phone-x", repository.copiedInputs[0].Slug)
+		require.Equal(t, "tablet-pro", repository.copiedInputs[1].Slug)
+		require.Equal(t, "EUR", repository.copiedInputs[0].Currency)
+		require.Equal(t, 1, *repository.copiedInputs[0].MinOrderQty)
+	})
+
+	t.Run("an invalid item loads nothing", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.CreateMany(context.Background(), []CreateItemInput{
+			{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "10.00"},
+			{Name: "Tablet", Price: "20.00"},
+		})
+
+		require.ErrorContains(t, err, "item 1: ")
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sku", validationError.Field)
+		require.Empty(t, repository.copiedInputs)
+	})
+}
+
+func TestService_CreateAll(t *testing.T) {
+	t.Run("every item in one transaction", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{takenSlugs: []string{"phone-x"}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		created, err := service.CreateAll(context.Background(), []CreateItemInput{
+			{Name: " Phone X ", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 2},
+			{Name: "Tablet", Slug: "tablet-pro", SKU: stringPointer("SKU-002"), Price: "20.00"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, created, 2)
+		require.True(t, repository.inTxCalled)
+		require.Equal(t, 2, repository.insertCalls)
+		require.Equal(t, "phone-x-2", created[0].Slug, "a generated slug skips the taken ones")
+		require.Equal(t, "tablet-pro", created[1].Slug)
+		require.Len(t, repository.notified, 2)
+		require.Len(t, events.published, 2)
+		require.Equal(t, EventCreated, events.published[0].Operation)
+	})
+
+	t.Run("an invalid item creates nothing", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.CreateAll(context.Background(), []CreateItemInput{
+			{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "10.00"},
+			{Name: "Tablet", Price: "20.00"},
+		})
+
+		var batchError *BatchError
+		require.ErrorAs(t, err, &batchError)
+		require.Equal(t, 1, batchError.Index)
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sku", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("a failed insert reports its position and publishes nothing", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{insertErrs: []error{nil, ErrorDuplicateSKU}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.CreateAll(context.Background(), []CreateItemInput{
+			{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "10.00"},
+			{Name: "Tablet", SKU: stringPointer("SKU-001"), Price: "20.00"},
+		})
+
+		var batchError *BatchError
+		require.ErrorAs(t, err, &batchError)
+		require.Equal(t, 1, batchError.Index)
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+		require.Empty(t, events.published)
+	})
+}
+
+func TestService_ValidateCreate(t *testing.T) {
+	t.Run("valid item is checked against the unique keys", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithDefaultCurrency("EUR"))
+
+		input, err := service.ValidateCreate(context.Background(), CreateItemInput{Name: " Phone X ", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "Phone X", input.Name)
+		require.Equal(t, "EUR", input.Currency)
+		require.Equal(t, []CreateItemInput{input}, repository.uniqueChecked)
+		require.Zero(t, repository.insertCalls, "nothing is written")
+	})
+
+	t.Run("taken key", func(t *testing.T) {
+		repository := &fakeRepo{uniqueErr: ErrorDuplicateSKU}
+		service := NewService(repository)
+
+		_, err := service.ValidateCreate(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+	})
+
+	t.Run("invalid item skips the lookup", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.ValidateCreate(context.Background(), CreateItemInput{Name: "Phone X", Price: "10.00"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sku", validationError.Field)
+		require.Empty(t, repository.uniqueChecked)
+	})
+}
+
+func TestService_UpsertBySKU(t *testing.T) {
+	t.Run("new sku is created", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		item, created, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("sku-001"), Price: "10.00", Stock: 3})
+
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, "Phone X", item.Name)
+		require.Equal(t, "SKU-001", repository.getSKU, "looked up with the normalized sku")
+		require.True(t, repository.insertCalled)
+		require.Nil(t, repository.upsertInput)
+		require.Len(t, repository.priceChanges, 1)
+		require.Equal(t, StockReasonCreate, repository.priceChanges[0].Reason)
+	})
+
+	t.Run("existing sku updates price, stock and description with history", func(t *testing.T) {
+		description := "old"
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Phone X", SKU: stringPointer("SKU-001"), Description: &description, Price: "10.00", Stock: 3, Currency: "USD", Version: 4}}
+		service := NewService(repository, WithOutbox(true))
+
+		item, created, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Description: stringPointer("new"), Price: "12.50", Stock: 7})
+
+		require.NoError(t, err)
+		require.False(t, created)
+		require.False(t, repository.insertCalled)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, "12.50", item.Price)
+		require.Equal(t, 7, item.Stock)
+		require.Equal(t, "new", *item.Description)
+		require.Equal(t, []PriceHistoryEntry{{ItemID: "id-1", OldPrice: stringPointer("10.00"), NewPrice: "12.50", Reason: StockReasonImport}}, repository.priceChanges)
+		require.Len(t, repository.movements, 1)
+		require.Equal(t, 4, repository.movements[0].Delta)
+		require.NotEmpty(t, repository.audit)
+		require.Len(t, repository.outbox, 1)
+		require.Equal(t, EventUpdated, repository.outbox[0].Operation)
+	})
+
+	t.Run("existing sku without description keeps the current one", func(t *testing.T) {
+		description := "old"
+		repository := &fakeRepo{getItem: Item{ID: "id-1", SKU: stringPointer("SKU-001"), Description: &description, Price: "10.00", Currency: "USD"}}
+		service := NewService(repository)
+
+		item, _, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "12.50"})
+
+		require.NoError(t, err)
+		require.Nil(t, repository.upsertInput.Description)
+		require.Equal(t, "old", *item.Description)
+	})
+
+	t.Run("stock of an item with variants", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 5, Currency: "USD"}, variantCount: 2}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, _, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "12.00", Stock: 9})
+
+		require.ErrorIs(t, err, ErrorStockManagedByVariants)
+		require.Empty(t, repository.movements)
+		require.Empty(t, events.published)
+
+		// Con el mismo stock el resto del upsert sigue valiendo.
+		item, _, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "12.00", Stock: 5})
+
+		require.NoError(t, err)
+		require.Equal(t, "12.00", item.Price)
+	})
+
+	t.Run("price precision follows the currency of the existing item", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			defaultCurrency string
+			itemCurrency    string
+			price           string
+			wantErr         bool
+		}{
+			{"cents in an item without decimals", "USD", "JPY", "1500.50", true},
+			{"whole amount in an item without decimals", "USD", "JPY", "1500", false},
+			{"cents in an item with decimals under a default without them", "JPY", "USD", "12.50", false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{getItem: Item{ID: "id-1", SKU: stringPointer("SKU-001"), Price: "10.00", Currency: tt.itemCurrency}}
+				service := NewService(repository, WithDefaultCurrency(tt.defaultCurrency))
+
+				_, _, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: tt.price})
+
+				if tt.wantErr {
+					var validationError *ValidationError
+					require.ErrorAs(t, err, &validationError)
+					require.Equal(t, "price", validationError.Field)
+					require.Nil(t, repository.upsertInput)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.itemCurrency, repository.upsertInput.Currency, "an upsert does not change the currency")
+			})
+		}
+	})
+
+	t.Run("negative stock needs backorder on the existing item", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", SKU: stringPointer("SKU-001"), Price: "10.00", Currency: "USD"}}
+		service := NewService(repository, WithBackorderFloor(-10))
+
+		_, _, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: -2, AllowBackorder: true})
+
+		require.ErrorIs(t, err, ErrorInvalidStock)
+		require.Nil(t, repository.upsertInput)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, _, err := service.UpsertBySKU(context.Background(), CreateItemInput{Name: "Phone X", Price: "10.00"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.False(t, repository.getCalled)
+	})
+}
+
+func TestService_Create_InvalidInput(t *testing.T) {
+	t.Run("invalid name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{
+			Name:  "   ",
+			SKU:   stringPointer("SKU-001"),
+			Price: "100.00",
+			Stock: 1,
+		})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.insertCalled, "repo.Insert should not be called on invalid input")
+	})
+
+	t.Run("price validation", func(t *testing.T) {
+		// Casos diseñados para cubrir casos típicas:
+		// - regex no matchea
+		// - trim
+		// - cero (bloqueo rápido)
+		// - formatos válidos (siempre que tu regex los permita)
+		tests := []struct {
+			name    string
+			price   string
+			wantErr bool
+		}{
+			// No matchea regex / inválidos obvios
+			{"letters", "aaa", true},
+			{"mixed", "100a", true},
+			{"blank", " ", true},
+			{"comma", "10,00", true},
+			{"dot-leading", ".50", true},
+			{"negative", "-1.00", true},
+
+			// Trim + formato
+			{"trimmed valid", " 10.00 ", false},
+
+			// Ceros (deben fallar si exigís > 0)
+			{"zero int", "0", true},
+			{"zero decimals", "0.00", true},
+
+			// Decimales: ajustá según tu regex actual
+			// Si usás `^\d+(\.\d{1,2})?$` estos pasan:
+			{"one decimal", "10.5", false},
+			{"two decimals", "10.50", false},
+			{"int", "10", false},
+
+			// Si cambiás a `^\d+(\.\d{2})?$`, entonces:
+			// {"one decimal", "10.5", true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.Create(context.Background(), CreateItemInput{
+					Name:  "product",
+					SKU:   stringPointer("SKU-001"),
+					Price: tt.price,
+					Stock: 1,
+				})
+
+				if tt.wantErr {
+					require.ErrorIs(t, err, ErrorInvalidInput, "price=%q", tt.price)
+					require.False(t, repository.insertCalled, "repo.Insert should not be called on invalid input (price=%q)", tt.price)
+				} else {
+					require.NoError(t, err, "price=%q", tt.price)
+					require.True(t, repository.insertCalled, "repo.Insert should be called on valid input (price=%q)", tt.price)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid stock", func(t *testing.T) {
+		testCases := []int{-1, -2}
+		for _, stock := range testCases {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.Create(context.Background(), CreateItemInput{
+				Name:  "product",
+				SKU:   stringPointer("SKU-001"),
+				Price: "100",
+				Stock: stock,
+			})
+			require.ErrorIs(t, err, ErrorInvalidInput, "stock=%d", stock)
+			require.False(t, repository.insertCalled, "repo.Insert should not be called on invalid input")
+		}
+	})
+
+	t.Run("insert product", func(t *testing.T) {
+		errDB := errors.New("db down")
+		tests := []struct {
+			name       string
+			insertErr  error
+			wantErr    error
+			expectSame bool
+		}{
+			{
+				name:    "success",
+				wantErr: nil,
+			},
+			{
+				name:       "duplicate name error",
+				insertErr:  fmt.Errorf("wrapped: %w", ErrorDuplicateName),
+				wantErr:    ErrorDuplicateName,
+				expectSame: false,
+			},
+			{
+				name:       "unknown repo error",
+				insertErr:  errDB,
+				wantErr:    errDB,
+				expectSame: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{insertErr: tt.insertErr}
+				service := NewService(repository)
+
+				input := CreateItemInput{
+					Name:  "  product  ",
+					SKU:   stringPointer("SKU-001"),
+					Price: "10.00",
+					Stock: 1,
+				}
+
+				product, err := service.Create(context.Background(), input)
+
+				if tt.wantErr != nil {
+					require.Error(t, err)
+					require.ErrorIs(t, err, tt.wantErr)
+					if tt.expectSame {
+						require.True(t, err == tt.wantErr, "expected same error instance, got %v", err)
+					}
+					require.True(t, repository.insertCalled, "repo.Insert should be called")
+					return
+				}
+
+				require.NoError(t, err)
+				require.True(t, repository.insertCalled, "repo.Insert should be called")
+				require.NotEmpty(t, product.ID, "expected created item, got empty ID")
+				require.Equal(t, "product", repository.insertCreatedInput.Name, "expected trimmed name")
+			})
+		}
+	})
+}
+
+func TestService_InvalidInputReasons(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			input   CreateItemInput
+			wantErr error
+		}{
+			{"blank name", CreateItemInput{Name: "  ", Price: "10.00", Stock: 1}, ErrorInvalidName},
+			{"blank price", CreateItemInput{Name: "Phone", Price: " ", Stock: 1}, ErrorInvalidPrice},
+			{"malformed price", CreateItemInput{Name: "Phone", Price: "10,00", Stock: 1}, ErrorInvalidPrice},
+			{"zero price", CreateItemInput{Name: "Phone", Price: "0.00", Stock: 1}, ErrorInvalidPrice},
+			{"negative stock", CreateItemInput{Name: "Phone", Price: "10.00", Stock: -1}, ErrorInvalidStock},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.Create(context.Background(), tt.input)
+
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorIs(t, err, ErrorInvalidInput, "every reason must still be an invalid input")
+				require.False(t, repository.insertCalled)
+			})
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			input   UpdateItemInput
+			wantErr error
+		}{
+			{"no fields", UpdateItemInput{}, ErrorInvalidInput},
+			{"blank name", UpdateItemInput{Name: stringPointer(" ")}, ErrorInvalidName},
+			{"blank price", UpdateItemInput{Price: stringPointer(" ")}, ErrorInvalidPrice},
+			{"malformed price", UpdateItemInput{Price: stringPointer("abc")}, ErrorInvalidPrice},
+			{"negative stock", UpdateItemInput{Stock: integerPointer(-5)}, ErrorInvalidStock},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.Update(context.Background(), "id", tt.input)
+
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorIs(t, err, ErrorInvalidInput)
+				require.False(t, repository.updateCalled)
+			})
+		}
+	})
+}
+
+func TestService_LengthLimits(t *testing.T) {
+	// "ñ" ocupa dos bytes: el límite se cuenta en caracteres.
+	longestName := strings.Repeat("ñ", maxNameLength)
+	longestDescription := strings.Repeat("ñ", maxDescriptionLength)
+
+	t.Run("create accepts the limits and trims the description", func(t *testing.T) {
+		repository := &fakeRepo{}
+		description := "  " + longestDescription + "\n"
+
+		_, err := NewService(repository).Create(context.Background(), CreateItemInput{Name: " " + longestName + " ", SKU: stringPointer("KB-001"), Description: &description, Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, longestName, repository.insertCreatedInput.Name)
+		require.Equal(t, longestDescription, *repository.insertCreatedInput.Description)
+	})
+
+	t.Run("update trims the description", func(t *testing.T) {
+		repository := &fakeRepo{}
+
+		_, err := NewService(repository).Update(context.Background(), "id", UpdateItemInput{Description: stringPointer(" Mechanical "), DescriptionPresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "Mechanical", *repository.updateInput.Description)
+	})
+
+	tooLongName := longestName + "a"
+	tooLongDescription := longestDescription + "a"
+	for _, tt := range []struct {
+		name   string
+		create CreateItemInput
+		update UpdateItemInput
+		field  string
+	}{
+		{"name", CreateItemInput{Name: tooLongName, Price: "10.00"}, UpdateItemInput{Name: &tooLongName}, "name"},
+		{"description", CreateItemInput{Name: "Phone", Description: &tooLongDescription, Price: "10.00"}, UpdateItemInput{Description: &tooLongDescription, DescriptionPresent: true}, "description"},
+	} {
+		t.Run(tt.name+" too long", func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, createErr := service.Create(context.Background(), tt.create)
+			_, updateErr := service.Update(context.Background(), "id", tt.update)
+
+			for _, err := range []error{createErr, updateErr} {
+				var validationError *ValidationError
+				require.ErrorAs(t, err, &validationError)
+				require.Equal(t, tt.field, validationError.Field)
+				require.ErrorIs(t, err, ErrorInvalidInput)
+			}
+			require.False(t, repository.insertCalled)
+			require.False(t, repository.updateCalled)
+		})
+	}
+}
+
+func TestService_CanonicalPrice(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		repository := &fakeRepo{}
+
+		_, err := NewService(repository).Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Price: " 10 ", SalePrice: stringPointer("9.5")})
+
+		require.NoError(t, err)
+		require.Equal(t, "10.00", repository.insertCreatedInput.Price)
+		require.Equal(t, "9.50", *repository.insertCreatedInput.SalePrice)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repository := &fakeRepo{}
+
+		_, err := NewService(repository).Update(context.Background(), "id", UpdateItemInput{Price: stringPointer("010.5"), SalePrice: stringPointer("7"), SalePricePresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "10.50", *repository.updateInput.Price)
+		require.Equal(t, "7.00", *repository.updateInput.SalePrice)
+	})
+
+	t.Run("create variant", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Currency: "USD"}}
+
+		_, err := NewService(repository).CreateVariant(context.Background(), "id-1", CreateVariantInput{SKU: "TS-M", Price: stringPointer(" 10 ")})
+
+		require.NoError(t, err)
+		require.Equal(t, "10.00", *repository.insertVariantInput.Price)
+	})
+
+	t.Run("update variant", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Currency: "USD"}}
+
+		_, err := NewService(repository).UpdateVariant(context.Background(), "id-1", "variant-1", UpdateVariantInput{Price: stringPointer("010.5"), PricePresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "10.50", *repository.updateVariantInput.Price)
+	})
+
+	t.Run("price schedule", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Currency: "USD"}}
+		service := NewService(repository)
+		service.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+		_, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "12.5", EffectiveAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)})
+
+		require.NoError(t, err)
+		require.Equal(t, "12.50", repository.insertScheduleInput.NewPrice)
+	})
+}
+
+// TestService_List prueba la lista de productos
+func TestService_List(t *testing.T) {
+	t.Run("invalid pagination", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			page  int
+			limit int
+		}{
+			{"page zero", 0, 10},
+			{"page negative", -1, 10},
+			{"limit zero", 1, 0},
+			{"limit negative", 1, -10},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				result, err := service.List(context.Background(), tt.page, tt.limit, ListFilter{Query: "any"})
+
+				require.ErrorIs(t, err, ErrorInvalidInput)
+				require.Zero(t, result)
+				require.False(t, repository.listCalled, "repo.List should not be called")
+				require.False(t, repository.countCalled, "repo.Count should not be called")
+			})
+		}
+	})
+
+	t.Run("max offset", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			maxOffset int
+			page      int
+			limit     int
+			wantErr   bool
+		}{
+			{"exactly at cap", 100, 10, 10, false},
+			{"one page past cap", 100, 11, 10, true},
+			{"limit not dividing cap", 100, 4, 30, true},
+			{"default cap", DefaultMaxOffset, DefaultMaxOffset / 100, 100, false},
+			{"past default cap", DefaultMaxOffset, DefaultMaxOffset/100 + 1, 100, true},
+			// Un page enorme no puede desbordar page*limit y pasar el tope.
+			{"overflowing page", 100, math.MaxInt / 2, 10, true},
+			{"disabled", 0, 1000000, 100, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository, WithMaxOffset(tt.maxOffset))
+
+				_, err := service.List(context.Background(), tt.page, tt.limit, ListFilter{})
+
+				if tt.wantErr {
+					require.ErrorIs(t, err, ErrorPaginationTooDeep)
+					require.ErrorIs(t, err, ErrorInvalidInput)
+					require.False(t, repository.listCalled)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, (tt.page-1)*tt.limit, repository.listOffset)
+			})
+		}
+	})
+
+	t.Run("match mode validation", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			match     MatchMode
+			wantMatch MatchMode
+			wantErr   error
+		}{
+			{"empty defaults to contains", "", MatchContains, nil},
+			{"contains", MatchContains, MatchContains, nil},
+			{"prefix", MatchPrefix, MatchPrefix, nil},
+			{"exact", MatchExact, MatchExact, nil},
+			{"unknown", MatchMode("fuzzy"), "", ErrorInvalidMatch},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.List(context.Background(), 1, 10, ListFilter{Query: "cable", Match: tt.match})
+
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+					require.ErrorIs(t, err, ErrorInvalidInput)
+					require.False(t, repository.listCalled, "repo.List should not be called")
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.wantMatch, repository.listFilter.Match)
+				require.True(t, repository.listWithTotalCalled)
+			})
+		}
+	})
+
+	t.Run("price range validation", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			minPrice  string
+			maxPrice  string
+			wantField string
+		}{
+			{"no range", "", "", ""},
+			{"only min", "10.00", "", ""},
+			{"only max", "", " 99.99 ", ""},
+			{"equal bounds", "10", "10.00", ""},
+			// Comparación numérica: como texto "9.50" > "10.00".
+			{"numeric comparison", "9.50", "10.00", ""},
+			{"invalid min", "abc", "", "min_price"},
+			{"zero min", "0", "", "min_price"},
+			{"too many decimals", "", "10.999", "max_price"},
+			{"min greater than max", "100.00", "20.00", "min_price"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.List(context.Background(), 1, 10, ListFilter{MinPrice: tt.minPrice, MaxPrice: tt.maxPrice})
+
+				if tt.wantField != "" {
+					require.ErrorIs(t, err, ErrorInvalidFilter)
+					require.ErrorIs(t, err, ErrorInvalidInput)
+					var filterError *FilterError
+					require.ErrorAs(t, err, &filterError)
+					require.Equal(t, tt.wantField, filterError.Field)
+					require.False(t, repository.listCalled, "repo.List should not be called")
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, strings.TrimSpace(tt.maxPrice), repository.listFilter.MaxPrice)
+			})
+		}
+	})
+
+	t.Run("search fields validation", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			fields  []string
+			wantErr string
+		}{
+			{"default", nil, ""},
+			{"name and description", []string{"name", "description"}, ""},
+			{"identifier fields", []string{"sku", "barcode"}, ""},
+			{"unknown field", []string{"name", "price"}, `unknown search field "price" (allowed: name, description, sku, barcode, all)`},
+			{"all combined with a field", []string{"all", "name"}, `search field "all" cannot be combined with other fields`},
+			{"empty entry", []string{"name", ""}, `unknown search field ""`},
+			{"repeated field", []string{"description", "description"}, `search field "description" is repeated`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.List(context.Background(), 1, 10, ListFilter{Query: "leather", SearchFields: tt.fields})
+
+				if tt.wantErr != "" {
+					var filterError *FilterError
+					require.ErrorAs(t, err, &filterError)
+					require.Equal(t, "search_fields", filterError.Field)
+					require.Contains(t, filterError.Message, tt.wantErr)
+					require.False(t, repository.listCalled, "repo.List should not be called")
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.fields, repository.listFilter.SearchFields)
+			})
+		}
+	})
+
+	t.Run("search_fields all expands to every field", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.List(context.Background(), 1, 10, ListFilter{Query: "KB-001", SearchFields: []string{"all"}})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"name", "description", "sku", "barcode"}, repository.listFilter.SearchFields)
+	})
+
+	t.Run("name_eq cannot be combined with query", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.List(context.Background(), 1, 10, ListFilter{Query: "phone", NameEq: "Phone X"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "name_eq", filterError.Field)
+		require.False(t, repository.listCalled)
+	})
+
+	t.Run("fuzzy", func(t *testing.T) {
+		t.Run("uses the default threshold", func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.List(context.Background(), 1, 10, ListFilter{Query: "keybord", Fuzzy: true})
+
+			require.NoError(t, err)
+			require.True(t, repository.listFilter.Fuzzy)
+			require.Equal(t, DefaultFuzzyThreshold, repository.listFilter.FuzzyThreshold)
+		})
+
+		t.Run("custom threshold", func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository, WithFuzzyThreshold(0.45))
+
+			_, err := service.List(context.Background(), 1, 10, ListFilter{Query: "keybord", Fuzzy: true, SearchFields: []string{"name"}})
+
+			require.NoError(t, err)
+			require.Equal(t, 0.45, repository.listFilter.FuzzyThreshold)
+		})
+
+		for _, tt := range []struct {
+			name    string
+			filter  ListFilter
+			message string
+		}{
+			{"requires a query", ListFilter{Fuzzy: true}, "fuzzy requires a query"},
+			{"not on description", ListFilter{Query: "x", Fuzzy: true, SearchFields: []string{"description"}}, "fuzzy search only supports the name, sku and barcode fields"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.List(context.Background(), 1, 10, tt.filter)
+
+				var filterError *FilterError
+				require.ErrorAs(t, err, &filterError)
+				require.Equal(t, FilterError{Field: "fuzzy", Message: tt.message}, *filterError)
+				require.False(t, repository.listCalled)
+			})
+		}
+	})
+
+	t.Run("stock range validation", func(t *testing.T) {
+		intPtr := func(value int) *int { return &value }
+		tests := []struct {
+			name      string
+			gte       *int
+			lte       *int
+			wantField string
+		}{
+			{"no range", nil, nil, ""},
+			{"zero bounds", intPtr(0), intPtr(0), ""},
+			{"range", intPtr(1), intPtr(5), ""},
+			{"negative gte", intPtr(-1), nil, "stock_gte"},
+			{"negative lte", nil, intPtr(-3), "stock_lte"},
+			{"inverted", intPtr(10), intPtr(2), "stock_gte"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.List(context.Background(), 1, 10, ListFilter{StockGTE: tt.gte, StockLTE: tt.lte})
+
+				if tt.wantField != "" {
+					var filterError *FilterError
+					require.ErrorAs(t, err, &filterError)
+					require.Equal(t, tt.wantField, filterError.Field)
+					require.False(t, repository.listCalled, "repo.List should not be called")
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.gte, repository.listFilter.StockGTE)
+			})
+		}
+	})
+
+	t.Run("sort validation", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			sort    []SortKey
+			wantErr bool
+		}{
+			{"empty", nil, false},
+			{"price", []SortKey{"price"}, false},
+			{"price descending", []SortKey{"-price"}, false},
+			{"created_at", []SortKey{"created_at"}, false},
+			{"created_at descending", []SortKey{"-created_at"}, false},
+			{"multiple fields", []SortKey{"stock", "-price", "name"}, false},
+			{"unknown column", []SortKey{"weight"}, true},
+			{"empty entry", []SortKey{"price", ""}, true},
+			{"duplicate field", []SortKey{"price", "stock", "price"}, true},
+			{"duplicate field with other direction", []SortKey{"price", "-price"}, true},
+			{"sql injection attempt", []SortKey{"price; DROP TABLE items"}, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.List(context.Background(), 1, 10, ListFilter{Sort: tt.sort})
+
+				if tt.wantErr {
+					require.ErrorIs(t, err, ErrorInvalidSort)
+					require.False(t, repository.listCalled, "repo.List should not be called")
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.sort, repository.listFilter.Sort)
+			})
+		}
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		repository := &fakeRepo{listErr: errors.New("list failed")}
+		service := NewService(repository)
+
+		result, err := service.List(context.Background(), 1, 10, ListFilter{Query: "  test  "})
+
+		require.ErrorIs(t, err, repository.listErr)
+		require.Zero(t, result)
+		require.True(t, repository.listCalled, "repo.List should be called")
+		require.False(t, repository.countCalled, "repo.Count should not be called on list error")
+	})
+
+	t.Run("total comes from the same query", func(t *testing.T) {
+		repository := &fakeRepo{
+			listItems:  []Item{{ID: "1", Name: "a"}},
+			countTotal: 6,
+			countErr:   errors.New("count failed"),
+		}
+		service := NewService(repository)
+
+		result, err := service.List(context.Background(), 2, 5, ListFilter{Query: "  test  "})
+
+		require.NoError(t, err)
+		require.Equal(t, 6, result.Total)
+		require.True(t, repository.listWithTotalCalled, "repo.ListWithTotal should be called")
+		require.False(t, repository.countCalled, "repo.Count should not be called")
+		require.Equal(t, "test", repository.listFilter.Query, "expected trimmed query")
+		require.Equal(t, 5, repository.listLimit)
+		require.Equal(t, 5, repository.listOffset)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		expectedItems := []Item{
+			{ID: "1", Name: "a"},
+			{ID: "2", Name: "b"},
+		}
+		repository := &fakeRepo{
+			listItems:  expectedItems,
+			countTotal: 2,
+		}
+		service := NewService(repository)
+
+		result, err := service.List(context.Background(), 3, 10, ListFilter{Query: "  name  "})
+
+		require.NoError(t, err)
+		require.Equal(t, ListPage{Items: expectedItems, Total: 2, Cursor: &CreatedAtID{ID: "2"}}, result)
+		require.True(t, repository.listWithTotalCalled, "repo.ListWithTotal should be called")
+		require.Equal(t, "name", repository.listFilter.Query, "expected trimmed query")
+		require.Equal(t, 10, repository.listLimit)
+		require.Equal(t, 20, repository.listOffset)
+	})
+}
+
+func TestService_ListEstimatedCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		enabled      bool
+		filter       ListFilter
+		estimateOK   bool
+		wantEstimate bool
+		wantExact    bool
+	}{
+		{"disabled", false, ListFilter{}, true, false, true},
+		{"unfiltered", true, ListFilter{}, true, true, false},
+		{"sort only is unfiltered", true, ListFilter{Sort: []SortKey{"-price"}}, true, true, false},
+		{"listing defaults are unfiltered", true, withDefaultStatus(ListFilter{}), true, true, false},
+		{"another status stays exact", true, ListFilter{Status: StatusInactive}, true, false, true},
+		{"filtered stays exact", true, ListFilter{Query: "phone"}, true, false, true},
+		{"trash stays exact", true, ListFilter{Scope: ScopeDeleted}, true, false, true},
+		{"no statistics falls back to count", true, ListFilter{}, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeRepo{countTotal: 7, estimateTotal: 2000000, estimateOK: tt.estimateOK}
+			service := NewService(repository, WithEstimatedCount(tt.enabled))
+
+			result, err := service.List(context.Background(), 1, 10, tt.filter)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEstimate, result.TotalIsEstimate)
+			require.Equal(t, tt.wantExact, repository.listWithTotalCalled)
+			if tt.wantEstimate {
+				require.Equal(t, 2000000, result.Total)
+				require.Equal(t, tt.filter.Status, repository.estimateFilter.Status, "estimated against the same filter")
+				return
+			}
+			require.Equal(t, 7, result.Total)
+		})
+	}
+
+	t.Run("estimate error", func(t *testing.T) {
+		repository := &fakeRepo{estimateErr: errors.New("pg_class unavailable")}
+		service := NewService(repository, WithEstimatedCount(true))
+
+		_, err := service.List(context.Background(), 1, 10, ListFilter{})
+
+		require.ErrorIs(t, err, repository.estimateErr)
+		require.False(t, repository.listCalled)
+	})
+
+	t.Run("cursor pages use it too", func(t *testing.T) {
+		repository := &fakeRepo{estimateTotal: 2000000, estimateOK: true}
+		service := NewService(repository, WithEstimatedCount(true))
+
+		result, err := service.ListAfter(context.Background(), CreatedAtID{ID: "id-0"}, 10, ListFilter{})
+
+		require.NoError(t, err)
+		require.True(t, result.TotalIsEstimate)
+		require.False(t, repository.countCalled)
+	})
+}
+
+func TestService_ListCursor(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hit := Item{ID: "id-1", SKU: stringPointer("KB-001"), Barcode: stringPointer("7790001000012"), CreatedAt: createdAt}
+
+	tests := []struct {
+		name   string
+		filter ListFilter
+		want   *CreatedAtID
+	}{
+		{"default order", ListFilter{}, &CreatedAtID{CreatedAt: createdAt, ID: "id-1"}},
+		{"explicit default sort", ListFilter{Sort: []SortKey{"-created_at"}}, &CreatedAtID{CreatedAt: createdAt, ID: "id-1"}},
+		{"custom sort", ListFilter{Sort: []SortKey{"price"}}, nil},
+		{"fuzzy", ListFilter{Query: "keyboard", Fuzzy: true}, nil},
+		{"sku hit", ListFilter{Query: " kb-001 "}, &CreatedAtID{CreatedAt: createdAt, ID: "id-1", Ranked: true}},
+		{"barcode hit with all fields", ListFilter{Query: "7790001000012", SearchFields: []string{"all"}}, &CreatedAtID{CreatedAt: createdAt, ID: "id-1", Ranked: true}},
+		{"sku not searched", ListFilter{Query: "kb-001", SearchFields: []string{"name"}}, &CreatedAtID{CreatedAt: createdAt, ID: "id-1"}},
+		{"name match only", ListFilter{Query: "kb"}, &CreatedAtID{CreatedAt: createdAt, ID: "id-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeRepo{listItems: []Item{hit}, countTotal: 3}
+
+			result, err := NewService(repository).List(context.Background(), 1, 1, tt.filter)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result.Cursor)
+		})
+	}
+
+	t.Run("empty page", func(t *testing.T) {
+		result, err := NewService(&fakeRepo{}).List(context.Background(), 1, 1, ListFilter{})
+
+		require.NoError(t, err)
+		require.Nil(t, result.Cursor)
+	})
+}
+
+func TestService_ListAfter(t *testing.T) {
+	after := CreatedAtID{CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: "id-0"}
+
+	t.Run("has more", func(t *testing.T) {
+		repository := &fakeRepo{
+			listItems:  []Item{{ID: "id-1"}, {ID: "id-2"}, {ID: "id-3"}},
+			countTotal: 10,
+		}
+		service := NewService(repository)
+
+		result, err := service.ListAfter(context.Background(), after, 2, ListFilter{Query: "phone"})
+
+		require.NoError(t, err)
+		require.Equal(t, ListPage{Items: []Item{{ID: "id-1"}, {ID: "id-2"}}, Total: 10, HasMore: true, Cursor: &CreatedAtID{ID: "id-2"}}, result)
+		// Pide uno de más para saber si hay página siguiente.
+		require.Equal(t, 3, repository.listLimit)
+		require.Equal(t, after, repository.listAfter)
+		require.Equal(t, MatchContains, repository.listFilter.Match)
+		require.Equal(t, repository.listFilter, repository.countFilter)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		repository := &fakeRepo{listItems: []Item{{ID: "id-1"}}, countTotal: 1}
+		service := NewService(repository)
+
+		result, err := service.ListAfter(context.Background(), after, 2, ListFilter{})
+
+		require.NoError(t, err)
+		require.False(t, result.HasMore)
+		require.Len(t, result.Items, 1)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			limit   int
+			filter  ListFilter
+			wantErr error
+		}{
+			{"limit zero", 0, ListFilter{}, ErrorInvalidInput},
+			{"custom sort", 10, ListFilter{Sort: []SortKey{"price"}}, ErrorCursorUnsupported},
+			{"ascending created_at", 10, ListFilter{Sort: []SortKey{"created_at"}}, ErrorCursorUnsupported},
+			{"fuzzy", 10, ListFilter{Query: "phone", Fuzzy: true}, ErrorCursorUnsupported},
+			{"invalid filter", 10, ListFilter{MinPrice: "abc"}, ErrorInvalidFilter},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.ListAfter(context.Background(), after, tt.limit, tt.filter)
+
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorIs(t, err, ErrorInvalidInput)
+				require.False(t, repository.listAfterCalled)
+			})
+		}
+	})
+
+	t.Run("explicit default sort", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.ListAfter(context.Background(), after, 10, ListFilter{Sort: []SortKey{"-created_at"}})
+
+		require.NoError(t, err)
+		require.True(t, repository.listAfterCalled)
+	})
+}
+
+func TestService_Slug(t *testing.T) {
+	t.Run("create generates the slug from the name", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		item, err := service.Create(context.Background(), CreateItemInput{Name: "Wireless Keyboard", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "wireless-keyboard", item.Slug)
+		require.Equal(t, "wireless-keyboard", repository.takenSlugsBase)
+		require.Empty(t, repository.takenSlugsExcept)
+	})
+
+	t.Run("create suffixes a taken slug", func(t *testing.T) {
+		repository := &fakeRepo{takenSlugs: []string{"wireless-keyboard", "wireless-keyboard-2"}}
+		service := NewService(repository)
+
+		item, err := service.Create(context.Background(), CreateItemInput{Name: "Wireless keyboard!", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "wireless-keyboard-3", item.Slug)
+	})
+
+	t.Run("create retries when another request takes the generated slug", func(t *testing.T) {
+		repository := &fakeRepo{insertErrs: []error{ErrorDuplicateSlug}}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, 2, repository.insertCalls)
+	})
+
+	t.Run("create gives up after a few races", func(t *testing.T) {
+		repository := &fakeRepo{insertErr: ErrorDuplicateSlug}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateSlug)
+		require.Equal(t, maxSlugAttempts, repository.insertCalls)
+	})
+
+	t.Run("create keeps an explicit slug", func(t *testing.T) {
+		repository := &fakeRepo{insertErr: ErrorDuplicateSlug}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", Slug: " my-keyboard ", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		// Un slug explícito tomado es un conflicto: no se le agrega sufijo ni se reintenta.
+		require.ErrorIs(t, err, ErrorDuplicateSlug)
+		require.Equal(t, "my-keyboard", repository.insertCreatedInput.Slug)
+		require.Equal(t, 1, repository.insertCalls)
+		require.False(t, repository.takenSlugsCalled)
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		tests := []string{"My Keyboard", "keyboard_2", "-keyboard", strings.Repeat("a", 121)}
+
+		for _, slug := range tests {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", Slug: slug, SKU: stringPointer("SKU-001"), Price: "10.00"})
+			require.ErrorIs(t, err, ErrorInvalidSlug, slug)
+			require.False(t, repository.insertCalled)
+
+			_, err = service.Update(context.Background(), "id-1", UpdateItemInput{Slug: &slug})
+			require.ErrorIs(t, err, ErrorInvalidSlug, slug)
+			require.False(t, repository.updateCalled)
+		}
+	})
+
+	t.Run("rename regenerates the slug excluding the item itself", func(t *testing.T) {
+		repository := &fakeRepo{takenSlugs: []string{"mechanical-keyboard"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Name: stringPointer("Mechanical Keyboard")})
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", repository.takenSlugsExcept)
+		require.Equal(t, "mechanical-keyboard-2", *repository.updateInput.Slug)
+	})
+
+	t.Run("rename keeps an explicit slug", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{
+			Name: stringPointer("Mechanical Keyboard"),
+			Slug: stringPointer("keyboard-pro"),
+		})
+
+		require.NoError(t, err)
+		require.False(t, repository.takenSlugsCalled)
+		require.Equal(t, "keyboard-pro", *repository.updateInput.Slug)
+	})
+
+	t.Run("update without name leaves the slug alone", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Price: stringPointer("12.00")})
+
+		require.NoError(t, err)
+		require.False(t, repository.takenSlugsCalled)
+		require.Nil(t, repository.updateInput.Slug)
+	})
+
+	t.Run("duplicate slug on update", func(t *testing.T) {
+		repository := &fakeRepo{updateErr: ErrorDuplicateSlug}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Slug: stringPointer("taken")})
+
+		require.ErrorIs(t, err, ErrorDuplicateSlug)
+	})
+
+	t.Run("get by slug", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Slug: "keyboard"}}
+		service := NewService(repository)
+
+		item, err := service.GetBySlug(context.Background(), "keyboard")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Equal(t, "keyboard", repository.getSlug)
+	})
+}
+
+func TestService_Replace(t *testing.T) {
+	t.Run("normalizes and generates the slug", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		item, err := service.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "  Wireless Phone ", Price: " 10.00 ", Stock: 2})
+
+		require.NoError(t, err)
+		require.Equal(t, ReplaceItemInput{Name: "Wireless Phone", Slug: "wireless-phone", Price: "10.00", Stock: 2}, repository.replaceInput)
+		require.Equal(t, "id-1", repository.replaceID)
+		require.Equal(t, "id-1", repository.takenSlugsExcept)
+		require.Nil(t, item.Description)
+	})
+
+	t.Run("explicit slug is kept", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "Phone", Slug: "my-phone", Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "my-phone", repository.replaceInput.Slug)
+		require.False(t, repository.takenSlugsCalled)
+	})
+
+	t.Run("create rules apply", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input ReplaceItemInput
+			want  error
+		}{
+			{"missing name", ReplaceItemInput{Price: "10.00"}, ErrorInvalidName},
+			{"missing price", ReplaceItemInput{Name: "Phone"}, ErrorInvalidPrice},
+			{"invalid price", ReplaceItemInput{Name: "Phone", Price: "10.001"}, ErrorInvalidPrice},
+			{"negative stock", ReplaceItemInput{Name: "Phone", Price: "10.00", Stock: -1}, ErrorInvalidStock},
+			{"invalid slug", ReplaceItemInput{Name: "Phone", Slug: "Bad Slug", Price: "10.00"}, ErrorInvalidSlug},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.Replace(context.Background(), "id-1", tt.input)
+
+				require.ErrorIs(t, err, tt.want)
+				require.False(t, repository.replaceCalled)
+			})
+		}
+	})
+
+	t.Run("validators see every field", func(t *testing.T) {
+		var calls []string
+		repository := &fakeRepo{}
+		service := NewService(repository, WithValidators(&recordingValidator{name: "hook", calls: &calls, err: &ValidationError{Field: "name", Message: "blocked"}}))
+
+		_, err := service.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "Phone", Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.Equal(t, []string{"hook:update"}, calls)
+		require.False(t, repository.replaceCalled)
+	})
+
+	t.Run("repository errors", func(t *testing.T) {
+		for _, repositoryErr := range []error{ErrorNotFound, ErrorDuplicateName, ErrorDuplicateSlug} {
+			repository := &fakeRepo{replaceErr: fmt.Errorf("wrapped: %w", repositoryErr)}
+			service := NewService(repository)
+
+			_, err := service.Replace(context.Background(), "id-1", ReplaceItemInput{Name: "Phone", Price: "10.00"})
+
+			require.ErrorIs(t, err, repositoryErr)
+		}
+	})
+}
+
+func TestService_Related(t *testing.T) {
+	t.Run("uses the base item and the fuzzy threshold", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Keyboard"}, relatedItems: []Item{{ID: "id-2"}}}
+		service := NewService(repository, WithFuzzyThreshold(0.4))
+
+		related, err := service.Related(context.Background(), "id-1", 5)
+
+		require.NoError(t, err)
+		require.Equal(t, []Item{{ID: "id-2"}}, related)
+		require.Equal(t, "Keyboard", repository.relatedItem.Name)
+		require.Equal(t, 0.4, repository.relatedThreshold)
+		require.Equal(t, 5, repository.relatedLimit)
+	})
+
+	t.Run("missing base item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.Related(context.Background(), "id-1", 5)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Empty(t, repository.relatedItem.ID)
+	})
+}
+
+func TestService_Suggest(t *testing.T) {
+	repository := &fakeRepo{suggestions: []Suggestion{{ID: "id-1", Name: "Phone case"}}}
+	service := NewService(repository, WithFuzzyThreshold(0.4))
+
+	suggestions, err := service.Suggest(context.Background(), "pho", 8)
+
+	require.NoError(t, err)
+	require.Equal(t, []Suggestion{{ID: "id-1", Name: "Phone case"}}, suggestions)
+	require.Equal(t, "pho", repository.suggestPrefix)
+	require.Equal(t, 0.4, repository.suggestThreshold)
+	require.Equal(t, 8, repository.suggestLimit)
+}
+
+func TestService_DidYouMean(t *testing.T) {
+	t.Run("closest name above the threshold", func(t *testing.T) {
+		repository := &fakeRepo{closestName: "Keyboard"}
+		service := NewService(repository, WithDidYouMeanThreshold(0.5))
+
+		suggestion, err := service.DidYouMean(context.Background(), " keybord ")
+
+		require.NoError(t, err)
+		require.Equal(t, "Keyboard", suggestion)
+		require.Equal(t, "keybord", repository.closestQuery)
+		require.Equal(t, 0.5, repository.closestThreshold)
+	})
+
+	t.Run("default threshold", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		suggestion, err := service.DidYouMean(context.Background(), "keybord")
+
+		require.NoError(t, err)
+		require.Empty(t, suggestion)
+		require.Equal(t, DefaultDidYouMeanThreshold, repository.closestThreshold)
+	})
+
+	t.Run("the same query is not a suggestion", func(t *testing.T) {
+		repository := &fakeRepo{closestName: "Keyboard"}
+		service := NewService(repository)
+
+		suggestion, err := service.DidYouMean(context.Background(), "KEYBOARD")
+
+		require.NoError(t, err)
+		require.Empty(t, suggestion)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		repository := &fakeRepo{closestName: "Keyboard"}
+		service := NewService(repository, WithDidYouMeanThreshold(0))
+
+		suggestion, err := service.DidYouMean(context.Background(), "keybord")
+
+		require.NoError(t, err)
+		require.Empty(t, suggestion)
+		require.False(t, repository.closestCalled)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repositoryErr := errors.New("db down")
+		repository := &fakeRepo{closestName: "Keyboard", closestErr: repositoryErr}
+		service := NewService(repository)
+
+		suggestion, err := service.DidYouMean(context.Background(), "keybord")
+
+		require.ErrorIs(t, err, repositoryErr)
+		require.Empty(t, suggestion)
+	})
+}
+
+func TestService_SKU(t *testing.T) {
+	t.Run("create trims and uppercases the sku", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		item, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer(" kb_001.a "), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "KB_001.A", *item.SKU)
+	})
+
+	t.Run("create without sku", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", Price: "10.00"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sku", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("invalid sku", func(t *testing.T) {
+		for _, sku := range []string{"", "KB", "KB 001", "KB/001", "KB#1", strings.Repeat("A", 65)} {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: &sku, Price: "10.00"})
+
+			require.ErrorIs(t, err, ErrorInvalidSKU, sku)
+			require.False(t, repository.insertCalled)
+		}
+	})
+
+	t.Run("duplicate sku", func(t *testing.T) {
+		repository := &fakeRepo{insertErr: ErrorDuplicateSKU}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+	})
+
+	t.Run("update normalizes the sku", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{SKU: stringPointer(" kb-002 ")})
+
+		require.NoError(t, err)
+		require.Equal(t, "KB-002", *repository.updateInput.SKU)
+	})
+
+	t.Run("update with an invalid sku", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{SKU: stringPointer("K")})
+
+		require.ErrorIs(t, err, ErrorInvalidSKU)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("update to a taken sku", func(t *testing.T) {
+		repository := &fakeRepo{updateErr: ErrorDuplicateSKU}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{SKU: stringPointer("KB-001")})
+
+		require.ErrorIs(t, err, ErrorDuplicateSKU)
+	})
+
+	t.Run("list filter is normalized", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.List(context.Background(), 1, 20, ListFilter{SKU: " kb-001"})
+		require.NoError(t, err)
+		require.Equal(t, "KB-001", repository.listFilter.SKU)
+
+		_, err = service.List(context.Background(), 1, 20, ListFilter{SKU: "K"})
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "sku", filterError.Field)
+	})
+
+	t.Run("get by sku", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}}
+		service := NewService(repository)
+
+		item, err := service.GetBySKU(context.Background(), "KB-001")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Equal(t, "KB-001", repository.getSKU)
+	})
+
+	t.Run("get by sku not found maps to domain error", func(t *testing.T) {
+		repository := &fakeRepo{getErr: fmt.Errorf("wrapped: %w", pgx.ErrNoRows)}
+		service := NewService(repository)
+
+		_, err := service.GetBySKU(context.Background(), "KB-404")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestService_Barcode(t *testing.T) {
+	t.Run("create trims the barcode", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		item, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Barcode: stringPointer(" 4006381333931 "), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, "4006381333931", *item.Barcode)
+	})
+
+	t.Run("create with an invalid check digit", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Barcode: stringPointer("4006381333932"), Price: "10.00"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "barcode", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("duplicate barcode", func(t *testing.T) {
+		repository := &fakeRepo{insertErr: ErrorDuplicateBarcode}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Barcode: stringPointer("4006381333931"), Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorDuplicateBarcode)
+	})
+
+	t.Run("update with null clears the barcode", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{BarcodePresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateInput.BarcodePresent)
+		require.Nil(t, repository.updateInput.Barcode)
+	})
+
+	t.Run("update with a malformed barcode", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{BarcodePresent: true, Barcode: stringPointer("12345")})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "barcode", validationError.Field)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("update to a taken barcode", func(t *testing.T) {
+		repository := &fakeRepo{updateErr: ErrorDuplicateBarcode}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{BarcodePresent: true, Barcode: stringPointer("036000291452")})
+
+		require.ErrorIs(t, err, ErrorDuplicateBarcode)
+	})
+
+	t.Run("get by barcode", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}}
+		service := NewService(repository)
+
+		item, err := service.GetByBarcode(context.Background(), "4006381333931")
+
+		require.NoError(t, err)
+		require.Equal(t, "id-1", item.ID)
+		require.Equal(t, "4006381333931", repository.getBarcode)
+	})
+
+	t.Run("get by barcode not found maps to domain error", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.GetByBarcode(context.Background(), "4006381333931")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestService_Category(t *testing.T) {
+	const categoryID = "550e8400-e29b-41d4-a716-446655440000"
+
+	t.Run("create trims the category_id", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), CategoryID: stringPointer(" " + categoryID + " "), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, categoryID, *repository.insertCreatedInput.CategoryID)
+	})
+
+	t.Run("create with a malformed category_id", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), CategoryID: stringPointer("audio"), Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorInvalidCategory)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("create with an unknown category", func(t *testing.T) {
+		repository := &fakeRepo{insertErr: ErrorUnknownCategory}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), CategoryID: stringPointer(categoryID), Price: "10.00"})
+
+		require.ErrorIs(t, err, ErrorUnknownCategory)
+		require.ErrorIs(t, err, ErrorInvalidInput)
+	})
+
+	t.Run("update with null removes the category", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{CategoryIDPresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateInput.CategoryIDPresent)
+		require.Nil(t, repository.updateInput.CategoryID)
+	})
+
+	t.Run("list filter must be a uuid", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.List(context.Background(), 1, 20, ListFilter{CategoryID: "audio"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "category_id", filterError.Field)
+		require.False(t, repository.listCalled)
+	})
+
+	t.Run("duplicate keeps the category", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Keyboard", Price: "10.00", Category: &ItemCategory{ID: categoryID, Name: "Audio"}}}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "id-1", DuplicateItemInput{SKU: "KB-002"})
+
+		require.NoError(t, err)
+		require.Equal(t, categoryID, *repository.insertCreatedInput.CategoryID)
+	})
+}
+
+func TestService_Variants(t *testing.T) {
+	t.Run("create syncs the item stock with the variants", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "15.00", Currency: "USD", Stock: 10}, updateItem: Item{ID: "id-1", Stock: 3}, variantCount: 1, variantTotal: 3}
+		service := NewService(repository)
+
+		variant, err := service.CreateVariant(context.Background(), "id-1", CreateVariantInput{SKU: " ts-m ", Stock: 3, Attributes: map[string]string{"size": "M"}})
+
+		require.NoError(t, err)
+		require.Equal(t, "TS-M", variant.SKU)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, 3, *repository.updateInput.Stock)
+		require.Equal(t, []StockMovement{{ItemID: "id-1", Delta: -7, ResultingStock: 3, Reason: StockReasonVariants}}, repository.movements)
+	})
+
+	t.Run("create on a missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: ErrorNotFound}
+		service := NewService(repository)
+
+		_, err := service.CreateVariant(context.Background(), "id-1", CreateVariantInput{SKU: "TS-M"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Empty(t, repository.insertVariantInput.SKU)
+	})
+
+	t.Run("create does not touch the item when the sum does not change", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 0}, variantCount: 1, variantTotal: 0}
+		service := NewService(repository)
+
+		_, err := service.CreateVariant(context.Background(), "id-1", CreateVariantInput{SKU: "TS-M"})
+
+		require.NoError(t, err)
+		require.False(t, repository.updateCalled)
+		require.Empty(t, repository.movements)
+	})
+
+	t.Run("create validates the variant", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input CreateVariantInput
+			want  error
+		}{
+			{"sku", CreateVariantInput{SKU: "x"}, ErrorInvalidSKU},
+			{"price", CreateVariantInput{SKU: "TS-M", Price: stringPointer("0")}, ErrorInvalidPrice},
+			{"stock", CreateVariantInput{SKU: "TS-M", Stock: -1}, ErrorInvalidStock},
+			{"attributes", CreateVariantInput{SKU: "TS-M", Attributes: map[string]string{"": "M"}}, ErrorInvalidInput},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+
+				_, err := service.CreateVariant(context.Background(), "id-1", tt.input)
+
+				require.ErrorIs(t, err, tt.want)
+				require.False(t, repository.inTxCalled)
+			})
+		}
+	})
+
+	t.Run("price precision follows the item currency", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "1500.00", Currency: "JPY"}}
+		service := NewService(repository)
+
+		_, err := service.CreateVariant(context.Background(), "id-1", CreateVariantInput{SKU: "TS-M", Price: stringPointer("1500.50")})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.Empty(t, repository.insertVariantInput.SKU)
+	})
+
+	t.Run("update without fields", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.UpdateVariant(context.Background(), "id-1", "variant-1", UpdateVariantInput{})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+	})
+
+	t.Run("update with a null price inherits", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 4}, variantCount: 2, variantTotal: 4}
+		service := NewService(repository)
+
+		_, err := service.UpdateVariant(context.Background(), "id-1", "variant-1", UpdateVariantInput{PricePresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateVariantInput.PricePresent)
+		require.Nil(t, repository.updateVariantInput.Price)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("deleting the last variant leaves the item without stock", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 4}}
+		service := NewService(repository)
+
+		err := service.DeleteVariant(context.Background(), "id-1", "variant-1")
+
+		require.NoError(t, err)
+		require.Equal(t, "variant-1", repository.deletedVariantID)
+		require.Equal(t, 0, *repository.updateInput.Stock)
+	})
+
+	t.Run("delete of a missing variant", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 4}, deleteVariantErr: ErrorVariantNotFound}
+		service := NewService(repository)
+
+		err := service.DeleteVariant(context.Background(), "id-1", "variant-1")
+
+		require.ErrorIs(t, err, ErrorVariantNotFound)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("list on a missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.Variants(context.Background(), "id-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("stock of an item with variants cannot change directly", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 4}, updateItem: Item{ID: "id-1", Stock: 9}, variantCount: 2, variantTotal: 4}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(9)})
+		require.ErrorIs(t, err, ErrorStockManagedByVariants)
+		require.Empty(t, repository.movements)
+
+		_, err = service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{Delta: 2})
+		require.ErrorIs(t, err, ErrorStockManagedByVariants)
+	})
+
+	t.Run("other fields of an item with variants can change", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 4}, updateItem: Item{ID: "id-1", Stock: 4}, variantCount: 2, variantTotal: 4}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Name: stringPointer("T-Shirt"), Stock: integerPointer(4)})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestService_Attributes(t *testing.T) {
+	t.Run("create keeps the attributes", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		attributes := map[string]any{"color": "red", "screen_size": 6.1, "waterproof": true}
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("PH-001"), Price: "10.00", Attributes: attributes})
+
+		require.NoError(t, err)
+		require.Equal(t, attributes, repository.insertCreatedInput.Attributes)
+	})
+
+	t.Run("create rejects nested values", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("PH-001"), Price: "10.00", Attributes: map[string]any{"size": []any{1, 2}}})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "attributes", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch with null attributes is a change", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{AttributesPresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateCalled)
+		require.True(t, repository.updateInput.AttributesPresent)
+		require.Nil(t, repository.updateInput.Attributes)
+	})
+
+	t.Run("patch validates the limits", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Attributes: map[string]any{strings.Repeat("k", 65): "x"}, AttributesPresent: true})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("list filter rejects an empty key", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{Attributes: map[string]string{"": "red"}})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "attr.", filterError.Field)
+	})
+}
+
+func TestService_Dimensions(t *testing.T) {
+	t.Run("create keeps the weight and dimensions", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{
+			Name: "Box", SKU: stringPointer("BX-001"), Price: "10.00",
+			WeightGrams: integerPointer(1500), WidthMM: integerPointer(300), HeightMM: integerPointer(200), DepthMM: integerPointer(100),
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1500, *repository.insertCreatedInput.WeightGrams)
+		require.Equal(t, 100, *repository.insertCreatedInput.DepthMM)
+	})
+
+	t.Run("create rejects values out of bounds", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Box", SKU: stringPointer("BX-001"), Price: "10.00", WeightGrams: integerPointer(1_000_001)})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "weight_grams", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch with a null dimension is a change", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{WidthMMPresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateInput.WidthMMPresent)
+		require.Nil(t, repository.updateInput.WidthMM)
+	})
+
+	t.Run("patch rejects a negative dimension", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{HeightMM: integerPointer(-1), HeightMMPresent: true})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("list filter rejects a negative max weight", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{MaxWeight: integerPointer(-1)})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "max_weight", filterError.Field)
+	})
+}
+
+func TestService_TaxRate(t *testing.T) {
+	t.Run("create without a rate uses the default", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithDefaultTaxRate(2100))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, 2100, *repository.insertCreatedInput.TaxRateBPS)
+	})
+
+	t.Run("create keeps an explicit zero rate", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithDefaultTaxRate(2100))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-001"), Price: "10.00", TaxRateBPS: integerPointer(0)})
+
+		require.NoError(t, err)
+		require.Equal(t, 0, *repository.insertCreatedInput.TaxRateBPS)
+	})
+
+	t.Run("create rejects a rate out of range", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-001"), Price: "10.00", TaxRateBPS: integerPointer(10001)})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "tax_rate_bps", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch with only the rate is a change", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{TaxRateBPS: integerPointer(1900)})
+
+		require.NoError(t, err)
+		require.Equal(t, 1900, *repository.updateInput.TaxRateBPS)
+	})
+
+	t.Run("patch rejects a negative rate", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{TaxRateBPS: integerPointer(-1)})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+}
+
+func TestService_MinOrderQty(t *testing.T) {
+	t.Run("create defaults to one", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Screws", SKU: stringPointer("SC-001"), Price: "1.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, *repository.insertCreatedInput.MinOrderQty)
+	})
+
+	t.Run("create rejects zero", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Screws", SKU: stringPointer("SC-001"), Price: "1.00", MinOrderQty: integerPointer(0)})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "min_order_qty", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch with only the minimum is a change", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{MinOrderQty: integerPointer(12)})
+
+		require.NoError(t, err)
+		require.Equal(t, 12, *repository.updateInput.MinOrderQty)
+	})
+
+	t.Run("patch rejects a negative minimum", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{MinOrderQty: integerPointer(-3)})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("list filter must be at least one", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{MinOrderQtyLTE: integerPointer(0)})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "min_order_qty_lte", filterError.Field)
+	})
+}
+
+func TestService_ReorderPoint(t *testing.T) {
+	t.Run("create keeps it optional", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Screws", SKU: stringPointer("SC-001"), Price: "1.00", ReorderPoint: integerPointer(0)})
+
+		require.NoError(t, err)
+		require.Equal(t, 0, *repository.insertCreatedInput.ReorderPoint)
+	})
+
+	t.Run("create rejects a negative reorder point", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Screws", SKU: stringPointer("SC-001"), Price: "1.00", ReorderPoint: integerPointer(-1)})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "reorder_point", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch clearing it is a change", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{ReorderPointPresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateInput.ReorderPointPresent)
+		require.Nil(t, repository.updateInput.ReorderPoint)
+	})
+
+	t.Run("restock report suggests twice the reorder point minus the stock", func(t *testing.T) {
+		repository := &fakeRepo{
+			restockItems: []Item{
+				{ID: "id-1", Stock: 2, ReorderPoint: integerPointer(10)},
+				{ID: "id-2", Stock: -4, ReorderPoint: integerPointer(0)},
+				{ID: "id-3", Stock: 0, ReorderPoint: integerPointer(0)},
+			},
+			restockTotal: 7,
+		}
+		service := NewService(repository)
+
+		page, err := service.RestockNeeded(context.Background(), 2, 3)
+
+		require.NoError(t, err)
+		require.Equal(t, 3, repository.restockLimit)
+		require.Equal(t, 3, repository.restockOffset)
+		require.Equal(t, 7, page.Total)
+		require.Equal(t, 18, *page.Items[0].SuggestedOrderQty)
+		require.Equal(t, 4, *page.Items[1].SuggestedOrderQty)
+		require.Equal(t, 1, *page.Items[2].SuggestedOrderQty, "floor of one unit")
+	})
+}
+
+func TestService_Changes(t *testing.T) {
+	now := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)
+	first, second := now.Add(-2*time.Hour), now.Add(-time.Hour)
+	deletedAt := second
+
+	t.Run("marks deleted items and moves the watermark to the last one", func(t *testing.T) {
+		repository := &fakeRepo{changesItems: []Item{
+			{ID: "id-1", UpdatedAt: first},
+			{ID: "id-2", UpdatedAt: second, DeletedAt: &deletedAt},
+			{ID: "id-3", UpdatedAt: second},
+		}}
+		service := NewService(repository)
+		service.now = func() time.Time { return now }
+		after := UpdatedAtID{UpdatedAt: first.Add(-time.Minute), ID: "id-0"}
+
+		page, err := service.Changes(context.Background(), after, 2)
+
+		require.NoError(t, err)
+		require.Equal(t, after, repository.changesAfter)
+		require.Equal(t, now.Add(-changesSettleWindow), repository.changesUntil)
+		require.Equal(t, 3, repository.changesLimit, "one extra to know if there is more")
+		require.Len(t, page.Items, 2)
+		require.True(t, page.HasMore)
+		require.False(t, page.Items[0].Deleted)
+		require.True(t, page.Items[1].Deleted)
+		require.Equal(t, UpdatedAtID{UpdatedAt: second, ID: "id-2"}, page.Next)
+	})
+
+	t.Run("an empty batch keeps the watermark and a bare timestamp starts inclusive", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		page, err := service.Changes(context.Background(), UpdatedAtID{UpdatedAt: first}, 10)
+
+		require.NoError(t, err)
+		require.Equal(t, UpdatedAtID{UpdatedAt: first, ID: "00000000-0000-0000-0000-000000000000"}, repository.changesAfter)
+		require.Empty(t, page.Items)
+		require.False(t, page.HasMore)
+		require.Equal(t, repository.changesAfter, page.Next)
+	})
+
+	t.Run("rejects a non-positive limit", func(t *testing.T) {
+		_, err := NewService(&fakeRepo{}).Changes(context.Background(), UpdatedAtID{}, 0)
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+	})
+}
+
+func TestService_ExpiresAt(t *testing.T) {
+	today := func() time.Time { return time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC) }
+
+	t.Run("create keeps a future date", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		service.now = today
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Milk", SKU: stringPointer("MK-001"), Price: "1.50", ExpiresAt: stringPointer("2025-03-20")})
+
+		require.NoError(t, err)
+		require.Equal(t, "2025-03-20", *repository.insertCreatedInput.ExpiresAt)
+	})
+
+	t.Run("create rejects a date that is not in the future", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		service.now = today
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Milk", SKU: stringPointer("MK-001"), Price: "1.50", ExpiresAt: stringPointer("2025-03-10")})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "expires_at", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch accepts a past date and null clears it", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		service.now = today
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{ExpiresAt: stringPointer("2025-01-01"), ExpiresAtPresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "2025-01-01", *repository.updateInput.ExpiresAt)
+
+		_, err = service.Update(context.Background(), "id-1", UpdateItemInput{ExpiresAtPresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateInput.ExpiresAtPresent)
+		require.Nil(t, repository.updateInput.ExpiresAt)
+	})
+
+	t.Run("patch rejects an invalid date", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{ExpiresAt: stringPointer("31/12/2025"), ExpiresAtPresent: true})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("list filter rejects an invalid date", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{ExpiringBefore: "2025-13-01"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "expiring_before", filterError.Field)
+	})
+}
+
+func TestService_PriceSchedules(t *testing.T) {
+	now := func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+	future := time.Date(2025, 7, 1, 0, 0, 0, 0, time.FixedZone("ART", -3*60*60))
+
+	t.Run("create trims the price and stores the instant in utc", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Currency: "USD"}}
+		service := NewService(repository, WithEventPublisher(events))
+		service.now = now
+
+		schedule, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: " 19.99 ", EffectiveAt: future})
+
+		require.NoError(t, err)
+		require.Equal(t, "19.99", repository.insertScheduleInput.NewPrice)
+		require.Equal(t, time.UTC, repository.insertScheduleInput.EffectiveAt.Location())
+		require.True(t, future.Equal(schedule.EffectiveAt))
+		require.True(t, repository.inTxCalled)
+		require.Len(t, repository.notified, 1, "next_price_change changed")
+		require.Equal(t, EventUpdated, repository.notified[0].Operation)
+		require.Len(t, events.published, 1)
+	})
+
+	t.Run("create rejects a price at or below the sale price", func(t *testing.T) {
+		for _, newPrice := range []string{"8.00", "7.50"} {
+			t.Run(newPrice, func(t *testing.T) {
+				salePrice := "8.00"
+				events := &recordingEvents{}
+				repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00", SalePrice: &salePrice, Currency: "USD"}}
+				service := NewService(repository, WithEventPublisher(events))
+				service.now = now
+
+				_, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: newPrice, EffectiveAt: future})
+
+				require.ErrorIs(t, err, ErrorScheduledBelowSalePrice)
+				require.Empty(t, repository.insertScheduleInput.NewPrice)
+				require.Empty(t, repository.notified)
+				require.Empty(t, events.published)
+			})
+		}
+	})
+
+	t.Run("create above the sale price", func(t *testing.T) {
+		salePrice := "8.00"
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00", SalePrice: &salePrice, Currency: "USD"}}
+		service := NewService(repository)
+		service.now = now
+
+		_, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "8.01", EffectiveAt: future})
+
+		require.NoError(t, err)
+		require.Equal(t, "8.01", repository.insertScheduleInput.NewPrice)
+	})
+
+	t.Run("create validates the input", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input CreatePriceScheduleInput
+			field string
+		}{
+			{"price", CreatePriceScheduleInput{NewPrice: "0", EffectiveAt: future}, "new_price"},
+			{"missing instant", CreatePriceScheduleInput{NewPrice: "19.99"}, "effective_at"},
+			{"past instant", CreatePriceScheduleInput{NewPrice: "19.99", EffectiveAt: now().Add(-time.Minute)}, "effective_at"},
+			{"present instant", CreatePriceScheduleInput{NewPrice: "19.99", EffectiveAt: now()}, "effective_at"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{}
+				service := NewService(repository)
+				service.now = now
+
+				_, err := service.CreatePriceSchedule(context.Background(), "id-1", tt.input)
+
+				var validationError *ValidationError
+				require.ErrorAs(t, err, &validationError)
+				require.Equal(t, tt.field, validationError.Field)
+				require.False(t, repository.inTxCalled)
+			})
+		}
+	})
+
+	t.Run("create checks the currency decimals", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Currency: "JPY"}}
+		service := NewService(repository)
+		service.now = now
+
+		_, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "1500.50", EffectiveAt: future})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "new_price", validationError.Field)
+		require.Empty(t, repository.insertScheduleInput.NewPrice)
+	})
+
+	t.Run("create of a missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+		service.now = now
+
+		_, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "19.99", EffectiveAt: future})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("create rejects another pending change at the same instant", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Currency: "USD"}, insertScheduleErr: ErrorDuplicatePriceSchedule}
+		service := NewService(repository)
+		service.now = now
+
+		_, err := service.CreatePriceSchedule(context.Background(), "id-1", CreatePriceScheduleInput{NewPrice: "19.99", EffectiveAt: future})
+
+		require.ErrorIs(t, err, ErrorDuplicatePriceSchedule)
+	})
+
+	t.Run("delete notifies and publishes the item", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Version: 4}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		require.NoError(t, service.DeletePriceSchedule(context.Background(), "id-1", "schedule-1"))
+		require.Equal(t, "schedule-1", repository.deletedScheduleID)
+		require.Len(t, repository.notified, 1)
+		require.Len(t, events.published, 1)
+		require.Equal(t, 4, events.published[0].Item.Version)
+	})
+
+	t.Run("delete of a schedule that is not pending emits nothing", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}, deleteScheduleErr: ErrorPriceScheduleNotFound}
+		service := NewService(repository, WithEventPublisher(events))
+
+		require.ErrorIs(t, service.DeletePriceSchedule(context.Background(), "id-1", "schedule-1"), ErrorPriceScheduleNotFound)
+		require.Empty(t, repository.notified)
+		require.Empty(t, events.published)
+	})
+
+	t.Run("list of a missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.PriceSchedules(context.Background(), "id-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.True(t, repository.inSnapshotCalled)
+	})
+
+	t.Run("apply updates the price of each due schedule", func(t *testing.T) {
+		repository := &fakeRepo{
+			getItem: Item{ID: "id-1", Price: "10.00", Currency: "USD"},
+			dueSchedules: []PriceSchedule{
+				{ID: "schedule-1", ItemID: "id-1", NewPrice: "12.00"},
+				{ID: "schedule-2", ItemID: "id-1", NewPrice: "15.00"},
+			},
+		}
+		service := NewService(repository)
+
+		applied, err := service.ApplyDuePriceSchedules(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, 2, applied)
+		require.Equal(t, maxDuePriceSchedules, repository.dueLimit)
+		require.Equal(t, []string{"schedule-1", "schedule-2"}, repository.appliedScheduleIDs)
+		require.Equal(t, "15.00", *repository.updateInput.Price)
+	})
+
+	t.Run("apply skips schedules applied by another instance", func(t *testing.T) {
+		repository := &fakeRepo{
+			getItem:             Item{ID: "id-1", Price: "10.00", Currency: "USD"},
+			dueSchedules:        []PriceSchedule{{ID: "schedule-1", ItemID: "id-1", NewPrice: "12.00"}},
+			markScheduleErrByID: map[string]error{"schedule-1": ErrorPriceScheduleNotFound},
+		}
+		service := NewService(repository)
+
+		applied, err := service.ApplyDuePriceSchedules(context.Background())
+
+		require.NoError(t, err)
+		require.Zero(t, applied)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("apply consumes the schedule of a deleted item", func(t *testing.T) {
+		repository := &fakeRepo{
+			getErr:       ErrorNotFound,
+			dueSchedules: []PriceSchedule{{ID: "schedule-1", ItemID: "id-1", NewPrice: "12.00"}},
+		}
+		service := NewService(repository)
+
+		applied, err := service.ApplyDuePriceSchedules(context.Background())
+
+		require.NoError(t, err)
+		require.Zero(t, applied)
+		require.Equal(t, []string{"schedule-1"}, repository.appliedScheduleIDs)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("apply keeps going after a failure", func(t *testing.T) {
+		repository := &fakeRepo{
+			getItem: Item{ID: "id-1", Price: "10.00", Currency: "USD"},
+			dueSchedules: []PriceSchedule{
+				{ID: "schedule-1", ItemID: "id-1", NewPrice: "12.00"},
+				{ID: "schedule-2", ItemID: "id-1", NewPrice: "15.00"},
+			},
+			markScheduleErrByID: map[string]error{"schedule-1": errors.New("boom")},
+		}
+		service := NewService(repository)
+
+		applied, err := service.ApplyDuePriceSchedules(context.Background())
+
+		require.ErrorContains(t, err, "price schedule schedule-1: boom")
+		require.Equal(t, 1, applied)
+		require.Equal(t, []string{"schedule-2"}, repository.appliedScheduleIDs)
+	})
+
+	t.Run("apply marks failed the schedules that are no longer valid", func(t *testing.T) {
+		salePrice := "12.00"
+		tests := []struct {
+			name     string
+			item     Item
+			newPrice string
+			reason   string
+		}{
+			{"at the sale price", Item{ID: "id-1", Price: "15.00", SalePrice: &salePrice, Currency: "USD"}, "12.00", PriceScheduleFailureBelowSalePrice},
+			{"cents in a currency without decimals", Item{ID: "id-1", Price: "1500.00", Currency: "JPY"}, "1200.50", PriceScheduleFailureCurrencyPrecision},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				events := &recordingEvents{}
+				repository := &fakeRepo{
+					getItem:      tt.item,
+					dueSchedules: []PriceSchedule{{ID: "schedule-1", ItemID: "id-1", NewPrice: tt.newPrice}},
+				}
+				service := NewService(repository, WithEventPublisher(events))
+
+				applied, err := service.ApplyDuePriceSchedules(context.Background())
+
+				require.NoError(t, err, "a schedule that can no longer apply leaves the queue instead of failing every run")
+				require.Zero(t, applied)
+				require.False(t, repository.updateCalled)
+				require.Equal(t, map[string]string{"schedule-1": tt.reason}, repository.failedSchedules)
+				require.Len(t, repository.notified, 1, "next_price_change changed")
+				require.Len(t, events.published, 1)
+			})
+		}
+	})
+}
+
+func TestService_ExternalRefs(t *testing.T) {
+	t.Run("add normalizes the system and inserts the ref", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		ref, created, err := service.AddExternalRef(context.Background(), "id-1", ExternalRefInput{System: " ERP ", ExternalID: " A-100 "})
+
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, ExternalRefInput{System: "erp", ExternalID: "A-100"}, repository.insertRefInput)
+		require.Equal(t, "erp", ref.System)
+		require.True(t, repository.inTxCalled)
+		require.Len(t, repository.notified, 1, "the refs are part of the item")
+		require.Equal(t, EventUpdated, repository.notified[0].Operation)
+		require.Len(t, events.published, 1)
+	})
+
+	t.Run("adding a ref the item already has returns it", func(t *testing.T) {
+		existing := ExternalRef{System: "erp", ExternalID: "A-100", CreatedAt: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)}
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Refs: []ExternalRef{existing}}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		ref, created, err := service.AddExternalRef(context.Background(), "id-1", ExternalRefInput{System: "erp", ExternalID: "A-100"})
+
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, existing, ref)
+		require.Empty(t, repository.insertRefInput.System)
+		require.Empty(t, repository.notified, "nothing changed")
+		require.Empty(t, events.published)
+	})
+
+	t.Run("a ref of another item is a conflict naming the system", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}, insertRefErr: ErrorDuplicateExternalRef}
+		service := NewService(repository)
+
+		_, _, err := service.AddExternalRef(context.Background(), "id-1", ExternalRefInput{System: "marketplace", ExternalID: "MLA-1"})
+
+		var conflictError *ExternalRefConflictError
+		require.ErrorAs(t, err, &conflictError)
+		require.Equal(t, "marketplace", conflictError.System)
+		require.ErrorIs(t, err, ErrorDuplicateExternalRef)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := NewService(&fakeRepo{getErr: pgx.ErrNoRows})
+
+		_, _, err := service.AddExternalRef(context.Background(), "missing", ExternalRefInput{System: "erp", ExternalID: "A-100"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	validation := []struct {
+		name  string
+		input ExternalRefInput
+		field string
+	}{
+		{"empty system", ExternalRefInput{ExternalID: "A-100"}, "system"},
+		{"system with spaces", ExternalRefInput{System: "my erp", ExternalID: "A-100"}, "system"},
+		{"empty external id", ExternalRefInput{System: "erp", ExternalID: "  "}, "external_id"},
+		{"external id too long", ExternalRefInput{System: "erp", ExternalID: strings.Repeat("x", maxExternalIDLength+1)}, "external_id"},
+	}
+	for _, tt := range validation {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, _, err := service.AddExternalRef(context.Background(), "id-1", tt.input)
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, tt.field, validationError.Field)
+			require.False(t, repository.inTxCalled)
+		})
+	}
+
+	t.Run("remove normalizes the system", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{deleteRefErr: ErrorExternalRefNotFound}
+		service := NewService(repository, WithEventPublisher(events))
+
+		err := service.RemoveExternalRef(context.Background(), "id-1", "ERP", "A-100")
+
+		require.ErrorIs(t, err, ErrorExternalRefNotFound)
+		require.Equal(t, [2]string{"erp", "A-100"}, repository.deletedRef)
+		require.Empty(t, repository.notified)
+		require.Empty(t, events.published)
+	})
+
+	t.Run("remove notifies and publishes the item", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Version: 7}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		require.NoError(t, service.RemoveExternalRef(context.Background(), "id-1", "erp", "A-100"))
+		require.True(t, repository.inTxCalled)
+		require.Len(t, repository.notified, 1)
+		require.Len(t, events.published, 1)
+		require.Equal(t, 7, events.published[0].Item.Version)
+	})
+
+	t.Run("lookup maps no rows to not found", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.GetByExternalRef(context.Background(), "Marketplace", "MLA-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Equal(t, [2]string{"marketplace", "MLA-1"}, repository.refLookup)
+	})
+}
+
+func TestService_Translations(t *testing.T) {
+	t.Run("put normalizes the locale and trims the name", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}}
+		service := NewService(repository)
+
+		translation, err := service.PutTranslation(context.Background(), "id-1", " ES ", TranslationInput{Name: " Teclado ", Description: stringPointer(" Mecánico ")})
+
+		require.NoError(t, err)
+		require.Equal(t, "es", translation.Locale)
+		require.Equal(t, "es", repository.upsertLocale)
+		require.Equal(t, "Teclado", repository.upsertTranslation.Name)
+		require.Equal(t, "Mecánico", *repository.upsertTranslation.Description)
+		require.True(t, repository.inTxCalled)
+	})
+
+	t.Run("put notifies and publishes the item", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Version: 2}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.PutTranslation(context.Background(), "id-1", "es", TranslationInput{Name: "Teclado"})
+
+		require.NoError(t, err)
+		require.Len(t, repository.notified, 1, "the translated representation changed")
+		require.Equal(t, EventUpdated, repository.notified[0].Operation)
+		require.Len(t, events.published, 1)
+		require.Equal(t, 2, events.published[0].Item.Version)
+	})
+
+	rejected := []struct {
+		name      string
+		locale    string
+		input     TranslationInput
+		want      error
+		wantField string
+	}{
+		{"base locale", "en", TranslationInput{Name: "Keyboard"}, ErrorUnsupportedLocale, ""},
+		{"locale outside the allowlist", "fr", TranslationInput{Name: "Clavier"}, ErrorUnsupportedLocale, ""},
+		{"blank name", "es", TranslationInput{Name: "  "}, ErrorInvalidName, ""},
+		{"name too long", "es", TranslationInput{Name: strings.Repeat("a", maxNameLength+1)}, ErrorInvalidInput, "name"},
+		{"description too long", "es", TranslationInput{Name: "Teclado", Description: stringPointer(strings.Repeat("a", maxDescriptionLength+1))}, ErrorInvalidInput, "description"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.PutTranslation(context.Background(), "id-1", tt.locale, tt.input)
+
+			require.ErrorIs(t, err, tt.want)
+			if tt.wantField != "" {
+				var validationError *ValidationError
+				require.ErrorAs(t, err, &validationError)
+				require.Equal(t, tt.wantField, validationError.Field)
+			}
+			require.False(t, repository.inTxCalled)
+		})
+	}
+
+	t.Run("configured locales", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}}
+		service := NewService(repository, WithLocales("es", "en"))
+
+		_, err := service.PutTranslation(context.Background(), "id-1", "en", TranslationInput{Name: "Keyboard"})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"es", "en"}, service.Locales())
+	})
+
+	t.Run("put on a missing item", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.PutTranslation(context.Background(), "missing", "es", TranslationInput{Name: "Teclado"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Empty(t, repository.notified)
+		require.Empty(t, events.published)
+	})
+
+	t.Run("list on a missing item", func(t *testing.T) {
+		service := NewService(&fakeRepo{getErr: pgx.ErrNoRows})
+
+		_, err := service.Translations(context.Background(), "missing")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	t.Run("localize overlays the translations and falls back to the base fields", func(t *testing.T) {
+		description := "Mecánico"
+		repository := &fakeRepo{translationsByItem: map[string]Translation{
+			"id-1": {Locale: "es", Name: "Teclado", Description: &description},
+			"id-2": {Locale: "es", Name: "Ratón"},
+		}}
+		service := NewService(repository)
+		base := "Wireless"
+		list := []Item{
+			{ID: "id-1", Name: "Keyboard"},
+			{ID: "id-2", Name: "Mouse", Description: &base},
+			{ID: "id-3", Name: "Monitor"},
+		}
+
+		localized, err := service.Localize(context.Background(), "es", list)
+
+		require.NoError(t, err)
+		require.Equal(t, "es", repository.translationLookup)
+		require.Equal(t, Item{ID: "id-1", Name: "Teclado", Description: &description, Locale: "es"}, localized[0])
+		require.Equal(t, Item{ID: "id-2", Name: "Ratón", Description: &base, Locale: "es"}, localized[1])
+		require.Equal(t, Item{ID: "id-3", Name: "Monitor", Locale: "en"}, localized[2])
+	})
+
+	t.Run("localize to the base locale only marks the items", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		localized, err := service.Localize(context.Background(), "en", []Item{{ID: "id-1", Name: "Keyboard"}})
+
+		require.NoError(t, err)
+		require.Equal(t, "en", localized[0].Locale)
+		require.Empty(t, repository.translationLookup)
+	})
+
+	t.Run("localize without a locale leaves the items untouched", func(t *testing.T) {
+		service := NewService(&fakeRepo{})
+
+		localized, err := service.Localize(context.Background(), "", []Item{{ID: "id-1", Name: "Keyboard"}})
+
+		require.NoError(t, err)
+		require.Empty(t, localized[0].Locale)
+	})
+}
+
+func TestService_SalePrice(t *testing.T) {
+	t.Run("create keeps a sale price below the price", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-001"), Price: "10.00", SalePrice: stringPointer(" 7.99 ")})
+
+		require.NoError(t, err)
+		require.Equal(t, "7.99", *repository.insertCreatedInput.SalePrice)
+	})
+
+	t.Run("create rejects invalid sale prices", func(t *testing.T) {
+		for _, salePrice := range []string{"10.00", "12.50", "0", "abc", "7.999"} {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-001"), Price: "10.00", SalePrice: &salePrice})
+
+			require.ErrorIs(t, err, ErrorInvalidSalePrice, salePrice)
+			require.False(t, repository.insertCalled)
+		}
+	})
+
+	t.Run("create checks the sale price precision", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-001"), Price: "1000", SalePrice: stringPointer("899.50"), Currency: "JPY"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sale_price", validationError.Field)
+	})
+
+	t.Run("patch compares with the price of the same patch", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Price: stringPointer("8.00"), SalePrice: stringPointer("8.00"), SalePricePresent: true})
+
+		require.ErrorIs(t, err, ErrorInvalidSalePrice)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("patch with only a sale price leaves the comparison to the database", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{SalePrice: stringPointer("8.00"), SalePricePresent: true})
+
+		require.NoError(t, err)
+		require.Equal(t, "8.00", *repository.updateInput.SalePrice)
+	})
+
+	t.Run("patch with a null sale price ends the sale", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{SalePricePresent: true})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateInput.SalePricePresent)
+		require.Nil(t, repository.updateInput.SalePrice)
+	})
+
+	t.Run("patch checks the sale price precision with the item currency", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "1000.00", Currency: "JPY"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{SalePrice: stringPointer("899.50"), SalePricePresent: true})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sale_price", validationError.Field)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("changing the currency checks the current sale price", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "1000.00", SalePrice: stringPointer("899.50"), Currency: "USD"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Currency: stringPointer("JPY"), AllowCurrencyChange: true})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sale_price", validationError.Field)
+	})
+}
+
+func TestService_Currency(t *testing.T) {
+	t.Run("create uses the default currency", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithDefaultCurrency("eur"))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Price: "10.50"})
+
+		require.NoError(t, err)
+		require.Equal(t, "EUR", repository.insertCreatedInput.Currency)
+	})
+
+	t.Run("create normalizes the currency", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Price: "1500", Currency: " jpy "})
+
+		require.NoError(t, err)
+		require.Equal(t, "JPY", repository.insertCreatedInput.Currency)
+	})
+
+	t.Run("create rejects an unknown currency", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Price: "10.00", Currency: "ABC"})
+
+		require.ErrorIs(t, err, ErrorInvalidCurrency)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("zero-decimal currencies reject cents", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), Price: "100.50", Currency: "JPY"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "price", validationError.Field)
+		require.Equal(t, "price must be a whole amount in JPY", validationError.Message)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch cannot change the currency without the flag", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00", Currency: "USD"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Currency: stringPointer("eur")})
+
+		require.ErrorIs(t, err, ErrorCurrencyChange)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("patch can send the same currency", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00", Currency: "USD"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Currency: stringPointer("usd")})
+
+		require.NoError(t, err)
+		require.Equal(t, "USD", *repository.updateInput.Currency)
+	})
+
+	t.Run("changing to a zero-decimal currency checks the current price", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.50", Currency: "USD"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Currency: stringPointer("JPY"), AllowCurrencyChange: true})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+
+		_, err = service.Update(context.Background(), "id-1", UpdateItemInput{Currency: stringPointer("JPY"), Price: stringPointer("1500"), AllowCurrencyChange: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("patch price with cents on a zero-decimal item", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "1500.00", Currency: "JPY"}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Price: stringPointer("1500.50")})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.True(t, repository.getForUpdateCalled)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("list filter rejects an unknown currency", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{Currency: "ABC"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "currency", filterError.Field)
+	})
+}
+
+func TestService_Status(t *testing.T) {
+	t.Run("update normalizes a legal status", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		status := ItemStatus(" Inactive ")
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Status: &status})
+
+		require.NoError(t, err)
+		require.Equal(t, StatusInactive, *repository.updateInput.Status)
+	})
+
+	for _, value := range []ItemStatus{"", "all", "archived", "deleted", "act ive"} {
+		t.Run("update rejects "+string(value), func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+			status := value
+
+			_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Status: &status})
+
+			require.ErrorIs(t, err, ErrorInvalidStatus)
+			require.ErrorIs(t, err, ErrorInvalidInput)
+			require.False(t, repository.updateCalled)
+		})
+	}
+
+	t.Run("list filter accepts all", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{Status: StatusAll})
+
+		require.NoError(t, err)
+		require.Equal(t, StatusAll, repository.countFilter.Status)
+	})
+
+	t.Run("list filter rejects an unknown status", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{Status: "archived"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "status", filterError.Field)
+		require.False(t, repository.countCalled)
+	})
+}
+
+func TestService_Brand(t *testing.T) {
+	const brandID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	t.Run("create with a malformed brand_id", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Keyboard", SKU: stringPointer("KB-001"), BrandID: stringPointer("acme"), Price: "10.00"})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "brand_id", validationError.Field)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("update with an unknown brand", func(t *testing.T) {
+		repository := &fakeRepo{updateErr: ErrorUnknownBrand}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{BrandIDPresent: true, BrandID: stringPointer(" " + brandID)})
+
+		require.ErrorIs(t, err, ErrorUnknownBrand)
+		require.Equal(t, brandID, *repository.updateInput.BrandID)
+	})
+
+	t.Run("list filter must be a uuid", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{BrandID: "acme"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "brand_id", filterError.Field)
+		require.False(t, repository.countCalled)
+	})
+
+	t.Run("duplicate keeps the brand", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Keyboard", Price: "10.00", Brand: &ItemBrand{ID: brandID, Name: "Acme"}}}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "id-1", DuplicateItemInput{SKU: "KB-002"})
+
+		require.NoError(t, err)
+		require.Equal(t, brandID, *repository.insertCreatedInput.BrandID)
+	})
+}
+
+func TestService_Count(t *testing.T) {
+	t.Run("counts without listing", func(t *testing.T) {
+		repository := &fakeRepo{countTotal: 7}
+		service := NewService(repository)
+
+		count, err := service.Count(context.Background(), ListFilter{Query: " phone "})
+
+		require.NoError(t, err)
+		require.Equal(t, ItemCount{Total: 7}, count)
+		require.True(t, repository.countCalled)
+		require.Equal(t, "phone", repository.countFilter.Query)
+		require.Equal(t, MatchContains, repository.countFilter.Match)
+		require.False(t, repository.listCalled)
+		require.False(t, repository.listWithTotalCalled)
+	})
+
+	t.Run("estimate when unfiltered", func(t *testing.T) {
+		repository := &fakeRepo{estimateTotal: 1000, estimateOK: true}
+		service := NewService(repository, WithEstimatedCount(true))
+
+		count, err := service.Count(context.Background(), ListFilter{})
+
+		require.NoError(t, err)
+		require.Equal(t, ItemCount{Total: 1000, TotalIsEstimate: true}, count)
+		require.False(t, repository.countCalled)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Count(context.Background(), ListFilter{MinPrice: "abc"})
+
+		require.ErrorIs(t, err, ErrorInvalidFilter)
+		require.False(t, repository.countCalled)
+	})
+}
+
+func TestService_Export(t *testing.T) {
+	t.Run("normalized filter and every item", func(t *testing.T) {
+		repository := &fakeRepo{listItems: []Item{{ID: "id-1"}, {ID: "id-2"}}}
+		service := NewService(repository)
+
+		var exported []string
+		err := service.Export(context.Background(), ListFilter{Query: " phone "}, func(item Item) error {
+			exported = append(exported, item.ID)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"id-1", "id-2"}, exported)
+		require.Equal(t, "phone", repository.listFilter.Query)
+		require.Equal(t, MatchContains, repository.listFilter.Match)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		err := service.Export(context.Background(), ListFilter{MinPrice: "abc"}, func(Item) error { return nil })
+
+		require.ErrorIs(t, err, ErrorInvalidFilter)
+		require.False(t, repository.exportCalled)
+	})
+}
+
+func TestService_ListSnapshot(t *testing.T) {
+	after := CreatedAtID{CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ID: "id-0"}
+
+	t.Run("offset page and total read from the snapshot", func(t *testing.T) {
+		snapshot := &fakeRepo{listItems: []Item{{ID: "id-1"}}, countTotal: 1}
+		repository := &fakeRepo{snapshotRepo: snapshot}
+		service := NewService(repository)
+
+		result, err := service.List(context.Background(), 1, 10, ListFilter{})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Total)
+		require.True(t, repository.inSnapshotCalled)
+		require.True(t, snapshot.listWithTotalCalled)
+		require.False(t, repository.listCalled)
+	})
+
+	t.Run("estimate and page read from the snapshot", func(t *testing.T) {
+		snapshot := &fakeRepo{listItems: []Item{{ID: "id-1"}}, estimateTotal: 500, estimateOK: true}
+		repository := &fakeRepo{snapshotRepo: snapshot}
+		service := NewService(repository, WithEstimatedCount(true))
+
+		_, err := service.List(context.Background(), 1, 10, ListFilter{})
+
+		require.NoError(t, err)
+		require.True(t, snapshot.estimateCalled)
+		require.True(t, snapshot.listCalled)
+		require.False(t, repository.estimateCalled)
+		require.False(t, repository.listCalled)
+	})
+
+	t.Run("cursor page and count read from the snapshot", func(t *testing.T) {
+		snapshot := &fakeRepo{listItems: []Item{{ID: "id-1"}}, countTotal: 1}
+		repository := &fakeRepo{snapshotRepo: snapshot}
+		service := NewService(repository)
+
+		result, err := service.ListAfter(context.Background(), after, 10, ListFilter{})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Total)
+		require.True(t, repository.inSnapshotCalled)
+		require.True(t, snapshot.listAfterCalled)
+		require.True(t, snapshot.countCalled)
+		require.False(t, repository.listAfterCalled)
+		require.False(t, repository.countCalled)
+	})
+}
+
+func TestService_Get(t *testing.T) {
+	t.Run("not found maps to domain error", func(t *testing.T) {
+		repository := &fakeRepo{
+			getErr: fmt.Errorf("wrapped: %w", pgx.ErrNoRows),
+		}
+		service := NewService(repository)
+
+		item, err := service.Get(context.Background(), "id-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Equal(t, Item{}, item)
+		require.True(t, repository.getCalled, "repo.GetByID should be called")
+		require.Equal(t, "id-1", repository.getID)
+	})
+
+	t.Run("repo error is returned", func(t *testing.T) {
+		errorDatabase := errors.New("db failed")
+		repository := &fakeRepo{
+			getErr: errorDatabase,
+		}
+		service := NewService(repository)
+
+		item, err := service.Get(context.Background(), "id-2")
+
+		require.ErrorIs(t, err, errorDatabase)
+		require.True(t, err == errorDatabase, "expected same error instance")
+		require.Equal(t, Item{}, item)
+		require.True(t, repository.getCalled, "repo.GetByID should be called")
+		require.Equal(t, "id-2", repository.getID)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		expected := Item{ID: "x", Name: "ok", Price: "1.00", Stock: 2}
+		repository := &fakeRepo{
+			getItem: expected,
+		}
+		service := NewService(repository)
+
+		item, err := service.Get(context.Background(), "id-3")
+
+		require.NoError(t, err)
+		require.Equal(t, expected, item)
+		require.True(t, repository.getCalled, "repo.GetByID should be called")
+		require.Equal(t, "id-3", repository.getID)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	t.Run("requires at least one field", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled, "repo.Update should not be called when payload has no fields")
+	})
+
+	t.Run("invalid name empty after trim", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Name: stringPointer("   "),
+		})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+	})
+
+	t.Run("invalid price empty after trim", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Price: stringPointer("   "),
+		})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+	})
+
+	t.Run("invalid price format", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Price: stringPointer("0"),
+		})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+	})
+
+	t.Run("invalid stock negative", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Stock: integerPointer(-1),
+		})
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+	})
+
+	t.Run("repo not found maps to domain error", func(t *testing.T) {
+		repository := &fakeRepo{
+			updateErr: fmt.Errorf("wrapped: %w", ErrorNotFound),
+		}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Name: stringPointer("name"),
+		})
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.True(t, repository.updateCalled, "repo.Update should be called")
+	})
+
+	t.Run("repo duplicate name maps to domain error", func(t *testing.T) {
+		repository := &fakeRepo{
+			updateErr: fmt.Errorf("wrapped: %w", ErrorDuplicateName),
+		}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Name: stringPointer("name"),
+		})
+		require.ErrorIs(t, err, ErrorDuplicateName)
+		require.True(t, repository.updateCalled, "repo.Update should be called")
+	})
+
+	t.Run("repo error is returned", func(t *testing.T) {
+		errDB := errors.New("db failed")
+		repository := &fakeRepo{
+			updateErr: errDB,
+		}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Name: stringPointer("name"),
+		})
+		require.ErrorIs(t, err, errDB)
+		require.True(t, err == errDB, "expected same error instance")
+		require.True(t, repository.updateCalled, "repo.Update should be called")
+	})
+
+	t.Run("success trims fields and returns item", func(t *testing.T) {
+		expected := Item{ID: "x", Name: "ok", Price: "1.00", Stock: 2}
+		repository := &fakeRepo{
+			updateItem: expected,
+		}
+		service := NewService(repository)
+
+		item, err := service.Update(context.Background(), "id", UpdateItemInput{
+			Name:  stringPointer("  name  "),
+			Price: stringPointer(" 10.00 "),
+			Stock: integerPointer(2),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, item)
+		require.True(t, repository.updateCalled, "repo.Update should be called")
+		require.Equal(t, "id", repository.updateID)
+		require.NotNil(t, repository.updateInput.Name)
+		require.Equal(t, "name", *repository.updateInput.Name)
+		require.NotNil(t, repository.updateInput.Price)
+		require.Equal(t, "10.00", *repository.updateInput.Price)
+		require.NotNil(t, repository.updateInput.Stock)
+		require.Equal(t, 2, *repository.updateInput.Stock)
+	})
+}
+
+func TestService_Update_ClearDescriptionOnly(t *testing.T) {
+	repository := &fakeRepo{}
+	service := NewService(repository)
+
+	_, err := service.Update(context.Background(), "id", UpdateItemInput{DescriptionPresent: true})
+
+	require.NoError(t, err)
+	require.True(t, repository.updateCalled)
+	require.True(t, repository.updateInput.DescriptionPresent)
+	require.Nil(t, repository.updateInput.Description)
+}
+
+func TestService_Update_AttributesMergePatch(t *testing.T) {
+	current := Item{ID: "id-1", Name: "Phone", Price: "10.00", Currency: "USD", Attributes: map[string]any{"color": "red", "size": "M", "waterproof": true}}
+
+	t.Run("merged with the current attributes", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{
+			AttributesPresent: true,
+			AttributesPatch:   map[string]any{"color": "blue", "size": nil, "material": "aluminium"},
+		})
+
+		require.NoError(t, err)
+		require.True(t, repository.getForUpdateCalled, "merged against the locked item")
+		require.True(t, repository.updateInput.AttributesPresent)
+		require.Nil(t, repository.updateInput.AttributesPatch)
+		require.Equal(t, map[string]any{"color": "blue", "waterproof": true, "material": "aluminium"}, repository.updateInput.Attributes)
+		require.Equal(t, map[string]any{"color": "red", "size": "M", "waterproof": true}, current.Attributes, "current attributes are not modified")
+	})
+
+	t.Run("deleting every key leaves them empty", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{
+			AttributesPresent: true,
+			AttributesPatch:   map[string]any{"color": nil, "size": nil, "waterproof": nil, "missing": nil},
+		})
+
+		require.NoError(t, err)
+		require.Empty(t, repository.updateInput.Attributes)
+	})
+
+	t.Run("the merged result is validated", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{
+			AttributesPresent: true,
+			AttributesPatch:   map[string]any{"size": map[string]any{"eu": "40"}},
+		})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "attributes", validationError.Field)
+		require.False(t, repository.updateCalled)
+	})
+}
+
+func TestService_ApplyJSONPatch(t *testing.T) {
+	current := Item{ID: "id-1", Name: "Phone", Price: "10.00", Stock: 3}
+
+	t.Run("applies and persists inside a transaction", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		metrics := &countingMetrics{}
+		service := NewService(repository, WithMetrics(metrics))
+
+		_, err := service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "test", Path: "/stock", Value: json.RawMessage(`3`)},
+			{Op: "replace", Path: "/stock", Value: json.RawMessage(`2`)},
+		}, nil)
+
+		require.NoError(t, err)
+		require.True(t, repository.inTxCalled)
+		require.True(t, repository.getForUpdateCalled)
+		require.True(t, repository.updateCalled)
+		require.Equal(t, 2, *repository.updateInput.Stock)
+		require.Equal(t, 1, metrics.updated)
+	})
+
+	t.Run("stale version is not applied", func(t *testing.T) {
+		versioned := current
+		versioned.Version = 4
+		repository := &fakeRepo{getItem: versioned}
+		service := NewService(repository)
+
+		_, err := service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "replace", Path: "/stock", Value: json.RawMessage(`2`)},
+		}, integerPointer(3))
+
+		require.ErrorIs(t, err, ErrorVersionMismatch)
+		require.False(t, repository.updateCalled)
+
+		_, err = service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "replace", Path: "/stock", Value: json.RawMessage(`2`)},
+		}, integerPointer(4))
+
+		require.NoError(t, err)
+		require.True(t, repository.updateCalled)
+	})
+
+	t.Run("limits the number of operations", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+		operations := make([]PatchOperation, maxPatchOperations+1)
+		for index := range operations {
+			operations[index] = PatchOperation{Op: "test", Path: "/stock", Value: json.RawMessage(`3`)}
+		}
+
+		_, err := service.ApplyJSONPatch(context.Background(), "id-1", operations, nil)
+		require.ErrorIs(t, err, ErrorPatchTooLarge)
+		require.False(t, repository.inTxCalled)
+
+		_, err = service.ApplyJSONPatch(context.Background(), "id-1", operations[:maxPatchOperations], nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("result goes through the update rules", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		_, err := service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "replace", Path: "/price", Value: json.RawMessage(`"0"`)},
+		}, nil)
+
+		require.ErrorIs(t, err, ErrorInvalidPrice)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("failed test does not update", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		_, err := service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "replace", Path: "/stock", Value: json.RawMessage(`2`)},
+			{Op: "test", Path: "/name", Value: json.RawMessage(`"Tablet"`)},
+		}, nil)
+
+		require.ErrorIs(t, err, ErrorPatchTestFailed)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("test only patch returns the current item", func(t *testing.T) {
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		item, err := service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "test", Path: "/name", Value: json.RawMessage(`"Phone"`)},
+		}, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, current, item)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: ErrorNotFound}
+		service := NewService(repository)
+
+		_, err := service.ApplyJSONPatch(context.Background(), "id-1", []PatchOperation{
+			{Op: "replace", Path: "/stock", Value: json.RawMessage(`2`)},
+		}, nil)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Delete(context.Background(), "id", nil)
+
+		require.NoError(t, err)
+		require.True(t, repository.deleteCalled, "repo.Delete should be called")
+		require.Equal(t, "id", repository.deleteID)
+		require.Nil(t, repository.deleteIfVersion)
+	})
+
+	t.Run("passes the expected version", func(t *testing.T) {
+		repository := &fakeRepo{deleteErr: ErrorVersionMismatch}
+		service := NewService(repository)
+
+		_, err := service.Delete(context.Background(), "id", integerPointer(2))
+
+		require.ErrorIs(t, err, ErrorVersionMismatch)
+		require.Equal(t, 2, *repository.deleteIfVersion)
+	})
+
+	t.Run("repo error is returned", func(t *testing.T) {
+		errorFromDatabase := errors.New("delete failed")
+		repository := &fakeRepo{deleteErr: errorFromDatabase}
+		service := NewService(repository)
+
+		_, err := service.Delete(context.Background(), "id-2", nil)
+
+		require.ErrorIs(t, err, errorFromDatabase)
+		require.True(t, err == errorFromDatabase, "expected same error instance")
+		require.True(t, repository.deleteCalled, "repo.Delete should be called")
+		require.Equal(t, "id-2", repository.deleteID)
+	})
+}
+
+func TestService_Purge(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		require.NoError(t, service.Purge(context.Background(), "id-1"))
+		require.Equal(t, "id-1", repository.purgeID)
+	})
+
+	t.Run("not in trash", func(t *testing.T) {
+		repository := &fakeRepo{purgeErr: ErrorNotFound}
+		service := NewService(repository)
+
+		require.ErrorIs(t, service.Purge(context.Background(), "id-2"), ErrorNotFound)
+	})
+}
+
+func TestService_StockLedger(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+
+	t.Run("create with stock records the initial movement in a transaction", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(ctx, CreateItemInput{Name: "Lamp", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 3})
+
+		require.NoError(t, err)
+		require.True(t, repository.inTxCalled)
+		require.Equal(t, []StockMovement{{ItemID: "x", Delta: 3, ResultingStock: 3, Reason: StockReasonCreate, RequestID: "req-1"}}, repository.movements)
+	})
+
+	t.Run("create without stock records no movement", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(ctx, CreateItemInput{Name: "Lamp", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Empty(t, repository.movements)
+	})
+
+	t.Run("patch stock records the difference", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 10}, updateItem: Item{ID: "id-1", Stock: 4}}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Stock: integerPointer(4)})
+
+		require.NoError(t, err)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, []StockMovement{{ItemID: "id-1", Delta: -6, ResultingStock: 4, Reason: StockReasonUpdate, RequestID: "req-1"}}, repository.movements)
+	})
+
+	t.Run("patch without stock skips the ledger", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 4}, updateItem: Item{ID: "id-1", Stock: 4}}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Description: stringPointer("Desk lamp"), DescriptionPresent: true})
+
+		require.NoError(t, err)
+		require.Empty(t, repository.movements)
+	})
+
+	t.Run("a ledger failure fails the update", func(t *testing.T) {
+		ledgerErr := errors.New("ledger down")
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 1}, updateItem: Item{ID: "id-1", Stock: 2}, movementsErr: ledgerErr}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Stock: integerPointer(2)})
+
+		require.ErrorIs(t, err, ledgerErr)
+	})
+}
+
+func TestService_PriceHistory(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+
+	t.Run("create records the initial price", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(ctx, CreateItemInput{Name: "Lamp", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.True(t, repository.inTxCalled)
+		require.Equal(t, []PriceHistoryEntry{{ItemID: "x", NewPrice: "10.00", Reason: StockReasonCreate, RequestID: "req-1"}}, repository.priceChanges)
+	})
+
+	t.Run("patch price records the old and new price", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00"}, updateItem: Item{ID: "id-1", Price: "12.50"}}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Price: stringPointer("12.5")})
+
+		require.NoError(t, err)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, []PriceHistoryEntry{{ItemID: "id-1", OldPrice: stringPointer("10.00"), NewPrice: "12.50", Reason: StockReasonUpdate, RequestID: "req-1"}}, repository.priceChanges)
+	})
+
+	t.Run("patch with the same price records nothing", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00"}, updateItem: Item{ID: "id-1", Price: "10.00"}}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Price: stringPointer("10")})
+
+		require.NoError(t, err)
+		require.Empty(t, repository.priceChanges)
+	})
+
+	t.Run("patch without price records nothing", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00", Stock: 1}, updateItem: Item{ID: "id-1", Price: "10.00", Stock: 5}}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Stock: integerPointer(5)})
+
+		require.NoError(t, err)
+		require.Len(t, repository.movements, 1)
+		require.Empty(t, repository.priceChanges)
+	})
+
+	t.Run("replace records the price change", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00", Currency: "USD"}}
+		service := NewService(repository)
+
+		_, err := service.Replace(ctx, "id-1", ReplaceItemInput{Name: "Lamp", Slug: "lamp", Price: "8.00"})
+
+		require.NoError(t, err)
+		require.Equal(t, []PriceHistoryEntry{{ItemID: "id-1", OldPrice: stringPointer("10.00"), NewPrice: "8.00", Reason: StockReasonReplace, RequestID: "req-1"}}, repository.priceChanges)
+	})
+
+	t.Run("a scheduled change is recorded", func(t *testing.T) {
+		repository := &fakeRepo{
+			getItem:      Item{ID: "id-1", Price: "10.00", Currency: "USD"},
+			updateItem:   Item{ID: "id-1", Price: "15.00"},
+			dueSchedules: []PriceSchedule{{ID: "schedule-1", ItemID: "id-1", NewPrice: "15.00"}},
+		}
+		service := NewService(repository)
+
+		_, err := service.ApplyDuePriceSchedules(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, []PriceHistoryEntry{{ItemID: "id-1", OldPrice: stringPointer("10.00"), NewPrice: "15.00", Reason: PriceReasonSchedule}}, repository.priceChanges)
+	})
+
+	t.Run("a history failure fails the update", func(t *testing.T) {
+		historyErr := errors.New("history down")
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Price: "10.00"}, updateItem: Item{ID: "id-1", Price: "12.00"}, priceChangesErr: historyErr}
+		service := NewService(repository)
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Price: stringPointer("12.00")})
+
+		require.ErrorIs(t, err, historyErr)
+	})
+
+	t.Run("list pages the history of the item", func(t *testing.T) {
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}, listPriceHistory: []PriceHistoryEntry{{ID: 1, ItemID: "id-1", NewPrice: "10.00"}}}
+		service := NewService(repository)
+
+		page, err := service.PriceHistory(context.Background(), "id-1", PriceHistoryFilter{From: &from}, 2, 10)
+
+		require.NoError(t, err)
+		require.Equal(t, 1, page.Total)
+		require.Equal(t, &from, repository.historyFilter.From)
+		require.Equal(t, 10, repository.listMovementsAt)
+		require.True(t, repository.inSnapshotCalled)
+	})
+
+	t.Run("list rejects an empty range", func(t *testing.T) {
+		from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		service := NewService(&fakeRepo{})
+
+		_, err := service.PriceHistory(context.Background(), "id-1", PriceHistoryFilter{From: &from, To: &to}, 1, 10)
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+		require.Equal(t, "to", filterError.Field)
+	})
+
+	t.Run("list of a missing item", func(t *testing.T) {
+		service := NewService(&fakeRepo{getErr: pgx.ErrNoRows})
+
+		_, err := service.PriceHistory(context.Background(), "missing", PriceHistoryFilter{}, 1, 10)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestService_AdjustStock(t *testing.T) {
+	t.Run("adds delta and records the reason", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 5}, updateItem: Item{ID: "id-1", Stock: 55}}
+		service := NewService(repository)
+
+		item, err := service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{Delta: 50, Reason: " purchase order 12 "})
+
+		require.NoError(t, err)
+		require.Equal(t, 55, item.Stock)
+		require.Equal(t, 55, *repository.updateInput.Stock)
+		require.Equal(t, []StockMovement{{ItemID: "id-1", Delta: 50, ResultingStock: 55, Reason: "purchase order 12"}}, repository.movements)
+	})
+
+	t.Run("default reason", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 5}, updateItem: Item{ID: "id-1", Stock: 4}}
+		service := NewService(repository)
+
+		_, err := service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{Delta: -1})
+
+		require.NoError(t, err)
+		require.Equal(t, StockReasonAdjustment, repository.movements[0].Reason)
+	})
+
+	t.Run("cannot go below zero", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2}}
+		service := NewService(repository)
+
+		_, err := service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{Delta: -3})
+
+		require.ErrorIs(t, err, ErrorInvalidStock)
+		require.False(t, repository.updateCalled)
+		require.Empty(t, repository.movements)
+	})
+
+	t.Run("zero delta", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "delta", validationError.Field)
+		require.False(t, repository.inTxCalled)
+	})
+}
+
+func TestService_Backorder(t *testing.T) {
+	t.Run("create with negative stock needs the flag", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: -5})
+
+		require.ErrorIs(t, err, ErrorInvalidStock)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("create backordered item", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: -5, AllowBackorder: true})
+
+		require.NoError(t, err)
+		require.Equal(t, -5, repository.insertCreatedInput.Stock)
+		require.True(t, repository.insertCreatedInput.AllowBackorder)
+	})
+
+	t.Run("create below the floor", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithBackorderFloor(-10))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: -11, AllowBackorder: true})
+
+		require.ErrorIs(t, err, ErrorStockBelowFloor)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("patch takes the flag from the item", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2, AllowBackorder: true}, updateItem: Item{ID: "id-1", Stock: -3}}
+		service := NewService(repository)
+
+		item, err := service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(-3)})
+
+		require.NoError(t, err)
+		require.Equal(t, -3, item.Stock)
+		require.Equal(t, -5, repository.movements[0].Delta)
+	})
+
+	t.Run("patch without the flag keeps the old rule", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(-3)})
+
+		require.ErrorIs(t, err, ErrorInvalidStock)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("patch enabling the flag in the same request", func(t *testing.T) {
+		allow := true
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2}, updateItem: Item{ID: "id-1", Stock: -3}}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(-3), AllowBackorder: &allow})
+
+		require.NoError(t, err)
+		require.True(t, repository.updateCalled)
+	})
+
+	t.Run("patch disabling the flag with negative stock", func(t *testing.T) {
+		allow := false
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(-3), AllowBackorder: &allow})
+
+		require.ErrorIs(t, err, ErrorInvalidStock)
+		require.False(t, repository.inTxCalled)
+	})
+
+	t.Run("patch below the floor", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository, WithBackorderFloor(-10))
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(-11)})
+
+		require.ErrorIs(t, err, ErrorStockBelowFloor)
+		require.False(t, repository.inTxCalled)
+	})
+
+	t.Run("adjustment below zero", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2, AllowBackorder: true}, updateItem: Item{ID: "id-1", Stock: -8}}
+		service := NewService(repository)
+
+		_, err := service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{Delta: -10})
+
+		require.NoError(t, err)
+		require.Equal(t, -8, *repository.updateInput.Stock)
+	})
+
+	t.Run("adjustment below the floor", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2, AllowBackorder: true}}
+		service := NewService(repository, WithBackorderFloor(-5))
+
+		_, err := service.AdjustStock(context.Background(), "id-1", StockAdjustmentInput{Delta: -10})
+
+		require.ErrorIs(t, err, ErrorStockBelowFloor)
+		require.False(t, repository.updateCalled)
+	})
+}
+
+func TestService_StockMovements(t *testing.T) {
+	t.Run("reads the page in a snapshot", func(t *testing.T) {
+		repository := &fakeRepo{listMovements: []StockMovement{{ID: 2}, {ID: 1}}}
+		service := NewService(repository)
+
+		page, err := service.StockMovements(context.Background(), "id-1", 3, 2)
+
+		require.NoError(t, err)
+		require.True(t, repository.inSnapshotCalled)
+		require.Equal(t, 2, page.Total)
+		require.Equal(t, "id-1", repository.listMovementsID)
+		require.Equal(t, 4, repository.listMovementsAt)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.StockMovements(context.Background(), "id-1", 1, 20)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestService_Reserve(t *testing.T) {
+	t.Run("reserves with the item locked and the default ttl", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 5}, reserved: 3}
+		service := NewService(repository)
+
+		reservation, err := service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 2})
+
+		require.NoError(t, err)
+		require.Equal(t, "res-1", reservation.ID)
+		require.True(t, repository.inTxCalled)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, 2, repository.reserveQuantity)
+		require.Equal(t, defaultReservationTTL, repository.reserveTTL)
+	})
+
+	t.Run("reserving notifies the item in the transaction and publishes it after", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 5, Version: 3}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 2})
+
+		require.NoError(t, err)
+		require.Len(t, repository.notified, 1)
+		require.Equal(t, EventUpdated, repository.notified[0].Operation)
+		require.Equal(t, "id-1", repository.notified[0].ID)
+		require.Len(t, events.published, 1)
+		require.Equal(t, 3, events.published[0].Item.Version)
+	})
+
+	t.Run("uses the requested ttl", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 1}}
+		service := NewService(repository)
+
+		_, err := service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 1, TTLSeconds: 90})
+
+		require.NoError(t, err)
+		require.Equal(t, 90*time.Second, repository.reserveTTL)
+	})
+
+	t.Run("insufficient stock counts active reservations", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 5}, reserved: 4}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 2})
+
+		require.ErrorIs(t, err, ErrorInsufficientStock)
+		require.False(t, repository.reserveCalled)
+		require.Empty(t, repository.notified)
+		require.Empty(t, events.published)
+	})
+
+	t.Run("below the minimum order quantity", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 50, MinOrderQty: 10}}
+		service := NewService(repository)
+
+		_, err := service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 9})
+
+		require.ErrorIs(t, err, ErrorBelowMinOrderQty)
+		require.False(t, repository.reserveCalled)
+
+		_, err = service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 10})
+
+		require.NoError(t, err)
+		require.Equal(t, 10, repository.reserveQuantity)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: ErrorNotFound}
+		service := NewService(repository)
+
+		_, err := service.Reserve(context.Background(), "id-1", ReserveInput{Quantity: 1})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+
+	for name, input := range map[string]ReserveInput{
+		"zero quantity":    {Quantity: 0},
+		"negative ttl":     {Quantity: 1, TTLSeconds: -1},
+		"ttl over maximum": {Quantity: 1, TTLSeconds: 3601},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.Reserve(context.Background(), "id-1", input)
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.False(t, repository.inTxCalled)
+		})
+	}
+}
+
+func TestService_Release(t *testing.T) {
+	t.Run("missing reservation", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{releaseErr: ErrorNotFound}
+		service := NewService(repository, WithEventPublisher(events))
+
+		require.ErrorIs(t, service.Release(context.Background(), "id-1", "res-9"), ErrorNotFound)
+		require.Equal(t, "res-9", repository.releaseReservation)
+		require.Empty(t, repository.notified)
+		require.Empty(t, events.published)
+	})
+
+	t.Run("releasing notifies and publishes the item", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 5}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		require.NoError(t, service.Release(context.Background(), "id-1", "res-1"))
+		require.True(t, repository.inTxCalled)
+		require.Len(t, repository.notified, 1)
+		require.Len(t, events.published, 1)
+		require.Equal(t, "id-1", events.published[0].ID)
+	})
+}
+
+func TestService_ExpireReservations(t *testing.T) {
+	events := &recordingEvents{}
+	repository := &fakeRepo{expiredItemIDs: []string{"id-1", "id-2"}}
+	service := NewService(repository, WithEventPublisher(events))
+
+	deleted, err := service.ExpireReservations(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+	require.True(t, repository.inTxCalled)
+	want := []Event{{ID: "id-1", Operation: EventUpdated}, {ID: "id-2", Operation: EventUpdated}}
+	require.Equal(t, want, repository.notified)
+	require.Equal(t, want, events.published)
+}
+
+func TestService_State(t *testing.T) {
+	t.Run("create defaults to published and accepts draft", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-1"), Price: "10.00"})
+		require.NoError(t, err)
+		require.Equal(t, StatePublished, repository.insertCreatedInput.State)
+
+		_, err = service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-1"), Price: "10.00", State: " Draft "})
+		require.NoError(t, err)
+		require.Equal(t, StateDraft, repository.insertCreatedInput.State)
+	})
+
+	t.Run("create rejects an unknown state", func(t *testing.T) {
+		service := NewService(&fakeRepo{})
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Mouse", SKU: stringPointer("MS-1"), Price: "10.00", State: "archived"})
+
+		require.ErrorIs(t, err, ErrorInvalidState)
+		require.ErrorIs(t, err, ErrorInvalidInput)
+	})
+
+	t.Run("list rejects an unknown state filter", func(t *testing.T) {
+		service := NewService(&fakeRepo{})
+
+		_, err := service.List(context.Background(), 1, 10, ListFilter{State: "archived"})
+
+		var filterError *FilterError
+		require.ErrorAs(t, err, &filterError)
+	})
+
+	t.Run("publish updates a complete draft", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Mouse", Price: "10.00", State: StateDraft}}
+		service := NewService(repository, WithMetrics(metrics))
+
+		_, err := service.Publish(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, "id-1", repository.updateID)
+		require.Equal(t, UpdateItemInput{State: statePointer(StatePublished)}, repository.updateInput)
+		require.Equal(t, 1, metrics.updated)
+	})
+
+	t.Run("publish of a published item changes nothing", func(t *testing.T) {
+		current := Item{ID: "id-1", Name: "Mouse", Price: "10.00", State: StatePublished}
+		repository := &fakeRepo{getItem: current}
+		service := NewService(repository)
+
+		item, err := service.Publish(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, current, item)
+		require.False(t, repository.updateCalled)
+	})
+
+	t.Run("publish requires name and price", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			item  Item
+			field string
+		}{
+			{"blank name", Item{ID: "id-1", Name: " ", Price: "10.00", State: StateDraft}, "name"},
+			{"missing price", Item{ID: "id-1", Name: "Mouse", State: StateDraft}, "price"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repository := &fakeRepo{getItem: tt.item}
+				service := NewService(repository)
+
+				_, err := service.Publish(context.Background(), "id-1")
+
+				var validationError *ValidationError
+				require.ErrorAs(t, err, &validationError)
+				require.Equal(t, tt.field, validationError.Field)
+				require.ErrorIs(t, err, ErrorInvalidInput)
+				require.False(t, repository.updateCalled)
 			})
 		}
 	})
+
+	t.Run("unpublish skips the completeness check", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", State: StatePublished}}
+		service := NewService(repository)
+
+		_, err := service.Unpublish(context.Background(), "id-1")
+
+		require.NoError(t, err)
+		require.Equal(t, UpdateItemInput{State: statePointer(StateDraft)}, repository.updateInput)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		service := NewService(&fakeRepo{getErr: ErrorNotFound})
+
+		_, err := service.Unpublish(context.Background(), "missing")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestService_Duplicate(t *testing.T) {
+	description := "black"
+	source := Item{ID: "src", Name: "Phone X", SKU: stringPointer("PX-1"), Description: &description, Price: "10.00", TaxRateBPS: 1900, MinOrderQty: 6, Stock: 7}
+
+	t.Run("copies fields with a copy name and zero stock", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{getItem: source}
+		service := NewService(repository, WithMetrics(metrics))
+
+		_, err := service.Duplicate(context.Background(), "src", DuplicateItemInput{SKU: "px-2"})
+
+		require.NoError(t, err)
+		require.Equal(t, "src", repository.getID)
+		require.Equal(t, CreateItemInput{Name: "Phone X (copy)", Slug: "phone-x-copy", SKU: stringPointer("PX-2"), Description: &description, Price: "10.00", TaxRateBPS: integerPointer(1900), MinOrderQty: integerPointer(6)}, repository.insertCreatedInput)
+		require.Equal(t, 1, metrics.created)
+	})
+
+	t.Run("copy_stock keeps the stock", func(t *testing.T) {
+		repository := &fakeRepo{getItem: source}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "src", DuplicateItemInput{SKU: "PX-2", CopyStock: true})
+
+		require.NoError(t, err)
+		require.Equal(t, 7, repository.insertCreatedInput.Stock)
+	})
+
+	t.Run("name collisions try the next suffix", func(t *testing.T) {
+		repository := &fakeRepo{getItem: source, insertErrs: []error{ErrorDuplicateName, ErrorDuplicateName}}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "src", DuplicateItemInput{SKU: "px-2"})
+
+		require.NoError(t, err)
+		require.Equal(t, 3, repository.insertCalls)
+		require.Equal(t, "Phone X (copy 3)", repository.insertCreatedInput.Name)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		repository := &fakeRepo{getItem: source, insertErr: ErrorDuplicateName}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "src", DuplicateItemInput{SKU: "px-2"})
+
+		require.ErrorIs(t, err, ErrorDuplicateName)
+		require.Equal(t, maxCopyNameAttempts, repository.insertCalls)
+	})
+
+	t.Run("requires a valid sku", func(t *testing.T) {
+		repository := &fakeRepo{getItem: source}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "src", DuplicateItemInput{})
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "sku", validationError.Field)
+
+		_, err = service.Duplicate(context.Background(), "src", DuplicateItemInput{SKU: "P X"})
+		require.ErrorIs(t, err, ErrorInvalidSKU)
+		require.Zero(t, repository.insertCalls)
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
+		service := NewService(repository)
+
+		_, err := service.Duplicate(context.Background(), "src", DuplicateItemInput{SKU: "px-2"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Zero(t, repository.insertCalls)
+	})
+}
+
+func TestService_UpdateMany(t *testing.T) {
+	stock := 3
+	name := "  Phone  "
+	blank := " "
+
+	t.Run("applies every entry in one transaction", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{}
+		service := NewService(repository, WithMetrics(metrics))
+
+		results, err := service.UpdateMany(context.Background(), []BulkUpdateEntry{
+			{ID: "a", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "b", Input: UpdateItemInput{DescriptionPresent: true}},
+		})
+
+		require.NoError(t, err)
+		require.True(t, repository.inTxCalled)
+		require.Equal(t, []string{"a", "b"}, repository.updateIDs)
+		require.Len(t, results, 2)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, "a", results[0].Item.ID)
+		require.Equal(t, "b", results[1].ID)
+		require.Equal(t, 2, metrics.updated)
+	})
+
+	t.Run("invalid entries stop the batch before touching the database", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		results, err := service.UpdateMany(context.Background(), []BulkUpdateEntry{
+			{ID: "a", Input: UpdateItemInput{Name: &name}},
+			{ID: "b", Input: UpdateItemInput{Name: &blank}},
+			{ID: "c", Input: UpdateItemInput{}},
+		})
+
+		require.NoError(t, err)
+		require.False(t, repository.inTxCalled)
+		require.ErrorIs(t, results[0].Err, ErrorNotApplied)
+		require.ErrorIs(t, results[1].Err, ErrorInvalidName)
+		require.ErrorIs(t, results[2].Err, ErrorInvalidInput)
+	})
+
+	t.Run("a failing entry rolls back the others", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{updateErrByID: map[string]error{"b": ErrorNotFound}}
+		service := NewService(repository, WithMetrics(metrics))
+
+		results, err := service.UpdateMany(context.Background(), []BulkUpdateEntry{
+			{ID: "a", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "b", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "c", Input: UpdateItemInput{Stock: &stock}},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, repository.updateIDs)
+		require.ErrorIs(t, results[0].Err, ErrorNotApplied)
+		require.Empty(t, results[0].Item.ID)
+		require.ErrorIs(t, results[1].Err, ErrorNotFound)
+		require.ErrorIs(t, results[2].Err, ErrorNotApplied)
+		require.Zero(t, metrics.updated)
+	})
+
+	t.Run("duplicate ids are rejected", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.UpdateMany(context.Background(), []BulkUpdateEntry{
+			{ID: "a", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "a", Input: UpdateItemInput{Name: &name}},
+		})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "updates[1].id", validationError.Field)
+		require.False(t, repository.inTxCalled)
+	})
+
+	t.Run("empty or oversized batches are rejected", func(t *testing.T) {
+		for _, entries := range [][]BulkUpdateEntry{nil, make([]BulkUpdateEntry, 201)} {
+			service := NewService(&fakeRepo{})
+
+			_, err := service.UpdateMany(context.Background(), entries)
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, "updates", validationError.Field)
+		}
+	})
+
+	t.Run("unexpected errors are returned", func(t *testing.T) {
+		errorFromDatabase := errors.New("update failed")
+		service := NewService(&fakeRepo{updateErr: errorFromDatabase})
+
+		_, err := service.UpdateMany(context.Background(), []BulkUpdateEntry{{ID: "a", Input: UpdateItemInput{Stock: &stock}}})
+
+		require.ErrorIs(t, err, errorFromDatabase)
+	})
+}
+
+func TestService_UpdateEach(t *testing.T) {
+	stock := 3
+	blank := " "
+
+	t.Run("applies the valid entries and reports the rest", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{updateErrByID: map[string]error{"c": ErrorNotFound}}
+		service := NewService(repository, WithMetrics(metrics))
+
+		results, err := service.UpdateEach(context.Background(), []BulkUpdateEntry{
+			{ID: "a", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "b", Input: UpdateItemInput{Name: &blank}},
+			{ID: "c", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "d", Input: UpdateItemInput{Stock: &stock}},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "c", "d"}, repository.updateIDs)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, "a", results[0].Item.ID)
+		require.ErrorIs(t, results[1].Err, ErrorInvalidName)
+		require.ErrorIs(t, results[2].Err, ErrorNotFound)
+		require.NoError(t, results[3].Err)
+		require.Equal(t, 2, metrics.updated)
+	})
+
+	t.Run("duplicate ids are rejected", func(t *testing.T) {
+		repository := &fakeRepo{}
+
+		_, err := NewService(repository).UpdateEach(context.Background(), []BulkUpdateEntry{
+			{ID: "a", Input: UpdateItemInput{Stock: &stock}},
+			{ID: "a", Input: UpdateItemInput{Stock: &stock}},
+		})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Empty(t, repository.updateIDs)
+	})
+
+	t.Run("unexpected errors are returned", func(t *testing.T) {
+		errorFromDatabase := errors.New("update failed")
+		service := NewService(&fakeRepo{updateErr: errorFromDatabase})
+
+		_, err := service.UpdateEach(context.Background(), []BulkUpdateEntry{{ID: "a", Input: UpdateItemInput{Stock: &stock}}})
+
+		require.ErrorIs(t, err, errorFromDatabase)
+	})
+}
+
+func TestService_DeleteMany(t *testing.T) {
+	t.Run("reports missing ids in request order", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{deleteManyDeleted: []string{"b", "a"}}
+		service := NewService(repository, WithMetrics(metrics))
+
+		result, err := service.DeleteMany(context.Background(), []string{"a", "c", "b", "a", "d"})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "c", "b", "d"}, repository.deleteManyIDs)
+		require.Equal(t, BulkDeleteResult{Deleted: 2, Missing: []string{"c", "d"}}, result)
+		require.Equal(t, 2, metrics.deleted)
+	})
+
+	t.Run("nothing missing is an empty list", func(t *testing.T) {
+		repository := &fakeRepo{deleteManyDeleted: []string{"a"}}
+		service := NewService(repository)
+
+		result, err := service.DeleteMany(context.Background(), []string{"a"})
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Missing)
+		require.Empty(t, result.Missing)
+	})
+
+	t.Run("empty or oversized lists are rejected", func(t *testing.T) {
+		for _, ids := range [][]string{nil, make([]string, 501)} {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.DeleteMany(context.Background(), ids)
+
+			require.ErrorIs(t, err, ErrorInvalidInput)
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.Equal(t, "ids", validationError.Field)
+			require.Nil(t, repository.deleteManyIDs)
+		}
+	})
+
+	t.Run("repo error is returned", func(t *testing.T) {
+		errorFromDatabase := errors.New("delete failed")
+		service := NewService(&fakeRepo{deleteManyErr: errorFromDatabase})
+
+		_, err := service.DeleteMany(context.Background(), []string{"a"})
+
+		require.ErrorIs(t, err, errorFromDatabase)
+	})
+}
+
+func TestService_AdjustPrices(t *testing.T) {
+	percent := PriceAdjustmentInput{
+		Filter:     PriceAdjustmentFilter{CategoryID: "550e8400-e29b-41d4-a716-446655440000"},
+		Adjustment: PriceAdjustment{Type: "Percent", Value: " 5 "},
+	}
+	sample := []PriceAdjustmentSample{{ID: "id-1", Name: "Phone", Currency: "USD", OldPrice: "10.00", NewPrice: "10.50"}}
+
+	t.Run("dry run computes in a snapshot without applying", func(t *testing.T) {
+		repository := &fakeRepo{preview: PriceAdjustmentPreview{Affected: 3, Sample: sample}}
+		service := NewService(repository)
+		input := percent
+		input.DryRun = true
+
+		result, err := service.AdjustPrices(context.Background(), input)
+
+		require.NoError(t, err)
+		require.True(t, repository.inSnapshotCalled)
+		require.False(t, repository.adjustPricesCalled)
+		require.Equal(t, PriceAdjustmentResult{Affected: 3, DryRun: true, Sample: sample}, result)
+		require.Equal(t, "550e8400-e29b-41d4-a716-446655440000", repository.previewFilter.CategoryID)
+		require.Equal(t, PriceAdjustment{Type: AdjustmentPercent, Value: "5"}, repository.previewAdjustment)
+	})
+
+	t.Run("applies in a transaction and records the reason", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		events := &recordingEvents{}
+		repository := &fakeRepo{preview: PriceAdjustmentPreview{Affected: 2, Sample: sample}, adjustPricesIDs: []string{"id-1", "id-2"}}
+		service := NewService(repository, WithMetrics(metrics), WithEventPublisher(events))
+
+		result, err := service.AdjustPrices(context.Background(), percent)
+
+		require.NoError(t, err)
+		require.True(t, repository.inTxCalled)
+		require.True(t, repository.adjustPricesCalled)
+		require.Equal(t, PriceReasonBulkAdjustment, repository.adjustPricesReason)
+		require.Equal(t, 2, result.Affected)
+		require.Equal(t, 2, metrics.updated)
+		require.Equal(t, []Event{{ID: "id-1", Operation: EventUpdated}, {ID: "id-2", Operation: EventUpdated}}, events.published)
+	})
+
+	t.Run("refuses non-positive or below-sale prices", func(t *testing.T) {
+		for _, test := range []struct {
+			preview PriceAdjustmentPreview
+			want    error
+		}{
+			{PriceAdjustmentPreview{Affected: 4, NonPositive: 1}, ErrorAdjustedPriceNotPositive},
+			{PriceAdjustmentPreview{Affected: 4, NotAboveSalePrice: 2}, ErrorAdjustedBelowSalePrice},
+		} {
+			repository := &fakeRepo{preview: test.preview}
+			service := NewService(repository)
+			input := percent
+			input.DryRun = true
+
+			_, err := service.AdjustPrices(context.Background(), input)
+
+			require.ErrorIs(t, err, test.want)
+			require.False(t, repository.adjustPricesCalled)
+		}
+	})
+
+	t.Run("over the cap needs confirm_over", func(t *testing.T) {
+		repository := &fakeRepo{preview: PriceAdjustmentPreview{Affected: maxUnconfirmedPriceAdjustment + 1}}
+		service := NewService(repository)
+
+		_, err := service.AdjustPrices(context.Background(), percent)
+
+		var limitError *PriceAdjustmentLimitError
+		require.ErrorAs(t, err, &limitError)
+		require.Equal(t, maxUnconfirmedPriceAdjustment+1, limitError.Affected)
+		require.False(t, repository.adjustPricesCalled)
+
+		input := percent
+		input.ConfirmOver = integerPointer(maxUnconfirmedPriceAdjustment)
+		_, err = service.AdjustPrices(context.Background(), input)
+		require.ErrorAs(t, err, &limitError, "confirm_over below the affected count")
+
+		input.ConfirmOver = integerPointer(500)
+		_, err = service.AdjustPrices(context.Background(), input)
+		require.NoError(t, err)
+		require.True(t, repository.adjustPricesCalled)
+	})
+
+	t.Run("filter errors name the body field", func(t *testing.T) {
+		service := NewService(&fakeRepo{})
+
+		_, err := service.AdjustPrices(context.Background(), PriceAdjustmentInput{
+			Filter:     PriceAdjustmentFilter{BrandID: "nope"},
+			Adjustment: PriceAdjustment{Type: AdjustmentPercent, Value: "5"},
+		})
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "filter.brand_id", validationError.Field)
+	})
 }
 
-// TestService_List prueba la lista de productos
-func TestService_List(t *testing.T) {
-	t.Run("invalid pagination", func(t *testing.T) {
-		tests := []struct {
-			name  string
-			page  int
-			limit int
-		}{
-			{"page zero", 0, 10},
-			{"page negative", -1, 10},
-			{"limit zero", 1, 0},
-			{"limit negative", 1, -10},
-		}
+// countingMetrics cuenta los eventos de negocio que emite el service.
+type countingMetrics struct {
+	created, updated, deleted int
+}
 
-		for _, tt := range tests {
-			t.Run(tt.name, func(t *testing.T) {
-				repository := &fakeRepo{}
-				service := NewService(repository)
+func (metrics *countingMetrics) ItemCreated() { metrics.created++ }
+func (metrics *countingMetrics) ItemUpdated() { metrics.updated++ }
+func (metrics *countingMetrics) ItemDeleted() { metrics.deleted++ }
 
-				items, total, err := service.List(context.Background(), tt.page, tt.limit, "any")
+func TestService_Metrics(t *testing.T) {
+	t.Run("successful writes are counted", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		service := NewService(&fakeRepo{}, WithMetrics(metrics))
 
-				require.ErrorIs(t, err, ErrorInvalidInput)
-				require.Nil(t, items)
-				require.Zero(t, total)
-				require.False(t, repository.listCalled, "repo.List should not be called")
-				require.False(t, repository.countCalled, "repo.Count should not be called")
-			})
-		}
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 1})
+		require.NoError(t, err)
+		_, err = service.Update(context.Background(), "id", UpdateItemInput{Stock: integerPointer(2)})
+		require.NoError(t, err)
+		_, err = service.Delete(context.Background(), "id", nil)
+		require.NoError(t, err)
+
+		require.Equal(t, &countingMetrics{created: 1, updated: 1, deleted: 1}, metrics)
 	})
 
-	t.Run("list error", func(t *testing.T) {
-		repository := &fakeRepo{listErr: errors.New("list failed")}
-		service := NewService(repository)
+	t.Run("failed writes are not counted", func(t *testing.T) {
+		metrics := &countingMetrics{}
+		repository := &fakeRepo{insertErr: ErrorDuplicateName, updateErr: ErrorNotFound, deleteErr: ErrorNotFound}
+		service := NewService(repository, WithMetrics(metrics))
 
-		items, total, err := service.List(context.Background(), 1, 10, "  test  ")
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 1})
+		require.Error(t, err)
+		_, err = service.Update(context.Background(), "id", UpdateItemInput{Stock: integerPointer(2)})
+		require.Error(t, err)
+		_, err = service.Delete(context.Background(), "id", nil)
+		require.Error(t, err)
 
-		require.ErrorIs(t, err, repository.listErr)
-		require.Nil(t, items)
-		require.Zero(t, total)
-		require.True(t, repository.listCalled, "repo.List should be called")
-		require.False(t, repository.countCalled, "repo.Count should not be called on list error")
+		require.Equal(t, &countingMetrics{}, metrics)
 	})
+}
 
-	t.Run("count error", func(t *testing.T) {
-		repository := &fakeRepo{
-			listItems: []Item{{ID: "1", Name: "a"}},
-			countErr:  errors.New("count failed"),
-		}
-		service := NewService(repository)
+// recordingEvents guarda los eventos publicados, en orden.
+type recordingEvents struct {
+	published []Event
+}
 
-		items, total, err := service.List(context.Background(), 2, 5, "  test  ")
+func (events *recordingEvents) Publish(event Event) {
+	events.published = append(events.published, event)
+}
 
-		require.ErrorIs(t, err, repository.countErr)
-		require.Nil(t, items)
-		require.Zero(t, total)
-		require.True(t, repository.listCalled, "repo.List should be called")
-		require.True(t, repository.countCalled, "repo.Count should be called")
-		require.Equal(t, "test", repository.listQuery, "expected trimmed query")
-		require.Equal(t, 5, repository.listLimit)
-		require.Equal(t, 5, repository.listOffset)
-		require.Equal(t, "test", repository.countQuery, "expected trimmed query")
+func TestService_Events(t *testing.T) {
+	t.Run("successful writes publish the item after the change", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{deleteManyDeleted: []string{"id-3"}}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 1})
+		require.NoError(t, err)
+		_, err = service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(2)})
+		require.NoError(t, err)
+		_, err = service.Delete(context.Background(), "id-2", nil)
+		require.NoError(t, err)
+		_, err = service.DeleteMany(context.Background(), []string{"id-3"})
+		require.NoError(t, err)
+
+		require.Len(t, events.published, 4)
+		require.Equal(t, EventCreated, events.published[0].Operation)
+		require.Equal(t, "x", events.published[0].ID)
+		require.Equal(t, "Phone", events.published[0].Item.Name)
+		require.Equal(t, EventUpdated, events.published[1].Operation)
+		require.Equal(t, "id-1", events.published[1].ID)
+		require.NotNil(t, events.published[1].Item)
+		require.Equal(t, Event{ID: "id-2", Operation: EventDeleted, Item: &Item{ID: "id-2"}}, events.published[2])
+		require.Equal(t, Event{ID: "id-3", Operation: EventDeleted}, events.published[3], "bulk deletes only know the id")
 	})
 
-	t.Run("success", func(t *testing.T) {
-		expectedItems := []Item{
-			{ID: "1", Name: "a"},
-			{ID: "2", Name: "b"},
-		}
-		repository := &fakeRepo{
-			listItems: expectedItems,
-			countTotal: 2,
-		}
-		service := NewService(repository)
+	t.Run("failed writes publish nothing", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{insertErr: ErrorDuplicateName, updateErr: ErrorNotFound, deleteErr: ErrorNotFound}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 1})
+		require.Error(t, err)
+		_, err = service.Update(context.Background(), "id-1", UpdateItemInput{Stock: integerPointer(2)})
+		require.Error(t, err)
+		_, err = service.Delete(context.Background(), "id-2", nil)
+		require.Error(t, err)
+
+		require.Empty(t, events.published)
+	})
 
-		items, total, err := service.List(context.Background(), 3, 10, "  name  ")
+	t.Run("every publisher receives each event", func(t *testing.T) {
+		first, second := &recordingEvents{}, &recordingEvents{}
+		service := NewService(&fakeRepo{}, WithEventPublisher(first), WithEventPublisher(second))
 
+		_, err := service.Delete(context.Background(), "id-2", nil)
 		require.NoError(t, err)
-		require.Equal(t, expectedItems, items)
-		require.Equal(t, 2, total)
-		require.True(t, repository.listCalled, "repo.List should be called")
-		require.True(t, repository.countCalled, "repo.Count should be called")
-		require.Equal(t, "name", repository.listQuery, "expected trimmed query")
-		require.Equal(t, 10, repository.listLimit)
-		require.Equal(t, 20, repository.listOffset)
-		require.Equal(t, "name", repository.countQuery, "expected trimmed query")
+
+		require.Len(t, first.published, 1)
+		require.Equal(t, first.published, second.published)
 	})
 }
 
-func TestService_Get(t *testing.T) {
-	t.Run("not found maps to domain error", func(t *testing.T) {
-		repository := &fakeRepo{
-			getErr: fmt.Errorf("wrapped: %w", pgx.ErrNoRows),
-		}
-		service := NewService(repository)
+func TestService_Outbox(t *testing.T) {
+	t.Run("writes are recorded in their transaction", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{deleteManyDeleted: []string{"id-3"}}
+		service := NewService(repository, WithOutbox(true), WithEventPublisher(events))
 
-		item, err := service.Get(context.Background(), "id-1")
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", SKU: stringPointer("SKU-001"), Price: "10.00", Stock: 1})
+		require.NoError(t, err)
+		_, err = service.Update(context.Background(), "id-1", UpdateItemInput{Name: stringPointer("Tablet")})
+		require.NoError(t, err)
+		_, err = service.DeleteMany(context.Background(), []string{"id-3"})
+		require.NoError(t, err)
 
-		require.ErrorIs(t, err, ErrorNotFound)
-		require.Equal(t, Item{}, item)
-		require.True(t, repository.getCalled, "repo.GetByID should be called")
-		require.Equal(t, "id-1", repository.getID)
+		require.True(t, repository.inTxCalled)
+		require.Len(t, repository.outbox, 3)
+		require.Equal(t, EventCreated, repository.outbox[0].Operation)
+		require.Equal(t, "Phone", repository.outbox[0].Item.Name)
+		require.Equal(t, EventUpdated, repository.outbox[1].Operation)
+		require.Equal(t, Event{ID: "id-3", Operation: EventDeleted}, repository.outbox[2])
+		require.Equal(t, repository.outbox, events.published, "publishers still get the events after the commit")
 	})
 
-	t.Run("repo error is returned", func(t *testing.T) {
-		errorDatabase := errors.New("db failed")
-		repository := &fakeRepo{
-			getErr: errorDatabase,
-		}
-		service := NewService(repository)
+	t.Run("a failed outbox write fails the change", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{outboxErr: errors.New("outbox down")}
+		service := NewService(repository, WithOutbox(true), WithEventPublisher(events))
 
-		item, err := service.Get(context.Background(), "id-2")
+		_, err := service.Delete(context.Background(), "id-2", nil)
 
-		require.ErrorIs(t, err, errorDatabase)
-		require.True(t, err == errorDatabase, "expected same error instance")
-		require.Equal(t, Item{}, item)
-		require.True(t, repository.getCalled, "repo.GetByID should be called")
-		require.Equal(t, "id-2", repository.getID)
+		require.Error(t, err)
+		require.Empty(t, events.published)
 	})
 
-	t.Run("success", func(t *testing.T) {
-		expected := Item{ID: "x", Name: "ok", Price: "1.00", Stock: 2}
-		repository := &fakeRepo{
-			getItem: expected,
-		}
+	t.Run("disabled by default", func(t *testing.T) {
+		repository := &fakeRepo{}
 		service := NewService(repository)
 
-		item, err := service.Get(context.Background(), "id-3")
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Name: stringPointer("Tablet")})
 
 		require.NoError(t, err)
-		require.Equal(t, expected, item)
-		require.True(t, repository.getCalled, "repo.GetByID should be called")
-		require.Equal(t, "id-3", repository.getID)
+		require.Empty(t, repository.outbox)
 	})
 }
 
-func TestService_Update(t *testing.T) {
-	t.Run("requires at least one field", func(t *testing.T) {
-		repository := &fakeRepo{}
+// recordingBus guarda lo publicado en el bus.
+type recordingBus struct {
+	published []events.Event
+	err       error
+}
+
+func (bus *recordingBus) Publish(ctx context.Context, event events.Event) error {
+	bus.published = append(bus.published, event)
+	return bus.err
+}
+
+func (bus *recordingBus) Close() error { return nil }
+
+func TestService_ChangeNotifications(t *testing.T) {
+	t.Run("writes notify in their transaction", func(t *testing.T) {
+		repository := &fakeRepo{deleteManyDeleted: []string{"id-3"}}
 		service := NewService(repository)
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{})
-		require.ErrorIs(t, err, ErrorInvalidInput)
-		require.False(t, repository.updateCalled, "repo.Update should not be called when payload has no fields")
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Name: stringPointer("Tablet")})
+		require.NoError(t, err)
+		_, err = service.DeleteMany(context.Background(), []string{"id-3"})
+		require.NoError(t, err)
+
+		require.True(t, repository.inTxCalled)
+		require.Len(t, repository.notified, 2)
+		require.Equal(t, EventUpdated, repository.notified[0].Operation)
+		require.Equal(t, Event{ID: "id-3", Operation: EventDeleted}, repository.notified[1])
 	})
 
-	t.Run("invalid name empty after trim", func(t *testing.T) {
-		repository := &fakeRepo{}
-		service := NewService(repository)
+	t.Run("collection version is served from the cache", func(t *testing.T) {
+		cache := NewCollectionVersionCache()
+		cache.Listening()
+		repository := &fakeRepo{version: CollectionVersion{Count: 3}}
+		service := NewService(repository, WithCollectionVersionCache(cache), WithEventPublisher(cache))
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Name: stringPointer("   "),
-		})
-		require.ErrorIs(t, err, ErrorInvalidInput)
-		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+		version, err := service.CollectionVersion(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 3, version.Count)
+
+		repository.version = CollectionVersion{Count: 4}
+		version, err = service.CollectionVersion(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 3, version.Count, "cached until something changes")
+
+		_, err = service.Delete(context.Background(), "id-2", nil)
+		require.NoError(t, err)
+		version, err = service.CollectionVersion(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 4, version.Count, "a local write invalidates right away")
 	})
+}
 
-	t.Run("invalid price empty after trim", func(t *testing.T) {
-		repository := &fakeRepo{}
-		service := NewService(repository)
+func TestService_Bus(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Price: stringPointer("   "),
-		})
-		require.ErrorIs(t, err, ErrorInvalidInput)
-		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+	t.Run("without outbox the service publishes after the change", func(t *testing.T) {
+		bus := &recordingBus{}
+		service := NewService(&fakeRepo{}, WithBus(bus))
+		service.now = func() time.Time { return now }
+
+		_, err := service.Update(context.Background(), "id-1", UpdateItemInput{Name: stringPointer("Tablet")})
+
+		require.NoError(t, err)
+		require.Len(t, bus.published, 1)
+		published := bus.published[0]
+		require.Equal(t, events.SchemaVersion, published.SchemaVersion)
+		require.Equal(t, "item.updated", published.Type)
+		require.Equal(t, now, published.OccurredAt)
+		var data Event
+		require.NoError(t, json.Unmarshal(published.Data, &data))
+		require.Equal(t, "id-1", data.ID)
+		require.NotNil(t, data.Item, "the payload carries the item snapshot")
 	})
 
-	t.Run("invalid price format", func(t *testing.T) {
+	t.Run("a bus error does not fail the change", func(t *testing.T) {
+		service := NewService(&fakeRepo{}, WithBus(&recordingBus{err: errors.New("bus down")}))
+
+		_, err := service.Delete(context.Background(), "id-2", nil)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("with outbox the relay publishes instead", func(t *testing.T) {
+		bus := &recordingBus{}
 		repository := &fakeRepo{}
-		service := NewService(repository)
+		service := NewService(repository, WithOutbox(true), WithBus(bus))
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Price: stringPointer("0"),
-		})
-		require.ErrorIs(t, err, ErrorInvalidInput)
-		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+		_, err := service.Delete(context.Background(), "id-2", nil)
+
+		require.NoError(t, err)
+		require.Len(t, repository.outbox, 1)
+		require.Empty(t, bus.published)
 	})
+}
 
-	t.Run("invalid stock negative", func(t *testing.T) {
+func TestService_Audit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+
+	t.Run("create records every field", func(t *testing.T) {
 		repository := &fakeRepo{}
 		service := NewService(repository)
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Stock: integerPointer(-1),
-		})
-		require.ErrorIs(t, err, ErrorInvalidInput)
-		require.False(t, repository.updateCalled, "repo.Update should not be called on invalid input")
+		_, err := service.Create(ctx, CreateItemInput{Name: "Lamp", SKU: stringPointer("SKU-001"), Price: "10.00"})
+
+		require.NoError(t, err)
+		require.Len(t, repository.audit, 1)
+		require.Equal(t, AuditCreated, repository.audit[0].Action)
+		require.Equal(t, "req-1", repository.audit[0].RequestID)
+		require.Equal(t, FieldChange{Field: "name", Old: jsonNull, New: json.RawMessage(`"Lamp"`)}, repository.audit[0].Changes[0])
 	})
 
-	t.Run("repo not found maps to domain error", func(t *testing.T) {
-		repository := &fakeRepo{
-			updateErr: fmt.Errorf("wrapped: %w", ErrorNotFound),
-		}
+	t.Run("patch records the diff against the locked item", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Lamp", Price: "10.00"}, updateItem: Item{ID: "id-1", Name: "Desk lamp", Price: "10.00"}}
 		service := NewService(repository)
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Name: stringPointer("name"),
-		})
-		require.ErrorIs(t, err, ErrorNotFound)
-		require.True(t, repository.updateCalled, "repo.Update should be called")
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Name: stringPointer("Desk lamp")})
+
+		require.NoError(t, err)
+		require.True(t, repository.getForUpdateCalled)
+		require.Equal(t, []AuditEntry{{
+			ItemID:    "id-1",
+			Action:    AuditUpdated,
+			Changes:   []FieldChange{{Field: "name", Old: json.RawMessage(`"Lamp"`), New: json.RawMessage(`"Desk lamp"`)}},
+			RequestID: "req-1",
+		}}, repository.audit)
 	})
 
-	t.Run("repo duplicate name maps to domain error", func(t *testing.T) {
-		repository := &fakeRepo{
-			updateErr: fmt.Errorf("wrapped: %w", ErrorDuplicateName),
-		}
+	t.Run("patch with the same values is not recorded", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Lamp"}, updateItem: Item{ID: "id-1", Name: "Lamp", Version: 2}}
 		service := NewService(repository)
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Name: stringPointer("name"),
-		})
-		require.ErrorIs(t, err, ErrorDuplicateName)
-		require.True(t, repository.updateCalled, "repo.Update should be called")
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Name: stringPointer("Lamp")})
+
+		require.NoError(t, err)
+		require.Empty(t, repository.audit)
 	})
 
-	t.Run("repo error is returned", func(t *testing.T) {
-		errDB := errors.New("db failed")
-		repository := &fakeRepo{
-			updateErr: errDB,
-		}
+	t.Run("stock adjustment records the stock change", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Stock: 2}, updateItem: Item{ID: "id-1", Stock: 5}}
 		service := NewService(repository)
 
-		_, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Name: stringPointer("name"),
-		})
-		require.ErrorIs(t, err, errDB)
-		require.True(t, err == errDB, "expected same error instance")
-		require.True(t, repository.updateCalled, "repo.Update should be called")
+		_, err := service.AdjustStock(ctx, "id-1", StockAdjustmentInput{Delta: 3})
+
+		require.NoError(t, err)
+		require.Equal(t, []FieldChange{{Field: "stock", Old: json.RawMessage(`2`), New: json.RawMessage(`5`)}}, repository.audit[0].Changes)
 	})
 
-	t.Run("success trims fields and returns item", func(t *testing.T) {
-		expected := Item{ID: "x", Name: "ok", Price: "1.00", Stock: 2}
-		repository := &fakeRepo{
-			updateItem: expected,
-		}
+	t.Run("deletes are recorded without changes", func(t *testing.T) {
+		repository := &fakeRepo{deleteManyDeleted: []string{"id-3"}}
 		service := NewService(repository)
 
-		item, err := service.Update(context.Background(), "id", UpdateItemInput{
-			Name:  stringPointer("  name  "),
-			Price: stringPointer(" 10.00 "),
-			Stock: integerPointer(2),
-		})
+		_, err := service.Delete(ctx, "id-2", nil)
 		require.NoError(t, err)
-		require.Equal(t, expected, item)
-		require.True(t, repository.updateCalled, "repo.Update should be called")
-		require.Equal(t, "id", repository.updateID)
-		require.NotNil(t, repository.updateInput.Name)
-		require.Equal(t, "name", *repository.updateInput.Name)
-		require.NotNil(t, repository.updateInput.Price)
-		require.Equal(t, "10.00", *repository.updateInput.Price)
-		require.NotNil(t, repository.updateInput.Stock)
-		require.Equal(t, 2, *repository.updateInput.Stock)
+		_, err = service.DeleteMany(ctx, []string{"id-3", "id-4"})
+		require.NoError(t, err)
+
+		require.Equal(t, []AuditEntry{
+			{ItemID: "id-2", Action: AuditDeleted, Changes: []FieldChange{}, RequestID: "req-1"},
+			{ItemID: "id-3", Action: AuditDeleted, Changes: []FieldChange{}, RequestID: "req-1"},
+		}, repository.audit)
 	})
-}
 
-func TestService_Delete(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		repository := &fakeRepo{}
+	t.Run("a failed audit write fails the change", func(t *testing.T) {
+		events := &recordingEvents{}
+		repository := &fakeRepo{getItem: Item{ID: "id-1", Name: "Lamp"}, updateItem: Item{ID: "id-1", Name: "Desk lamp"}, auditErr: errors.New("audit down")}
+		service := NewService(repository, WithEventPublisher(events))
+
+		_, err := service.Update(ctx, "id-1", UpdateItemInput{Name: stringPointer("Desk lamp")})
+
+		require.EqualError(t, err, "audit down")
+		require.Empty(t, events.published)
+	})
+
+	t.Run("page of an existing item", func(t *testing.T) {
+		repository := &fakeRepo{getItem: Item{ID: "id-1"}, listAudit: []AuditEntry{{ID: 1, ItemID: "id-1", Action: AuditCreated}}}
 		service := NewService(repository)
 
-		err := service.Delete(context.Background(), "id")
+		page, err := service.Audit(context.Background(), "id-1", 3, 10)
 
 		require.NoError(t, err)
-		require.True(t, repository.deleteCalled, "repo.Delete should be called")
-		require.Equal(t, "id", repository.deleteID)
+		require.True(t, repository.inSnapshotCalled)
+		require.Equal(t, 20, repository.listMovementsAt)
+		require.Equal(t, AuditPage{Entries: repository.listAudit, Total: 1}, page)
 	})
 
-	t.Run("repo error is returned", func(t *testing.T) {
-		errorFromDatabase := errors.New("delete failed")
-		repository := &fakeRepo{deleteErr: errorFromDatabase}
+	t.Run("missing item", func(t *testing.T) {
+		repository := &fakeRepo{getErr: pgx.ErrNoRows}
 		service := NewService(repository)
 
-		err := service.Delete(context.Background(), "id-2")
+		_, err := service.Audit(context.Background(), "missing", 1, 10)
 
-		require.ErrorIs(t, err, errorFromDatabase)
-		require.True(t, err == errorFromDatabase, "expected same error instance")
-		require.True(t, repository.deleteCalled, "repo.Delete should be called")
-		require.Equal(t, "id-2", repository.deleteID)
+		require.ErrorIs(t, err, ErrorNotFound)
 	})
 }
 
@@ -546,3 +5509,7 @@ func stringPointer(value string) *string {
 func integerPointer(value int) *int {
 	return &value
 }
+
+func statePointer(state ItemState) *ItemState {
+	return &state
+}
@@ -0,0 +1,96 @@
+package items
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxSlugLength es el largo máximo de un slug, incluido el sufijo de colisión.
+const maxSlugLength = 120
+
+// slugPattern acepta minúsculas, dígitos y guiones simples entre palabras ("wireless-keyboard-2").
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slugAccents traduce las letras acentuadas más comunes a su versión sin acento,
+// así "Teclado Inalámbrico" queda "teclado-inalambrico" y no "teclado-inal-mbrico".
+var slugAccents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n", "ç", "c",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u", "â", "a", "ê", "e", "î", "i", "ô", "o", "û", "u",
+	"ä", "a", "ë", "e", "ï", "i", "ö", "o", "ã", "a", "õ", "o",
+)
+
+// isValidSlug indica si slug cumple el formato y el largo máximo.
+func isValidSlug(slug string) bool {
+	return len(slug) <= maxSlugLength && slugPattern.MatchString(slug)
+}
+
+// Slugify arma un slug a partir de name con las mismas reglas que los items. Lo usan otros
+// recursos del catálogo (categorías) para que todos los slugs tengan el mismo formato.
+func Slugify(name string) string {
+	return slugify(name)
+}
+
+// IsValidSlug indica si slug cumple el formato de slug de los items.
+func IsValidSlug(slug string) bool {
+	return isValidSlug(slug)
+}
+
+// slugify arma el slug base a partir del nombre: minúsculas, sin acentos y con un guion
+// en lugar de cada tramo de caracteres que no sean letras o dígitos.
+// Si el nombre no tiene ningún carácter utilizable devuelve "item".
+func slugify(name string) string {
+	name = slugAccents.Replace(strings.ToLower(name))
+
+	var builder strings.Builder
+	pendingHyphen := false
+	for _, character := range name {
+		if (character >= 'a' && character <= 'z') || (character >= '0' && character <= '9') {
+			if pendingHyphen && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			pendingHyphen = false
+			builder.WriteRune(character)
+			continue
+		}
+		pendingHyphen = true
+	}
+
+	slug := builder.String()
+	if slug == "" {
+		return "item"
+	}
+	return truncateSlug(slug, maxSlugLength)
+}
+
+// withSlugSuffix agrega el sufijo de colisión ("-2", "-3", ...) recortando la base si hace falta
+// para no pasar de maxSlugLength.
+func withSlugSuffix(base string, suffix int) string {
+	if suffix < 2 {
+		return base
+	}
+	tail := "-" + strconv.Itoa(suffix)
+	return truncateSlug(base, maxSlugLength-len(tail)) + tail
+}
+
+// nextFreeSlug elige base o, si está tomado, el primer base-N libre (N >= 2).
+func nextFreeSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, slug := range taken {
+		used[slug] = true
+	}
+	for suffix := 1; ; suffix++ {
+		candidate := withSlugSuffix(base, suffix)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// truncateSlug corta el slug en limit bytes sin dejar un guion al final.
+func truncateSlug(slug string, limit int) string {
+	if len(slug) > limit {
+		slug = slug[:limit]
+	}
+	return strings.TrimRight(slug, "-")
+}
@@ -0,0 +1,56 @@
+package items
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"lowercases and joins words", "Wireless Keyboard", "wireless-keyboard"},
+		{"drops accents", "Teclado Inalámbrico Ñandú", "teclado-inalambrico-nandu"},
+		{"collapses punctuation", "  USB-C -- Cable (2m)!  ", "usb-c-cable-2m"},
+		{"keeps digits", "iPhone 15 Pro", "iphone-15-pro"},
+		{"nothing usable", "¡¿!?", "item"},
+		{"truncates without trailing hyphen", strings.Repeat("a", 119) + " b", strings.Repeat("a", 119)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slug := slugify(tt.input)
+
+			require.Equal(t, tt.want, slug)
+			require.True(t, isValidSlug(slug))
+		})
+	}
+}
+
+func TestIsValidSlug(t *testing.T) {
+	require.True(t, isValidSlug("wireless-keyboard-2"))
+	require.False(t, isValidSlug(""))
+	require.False(t, isValidSlug("Wireless-Keyboard"))
+	require.False(t, isValidSlug("wireless_keyboard"))
+	require.False(t, isValidSlug("-keyboard"))
+	require.False(t, isValidSlug("wireless--keyboard"))
+	require.False(t, isValidSlug(strings.Repeat("a", maxSlugLength+1)))
+}
+
+func TestNextFreeSlug(t *testing.T) {
+	require.Equal(t, "keyboard", nextFreeSlug("keyboard", nil))
+	require.Equal(t, "keyboard-2", nextFreeSlug("keyboard", []string{"keyboard"}))
+	require.Equal(t, "keyboard-3", nextFreeSlug("keyboard", []string{"keyboard", "keyboard-2", "keyboard-4"}))
+	// base-N de otro producto no bloquea la base.
+	require.Equal(t, "keyboard", nextFreeSlug("keyboard", []string{"keyboard-2"}))
+
+	long := strings.Repeat("a", maxSlugLength)
+	suffixed := nextFreeSlug(long, []string{long})
+	require.Len(t, suffixed, maxSlugLength)
+	require.True(t, strings.HasSuffix(suffixed, "-2"))
+	require.True(t, isValidSlug(suffixed))
+}
@@ -0,0 +1,58 @@
+package items
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	"github.com/Lelo88/catalog-api-golang/internal/currency"
+)
+
+// maxTaxRateBPS es la alícuota máxima: 10000 puntos básicos son el 100%.
+const maxTaxRateBPS = 10_000
+
+// taxRateError valida la alícuota de un alta o un PATCH y devuelve el error de campo, o nil si es válida.
+func taxRateError(bps int) error {
+	if bps < 0 || bps > maxTaxRateBPS {
+		return &ValidationError{Field: "tax_rate_bps", Message: fmt.Sprintf("tax_rate_bps must be between 0 and %d", maxTaxRateBPS)}
+	}
+	return nil
+}
+
+// priceWithTax calcula price * (1 + bps/10000) con aritmética exacta y lo redondea a los decimales
+// de la moneda, con las mitades hacia arriba (19% sobre 0.99 es 1.1781 y queda "1.18"). El resultado
+// tiene siempre dos decimales, como price::text: en JPY 999 al 19% es "1189.00".
+func priceWithTax(price string, bps int, code string) (string, error) {
+	net, ok := new(big.Rat).SetString(price)
+	if !ok {
+		return "", fmt.Errorf("invalid price %q", price)
+	}
+	gross := net.Mul(net, big.NewRat(int64(maxTaxRateBPS+bps), maxTaxRateBPS))
+	// FloatString redondea las mitades alejándose del cero; los precios nunca son negativos.
+	rounded, _ := new(big.Rat).SetString(gross.FloatString(currency.Decimals(code)))
+	return rounded.FloatString(2), nil
+}
+
+// taxRateDestination es el destino de Scan de tax_rate_bps: guarda la alícuota y calcula
+// PriceWithTax. itemColumns la deja después de price y currency, que ya están escaneados.
+type taxRateDestination struct {
+	item *Item
+}
+
+// Scan implementa sql.Scanner. NULL (la columna es NOT NULL, no debería llegar) deja ambos campos en cero.
+func (destination taxRateDestination) Scan(src any) error {
+	var bps sql.NullInt64
+	if err := bps.Scan(src); err != nil {
+		return err
+	}
+	if !bps.Valid {
+		destination.item.TaxRateBPS, destination.item.PriceWithTax = 0, ""
+		return nil
+	}
+	gross, err := priceWithTax(destination.item.Price, int(bps.Int64), destination.item.Currency)
+	if err != nil {
+		return err
+	}
+	destination.item.TaxRateBPS, destination.item.PriceWithTax = int(bps.Int64), gross
+	return nil
+}
@@ -0,0 +1,86 @@
+package items
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceWithTax(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    string
+		bps      int
+		currency string
+		expected string
+	}{
+		{"19% on 0.99 rounds up", "0.99", 1900, "USD", "1.18"},
+		{"half cent rounds up", "10.50", 1900, "USD", "12.50"},
+		{"below half cent rounds down", "0.10", 1900, "USD", "0.12"},
+		{"5% on 0.10 is exactly half", "0.10", 500, "EUR", "0.11"},
+		{"21% on 19.99", "19.99", 2100, "EUR", "24.19"},
+		{"basis points below one percent", "100.00", 1, "USD", "100.01"},
+		{"zero rate keeps the price", "19.99", 0, "USD", "19.99"},
+		{"full rate doubles the price", "0.99", 10000, "USD", "1.98"},
+		{"zero price", "0.00", 1900, "USD", "0.00"},
+		{"largest price", "99999999.99", 10000, "USD", "199999999.98"},
+		{"price without decimals", "10", 1900, "USD", "11.90"},
+		{"JPY rounds to yen", "999.00", 1900, "JPY", "1189.00"},
+		{"JPY half yen rounds up", "10.00", 500, "JPY", "11.00"},
+		{"JPY below half yen rounds down", "10.00", 400, "JPY", "10.00"},
+		{"unknown currency uses cents", "0.99", 1900, "", "1.18"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gross, err := priceWithTax(tt.price, tt.bps, tt.currency)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, gross)
+		})
+	}
+
+	t.Run("invalid price", func(t *testing.T) {
+		_, err := priceWithTax("abc", 1900, "USD")
+
+		require.Error(t, err)
+	})
+}
+
+func TestTaxRateError(t *testing.T) {
+	for _, bps := range []int{0, 1, 1900, maxTaxRateBPS} {
+		require.NoError(t, taxRateError(bps))
+	}
+	for _, bps := range []int{-1, maxTaxRateBPS + 1} {
+		var validationError *ValidationError
+		require.ErrorAs(t, taxRateError(bps), &validationError)
+		require.Equal(t, "tax_rate_bps", validationError.Field)
+		require.Equal(t, "tax_rate_bps must be between 0 and 10000", validationError.Message)
+	}
+}
+
+func TestTaxRateDestination(t *testing.T) {
+	t.Run("computes the price with tax from the scanned price and currency", func(t *testing.T) {
+		item := Item{Price: "0.99", Currency: "USD"}
+
+		require.NoError(t, taxRateDestination{&item}.Scan(int64(1900)))
+
+		require.Equal(t, 1900, item.TaxRateBPS)
+		require.Equal(t, "1.18", item.PriceWithTax)
+	})
+
+	t.Run("null leaves both fields empty", func(t *testing.T) {
+		item := Item{Price: "0.99", TaxRateBPS: 1900, PriceWithTax: "1.18"}
+
+		require.NoError(t, taxRateDestination{&item}.Scan(nil))
+
+		require.Zero(t, item.TaxRateBPS)
+		require.Empty(t, item.PriceWithTax)
+	})
+
+	t.Run("invalid price", func(t *testing.T) {
+		item := Item{Price: "abc"}
+
+		require.Error(t, taxRateDestination{&item}.Scan(int64(1900)))
+	})
+}
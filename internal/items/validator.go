@@ -0,0 +1,67 @@
+package items
+
+import (
+	"context"
+	"regexp"
+)
+
+// Validator permite agregar reglas de negocio propias de cada deployment
+// (palabras prohibidas, precios mínimos por categoría, formato de SKU, etc.) sin tocar el service.
+// Los validators corren después de la validación base, en el orden en que se registraron,
+// y el primero que falla corta la ejecución.
+//
+// Un *ValidationError se devuelve al cliente como 400 con detalle por campo;
+// cualquier otro error se trata como falla interna (500).
+type Validator interface {
+	ValidateCreate(ctx context.Context, input CreateItemInput) error
+	ValidateUpdate(ctx context.Context, id string, input UpdateItemInput) error
+}
+
+// ValidationError describe un error de validación atado a un campo del payload.
+// Envuelve ErrorInvalidInput, así que errors.Is(err, ErrorInvalidInput) es true.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implementa error.
+func (validationError *ValidationError) Error() string {
+	return validationError.Field + ": " + validationError.Message
+}
+
+// Unwrap permite que errors.Is reconozca el error como ErrorInvalidInput.
+func (validationError *ValidationError) Unwrap() error {
+	return ErrorInvalidInput
+}
+
+// NameBlacklistValidator rechaza nombres que matchean una expresión regular.
+// Es el validator de ejemplo; se habilita con ITEM_NAME_BLACKLIST_PATTERN.
+type NameBlacklistValidator struct {
+	pattern *regexp.Regexp
+}
+
+// NewNameBlacklistValidator crea el validator a partir de una regex ya compilada.
+// Para ignorar mayúsculas usá el flag (?i) en el patrón.
+func NewNameBlacklistValidator(pattern *regexp.Regexp) *NameBlacklistValidator {
+	return &NameBlacklistValidator{pattern: pattern}
+}
+
+// ValidateCreate implementa Validator.
+func (validator *NameBlacklistValidator) ValidateCreate(ctx context.Context, input CreateItemInput) error {
+	return validator.validateName(input.Name)
+}
+
+// ValidateUpdate implementa Validator. Solo valida si el PATCH cambia el nombre.
+func (validator *NameBlacklistValidator) ValidateUpdate(ctx context.Context, id string, input UpdateItemInput) error {
+	if input.Name == nil {
+		return nil
+	}
+	return validator.validateName(*input.Name)
+}
+
+func (validator *NameBlacklistValidator) validateName(name string) error {
+	if validator.pattern.MatchString(name) {
+		return &ValidationError{Field: "name", Message: "name contains a blocked word"}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingValidator registra el orden de ejecución y devuelve el error configurado.
+type recordingValidator struct {
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (validator *recordingValidator) ValidateCreate(ctx context.Context, input CreateItemInput) error {
+	*validator.calls = append(*validator.calls, validator.name+":create")
+	return validator.err
+}
+
+func (validator *recordingValidator) ValidateUpdate(ctx context.Context, id string, input UpdateItemInput) error {
+	*validator.calls = append(*validator.calls, validator.name+":update")
+	return validator.err
+}
+
+func TestService_Validators(t *testing.T) {
+	validInput := CreateItemInput{Name: "Phone", SKU: stringPointer("PH-001"), Price: "10.00", Stock: 1}
+
+	t.Run("run in order after built-in validation", func(t *testing.T) {
+		var calls []string
+		repository := &fakeRepo{}
+		service := NewService(repository, WithValidators(
+			&recordingValidator{name: "first", calls: &calls},
+			&recordingValidator{name: "second", calls: &calls},
+		))
+
+		_, err := service.Create(context.Background(), validInput)
+		require.NoError(t, err)
+		_, err = service.Update(context.Background(), "id", UpdateItemInput{Stock: integerPointer(2)})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"first:create", "second:create", "first:update", "second:update"}, calls)
+		require.True(t, repository.insertCalled)
+		require.True(t, repository.updateCalled)
+	})
+
+	t.Run("not called when built-in validation fails", func(t *testing.T) {
+		var calls []string
+		service := NewService(&fakeRepo{}, WithValidators(&recordingValidator{name: "hook", calls: &calls}))
+
+		_, err := service.Create(context.Background(), CreateItemInput{Name: "Phone", Price: "0", Stock: 1})
+
+		require.ErrorIs(t, err, ErrorInvalidPrice)
+		require.Empty(t, calls)
+	})
+
+	t.Run("short-circuit on first failure", func(t *testing.T) {
+		var calls []string
+		repository := &fakeRepo{}
+		failure := &ValidationError{Field: "name", Message: "not allowed"}
+		service := NewService(repository, WithValidators(
+			&recordingValidator{name: "first", calls: &calls, err: failure},
+			&recordingValidator{name: "second", calls: &calls},
+		))
+
+		_, err := service.Create(context.Background(), validInput)
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "name", validationError.Field)
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.Equal(t, []string{"first:create"}, calls)
+		require.False(t, repository.insertCalled)
+	})
+
+	t.Run("other errors become opaque internal errors", func(t *testing.T) {
+		var calls []string
+		repository := &fakeRepo{}
+		service := NewService(repository, WithValidators(
+			&recordingValidator{name: "broken", calls: &calls, err: ErrorNotFound},
+		))
+
+		_, err := service.Update(context.Background(), "id", UpdateItemInput{Name: stringPointer("Phone")})
+
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrorNotFound), "validator errors must not leak as domain errors")
+		require.False(t, errors.Is(err, ErrorInvalidInput))
+		require.False(t, repository.updateCalled)
+	})
+}
+
+func TestNameBlacklistValidator(t *testing.T) {
+	validator := NewNameBlacklistValidator(regexp.MustCompile(`(?i)\b(replica|fake)\b`))
+	ctx := context.Background()
+
+	t.Run("create", func(t *testing.T) {
+		require.NoError(t, validator.ValidateCreate(ctx, CreateItemInput{Name: "Leather wallet"}))
+
+		err := validator.ValidateCreate(ctx, CreateItemInput{Name: "Replica watch"})
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "name", validationError.Field)
+	})
+
+	t.Run("update only checks name when present", func(t *testing.T) {
+		require.NoError(t, validator.ValidateUpdate(ctx, "id", UpdateItemInput{Stock: integerPointer(1)}))
+		require.NoError(t, validator.ValidateUpdate(ctx, "id", UpdateItemInput{Name: stringPointer("Real watch")}))
+		require.Error(t, validator.ValidateUpdate(ctx, "id", UpdateItemInput{Name: stringPointer("fake watch")}))
+	})
+}
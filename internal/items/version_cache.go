@@ -0,0 +1,89 @@
+package items
+
+import (
+	"context"
+	"sync"
+)
+
+// CollectionVersionCache guarda la versión del catálogo (el ETag del listado) hasta que algo la
+// invalida: un cambio de este proceso (Publish, como EventPublisher del service) o de cualquier
+// instancia (Notify, como db.Subscriber de ChangesChannel). Solo cachea mientras el listener está
+// conectado; sin él no hay forma de enterarse de los cambios de las otras instancias.
+type CollectionVersionCache struct {
+	mutex sync.Mutex
+	// live indica si el listener está escuchando ChangesChannel.
+	live bool
+	// generation cambia con cada invalidación, para no guardar una versión leída antes de un cambio.
+	generation uint64
+	valid      bool
+	version    CollectionVersion
+}
+
+// NewCollectionVersionCache crea un cache vacío, que no cachea hasta que el listener conecta.
+func NewCollectionVersionCache() *CollectionVersionCache {
+	return &CollectionVersionCache{}
+}
+
+// Listening implementa db.Subscriber: el listener (re)conectó. Lo cacheado se descarta porque
+// pudo haber cambios mientras estaba desconectado.
+func (cache *CollectionVersionCache) Listening() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.live = true
+	cache.invalidate()
+}
+
+// Lost implementa db.Subscriber: sin listener el cache deja de usarse hasta que reconecte.
+func (cache *CollectionVersionCache) Lost() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.live = false
+	cache.invalidate()
+}
+
+// Notify implementa db.Subscriber. Cualquier cambio de item cambia la versión, así que no hace
+// falta mirar el payload.
+func (cache *CollectionVersionCache) Notify(payload string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.invalidate()
+}
+
+// Publish implementa EventPublisher: invalida apenas confirma un cambio de este proceso, sin
+// esperar a que vuelva el NOTIFY.
+func (cache *CollectionVersionCache) Publish(event Event) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.invalidate()
+}
+
+// invalidate descarta la versión guardada. Hay que tener el mutex tomado.
+func (cache *CollectionVersionCache) invalidate() {
+	cache.generation++
+	cache.valid = false
+}
+
+// get devuelve la versión cacheada o la lee con load. Lo leído se guarda solo si nada la invalidó
+// mientras tanto.
+func (cache *CollectionVersionCache) get(ctx context.Context, load func(context.Context) (CollectionVersion, error)) (CollectionVersion, error) {
+	cache.mutex.Lock()
+	if cache.live && cache.valid {
+		version := cache.version
+		cache.mutex.Unlock()
+		return version, nil
+	}
+	generation := cache.generation
+	cache.mutex.Unlock()
+
+	version, err := load(ctx)
+	if err != nil {
+		return CollectionVersion{}, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.live && cache.generation == generation {
+		cache.version, cache.valid = version, true
+	}
+	return version, nil
+}
@@ -0,0 +1,90 @@
+package items
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingLoad devuelve versiones con Count creciente y cuenta las lecturas.
+type countingLoad struct {
+	calls int
+	err   error
+}
+
+func (load *countingLoad) load(ctx context.Context) (CollectionVersion, error) {
+	load.calls++
+	return CollectionVersion{LastUpdatedAt: time.Unix(0, 0), Count: load.calls}, load.err
+}
+
+func TestCollectionVersionCache(t *testing.T) {
+	t.Run("caches only while the listener is connected", func(t *testing.T) {
+		cache := NewCollectionVersionCache()
+		load := &countingLoad{}
+
+		_, _ = cache.get(context.Background(), load.load)
+		_, _ = cache.get(context.Background(), load.load)
+		require.Equal(t, 2, load.calls, "not listening yet")
+
+		cache.Listening()
+		first, _ := cache.get(context.Background(), load.load)
+		second, _ := cache.get(context.Background(), load.load)
+		require.Equal(t, 3, load.calls)
+		require.Equal(t, first, second)
+
+		cache.Lost()
+		_, _ = cache.get(context.Background(), load.load)
+		_, _ = cache.get(context.Background(), load.load)
+		require.Equal(t, 5, load.calls, "connection lost")
+	})
+
+	t.Run("local and remote changes invalidate", func(t *testing.T) {
+		cache := NewCollectionVersionCache()
+		cache.Listening()
+		load := &countingLoad{}
+
+		_, _ = cache.get(context.Background(), load.load)
+		cache.Publish(Event{ID: "id-1", Operation: EventUpdated})
+		version, _ := cache.get(context.Background(), load.load)
+		require.Equal(t, 2, version.Count)
+
+		cache.Notify(`{"id":"id-2","op":"deleted"}`)
+		version, _ = cache.get(context.Background(), load.load)
+		require.Equal(t, 3, version.Count)
+	})
+
+	t.Run("a version read before a change is not kept", func(t *testing.T) {
+		cache := NewCollectionVersionCache()
+		cache.Listening()
+		calls := 0
+		load := func(ctx context.Context) (CollectionVersion, error) {
+			calls++
+			if calls == 1 {
+				// Llega un cambio mientras se lee la versión.
+				cache.Notify(`{"id":"id-1","op":"updated"}`)
+			}
+			return CollectionVersion{Count: calls}, nil
+		}
+
+		_, _ = cache.get(context.Background(), load)
+		version, _ := cache.get(context.Background(), load)
+
+		require.Equal(t, 2, version.Count)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		cache := NewCollectionVersionCache()
+		cache.Listening()
+		load := &countingLoad{err: errors.New("db down")}
+
+		_, err := cache.get(context.Background(), load.load)
+		require.Error(t, err)
+		load.err = nil
+		_, err = cache.get(context.Background(), load.load)
+		require.NoError(t, err)
+		require.Equal(t, 2, load.calls)
+	})
+}
@@ -0,0 +1,239 @@
+package items
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// xlsxMaxSheetRows es el máximo de filas de una hoja de Excel, encabezado incluido.
+const xlsxMaxSheetRows = 1_048_576
+
+// Estilos de celda del xlsx: la posición de cada xf en xlsxStyles.
+const (
+	xlsxStyleDefault  = 0
+	xlsxStyleMoney    = 1
+	xlsxStyleDateTime = 2
+	xlsxStyleDate     = 3
+	xlsxStyleHeader   = 4
+)
+
+// xlsxMoneyFields son los campos de Item que se escriben como número con dos decimales: en JSON son
+// strings para no perder precisión, pero en una planilla tienen que poder sumarse.
+var xlsxMoneyFields = map[string]bool{"price": true, "sale_price": true, "effective_price": true, "price_with_tax": true}
+
+// xlsxDateTimeFields son los timestamps de Item, que se escriben como fecha y hora en UTC.
+var xlsxDateTimeFields = map[string]bool{"created_at": true, "updated_at": true, "deleted_at": true}
+
+// xlsxEpoch es el día cero de las fechas de Excel. Arranca el 30/12/1899 y no el 1/1/1900 por el 29
+// de febrero de 1900 que Excel cuenta y no existió; para fechas desde marzo de 1900 da el número justo.
+var xlsxEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// Las partes fijas del paquete: una hoja "items", los estilos y las relaciones entre ellos.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="items" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="5"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+	// xlsxSheetStart abre la hoja con el encabezado fijo al scrollear.
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxExportEncoder escribe el export como un libro de Excel con una sola hoja. El zip se arma a
+// medida que llegan los items: las partes fijas van primero y la hoja es la última entrada, que se
+// escribe fila por fila con strings inline (sin tabla de strings compartidos, que obligaría a tener
+// todos los textos en memoria hasta el final). Las columnas son las de CSVColumns o las de ?fields=.
+type xlsxExportEncoder struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	columns projection
+	// row es la última fila escrita, contando el encabezado.
+	row  int
+	line bytes.Buffer
+}
+
+func newXLSXExportEncoder(writer io.Writer, fields projection) *xlsxExportEncoder {
+	columns := fields
+	if columns == nil {
+		columns = projection(CSVColumns)
+	}
+	return &xlsxExportEncoder{archive: zip.NewWriter(writer), columns: columns}
+}
+
+func (encoder *xlsxExportEncoder) Begin() error {
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		entry, err := encoder.archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return err
+		}
+	}
+	sheet, err := encoder.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	encoder.sheet = sheet
+	if _, err := io.WriteString(sheet, xlsxSheetStart); err != nil {
+		return err
+	}
+
+	encoder.startRow()
+	for column, name := range encoder.columns {
+		encoder.stringCell(column, name, xlsxStyleHeader)
+	}
+	return encoder.endRow()
+}
+
+func (encoder *xlsxExportEncoder) Encode(item Item) error {
+	if encoder.row >= xlsxMaxSheetRows {
+		return fmt.Errorf("xlsx sheets hold at most %d rows", xlsxMaxSheetRows)
+	}
+	projected, err := encoder.columns.apply(item)
+	if err != nil {
+		return err
+	}
+	values := projected.(map[string]json.RawMessage)
+	encoder.startRow()
+	for column, name := range encoder.columns {
+		if err := encoder.cell(column, name, values[name]); err != nil {
+			return err
+		}
+	}
+	return encoder.endRow()
+}
+
+// Flush pasa al writer lo que el zip tiene en su buffer. Lo que quedó en el compresor sale con los
+// items siguientes o con End.
+func (encoder *xlsxExportEncoder) Flush() error {
+	return encoder.archive.Flush()
+}
+
+// End cierra la hoja y escribe el directorio del zip, sin el cual el archivo no abre.
+func (encoder *xlsxExportEncoder) End() error {
+	if _, err := io.WriteString(encoder.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return encoder.archive.Close()
+}
+
+// cell escribe el valor JSON del campo name con el tipo de celda que le corresponde. Un null no
+// escribe celda.
+func (encoder *xlsxExportEncoder) cell(column int, name string, value json.RawMessage) error {
+	if len(value) == 0 || string(value) == "null" {
+		return nil
+	}
+	switch value[0] {
+	case '"':
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return err
+		}
+		switch {
+		case xlsxMoneyFields[name]:
+			encoder.numberCell(column, text, xlsxStyleMoney)
+		case xlsxDateTimeFields[name]:
+			at, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			encoder.numberCell(column, xlsxSerial(at), xlsxStyleDateTime)
+		case name == "expires_at":
+			day, err := time.Parse(time.DateOnly, text)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			encoder.numberCell(column, xlsxSerial(day), xlsxStyleDate)
+		default:
+			encoder.stringCell(column, text, xlsxStyleDefault)
+		}
+	case 't', 'f':
+		encoder.boolCell(column, value[0] == 't')
+	case '{', '[':
+		// Atributos, refs, categoría: el JSON tal cual, como en el CSV.
+		encoder.stringCell(column, string(value), xlsxStyleDefault)
+	default:
+		encoder.numberCell(column, string(value), xlsxStyleDefault)
+	}
+	return nil
+}
+
+func (encoder *xlsxExportEncoder) startRow() {
+	encoder.row++
+	encoder.line.Reset()
+	fmt.Fprintf(&encoder.line, `<row r="%d">`, encoder.row)
+}
+
+func (encoder *xlsxExportEncoder) endRow() error {
+	encoder.line.WriteString(`</row>`)
+	_, err := encoder.sheet.Write(encoder.line.Bytes())
+	return err
+}
+
+func (encoder *xlsxExportEncoder) stringCell(column int, text string, style int) {
+	encoder.openCell(column, style, "inlineStr")
+	encoder.line.WriteString(`<is><t xml:space="preserve">`)
+	// EscapeText también reemplaza los caracteres que XML no admite, como los de control.
+	_ = xml.EscapeText(&encoder.line, []byte(text))
+	encoder.line.WriteString(`</t></is></c>`)
+}
+
+func (encoder *xlsxExportEncoder) numberCell(column int, number string, style int) {
+	encoder.openCell(column, style, "")
+	encoder.line.WriteString(`<v>` + number + `</v></c>`)
+}
+
+func (encoder *xlsxExportEncoder) boolCell(column int, value bool) {
+	encoder.openCell(column, xlsxStyleDefault, "b")
+	if value {
+		encoder.line.WriteString(`<v>1</v></c>`)
+		return
+	}
+	encoder.line.WriteString(`<v>0</v></c>`)
+}
+
+func (encoder *xlsxExportEncoder) openCell(column, style int, kind string) {
+	fmt.Fprintf(&encoder.line, `<c r="%s%d"`, xlsxColumn(column), encoder.row)
+	if style != xlsxStyleDefault {
+		fmt.Fprintf(&encoder.line, ` s="%d"`, style)
+	}
+	if kind != "" {
+		fmt.Fprintf(&encoder.line, ` t="%s"`, kind)
+	}
+	encoder.line.WriteString(`>`)
+}
+
+// xlsxColumn devuelve la letra de la columna index (desde 0): A, B, ..., Z, AA, AB, ...
+func xlsxColumn(index int) string {
+	var letters []byte
+	for index++; index > 0; index = (index - 1) / 26 {
+		letters = append([]byte{byte('A' + (index-1)%26)}, letters...)
+	}
+	return string(letters)
+}
+
+// xlsxSerial es at como número de serie de Excel: días desde xlsxEpoch, con la hora como fracción.
+func xlsxSerial(at time.Time) string {
+	days := at.UTC().Sub(xlsxEpoch).Seconds() / (24 * 60 * 60)
+	return strconv.FormatFloat(days, 'f', -1, 64)
+}
@@ -0,0 +1,20 @@
+package items
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		require.Equal(t, want, xlsxColumn(index), index)
+	}
+}
+
+func TestXLSXSerial(t *testing.T) {
+	require.Equal(t, "1", xlsxSerial(time.Date(1899, time.December, 31, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, "45658", xlsxSerial(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, "45658.75", xlsxSerial(time.Date(2025, time.January, 1, 15, 0, 0, 0, time.FixedZone("ART", -3*60*60))), "in UTC")
+}
@@ -0,0 +1,82 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	Get(ctx context.Context, id string) (Job, error)
+	Cancel(ctx context.Context, id string) (Job, error)
+}
+
+// Handler expone el estado de los jobs y su cancelación.
+type Handler struct {
+	service ServiceAPI
+}
+
+// NewHandler crea un handler de jobs.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service}
+}
+
+// Get maneja GET /jobs/{id}: estado, avance, errores por fila y, al terminar, el resumen.
+func (handler *Handler) Get(writer http.ResponseWriter, request *http.Request) {
+	id, ok := jobID(writer, request)
+	if !ok {
+		return
+	}
+
+	job, err := handler.service.Get(request.Context(), id)
+	if err != nil {
+		failJob(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, job)
+}
+
+// Cancel maneja DELETE /jobs/{id}. Responde 202 con el job: si estaba queued ya queda canceled; si
+// estaba running, cancel_requested avisa que el worker va a cortar en su próximo reporte.
+func (handler *Handler) Cancel(writer http.ResponseWriter, request *http.Request) {
+	id, ok := jobID(writer, request)
+	if !ok {
+		return
+	}
+
+	job, err := handler.service.Cancel(request.Context(), id)
+	if err != nil {
+		failJob(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusAccepted, job)
+}
+
+// jobID lee y valida el {id} del path; si no es un UUID responde 400 y devuelve false.
+func jobID(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// failJob responde los errores de dominio: job inexistente, job ya terminado y el resto como
+// inesperados.
+func failJob(writer http.ResponseWriter, request *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "job not found")
+	case errors.Is(err, ErrorFinished):
+		httpx.Fail(writer, request, http.StatusConflict, "job_finished", "job already finished")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
@@ -0,0 +1,149 @@
+package jobqueue_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const jobID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+type stubService struct {
+	err    error
+	called bool
+}
+
+func (service *stubService) Get(ctx context.Context, id string) (jobqueue.Job, error) {
+	service.called = true
+	if service.err != nil {
+		return jobqueue.Job{}, service.err
+	}
+	return jobqueue.Job{
+		ID: id, Kind: "items.import", Status: jobqueue.StatusSucceeded,
+		Payload:  json.RawMessage(`{"items":[]}`),
+		Progress: jobqueue.Progress{Total: 4, Processed: 4, Failed: 1},
+		Errors:   []jobqueue.RowError{{Row: 3, Field: "name", Message: "row 3 failed"}},
+		Result:   json.RawMessage(`{"created":3}`),
+	}, nil
+}
+
+func (service *stubService) Cancel(ctx context.Context, id string) (jobqueue.Job, error) {
+	service.called = true
+	if service.err != nil {
+		return jobqueue.Job{}, service.err
+	}
+	return jobqueue.Job{ID: id, Status: jobqueue.StatusRunning, CancelRequested: true}, nil
+}
+
+func TestHandler_Get(t *testing.T) {
+	t.Run("status, progress and result", func(t *testing.T) {
+		handler := jobqueue.NewHandler(&stubService{})
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/jobs/"+jobID, nil), "id", jobID)
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "succeeded", data["status"])
+		require.NotContains(t, data, "payload")
+		progress := asMap(t, data["progress"])
+		require.Equal(t, json.Number("4"), progress["processed"])
+		require.Equal(t, json.Number("1"), progress["failed"])
+		require.Equal(t, json.Number("3"), asMap(t, data["result"])["created"])
+		require.Len(t, data["errors"], 1)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		handler := jobqueue.NewHandler(&stubService{err: jobqueue.ErrorNotFound})
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/jobs/"+jobID, nil), "id", jobID)
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := jobqueue.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/jobs/nope", nil), "id", "nope")
+		rec := httptest.NewRecorder()
+
+		handler.Get(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.False(t, service.called)
+	})
+}
+
+func TestHandler_Cancel(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		handler := jobqueue.NewHandler(&stubService{})
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/jobs/"+jobID, nil), "id", jobID)
+		rec := httptest.NewRecorder()
+
+		handler.Cancel(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		require.Equal(t, true, asMap(t, decodeResponse(t, rec).Data)["cancel_requested"])
+	})
+
+	t.Run("already finished", func(t *testing.T) {
+		handler := jobqueue.NewHandler(&stubService{err: jobqueue.ErrorFinished})
+
+		req := withURLParam(httptest.NewRequest(http.MethodDelete, "/jobs/"+jobID, nil), "id", jobID)
+		rec := httptest.NewRecorder()
+
+		handler.Cancel(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, "job_finished", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	router := chi.NewRouter()
+	jobqueue.RegisterRoutes(router, jobqueue.NewHandler(&stubService{}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/jobs/"+jobID, nil))
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
@@ -0,0 +1,64 @@
+package jobqueue
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status es el estado de un job.
+type Status string
+
+// Estados de un job: queued espera un worker, running lo está procesando uno y los otros tres son
+// finales.
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Finished indica si el estado es final.
+func (status Status) Finished() bool {
+	return status == StatusSucceeded || status == StatusFailed || status == StatusCanceled
+}
+
+// MaxRowErrors es cuántos errores por fila se guardan de un job; Progress.Failed los cuenta todos.
+const MaxRowErrors = 100
+
+// RowError es el error de una fila del payload. Row es el índice en el payload, desde 0.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Progress es el avance de un job: Processed cuenta las filas ya procesadas, bien o mal, y Failed
+// las que fallaron.
+type Progress struct {
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+}
+
+// Job es un trabajo asincrónico y su estado. Payload es la entrada tal como la guardó quien lo
+// encoló; no se devuelve en las respuestas y se borra al terminar.
+type Job struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"`
+	Status   Status          `json:"status"`
+	Payload  json.RawMessage `json:"-"`
+	Progress Progress        `json:"progress"`
+	// Errors son los primeros MaxRowErrors errores por fila.
+	Errors []RowError `json:"errors"`
+	// Result es el resumen que deja el job al terminar bien.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error es el motivo por el que el job falló entero.
+	Error           *string    `json:"error,omitempty"`
+	CancelRequested bool       `json:"cancel_requested"`
+	Attempts        int        `json:"attempts"`
+	CreatedAt       time.Time  `json:"created_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
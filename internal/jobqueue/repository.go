@@ -0,0 +1,177 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Repository accede a la tabla jobs.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de jobs.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// jobColumns son las columnas de Job en el orden en que las escanea jobDestinations. El payload
+// queda afuera: puede pesar megas y solo lo lee Claim.
+const jobColumns = `id, kind, status, total, processed, failed, errors, result, error, cancel_requested, attempts, created_at, started_at, finished_at, updated_at`
+
+// jobDestinations devuelve los destinos de Scan para las columnas de jobColumns.
+func jobDestinations(job *Job) []any {
+	return []any{
+		&job.ID, &job.Kind, &job.Status, &job.Progress.Total, &job.Progress.Processed, &job.Progress.Failed, &job.Errors,
+		&job.Result, &job.Error, &job.CancelRequested, &job.Attempts, &job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.UpdatedAt,
+	}
+}
+
+// Insert crea un job queued y devuelve el registro persistido.
+func (repository *Repository) Insert(ctx context.Context, kind string, payload []byte, total int) (Job, error) {
+	const query = `INSERT INTO jobs (kind, payload, total) VALUES ($1, $2, $3) RETURNING ` + jobColumns + `;`
+
+	var job Job
+	if err := repository.database.QueryRow(ctx, query, kind, payload, total).Scan(jobDestinations(&job)...); err != nil {
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// GetByID busca un job por su ID. Devuelve ErrorNotFound si no existe.
+func (repository *Repository) GetByID(ctx context.Context, id string) (Job, error) {
+	const query = `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1;`
+
+	var job Job
+	if err := repository.database.QueryRow(ctx, query, id).Scan(jobDestinations(&job)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Job{}, ErrorNotFound
+		}
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// RequestCancel cancela un job queued en el momento y marca cancel_requested en uno running, para
+// que su worker corte. Devuelve ErrorNotFound si el job no existe y ErrorFinished si ya terminó.
+func (repository *Repository) RequestCancel(ctx context.Context, id string) (Job, error) {
+	const query = `
+		UPDATE jobs
+		SET cancel_requested = true,
+			status = CASE WHEN status = 'queued' THEN 'canceled' ELSE status END,
+			finished_at = CASE WHEN status = 'queued' THEN now() ELSE finished_at END,
+			payload = CASE WHEN status = 'queued' THEN NULL ELSE payload END,
+			updated_at = now()
+		WHERE id = $1 AND status IN ('queued', 'running')
+		RETURNING ` + jobColumns + `;`
+
+	var job Job
+	err := repository.database.QueryRow(ctx, query, id).Scan(jobDestinations(&job)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := repository.GetByID(ctx, id); err != nil {
+			return Job{}, err
+		}
+		return Job{}, ErrorFinished
+	}
+	if err != nil {
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// Claim toma el job queued más viejo, o uno running cuyo lease venció porque el worker que lo tenía
+// murió, lo pasa a running con un lease nuevo y cuenta el intento. FOR UPDATE SKIP LOCKED evita que
+// dos instancias tomen el mismo job. Devuelve false si no hay ninguno para tomar.
+func (repository *Repository) Claim(ctx context.Context, lease time.Duration) (Job, bool, error) {
+	const query = `
+		WITH next AS (
+			SELECT id FROM jobs
+			WHERE status = 'queued' OR (status = 'running' AND locked_until < now())
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE jobs AS job
+		SET status = 'running', attempts = job.attempts + 1, locked_until = now() + $1 * interval '1 millisecond',
+			started_at = COALESCE(job.started_at, now()), updated_at = now()
+		FROM next
+		WHERE job.id = next.id
+		RETURNING job.payload, ` + jobColumns + `;`
+
+	var job Job
+	destinations := append([]any{&job.Payload}, jobDestinations(&job)...)
+	if err := repository.database.QueryRow(ctx, query, lease.Milliseconds()).Scan(destinations...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Job{}, false, nil
+		}
+		return Job{}, false, err
+	}
+	return job, true, nil
+}
+
+// SaveProgress guarda el avance del intento attempt del job y extiende su lease. Devuelve si
+// pidieron cancelarlo, o ErrorNotFound si el job ya no es de este intento (lo tomó otro worker
+// porque venció el lease).
+func (repository *Repository) SaveProgress(ctx context.Context, id string, attempt int, progress Progress, rowErrors []RowError, lease time.Duration) (bool, error) {
+	const query = `
+		UPDATE jobs
+		SET processed = $3, failed = $4, errors = $5, locked_until = now() + $6 * interval '1 millisecond', updated_at = now()
+		WHERE id = $1 AND attempts = $2 AND status = 'running'
+		RETURNING cancel_requested;`
+
+	encodedErrors, err := encodeRowErrors(rowErrors)
+	if err != nil {
+		return false, err
+	}
+	var cancelRequested bool
+	if err := repository.database.QueryRow(ctx, query, id, attempt, progress.Processed, progress.Failed, encodedErrors, lease.Milliseconds()).Scan(&cancelRequested); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrorNotFound
+		}
+		return false, err
+	}
+	return cancelRequested, nil
+}
+
+// Finish cierra el intento attempt del job con outcome y borra el payload, que ya no hace falta.
+// Devuelve ErrorNotFound si el job ya no es de este intento.
+func (repository *Repository) Finish(ctx context.Context, id string, attempt int, outcome Outcome) error {
+	const query = `
+		UPDATE jobs
+		SET status = $3, processed = $4, failed = $5, errors = $6, result = $7, error = $8,
+			payload = NULL, locked_until = NULL, finished_at = now(), updated_at = now()
+		WHERE id = $1 AND attempts = $2 AND status = 'running'
+		RETURNING id;`
+
+	encodedErrors, err := encodeRowErrors(outcome.Errors)
+	if err != nil {
+		return err
+	}
+	var finishedID string
+	if err := repository.database.QueryRow(ctx, query, id, attempt, outcome.Status, outcome.Progress.Processed, outcome.Progress.Failed,
+		encodedErrors, outcome.Result, outcome.Error).Scan(&finishedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// encodeRowErrors pasa los errores por fila a JSON para la columna errors, que nunca es null.
+func encodeRowErrors(rowErrors []RowError) ([]byte, error) {
+	if rowErrors == nil {
+		rowErrors = []RowError{}
+	}
+	return json.Marshal(rowErrors)
+}
@@ -0,0 +1,72 @@
+//go:build integration
+
+package jobqueue_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/jobqueue"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_Lifecycle(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	repository := jobqueue.NewRepository(pool)
+	service := jobqueue.NewService(repository)
+	// Los jobs que quedaron de otras corridas no deben interferir con Claim.
+	_, err = pool.Exec(ctx, `UPDATE jobs SET status = 'canceled' WHERE status IN ('queued', 'running')`)
+	require.NoError(t, err)
+
+	job, err := service.Enqueue(ctx, "test", map[string]any{"rows": 2}, 2)
+	require.NoError(t, err)
+	require.Equal(t, jobqueue.StatusQueued, job.Status)
+
+	claimed, ok, err := repository.Claim(ctx, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, job.ID, claimed.ID)
+	require.JSONEq(t, `{"rows":2}`, string(claimed.Payload))
+	_, ok, err = repository.Claim(ctx, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok, "a leased job is not claimed twice")
+
+	cancelRequested, err := repository.SaveProgress(ctx, job.ID, claimed.Attempts, jobqueue.Progress{Processed: 1, Failed: 1}, []jobqueue.RowError{{Row: 0, Message: "bad"}}, time.Minute)
+	require.NoError(t, err)
+	require.False(t, cancelRequested)
+
+	canceled, err := service.Cancel(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, jobqueue.StatusRunning, canceled.Status)
+	require.True(t, canceled.CancelRequested)
+	cancelRequested, err = repository.SaveProgress(ctx, job.ID, claimed.Attempts, jobqueue.Progress{Processed: 2, Failed: 1}, nil, time.Minute)
+	require.NoError(t, err)
+	require.True(t, cancelRequested)
+
+	require.NoError(t, repository.Finish(ctx, job.ID, claimed.Attempts, jobqueue.Outcome{Status: jobqueue.StatusCanceled, Progress: jobqueue.Progress{Processed: 2, Failed: 1}}))
+	finished, err := service.Get(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, jobqueue.StatusCanceled, finished.Status)
+	require.Equal(t, 2, finished.Progress.Processed)
+	require.NotNil(t, finished.FinishedAt)
+
+	_, err = service.Cancel(ctx, job.ID)
+	require.ErrorIs(t, err, jobqueue.ErrorFinished)
+	_, err = service.Cancel(ctx, "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	require.ErrorIs(t, err, jobqueue.ErrorNotFound)
+}
@@ -0,0 +1,171 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// jobValues son los valores de una fila de jobColumns.
+func jobValues(status Status, processed int) []any {
+	now := time.Now()
+	return []any{
+		"job-1", "items.import", status, 10, processed, 0, []RowError{},
+		json.RawMessage(nil), (*string)(nil), false, 1, now, &now, (*time.Time)(nil), now,
+	}
+}
+
+func TestRepository_Insert(t *testing.T) {
+	database := &fakeDB{row: &fakeRow{values: jobValues(StatusQueued, 0)}}
+	repository := NewRepository(database)
+
+	job, err := repository.Insert(context.Background(), "items.import", []byte(`{"items":[]}`), 10)
+
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, job.Status)
+	require.Equal(t, 10, job.Progress.Total)
+	require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO jobs (kind, payload, total) VALUES ($1, $2, $3)")
+	require.NotContains(t, normalizeSQL(database.lastQuery), "RETURNING payload", "the payload is never read back")
+}
+
+func TestRepository_RequestCancel(t *testing.T) {
+	t.Run("queued jobs are canceled right away", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{values: jobValues(StatusCanceled, 0)}}
+		repository := NewRepository(database)
+
+		job, err := repository.RequestCancel(context.Background(), "job-1")
+
+		require.NoError(t, err)
+		require.Equal(t, StatusCanceled, job.Status)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "status = CASE WHEN status = 'queued' THEN 'canceled' ELSE status END")
+		require.Contains(t, query, "WHERE id = $1 AND status IN ('queued', 'running')")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{row: &fakeRow{err: pgx.ErrNoRows}})
+
+		_, err := repository.RequestCancel(context.Background(), "job-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_Claim(t *testing.T) {
+	t.Run("claims with skip locked and a lease", func(t *testing.T) {
+		values := append([]any{json.RawMessage(`{"items":[]}`)}, jobValues(StatusRunning, 4)...)
+		database := &fakeDB{row: &fakeRow{values: values}}
+		repository := NewRepository(database)
+
+		job, ok, err := repository.Claim(context.Background(), time.Minute)
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.JSONEq(t, `{"items":[]}`, string(job.Payload))
+		require.Equal(t, 4, job.Progress.Processed)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "WHERE status = 'queued' OR (status = 'running' AND locked_until < now())")
+		require.Contains(t, query, "FOR UPDATE SKIP LOCKED")
+		require.Equal(t, []any{int64(60000)}, database.lastArgs)
+	})
+
+	t.Run("nothing to claim", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{row: &fakeRow{err: pgx.ErrNoRows}})
+
+		_, ok, err := repository.Claim(context.Background(), time.Minute)
+
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
+
+func TestRepository_SaveProgress(t *testing.T) {
+	t.Run("reports cancellation", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{values: []any{true}}}
+		repository := NewRepository(database)
+
+		cancelRequested, err := repository.SaveProgress(context.Background(), "job-1", 2, Progress{Processed: 5, Failed: 1}, nil, time.Minute)
+
+		require.NoError(t, err)
+		require.True(t, cancelRequested)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id = $1 AND attempts = $2 AND status = 'running'")
+		require.Equal(t, []any{"job-1", 2, 5, 1, []byte(`[]`), int64(60000)}, database.lastArgs)
+	})
+
+	t.Run("job taken by another attempt", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{row: &fakeRow{err: pgx.ErrNoRows}})
+
+		_, err := repository.SaveProgress(context.Background(), "job-1", 1, Progress{}, nil, time.Minute)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_Finish(t *testing.T) {
+	database := &fakeDB{row: &fakeRow{values: []any{"job-1"}}}
+	repository := NewRepository(database)
+
+	err := repository.Finish(context.Background(), "job-1", 1, Outcome{
+		Status:   StatusSucceeded,
+		Progress: Progress{Total: 2, Processed: 2, Failed: 1},
+		Errors:   []RowError{{Row: 1, Field: "name", Message: "name is required"}},
+		Result:   json.RawMessage(`{"created":1}`),
+	})
+
+	require.NoError(t, err)
+	require.Contains(t, normalizeSQL(database.lastQuery), "payload = NULL, locked_until = NULL, finished_at = now()")
+	require.Equal(t, []any{
+		"job-1", 1, StatusSucceeded, 2, 1, []byte(`[{"row":1,"field":"name","message":"name is required"}]`), json.RawMessage(`{"created":1}`), (*string)(nil),
+	}, database.lastArgs)
+}
+
+type fakeDB struct {
+	row       *fakeRow
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.row == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.row
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	return nil, errors.New("unexpected Query call")
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	if len(dest) != len(row.values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(row.values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(row.values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
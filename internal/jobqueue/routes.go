@@ -0,0 +1,9 @@
+package jobqueue
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra las rutas de jobs en el router.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Get("/jobs/{id}", handler.Get)
+	route.Delete("/jobs/{id}", handler.Cancel)
+}
@@ -0,0 +1,58 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Errores de dominio (no HTTP). El handler los traduce a status codes.
+var (
+	ErrorNotFound = errors.New("job not found")
+	// ErrorFinished lo devuelve Cancel si el job ya terminó.
+	ErrorFinished = errors.New("job already finished")
+	// ErrorCanceled lo devuelve Reporter.Report cuando pidieron cancelar el job: el Processor tiene
+	// que cortar y devolverlo.
+	ErrorCanceled = errors.New("job canceled")
+)
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	Insert(ctx context.Context, kind string, payload []byte, total int) (Job, error)
+	// GetByID devuelve ErrorNotFound si el job no existe.
+	GetByID(ctx context.Context, id string) (Job, error)
+	// RequestCancel cancela un job queued y marca cancel_requested en uno running. Devuelve
+	// ErrorNotFound si el job no existe y ErrorFinished si ya terminó.
+	RequestCancel(ctx context.Context, id string) (Job, error)
+}
+
+// Service encola jobs y expone su estado.
+type Service struct {
+	repository RepositoryAPI
+}
+
+// NewService crea un service de jobs.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository}
+}
+
+// Enqueue crea un job queued de tipo kind con payload en JSON; total es cuántas filas trae, para
+// informar el avance. El worker que tenga un Processor para kind lo toma en su próxima corrida.
+func (service *Service) Enqueue(ctx context.Context, kind string, payload any, total int) (Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, err
+	}
+	return service.repository.Insert(ctx, kind, encoded, total)
+}
+
+// Get devuelve un job por ID.
+func (service *Service) Get(ctx context.Context, id string) (Job, error) {
+	return service.repository.GetByID(ctx, id)
+}
+
+// Cancel pide cancelar un job. Es best-effort: uno queued queda canceled en el momento; uno running
+// se corta cuando el worker guarda el próximo avance, y lo que ya procesó queda hecho.
+func (service *Service) Cancel(ctx context.Context, id string) (Job, error) {
+	return service.repository.RequestCancel(ctx, id)
+}
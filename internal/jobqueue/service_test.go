@@ -0,0 +1,40 @@
+package jobqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	kind    string
+	payload []byte
+	total   int
+}
+
+func (repository *fakeRepository) Insert(ctx context.Context, kind string, payload []byte, total int) (Job, error) {
+	repository.kind, repository.payload, repository.total = kind, payload, total
+	return Job{ID: "job-1", Kind: kind, Status: StatusQueued, Progress: Progress{Total: total}}, nil
+}
+
+func (repository *fakeRepository) GetByID(ctx context.Context, id string) (Job, error) {
+	return Job{}, ErrorNotFound
+}
+
+func (repository *fakeRepository) RequestCancel(ctx context.Context, id string) (Job, error) {
+	return Job{}, ErrorFinished
+}
+
+func TestService_Enqueue(t *testing.T) {
+	repository := &fakeRepository{}
+	service := NewService(repository)
+
+	job, err := service.Enqueue(context.Background(), "items.import", map[string]any{"items": []int{1, 2}}, 2)
+
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, job.Status)
+	require.Equal(t, "items.import", repository.kind)
+	require.JSONEq(t, `{"items":[1,2]}`, string(repository.payload))
+	require.Equal(t, 2, repository.total)
+}
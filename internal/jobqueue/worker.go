@@ -0,0 +1,166 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// claimLease es cuánto queda reservado un job después de tomarlo o de guardar avance: si el
+	// proceso muere, otro worker lo retoma después de esto. Los Processor tienen que reportar avance
+	// bastante más seguido.
+	claimLease = 2 * time.Minute
+	// maxAttempts es cuántas veces se toma un job. Un job que tira abajo el proceso en cada intento
+	// termina en failed en lugar de volver a tomarse para siempre.
+	maxAttempts = 3
+)
+
+// Store es lo que el Worker necesita de la DB. Repository la implementa.
+type Store interface {
+	Claim(ctx context.Context, lease time.Duration) (Job, bool, error)
+	SaveProgress(ctx context.Context, id string, attempt int, progress Progress, rowErrors []RowError, lease time.Duration) (bool, error)
+	Finish(ctx context.Context, id string, attempt int, outcome Outcome) error
+}
+
+// Outcome es como terminó un intento de un job.
+type Outcome struct {
+	Status   Status
+	Progress Progress
+	Errors   []RowError
+	Result   json.RawMessage
+	Error    *string
+}
+
+// Processor procesa los jobs de un tipo. Process arranca desde job.Progress.Processed (un intento
+// anterior pudo dejar filas hechas), reporta el avance con reporter cada tanto y devuelve el resumen
+// que queda en el resultado del job. Si reporter devuelve ErrorCanceled tiene que cortar y
+// devolverlo.
+type Processor interface {
+	Process(ctx context.Context, job Job, reporter *Reporter) (any, error)
+}
+
+// Reporter guarda el avance de un job en curso y avisa si pidieron cancelarlo.
+type Reporter struct {
+	store    Store
+	job      Job
+	progress Progress
+	errors   []RowError
+}
+
+// NewReporter crea el Reporter de un intento de job, arrancando del avance y los errores que ya
+// tiene. Lo usa el Worker; sirve también para probar un Processor con un Store de mentira.
+func NewReporter(store Store, job Job) *Reporter {
+	return &Reporter{store: store, job: job, progress: job.Progress, errors: job.Errors}
+}
+
+// Report guarda progress y suma rowErrors a los errores del job (hasta MaxRowErrors). Devuelve
+// ErrorCanceled si pidieron cancelar el job.
+func (reporter *Reporter) Report(ctx context.Context, progress Progress, rowErrors ...RowError) error {
+	reporter.progress = progress
+	room := max(MaxRowErrors-len(reporter.errors), 0)
+	reporter.errors = append(reporter.errors, rowErrors[:min(room, len(rowErrors))]...)
+
+	cancelRequested, err := reporter.store.SaveProgress(ctx, reporter.job.ID, reporter.job.Attempts, reporter.progress, reporter.errors, claimLease)
+	if err != nil {
+		return err
+	}
+	if cancelRequested {
+		return ErrorCanceled
+	}
+	return nil
+}
+
+// Worker toma los jobs encolados y los procesa con el Processor de su tipo.
+type Worker struct {
+	store      Store
+	processors map[string]Processor
+	logf       func(format string, args ...any)
+}
+
+// WorkerOption configura comportamiento opcional del Worker.
+type WorkerOption func(*Worker)
+
+// WithProcessor registra el Processor de los jobs de tipo kind.
+func WithProcessor(kind string, processor Processor) WorkerOption {
+	return func(worker *Worker) {
+		worker.processors[kind] = processor
+	}
+}
+
+// WithWorkerLogf cambia dónde se loguea el final de cada job.
+func WithWorkerLogf(logf func(format string, args ...any)) WorkerOption {
+	return func(worker *Worker) {
+		worker.logf = logf
+	}
+}
+
+// NewWorker crea un worker que lee los jobs de store.
+func NewWorker(store Store, options ...WorkerOption) *Worker {
+	worker := &Worker{store: store, processors: make(map[string]Processor), logf: log.Printf}
+	for _, option := range options {
+		option(worker)
+	}
+	return worker
+}
+
+// Run es el job del worker: procesa jobs, de a uno, hasta que no queda ninguno para tomar. Si ctx
+// se cancela a mitad de un job, el job queda running y se retoma (en esta u otra instancia) cuando
+// vence su lease.
+func (worker *Worker) Run(ctx context.Context) error {
+	for {
+		job, ok, err := worker.store.Claim(ctx, claimLease)
+		if err != nil || !ok {
+			return err
+		}
+		if err := worker.process(ctx, job); err != nil {
+			return err
+		}
+	}
+}
+
+// process corre un intento de job y guarda cómo terminó.
+func (worker *Worker) process(ctx context.Context, job Job) error {
+	reporter := NewReporter(worker.store, job)
+	outcome := Outcome{Status: StatusSucceeded}
+	processor, known := worker.processors[job.Kind]
+	switch {
+	case job.CancelRequested:
+		outcome.Status = StatusCanceled
+	case !known:
+		outcome.Status, outcome.Error = StatusFailed, failure("unknown job kind "+job.Kind)
+	case job.Attempts > maxAttempts:
+		outcome.Status, outcome.Error = StatusFailed, failure("job abandoned after too many attempts")
+	default:
+		result, err := processor.Process(ctx, job, reporter)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, ErrorNotFound):
+			// Venció el lease y lo tomó otro worker: ese intento sigue.
+			return nil
+		case errors.Is(err, ErrorCanceled):
+			outcome.Status = StatusCanceled
+		case err != nil:
+			outcome.Status, outcome.Error = StatusFailed, failure(err.Error())
+		default:
+			if outcome.Result, err = json.Marshal(result); err != nil {
+				outcome.Status, outcome.Error = StatusFailed, failure(err.Error())
+			}
+		}
+	}
+	outcome.Progress, outcome.Errors = reporter.progress, reporter.errors
+
+	if err := worker.store.Finish(ctx, job.ID, job.Attempts, outcome); err != nil && !errors.Is(err, ErrorNotFound) {
+		return err
+	}
+	worker.logf("jobs: finished id=%s kind=%s status=%s processed=%d failed=%d", job.ID, job.Kind, outcome.Status, outcome.Progress.Processed, outcome.Progress.Failed)
+	return nil
+}
+
+// failure arma el motivo de un job fallido.
+func failure(message string) *string {
+	return &message
+}
@@ -0,0 +1,150 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	queue           []Job
+	cancelRequested bool
+	saveErr         error
+	saved           []Progress
+	finished        map[string]Outcome
+}
+
+func (store *fakeStore) Claim(ctx context.Context, lease time.Duration) (Job, bool, error) {
+	if len(store.queue) == 0 {
+		return Job{}, false, nil
+	}
+	job := store.queue[0]
+	store.queue = store.queue[1:]
+	return job, true, nil
+}
+
+func (store *fakeStore) SaveProgress(ctx context.Context, id string, attempt int, progress Progress, rowErrors []RowError, lease time.Duration) (bool, error) {
+	store.saved = append(store.saved, progress)
+	return store.cancelRequested, store.saveErr
+}
+
+func (store *fakeStore) Finish(ctx context.Context, id string, attempt int, outcome Outcome) error {
+	if store.finished == nil {
+		store.finished = make(map[string]Outcome)
+	}
+	store.finished[id] = outcome
+	return nil
+}
+
+// countingProcessor reporta una fila por vez desde donde quedó el job; las filas de failRows fallan.
+type countingProcessor struct {
+	failRows map[int]bool
+	err      error
+}
+
+func (processor *countingProcessor) Process(ctx context.Context, job Job, reporter *Reporter) (any, error) {
+	if processor.err != nil {
+		return nil, processor.err
+	}
+	progress := job.Progress
+	for row := progress.Processed; row < progress.Total; row++ {
+		progress.Processed++
+		var rowErrors []RowError
+		if processor.failRows[row] {
+			progress.Failed++
+			rowErrors = append(rowErrors, RowError{Row: row, Message: "invalid"})
+		}
+		if err := reporter.Report(ctx, progress, rowErrors...); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]int{"created": progress.Processed - progress.Failed}, nil
+}
+
+func TestWorker_Run(t *testing.T) {
+	t.Run("processes every queued job", func(t *testing.T) {
+		store := &fakeStore{queue: []Job{
+			{ID: "job-1", Kind: "count", Attempts: 1, Progress: Progress{Total: 3}},
+			{ID: "job-2", Kind: "count", Attempts: 1, Progress: Progress{Total: 1}},
+		}}
+		worker := NewWorker(store, WithProcessor("count", &countingProcessor{failRows: map[int]bool{1: true}}), WithWorkerLogf(t.Logf))
+
+		require.NoError(t, worker.Run(context.Background()))
+
+		outcome := store.finished["job-1"]
+		require.Equal(t, StatusSucceeded, outcome.Status)
+		require.Equal(t, Progress{Total: 3, Processed: 3, Failed: 1}, outcome.Progress)
+		require.Equal(t, []RowError{{Row: 1, Message: "invalid"}}, outcome.Errors)
+		require.JSONEq(t, `{"created":2}`, string(outcome.Result))
+		require.Equal(t, StatusSucceeded, store.finished["job-2"].Status)
+	})
+
+	t.Run("resumes where the previous attempt left", func(t *testing.T) {
+		store := &fakeStore{queue: []Job{{ID: "job-1", Kind: "count", Attempts: 2, Progress: Progress{Total: 5, Processed: 3}}}}
+		worker := NewWorker(store, WithProcessor("count", &countingProcessor{}), WithWorkerLogf(t.Logf))
+
+		require.NoError(t, worker.Run(context.Background()))
+
+		require.Len(t, store.saved, 2)
+		require.Equal(t, 5, store.finished["job-1"].Progress.Processed)
+	})
+
+	t.Run("cancellation stops at the next report", func(t *testing.T) {
+		store := &fakeStore{queue: []Job{{ID: "job-1", Kind: "count", Attempts: 1, Progress: Progress{Total: 5}}}, cancelRequested: true}
+		worker := NewWorker(store, WithProcessor("count", &countingProcessor{}), WithWorkerLogf(t.Logf))
+
+		require.NoError(t, worker.Run(context.Background()))
+
+		outcome := store.finished["job-1"]
+		require.Equal(t, StatusCanceled, outcome.Status)
+		require.Equal(t, 1, outcome.Progress.Processed, "what was processed stays done")
+	})
+
+	t.Run("failures and unknown kinds fail the job", func(t *testing.T) {
+		store := &fakeStore{queue: []Job{
+			{ID: "job-1", Kind: "count", Attempts: 1},
+			{ID: "job-2", Kind: "other", Attempts: 1},
+			{ID: "job-3", Kind: "count", Attempts: maxAttempts + 1},
+		}}
+		worker := NewWorker(store, WithProcessor("count", &countingProcessor{err: errors.New("feed unreachable")}), WithWorkerLogf(t.Logf))
+
+		require.NoError(t, worker.Run(context.Background()))
+
+		require.Equal(t, StatusFailed, store.finished["job-1"].Status)
+		require.Equal(t, "feed unreachable", *store.finished["job-1"].Error)
+		require.Equal(t, "unknown job kind other", *store.finished["job-2"].Error)
+		require.Equal(t, "job abandoned after too many attempts", *store.finished["job-3"].Error)
+	})
+
+	t.Run("a job taken by another worker is left alone", func(t *testing.T) {
+		store := &fakeStore{queue: []Job{{ID: "job-1", Kind: "count", Attempts: 1, Progress: Progress{Total: 2}}}, saveErr: ErrorNotFound}
+		worker := NewWorker(store, WithProcessor("count", &countingProcessor{}), WithWorkerLogf(t.Logf))
+
+		require.NoError(t, worker.Run(context.Background()))
+
+		require.NotContains(t, store.finished, "job-1")
+	})
+
+	t.Run("shutdown leaves the job running", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		store := &fakeStore{queue: []Job{{ID: "job-1", Kind: "count", Attempts: 1, Progress: Progress{Total: 2}}}}
+		worker := NewWorker(store, WithProcessor("count", &countingProcessor{}), WithWorkerLogf(t.Logf))
+
+		require.ErrorIs(t, worker.Run(ctx), context.Canceled)
+		require.NotContains(t, store.finished, "job-1")
+	})
+}
+
+func TestReporter_CapsRowErrors(t *testing.T) {
+	store := &fakeStore{}
+	reporter := NewReporter(store, Job{ID: "job-1", Errors: make([]RowError, MaxRowErrors-1)})
+
+	require.NoError(t, reporter.Report(context.Background(), Progress{Processed: 3, Failed: 3}, RowError{Row: 0}, RowError{Row: 1}, RowError{Row: 2}))
+
+	require.Len(t, reporter.errors, MaxRowErrors)
+	require.Equal(t, 3, reporter.progress.Failed, "failed counts every error")
+}
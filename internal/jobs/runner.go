@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Func es el trabajo que ejecuta un job. Un error se loguea y el job sigue programado.
+type Func func(ctx context.Context) error
+
+// Schedule calcula la próxima ejecución de un job programado con expresión cron.
+type Schedule interface {
+	Next(time.Time) time.Time
+}
+
+// ParseSchedule interpreta una expresión cron estándar de 5 campos ("0 3 * * *")
+// o un descriptor como "@daily" o "@every 1h". Un spec vacío devuelve nil (job deshabilitado).
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// MustParseSchedule es como ParseSchedule pero entra en pánico si el spec es inválido.
+// Pensado para specs que ya validó config.Load.
+func MustParseSchedule(spec string) Schedule {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// job es un trabajo registrado con su intervalo o su schedule cron (uno de los dos, o ninguno para
+// los que corren una sola vez).
+type job struct {
+	name     string
+	interval time.Duration
+	schedule Schedule
+	run      Func
+}
+
+// Runner corre trabajos en background de forma periódica.
+// Un job que falla (o entra en pánico) se loguea y nunca tira abajo el proceso.
+type Runner struct {
+	logf      func(format string, args ...any)
+	jobs      []job
+	waitGroup sync.WaitGroup
+}
+
+// NewRunner crea un Runner que loguea con logf.
+func NewRunner(logf func(format string, args ...any)) *Runner {
+	return &Runner{logf: logf}
+}
+
+// Every registra un job que corre al arrancar y después cada interval.
+// Un interval menor o igual a 0 no registra nada (job deshabilitado).
+func (runner *Runner) Every(name string, interval time.Duration, run Func) {
+	if interval <= 0 {
+		return
+	}
+	runner.jobs = append(runner.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Cron registra un job que corre cada vez que se cumple schedule (no al arrancar).
+// Un schedule nil no registra nada (job deshabilitado).
+func (runner *Runner) Cron(name string, schedule Schedule, run Func) {
+	if schedule == nil {
+		return
+	}
+	runner.jobs = append(runner.jobs, job{name: name, schedule: schedule, run: run})
+}
+
+// Go registra un trabajo que corre una sola vez, al arrancar, y dura lo que quiera: pensado para
+// loops propios como un listener, que tienen que terminar cuando se cancela ctx.
+func (runner *Runner) Go(name string, run Func) {
+	runner.jobs = append(runner.jobs, job{name: name, run: run})
+}
+
+// Start lanza cada job en su propia goroutine. Los jobs se detienen cuando se cancela ctx.
+func (runner *Runner) Start(ctx context.Context) {
+	for _, registered := range runner.jobs {
+		runner.waitGroup.Add(1)
+		go func(registered job) {
+			defer runner.waitGroup.Done()
+			switch {
+			case registered.schedule != nil:
+				runner.cronLoop(ctx, registered)
+			case registered.interval > 0:
+				runner.loop(ctx, registered)
+			default:
+				runner.runOnce(ctx, registered)
+			}
+		}(registered)
+	}
+}
+
+// Wait bloquea hasta que todos los jobs terminaron (después de cancelar el ctx de Start).
+func (runner *Runner) Wait() {
+	runner.waitGroup.Wait()
+}
+
+func (runner *Runner) loop(ctx context.Context, registered job) {
+	ticker := time.NewTicker(registered.interval)
+	defer ticker.Stop()
+
+	for {
+		runner.runOnce(ctx, registered)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (runner *Runner) cronLoop(ctx context.Context, registered job) {
+	for {
+		timer := time.NewTimer(time.Until(registered.schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		runner.runOnce(ctx, registered)
+	}
+}
+
+// runOnce ejecuta el job una vez, aislando errores y pánicos.
+func (runner *Runner) runOnce(ctx context.Context, registered job) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			runner.logf("job %s panicked: %v", registered.name, recovered)
+		}
+	}()
+
+	if err := registered.run(ctx); err != nil && ctx.Err() == nil {
+		runner.logf("job %s failed: %v", registered.name, err)
+	}
+}
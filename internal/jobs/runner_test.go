@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger guarda los mensajes logueados de forma segura entre goroutines.
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (logger *recordingLogger) logf(format string, args ...any) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	logger.messages = append(logger.messages, fmt.Sprintf(format, args...))
+}
+
+func (logger *recordingLogger) all() []string {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	return append([]string(nil), logger.messages...)
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("runs immediately and then periodically", func(t *testing.T) {
+		runner := NewRunner((&recordingLogger{}).logf)
+		var runs atomic.Int32
+		runner.Every("tick", 5*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.Start(ctx)
+
+		require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+		cancel()
+		runner.Wait()
+	})
+
+	t.Run("errors and panics are logged and the job keeps running", func(t *testing.T) {
+		logger := &recordingLogger{}
+		runner := NewRunner(logger.logf)
+		var runs atomic.Int32
+		runner.Every("flaky", 5*time.Millisecond, func(ctx context.Context) error {
+			if runs.Add(1) == 1 {
+				panic("boom")
+			}
+			return errors.New("db down")
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.Start(ctx)
+
+		require.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		runner.Wait()
+
+		messages := logger.all()
+		require.Contains(t, messages, "job flaky panicked: boom")
+		require.Contains(t, messages, "job flaky failed: db down")
+	})
+
+	t.Run("non-positive interval disables the job", func(t *testing.T) {
+		runner := NewRunner((&recordingLogger{}).logf)
+		runner.Every("disabled", 0, func(ctx context.Context) error {
+			t.Fatal("disabled job must not run")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.Start(ctx)
+		cancel()
+		runner.Wait()
+	})
+}
+
+func TestRunner_Cron(t *testing.T) {
+	t.Run("runs on schedule, not at start", func(t *testing.T) {
+		runner := NewRunner((&recordingLogger{}).logf)
+		var runs atomic.Int32
+		runner.Cron("export", MustParseSchedule("@every 1s"), func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.Start(ctx)
+
+		time.Sleep(50 * time.Millisecond)
+		require.Equal(t, int32(0), runs.Load())
+		require.Eventually(t, func() bool { return runs.Load() >= 1 }, 2*time.Second, 10*time.Millisecond)
+		cancel()
+		runner.Wait()
+	})
+
+	t.Run("nil schedule disables the job", func(t *testing.T) {
+		runner := NewRunner((&recordingLogger{}).logf)
+		runner.Cron("disabled", nil, func(ctx context.Context) error {
+			t.Fatal("disabled job must not run")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.Start(ctx)
+		cancel()
+		runner.Wait()
+	})
+}
+
+func TestRunner_Go(t *testing.T) {
+	logger := &recordingLogger{}
+	runner := NewRunner(logger.logf)
+	var runs atomic.Int32
+	runner.Go("listener", func(ctx context.Context) error {
+		runs.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	runner.Go("broken", func(ctx context.Context) error {
+		return errors.New("no connection")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.Start(ctx)
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return len(logger.all()) == 1 }, time.Second, time.Millisecond)
+	cancel()
+	runner.Wait()
+
+	require.Equal(t, int32(1), runs.Load(), "runs once")
+	require.Equal(t, []string{"job broken failed: no connection"}, logger.all(), "cancellation is not an error")
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantNil bool
+		wantErr bool
+	}{
+		{"empty disables", "  ", true, false},
+		{"standard", "0 3 * * *", false, false},
+		{"descriptor", "@daily", false, false},
+		{"invalid", "every night", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantNil, schedule == nil)
+		})
+	}
+
+	t.Run("next run", func(t *testing.T) {
+		schedule := MustParseSchedule("0 3 * * *")
+		from := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		require.Equal(t, time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC), schedule.Next(from))
+	})
+}
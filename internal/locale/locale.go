@@ -0,0 +1,77 @@
+package locale
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Los locales del catálogo y la negociación de Accept-Language. Los comparten el service y el
+// handler de items y la validación de SUPPORTED_LOCALES.
+
+// Default son los locales cuando no se configura SUPPORTED_LOCALES. El primero es el idioma de
+// los campos base de los items (name, description); el resto son los que admiten traducción.
+var Default = []string{"en", "es", "pt"}
+
+// tagPattern acepta un tag BCP 47 simple ya normalizado: idioma y, opcionalmente, región o variante ("es", "pt-br").
+var tagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// Normalize recorta, pasa a minúsculas y usa guiones: "pt_BR " queda "pt-br".
+func Normalize(tag string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "_", "-")
+}
+
+// IsValid indica si tag (ya normalizado) tiene forma de locale.
+func IsValid(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// Negotiate elige, de supported, el locale que mejor responde al header Accept-Language:
+// recorre los idiomas pedidos de mayor a menor q y se queda con el primero que esté soportado,
+// exacto o por idioma ("es-AR" cae en "es"). Devuelve "" si ninguno está soportado.
+func Negotiate(acceptLanguage string, supported []string) string {
+	type preference struct {
+		tag    string
+		weight float64
+	}
+	var preferences []preference
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(entry, ";")
+		tag = Normalize(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight > 0 {
+			preferences = append(preferences, preference{tag: tag, weight: weight})
+		}
+	}
+	// Estable: a igual q gana el que vino primero.
+	slices.SortStableFunc(preferences, func(a, b preference) int {
+		switch {
+		case a.weight > b.weight:
+			return -1
+		case a.weight < b.weight:
+			return 1
+		}
+		return 0
+	})
+
+	for _, preference := range preferences {
+		if slices.Contains(supported, preference.tag) {
+			return preference.tag
+		}
+		if language, _, ok := strings.Cut(preference.tag, "-"); ok && slices.Contains(supported, language) {
+			return language
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package locale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale(t *testing.T) {
+	require.Equal(t, "pt-br", Normalize(" pt_BR "))
+	require.True(t, IsValid("es"))
+	require.True(t, IsValid("pt-br"))
+	require.False(t, IsValid("e"))
+	require.False(t, IsValid("es-"))
+	require.False(t, IsValid("*"))
+}
+
+func TestNegotiate(t *testing.T) {
+	supported := []string{"en", "es", "pt-br"}
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"exact", "es", "es"},
+		{"region falls back to the language", "es-AR,es;q=0.9", "es"},
+		{"region variant supported", "pt-BR", "pt-br"},
+		{"highest weight wins", "en;q=0.5, es;q=0.8", "es"},
+		{"first on ties", "es, en", "es"},
+		{"unsupported skipped", "fr-FR, fr;q=0.9, en;q=0.3", "en"},
+		{"none supported", "fr, de", ""},
+		{"zero weight excluded", "es;q=0, en;q=0.1", "en"},
+		{"wildcard ignored", "*", ""},
+		{"malformed weight ignored", "es;q=abc, en", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Negotiate(tt.header, supported))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewRegistry crea un registry propio con las métricas de runtime de Go y del proceso.
+// Usamos un registry por router (y no el global) para que los tests puedan armar
+// varios routers sin chocar por registros duplicados.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler expone el registry en formato Prometheus (GET /metrics).
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ConcurrencyStats es lo que se necesita del limiter de concurrencia para exponerlo.
+type ConcurrencyStats interface {
+	InUse() int
+	Queued() int
+	Rejected() uint64
+}
+
+// RegisterConcurrency publica el estado del limiter de requests que tocan la DB.
+func RegisterConcurrency(registry prometheus.Registerer, stats ConcurrencyStats) {
+	registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "catalog_db_concurrency_in_use",
+			Help: "Requests que están ejecutándose dentro del límite de concurrencia.",
+		}, func() float64 { return float64(stats.InUse()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "catalog_db_concurrency_queued",
+			Help: "Requests esperando un lugar en el límite de concurrencia.",
+		}, func() float64 { return float64(stats.Queued()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "catalog_db_concurrency_rejected_total",
+			Help: "Requests rechazados con 503 overloaded.",
+		}, func() float64 { return float64(stats.Rejected()) }),
+	)
+}
+
+// NewDBRetries registra el contador de reintentos de queries y devuelve la función que lo incrementa.
+func NewDBRetries(registry prometheus.Registerer) func(operation string) {
+	retries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "catalog_db_retries_total",
+		Help: "Reintentos de queries por errores transitorios de la base, por operación.",
+	}, []string{"operation"})
+	registry.MustRegister(retries)
+	return func(operation string) {
+		retries.WithLabelValues(operation).Inc()
+	}
+}
+
+// NewAuditDrops registra el contador de entradas descartadas del log de auditoría y devuelve la
+// función que lo incrementa.
+func NewAuditDrops(registry prometheus.Registerer) func(reason string, count int) {
+	drops := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "catalog_audit_log_dropped_total",
+		Help: "Entradas del log de auditoría que no se escribieron, por razón (queue_full o write_failed).",
+	}, []string{"reason"})
+	registry.MustRegister(drops)
+	return func(reason string, count int) {
+		drops.WithLabelValues(reason).Add(float64(count))
+	}
+}
+
+// Catalog agrupa las métricas de negocio del catálogo.
+// Implementa items.Metrics para los contadores; los gauges los refresca un job periódico.
+type Catalog struct {
+	created    prometheus.Counter
+	updated    prometheus.Counter
+	deleted    prometheus.Counter
+	total      prometheus.Gauge
+	outOfStock prometheus.Gauge
+}
+
+// NewCatalog registra las métricas de negocio en registry.
+func NewCatalog(registry prometheus.Registerer) *Catalog {
+	catalog := &Catalog{
+		created: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "catalog_items_created_total",
+			Help: "Items creados desde el arranque.",
+		}),
+		updated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "catalog_items_updated_total",
+			Help: "Items actualizados desde el arranque.",
+		}),
+		deleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "catalog_items_deleted_total",
+			Help: "Items eliminados desde el arranque.",
+		}),
+		total: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "catalog_items_total",
+			Help: "Cantidad de items en el catálogo (refrescada periódicamente).",
+		}),
+		outOfStock: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "catalog_items_out_of_stock",
+			Help: "Cantidad de items con stock 0 (refrescada periódicamente).",
+		}),
+	}
+	registry.MustRegister(catalog.created, catalog.updated, catalog.deleted, catalog.total, catalog.outOfStock)
+	return catalog
+}
+
+// ItemCreated incrementa catalog_items_created_total.
+func (catalog *Catalog) ItemCreated() { catalog.created.Inc() }
+
+// ItemUpdated incrementa catalog_items_updated_total.
+func (catalog *Catalog) ItemUpdated() { catalog.updated.Inc() }
+
+// ItemDeleted incrementa catalog_items_deleted_total.
+func (catalog *Catalog) ItemDeleted() { catalog.deleted.Inc() }
+
+// SetStats actualiza los gauges de tamaño del catálogo.
+func (catalog *Catalog) SetStats(total, outOfStock int) {
+	catalog.total.Set(float64(total))
+	catalog.outOfStock.Set(float64(outOfStock))
+}
+
+// Exports agrupa las métricas del export programado. Implementa export.Metrics.
+type Exports struct {
+	runs        *prometheus.CounterVec
+	lastSuccess prometheus.Gauge
+	lastRows    prometheus.Gauge
+}
+
+// NewExports registra las métricas del export en registry.
+func NewExports(registry prometheus.Registerer) *Exports {
+	exports := &Exports{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "catalog_export_runs_total",
+			Help: "Corridas del export, por resultado (success o failure, después del reintento).",
+		}, []string{"result"}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "catalog_export_last_success_timestamp_seconds",
+			Help: "Momento (unix) en que terminó el último export exitoso.",
+		}),
+		lastRows: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "catalog_export_last_rows",
+			Help: "Filas escritas por el último export exitoso.",
+		}),
+	}
+	registry.MustRegister(exports.runs, exports.lastSuccess, exports.lastRows)
+	return exports
+}
+
+// ExportFinished registra el resultado de una corrida.
+func (exports *Exports) ExportFinished(result string, rows int64, finishedAt time.Time) {
+	exports.runs.WithLabelValues(result).Inc()
+	if result != "success" {
+		return
+	}
+	exports.lastSuccess.Set(float64(finishedAt.Unix()))
+	exports.lastRows.Set(float64(rows))
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStats struct{}
+
+func (fakeStats) InUse() int       { return 3 }
+func (fakeStats) Queued() int      { return 2 }
+func (fakeStats) Rejected() uint64 { return 7 }
+
+func TestHandler_ExposesConcurrencyMetrics(t *testing.T) {
+	registry := NewRegistry()
+	RegisterConcurrency(registry, fakeStats{})
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, "catalog_db_concurrency_in_use 3")
+	require.Contains(t, body, "catalog_db_concurrency_queued 2")
+	require.Contains(t, body, "catalog_db_concurrency_rejected_total 7")
+	require.Contains(t, body, "go_goroutines")
+}
+
+func TestNewDBRetries(t *testing.T) {
+	registry := NewRegistry()
+	onRetry := NewDBRetries(registry)
+
+	onRetry("get")
+	onRetry("get")
+	onRetry("list")
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	require.Contains(t, body, `catalog_db_retries_total{operation="get"} 2`)
+	require.Contains(t, body, `catalog_db_retries_total{operation="list"} 1`)
+}
+
+func TestNewAuditDrops(t *testing.T) {
+	registry := NewRegistry()
+	onDrop := NewAuditDrops(registry)
+
+	onDrop("queue_full", 1)
+	onDrop("write_failed", 500)
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	require.Contains(t, body, `catalog_audit_log_dropped_total{reason="queue_full"} 1`)
+	require.Contains(t, body, `catalog_audit_log_dropped_total{reason="write_failed"} 500`)
+}
+
+func TestCatalog(t *testing.T) {
+	registry := NewRegistry()
+	catalog := NewCatalog(registry)
+
+	catalog.ItemCreated()
+	catalog.ItemCreated()
+	catalog.ItemUpdated()
+	catalog.ItemDeleted()
+	catalog.SetStats(42, 5)
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	require.Contains(t, body, "catalog_items_created_total 2")
+	require.Contains(t, body, "catalog_items_updated_total 1")
+	require.Contains(t, body, "catalog_items_deleted_total 1")
+	require.Contains(t, body, "catalog_items_total 42")
+	require.Contains(t, body, "catalog_items_out_of_stock 5")
+}
+
+func TestExports(t *testing.T) {
+	registry := NewRegistry()
+	exports := NewExports(registry)
+
+	exports.ExportFinished("success", 120, time.Unix(1700000000, 0))
+	exports.ExportFinished("failure", 0, time.Unix(1700000100, 0))
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	require.Contains(t, body, `catalog_export_runs_total{result="success"} 1`)
+	require.Contains(t, body, `catalog_export_runs_total{result="failure"} 1`)
+	require.Contains(t, body, "catalog_export_last_success_timestamp_seconds 1.7e+09")
+	require.Contains(t, body, "catalog_export_last_rows 120")
+}
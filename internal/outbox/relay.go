@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Message es una fila del outbox: un evento ya confirmado, pendiente de publicar.
+type Message struct {
+	ID int64
+	// EventType es el tipo del evento ("item.created", "item.updated", "item.deleted").
+	EventType string
+	// Payload es el evento en JSON, como lo escribió quien lo generó.
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// Publisher recibe los mensajes del outbox. Un error corta la tanda: el mensaje y los que siguen se
+// vuelven a mandar en la próxima corrida, así que Publish tiene que tolerar recibir un mensaje más
+// de una vez. webhooks.Dispatcher y events.OutboxPublisher lo implementan.
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+// Store es lo que el Relay necesita de la DB. Repository la implementa.
+type Store interface {
+	Process(ctx context.Context, limit int, fn func(Message) error) (int, error)
+	PurgeProcessedBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+const (
+	// relayBatchSize es cuántos mensajes toma el relay por transacción.
+	relayBatchSize = 100
+	// processedRetention es cuánto se guardan los mensajes ya publicados, para poder revisar qué salió.
+	processedRetention = 7 * 24 * time.Hour
+)
+
+// Relay publica los mensajes pendientes del outbox, en orden.
+type Relay struct {
+	store      Store
+	publishers []Publisher
+	now        func() time.Time
+}
+
+// NewRelay crea un relay que lee de store y publica cada mensaje en publishers, en orden. Si uno
+// falla, el mensaje se vuelve a mandar a todos en la próxima corrida.
+func NewRelay(store Store, publishers ...Publisher) *Relay {
+	return &Relay{store: store, publishers: publishers, now: time.Now}
+}
+
+// Run es el job del relay: publica tandas hasta que no quedan mensajes pendientes y después borra
+// los procesados más viejos que processedRetention. Al cancelarse ctx la tanda en curso hace
+// rollback y sus mensajes quedan para la próxima corrida.
+func (relay *Relay) Run(ctx context.Context) error {
+	for {
+		processed, err := relay.store.Process(ctx, relayBatchSize, func(message Message) error {
+			for _, publisher := range relay.publishers {
+				if err := publisher.Publish(ctx, message); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if processed < relayBatchSize {
+			break
+		}
+	}
+	_, err := relay.store.PurgeProcessedBefore(ctx, relay.now().Add(-processedRetention))
+	return err
+}
@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore simula la tabla: Process entrega los pendientes en orden y marca los que fn acepta.
+type fakeStore struct {
+	pending    []Message
+	batches    []int
+	processErr error
+	purgeErr   error
+	cutoff     time.Time
+}
+
+func (store *fakeStore) Process(ctx context.Context, limit int, fn func(Message) error) (int, error) {
+	if store.processErr != nil {
+		return 0, store.processErr
+	}
+	batch := store.pending[:min(limit, len(store.pending))]
+	store.batches = append(store.batches, len(batch))
+	for index, message := range batch {
+		if err := fn(message); err != nil {
+			store.pending = store.pending[index:]
+			return index, err
+		}
+	}
+	store.pending = store.pending[len(batch):]
+	return len(batch), nil
+}
+
+func (store *fakeStore) PurgeProcessedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	store.cutoff = cutoff
+	return 0, store.purgeErr
+}
+
+type fakePublisher struct {
+	published []int64
+	failOn    int64
+}
+
+func (publisher *fakePublisher) Publish(ctx context.Context, message Message) error {
+	if message.ID == publisher.failOn {
+		return errors.New("publish failed")
+	}
+	publisher.published = append(publisher.published, message.ID)
+	return nil
+}
+
+func pendingMessages(count int) []Message {
+	messages := make([]Message, count)
+	for index := range messages {
+		messages[index] = Message{ID: int64(index + 1), EventType: "item.created", Payload: json.RawMessage(`{}`)}
+	}
+	return messages
+}
+
+func TestRelay_Run(t *testing.T) {
+	now := time.Date(2025, 6, 8, 12, 0, 0, 0, time.UTC)
+
+	t.Run("drains every batch in order and purges", func(t *testing.T) {
+		store := &fakeStore{pending: pendingMessages(relayBatchSize + 5)}
+		publisher := &fakePublisher{}
+		relay := NewRelay(store, publisher)
+		relay.now = func() time.Time { return now }
+
+		require.NoError(t, relay.Run(context.Background()))
+
+		require.Equal(t, []int{relayBatchSize, 5}, store.batches)
+		require.Len(t, publisher.published, relayBatchSize+5)
+		require.Equal(t, int64(1), publisher.published[0])
+		require.Equal(t, int64(relayBatchSize+5), publisher.published[relayBatchSize+4])
+		require.Empty(t, store.pending)
+		require.Equal(t, now.Add(-processedRetention), store.cutoff)
+	})
+
+	t.Run("publisher error stops at that message", func(t *testing.T) {
+		store := &fakeStore{pending: pendingMessages(3)}
+		publisher := &fakePublisher{failOn: 2}
+		relay := NewRelay(store, publisher)
+
+		require.EqualError(t, relay.Run(context.Background()), "publish failed")
+
+		require.Equal(t, []int64{1}, publisher.published)
+		require.Len(t, store.pending, 2, "the failed message and the rest stay pending")
+		require.True(t, store.cutoff.IsZero(), "no purge after a failed run")
+	})
+
+	t.Run("every publisher gets each message", func(t *testing.T) {
+		store := &fakeStore{pending: pendingMessages(3)}
+		first, second := &fakePublisher{}, &fakePublisher{failOn: 3}
+		relay := NewRelay(store, first, second)
+
+		require.EqualError(t, relay.Run(context.Background()), "publish failed")
+
+		require.Equal(t, []int64{1, 2, 3}, first.published)
+		require.Equal(t, []int64{1, 2}, second.published)
+		require.Len(t, store.pending, 1, "a message stays pending until every publisher takes it")
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		store := &fakeStore{processErr: errors.New("db down")}
+		relay := NewRelay(store, &fakePublisher{})
+
+		require.EqualError(t, relay.Run(context.Background()), "db down")
+	})
+
+	t.Run("purge error", func(t *testing.T) {
+		store := &fakeStore{purgeErr: errors.New("db down")}
+		relay := NewRelay(store, &fakePublisher{})
+
+		require.EqualError(t, relay.Run(context.Background()), "db down")
+	})
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// txBeginner lo implementa el pool: Process necesita una transacción para tener los locks mientras publica.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Repository lee y marca las filas de la tabla outbox. Las escribe items.Repository.InsertOutbox,
+// en la transacción de cada cambio.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio del outbox.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// purgeBatchSize es cuántas filas procesadas borra cada sentencia de PurgeProcessedBefore.
+const purgeBatchSize = 1000
+
+// Process toma hasta limit mensajes pendientes, del más viejo al más nuevo, y llama a fn con cada
+// uno. Los que fn acepta quedan con processed_at; en el primero que falla corta y devuelve ese error,
+// así el siguiente intento sigue en orden desde ahí. Todo pasa en una transacción con FOR UPDATE SKIP
+// LOCKED: otra instancia que corre a la vez toma otros mensajes en lugar de mandar los mismos, y si
+// el proceso se corta antes del commit los mensajes siguen pendientes.
+func (repository *Repository) Process(ctx context.Context, limit int, fn func(Message) error) (int, error) {
+	const claimQuery = `
+		SELECT id, event_type, payload, created_at
+		FROM outbox
+		WHERE processed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED;
+	`
+	const markQuery = `UPDATE outbox SET processed_at = now() WHERE id = ANY($1);`
+
+	beginner, ok := repository.database.(txBeginner)
+	if !ok {
+		return 0, errors.New("outbox: database does not support transactions")
+	}
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Rollback después de Commit no hace nada; cubre los caminos de error.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	messages, err := claim(ctx, tx, claimQuery, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := make([]int64, 0, len(messages))
+	var publishErr error
+	for _, message := range messages {
+		if publishErr = fn(message); publishErr != nil {
+			break
+		}
+		processed = append(processed, message.ID)
+	}
+	if len(processed) > 0 {
+		if _, err := tx.Exec(ctx, markQuery, processed); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(processed), publishErr
+}
+
+// claim lee los mensajes pendientes bloqueándolos.
+func claim(ctx context.Context, tx pgx.Tx, query string, limit int) ([]Message, error) {
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]Message, 0, limit)
+	for rows.Next() {
+		var message Message
+		if err := rows.Scan(&message.ID, &message.EventType, &message.Payload, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// PurgeProcessedBefore borra, de a tandas, los mensajes procesados antes de cutoff y devuelve cuántos borró.
+func (repository *Repository) PurgeProcessedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	const query = `
+		WITH purged AS (
+			DELETE FROM outbox
+			WHERE id IN (SELECT id FROM outbox WHERE processed_at < $1 LIMIT $2)
+			RETURNING 1
+		)
+		SELECT count(*) FROM purged;
+	`
+
+	purged := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		var batch int
+		if err := repository.database.QueryRow(ctx, query, cutoff, purgeBatchSize).Scan(&batch); err != nil {
+			return purged, err
+		}
+		purged += batch
+		if batch < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
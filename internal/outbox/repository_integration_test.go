@@ -0,0 +1,65 @@
+//go:build integration
+
+package outbox_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_Process(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	repository := outbox.NewRepository(pool)
+	// Deja el outbox vacío para que los ids del test sean los únicos pendientes.
+	_, err = repository.Process(ctx, 100000, func(outbox.Message) error { return nil })
+	require.NoError(t, err)
+
+	require.NoError(t, items.NewRepository(pool).InsertOutbox(ctx, []items.Event{
+		{ID: "id-1", Operation: items.EventCreated},
+		{ID: "id-1", Operation: items.EventDeleted},
+	}))
+
+	t.Run("a concurrent relay skips the locked messages", func(t *testing.T) {
+		var inner int
+		var innerErr error
+		var outer []string
+		processed, err := repository.Process(ctx, 1, func(message outbox.Message) error {
+			outer = append(outer, message.EventType)
+			inner, innerErr = repository.Process(ctx, 10, func(message outbox.Message) error {
+				outer = append(outer, message.EventType)
+				return nil
+			})
+			return innerErr
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Equal(t, 1, inner)
+		require.Equal(t, []string{"item.created", "item.deleted"}, outer)
+	})
+
+	t.Run("nothing left pending", func(t *testing.T) {
+		processed, err := repository.Process(ctx, 10, func(outbox.Message) error { return nil })
+
+		require.NoError(t, err)
+		require.Zero(t, processed)
+	})
+}
@@ -0,0 +1,95 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Process(t *testing.T) {
+	t.Run("database without transactions", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{})
+
+		_, err := repository.Process(context.Background(), 10, func(Message) error { return nil })
+
+		require.EqualError(t, err, "outbox: database does not support transactions")
+	})
+}
+
+func TestRepository_PurgeProcessedBefore(t *testing.T) {
+	t.Run("deletes in batches until one comes short", func(t *testing.T) {
+		database := &fakeDB{rows: []*fakeRow{{values: []any{purgeBatchSize}}, {values: []any{3}}}}
+		repository := NewRepository(database)
+		cutoff := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		purged, err := repository.PurgeProcessedBefore(context.Background(), cutoff)
+
+		require.NoError(t, err)
+		require.Equal(t, purgeBatchSize+3, purged)
+		require.Equal(t, 2, database.calls)
+		require.Contains(t, normalizeSQL(database.lastQuery), "WHERE id IN (SELECT id FROM outbox WHERE processed_at < $1 LIMIT $2)")
+		require.Equal(t, []any{cutoff, purgeBatchSize}, database.lastArgs)
+	})
+
+	t.Run("error keeps the count so far", func(t *testing.T) {
+		database := &fakeDB{rows: []*fakeRow{{values: []any{purgeBatchSize}}, {err: errors.New("db down")}}}
+		repository := NewRepository(database)
+
+		purged, err := repository.PurgeProcessedBefore(context.Background(), time.Now())
+
+		require.EqualError(t, err, "db down")
+		require.Equal(t, purgeBatchSize, purged)
+	})
+}
+
+type fakeDB struct {
+	rows      []*fakeRow
+	calls     int
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.calls >= len(db.rows) {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	db.calls++
+	return db.rows[db.calls-1]
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	return nil, errors.New("unexpected Query call")
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	if len(dest) != len(row.values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(row.values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(row.values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
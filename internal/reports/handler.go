@@ -0,0 +1,80 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	InventoryValuation(ctx context.Context, groupBy GroupBy, asOf *time.Time) (Valuation, error)
+}
+
+// Handler expone los reportes.
+type Handler struct {
+	service ServiceAPI
+}
+
+// NewHandler crea un handler de reportes.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service}
+}
+
+// dateLayout es el formato de as_of cuando viene como fecha sin hora.
+const dateLayout = "2006-01-02"
+
+// InventoryValuation maneja GET /reports/inventory-valuation?group_by=category&as_of=2025-03-31.
+func (handler *Handler) InventoryValuation(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	groupBy := GroupBy(strings.ToLower(strings.TrimSpace(query.Get("group_by"))))
+
+	var asOf *time.Time
+	if value := strings.TrimSpace(query.Get("as_of")); value != "" {
+		instant, err := parseAsOf(value)
+		if err != nil {
+			failInvalidFilter(writer, request, "as_of", "as_of must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			return
+		}
+		asOf = &instant
+	}
+
+	valuation, err := handler.service.InventoryValuation(request.Context(), groupBy, asOf)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrorInvalidGroupBy):
+			failInvalidFilter(writer, request, "group_by", err.Error())
+		case errors.Is(err, ErrorAsOfInFuture):
+			failInvalidFilter(writer, request, "as_of", err.Error())
+		default:
+			httpx.FailUnexpected(writer, request, err)
+		}
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, valuation)
+}
+
+// parseAsOf lee as_of como un instante RFC 3339 o como una fecha, que se toma al cierre del día
+// (el comienzo del día siguiente, en UTC): as_of=2025-03-31 incluye todo lo que pasó el 31.
+func parseAsOf(value string) (time.Time, error) {
+	if instant, err := time.Parse(time.RFC3339, value); err == nil {
+		return instant, nil
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.AddDate(0, 0, 1), nil
+}
+
+// failInvalidFilter responde 400 invalid_filter con el parámetro que falló, como GET /items.
+func failInvalidFilter(writer http.ResponseWriter, request *http.Request, field, message string) {
+	httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_filter", "invalid filter parameters", []httpx.ErrorDetail{
+		{Field: field, Message: message},
+	})
+}
@@ -0,0 +1,153 @@
+package reports_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/reports"
+	"github.com/stretchr/testify/require"
+)
+
+type stubService struct {
+	valuationFn func(ctx context.Context, groupBy reports.GroupBy, asOf *time.Time) (reports.Valuation, error)
+	called      bool
+	groupBy     reports.GroupBy
+	asOf        *time.Time
+}
+
+func (service *stubService) InventoryValuation(ctx context.Context, groupBy reports.GroupBy, asOf *time.Time) (reports.Valuation, error) {
+	service.called = true
+	service.groupBy = groupBy
+	service.asOf = asOf
+	if service.valuationFn != nil {
+		return service.valuationFn(ctx, groupBy, asOf)
+	}
+	return reports.Valuation{GroupBy: groupBy, Rows: []reports.ValuationRow{}}, nil
+}
+
+func TestHandler_InventoryValuation(t *testing.T) {
+	valuate := func(service *stubService, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		reports.NewHandler(service).InventoryValuation(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	t.Run("rows with values as strings", func(t *testing.T) {
+		category := "cat-1"
+		name := "Peripherals"
+		service := &stubService{
+			valuationFn: func(ctx context.Context, groupBy reports.GroupBy, asOf *time.Time) (reports.Valuation, error) {
+				return reports.Valuation{
+					GroupBy: reports.GroupByCategory,
+					AsOf:    time.Date(2025, 4, 15, 12, 0, 0, 0, time.UTC),
+					Rows: []reports.ValuationRow{
+						{GroupID: &category, Group: &name, Currency: "USD", ItemCount: 2, TotalUnits: 7, TotalValue: "120.50"},
+						{Currency: "EUR", ItemCount: 1, TotalUnits: 3, TotalValue: "9.99"},
+					},
+				}, nil
+			},
+		}
+
+		rec := valuate(service, "/reports/inventory-valuation?group_by=Category")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "category", data["group_by"])
+		require.Equal(t, "2025-04-15T12:00:00Z", data["as_of"])
+		rows := data["rows"].([]any)
+		require.Len(t, rows, 2)
+		require.Equal(t, map[string]any{
+			"group_id": "cat-1", "group": "Peripherals", "currency": "USD",
+			"item_count": json.Number("2"), "total_units": json.Number("7"), "total_value": "120.50",
+		}, asMap(t, rows[0]))
+		require.Nil(t, asMap(t, rows[1])["group"])
+		require.Equal(t, reports.GroupByCategory, service.groupBy)
+		require.Nil(t, service.asOf)
+	})
+
+	t.Run("as of a date is the end of that day", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := valuate(service, "/reports/inventory-valuation?as_of=2025-03-31")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), *service.asOf)
+		require.Empty(t, service.groupBy)
+	})
+
+	t.Run("as of an instant", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := valuate(service, "/reports/inventory-valuation?group_by=brand&as_of=2025-03-31T18:00:00-03:00")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, time.Date(2025, 3, 31, 21, 0, 0, 0, time.UTC).Equal(*service.asOf))
+		require.Equal(t, reports.GroupByBrand, service.groupBy)
+	})
+
+	t.Run("malformed as of", func(t *testing.T) {
+		service := &stubService{}
+
+		rec := valuate(service, "/reports/inventory-valuation?as_of=last-month")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeResponse(t, rec)
+		require.Equal(t, "invalid_filter", resp.Error.Code)
+		require.Equal(t, "as_of", resp.Error.Details[0].Field)
+		require.False(t, service.called)
+	})
+
+	errorTests := []struct {
+		name      string
+		err       error
+		wantCode  int
+		wantField string
+	}{
+		{"unknown group", reports.ErrorInvalidGroupBy, http.StatusBadRequest, "group_by"},
+		{"future as of", reports.ErrorAsOfInFuture, http.StatusBadRequest, "as_of"},
+		{"unexpected", errors.New("db down"), http.StatusInternalServerError, ""},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{
+				valuationFn: func(ctx context.Context, groupBy reports.GroupBy, asOf *time.Time) (reports.Valuation, error) {
+					return reports.Valuation{}, tt.err
+				},
+			}
+
+			rec := valuate(service, "/reports/inventory-valuation")
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantField != "" {
+				resp := decodeResponse(t, rec)
+				require.Equal(t, "invalid_filter", resp.Error.Code)
+				require.Equal(t, tt.wantField, resp.Error.Details[0].Field)
+			}
+		})
+	}
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
@@ -0,0 +1,31 @@
+package reports
+
+import "time"
+
+// GroupBy es el criterio de agrupación del reporte de valuación.
+type GroupBy string
+
+const (
+	GroupByCategory GroupBy = "category"
+	GroupByBrand    GroupBy = "brand"
+)
+
+// ValuationRow es el stock valuado de un grupo en una moneda: los precios de monedas distintas no se
+// suman entre sí, así que un grupo con items en dos monedas tiene dos filas. GroupID y Group son nil
+// para los items sin categoría (o sin marca). TotalValue es la suma de precio de lista por unidades,
+// como string para no perder precisión.
+type ValuationRow struct {
+	GroupID    *string `json:"group_id"`
+	Group      *string `json:"group"`
+	Currency   string  `json:"currency"`
+	ItemCount  int64   `json:"item_count"`
+	TotalUnits int64   `json:"total_units"`
+	TotalValue string  `json:"total_value"`
+}
+
+// Valuation es el reporte de valuación de inventario: una fila por grupo y moneda, al momento AsOf.
+type Valuation struct {
+	GroupBy GroupBy        `json:"group_by"`
+	AsOf    time.Time      `json:"as_of"`
+	Rows    []ValuationRow `json:"rows"`
+}
@@ -0,0 +1,108 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Repository calcula los reportes con agregaciones en SQL sobre items y sus historiales.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de reportes.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// valuationGroup es la columna de items y la tabla por las que agrupa cada GroupBy. Son los
+// únicos identificadores que se interpolan en la query.
+type valuationGroup struct {
+	column string
+	table  string
+}
+
+var valuationGroups = map[GroupBy]valuationGroup{
+	GroupByCategory: {column: "category_id", table: "categories"},
+	GroupByBrand:    {column: "brand_id", table: "brands"},
+}
+
+// currentSnapshot es el stock y el precio actuales de los items vivos. %s es la columna del grupo.
+const currentSnapshot = `
+	SELECT items.%s AS group_id, items.currency, greatest(items.stock, 0) AS units, items.price
+	FROM items
+	WHERE items.deleted_at IS NULL`
+
+// asOfSnapshot reconstruye el stock y el precio de cada item al momento $1 a partir de los historiales:
+// el último movimiento (o cambio de precio) anterior a $1 dice cómo quedó; si no hay, el primero
+// posterior dice cómo estaba antes; si el item no tiene historial, no cambió y vale lo actual.
+// Entran los items creados antes de $1 que no estaban borrados en ese momento. La categoría, la marca
+// y la moneda son las actuales: no tienen historial.
+const asOfSnapshot = `
+	SELECT items.%s AS group_id, items.currency,
+		greatest(coalesce(
+			(SELECT movements.resulting_stock FROM stock_movements movements
+			 WHERE movements.item_id = items.id AND movements.created_at < $1
+			 ORDER BY movements.created_at DESC, movements.id DESC LIMIT 1),
+			(SELECT movements.resulting_stock - movements.delta FROM stock_movements movements
+			 WHERE movements.item_id = items.id AND movements.created_at >= $1
+			 ORDER BY movements.created_at, movements.id LIMIT 1),
+			items.stock), 0) AS units,
+		coalesce(
+			(SELECT history.new_price FROM price_history history
+			 WHERE history.item_id = items.id AND history.changed_at < $1
+			 ORDER BY history.changed_at DESC, history.id DESC LIMIT 1),
+			(SELECT history.old_price FROM price_history history
+			 WHERE history.item_id = items.id AND history.changed_at >= $1
+			 ORDER BY history.changed_at, history.id LIMIT 1),
+			items.price) AS price
+	FROM items
+	WHERE items.created_at < $1 AND (items.deleted_at IS NULL OR items.deleted_at >= $1)`
+
+// InventoryValuation suma unidades y valor (precio de lista por stock) por grupo y moneda. El stock
+// negativo de los items con backorder cuenta como cero. Con asOf nil valúa lo actual; si no,
+// reconstruye el stock y el precio de ese momento con stock_movements y price_history.
+// Los items sin grupo van al final, en una fila con GroupID nil.
+func (repository *Repository) InventoryValuation(ctx context.Context, groupBy GroupBy, asOf *time.Time) ([]ValuationRow, error) {
+	group, ok := valuationGroups[groupBy]
+	if !ok {
+		return nil, ErrorInvalidGroupBy
+	}
+
+	snapshot := fmt.Sprintf(currentSnapshot, group.column)
+	var args []any
+	if asOf != nil {
+		snapshot = fmt.Sprintf(asOfSnapshot, group.column)
+		args = append(args, *asOf)
+	}
+	query := `WITH snapshot AS (` + snapshot + `)
+		SELECT groups.id, groups.name, snapshot.currency, count(*), sum(snapshot.units), sum(snapshot.price * snapshot.units)::text
+		FROM snapshot
+		LEFT JOIN ` + group.table + ` groups ON groups.id = snapshot.group_id
+		GROUP BY groups.id, groups.name, snapshot.currency
+		ORDER BY groups.name NULLS LAST, groups.id, snapshot.currency;`
+
+	rows, err := repository.database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	valuation := make([]ValuationRow, 0)
+	for rows.Next() {
+		var row ValuationRow
+		if err := rows.Scan(&row.GroupID, &row.Group, &row.Currency, &row.ItemCount, &row.TotalUnits, &row.TotalValue); err != nil {
+			return nil, err
+		}
+		valuation = append(valuation, row)
+	}
+	return valuation, rows.Err()
+}
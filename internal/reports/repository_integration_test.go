@@ -0,0 +1,91 @@
+//go:build integration
+
+package reports_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/brands"
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/reports"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_InventoryValuation(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	brandService := brands.NewService(brands.NewRepository(pool))
+	itemsRepository := items.NewRepository(pool)
+	itemsService := items.NewService(itemsRepository)
+	repository := reports.NewRepository(pool)
+
+	brand, err := brandService.Create(ctx, brands.BrandInput{Name: "Valuation " + uuid.NewString()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = brandService.Delete(ctx, brand.ID) })
+
+	create := func(price string, stock int) items.Item {
+		sku := "IT-" + strings.ToUpper(uuid.NewString()[:8])
+		item, err := itemsService.Create(ctx, items.CreateItemInput{Name: "Valued " + uuid.NewString(), SKU: &sku, BrandID: &brand.ID, Price: price, Stock: stock})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = itemsRepository.Delete(ctx, item.ID, nil)
+			_ = itemsRepository.Purge(ctx, item.ID)
+		})
+		return item
+	}
+	rowFor := func(rows []reports.ValuationRow) reports.ValuationRow {
+		for _, row := range rows {
+			if row.GroupID != nil && *row.GroupID == brand.ID {
+				return row
+			}
+		}
+		t.Fatalf("no row for brand %s", brand.ID)
+		return reports.ValuationRow{}
+	}
+
+	first := create("10.00", 5)
+	create("2.50", 4)
+
+	var before time.Time
+	require.NoError(t, pool.QueryRow(ctx, `SELECT clock_timestamp()`).Scan(&before))
+
+	// Después del corte: el primero cambia de stock y de precio, y entra un item nuevo.
+	_, err = itemsService.AdjustStock(ctx, first.ID, items.StockAdjustmentInput{Delta: 3})
+	require.NoError(t, err)
+	price := "12.00"
+	_, err = itemsService.Update(ctx, first.ID, items.UpdateItemInput{Price: &price})
+	require.NoError(t, err)
+	create("1.00", 100)
+
+	current, err := repository.InventoryValuation(ctx, reports.GroupByBrand, nil)
+	require.NoError(t, err)
+	row := rowFor(current)
+	require.Equal(t, brand.Name, *row.Group)
+	require.Equal(t, int64(3), row.ItemCount)
+	require.Equal(t, int64(112), row.TotalUnits)
+	require.Equal(t, "206.00", row.TotalValue)
+
+	past, err := repository.InventoryValuation(ctx, reports.GroupByBrand, &before)
+	require.NoError(t, err)
+	row = rowFor(past)
+	require.Equal(t, int64(2), row.ItemCount)
+	require.Equal(t, int64(9), row.TotalUnits)
+	require.Equal(t, "60.00", row.TotalValue)
+}
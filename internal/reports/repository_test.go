@@ -0,0 +1,139 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_InventoryValuation(t *testing.T) {
+	category := "cat-1"
+	categoryName := "Peripherals"
+
+	t.Run("current values by category", func(t *testing.T) {
+		database := &fakeDB{rows: &fakeRows{rows: [][]any{
+			{&category, &categoryName, "USD", int64(2), int64(7), "120.50"},
+			{(*string)(nil), (*string)(nil), "USD", int64(1), int64(0), "0.00"},
+		}}}
+		repository := NewRepository(database)
+
+		rows, err := repository.InventoryValuation(context.Background(), GroupByCategory, nil)
+
+		require.NoError(t, err)
+		require.Equal(t, []ValuationRow{
+			{GroupID: &category, Group: &categoryName, Currency: "USD", ItemCount: 2, TotalUnits: 7, TotalValue: "120.50"},
+			{Currency: "USD", ItemCount: 1, TotalUnits: 0, TotalValue: "0.00"},
+		}, rows)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "SELECT items.category_id AS group_id, items.currency, greatest(items.stock, 0) AS units, items.price FROM items WHERE items.deleted_at IS NULL")
+		require.Contains(t, query, "count(*), sum(snapshot.units), sum(snapshot.price * snapshot.units)::text")
+		require.Contains(t, query, "LEFT JOIN categories groups ON groups.id = snapshot.group_id GROUP BY groups.id, groups.name, snapshot.currency")
+		require.NotContains(t, query, "stock_movements")
+		require.Empty(t, database.lastArgs)
+		require.True(t, database.rows.closed)
+	})
+
+	t.Run("as of replays the ledgers", func(t *testing.T) {
+		database := &fakeDB{rows: &fakeRows{}}
+		repository := NewRepository(database)
+		asOf := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+		rows, err := repository.InventoryValuation(context.Background(), GroupByBrand, &asOf)
+
+		require.NoError(t, err)
+		require.Empty(t, rows)
+		require.NotNil(t, rows)
+		query := normalizeSQL(database.lastQuery)
+		require.Contains(t, query, "SELECT items.brand_id AS group_id")
+		require.Contains(t, query, "movements.item_id = items.id AND movements.created_at < $1 ORDER BY movements.created_at DESC, movements.id DESC LIMIT 1")
+		require.Contains(t, query, "SELECT movements.resulting_stock - movements.delta FROM stock_movements movements")
+		require.Contains(t, query, "history.item_id = items.id AND history.changed_at < $1 ORDER BY history.changed_at DESC, history.id DESC LIMIT 1")
+		require.Contains(t, query, "SELECT history.old_price FROM price_history history")
+		require.Contains(t, query, "WHERE items.created_at < $1 AND (items.deleted_at IS NULL OR items.deleted_at >= $1)")
+		require.Contains(t, query, "LEFT JOIN brands groups")
+		require.Equal(t, []any{asOf}, database.lastArgs)
+	})
+
+	t.Run("unknown group never reaches the database", func(t *testing.T) {
+		database := &fakeDB{}
+		repository := NewRepository(database)
+
+		_, err := repository.InventoryValuation(context.Background(), "items; DROP TABLE items", nil)
+
+		require.ErrorIs(t, err, ErrorInvalidGroupBy)
+		require.Empty(t, database.lastQuery)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		database := &fakeDB{err: &pgconn.PgError{Code: "57014"}}
+		repository := NewRepository(database)
+
+		_, err := repository.InventoryValuation(context.Background(), GroupByCategory, nil)
+
+		require.Error(t, err)
+	})
+}
+
+type fakeDB struct {
+	rows      *fakeRows
+	err       error
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.err != nil {
+		return nil, db.err
+	}
+	if db.rows == nil {
+		return nil, errors.New("unexpected Query call")
+	}
+	return db.rows, nil
+}
+
+type fakeRows struct {
+	rows   [][]any
+	index  int
+	closed bool
+}
+
+func (rows *fakeRows) Close()                                       { rows.closed = true }
+func (rows *fakeRows) Err() error                                   { return nil }
+func (rows *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (rows *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (rows *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (rows *fakeRows) RawValues() [][]byte                          { return nil }
+func (rows *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (rows *fakeRows) Next() bool {
+	if rows.index >= len(rows.rows) {
+		return false
+	}
+	rows.index++
+	return true
+}
+
+func (rows *fakeRows) Scan(dest ...any) error {
+	values := rows.rows[rows.index-1]
+	if len(dest) != len(values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -0,0 +1,10 @@
+package reports
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra las rutas de reportes en el router.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Route("/reports", func(route chi.Router) {
+		route.Get("/inventory-valuation", handler.InventoryValuation)
+	})
+}
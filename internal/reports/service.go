@@ -0,0 +1,63 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Errores de dominio (no HTTP). El handler los traduce a status codes.
+var (
+	// ErrorInvalidGroupBy indica un group_by que no es category ni brand.
+	ErrorInvalidGroupBy = errors.New("group_by must be category or brand")
+	// ErrorAsOfInFuture indica un as_of posterior a ahora: todavía no hay stock que valuar.
+	ErrorAsOfInFuture = errors.New("as_of must not be in the future")
+)
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	InventoryValuation(ctx context.Context, groupBy GroupBy, asOf *time.Time) ([]ValuationRow, error)
+}
+
+// Service contiene las reglas de los reportes.
+type Service struct {
+	repository RepositoryAPI
+	// now es el reloj con el que se rechaza un as_of futuro; los tests lo fijan.
+	now func() time.Time
+}
+
+// NewService crea un service de reportes.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository, now: time.Now}
+}
+
+// InventoryValuation devuelve la valuación del inventario agrupada por groupBy (category si viene
+// vacío). Con asOf nil es la valuación actual; si no, la de ese momento. Un asOf posterior al cierre
+// de hoy es ErrorAsOfInFuture.
+func (service *Service) InventoryValuation(ctx context.Context, groupBy GroupBy, asOf *time.Time) (Valuation, error) {
+	if groupBy == "" {
+		groupBy = GroupByCategory
+	}
+	if _, ok := valuationGroups[groupBy]; !ok {
+		return Valuation{}, ErrorInvalidGroupBy
+	}
+	now := service.now().UTC()
+	if asOf != nil && asOf.After(now) {
+		// as_of=<hoy> es el cierre del día, que todavía no llegó: vale lo actual.
+		tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		if asOf.After(tomorrow) {
+			return Valuation{}, ErrorAsOfInFuture
+		}
+		asOf = nil
+	}
+
+	rows, err := service.repository.InventoryValuation(ctx, groupBy, asOf)
+	if err != nil {
+		return Valuation{}, err
+	}
+	valuation := Valuation{GroupBy: groupBy, AsOf: now, Rows: rows}
+	if asOf != nil {
+		valuation.AsOf = asOf.UTC()
+	}
+	return valuation, nil
+}
@@ -0,0 +1,96 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	called  bool
+	groupBy GroupBy
+	asOf    *time.Time
+	rows    []ValuationRow
+	err     error
+}
+
+func (fakerepo *fakeRepo) InventoryValuation(ctx context.Context, groupBy GroupBy, asOf *time.Time) ([]ValuationRow, error) {
+	fakerepo.called = true
+	fakerepo.groupBy = groupBy
+	fakerepo.asOf = asOf
+	return fakerepo.rows, fakerepo.err
+}
+
+func TestService_InventoryValuation(t *testing.T) {
+	now := time.Date(2025, 4, 15, 12, 0, 0, 0, time.UTC)
+	newService := func(repository *fakeRepo) *Service {
+		service := NewService(repository)
+		service.now = func() time.Time { return now }
+		return service
+	}
+
+	t.Run("current values grouped by category by default", func(t *testing.T) {
+		rows := []ValuationRow{{Currency: "USD", ItemCount: 2, TotalUnits: 5, TotalValue: "50.00"}}
+		repository := &fakeRepo{rows: rows}
+
+		valuation, err := newService(repository).InventoryValuation(context.Background(), "", nil)
+
+		require.NoError(t, err)
+		require.Equal(t, Valuation{GroupBy: GroupByCategory, AsOf: now, Rows: rows}, valuation)
+		require.Equal(t, GroupByCategory, repository.groupBy)
+		require.Nil(t, repository.asOf)
+	})
+
+	t.Run("as of a past instant", func(t *testing.T) {
+		repository := &fakeRepo{}
+		asOf := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+		valuation, err := newService(repository).InventoryValuation(context.Background(), GroupByBrand, &asOf)
+
+		require.NoError(t, err)
+		require.Equal(t, asOf, valuation.AsOf)
+		require.Equal(t, GroupByBrand, valuation.GroupBy)
+		require.Equal(t, &asOf, repository.asOf)
+	})
+
+	t.Run("the end of today is the current valuation", func(t *testing.T) {
+		repository := &fakeRepo{}
+		endOfToday := time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)
+
+		valuation, err := newService(repository).InventoryValuation(context.Background(), GroupByCategory, &endOfToday)
+
+		require.NoError(t, err)
+		require.Equal(t, now, valuation.AsOf)
+		require.Nil(t, repository.asOf)
+	})
+
+	t.Run("after today", func(t *testing.T) {
+		repository := &fakeRepo{}
+		asOf := time.Date(2025, 4, 17, 0, 0, 0, 0, time.UTC)
+
+		_, err := newService(repository).InventoryValuation(context.Background(), GroupByCategory, &asOf)
+
+		require.ErrorIs(t, err, ErrorAsOfInFuture)
+		require.False(t, repository.called)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		repository := &fakeRepo{}
+
+		_, err := newService(repository).InventoryValuation(context.Background(), "supplier", nil)
+
+		require.ErrorIs(t, err, ErrorInvalidGroupBy)
+		require.False(t, repository.called)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repositoryErr := errors.New("db down")
+
+		_, err := newService(&fakeRepo{err: repositoryErr}).InventoryValuation(context.Background(), GroupByCategory, nil)
+
+		require.ErrorIs(t, err, repositoryErr)
+	})
+}
@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+)
+
+// Rutas de los snapshots. Quedan fuera del timeout de los requests: duran lo que tarde la descarga
+// o la subida del catálogo entero.
+const (
+	ExportPath = "/admin/export/snapshot"
+	ImportPath = "/admin/import/snapshot"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	Export(ctx context.Context, writer io.Writer) error
+	Import(ctx context.Context, reader io.Reader, force bool) (Manifest, error)
+}
+
+// Handler expone el export y el restore de snapshots del catálogo.
+type Handler struct {
+	service ServiceAPI
+	now     func() time.Time
+}
+
+// NewHandler crea un handler de snapshots.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service, now: time.Now}
+}
+
+// Export maneja GET /admin/export/snapshot: el catálogo entero, borrados incluidos, como NDJSON
+// comprimido con gzip. Los headers salen con el primer byte; si la lectura falla después, la
+// respuesta se corta y el cliente ve una descarga incompleta (el gzip no cierra).
+func (handler *Handler) Export(writer http.ResponseWriter, request *http.Request) {
+	lazy := &lazyWriter{writer: writer, begin: func() {
+		header := writer.Header()
+		header.Set("Content-Type", "application/gzip")
+		header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog-snapshot-%s.ndjson.gz"`, handler.now().UTC().Format("20060102T150405Z")))
+		// nginx bufferea las respuestas por defecto; sin esto la descarga llegaría de a tandas grandes.
+		header.Set("X-Accel-Buffering", "no")
+		writer.WriteHeader(http.StatusOK)
+	}}
+	err := handler.service.Export(request.Context(), lazy)
+	switch {
+	case err == nil:
+	case !lazy.started:
+		httpx.FailUnexpected(writer, request, err)
+	default:
+		log.Printf("warn: snapshot_export_aborted request_id=%s err=%v", httpx.RequestIDFrom(request), err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// Import maneja POST /admin/import/snapshot con un snapshot de Export como body. Solo restaura en
+// una base sin items, salvo con ?force=true, que reemplaza el catálogo (y borra su historial).
+func (handler *Handler) Import(writer http.ResponseWriter, request *http.Request) {
+	force := false
+	if value := request.URL.Query().Get("force"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			httpx.Fail(writer, request, http.StatusBadRequest, "invalid_force", "force must be true or false")
+			return
+		}
+		force = parsed
+	}
+
+	manifest, err := handler.service.Import(request.Context(), request.Body, force)
+	var invalid *InvalidError
+	var conflict *ConflictError
+	switch {
+	case err == nil:
+		httpx.OK(writer, request, http.StatusOK, manifest)
+	case errors.As(err, &invalid):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_snapshot", invalid.Message)
+	case errors.Is(err, ErrorSchemaMismatch):
+		httpx.Fail(writer, request, http.StatusConflict, "schema_mismatch", err.Error())
+	case errors.Is(err, ErrorNotEmpty):
+		httpx.Fail(writer, request, http.StatusConflict, "catalog_not_empty", "the catalog already has items; use force=true to replace it")
+	case errors.As(err, &conflict):
+		httpx.Fail(writer, request, http.StatusConflict, "snapshot_conflict", conflict.Error())
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
+
+// lazyWriter manda los headers con el primer Write: hasta entonces un error todavía puede
+// responder con un JSON de error.
+type lazyWriter struct {
+	writer  http.ResponseWriter
+	begin   func()
+	started bool
+}
+
+func (lazy *lazyWriter) Write(data []byte) (int, error) {
+	if !lazy.started {
+		lazy.started = true
+		lazy.begin()
+	}
+	return lazy.writer.Write(data)
+}
@@ -0,0 +1,161 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/snapshot"
+)
+
+type stubService struct {
+	exportFn func(ctx context.Context, writer io.Writer) error
+	importFn func(ctx context.Context, reader io.Reader, force bool) (snapshot.Manifest, error)
+}
+
+func (service *stubService) Export(ctx context.Context, writer io.Writer) error {
+	if service.exportFn == nil {
+		return nil
+	}
+	return service.exportFn(ctx, writer)
+}
+
+func (service *stubService) Import(ctx context.Context, reader io.Reader, force bool) (snapshot.Manifest, error) {
+	if service.importFn == nil {
+		return snapshot.Manifest{}, nil
+	}
+	return service.importFn(ctx, reader, force)
+}
+
+func TestHandler_Export(t *testing.T) {
+	t.Run("streams the snapshot", func(t *testing.T) {
+		handler := snapshot.NewHandler(&stubService{exportFn: func(ctx context.Context, writer io.Writer) error {
+			_, err := writer.Write([]byte("gzip bytes"))
+			return err
+		}})
+		rec := httptest.NewRecorder()
+
+		handler.Export(rec, httptest.NewRequest(http.MethodGet, snapshot.ExportPath, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+		require.Regexp(t, `^attachment; filename="catalog-snapshot-\d{8}T\d{6}Z\.ndjson\.gz"$`, rec.Header().Get("Content-Disposition"))
+		require.Equal(t, "gzip bytes", rec.Body.String())
+	})
+
+	t.Run("error before the first byte", func(t *testing.T) {
+		handler := snapshot.NewHandler(&stubService{exportFn: func(ctx context.Context, writer io.Writer) error {
+			return errors.New("connection refused")
+		}})
+		rec := httptest.NewRecorder()
+
+		handler.Export(rec, httptest.NewRequest(http.MethodGet, snapshot.ExportPath, nil))
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.Empty(t, rec.Header().Get("Content-Disposition"))
+		require.Equal(t, "internal_error", decodeResponse(t, rec).Error.Code)
+	})
+
+	t.Run("error mid-stream aborts", func(t *testing.T) {
+		handler := snapshot.NewHandler(&stubService{exportFn: func(ctx context.Context, writer io.Writer) error {
+			_, _ = writer.Write([]byte("partial"))
+			return errors.New("connection reset")
+		}})
+		rec := httptest.NewRecorder()
+
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.Export(rec, httptest.NewRequest(http.MethodGet, snapshot.ExportPath, nil))
+		})
+	})
+}
+
+func TestHandler_Import(t *testing.T) {
+	t.Run("restores and returns the manifest", func(t *testing.T) {
+		var body string
+		var force bool
+		handler := snapshot.NewHandler(&stubService{importFn: func(ctx context.Context, reader io.Reader, forced bool) (snapshot.Manifest, error) {
+			data, err := io.ReadAll(reader)
+			body, force = string(data), forced
+			return snapshot.Manifest{SchemaVersion: 37, Counts: map[string]int64{"items": 2}}, err
+		}})
+		rec := httptest.NewRecorder()
+
+		handler.Import(rec, httptest.NewRequest(http.MethodPost, snapshot.ImportPath+"?force=true", bytes.NewBufferString("gzip bytes")))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "gzip bytes", body)
+		require.True(t, force)
+		data := decodeResponse(t, rec).Data.(map[string]any)
+		require.Equal(t, json.Number("37"), data["schema_version"])
+		require.Equal(t, map[string]any{"items": json.Number("2")}, data["counts"])
+	})
+
+	t.Run("invalid force", func(t *testing.T) {
+		service := &stubService{importFn: func(ctx context.Context, reader io.Reader, force bool) (snapshot.Manifest, error) {
+			t.Fatal("service must not be called")
+			return snapshot.Manifest{}, nil
+		}}
+		rec := httptest.NewRecorder()
+
+		snapshot.NewHandler(service).Import(rec, httptest.NewRequest(http.MethodPost, snapshot.ImportPath+"?force=yes", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_force", decodeResponse(t, rec).Error.Code)
+	})
+
+	for name, test := range map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"invalid snapshot": {&snapshot.InvalidError{Message: "missing manifest"}, http.StatusBadRequest, "invalid_snapshot"},
+		"schema mismatch":  {snapshot.ErrorSchemaMismatch, http.StatusConflict, "schema_mismatch"},
+		"not empty":        {snapshot.ErrorNotEmpty, http.StatusConflict, "catalog_not_empty"},
+		"conflict":         {&snapshot.ConflictError{Table: "categories", Constraint: "ux_categories_name"}, http.StatusConflict, "snapshot_conflict"},
+		"unexpected":       {errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := snapshot.NewHandler(&stubService{importFn: func(ctx context.Context, reader io.Reader, force bool) (snapshot.Manifest, error) {
+				return snapshot.Manifest{}, test.err
+			}})
+			rec := httptest.NewRecorder()
+
+			handler.Import(rec, httptest.NewRequest(http.MethodPost, snapshot.ImportPath, nil))
+
+			require.Equal(t, test.status, rec.Code)
+			require.Equal(t, test.code, decodeResponse(t, rec).Error.Code)
+		})
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	router := chi.NewRouter()
+	snapshot.RegisterRoutes(router, snapshot.NewHandler(&stubService{}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, snapshot.ImportPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, snapshot.ExportPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
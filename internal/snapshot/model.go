@@ -0,0 +1,65 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Tables son las tablas del catálogo que entran en un snapshot, en el orden en que se exportan y
+// se restauran: cada tabla va después de las que referencia. El historial (precios, stock,
+// auditoría), las reservas, los webhooks, el outbox y los jobs no entran: un snapshot es el
+// catálogo, no la actividad sobre él.
+var Tables = []string{
+	"categories",
+	"brands",
+	"items",
+	"item_variants",
+	"item_translations",
+	"item_external_refs",
+	"price_schedules",
+}
+
+// Manifest es la primera línea de un snapshot. SchemaVersion es la versión de las migraciones de
+// la base exportada: las filas van con las columnas tal cual, así que solo se pueden restaurar en
+// una base con la misma versión.
+type Manifest struct {
+	SchemaVersion int64            `json:"schema_version"`
+	ExportedAt    time.Time        `json:"exported_at"`
+	Counts        map[string]int64 `json:"counts"`
+}
+
+// Record es cada línea de un snapshot después del manifest: una fila de Table como la devuelve
+// row_to_json.
+type Record struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+var (
+	// ErrorNotEmpty es un restore sobre una base con items (borrados incluidos) sin ?force=true.
+	ErrorNotEmpty = errors.New("items table is not empty")
+	// ErrorSchemaMismatch es un snapshot de otra versión de las migraciones.
+	ErrorSchemaMismatch = errors.New("snapshot schema version does not match the database")
+)
+
+// InvalidError es un snapshot que no se puede leer: no es gzip, falta el manifest, una línea no
+// es JSON, nombra una tabla desconocida o las filas no coinciden con los conteos del manifest.
+type InvalidError struct {
+	Message string
+}
+
+func (err *InvalidError) Error() string {
+	return "invalid snapshot: " + err.Message
+}
+
+// ConflictError es una fila que viola una restricción de la base al restaurarla; pasa cuando, sin
+// force, quedaron categorías o marcas con el mismo nombre que las del snapshot.
+type ConflictError struct {
+	Table      string
+	Constraint string
+}
+
+func (err *ConflictError) Error() string {
+	return "snapshot rows of " + err.Table + " conflict with existing data (" + err.Constraint + ")"
+}
@@ -0,0 +1,220 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// txBeginner lo implementa el pool: el export y el restore corren enteros en una transacción.
+type txBeginner interface {
+	BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error)
+}
+
+// InsertFunc inserta filas de table en la transacción del restore.
+type InsertFunc func(ctx context.Context, table string, rows []json.RawMessage) error
+
+// Repository lee y restaura las tablas del catálogo.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de snapshots.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// begin abre la transacción del export o del restore.
+func (repository *Repository) begin(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error) {
+	beginner, ok := repository.database.(txBeginner)
+	if !ok {
+		return nil, errors.New("snapshot: database does not support transactions")
+	}
+	return beginner.BeginTx(ctx, options)
+}
+
+// Export lee el catálogo en una transacción de solo lectura REPEATABLE READ: el manifest y las
+// filas son la misma foto aunque haya escrituras mientras dura la descarga. Llama a manifest una
+// vez y después a row por cada fila de cada tabla de Tables, en ese orden.
+func (repository *Repository) Export(ctx context.Context, manifest func(Manifest) error, row func(table string, row []byte) error) error {
+	tx, err := repository.begin(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	// Rollback después de Commit no hace nada; cubre los caminos de error.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	header, err := readManifest(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := manifest(header); err != nil {
+		return err
+	}
+	for _, table := range Tables {
+		if err := exportTable(ctx, tx, table, row); err != nil {
+			return fmt.Errorf("export %s: %w", table, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// readManifest lee la versión de las migraciones y cuenta las filas de cada tabla en una sola
+// query. Una base sin versión limpia (migración a medias) no se exporta.
+func readManifest(ctx context.Context, tx pgx.Tx) (Manifest, error) {
+	counts := make([]string, len(Tables))
+	for index, table := range Tables {
+		counts[index] = "(SELECT count(*) FROM " + pgx.Identifier{table}.Sanitize() + ")"
+	}
+	query := "SELECT version, now(), " + strings.Join(counts, ", ") + " FROM schema_migrations WHERE NOT dirty;"
+
+	manifest := Manifest{Counts: make(map[string]int64, len(Tables))}
+	values := make([]int64, len(Tables))
+	dest := []any{&manifest.SchemaVersion, &manifest.ExportedAt}
+	for index := range values {
+		dest = append(dest, &values[index])
+	}
+	if err := tx.QueryRow(ctx, query).Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Manifest{}, errors.New("snapshot: the database has no clean migration version")
+		}
+		return Manifest{}, err
+	}
+	for index, table := range Tables {
+		manifest.Counts[table] = values[index]
+	}
+	return manifest, nil
+}
+
+// exportTable llama a row con cada fila de table, serializada por row_to_json.
+func exportTable(ctx context.Context, tx pgx.Tx, table string, row func(table string, row []byte) error) error {
+	rows, err := tx.Query(ctx, "SELECT row_to_json(t)::text FROM "+pgx.Identifier{table}.Sanitize()+" AS t;")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line []byte
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		if err := row(table, line); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Restore carga un snapshot en una transacción. Bloquea las tablas de Tables contra escrituras,
+// verifica que schemaVersion sea la versión de la base y que items esté vacía (borrados incluidos);
+// con force, en cambio, vacía las tablas y en cascada todo lo que cuelga de los items, historial
+// incluido. Después llama a load con la función que inserta las filas: si load falla no queda nada.
+// Al confirmar avisa en items.ChangesChannel, para que las otras instancias descarten lo cacheado.
+func (repository *Repository) Restore(ctx context.Context, schemaVersion int64, force bool, load func(insert InsertFunc) error) error {
+	tx, err := repository.begin(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tables := tableList()
+	if _, err := tx.Exec(ctx, "LOCK TABLE "+tables+" IN EXCLUSIVE MODE;"); err != nil {
+		return err
+	}
+
+	var current int64
+	var populated bool
+	const check = `
+		SELECT coalesce((SELECT version FROM schema_migrations WHERE NOT dirty), 0), EXISTS (SELECT 1 FROM items);
+	`
+	if err := tx.QueryRow(ctx, check).Scan(&current, &populated); err != nil {
+		return err
+	}
+	if current != schemaVersion {
+		return fmt.Errorf("%w: snapshot %d, database %d", ErrorSchemaMismatch, schemaVersion, current)
+	}
+	if populated {
+		if !force {
+			return ErrorNotEmpty
+		}
+		if _, err := tx.Exec(ctx, "TRUNCATE "+tables+" CASCADE;"); err != nil {
+			return err
+		}
+	}
+
+	if err := load(insertRows(tx)); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(items.ChangeNotification{Operation: items.ChangeRestored})
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_notify($1, $2);", items.ChangesChannel, string(payload)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// insertRows devuelve la InsertFunc de tx: un INSERT por tanda, con las filas como un array JSON
+// que json_populate_recordset convierte al tipo de la tabla. Las columnas que no vienen quedan NULL.
+func insertRows(tx pgx.Tx) InsertFunc {
+	return func(ctx context.Context, table string, rows []json.RawMessage) error {
+		identifier := pgx.Identifier{table}.Sanitize()
+		query := "INSERT INTO " + identifier + " SELECT * FROM json_populate_recordset(NULL::" + identifier + ", $1::json);"
+
+		var batch strings.Builder
+		batch.WriteByte('[')
+		for index, row := range rows {
+			if index > 0 {
+				batch.WriteByte(',')
+			}
+			batch.Write(row)
+		}
+		batch.WriteByte(']')
+
+		if _, err := tx.Exec(ctx, query, batch.String()); err != nil {
+			return restoreError(table, err)
+		}
+		return nil
+	}
+}
+
+// restoreError traduce los errores de PostgreSQL al insertar filas: una restricción violada es un
+// ConflictError y un valor que no entra en su columna (clase 22) es un snapshot inválido.
+func restoreError(table string, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case strings.HasPrefix(pgErr.Code, "23"):
+		return &ConflictError{Table: table, Constraint: pgErr.ConstraintName}
+	case strings.HasPrefix(pgErr.Code, "22"):
+		return &InvalidError{Message: table + ": " + pgErr.Message}
+	}
+	return err
+}
+
+// tableList es Tables como lista de identificadores para LOCK y TRUNCATE.
+func tableList() string {
+	identifiers := make([]string, len(Tables))
+	for index, table := range Tables {
+		identifiers[index] = pgx.Identifier{table}.Sanitize()
+	}
+	return strings.Join(identifiers, ", ")
+}
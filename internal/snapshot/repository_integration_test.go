@@ -0,0 +1,91 @@
+//go:build integration
+
+package snapshot_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/items"
+	"github.com/Lelo88/catalog-api-golang/internal/snapshot"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas. Comparten la base con los otros paquetes, así
+// que el restore solo se prueba en los caminos que no escriben (base con items, otra versión).
+
+func TestRepositoryIntegration_Snapshot(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	ctx := context.Background()
+	itemsRepository := items.NewRepository(pool)
+	itemsService := items.NewService(itemsRepository)
+	sku := "SNAP-" + strings.ToUpper(uuid.NewString()[:8])
+	item, err := itemsService.Create(ctx, items.CreateItemInput{Name: "Snapshot " + uuid.NewString(), SKU: &sku, Price: "10.00", Stock: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = itemsRepository.Purge(ctx, item.ID) })
+	_, err = itemsService.Delete(ctx, item.ID, nil)
+	require.NoError(t, err)
+
+	service := snapshot.NewService(snapshot.NewRepository(pool))
+	var buffer bytes.Buffer
+	require.NoError(t, service.Export(ctx, &buffer))
+	exported := buffer.Bytes()
+
+	reader, err := gzip.NewReader(bytes.NewReader(exported))
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 16<<20)
+	require.True(t, scanner.Scan())
+	var manifest snapshot.Manifest
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &manifest))
+	require.Positive(t, manifest.SchemaVersion)
+	require.Positive(t, manifest.Counts["items"])
+
+	found := false
+	for scanner.Scan() {
+		var record snapshot.Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		if record.Table != "items" {
+			continue
+		}
+		var row map[string]any
+		require.NoError(t, json.Unmarshal(record.Row, &row))
+		if row["id"] == item.ID {
+			found = true
+			require.Equal(t, sku, row["sku"])
+			require.NotNil(t, row["deleted_at"], "soft-deleted items are exported")
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.True(t, found)
+
+	// La base tiene items (al menos el de este test): sin force no restaura nada.
+	_, err = service.Import(ctx, bytes.NewReader(exported), false)
+	require.ErrorIs(t, err, snapshot.ErrorNotEmpty)
+
+	// Un snapshot de otra versión de las migraciones se rechaza antes de mirar los items.
+	var other bytes.Buffer
+	writer := gzip.NewWriter(&other)
+	_, err = writer.Write([]byte(`{"schema_version":1,"counts":{}}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	_, err = service.Import(ctx, &other, true)
+	require.ErrorIs(t, err, snapshot.ErrorSchemaMismatch)
+}
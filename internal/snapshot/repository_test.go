@@ -0,0 +1,321 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Export(t *testing.T) {
+	t.Run("manifest then every table in order", func(t *testing.T) {
+		now := time.Now()
+		tx := &fakeTx{
+			row: &fakeRow{values: []any{int64(37), now, int64(1), int64(0), int64(2), int64(0), int64(0), int64(0), int64(0)}},
+			rows: map[string]*fakeRows{
+				"categories": {rows: [][]any{{[]byte(`{"id":"cat-1"}`)}}},
+				"items":      {rows: [][]any{{[]byte(`{"id":"item-1"}`)}, {[]byte(`{"id":"item-2","deleted_at":"2026-01-01T00:00:00Z"}`)}}},
+			},
+		}
+		database := &fakeDB{tx: tx}
+		repository := NewRepository(database)
+
+		var manifest Manifest
+		var lines []string
+		err := repository.Export(context.Background(), func(header Manifest) error {
+			manifest = header
+			return nil
+		}, func(table string, row []byte) error {
+			lines = append(lines, table+" "+string(row))
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, database.options)
+		require.Equal(t, Manifest{SchemaVersion: 37, ExportedAt: now, Counts: map[string]int64{
+			"categories": 1, "brands": 0, "items": 2, "item_variants": 0, "item_translations": 0, "item_external_refs": 0, "price_schedules": 0,
+		}}, manifest)
+		require.Contains(t, normalizeSQL(tx.lastQueryRow), `SELECT version, now(), (SELECT count(*) FROM "categories"), (SELECT count(*) FROM "brands"), (SELECT count(*) FROM "items")`)
+		require.Contains(t, tx.lastQueryRow, "FROM schema_migrations WHERE NOT dirty")
+		require.Equal(t, []string{
+			`categories {"id":"cat-1"}`,
+			`items {"id":"item-1"}`,
+			`items {"id":"item-2","deleted_at":"2026-01-01T00:00:00Z"}`,
+		}, lines)
+		require.Len(t, tx.queries, len(Tables))
+		require.Equal(t, `SELECT row_to_json(t)::text FROM "price_schedules" AS t;`, tx.queries[len(Tables)-1])
+		require.True(t, tx.committed)
+	})
+
+	t.Run("no clean migration version", func(t *testing.T) {
+		tx := &fakeTx{row: &fakeRow{err: pgx.ErrNoRows}}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Export(context.Background(), func(Manifest) error { return nil }, func(string, []byte) error { return nil })
+
+		require.ErrorContains(t, err, "no clean migration version")
+		require.True(t, tx.rolledBack)
+	})
+
+	t.Run("write errors stop the export", func(t *testing.T) {
+		tx := &fakeTx{
+			row:  &fakeRow{values: []any{int64(37), time.Now(), int64(1), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0)}},
+			rows: map[string]*fakeRows{"categories": {rows: [][]any{{[]byte(`{}`)}}}},
+		}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Export(context.Background(), func(Manifest) error { return nil }, func(string, []byte) error { return errors.New("broken pipe") })
+
+		require.ErrorContains(t, err, "export categories: broken pipe")
+		require.Len(t, tx.queries, 1)
+		require.True(t, tx.rows["categories"].closed)
+		require.False(t, tx.committed)
+	})
+
+	t.Run("database without transactions", func(t *testing.T) {
+		repository := NewRepository(&plainDB{})
+
+		err := repository.Export(context.Background(), func(Manifest) error { return nil }, func(string, []byte) error { return nil })
+
+		require.ErrorContains(t, err, "does not support transactions")
+	})
+}
+
+func TestRepository_Restore(t *testing.T) {
+	rows := []json.RawMessage{json.RawMessage(`{"id":"item-1"}`), json.RawMessage(`{"id":"item-2"}`)}
+	load := func(ctx context.Context) func(insert InsertFunc) error {
+		return func(insert InsertFunc) error {
+			return insert(ctx, "items", rows)
+		}
+	}
+
+	t.Run("empty database", func(t *testing.T) {
+		ctx := context.Background()
+		tx := &fakeTx{row: &fakeRow{values: []any{int64(37), false}}}
+		database := &fakeDB{tx: tx}
+		repository := NewRepository(database)
+
+		err := repository.Restore(ctx, 37, false, load(ctx))
+
+		require.NoError(t, err)
+		require.Equal(t, pgx.TxOptions{}, database.options)
+		require.Equal(t, []string{
+			`LOCK TABLE "categories", "brands", "items", "item_variants", "item_translations", "item_external_refs", "price_schedules" IN EXCLUSIVE MODE;`,
+			`INSERT INTO "items" SELECT * FROM json_populate_recordset(NULL::"items", $1::json);`,
+			`SELECT pg_notify($1, $2);`,
+		}, tx.execs)
+		require.Equal(t, []any{`[{"id":"item-1"},{"id":"item-2"}]`}, tx.execArgs[1])
+		require.Equal(t, []any{"items_changed", `{"id":"","op":"restored"}`}, tx.execArgs[2])
+		require.Contains(t, tx.lastQueryRow, "EXISTS (SELECT 1 FROM items)")
+		require.True(t, tx.committed)
+	})
+
+	t.Run("schema mismatch", func(t *testing.T) {
+		tx := &fakeTx{row: &fakeRow{values: []any{int64(38), false}}}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Restore(context.Background(), 37, false, load(context.Background()))
+
+		require.ErrorIs(t, err, ErrorSchemaMismatch)
+		require.ErrorContains(t, err, "snapshot 37, database 38")
+		require.Len(t, tx.execs, 1, "only the lock")
+		require.True(t, tx.rolledBack)
+	})
+
+	t.Run("items present without force", func(t *testing.T) {
+		tx := &fakeTx{row: &fakeRow{values: []any{int64(37), true}}}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Restore(context.Background(), 37, false, load(context.Background()))
+
+		require.ErrorIs(t, err, ErrorNotEmpty)
+		require.Len(t, tx.execs, 1)
+		require.True(t, tx.rolledBack)
+	})
+
+	t.Run("force truncates first", func(t *testing.T) {
+		tx := &fakeTx{row: &fakeRow{values: []any{int64(37), true}}}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Restore(context.Background(), 37, true, load(context.Background()))
+
+		require.NoError(t, err)
+		require.Equal(t, `TRUNCATE "categories", "brands", "items", "item_variants", "item_translations", "item_external_refs", "price_schedules" CASCADE;`, tx.execs[1])
+		require.Contains(t, tx.execs[2], `INSERT INTO "items"`)
+		require.True(t, tx.committed)
+	})
+
+	t.Run("constraint violations are conflicts", func(t *testing.T) {
+		tx := &fakeTx{
+			row:     &fakeRow{values: []any{int64(37), false}},
+			execErr: map[string]error{"INSERT INTO": &pgconn.PgError{Code: "23505", ConstraintName: "ux_items_sku"}},
+		}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Restore(context.Background(), 37, false, load(context.Background()))
+
+		var conflict *ConflictError
+		require.ErrorAs(t, err, &conflict)
+		require.Equal(t, ConflictError{Table: "items", Constraint: "ux_items_sku"}, *conflict)
+		require.True(t, tx.rolledBack)
+	})
+
+	t.Run("bad values make the snapshot invalid", func(t *testing.T) {
+		tx := &fakeTx{
+			row:     &fakeRow{values: []any{int64(37), false}},
+			execErr: map[string]error{"INSERT INTO": &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type uuid: "x"`}},
+		}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Restore(context.Background(), 37, false, load(context.Background()))
+
+		var invalid *InvalidError
+		require.ErrorAs(t, err, &invalid)
+		require.Equal(t, `items: invalid input syntax for type uuid: "x"`, invalid.Message)
+	})
+
+	t.Run("load errors roll back", func(t *testing.T) {
+		tx := &fakeTx{row: &fakeRow{values: []any{int64(37), false}}}
+		repository := NewRepository(&fakeDB{tx: tx})
+
+		err := repository.Restore(context.Background(), 37, false, func(InsertFunc) error { return errors.New("truncated upload") })
+
+		require.ErrorContains(t, err, "truncated upload")
+		require.False(t, tx.committed)
+		require.True(t, tx.rolledBack)
+	})
+}
+
+// plainDB no abre transacciones: todo lo del repositorio corre en una.
+type plainDB struct{}
+
+func (db *plainDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &fakeRow{err: errors.New("unexpected QueryRow call outside a transaction")}
+}
+
+func (db *plainDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected Query call outside a transaction")
+}
+
+type fakeDB struct {
+	plainDB
+	tx      *fakeTx
+	options pgx.TxOptions
+}
+
+func (db *fakeDB) BeginTx(ctx context.Context, options pgx.TxOptions) (pgx.Tx, error) {
+	db.options = options
+	return db.tx, nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+	row          *fakeRow
+	rows         map[string]*fakeRows
+	execErr      map[string]error
+	lastQueryRow string
+	queries      []string
+	execs        []string
+	execArgs     [][]any
+	committed    bool
+	rolledBack   bool
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	tx.lastQueryRow = sql
+	return tx.row
+}
+
+// Query devuelve las filas de la tabla que nombra sql; una tabla sin filas cargadas viene vacía.
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx.queries = append(tx.queries, sql)
+	for table, rows := range tx.rows {
+		if strings.Contains(sql, `"`+table+`"`) {
+			return rows, nil
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.execs = append(tx.execs, sql)
+	tx.execArgs = append(tx.execArgs, args)
+	for fragment, err := range tx.execErr {
+		if strings.Contains(sql, fragment) {
+			return pgconn.CommandTag{}, err
+		}
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	return assignValues(dest, row.values)
+}
+
+type fakeRows struct {
+	rows   [][]any
+	index  int
+	closed bool
+}
+
+func (rows *fakeRows) Close()                                       { rows.closed = true }
+func (rows *fakeRows) Err() error                                   { return nil }
+func (rows *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (rows *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (rows *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (rows *fakeRows) RawValues() [][]byte                          { return nil }
+func (rows *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (rows *fakeRows) Next() bool {
+	if rows.index >= len(rows.rows) {
+		return false
+	}
+	rows.index++
+	return true
+}
+
+func (rows *fakeRows) Scan(dest ...any) error {
+	return assignValues(dest, rows.rows[rows.index-1])
+}
+
+func assignValues(dest []any, values []any) error {
+	if len(dest) != len(values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
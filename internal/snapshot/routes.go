@@ -0,0 +1,11 @@
+package snapshot
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra el export y el restore de snapshots del catálogo.
+// Todavía no hay autenticación: estas rutas no deberían exponerse fuera de la red interna, y el
+// restore con force reemplaza el catálogo entero.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Get(ExportPath, handler.Export)
+	route.Post(ImportPath, handler.Import)
+}
@@ -0,0 +1,173 @@
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	// Export llama a manifest y después a row por cada fila de Tables, todo de la misma foto.
+	Export(ctx context.Context, manifest func(Manifest) error, row func(table string, row []byte) error) error
+	// Restore verifica la base y llama a load con la función que inserta filas, en una transacción.
+	Restore(ctx context.Context, schemaVersion int64, force bool, load func(insert InsertFunc) error) error
+}
+
+const (
+	// restoreBatchSize es cuántas filas inserta cada INSERT del restore.
+	restoreBatchSize = 500
+	// maxLineSize es el largo máximo de una línea del snapshot, con holgura para un item con
+	// descripción y atributos grandes.
+	maxLineSize = 16 << 20
+)
+
+// Service exporta y restaura snapshots del catálogo.
+type Service struct {
+	repository RepositoryAPI
+}
+
+// NewService crea el service de snapshots.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository}
+}
+
+// Export escribe el snapshot en writer: NDJSON comprimido con gzip, con el manifest en la primera
+// línea y después un Record por fila. Los items borrados entran como cualquier otra fila.
+func (service *Service) Export(ctx context.Context, writer io.Writer) error {
+	compressed := gzip.NewWriter(writer)
+	encoder := json.NewEncoder(compressed)
+	encoder.SetEscapeHTML(false)
+
+	err := service.repository.Export(ctx, func(manifest Manifest) error {
+		return encoder.Encode(manifest)
+	}, func(table string, row []byte) error {
+		return encoder.Encode(Record{Table: table, Row: row})
+	})
+	if err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+// Import restaura el snapshot de reader en una transacción y devuelve su manifest. El stream se lee
+// mientras se inserta, así que un snapshot inválido a la mitad deshace lo cargado hasta ahí.
+// Sin force falla con ErrorNotEmpty si la base ya tiene items.
+func (service *Service) Import(ctx context.Context, reader io.Reader, force bool) (Manifest, error) {
+	decompressed, err := gzip.NewReader(reader)
+	if err != nil {
+		if errors.Is(err, gzip.ErrHeader) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return Manifest{}, &InvalidError{Message: "not a gzip stream"}
+		}
+		return Manifest{}, err
+	}
+	defer decompressed.Close()
+
+	scanner := bufio.NewScanner(decompressed)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	manifest, err := readManifestLine(scanner)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	err = service.repository.Restore(ctx, manifest.SchemaVersion, force, func(insert InsertFunc) error {
+		return loadRecords(ctx, scanner, manifest, insert)
+	})
+	if err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// readManifestLine lee y valida la primera línea del snapshot.
+func readManifestLine(scanner *bufio.Scanner) (Manifest, error) {
+	if !scanner.Scan() {
+		if err := scanError(scanner.Err()); err != nil {
+			return Manifest{}, err
+		}
+		return Manifest{}, &InvalidError{Message: "missing manifest"}
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(scanner.Bytes(), &manifest); err != nil || manifest.SchemaVersion < 1 || manifest.Counts == nil {
+		return Manifest{}, &InvalidError{Message: "the first line must be the manifest"}
+	}
+	for table := range manifest.Counts {
+		if !slices.Contains(Tables, table) {
+			return Manifest{}, &InvalidError{Message: fmt.Sprintf("manifest counts unknown table %q", table)}
+		}
+	}
+	return manifest, nil
+}
+
+// loadRecords inserta las filas del resto del snapshot de a restoreBatchSize. Las tablas tienen que
+// venir en el orden de Tables (las FKs se verifican al insertar) y al terminar cada tabla tiene que
+// tener las filas que dice el manifest: si no, el archivo está cortado o mezclado.
+func loadRecords(ctx context.Context, scanner *bufio.Scanner, manifest Manifest, insert InsertFunc) error {
+	loaded := make(map[string]int64, len(Tables))
+	position := 0
+	table := ""
+	batch := make([]json.RawMessage, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := insert(ctx, table, batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Table == "" || len(record.Row) == 0 {
+			return &InvalidError{Message: fmt.Sprintf("line %d is not a snapshot row", line)}
+		}
+		index := slices.Index(Tables, record.Table)
+		if index < 0 {
+			return &InvalidError{Message: fmt.Sprintf("line %d: unknown table %q", line, record.Table)}
+		}
+		if index < position {
+			return &InvalidError{Message: fmt.Sprintf("line %d: %s rows must come before %s rows", line, record.Table, Tables[position])}
+		}
+		if record.Table != table || len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		position, table = index, record.Table
+		// Unmarshal copia el RawMessage: la tanda no depende del buffer del scanner.
+		batch = append(batch, record.Row)
+		loaded[record.Table]++
+	}
+	if err := scanError(scanner.Err()); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	for _, name := range Tables {
+		if loaded[name] != manifest.Counts[name] {
+			return &InvalidError{Message: fmt.Sprintf("%s has %d rows but the manifest says %d", name, loaded[name], manifest.Counts[name])}
+		}
+	}
+	return nil
+}
+
+// scanError traduce los errores de lectura que son culpa del archivo (gzip corrupto o cortado,
+// línea demasiado larga) a InvalidError; el resto (el cliente cortó la subida) sigue igual.
+func scanError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, bufio.ErrTooLong):
+		return &InvalidError{Message: fmt.Sprintf("a line is longer than %d bytes", maxLineSize)}
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader), errors.Is(err, io.ErrUnexpectedEOF):
+		return &InvalidError{Message: "corrupt or truncated gzip stream"}
+	}
+	return err
+}
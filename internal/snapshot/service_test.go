@@ -0,0 +1,185 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository exporta manifest y rows y, al restaurar, guarda lo que load inserta.
+type fakeRepository struct {
+	manifest Manifest
+	rows     []Record
+	err      error
+
+	restoreErr    error
+	schemaVersion int64
+	force         bool
+	inserted      map[string][][]json.RawMessage
+}
+
+func (repository *fakeRepository) Export(ctx context.Context, manifest func(Manifest) error, row func(table string, row []byte) error) error {
+	if repository.err != nil {
+		return repository.err
+	}
+	if err := manifest(repository.manifest); err != nil {
+		return err
+	}
+	for _, record := range repository.rows {
+		if err := row(record.Table, record.Row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repository *fakeRepository) Restore(ctx context.Context, schemaVersion int64, force bool, load func(insert InsertFunc) error) error {
+	repository.schemaVersion, repository.force = schemaVersion, force
+	if repository.restoreErr != nil {
+		return repository.restoreErr
+	}
+	repository.inserted = map[string][][]json.RawMessage{}
+	return load(func(ctx context.Context, table string, rows []json.RawMessage) error {
+		repository.inserted[table] = append(repository.inserted[table], append([]json.RawMessage(nil), rows...))
+		return nil
+	})
+}
+
+// gzipLines arma un snapshot con lines, una por línea.
+func gzipLines(t *testing.T, lines ...string) *bytes.Buffer {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	for _, line := range lines {
+		_, err := writer.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return &buffer
+}
+
+func TestService_Export(t *testing.T) {
+	t.Run("gzip ndjson with the manifest first", func(t *testing.T) {
+		exportedAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+		repository := &fakeRepository{
+			manifest: Manifest{SchemaVersion: 37, ExportedAt: exportedAt, Counts: map[string]int64{"items": 1}},
+			rows:     []Record{{Table: "items", Row: json.RawMessage(`{"id": "item-1", "name": "<b>Lamp</b>"}`)}},
+		}
+		var buffer bytes.Buffer
+
+		err := NewService(repository).Export(context.Background(), &buffer)
+
+		require.NoError(t, err)
+		reader, err := gzip.NewReader(&buffer)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(reader)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		require.Equal(t, []string{
+			`{"schema_version":37,"exported_at":"2026-10-15T12:00:00Z","counts":{"items":1}}`,
+			`{"table":"items","row":{"id":"item-1","name":"<b>Lamp</b>"}}`,
+		}, lines)
+	})
+
+	t.Run("repository errors", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		err := NewService(&fakeRepository{err: errors.New("connection reset")}).Export(context.Background(), &buffer)
+
+		require.ErrorContains(t, err, "connection reset")
+		require.Zero(t, buffer.Len())
+	})
+}
+
+func TestService_Import(t *testing.T) {
+	manifest := `{"schema_version":37,"exported_at":"2026-10-15T12:00:00Z","counts":{"categories":1,"items":2}}`
+
+	t.Run("restores what Export writes", func(t *testing.T) {
+		source := &fakeRepository{
+			manifest: Manifest{SchemaVersion: 37, Counts: map[string]int64{"categories": 1, "items": 2}},
+			rows: []Record{
+				{Table: "categories", Row: json.RawMessage(`{"id":"cat-1"}`)},
+				{Table: "items", Row: json.RawMessage(`{"id":"item-1"}`)},
+				{Table: "items", Row: json.RawMessage(`{"id":"item-2"}`)},
+			},
+		}
+		var buffer bytes.Buffer
+		require.NoError(t, NewService(source).Export(context.Background(), &buffer))
+		target := &fakeRepository{}
+
+		restored, err := NewService(target).Import(context.Background(), &buffer, true)
+
+		require.NoError(t, err)
+		require.Equal(t, source.manifest.Counts, restored.Counts)
+		require.Equal(t, int64(37), target.schemaVersion)
+		require.True(t, target.force)
+		require.Equal(t, map[string][][]json.RawMessage{
+			"categories": {{json.RawMessage(`{"id":"cat-1"}`)}},
+			"items":      {{json.RawMessage(`{"id":"item-1"}`), json.RawMessage(`{"id":"item-2"}`)}},
+		}, target.inserted)
+	})
+
+	t.Run("inserts in batches", func(t *testing.T) {
+		lines := []string{fmt.Sprintf(`{"schema_version":37,"counts":{"items":%d}}`, restoreBatchSize+1)}
+		for index := range restoreBatchSize + 1 {
+			lines = append(lines, fmt.Sprintf(`{"table":"items","row":{"n":%d}}`, index))
+		}
+		repository := &fakeRepository{}
+
+		_, err := NewService(repository).Import(context.Background(), gzipLines(t, lines...), false)
+
+		require.NoError(t, err)
+		require.Len(t, repository.inserted["items"], 2)
+		require.Len(t, repository.inserted["items"][0], restoreBatchSize)
+		require.Equal(t, json.RawMessage(fmt.Sprintf(`{"n":%d}`, restoreBatchSize)), repository.inserted["items"][1][0])
+	})
+
+	t.Run("invalid snapshots", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			body    *bytes.Buffer
+			message string
+		}{
+			"not gzip":        {bytes.NewBufferString(`{"schema_version":37}`), "not a gzip stream"},
+			"empty":           {gzipLines(t), "missing manifest"},
+			"no manifest":     {gzipLines(t, `{"table":"items","row":{}}`), "the first line must be the manifest"},
+			"unknown count":   {gzipLines(t, `{"schema_version":37,"counts":{"orders":1}}`), `manifest counts unknown table "orders"`},
+			"bad line":        {gzipLines(t, manifest, `not json`), "line 2 is not a snapshot row"},
+			"unknown table":   {gzipLines(t, manifest, `{"table":"orders","row":{}}`), `line 2: unknown table "orders"`},
+			"out of order":    {gzipLines(t, manifest, `{"table":"items","row":{}}`, `{"table":"categories","row":{}}`), "line 3: categories rows must come before items rows"},
+			"count mismatch":  {gzipLines(t, manifest, `{"table":"categories","row":{}}`, `{"table":"items","row":{}}`), "items has 1 rows but the manifest says 2"},
+			"truncated gzip":  {truncated(gzipLines(t, manifest, `{"table":"items","row":{}}`)), "corrupt or truncated gzip stream"},
+			"line is too big": {gzipLines(t, manifest, `{"table":"items","row":{"d":"`+strings.Repeat("x", maxLineSize)+`"}}`), "a line is longer than"},
+		} {
+			_, err := NewService(&fakeRepository{}).Import(context.Background(), test.body, false)
+
+			var invalid *InvalidError
+			require.ErrorAs(t, err, &invalid, name)
+			require.Contains(t, invalid.Message, test.message, name)
+		}
+	})
+
+	t.Run("repository errors", func(t *testing.T) {
+		repository := &fakeRepository{restoreErr: ErrorNotEmpty}
+
+		_, err := NewService(repository).Import(context.Background(), gzipLines(t, manifest), false)
+
+		require.ErrorIs(t, err, ErrorNotEmpty)
+		require.False(t, repository.force)
+	})
+}
+
+// truncated corta el final del stream (el trailer del gzip), como una subida interrumpida.
+func truncated(buffer *bytes.Buffer) *bytes.Buffer {
+	return bytes.NewBuffer(buffer.Bytes()[:buffer.Len()-4])
+}
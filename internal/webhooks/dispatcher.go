@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+)
+
+// Cabeceras de cada entrega. La firma es "sha256=" más el HMAC-SHA256 en hexa, con el secret del
+// webhook, de "<X-Webhook-Timestamp>.<body>".
+const (
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	// DefaultMaxAttempts es cuántas veces se intenta una entrega antes de pasarla a dead.
+	DefaultMaxAttempts = 8
+	// deliveryTimeout es lo máximo que se espera la respuesta de un webhook.
+	deliveryTimeout = 10 * time.Second
+	// claimBatchSize es cuántas entregas toma el job por vez. Se mandan de a una, así que el lease
+	// tiene que alcanzar para todo el lote.
+	claimBatchSize = 10
+	// claimLease es cuánto queda reservada una entrega tomada: si el proceso muere a mitad de camino,
+	// otro la vuelve a tomar después de esto.
+	claimLease = 2 * time.Minute
+	// firstRetryDelay es la espera antes del segundo intento; cada reintento espera el doble que el
+	// anterior, hasta maxRetryDelay.
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = time.Hour
+	// maxResponseBytes es cuánto del body de la respuesta se lee (y se descarta) para reusar la conexión.
+	maxResponseBytes = 64 << 10
+)
+
+// DeliveryStore es lo que el Dispatcher necesita de la DB. Repository la implementa.
+type DeliveryStore interface {
+	EnqueueDeliveries(ctx context.Context, event string, payload []byte) (int, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Attempt, error)
+	MarkDelivered(ctx context.Context, id string, responseStatus int) error
+	MarkFailed(ctx context.Context, id string, failure Failure) error
+}
+
+// Dispatcher manda los eventos de items a los webhooks suscriptos. Publish (llamado por el relay
+// del outbox) encola el evento en webhook_deliveries para cada webhook suscripto; Deliver, que corre
+// como job, manda las entregas pendientes.
+type Dispatcher struct {
+	store       DeliveryStore
+	client      *http.Client
+	now         func() time.Time
+	maxAttempts int
+	logf        func(format string, args ...any)
+}
+
+// DispatcherOption configura comportamiento opcional del Dispatcher.
+type DispatcherOption func(*Dispatcher)
+
+// WithMaxAttempts cambia cuántas veces se intenta una entrega antes de pasarla a dead.
+func WithMaxAttempts(attempts int) DispatcherOption {
+	return func(dispatcher *Dispatcher) {
+		dispatcher.maxAttempts = attempts
+	}
+}
+
+// WithHTTPClient cambia el cliente con el que se mandan las entregas.
+func WithHTTPClient(client *http.Client) DispatcherOption {
+	return func(dispatcher *Dispatcher) {
+		dispatcher.client = client
+	}
+}
+
+// NewDispatcher crea un Dispatcher que encola y registra las entregas en store.
+func NewDispatcher(store DeliveryStore, options ...DispatcherOption) *Dispatcher {
+	dispatcher := &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: deliveryTimeout},
+		now:         time.Now,
+		maxAttempts: DefaultMaxAttempts,
+		logf:        log.Printf,
+	}
+	for _, option := range options {
+		option(dispatcher)
+	}
+	return dispatcher
+}
+
+// Publish implementa outbox.Publisher: encola una entrega del mensaje para cada webhook suscripto a
+// su evento. Si el relay repite un mensaje (se cortó antes de marcarlo procesado), los webhooks lo
+// reciben dos veces con distinto X-Webhook-Delivery.
+func (dispatcher *Dispatcher) Publish(ctx context.Context, message outbox.Message) error {
+	payload, err := json.Marshal(Payload{Event: message.EventType, OccurredAt: message.CreatedAt.UTC(), Data: message.Payload})
+	if err != nil {
+		return err
+	}
+	_, err = dispatcher.store.EnqueueDeliveries(ctx, message.EventType, payload)
+	return err
+}
+
+// Deliver es el job de entregas: manda las entregas pendientes que ya vencieron hasta que no quede
+// ninguna.
+func (dispatcher *Dispatcher) Deliver(ctx context.Context) error {
+	for {
+		attempts, err := dispatcher.store.ClaimDue(ctx, claimBatchSize, claimLease)
+		if err != nil {
+			return err
+		}
+		for _, attempt := range attempts {
+			if err := dispatcher.send(ctx, attempt); err != nil {
+				return err
+			}
+		}
+		if len(attempts) < claimBatchSize {
+			return nil
+		}
+	}
+}
+
+// send manda un intento y registra el resultado. Solo devuelve error si no se pudo registrar o si
+// el job se cortó; un webhook que falla es un intento fallido, no un error del job.
+func (dispatcher *Dispatcher) send(ctx context.Context, attempt Attempt) error {
+	responseStatus, err := dispatcher.post(ctx, attempt)
+	if ctx.Err() != nil {
+		// Corte del job: el lease devuelve la entrega a pending.
+		return ctx.Err()
+	}
+	if err == nil {
+		err = dispatcher.store.MarkDelivered(ctx, attempt.DeliveryID, responseStatus)
+		if errors.Is(err, ErrorNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	failure := Failure{Error: err.Error()}
+	if responseStatus != 0 {
+		failure.ResponseStatus = &responseStatus
+	}
+	if attempt.Attempts < dispatcher.maxAttempts {
+		retryAt := dispatcher.now().Add(retryDelay(attempt.Attempts))
+		failure.RetryAt = &retryAt
+	} else {
+		dispatcher.logf("webhooks: delivery dead delivery_id=%s webhook_id=%s attempts=%d: %v", attempt.DeliveryID, attempt.WebhookID, attempt.Attempts, err)
+	}
+	if err := dispatcher.store.MarkFailed(ctx, attempt.DeliveryID, failure); err != nil && !errors.Is(err, ErrorNotFound) {
+		return err
+	}
+	return nil
+}
+
+// post hace el POST firmado. Devuelve el status de la respuesta (0 si no hubo) y un error si no
+// fue 2xx.
+func (dispatcher *Dispatcher) post(ctx context.Context, attempt Attempt) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, attempt.URL, bytes.NewReader(attempt.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(dispatcher.now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "catalog-api-golang-webhooks")
+	request.Header.Set(HeaderDelivery, attempt.DeliveryID)
+	request.Header.Set(HeaderEvent, attempt.Event)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, Sign(attempt.Secret, timestamp, attempt.Payload))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBytes))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign devuelve el valor de X-Webhook-Signature para body enviado en timestamp. El receptor la
+// recalcula con su copia del secret y compara con hmac.Equal.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay es la espera después del intento número attempts: 30s, 1m, 2m... hasta una hora.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
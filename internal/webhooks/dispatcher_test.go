@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+)
+
+type enqueued struct {
+	event   string
+	payload []byte
+}
+
+type fakeStore struct {
+	mutex      sync.Mutex
+	enqueueErr error
+	enqueued   []enqueued
+	due        [][]Attempt
+	delivered  map[string]int
+	failed     map[string]Failure
+}
+
+func (store *fakeStore) EnqueueDeliveries(ctx context.Context, event string, payload []byte) (int, error) {
+	if store.enqueueErr != nil {
+		return 0, store.enqueueErr
+	}
+	store.enqueued = append(store.enqueued, enqueued{event: event, payload: payload})
+	return 1, nil
+}
+
+func (store *fakeStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Attempt, error) {
+	if len(store.due) == 0 {
+		return nil, nil
+	}
+	batch := store.due[0]
+	store.due = store.due[1:]
+	return batch, nil
+}
+
+func (store *fakeStore) MarkDelivered(ctx context.Context, id string, responseStatus int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.delivered == nil {
+		store.delivered = map[string]int{}
+	}
+	store.delivered[id] = responseStatus
+	return nil
+}
+
+func (store *fakeStore) MarkFailed(ctx context.Context, id string, failure Failure) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if store.failed == nil {
+		store.failed = map[string]Failure{}
+	}
+	store.failed[id] = failure
+	return nil
+}
+
+var dispatcherNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestDispatcher(store DeliveryStore, options ...DispatcherOption) *Dispatcher {
+	dispatcher := NewDispatcher(store, options...)
+	dispatcher.now = func() time.Time { return dispatcherNow }
+	dispatcher.logf = func(string, ...any) {}
+	return dispatcher
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	t.Run("enqueues the outbox message", func(t *testing.T) {
+		store := &fakeStore{}
+		dispatcher := newTestDispatcher(store)
+
+		err := dispatcher.Publish(context.Background(), outbox.Message{
+			ID:        7,
+			EventType: EventItemDeleted,
+			Payload:   json.RawMessage(`{"id":"id-1","operation":"deleted"}`),
+			CreatedAt: time.Date(2025, 6, 1, 9, 0, 0, 0, time.FixedZone("ART", -3*60*60)),
+		})
+
+		require.NoError(t, err)
+		require.Len(t, store.enqueued, 1)
+		require.Equal(t, EventItemDeleted, store.enqueued[0].event)
+		require.JSONEq(t, `{"event":"item.deleted","occurred_at":"2025-06-01T12:00:00Z","data":{"id":"id-1","operation":"deleted"}}`, string(store.enqueued[0].payload))
+	})
+
+	t.Run("store error is returned to the relay", func(t *testing.T) {
+		store := &fakeStore{enqueueErr: errors.New("db down")}
+		dispatcher := newTestDispatcher(store)
+
+		err := dispatcher.Publish(context.Background(), outbox.Message{EventType: EventItemCreated, Payload: json.RawMessage(`{}`)})
+
+		require.EqualError(t, err, "db down")
+	})
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	const secret = "0123456789abcdef"
+
+	t.Run("posts a signed payload", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			received = request
+			body, _ = io.ReadAll(request.Body)
+			writer.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		payload, _ := json.Marshal(Payload{Event: EventPing, OccurredAt: dispatcherNow, Data: map[string]string{"webhook_id": "webhook-1"}})
+		store := &fakeStore{due: [][]Attempt{{{DeliveryID: "delivery-1", WebhookID: "webhook-1", URL: server.URL, Secret: secret, Event: EventPing, Payload: payload, Attempts: 1}}}}
+		dispatcher := newTestDispatcher(store)
+
+		require.NoError(t, dispatcher.Deliver(context.Background()))
+
+		require.Equal(t, map[string]int{"delivery-1": http.StatusNoContent}, store.delivered)
+		require.Equal(t, payload, body)
+		require.Equal(t, "delivery-1", received.Header.Get(HeaderDelivery))
+		require.Equal(t, EventPing, received.Header.Get(HeaderEvent))
+		require.Equal(t, "1748779200", received.Header.Get(HeaderTimestamp))
+		expected := Sign(secret, received.Header.Get(HeaderTimestamp), body)
+		require.True(t, hmac.Equal([]byte(expected), []byte(received.Header.Get(HeaderSignature))))
+	})
+
+	t.Run("failures retry with exponential backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := &fakeStore{due: [][]Attempt{{{DeliveryID: "delivery-1", URL: server.URL, Secret: secret, Payload: []byte(`{}`), Attempts: 3}}}}
+		dispatcher := newTestDispatcher(store)
+
+		require.NoError(t, dispatcher.Deliver(context.Background()))
+
+		failure := store.failed["delivery-1"]
+		require.Equal(t, http.StatusInternalServerError, *failure.ResponseStatus)
+		require.Equal(t, "unexpected status 500", failure.Error)
+		require.Equal(t, dispatcherNow.Add(2*time.Minute), *failure.RetryAt)
+	})
+
+	t.Run("the last attempt dead-letters the delivery", func(t *testing.T) {
+		store := &fakeStore{due: [][]Attempt{{{DeliveryID: "delivery-1", URL: "http://127.0.0.1:1", Secret: secret, Payload: []byte(`{}`), Attempts: 3}}}}
+		dispatcher := newTestDispatcher(store, WithMaxAttempts(3))
+
+		require.NoError(t, dispatcher.Deliver(context.Background()))
+
+		failure := store.failed["delivery-1"]
+		require.Nil(t, failure.RetryAt)
+		require.Nil(t, failure.ResponseStatus, "connection errors have no status")
+		require.NotEmpty(t, failure.Error)
+	})
+
+	t.Run("claims until the queue is drained", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+		defer server.Close()
+
+		full := make([]Attempt, claimBatchSize)
+		for index := range full {
+			full[index] = Attempt{DeliveryID: "full-" + string(rune('a'+index)), URL: server.URL, Attempts: 1}
+		}
+		store := &fakeStore{due: [][]Attempt{full, {{DeliveryID: "last", URL: server.URL, Attempts: 1}}}}
+		dispatcher := newTestDispatcher(store)
+
+		require.NoError(t, dispatcher.Deliver(context.Background()))
+
+		require.Len(t, store.delivered, claimBatchSize+1)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, retryDelay(1))
+	require.Equal(t, time.Minute, retryDelay(2))
+	require.Equal(t, 4*time.Minute, retryDelay(4))
+	require.Equal(t, time.Hour, retryDelay(20))
+}
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ServiceAPI define lo que el handler necesita.
+// Permite testear handlers con stubs sin tocar DB.
+type ServiceAPI interface {
+	Create(ctx context.Context, input WebhookInput) (Webhook, error)
+	List(ctx context.Context) ([]Webhook, error)
+	Get(ctx context.Context, id string) (Webhook, error)
+	Update(ctx context.Context, id string, input WebhookUpdate) (Webhook, error)
+	Delete(ctx context.Context, id string) error
+	Test(ctx context.Context, id string) (Delivery, error)
+	Deliveries(ctx context.Context, id string, status DeliveryStatus, page, limit int) (DeliveryPage, error)
+}
+
+// Handler expone el CRUD de webhooks y su log de entregas.
+type Handler struct {
+	service ServiceAPI
+}
+
+// NewHandler crea un handler de webhooks.
+func NewHandler(service ServiceAPI) *Handler {
+	return &Handler{service: service}
+}
+
+// Paginación de GET /webhooks/{id}/deliveries. Un limit mayor al máximo se recorta.
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 100
+)
+
+// Create maneja POST /webhooks.
+func (handler *Handler) Create(writer http.ResponseWriter, request *http.Request) {
+	var input WebhookInput
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	webhook, err := handler.service.Create(request.Context(), input)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.Created(writer, request, "/webhooks/"+webhook.ID, webhook)
+}
+
+// List maneja GET /webhooks.
+func (handler *Handler) List(writer http.ResponseWriter, request *http.Request) {
+	webhooks, err := handler.service.List(request.Context())
+	if err != nil {
+		httpx.FailUnexpected(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, webhooks)
+}
+
+// Get maneja GET /webhooks/{id}.
+func (handler *Handler) Get(writer http.ResponseWriter, request *http.Request) {
+	id, ok := webhookID(writer, request)
+	if !ok {
+		return
+	}
+
+	webhook, err := handler.service.Get(request.Context(), id)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, webhook)
+}
+
+// Update maneja PATCH /webhooks/{id}.
+func (handler *Handler) Update(writer http.ResponseWriter, request *http.Request) {
+	id, ok := webhookID(writer, request)
+	if !ok {
+		return
+	}
+	var input WebhookUpdate
+	if err := json.NewDecoder(request.Body).Decode(&input); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_json", "invalid JSON body")
+		return
+	}
+
+	webhook, err := handler.service.Update(request.Context(), id, input)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, webhook)
+}
+
+// Delete maneja DELETE /webhooks/{id}.
+func (handler *Handler) Delete(writer http.ResponseWriter, request *http.Request) {
+	id, ok := webhookID(writer, request)
+	if !ok {
+		return
+	}
+
+	if err := handler.service.Delete(request.Context(), id); err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// Test maneja POST /webhooks/{id}/test: encola un evento ping y responde 202 con la entrega, que
+// el job manda en su próxima corrida.
+func (handler *Handler) Test(writer http.ResponseWriter, request *http.Request) {
+	id, ok := webhookID(writer, request)
+	if !ok {
+		return
+	}
+
+	delivery, err := handler.service.Test(request.Context(), id)
+	if err != nil {
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusAccepted, delivery)
+}
+
+// Deliveries maneja GET /webhooks/{id}/deliveries?status=dead&page=1&limit=20: el log de entregas,
+// de la más nueva a la más vieja.
+func (handler *Handler) Deliveries(writer http.ResponseWriter, request *http.Request) {
+	id, ok := webhookID(writer, request)
+	if !ok {
+		return
+	}
+	query := request.URL.Query()
+	page, limit, ok := httpx.ParsePagination(query.Get("page"), query.Get("limit"), defaultDeliveriesLimit, maxDeliveriesLimit)
+	if !ok {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_pagination", "invalid pagination parameters")
+		return
+	}
+	status := DeliveryStatus(strings.ToLower(strings.TrimSpace(query.Get("status"))))
+
+	result, err := handler.service.Deliveries(request.Context(), id, status, page, limit)
+	if err != nil {
+		var validationError *ValidationError
+		if errors.As(err, &validationError) {
+			httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_filter", "invalid filter parameters", []httpx.ErrorDetail{
+				{Field: validationError.Field, Message: validationError.Message},
+			})
+			return
+		}
+		failWrite(writer, request, err)
+		return
+	}
+	httpx.OK(writer, request, http.StatusOK, map[string]any{
+		"deliveries": result.Deliveries,
+		"pagination": httpx.NewPagination(page, limit, result.Total),
+	})
+}
+
+// webhookID lee y valida el {id} del path; si no es un UUID responde 400 y devuelve false.
+func webhookID(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	id := chi.URLParam(request, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_id", "id must be a valid UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// failWrite responde los errores de dominio: validación, webhook inexistente y el resto como
+// inesperados.
+func failWrite(writer http.ResponseWriter, request *http.Request, err error) {
+	var validationError *ValidationError
+	switch {
+	case errors.As(err, &validationError):
+		httpx.FailWithDetails(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data", []httpx.ErrorDetail{
+			{Field: validationError.Field, Message: validationError.Message},
+		})
+	case errors.Is(err, ErrorInvalidInput):
+		httpx.Fail(writer, request, http.StatusBadRequest, "invalid_input", "invalid input data")
+	case errors.Is(err, ErrorNotFound):
+		httpx.Fail(writer, request, http.StatusNotFound, "not_found", "webhook not found")
+	default:
+		httpx.FailUnexpected(writer, request, err)
+	}
+}
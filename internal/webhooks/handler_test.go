@@ -0,0 +1,233 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Lelo88/catalog-api-golang/internal/httpx"
+	"github.com/Lelo88/catalog-api-golang/internal/webhooks"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+type stubService struct {
+	createFn     func(ctx context.Context, input webhooks.WebhookInput) (webhooks.Webhook, error)
+	testErr      error
+	deliveriesFn func(ctx context.Context, id string, status webhooks.DeliveryStatus, page, limit int) (webhooks.DeliveryPage, error)
+	page         int
+	limit        int
+	status       webhooks.DeliveryStatus
+	called       bool
+}
+
+func (service *stubService) Create(ctx context.Context, input webhooks.WebhookInput) (webhooks.Webhook, error) {
+	service.called = true
+	if service.createFn != nil {
+		return service.createFn(ctx, input)
+	}
+	return webhooks.Webhook{ID: webhookID, URL: input.URL, Secret: input.Secret, Events: input.Events}, nil
+}
+
+func (service *stubService) List(ctx context.Context) ([]webhooks.Webhook, error) {
+	return []webhooks.Webhook{{ID: webhookID}}, nil
+}
+
+func (service *stubService) Get(ctx context.Context, id string) (webhooks.Webhook, error) {
+	service.called = true
+	return webhooks.Webhook{ID: id}, nil
+}
+
+func (service *stubService) Update(ctx context.Context, id string, input webhooks.WebhookUpdate) (webhooks.Webhook, error) {
+	service.called = true
+	return webhooks.Webhook{ID: id}, nil
+}
+
+func (service *stubService) Delete(ctx context.Context, id string) error {
+	service.called = true
+	return nil
+}
+
+func (service *stubService) Test(ctx context.Context, id string) (webhooks.Delivery, error) {
+	service.called = true
+	if service.testErr != nil {
+		return webhooks.Delivery{}, service.testErr
+	}
+	return webhooks.Delivery{ID: "delivery-1", WebhookID: id, Event: webhooks.EventPing, Payload: json.RawMessage(`{}`), Status: webhooks.DeliveryPending}, nil
+}
+
+func (service *stubService) Deliveries(ctx context.Context, id string, status webhooks.DeliveryStatus, page, limit int) (webhooks.DeliveryPage, error) {
+	service.called = true
+	service.status, service.page, service.limit = status, page, limit
+	if service.deliveriesFn != nil {
+		return service.deliveriesFn(ctx, id, status, page, limit)
+	}
+	return webhooks.DeliveryPage{Deliveries: []webhooks.Delivery{{ID: "delivery-1", Payload: json.RawMessage(`{}`)}}, Total: 45}, nil
+}
+
+func TestHandler_Create(t *testing.T) {
+	t.Run("created without the secret", func(t *testing.T) {
+		handler := webhooks.NewHandler(&stubService{})
+
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"https://example.com","secret":"0123456789abcdef","events":["item.created"]}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "/webhooks/"+webhookID, rec.Header().Get("Location"))
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "https://example.com", data["url"])
+		require.NotContains(t, data, "secret")
+	})
+
+	t.Run("validation error names the field", func(t *testing.T) {
+		handler := webhooks.NewHandler(&stubService{
+			createFn: func(ctx context.Context, input webhooks.WebhookInput) (webhooks.Webhook, error) {
+				return webhooks.Webhook{}, &webhooks.ValidationError{Field: "url", Message: "url must be an absolute http or https URL"}
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"nope"}`))
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		response := decodeResponse(t, rec)
+		require.Equal(t, "invalid_input", response.Error.Code)
+		require.Equal(t, "url", response.Error.Details[0].Field)
+	})
+}
+
+func TestHandler_Test(t *testing.T) {
+	t.Run("accepted with the delivery", func(t *testing.T) {
+		handler := webhooks.NewHandler(&stubService{})
+
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/webhooks/"+webhookID+"/test", nil), "id", webhookID)
+		rec := httptest.NewRecorder()
+
+		handler.Test(rec, req)
+
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Equal(t, "ping", data["event"])
+		require.Equal(t, "pending", data["status"])
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		handler := webhooks.NewHandler(&stubService{testErr: webhooks.ErrorNotFound})
+
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/webhooks/"+webhookID+"/test", nil), "id", webhookID)
+		rec := httptest.NewRecorder()
+
+		handler.Test(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		service := &stubService{}
+		handler := webhooks.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodPost, "/webhooks/nope/test", nil), "id", "nope")
+		rec := httptest.NewRecorder()
+
+		handler.Test(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.False(t, service.called)
+	})
+}
+
+func TestHandler_Deliveries(t *testing.T) {
+	t.Run("paginated log", func(t *testing.T) {
+		service := &stubService{}
+		handler := webhooks.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/webhooks/"+webhookID+"/deliveries?status=DEAD&page=2&limit=500", nil), "id", webhookID)
+		rec := httptest.NewRecorder()
+
+		handler.Deliveries(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, webhooks.DeliveryDead, service.status)
+		require.Equal(t, 2, service.page)
+		require.Equal(t, 100, service.limit, "limit is capped")
+		data := asMap(t, decodeResponse(t, rec).Data)
+		require.Len(t, data["deliveries"], 1)
+		pagination := asMap(t, data["pagination"])
+		require.Equal(t, json.Number("45"), pagination["total"])
+		require.Equal(t, true, pagination["has_prev"])
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		service := &stubService{}
+		handler := webhooks.NewHandler(service)
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/webhooks/"+webhookID+"/deliveries?page=0", nil), "id", webhookID)
+		rec := httptest.NewRecorder()
+
+		handler.Deliveries(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_pagination", decodeResponse(t, rec).Error.Code)
+		require.False(t, service.called)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		handler := webhooks.NewHandler(&stubService{
+			deliveriesFn: func(ctx context.Context, id string, status webhooks.DeliveryStatus, page, limit int) (webhooks.DeliveryPage, error) {
+				return webhooks.DeliveryPage{}, &webhooks.ValidationError{Field: "status", Message: "status must be pending, delivered or dead"}
+			},
+		})
+
+		req := withURLParam(httptest.NewRequest(http.MethodGet, "/webhooks/"+webhookID+"/deliveries?status=failed", nil), "id", webhookID)
+		rec := httptest.NewRecorder()
+
+		handler.Deliveries(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "invalid_filter", decodeResponse(t, rec).Error.Code)
+	})
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	router := chi.NewRouter()
+	webhooks.RegisterRoutes(router, webhooks.NewHandler(&stubService{}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks/"+webhookID+"/test", nil))
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder) httpx.Response {
+	t.Helper()
+
+	var response httpx.Response
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&response))
+	return response
+}
+
+func asMap(t *testing.T, value any) map[string]any {
+	t.Helper()
+
+	out, ok := value.(map[string]any)
+	require.True(t, ok, "expected map, got %T", value)
+	return out
+}
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
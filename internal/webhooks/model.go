@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipos de evento a los que se puede suscribir un webhook. Coinciden con los de GET /items/events.
+const (
+	EventItemCreated = "item.created"
+	EventItemUpdated = "item.updated"
+	EventItemDeleted = "item.deleted"
+	// EventPing solo lo manda POST /webhooks/{id}/test; no hace falta suscribirse.
+	EventPing = "ping"
+)
+
+// EventTypes son los eventos a los que se puede suscribir un webhook, en el orden de la documentación.
+var EventTypes = []string{EventItemCreated, EventItemUpdated, EventItemDeleted}
+
+// Webhook es una suscripción: a qué URL mandar qué eventos. Secret firma cada entrega y nunca se
+// devuelve en las respuestas.
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookInput es el payload de POST /webhooks.
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// WebhookUpdate es el payload de PATCH /webhooks/{id}: solo cambia los campos que vienen.
+type WebhookUpdate struct {
+	URL    *string  `json:"url"`
+	Secret *string  `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// DeliveryStatus es el estado de una entrega.
+type DeliveryStatus string
+
+// Estados de una entrega: pending se va a (re)intentar, delivered recibió un 2xx y dead agotó los intentos.
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+// Delivery es un evento para un webhook y el estado de su entrega.
+type Delivery struct {
+	ID        string          `json:"id"`
+	WebhookID string          `json:"webhook_id"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    DeliveryStatus  `json:"status"`
+	Attempts  int             `json:"attempts"`
+	// NextAttemptAt es cuándo se vuelve a intentar; solo importa mientras está pending.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// ResponseStatus es el status HTTP del último intento; no viene si no hubo respuesta.
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// DeliveryPage es una página del log de entregas de un webhook.
+type DeliveryPage struct {
+	Deliveries []Delivery
+	Total      int
+}
+
+// Attempt es una entrega tomada por el job, con lo necesario para mandarla. Attempts ya cuenta este intento.
+type Attempt struct {
+	DeliveryID string
+	WebhookID  string
+	URL        string
+	Secret     string
+	Event      string
+	Payload    []byte
+	Attempts   int
+}
+
+// Failure es el resultado de un intento fallido. RetryAt nil deja la entrega en dead.
+type Failure struct {
+	ResponseStatus *int
+	Error          string
+	RetryAt        *time.Time
+}
+
+// Payload es el body de cada entrega. Data es el evento de items (id, operation e item) o, en un ping,
+// el id del webhook.
+type Payload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
@@ -0,0 +1,278 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// dbQuerier define el contrato para acceder a la base de datos.
+type dbQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Repository accede a las tablas webhooks y webhook_deliveries.
+type Repository struct {
+	database dbQuerier
+}
+
+// NewRepository crea un repositorio de webhooks.
+func NewRepository(database dbQuerier) *Repository {
+	return &Repository{database: database}
+}
+
+// webhookColumns son las columnas de Webhook en el orden en que las escanea webhookDestinations.
+const webhookColumns = `id, url, secret, events, created_at, updated_at`
+
+// webhookDestinations devuelve los destinos de Scan para las columnas de webhookColumns.
+func webhookDestinations(webhook *Webhook) []any {
+	return []any{&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.Events, &webhook.CreatedAt, &webhook.UpdatedAt}
+}
+
+// deliveryColumns son las columnas de Delivery en el orden en que las escanea deliveryDestinations.
+const deliveryColumns = `id, webhook_id, event, payload, status, attempts, next_attempt_at, response_status, last_error, created_at, delivered_at`
+
+// deliveryDestinations devuelve los destinos de Scan para las columnas de deliveryColumns.
+func deliveryDestinations(delivery *Delivery) []any {
+	return []any{
+		&delivery.ID, &delivery.WebhookID, &delivery.Event, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &delivery.ResponseStatus, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt,
+	}
+}
+
+// Insert crea un webhook y devuelve el registro persistido.
+func (repository *Repository) Insert(ctx context.Context, input WebhookInput) (Webhook, error) {
+	const query = `INSERT INTO webhooks (url, secret, events) VALUES ($1, $2, $3) RETURNING ` + webhookColumns + `;`
+
+	var webhook Webhook
+	if err := repository.database.QueryRow(ctx, query, input.URL, input.Secret, input.Events).Scan(webhookDestinations(&webhook)...); err != nil {
+		return Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// List devuelve todos los webhooks, del más viejo al más nuevo.
+func (repository *Repository) List(ctx context.Context) ([]Webhook, error) {
+	const query = `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY created_at, id;`
+
+	rows, err := repository.database.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]Webhook, 0)
+	for rows.Next() {
+		var webhook Webhook
+		if err := rows.Scan(webhookDestinations(&webhook)...); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetByID busca un webhook por su ID. Devuelve ErrorNotFound si no existe.
+func (repository *Repository) GetByID(ctx context.Context, id string) (Webhook, error) {
+	const query = `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1;`
+
+	var webhook Webhook
+	if err := repository.database.QueryRow(ctx, query, id).Scan(webhookDestinations(&webhook)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, ErrorNotFound
+		}
+		return Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// Update cambia los campos no nulos de input. Devuelve ErrorNotFound si el webhook no existe.
+func (repository *Repository) Update(ctx context.Context, id string, input WebhookUpdate) (Webhook, error) {
+	const query = `
+		UPDATE webhooks
+		SET url = COALESCE($1, url), secret = COALESCE($2, secret), events = COALESCE($3, events), updated_at = now()
+		WHERE id = $4
+		RETURNING ` + webhookColumns + `;`
+
+	var webhook Webhook
+	if err := repository.database.QueryRow(ctx, query, input.URL, input.Secret, input.Events, id).Scan(webhookDestinations(&webhook)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, ErrorNotFound
+		}
+		return Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// Delete borra el webhook; la FK borra en cascada su log de entregas. Devuelve ErrorNotFound si no existe.
+func (repository *Repository) Delete(ctx context.Context, id string) error {
+	const query = `DELETE FROM webhooks WHERE id = $1 RETURNING id;`
+
+	var deletedID string
+	if err := repository.database.QueryRow(ctx, query, id).Scan(&deletedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// InsertDelivery encola una entrega para el webhook. Si el webhook se borró en el medio, la FK lo
+// impide y devuelve ErrorNotFound.
+func (repository *Repository) InsertDelivery(ctx context.Context, webhookID, event string, payload []byte) (Delivery, error) {
+	const query = `INSERT INTO webhook_deliveries (webhook_id, event, payload) VALUES ($1, $2, $3) RETURNING ` + deliveryColumns + `;`
+
+	var delivery Delivery
+	if err := repository.database.QueryRow(ctx, query, webhookID, event, payload).Scan(deliveryDestinations(&delivery)...); err != nil {
+		return Delivery{}, constraintViolation(err)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries devuelve una página del log de entregas del webhook, de la más nueva a la más
+// vieja, y el total. status vacío no filtra.
+func (repository *Repository) ListDeliveries(ctx context.Context, webhookID string, status DeliveryStatus, limit, offset int) ([]Delivery, int, error) {
+	const countQuery = `SELECT count(*) FROM webhook_deliveries WHERE webhook_id = $1 AND ($2::text = '' OR status = $2::text);`
+	const query = `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2::text = '' OR status = $2::text)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4;`
+
+	var total int
+	if err := repository.database.QueryRow(ctx, countQuery, webhookID, string(status)).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repository.database.Query(ctx, query, webhookID, string(status), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]Delivery, 0, limit)
+	for rows.Next() {
+		var delivery Delivery
+		if err := rows.Scan(deliveryDestinations(&delivery)...); err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// EnqueueDeliveries encola event para cada webhook suscripto a ese tipo de evento y devuelve
+// cuántas entregas creó.
+func (repository *Repository) EnqueueDeliveries(ctx context.Context, event string, payload []byte) (int, error) {
+	const query = `
+		WITH enqueued AS (
+			INSERT INTO webhook_deliveries (webhook_id, event, payload)
+			SELECT id, $1::text, $2 FROM webhooks WHERE $1::text = ANY(events)
+			RETURNING 1
+		)
+		SELECT count(*) FROM enqueued;`
+
+	var enqueued int
+	if err := repository.database.QueryRow(ctx, query, event, payload).Scan(&enqueued); err != nil {
+		return 0, err
+	}
+	return enqueued, nil
+}
+
+// ClaimDue toma hasta limit entregas pendientes cuyo intento ya venció, cuenta el intento y corre
+// next_attempt_at en lease: si el proceso muere antes de registrar el resultado, la entrega vuelve
+// a estar disponible cuando vence el lease. FOR UPDATE SKIP LOCKED evita que dos instancias tomen
+// la misma entrega.
+func (repository *Repository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Attempt, error) {
+	const query = `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries AS delivery
+			SET attempts = delivery.attempts + 1, next_attempt_at = now() + $2 * interval '1 millisecond'
+			FROM due
+			WHERE delivery.id = due.id
+			RETURNING delivery.id, delivery.webhook_id, delivery.event, delivery.payload, delivery.attempts, delivery.created_at
+		)
+		SELECT claimed.id, claimed.webhook_id, webhook.url, webhook.secret, claimed.event, claimed.payload, claimed.attempts
+		FROM claimed
+		JOIN webhooks AS webhook ON webhook.id = claimed.webhook_id
+		ORDER BY claimed.created_at, claimed.id;`
+
+	rows, err := repository.database.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := make([]Attempt, 0, limit)
+	for rows.Next() {
+		var attempt Attempt
+		if err := rows.Scan(&attempt.DeliveryID, &attempt.WebhookID, &attempt.URL, &attempt.Secret, &attempt.Event, &attempt.Payload, &attempt.Attempts); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts, rows.Err()
+}
+
+// MarkDelivered registra que la entrega recibió un 2xx. Devuelve ErrorNotFound si la entrega ya no
+// existe (se borró su webhook).
+func (repository *Repository) MarkDelivered(ctx context.Context, id string, responseStatus int) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', response_status = $2, last_error = NULL, delivered_at = now()
+		WHERE id = $1
+		RETURNING id;`
+
+	var updatedID string
+	if err := repository.database.QueryRow(ctx, query, id, responseStatus).Scan(&updatedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// MarkFailed registra un intento fallido: con failure.RetryAt la entrega sigue pending hasta ese
+// momento; sin él queda dead. Devuelve ErrorNotFound si la entrega ya no existe.
+func (repository *Repository) MarkFailed(ctx context.Context, id string, failure Failure) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $4::timestamptz IS NULL THEN 'dead' ELSE 'pending' END,
+			next_attempt_at = COALESCE($4::timestamptz, next_attempt_at),
+			response_status = $2,
+			last_error = $3
+		WHERE id = $1
+		RETURNING id;`
+
+	var updatedID string
+	if err := repository.database.QueryRow(ctx, query, id, failure.ResponseStatus, failure.Error, failure.RetryAt).Scan(&updatedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrorNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// constraintViolation traduce una violación de constraint al error de dominio: la FK de las
+// entregas (23503) indica que el webhook ya no existe.
+func constraintViolation(err error) error {
+	var postgresError *pgconn.PgError
+	if errors.As(err, &postgresError) && postgresError.Code == "23503" && postgresError.ConstraintName == "fk_webhook_deliveries_webhook" {
+		return ErrorNotFound
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Lelo88/catalog-api-golang/internal/db"
+	"github.com/Lelo88/catalog-api-golang/internal/outbox"
+	"github.com/Lelo88/catalog-api-golang/internal/webhooks"
+)
+
+// Tests de integración contra PostgreSQL real (make it).
+// Requieren DATABASE_URL y las migraciones aplicadas.
+
+func TestRepositoryIntegration_Deliveries(t *testing.T) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	pool, err := db.NewPool(context.Background(), databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	repository := webhooks.NewRepository(pool)
+	service := webhooks.NewService(repository)
+	webhook, err := service.Create(ctx, webhooks.WebhookInput{URL: server.URL, Secret: "0123456789abcdef", Events: []string{webhooks.EventItemDeleted}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = service.Delete(ctx, webhook.ID) })
+
+	dispatcher := webhooks.NewDispatcher(repository, webhooks.WithMaxAttempts(1))
+	require.NoError(t, dispatcher.Publish(ctx, outbox.Message{ID: 1, EventType: webhooks.EventItemCreated, Payload: json.RawMessage(`{"id":"id-1","operation":"created"}`)}))
+	require.NoError(t, dispatcher.Publish(ctx, outbox.Message{ID: 2, EventType: webhooks.EventItemDeleted, Payload: json.RawMessage(`{"id":"id-1","operation":"deleted"}`)}))
+	require.NoError(t, dispatcher.Deliver(ctx))
+
+	page, err := service.Deliveries(ctx, webhook.ID, webhooks.DeliveryDelivered, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total, "only subscribed events are delivered")
+	require.Equal(t, webhooks.EventItemDeleted, page.Deliveries[0].Event)
+	require.Equal(t, 1, page.Deliveries[0].Attempts)
+
+	status = http.StatusServiceUnavailable
+	_, err = service.Test(ctx, webhook.ID)
+	require.NoError(t, err)
+	require.NoError(t, dispatcher.Deliver(ctx))
+
+	page, err = service.Deliveries(ctx, webhook.ID, webhooks.DeliveryDead, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, page.Total)
+	require.Equal(t, webhooks.EventPing, page.Deliveries[0].Event)
+	require.Equal(t, http.StatusServiceUnavailable, *page.Deliveries[0].ResponseStatus)
+
+	require.NoError(t, service.Delete(ctx, webhook.ID))
+	_, err = service.Deliveries(ctx, webhook.ID, "", 1, 10)
+	require.ErrorIs(t, err, webhooks.ErrorNotFound)
+}
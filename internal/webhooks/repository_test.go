@@ -0,0 +1,151 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Insert(t *testing.T) {
+	now := time.Now()
+	database := &fakeDB{row: &fakeRow{values: []any{"webhook-1", "https://example.com", "0123456789abcdef", []string{EventItemCreated}, now, now}}}
+	repository := NewRepository(database)
+
+	webhook, err := repository.Insert(context.Background(), WebhookInput{URL: "https://example.com", Secret: "0123456789abcdef", Events: []string{EventItemCreated}})
+
+	require.NoError(t, err)
+	require.Equal(t, Webhook{ID: "webhook-1", URL: "https://example.com", Secret: "0123456789abcdef", Events: []string{EventItemCreated}, CreatedAt: now, UpdatedAt: now}, webhook)
+	require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO webhooks (url, secret, events) VALUES ($1, $2, $3)")
+}
+
+func TestRepository_Update(t *testing.T) {
+	t.Run("keeps the fields not sent", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{"webhook-1", "https://example.com", "0123456789abcdef", []string{EventItemCreated}, now, now}}}
+		repository := NewRepository(database)
+		target := "https://example.com"
+
+		_, err := repository.Update(context.Background(), "webhook-1", WebhookUpdate{URL: &target})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "SET url = COALESCE($1, url), secret = COALESCE($2, secret), events = COALESCE($3, events)")
+		require.Equal(t, []any{&target, (*string)(nil), []string(nil), "webhook-1"}, database.lastArgs)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{row: &fakeRow{err: pgx.ErrNoRows}})
+
+		_, err := repository.Update(context.Background(), "webhook-1", WebhookUpdate{})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_InsertDelivery(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		now := time.Now()
+		database := &fakeDB{row: &fakeRow{values: []any{
+			"delivery-1", "webhook-1", EventPing, json.RawMessage(`{}`), DeliveryPending, 0, now, (*int)(nil), (*string)(nil), now, (*time.Time)(nil),
+		}}}
+		repository := NewRepository(database)
+
+		delivery, err := repository.InsertDelivery(context.Background(), "webhook-1", EventPing, []byte(`{}`))
+
+		require.NoError(t, err)
+		require.Equal(t, DeliveryPending, delivery.Status)
+		require.Contains(t, normalizeSQL(database.lastQuery), "INSERT INTO webhook_deliveries (webhook_id, event, payload) VALUES ($1, $2, $3)")
+	})
+
+	t.Run("webhook deleted meanwhile", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{err: &pgconn.PgError{Code: "23503", ConstraintName: "fk_webhook_deliveries_webhook"}}}
+		repository := NewRepository(database)
+
+		_, err := repository.InsertDelivery(context.Background(), "webhook-1", EventPing, []byte(`{}`))
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+func TestRepository_EnqueueDeliveries(t *testing.T) {
+	database := &fakeDB{row: &fakeRow{values: []any{2}}}
+	repository := NewRepository(database)
+
+	enqueued, err := repository.EnqueueDeliveries(context.Background(), EventItemCreated, []byte(`{}`))
+
+	require.NoError(t, err)
+	require.Equal(t, 2, enqueued)
+	require.Contains(t, normalizeSQL(database.lastQuery), "SELECT id, $1::text, $2 FROM webhooks WHERE $1::text = ANY(events)")
+}
+
+func TestRepository_MarkFailed(t *testing.T) {
+	t.Run("without retry the delivery is dead", func(t *testing.T) {
+		database := &fakeDB{row: &fakeRow{values: []any{"delivery-1"}}}
+		repository := NewRepository(database)
+
+		err := repository.MarkFailed(context.Background(), "delivery-1", Failure{Error: "timeout"})
+
+		require.NoError(t, err)
+		require.Contains(t, normalizeSQL(database.lastQuery), "CASE WHEN $4::timestamptz IS NULL THEN 'dead' ELSE 'pending' END")
+		require.Equal(t, []any{"delivery-1", (*int)(nil), "timeout", (*time.Time)(nil)}, database.lastArgs)
+	})
+
+	t.Run("delivery gone", func(t *testing.T) {
+		repository := NewRepository(&fakeDB{row: &fakeRow{err: pgx.ErrNoRows}})
+
+		err := repository.MarkFailed(context.Background(), "delivery-1", Failure{Error: "timeout"})
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
+
+type fakeDB struct {
+	row       *fakeRow
+	lastQuery string
+	lastArgs  []any
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.lastQuery = sql
+	db.lastArgs = args
+	if db.row == nil {
+		return &fakeRow{err: errors.New("unexpected QueryRow call")}
+	}
+	return db.row
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.lastQuery = sql
+	db.lastArgs = args
+	return nil, errors.New("unexpected Query call")
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (row *fakeRow) Scan(dest ...any) error {
+	if row.err != nil {
+		return row.err
+	}
+	if len(dest) != len(row.values) {
+		return fmt.Errorf("dest len %d does not match values len %d", len(dest), len(row.values))
+	}
+	for i, destination := range dest {
+		reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(row.values[i]))
+	}
+	return nil
+}
+
+func normalizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
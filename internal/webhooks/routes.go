@@ -0,0 +1,16 @@
+package webhooks
+
+import "github.com/go-chi/chi/v5"
+
+// RegisterRoutes registra las rutas de webhooks en el router.
+func RegisterRoutes(route chi.Router, handler *Handler) {
+	route.Route("/webhooks", func(route chi.Router) {
+		route.Post("/", handler.Create)
+		route.Get("/", handler.List)
+		route.Get("/{id}", handler.Get)
+		route.Patch("/{id}", handler.Update)
+		route.Delete("/{id}", handler.Delete)
+		route.Post("/{id}/test", handler.Test)
+		route.Get("/{id}/deliveries", handler.Deliveries)
+	})
+}
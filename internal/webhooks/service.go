@@ -0,0 +1,203 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Errores de dominio (no HTTP). El handler los traduce a status codes.
+var (
+	ErrorInvalidInput = errors.New("invalid webhook")
+	ErrorNotFound     = errors.New("webhook not found")
+)
+
+// ValidationError describe un error de validación atado a un campo del payload.
+// Envuelve ErrorInvalidInput, así que errors.Is(err, ErrorInvalidInput) es true.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implementa error.
+func (validationError *ValidationError) Error() string {
+	return validationError.Field + ": " + validationError.Message
+}
+
+// Unwrap permite que errors.Is reconozca el error como ErrorInvalidInput.
+func (validationError *ValidationError) Unwrap() error {
+	return ErrorInvalidInput
+}
+
+// Límites de los campos de un webhook.
+const (
+	maxURLLength    = 2048
+	minSecretLength = 16
+	maxSecretLength = 256
+)
+
+// RepositoryAPI define lo que el service necesita del repositorio.
+type RepositoryAPI interface {
+	Insert(ctx context.Context, input WebhookInput) (Webhook, error)
+	List(ctx context.Context) ([]Webhook, error)
+	// GetByID devuelve ErrorNotFound si el webhook no existe.
+	GetByID(ctx context.Context, id string) (Webhook, error)
+	// Update cambia los campos no nulos de input; devuelve ErrorNotFound si el webhook no existe.
+	Update(ctx context.Context, id string, input WebhookUpdate) (Webhook, error)
+	// Delete borra el webhook con su log de entregas; devuelve ErrorNotFound si no existe.
+	Delete(ctx context.Context, id string) error
+	// InsertDelivery encola una entrega para un solo webhook.
+	InsertDelivery(ctx context.Context, webhookID, event string, payload []byte) (Delivery, error)
+	// ListDeliveries devuelve una página del log de entregas del webhook, de la más nueva a la más
+	// vieja, y el total. status vacío no filtra.
+	ListDeliveries(ctx context.Context, webhookID string, status DeliveryStatus, limit, offset int) ([]Delivery, int, error)
+}
+
+// Service contiene las reglas de los webhooks.
+type Service struct {
+	repository RepositoryAPI
+	now        func() time.Time
+}
+
+// NewService crea un service de webhooks.
+func NewService(repository RepositoryAPI) *Service {
+	return &Service{repository: repository, now: time.Now}
+}
+
+// Create valida y crea un webhook.
+func (service *Service) Create(ctx context.Context, input WebhookInput) (Webhook, error) {
+	var err error
+	if input.URL, err = normalizeURL(input.URL); err != nil {
+		return Webhook{}, err
+	}
+	if err := secretError(input.Secret); err != nil {
+		return Webhook{}, err
+	}
+	if input.Events, err = normalizeEvents(input.Events); err != nil {
+		return Webhook{}, err
+	}
+	return service.repository.Insert(ctx, input)
+}
+
+// List devuelve todos los webhooks.
+func (service *Service) List(ctx context.Context) ([]Webhook, error) {
+	return service.repository.List(ctx)
+}
+
+// Get devuelve un webhook por ID.
+func (service *Service) Get(ctx context.Context, id string) (Webhook, error) {
+	return service.repository.GetByID(ctx, id)
+}
+
+// Update cambia la URL, el secret o los eventos del webhook. Tiene que venir al menos uno.
+func (service *Service) Update(ctx context.Context, id string, input WebhookUpdate) (Webhook, error) {
+	if input.URL == nil && input.Secret == nil && input.Events == nil {
+		return Webhook{}, &ValidationError{Field: "body", Message: "at least one of url, secret or events is required"}
+	}
+	if input.URL != nil {
+		normalized, err := normalizeURL(*input.URL)
+		if err != nil {
+			return Webhook{}, err
+		}
+		input.URL = &normalized
+	}
+	if input.Secret != nil {
+		if err := secretError(*input.Secret); err != nil {
+			return Webhook{}, err
+		}
+	}
+	if input.Events != nil {
+		var err error
+		if input.Events, err = normalizeEvents(input.Events); err != nil {
+			return Webhook{}, err
+		}
+	}
+	return service.repository.Update(ctx, id, input)
+}
+
+// Delete borra un webhook; sus entregas pendientes ya no se mandan.
+func (service *Service) Delete(ctx context.Context, id string) error {
+	return service.repository.Delete(ctx, id)
+}
+
+// Test encola un evento ping para el webhook, con su id en data. Lo manda el job de entregas
+// como cualquier otro evento, así el log muestra si la URL y el secret funcionan.
+func (service *Service) Test(ctx context.Context, id string) (Delivery, error) {
+	if _, err := service.repository.GetByID(ctx, id); err != nil {
+		return Delivery{}, err
+	}
+	payload, err := json.Marshal(Payload{Event: EventPing, OccurredAt: service.now().UTC(), Data: map[string]string{"webhook_id": id}})
+	if err != nil {
+		return Delivery{}, err
+	}
+	return service.repository.InsertDelivery(ctx, id, EventPing, payload)
+}
+
+// Deliveries devuelve una página del log de entregas del webhook. Un webhook que no existe devuelve
+// ErrorNotFound.
+func (service *Service) Deliveries(ctx context.Context, id string, status DeliveryStatus, page, limit int) (DeliveryPage, error) {
+	if page < 1 || limit < 1 {
+		return DeliveryPage{}, ErrorInvalidInput
+	}
+	switch status {
+	case "", DeliveryPending, DeliveryDelivered, DeliveryDead:
+	default:
+		return DeliveryPage{}, &ValidationError{Field: "status", Message: "status must be pending, delivered or dead"}
+	}
+	if _, err := service.repository.GetByID(ctx, id); err != nil {
+		return DeliveryPage{}, err
+	}
+
+	deliveries, total, err := service.repository.ListDeliveries(ctx, id, status, limit, (page-1)*limit)
+	if err != nil {
+		return DeliveryPage{}, err
+	}
+	return DeliveryPage{Deliveries: deliveries, Total: total}, nil
+}
+
+// normalizeURL valida que target sea una URL http o https absoluta y la devuelve sin espacios.
+func normalizeURL(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", &ValidationError{Field: "url", Message: "url must be an absolute http or https URL"}
+	}
+	if len(target) > maxURLLength {
+		return "", &ValidationError{Field: "url", Message: "url must be at most 2048 characters"}
+	}
+	return target, nil
+}
+
+// secretError valida el largo del secret con el que se firman las entregas.
+func secretError(secret string) error {
+	if len(secret) < minSecretLength || len(secret) > maxSecretLength {
+		return &ValidationError{Field: "secret", Message: "secret must be between 16 and 256 characters"}
+	}
+	return nil
+}
+
+// normalizeEvents valida los tipos de evento y los devuelve sin repetir, en el orden de EventTypes.
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, &ValidationError{Field: "events", Message: "events must not be empty"}
+	}
+	requested := make(map[string]bool, len(events))
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !slices.Contains(EventTypes, event) {
+			return nil, &ValidationError{Field: "events", Message: "events must be item.created, item.updated or item.deleted"}
+		}
+		requested[event] = true
+	}
+	normalized := make([]string, 0, len(requested))
+	for _, event := range EventTypes {
+		if requested[event] {
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
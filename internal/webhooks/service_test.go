@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	insertInput    WebhookInput
+	insertCalled   bool
+	updateInput    WebhookUpdate
+	updateCalled   bool
+	getErr         error
+	delivery       Delivery
+	deliveryEvent  string
+	deliveryBody   []byte
+	listStatus     DeliveryStatus
+	listLimit      int
+	listOffset     int
+	listDeliveries []Delivery
+	listTotal      int
+}
+
+func (fakerepo *fakeRepo) Insert(ctx context.Context, input WebhookInput) (Webhook, error) {
+	fakerepo.insertCalled = true
+	fakerepo.insertInput = input
+	return Webhook{ID: "webhook-1", URL: input.URL, Secret: input.Secret, Events: input.Events}, nil
+}
+
+func (fakerepo *fakeRepo) List(ctx context.Context) ([]Webhook, error) {
+	return []Webhook{}, nil
+}
+
+func (fakerepo *fakeRepo) GetByID(ctx context.Context, id string) (Webhook, error) {
+	if fakerepo.getErr != nil {
+		return Webhook{}, fakerepo.getErr
+	}
+	return Webhook{ID: id}, nil
+}
+
+func (fakerepo *fakeRepo) Update(ctx context.Context, id string, input WebhookUpdate) (Webhook, error) {
+	fakerepo.updateCalled = true
+	fakerepo.updateInput = input
+	return Webhook{ID: id}, nil
+}
+
+func (fakerepo *fakeRepo) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (fakerepo *fakeRepo) InsertDelivery(ctx context.Context, webhookID, event string, payload []byte) (Delivery, error) {
+	fakerepo.deliveryEvent = event
+	fakerepo.deliveryBody = payload
+	return Delivery{ID: "delivery-1", WebhookID: webhookID, Event: event, Payload: payload, Status: DeliveryPending}, nil
+}
+
+func (fakerepo *fakeRepo) ListDeliveries(ctx context.Context, webhookID string, status DeliveryStatus, limit, offset int) ([]Delivery, int, error) {
+	fakerepo.listStatus = status
+	fakerepo.listLimit = limit
+	fakerepo.listOffset = offset
+	return fakerepo.listDeliveries, fakerepo.listTotal, nil
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("normalizes url and events", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Create(context.Background(), WebhookInput{
+			URL:    " https://example.com/hooks ",
+			Secret: "0123456789abcdef",
+			Events: []string{"item.deleted", " ITEM.CREATED", "item.deleted"},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/hooks", repository.insertInput.URL)
+		require.Equal(t, []string{EventItemCreated, EventItemDeleted}, repository.insertInput.Events)
+	})
+
+	invalid := map[string]struct {
+		input WebhookInput
+		field string
+	}{
+		"relative url":   {WebhookInput{URL: "/hooks", Secret: "0123456789abcdef", Events: []string{"item.created"}}, "url"},
+		"ftp url":        {WebhookInput{URL: "ftp://example.com", Secret: "0123456789abcdef", Events: []string{"item.created"}}, "url"},
+		"short secret":   {WebhookInput{URL: "https://example.com", Secret: "short", Events: []string{"item.created"}}, "secret"},
+		"no events":      {WebhookInput{URL: "https://example.com", Secret: "0123456789abcdef"}, "events"},
+		"unknown event":  {WebhookInput{URL: "https://example.com", Secret: "0123456789abcdef", Events: []string{"item.moved"}}, "events"},
+		"ping is opt-in": {WebhookInput{URL: "https://example.com", Secret: "0123456789abcdef", Events: []string{"ping"}}, "events"},
+	}
+	for name, testCase := range invalid {
+		t.Run(name, func(t *testing.T) {
+			repository := &fakeRepo{}
+			service := NewService(repository)
+
+			_, err := service.Create(context.Background(), testCase.input)
+
+			var validationError *ValidationError
+			require.ErrorAs(t, err, &validationError)
+			require.ErrorIs(t, err, ErrorInvalidInput)
+			require.Equal(t, testCase.field, validationError.Field)
+			require.False(t, repository.insertCalled)
+		})
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	t.Run("only validates the fields sent", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "webhook-1", WebhookUpdate{Events: []string{"item.updated"}})
+
+		require.NoError(t, err)
+		require.Nil(t, repository.updateInput.URL)
+		require.Equal(t, []string{EventItemUpdated}, repository.updateInput.Events)
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+
+		_, err := service.Update(context.Background(), "webhook-1", WebhookUpdate{})
+
+		require.ErrorIs(t, err, ErrorInvalidInput)
+		require.False(t, repository.updateCalled)
+	})
+}
+
+func TestService_Test(t *testing.T) {
+	t.Run("enqueues a ping", func(t *testing.T) {
+		repository := &fakeRepo{}
+		service := NewService(repository)
+		service.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+		delivery, err := service.Test(context.Background(), "webhook-1")
+
+		require.NoError(t, err)
+		require.Equal(t, DeliveryPending, delivery.Status)
+		require.Equal(t, EventPing, repository.deliveryEvent)
+		require.JSONEq(t, `{"event":"ping","occurred_at":"2025-06-01T12:00:00Z","data":{"webhook_id":"webhook-1"}}`, string(repository.deliveryBody))
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		repository := &fakeRepo{getErr: ErrorNotFound}
+		service := NewService(repository)
+
+		_, err := service.Test(context.Background(), "webhook-1")
+
+		require.ErrorIs(t, err, ErrorNotFound)
+		require.Empty(t, repository.deliveryEvent)
+	})
+}
+
+func TestService_Deliveries(t *testing.T) {
+	t.Run("pages by offset", func(t *testing.T) {
+		repository := &fakeRepo{listDeliveries: []Delivery{{ID: "delivery-1", Payload: json.RawMessage(`{}`)}}, listTotal: 41}
+		service := NewService(repository)
+
+		page, err := service.Deliveries(context.Background(), "webhook-1", DeliveryDead, 3, 20)
+
+		require.NoError(t, err)
+		require.Equal(t, 41, page.Total)
+		require.Len(t, page.Deliveries, 1)
+		require.Equal(t, DeliveryDead, repository.listStatus)
+		require.Equal(t, 20, repository.listLimit)
+		require.Equal(t, 40, repository.listOffset)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		service := NewService(&fakeRepo{})
+
+		_, err := service.Deliveries(context.Background(), "webhook-1", "failed", 1, 20)
+
+		var validationError *ValidationError
+		require.ErrorAs(t, err, &validationError)
+		require.Equal(t, "status", validationError.Field)
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		service := NewService(&fakeRepo{getErr: ErrorNotFound})
+
+		_, err := service.Deliveries(context.Background(), "webhook-1", "", 1, 20)
+
+		require.ErrorIs(t, err, ErrorNotFound)
+	})
+}
@@ -0,0 +1,2 @@
+-- Rollback del índice de búsqueda por prefijo.
+DROP INDEX IF EXISTS ix_items_name_lower_pattern;
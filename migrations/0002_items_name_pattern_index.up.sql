@@ -0,0 +1,3 @@
+-- Índice para búsqueda por prefijo (match=prefix) y exacta sin distinguir mayúsculas (match=exact).
+-- text_pattern_ops permite que LIKE 'abc%' use el índice sin depender del collation.
+CREATE INDEX IF NOT EXISTS ix_items_name_lower_pattern ON items (lower(name) text_pattern_ops);
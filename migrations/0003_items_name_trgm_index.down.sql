@@ -0,0 +1,2 @@
+-- Rollback del índice de trigramas. La extensión se deja instalada: puede usarla otra base u objeto.
+DROP INDEX IF EXISTS ix_items_name_trgm;
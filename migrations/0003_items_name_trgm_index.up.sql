@@ -0,0 +1,4 @@
+-- Búsqueda aproximada (fuzzy=true) con pg_trgm.
+-- El índice GIN de trigramas también acelera el ILIKE '%q%' de match=contains.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS ix_items_name_trgm ON items USING gin (name gin_trgm_ops);
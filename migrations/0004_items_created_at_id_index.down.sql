@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ix_items_created_at_id;
@@ -0,0 +1,5 @@
+-- Orden por defecto del listado y paginación por cursor (keyset).
+-- Con (created_at, id) indexado, WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC
+-- recorre el índice hacia atrás desde el cursor y lee solo las filas de la página, en vez de
+-- saltear las anteriores como hace OFFSET.
+CREATE INDEX IF NOT EXISTS ix_items_created_at_id ON items (created_at, id);
@@ -0,0 +1,27 @@
+-- Slug para URLs legibles en el storefront (/products/wireless-keyboard).
+-- El service lo genera a partir del nombre; acá se completa para los items existentes con la misma regla
+-- (minúsculas, tramos que no son letras o dígitos → guion) y un sufijo -N para los repetidos.
+-- A diferencia del service, el backfill no quita acentos: los slugs existentes se pueden corregir con PATCH.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS slug text;
+
+WITH base AS (
+  SELECT
+    id,
+    created_at,
+    COALESCE(NULLIF(trim(BOTH '-' FROM left(trim(BOTH '-' FROM regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')), 110)), ''), 'item') AS slug
+  FROM items
+  WHERE slug IS NULL
+), numbered AS (
+  SELECT id, slug, row_number() OVER (PARTITION BY slug ORDER BY created_at, id) AS n
+  FROM base
+)
+UPDATE items
+SET slug = CASE WHEN numbered.n = 1 THEN numbered.slug ELSE numbered.slug || '-' || numbered.n END
+FROM numbered
+WHERE items.id = numbered.id;
+
+ALTER TABLE items ALTER COLUMN slug SET NOT NULL;
+
+-- El nombre del índice lo usa el repositorio para distinguir un slug repetido de un nombre repetido.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_items_slug ON items (slug);
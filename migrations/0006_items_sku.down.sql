@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ux_items_sku;
+ALTER TABLE items DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,7 @@
+-- Código de stock del item. Es nullable porque los items existentes no lo tienen;
+-- el índice único admite varios NULL.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS sku text;
+
+-- El nombre del índice lo usa el repositorio para distinguir un SKU repetido de un nombre repetido.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_items_sku ON items (sku);
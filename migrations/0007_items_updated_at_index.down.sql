@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ix_items_updated_at;
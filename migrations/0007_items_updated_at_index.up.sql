@@ -0,0 +1,3 @@
+-- El ETag del listado lee max(updated_at) en cada request de polling; con este índice
+-- sale de la punta del índice en lugar de recorrer la tabla.
+CREATE INDEX IF NOT EXISTS ix_items_updated_at ON items (updated_at);
@@ -0,0 +1,3 @@
+-- Versión del item para concurrencia optimista: cada update la incrementa y PATCH/DELETE
+-- con If-Match solo se aplican si la versión sigue siendo la que leyó el cliente.
+ALTER TABLE items ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
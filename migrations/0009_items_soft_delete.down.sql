@@ -0,0 +1,8 @@
+-- Sin la columna no hay forma de distinguir los items borrados: se eliminan de verdad
+-- antes de volver al índice único sobre toda la tabla.
+DELETE FROM items WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS ux_items_name;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_items_name ON items (name);
+
+ALTER TABLE items DROP COLUMN IF EXISTS deleted_at;
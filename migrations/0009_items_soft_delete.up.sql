@@ -0,0 +1,9 @@
+-- Borrado lógico: DELETE /items/{id} completa deleted_at en lugar de borrar la fila,
+-- así un item borrado por error se puede recuperar sin ir al backup. Los items existentes quedan vivos.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+-- El nombre solo tiene que ser único entre los items vivos: borrar un item libera su nombre.
+-- Se mantiene el nombre del índice porque el repositorio lo usa para reconocer un nombre repetido.
+DROP INDEX IF EXISTS ux_items_name;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_items_name ON items (name) WHERE deleted_at IS NULL;
@@ -0,0 +1,16 @@
+-- Reservas de stock: el checkout retiene unidades mientras se confirma el pago.
+-- Una reserva vencida deja de contar al leer (expires_at > now()) y el job de limpieza la borra después.
+
+CREATE TABLE IF NOT EXISTS item_reservations (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id uuid NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+  quantity integer NOT NULL,
+  expires_at timestamptz NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT ck_item_reservations_quantity_positive CHECK (quantity > 0)
+);
+
+-- Cubre la suma de reservas vigentes por item (available) y el borrado de las vencidas.
+CREATE INDEX IF NOT EXISTS ix_item_reservations_item_id_expires_at ON item_reservations (item_id, expires_at);
+CREATE INDEX IF NOT EXISTS ix_item_reservations_expires_at ON item_reservations (expires_at);
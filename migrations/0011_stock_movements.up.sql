@@ -0,0 +1,15 @@
+-- Historial de movimientos de stock: una fila por cada cambio (alta, PATCH/PUT, ajuste).
+-- Se escribe en la misma transacción que el cambio, así stock y historial no pueden divergir.
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id bigserial PRIMARY KEY,
+  item_id uuid NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+  delta integer NOT NULL,
+  resulting_stock integer NOT NULL,
+  reason text NOT NULL,
+  request_id text,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- GET /items/{id}/stock-movements pagina del más nuevo al más viejo; id desempata los de la misma transacción.
+CREATE INDEX IF NOT EXISTS ix_stock_movements_item_id_created_at_id ON stock_movements (item_id, created_at DESC, id DESC);
@@ -0,0 +1,7 @@
+-- Los items en backorder vuelven a stock 0 para poder restaurar el constraint original.
+UPDATE items SET stock = 0 WHERE stock < 0;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_stock_non_negative_unless_backorder;
+ALTER TABLE items ADD CONSTRAINT ck_items_stock_non_negative CHECK (stock >= 0);
+
+ALTER TABLE items DROP COLUMN IF EXISTS allow_backorder;
@@ -0,0 +1,8 @@
+-- Backorders: un item con allow_backorder puede quedar con stock negativo.
+-- El piso (BACKORDER_STOCK_FLOOR) es configuración y lo valida el service; la base solo garantiza
+-- que un item sin el flag nunca tenga stock negativo.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS allow_backorder boolean NOT NULL DEFAULT false;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_stock_non_negative;
+ALTER TABLE items ADD CONSTRAINT ck_items_stock_non_negative_unless_backorder CHECK (stock >= 0 OR allow_backorder);
@@ -0,0 +1 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_sku_format;
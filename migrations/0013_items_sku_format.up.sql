@@ -0,0 +1,5 @@
+-- El SKU se guarda normalizado: de 3 a 64 mayúsculas, dígitos, puntos, guiones bajos o guiones.
+-- Los SKU existentes ya cumplen (el formato anterior era un subconjunto). Los items previos a la
+-- columna siguen sin SKU: la constraint acepta NULL y el service lo exige en el alta.
+
+ALTER TABLE items ADD CONSTRAINT ck_items_sku_format CHECK (sku ~ '^[A-Z0-9._-]{3,64}$');
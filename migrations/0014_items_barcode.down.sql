@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ux_items_barcode;
+ALTER TABLE items DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,7 @@
+-- Código de barras (EAN-13 o UPC-A) que leen los scanners del depósito. Es opcional; el service
+-- valida el dígito verificador. El índice único admite varios NULL.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS barcode text;
+
+-- El nombre del índice lo usa el repositorio para distinguir un barcode repetido.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_items_barcode ON items (barcode);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ix_items_category_id;
+ALTER TABLE items DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,21 @@
+-- Categorías del catálogo y la categoría (opcional) de cada item.
+-- La FK no tiene ON DELETE: borrar una categoría con items falla (23503) y el repositorio lo
+-- informa como categoría en uso; ?force=true primero deja esos items sin categoría.
+
+CREATE TABLE IF NOT EXISTS categories (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  name text NOT NULL,
+  slug text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- Los nombres de los índices los usa el repositorio para distinguir un nombre repetido de un slug repetido.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_categories_name ON categories (name);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_categories_slug ON categories (slug);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS category_id uuid
+  CONSTRAINT fk_items_category REFERENCES categories (id);
+
+-- Cubre ?category_id= y la búsqueda de items al borrar una categoría.
+CREATE INDEX IF NOT EXISTS ix_items_category_id ON items (category_id);
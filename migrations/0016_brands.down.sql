@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ix_items_brand_id;
+ALTER TABLE items DROP COLUMN IF EXISTS brand_id;
+DROP TABLE IF EXISTS brands;
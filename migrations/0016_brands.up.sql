@@ -0,0 +1,18 @@
+-- Marcas del catálogo y la marca (opcional) de cada item.
+-- Como con las categorías, la FK no tiene ON DELETE: una marca con items no se puede borrar.
+
+CREATE TABLE IF NOT EXISTS brands (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  name text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_brands_name ON brands (name);
+
+-- El nombre de la FK lo usa el repositorio de items para informar un brand_id inexistente.
+ALTER TABLE items ADD COLUMN IF NOT EXISTS brand_id uuid
+  CONSTRAINT fk_items_brand REFERENCES brands (id);
+
+-- Cubre ?brand_id= en el listado y el conteo.
+CREATE INDEX IF NOT EXISTS ix_items_brand_id ON items (brand_id);
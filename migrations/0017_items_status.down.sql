@@ -0,0 +1,2 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_status;
+ALTER TABLE items DROP COLUMN IF EXISTS status;
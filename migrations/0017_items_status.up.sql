@@ -0,0 +1,7 @@
+-- Estado del item: inactive lo saca de la venta sin borrarlo. GET /items muestra solo los
+-- activos por defecto. El check es el que el repositorio traduce a ErrorInvalidStatus.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'active';
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_status;
+ALTER TABLE items ADD CONSTRAINT ck_items_status CHECK (status IN ('active', 'inactive'));
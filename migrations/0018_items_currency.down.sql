@@ -0,0 +1,2 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_currency_format;
+ALTER TABLE items DROP COLUMN IF EXISTS currency;
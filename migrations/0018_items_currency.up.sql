@@ -0,0 +1,7 @@
+-- Moneda del precio (ISO 4217). El service valida el código contra su lista y completa
+-- DEFAULT_CURRENCY en el alta; el default de la columna solo cubre los items existentes.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT 'USD';
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_currency_format;
+ALTER TABLE items ADD CONSTRAINT ck_items_currency_format CHECK (currency ~ '^[A-Z]{3}$');
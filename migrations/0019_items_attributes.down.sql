@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS ix_items_attributes;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_attributes_object;
+
+ALTER TABLE items DROP COLUMN IF EXISTS attributes;
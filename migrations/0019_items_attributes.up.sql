@@ -0,0 +1,10 @@
+-- Atributos libres por tipo de producto (color, material, ...). El service limita el objeto a un
+-- mapa plano; la DB solo asegura que sea un objeto. jsonb_path_ops alcanza para los filtros
+-- attr.<key>=<value>, que son todos de contención (@>), y ocupa menos que el opclass por defecto.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS attributes jsonb;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_attributes_object;
+ALTER TABLE items ADD CONSTRAINT ck_items_attributes_object CHECK (attributes IS NULL OR jsonb_typeof(attributes) = 'object');
+
+CREATE INDEX IF NOT EXISTS ix_items_attributes ON items USING gin (attributes jsonb_path_ops);
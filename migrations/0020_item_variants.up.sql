@@ -0,0 +1,21 @@
+-- Variantes de un item (talle, color). El precio NULL hereda el del item. Mientras el item tenga
+-- variantes, el service mantiene items.stock como la suma del stock de ellas.
+
+CREATE TABLE IF NOT EXISTS item_variants (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id uuid NOT NULL,
+  sku text NOT NULL,
+  price numeric(10,2) NULL,
+  stock integer NOT NULL DEFAULT 0,
+  attributes jsonb NOT NULL DEFAULT '{}'::jsonb,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT fk_item_variants_item FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE,
+  CONSTRAINT ck_item_variants_price_positive CHECK (price IS NULL OR price > 0),
+  CONSTRAINT ck_item_variants_stock_non_negative CHECK (stock >= 0)
+);
+
+-- El SKU de una variante es único dentro del item (dos items pueden tener una variante "M").
+-- También resuelve el listado y la suma de stock por item_id.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_item_variants_item_sku ON item_variants (item_id, sku);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS ix_items_weight_grams;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_dimensions_non_negative;
+
+ALTER TABLE items DROP COLUMN IF EXISTS depth_mm;
+ALTER TABLE items DROP COLUMN IF EXISTS height_mm;
+ALTER TABLE items DROP COLUMN IF EXISTS width_mm;
+ALTER TABLE items DROP COLUMN IF EXISTS weight_grams;
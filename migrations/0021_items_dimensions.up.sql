@@ -0,0 +1,18 @@
+-- Peso y medidas para las integraciones de envío. Son opcionales; la DB solo asegura que no sean
+-- negativos y el service aplica los máximos (1.000 kg / 10 m). El índice parcial resuelve
+-- ?max_weight= sin recorrer los items que no tienen peso cargado.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS weight_grams integer;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS width_mm integer;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS height_mm integer;
+ALTER TABLE items ADD COLUMN IF NOT EXISTS depth_mm integer;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_dimensions_non_negative;
+ALTER TABLE items ADD CONSTRAINT ck_items_dimensions_non_negative CHECK (
+    (weight_grams IS NULL OR weight_grams >= 0) AND
+    (width_mm IS NULL OR width_mm >= 0) AND
+    (height_mm IS NULL OR height_mm >= 0) AND
+    (depth_mm IS NULL OR depth_mm >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS ix_items_weight_grams ON items (weight_grams) WHERE weight_grams IS NOT NULL;
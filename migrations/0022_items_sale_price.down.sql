@@ -0,0 +1,3 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_sale_price_below_price;
+
+ALTER TABLE items DROP COLUMN IF EXISTS sale_price;
@@ -0,0 +1,7 @@
+-- Precio de oferta sin vencimiento. Mismo tipo que price; la DB asegura que sea positivo y menor
+-- que el precio, así un PATCH que baja solo el precio no puede dejar una oferta más cara.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS sale_price numeric(10,2);
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_sale_price_below_price;
+ALTER TABLE items ADD CONSTRAINT ck_items_sale_price_below_price CHECK (sale_price IS NULL OR (sale_price > 0 AND sale_price < price));
@@ -0,0 +1,3 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_tax_rate_bps;
+
+ALTER TABLE items DROP COLUMN IF EXISTS tax_rate_bps;
@@ -0,0 +1,7 @@
+-- Alícuota de impuesto del item en puntos básicos (1900 = 19%). price sigue siendo neto; el precio
+-- con impuesto se calcula al leer. Los items existentes quedan con 0 (sin impuesto).
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS tax_rate_bps integer NOT NULL DEFAULT 0;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_tax_rate_bps;
+ALTER TABLE items ADD CONSTRAINT ck_items_tax_rate_bps CHECK (tax_rate_bps BETWEEN 0 AND 10000);
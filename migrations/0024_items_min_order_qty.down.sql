@@ -0,0 +1,3 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_min_order_qty_positive;
+
+ALTER TABLE items DROP COLUMN IF EXISTS min_order_qty;
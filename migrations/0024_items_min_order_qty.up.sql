@@ -0,0 +1,7 @@
+-- Cantidad mínima por reserva (ventas B2B por bulto). Los items existentes quedan en 1, que no
+-- restringe nada; el listado filtra con min_order_qty <= n para ocultar los que solo se venden por mayor.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS min_order_qty integer NOT NULL DEFAULT 1;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_min_order_qty_positive;
+ALTER TABLE items ADD CONSTRAINT ck_items_min_order_qty_positive CHECK (min_order_qty >= 1);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ix_items_expires_at;
+
+ALTER TABLE items DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,6 @@
+-- Fecha de vencimiento de los perecederos. Es una fecha sin hora: el item vence al terminar ese día.
+-- El índice parcial cubre el reporte de próximos vencimientos y el filtro de vencidos.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS expires_at date;
+
+CREATE INDEX IF NOT EXISTS ix_items_expires_at ON items (expires_at) WHERE expires_at IS NOT NULL AND deleted_at IS NULL;
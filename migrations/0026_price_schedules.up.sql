@@ -0,0 +1,25 @@
+-- Cambios de precio programados. El job de cambios de precio aplica los vencidos (effective_at ya
+-- pasó) y completa applied_at; los aplicados no se borran.
+
+CREATE TABLE IF NOT EXISTS price_schedules (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  item_id uuid NOT NULL,
+  new_price numeric(10,2) NOT NULL,
+  effective_at timestamptz NOT NULL,
+  applied_at timestamptz NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT fk_price_schedules_item FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE,
+  CONSTRAINT ck_price_schedules_new_price_positive CHECK (new_price > 0)
+);
+
+-- Un item no puede tener dos cambios pendientes para el mismo instante: no habría forma de saber
+-- cuál gana. También resuelve el next_price_change del item.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_price_schedules_item_effective_at_pending
+  ON price_schedules (item_id, effective_at) WHERE applied_at IS NULL;
+
+-- Resuelve el listado por item (aplicados incluidos) y el borrado en cascada.
+CREATE INDEX IF NOT EXISTS ix_price_schedules_item_id ON price_schedules (item_id, effective_at);
+
+-- Resuelve la búsqueda de cambios vencidos del job.
+CREATE INDEX IF NOT EXISTS ix_price_schedules_due ON price_schedules (effective_at) WHERE applied_at IS NULL;
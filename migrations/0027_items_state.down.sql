@@ -0,0 +1,3 @@
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_state;
+
+ALTER TABLE items DROP COLUMN IF EXISTS state;
@@ -0,0 +1,8 @@
+-- Estado editorial del item: un draft se está preparando y no aparece en el listado por defecto
+-- ni por slug. Los items existentes quedan published, así nada deja de verse. El check es el que
+-- el repositorio traduce a ErrorInvalidState.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS state text NOT NULL DEFAULT 'published';
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_state;
+ALTER TABLE items ADD CONSTRAINT ck_items_state CHECK (state IN ('draft', 'published'));
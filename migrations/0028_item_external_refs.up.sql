@@ -0,0 +1,16 @@
+-- IDs del mismo item en sistemas externos (ERP, marketplaces). Cada sistema le da un solo ID a cada
+-- producto, así que (system, external_id) es único en toda la tabla: es la clave de las búsquedas
+-- de los jobs de sincronización.
+
+CREATE TABLE IF NOT EXISTS item_external_refs (
+  item_id uuid NOT NULL,
+  system text NOT NULL,
+  external_id text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT pk_item_external_refs PRIMARY KEY (system, external_id),
+  CONSTRAINT fk_item_external_refs_item FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
+
+-- Resuelve las refs embebidas en el item y el borrado en cascada.
+CREATE INDEX IF NOT EXISTS ix_item_external_refs_item_id ON item_external_refs (item_id);
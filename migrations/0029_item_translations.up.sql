@@ -0,0 +1,13 @@
+-- Nombre y descripción de los items en otros idiomas. Los campos de items son los del locale base
+-- (el primero de SUPPORTED_LOCALES); acá va una fila por item y locale traducido.
+
+CREATE TABLE IF NOT EXISTS item_translations (
+  item_id uuid NOT NULL,
+  locale text NOT NULL,
+  name text NOT NULL,
+  description text NULL,
+  updated_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT pk_item_translations PRIMARY KEY (item_id, locale),
+  CONSTRAINT fk_item_translations_item FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
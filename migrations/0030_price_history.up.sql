@@ -0,0 +1,17 @@
+-- Historial de precios: una fila por cada cambio del precio de lista (alta, PATCH/PUT, cambio
+-- programado). Se escribe en la misma transacción que el cambio, como stock_movements.
+
+CREATE TABLE IF NOT EXISTS price_history (
+  id bigserial PRIMARY KEY,
+  item_id uuid NOT NULL,
+  old_price numeric(10,2) NULL,
+  new_price numeric(10,2) NOT NULL,
+  reason text NOT NULL,
+  request_id text,
+  changed_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT fk_price_history_item FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
+
+-- GET /items/{id}/price-history pagina del más nuevo al más viejo y filtra por changed_at.
+CREATE INDEX IF NOT EXISTS ix_price_history_item_id_changed_at_id ON price_history (item_id, changed_at DESC, id DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS ix_items_reorder_point;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_reorder_point_non_negative;
+
+ALTER TABLE items DROP COLUMN IF EXISTS reorder_point;
@@ -0,0 +1,10 @@
+-- Punto de reposición: cuando el stock llega a este valor el item aparece en GET /items/restock-needed.
+-- Es opcional; los items sin reorder_point no entran en el reporte y el índice parcial no los incluye.
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS reorder_point integer;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS ck_items_reorder_point_non_negative;
+ALTER TABLE items ADD CONSTRAINT ck_items_reorder_point_non_negative CHECK (reorder_point >= 0);
+
+CREATE INDEX IF NOT EXISTS ix_items_reorder_point ON items ((reorder_point - stock) DESC, id)
+	WHERE reorder_point IS NOT NULL AND deleted_at IS NULL;
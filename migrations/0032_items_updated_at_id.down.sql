@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS ix_items_updated_at ON items (updated_at);
+
+DROP INDEX IF EXISTS ix_items_updated_at_id;
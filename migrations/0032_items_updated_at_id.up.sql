@@ -0,0 +1,7 @@
+-- Feed de cambios (GET /items/changes): WHERE (updated_at, id) > ($1, $2) ORDER BY updated_at, id
+-- recorre este índice hacia adelante desde la marca del cliente. El id desempata los items con el
+-- mismo updated_at, que abundan en las operaciones masivas. Reemplaza a ix_items_updated_at: la
+-- punta del índice compuesto también sirve para el max(updated_at) del ETag del listado.
+CREATE INDEX IF NOT EXISTS ix_items_updated_at_id ON items (updated_at, id);
+
+DROP INDEX IF EXISTS ix_items_updated_at;
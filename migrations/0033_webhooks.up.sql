@@ -0,0 +1,35 @@
+-- Webhooks salientes: las suscripciones y el log de entregas. Cada evento que matchea una suscripción
+-- es una fila de webhook_deliveries que el job de entregas manda y reintenta hasta entregarla o
+-- pasarla a dead después de WEBHOOK_MAX_ATTEMPTS intentos.
+
+CREATE TABLE IF NOT EXISTS webhooks (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  url text NOT NULL,
+  secret text NOT NULL,
+  events text[] NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  webhook_id uuid NOT NULL,
+  event text NOT NULL,
+  payload jsonb NOT NULL,
+  status text NOT NULL DEFAULT 'pending',
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  response_status integer,
+  last_error text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  delivered_at timestamptz,
+
+  CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE,
+  CONSTRAINT ck_webhook_deliveries_status CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+-- El job toma las entregas pendientes que ya vencieron, de la más vieja a la más nueva.
+CREATE INDEX IF NOT EXISTS ix_webhook_deliveries_due ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+
+-- GET /webhooks/{id}/deliveries pagina de la más nueva a la más vieja.
+CREATE INDEX IF NOT EXISTS ix_webhook_deliveries_webhook_id_created_at_id ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
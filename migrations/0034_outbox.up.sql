@@ -0,0 +1,17 @@
+-- Outbox de eventos de items: cada alta, cambio o baja escribe acá su evento en la misma transacción
+-- que el cambio, así un corte entre el commit y la publicación no lo pierde. El relay lee las filas
+-- sin procesar en orden, se las pasa al publisher (hoy, los webhooks) y les pone processed_at.
+
+CREATE TABLE IF NOT EXISTS outbox (
+  id bigserial PRIMARY KEY,
+  event_type text NOT NULL,
+  payload jsonb NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  processed_at timestamptz
+);
+
+-- El relay busca las pendientes por id; las procesadas no entran en el índice.
+CREATE INDEX IF NOT EXISTS ix_outbox_pending ON outbox (id) WHERE processed_at IS NULL;
+
+-- El relay borra las procesadas viejas.
+CREATE INDEX IF NOT EXISTS ix_outbox_processed_at ON outbox (processed_at) WHERE processed_at IS NOT NULL;
@@ -0,0 +1,19 @@
+-- Auditoría de items: una fila por cada alta, cambio o baja, con los campos que cambiaron
+-- ([{field, old, new}]), el request que lo hizo y, cuando haya autenticación, quién. Se escribe en la
+-- misma transacción que el cambio, como price_history.
+
+CREATE TABLE IF NOT EXISTS item_audit (
+  id bigserial PRIMARY KEY,
+  item_id uuid NOT NULL,
+  action text NOT NULL,
+  changes jsonb NOT NULL DEFAULT '[]',
+  request_id text,
+  actor text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT fk_item_audit_item FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE,
+  CONSTRAINT ck_item_audit_action CHECK (action IN ('created', 'updated', 'deleted'))
+);
+
+-- GET /items/{id}/audit pagina del más nuevo al más viejo.
+CREATE INDEX IF NOT EXISTS ix_item_audit_item_id_id ON item_audit (item_id, id DESC);
@@ -0,0 +1,18 @@
+-- Log de auditoría global: una fila por cada request de escritura (POST, PUT, PATCH, DELETE) que
+-- respondió 2xx. Lo escribe un job en background a partir de lo que anota el middleware, así que
+-- created_at es cuándo terminó el request y no cuándo se insertó la fila.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+  id bigserial PRIMARY KEY,
+  method text NOT NULL,
+  path text NOT NULL,
+  item_id uuid,
+  actor text,
+  request_id text,
+  status integer NOT NULL,
+  created_at timestamptz NOT NULL
+);
+
+-- GET /admin/audit pagina del más nuevo al más viejo y filtra por ?since= y ?actor=.
+CREATE INDEX IF NOT EXISTS ix_audit_log_created_at_id ON audit_log (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS ix_audit_log_actor_created_at ON audit_log (actor, created_at DESC) WHERE actor IS NOT NULL;
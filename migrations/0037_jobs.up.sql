@@ -0,0 +1,29 @@
+-- Jobs asincrónicos (por ahora, imports): el request crea la fila en queued y responde 202; un
+-- worker la toma, la procesa fuera del request y va guardando el avance. El estado vive acá, así
+-- que un job sobrevive a un reinicio: si la instancia que lo tenía muere, se vuelve a tomar cuando
+-- vence locked_until y sigue desde processed.
+
+CREATE TABLE IF NOT EXISTS jobs (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  kind text NOT NULL,
+  status text NOT NULL DEFAULT 'queued',
+  payload jsonb,
+  total integer NOT NULL DEFAULT 0,
+  processed integer NOT NULL DEFAULT 0,
+  failed integer NOT NULL DEFAULT 0,
+  errors jsonb NOT NULL DEFAULT '[]',
+  result jsonb,
+  error text,
+  cancel_requested boolean NOT NULL DEFAULT false,
+  attempts integer NOT NULL DEFAULT 0,
+  locked_until timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  started_at timestamptz,
+  finished_at timestamptz,
+  updated_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT ck_jobs_status CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'canceled'))
+);
+
+-- El worker toma los queued del más viejo al más nuevo, y los running cuyo lease venció.
+CREATE INDEX IF NOT EXISTS ix_jobs_claimable ON jobs (created_at, id) WHERE status IN ('queued', 'running');
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS import_upload_chunks;
+DROP TABLE IF EXISTS import_uploads;
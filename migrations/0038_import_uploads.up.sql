@@ -0,0 +1,32 @@
+-- Subidas por partes de archivos de import demasiado grandes para un solo request. El cliente abre
+-- una sesión, manda los chunks (en cualquier orden, y reenviar uno lo reemplaza) y al completarla
+-- se verifica el SHA-256 del archivo armado y se encola el import. Los chunks viven en la base y no
+-- en disco, así que cualquier instancia puede recibir cualquiera de ellos y el worker que tome el
+-- job puede leer el archivo.
+
+CREATE TABLE IF NOT EXISTS import_uploads (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  format text NOT NULL,
+  mode text NOT NULL,
+  dry_run boolean NOT NULL DEFAULT false,
+  sha256 text,
+  completed_at timestamptz,
+  job_id uuid,
+  expires_at timestamptz NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+
+  CONSTRAINT ck_import_uploads_format CHECK (format IN ('json', 'csv'))
+);
+
+-- Resuelve la búsqueda de sesiones vencidas del job de limpieza.
+CREATE INDEX IF NOT EXISTS ix_import_uploads_expires_at ON import_uploads (expires_at);
+
+CREATE TABLE IF NOT EXISTS import_upload_chunks (
+  upload_id uuid NOT NULL,
+  number integer NOT NULL,
+  size integer NOT NULL,
+  data bytea NOT NULL,
+
+  CONSTRAINT pk_import_upload_chunks PRIMARY KEY (upload_id, number),
+  CONSTRAINT fk_import_upload_chunks_upload FOREIGN KEY (upload_id) REFERENCES import_uploads (id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE import_uploads DROP COLUMN IF EXISTS atomic;
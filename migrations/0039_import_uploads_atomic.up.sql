@@ -0,0 +1,4 @@
+-- ?atomic=true de una subida por partes: se guarda con la sesión, como dry_run, para que el job que
+-- encola Complete valide todas las filas antes de crear ninguna.
+
+ALTER TABLE import_uploads ADD COLUMN IF NOT EXISTS atomic boolean NOT NULL DEFAULT false;
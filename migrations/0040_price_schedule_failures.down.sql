@@ -0,0 +1,13 @@
+-- Sin failed_at los fallidos volverían a quedar pendientes: se borran antes de sacar las columnas.
+DELETE FROM price_schedules WHERE failed_at IS NOT NULL;
+
+DROP INDEX IF EXISTS ix_price_schedules_due;
+CREATE INDEX IF NOT EXISTS ix_price_schedules_due ON price_schedules (effective_at) WHERE applied_at IS NULL;
+
+DROP INDEX IF EXISTS ux_price_schedules_item_effective_at_pending;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_price_schedules_item_effective_at_pending
+  ON price_schedules (item_id, effective_at) WHERE applied_at IS NULL;
+
+ALTER TABLE price_schedules
+  DROP COLUMN IF EXISTS failure_reason,
+  DROP COLUMN IF EXISTS failed_at;
@@ -0,0 +1,16 @@
+-- Un cambio programado que el job no puede aplicar (el precio nuevo quedó en o por debajo del de
+-- oferta, o tiene más decimales que la moneda actual del item) se marca fallido con el motivo: deja
+-- de estar pendiente, así no se reintenta en cada corrida ni tapa a los cambios más nuevos.
+
+ALTER TABLE price_schedules
+  ADD COLUMN IF NOT EXISTS failed_at timestamptz NULL,
+  ADD COLUMN IF NOT EXISTS failure_reason text NULL;
+
+-- Pendiente ahora es "ni aplicado ni fallido": un fallido no bloquea otro cambio en el mismo instante.
+DROP INDEX IF EXISTS ux_price_schedules_item_effective_at_pending;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_price_schedules_item_effective_at_pending
+  ON price_schedules (item_id, effective_at) WHERE applied_at IS NULL AND failed_at IS NULL;
+
+DROP INDEX IF EXISTS ix_price_schedules_due;
+CREATE INDEX IF NOT EXISTS ix_price_schedules_due
+  ON price_schedules (effective_at) WHERE applied_at IS NULL AND failed_at IS NULL;